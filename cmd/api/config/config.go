@@ -16,21 +16,141 @@ const (
 	APIEnvironmentProduction APIEnvironment = "PRODUCTION"
 )
 
+// StorageBackend defines where data is stored.
+type StorageBackend string
+
+const (
+	StorageBackendMySQL    StorageBackend = "mysql"
+	StorageBackendMemory   StorageBackend = "memory"
+	StorageBackendSQLite   StorageBackend = "sqlite"
+	StorageBackendPostgres StorageBackend = "postgres"
+)
+
+// LockoutStore defines where failed login attempts are stored.
+type LockoutStore string
+
+const (
+	LockoutStoreMySQL  LockoutStore = "mysql"
+	LockoutStoreMemory LockoutStore = "memory"
+)
+
+// CacheBackend defines where storage lookups are cached.
+type CacheBackend string
+
+const (
+	CacheBackendNone CacheBackend = "none"
+	CacheBackendLRU  CacheBackend = "lru"
+)
+
+// IDStrategy defines how the IDs of new records are assigned.
+type IDStrategy string
+
+const (
+	// IDStrategySequence assigns IDs in sequence, using AUTO_INCREMENT.
+	IDStrategySequence IDStrategy = "sequence"
+
+	// IDStrategySortable assigns random IDs that sort by creation time, so
+	// they do not show how many records there are.
+	IDStrategySortable IDStrategy = "sortable"
+)
+
+// TraceExporter defines where trace spans are exported to.
+type TraceExporter string
+
+const (
+	TraceExporterNone TraceExporter = "none"
+	TraceExporterOTLP TraceExporter = "otlp"
+)
+
+// OutboxSink defines where the events of the outbox are published.
+type OutboxSink string
+
+const (
+	// OutboxSinkNone leaves events in the outbox, for another process to
+	// publish.
+	OutboxSinkNone   OutboxSink = "none"
+	OutboxSinkStdout OutboxSink = "stdout"
+	OutboxSinkFile   OutboxSink = "file"
+	OutboxSinkHTTP   OutboxSink = "http"
+)
+
+// RateLimitGroup defines a group of routes sharing a rate limit.
+type RateLimitGroup string
+
+const (
+	// RateLimitGroupAPI is the group of authenticated routes.
+	RateLimitGroupAPI RateLimitGroup = "api"
+
+	// RateLimitGroupAuth is the group of login, password and token routes.
+	RateLimitGroupAuth RateLimitGroup = "auth"
+
+	// RateLimitGroupSignup is the group of signup routes.
+	RateLimitGroupSignup RateLimitGroup = "signup"
+
+	// RateLimitGroupPublic is the group of public invoice routes.
+	RateLimitGroupPublic RateLimitGroup = "public"
+)
+
+// RateLimit defines a token bucket rate limit, allowing bursts of up to
+// Requests requests, refilled evenly over Period seconds. A zero Requests
+// means no limit.
+type RateLimit struct {
+	Requests uint          `json:"requests"`
+	Period   time.Duration `json:"period"`
+}
+
 // Config defines the Go Todo API settings.
 type Config struct {
-	DBHost         string         `json:"db_host"`
-	DBPort         string         `json:"db_port"`
-	DBName         string         `json:"db_name"`
-	DBUser         string         `json:"db_user"`
-	DBPass         string         `json:"db_pass"`
-	APIHost        string         `json:"api_host"`
-	APIPort        string         `json:"api_port"`
-	APIEnvironment APIEnvironment `json:"api_environment"`
-	LogFile        string         `json:"log_file"`
-	JWTSecret      string         `json:"jwt_secret"`
-	JWTExpiryTime  time.Duration  `json:"jwt_expiry_time"`
-	LimitDefault   uint           `json:"limit_default"`
-	LimitMax       uint           `json:"limit_max"`
+	StorageBackend     StorageBackend               `json:"storage_backend"`
+	DBHost             string                       `json:"db_host"`
+	DBPort             string                       `json:"db_port"`
+	DBName             string                       `json:"db_name"`
+	DBUser             string                       `json:"db_user"`
+	DBPass             string                       `json:"db_pass"`
+	DBSSLMode          string                       `json:"db_sslmode"`
+	DBTimeout          time.Duration                `json:"db_timeout"`
+	SQLitePath         string                       `json:"sqlite_path"`
+	CacheBackend       CacheBackend                 `json:"cache_backend"`
+	CacheSize          uint                         `json:"cache_size"`
+	CacheTTL           time.Duration                `json:"cache_ttl"`
+	IDStrategy         IDStrategy                   `json:"id_strategy"`
+	APIHost            string                       `json:"api_host"`
+	APIPort            string                       `json:"api_port"`
+	APIEnvironment     APIEnvironment               `json:"api_environment"`
+	PublicHosts        []string                     `json:"public_hosts"`
+	TrustedProxies     []string                     `json:"trusted_proxies"`
+	LogFile            string                       `json:"log_file"`
+	JWTSecret          string                       `json:"jwt_secret"`
+	JWTExpiryTime      time.Duration                `json:"jwt_expiry_time"`
+	RefreshExpiryTime  time.Duration                `json:"refresh_expiry_time"`
+	CursorSecret       string                       `json:"cursor_secret"`
+	LimitDefault       uint                         `json:"limit_default"`
+	LimitMax           uint                         `json:"limit_max"`
+	ImportLimitMax     uint                         `json:"import_limit_max"`
+	SMTPHost           string                       `json:"smtp_host"`
+	SMTPPort           string                       `json:"smtp_port"`
+	SMTPUser           string                       `json:"smtp_user"`
+	SMTPPass           string                       `json:"smtp_pass"`
+	MailFrom           string                       `json:"mail_from"`
+	ResetLink          string                       `json:"reset_link"`
+	VerifyLink         string                       `json:"verify_link"`
+	InviteLink         string                       `json:"invite_link"`
+	TOTPIssuer         string                       `json:"totp_issuer"`
+	LockoutThreshold   uint                         `json:"lockout_threshold"`
+	LockoutIPThreshold uint                         `json:"lockout_ip_threshold"`
+	LockoutDelayAfter  uint                         `json:"lockout_delay_after"`
+	LockoutDuration    time.Duration                `json:"lockout_duration"`
+	LockoutStore       LockoutStore                 `json:"lockout_store"`
+	RateLimits         map[RateLimitGroup]RateLimit `json:"rate_limits"`
+	ShutdownDelay      time.Duration                `json:"shutdown_delay"`
+	ShutdownTimeout    time.Duration                `json:"shutdown_timeout"`
+	TraceExporter      TraceExporter                `json:"trace_exporter"`
+	TraceEndpoint      string                       `json:"trace_endpoint"`
+	OutboxSink         OutboxSink                   `json:"outbox_sink"`
+	OutboxFile         string                       `json:"outbox_file"`
+	OutboxURL          string                       `json:"outbox_url"`
+	CallGuard          bool                         `json:"call_guard"`
+	CallGuardMaxDepth  uint                         `json:"call_guard_max_depth"`
 }
 
 // ParseConfigFile parses the API configuration file.
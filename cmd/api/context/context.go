@@ -1,24 +1,79 @@
 package context
 
 import (
+	"context"
 	"log/slog"
+	"net/http"
+	"time"
 
 	"dddstructure/cmd/api/config"
+	"dddstructure/cmd/api/limiter"
+	"dddstructure/cmd/api/proxy"
 	"dddstructure/service"
 )
 
+// key is the key type used by this package for the request context.
+type key int
+
+// requestKey is the key used for storing and retrieving the request scoped
+// API context from the request context.
+var requestKey key = 1
+
 // Context defines the API context.
+//
+// Proxies holds the trusted proxies of Config.TrustedProxies, and trusts
+// none if nil. Limiters holds the rate limiter of each route group with a
+// limit in Config.RateLimits, so every route in a group shares the same
+// buckets.
 type Context struct {
-	Config  *config.Config
-	Logger  *slog.Logger
-	Service *service.Service
+	Config   *config.Config
+	Logger   *slog.Logger
+	Service  *service.Service
+	Proxies  *proxy.Trusted
+	Limiters map[config.RateLimitGroup]*limiter.Limiter
 }
 
-// New returns a new API context.
-func New(config *config.Config, logger *slog.Logger, services *service.Service) *Context {
+// New returns a new API context, with a new rate limiter for each route
+// group with a limit.
+func New(cfg *config.Config, logger *slog.Logger, services *service.Service) *Context {
+	limiters := make(map[config.RateLimitGroup]*limiter.Limiter)
+	for group, rl := range cfg.RateLimits {
+		if rl.Requests != 0 && rl.Period != 0 {
+			limiters[group] = limiter.New(rl.Requests, time.Second*rl.Period)
+		}
+	}
+
 	return &Context{
-		Config:  config,
-		Logger:  logger,
-		Service: services,
+		Config:   cfg,
+		Logger:   logger,
+		Service:  services,
+		Limiters: limiters,
+	}
+}
+
+// WithLogger returns a copy of the API context where the handlers and the
+// services log to the given logger.
+func (ac *Context) WithLogger(logger *slog.Logger) *Context {
+	return &Context{
+		Config:   ac.Config,
+		Logger:   logger,
+		Service:  ac.Service.WithLogger(logger),
+		Proxies:  ac.Proxies,
+		Limiters: ac.Limiters,
+	}
+}
+
+// WithRequest returns a shallow copy of the request with the given API
+// context scoped to it.
+func WithRequest(r *http.Request, ac *Context) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), requestKey, ac))
+}
+
+// FromRequest returns the API context scoped to the given request, or this
+// API context if there is none.
+func (ac *Context) FromRequest(r *http.Request) *Context {
+	if rac, ok := r.Context().Value(requestKey).(*Context); ok {
+		return rac
 	}
+	return ac
 }
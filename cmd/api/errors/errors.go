@@ -18,14 +18,18 @@ var (
 
 	// ErrInternalServerError is returned when an internal server error occurs.
 	ErrInternalServerError = New(http.StatusInternalServerError, "", "Internal server error")
+
+	// ErrTooManyRequests is returned when a rate limit is exceeded.
+	ErrTooManyRequests = New(http.StatusTooManyRequests, "", "Too many requests, try again later")
 )
 
 var (
-	// ErrOffsetInvalid is returned when the offset parameter is invalid.
-	ErrOffsetInvalid = New(http.StatusBadRequest, "offset", "Offset parameter is invalid, must be an integer")
+	// ErrCursorInvalid is returned when the cursor parameter is invalid.
+	ErrCursorInvalid = New(http.StatusBadRequest, "cursor", "Cursor parameter is invalid")
 
-	// ErrLimitInvalid is returned when the limit parameter is invalid.
-	ErrLimitInvalid = New(http.StatusBadRequest, "limit", "Limit parameter is invalid, must be an integer")
+	// ErrLimitInvalid is returned when the limit parameter is not a positive
+	// integer.
+	ErrLimitInvalid = New(http.StatusBadRequest, "limit", "Limit parameter is invalid, must be a positive integer")
 
 	// ErrLimitMax is returned when the limit parameter is greater than the
 	// maximum allowable limit.
@@ -35,6 +39,23 @@ var (
 			fmt.Sprintf("Limit value of %d is greater than maximum allowable limit of %d", limit, max),
 		)
 	}
+
+	// ErrOrganizationIDInvalid is returned when the X-Organization-ID header is
+	// invalid.
+	ErrOrganizationIDInvalid = New(http.StatusBadRequest, "", "X-Organization-ID header is invalid, must be an integer")
+
+	// ErrImportContentType is returned when an import is not sent as CSV or
+	// JSON Lines.
+	ErrImportContentType = New(http.StatusUnsupportedMediaType, "", "Content-Type must be either text/csv or application/x-ndjson")
+
+	// ErrImportLimitMax is returned when an import has more rows than the
+	// maximum allowable limit.
+	ErrImportLimitMax = func(max uint) *Error {
+		return New(http.StatusBadRequest,
+			"",
+			fmt.Sprintf("Import is greater than maximum allowable limit of %d invoices", max),
+		)
+	}
 )
 
 // Error defines the default API error type.
@@ -0,0 +1,125 @@
+package health
+
+import (
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/cmd/api/response"
+	"dddstructure/proto"
+
+	"github.com/beeker1121/httprouter"
+)
+
+// draining is set once the server starts shutting down, after which the API
+// is no longer ready.
+var draining atomic.Bool
+
+// New creates the routes for the health endpoints of the API.
+func New(ac *apictx.Context, router *httprouter.Router) {
+	// Handle the routes.
+	logging.Handle(router, http.MethodGet, "/healthz", HandleGetHealthz(ac))
+	logging.Handle(router, http.MethodGet, "/readyz", HandleGetReadyz(ac))
+}
+
+// Drain marks the API as not ready, so no new requests are routed to it
+// while in-flight requests finish.
+func Drain() {
+	draining.Store(true)
+}
+
+// Check defines the status of a single dependency.
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Health defines the status of the API.
+type Health struct {
+	Status string   `json:"status"`
+	Checks []*Check `json:"checks,omitempty"`
+}
+
+// ResultGet defines the response data for the HandleGetHealthz and
+// HandleGetReadyz handlers.
+type ResultGet struct {
+	Data Health `json:"data"`
+}
+
+// HandleGetHealthz handles the /healthz GET route of the API.
+//
+// This is the liveness check, which only shows the server is able to handle
+// requests.
+func HandleGetHealthz(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Create a new Result.
+		result := ResultGet{
+			Data: Health{
+				Status: string(proto.HealthStatusUp),
+			},
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
+
+// HandleGetReadyz handles the /readyz GET route of the API.
+//
+// This is the readiness check, which checks each dependency and responds with
+// 503 if any of them are down, or if the server is shutting down.
+func HandleGetReadyz(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Check the dependencies.
+		health := ac.Service.Health.Check(r.Context())
+
+		// Create a new Result.
+		result := ResultGet{
+			Data: Health{
+				Status: string(health.Status),
+			},
+		}
+
+		for _, c := range health.Checks {
+			result.Data.Checks = append(result.Data.Checks, &Check{
+				Name:   c.Name,
+				Status: string(c.Status),
+				Error:  c.Error,
+			})
+		}
+
+		if draining.Load() {
+			result.Data.Status = string(proto.HealthStatusDown)
+		}
+
+		// Set the status code, with the headers set first as they can not be
+		// changed after.
+		if result.Data.Status != string(proto.HealthStatusUp) {
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			return
+		}
+	}
+}
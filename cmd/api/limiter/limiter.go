@@ -0,0 +1,128 @@
+package limiter
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// bucket defines the token bucket of a single key.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Result defines the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed    bool
+	Limit      uint
+	Remaining  uint
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter defines a token bucket rate limiter, keeping a bucket for each key.
+//
+// Each bucket holds up to the limit of tokens and starts full. A request
+// takes a token, and tokens are refilled evenly over the period. Buckets that
+// have refilled are dropped, so idle keys do not use memory.
+type Limiter struct {
+	limit  uint
+	period time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+// New creates a new limiter allowing bursts of up to limit requests,
+// refilled evenly over period.
+func New(limit uint, period time.Duration) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		period:  period,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Limit returns the number of requests allowed in a burst.
+func (l *Limiter) Limit() uint {
+	return l.limit
+}
+
+// Period returns the period the tokens are refilled over.
+func (l *Limiter) Period() time.Duration {
+	return l.period
+}
+
+// Len returns the number of keys with a bucket, which have taken a token
+// since their bucket was last full.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buckets)
+}
+
+// Take takes a token from the bucket of the given key at the given time.
+func (l *Limiter) Take(key string, now time.Time) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit), updated: now}
+		l.buckets[key] = b
+	}
+	l.refill(b, now)
+
+	result := Result{
+		Limit: l.limit,
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = l.refillTime(1 - b.tokens)
+	}
+
+	result.Remaining = uint(b.tokens)
+	result.Reset = l.refillTime(float64(l.limit) - b.tokens)
+
+	return result
+}
+
+// refill adds the tokens refilled since the bucket was last updated.
+func (l *Limiter) refill(b *bucket, now time.Time) {
+	elapsed := now.Sub(b.updated)
+	if elapsed <= 0 {
+		return
+	}
+
+	b.tokens = math.Min(float64(l.limit), b.tokens+float64(l.limit)*float64(elapsed)/float64(l.period))
+	b.updated = now
+}
+
+// refillTime returns how long it takes to refill the given number of tokens.
+func (l *Limiter) refillTime(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens * float64(l.period) / float64(l.limit)))
+}
+
+// sweep drops the buckets that have refilled, at most once per period.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < l.period {
+		return
+	}
+
+	for key, b := range l.buckets {
+		l.refill(b, now)
+		if b.tokens >= float64(l.limit) {
+			delete(l.buckets, key)
+		}
+	}
+
+	l.swept = now
+}
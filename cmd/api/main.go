@@ -1,22 +1,55 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"dddstructure/cmd/api/config"
 	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/health"
+	apimetrics "dddstructure/cmd/api/metrics"
+	"dddstructure/cmd/api/middleware/instrument"
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/cmd/api/middleware/tracing"
+	"dddstructure/cmd/api/pagination"
+	"dddstructure/cmd/api/proxy"
 	v1 "dddstructure/cmd/api/v1"
+	"dddstructure/mail"
+	maillogger "dddstructure/mail/logger"
+	mailsmtp "dddstructure/mail/smtp"
+	"dddstructure/metrics"
+	"dddstructure/relay"
+	"dddstructure/relay/webhook"
+	"dddstructure/relay/writer"
 	"dddstructure/service"
+	"dddstructure/service/guard"
+	"dddstructure/service/user"
+	"dddstructure/storage"
+	"dddstructure/storage/cache/lru"
+	"dddstructure/storage/cached"
+	"dddstructure/storage/idgen"
+	"dddstructure/storage/instrumented"
+	"dddstructure/storage/memory"
+	memoryloginattempt "dddstructure/storage/memory/loginattempt"
 	storagemysql "dddstructure/storage/mysql"
+	"dddstructure/storage/postgres"
+	"dddstructure/storage/sqlite"
+	"dddstructure/storage/traced"
+	"dddstructure/trace"
+	"dddstructure/trace/otlphttp"
 
 	"github.com/beeker1121/creek"
 	"github.com/beeker1121/httprouter"
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
 )
 
 func main() {
@@ -32,14 +65,43 @@ func main() {
 	cfg.DBName = os.Getenv("DB_NAME")
 	cfg.DBUser = os.Getenv("DB_USER")
 	cfg.DBPass = os.Getenv("DB_PASS")
-	cfg.APIHost = os.Getenv("API_HOST")
-	cfg.APIPort = os.Getenv("API_PORT")
+	cfg.DBSSLMode = os.Getenv("DB_SSLMODE")
 	cfg.JWTSecret = os.Getenv("JWT_SECRET")
+	cfg.CursorSecret = os.Getenv("CURSOR_SECRET")
+
+	// Derive the secret for signing pagination cursors from the JWT secret,
+	// if not set.
+	if cfg.CursorSecret == "" && cfg.JWTSecret != "" {
+		cfg.CursorSecret = pagination.DeriveSecret(cfg.JWTSecret)
+	}
+	if cfg.CursorSecret == "" {
+		panic("CURSOR_SECRET or JWT_SECRET must be set")
+	}
+
+	if os.Getenv("API_HOST") != "" {
+		cfg.APIHost = os.Getenv("API_HOST")
+	}
+
+	if os.Getenv("API_PORT") != "" {
+		cfg.APIPort = os.Getenv("API_PORT")
+	}
 
 	if os.Getenv("API_ENVIRONMENT") != "" {
 		cfg.APIEnvironment = config.APIEnvironment(os.Getenv("API_ENVIRONMENT"))
 	}
 
+	if os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		cfg.TraceEndpoint = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	}
+
+	if os.Getenv("SMTP_HOST") != "" {
+		cfg.SMTPHost = os.Getenv("SMTP_HOST")
+		cfg.SMTPPort = os.Getenv("SMTP_PORT")
+		cfg.SMTPUser = os.Getenv("SMTP_USER")
+		cfg.SMTPPass = os.Getenv("SMTP_PASS")
+		cfg.MailFrom = os.Getenv("MAIL_FROM")
+	}
+
 	// Create a new logger.
 	var logger *slog.Logger
 	if cfg.APIEnvironment == config.APIEnvironmentDevelop {
@@ -50,51 +112,243 @@ func main() {
 		panic("invalid API environment")
 	}
 
-	/* // Create a new mock storage implementation.
-	fmt.Println("[+] Creating new mock storage implementation...")
-	store := mock.New(&sql.DB{}) */
+	// Assign IDs in sequence, or from a sortable ID generator if set.
+	var ids idgen.Generator
+	switch cfg.IDStrategy {
+	case config.IDStrategySequence, "":
+	case config.IDStrategySortable:
+		ids = idgen.NewSortable()
+	default:
+		panic("invalid ID strategy")
+	}
 
-	// Connect to the MySQL database.
-	db, err := sql.Open("mysql", cfg.DBUser+":"+cfg.DBPass+"@tcp("+cfg.DBHost+":"+cfg.DBPort+")/"+cfg.DBName+"?parseTime=true")
-	if err != nil {
-		panic(err)
+	// Create a new storage implementation.
+	var store *storage.Storage
+	switch cfg.StorageBackend {
+	case config.StorageBackendMySQL, "":
+		// Connect to the MySQL database.
+		db, err := sql.Open("mysql", cfg.DBUser+":"+cfg.DBPass+"@tcp("+cfg.DBHost+":"+cfg.DBPort+")/"+cfg.DBName+"?parseTime=true")
+		if err != nil {
+			panic(err)
+		}
+		defer db.Close()
+
+		// Test database connection.
+		if err := db.Ping(); err != nil {
+			panic(err)
+		}
+
+		store = storagemysql.New(db, time.Second*cfg.DBTimeout, ids)
+	case config.StorageBackendMemory:
+		// Everything is lost when the API stops, and each instance has its
+		// own data, so this is only for development.
+		fmt.Println("[+] Storing data in memory...")
+		store = memory.New()
+	case config.StorageBackendSQLite:
+		// Open the SQLite database, creating the file and its tables if
+		// they do not exist, so no database server is needed.
+		db, err := sqlite.Open(context.Background(), cfg.SQLitePath)
+		if err != nil {
+			panic(err)
+		}
+		defer db.Close()
+
+		fmt.Printf("[+] Storing data in SQLite database %s...\n", cfg.SQLitePath)
+		store = sqlite.New(db, time.Second*cfg.DBTimeout, ids)
+	case config.StorageBackendPostgres:
+		// Connect to the Postgres database, migrated with
+		// migrate -driver postgres.
+		db, err := sql.Open("postgres", postgres.DSN(cfg.DBHost, cfg.DBPort, cfg.DBName, cfg.DBUser, cfg.DBPass, cfg.DBSSLMode))
+		if err != nil {
+			panic(err)
+		}
+		defer db.Close()
+
+		// Test database connection.
+		if err := db.Ping(); err != nil {
+			panic(err)
+		}
+
+		store = postgres.New(db, time.Second*cfg.DBTimeout, ids)
+	default:
+		panic("invalid storage backend")
 	}
-	defer db.Close()
 
-	// Test database connection.
-	if err := db.Ping(); err != nil {
-		panic(err)
+	// Keep failed login attempts in memory if set. This only works with a
+	// single API instance, as each instance counts attempts on its own.
+	switch cfg.LockoutStore {
+	case config.LockoutStoreMySQL, "":
+	case config.LockoutStoreMemory:
+		store.LoginAttempt = memoryloginattempt.New()
+	default:
+		panic("invalid lockout store")
 	}
 
-	// Create a new MySQL storage implementation.
-	store := storagemysql.New(db)
+	// Record metrics of every storage call.
+	registry := metrics.NewRegistry()
+	store = instrumented.New(store, registry)
+
+	// Create a new tracer, if spans are exported, and trace every storage
+	// call.
+	var tracer *trace.Tracer
+	switch cfg.TraceExporter {
+	case config.TraceExporterNone, "":
+	case config.TraceExporterOTLP:
+		tracer = trace.NewTracer(otlphttp.New(cfg.TraceEndpoint, "dddstructure-api", logger))
+		store = traced.New(store)
+	default:
+		panic("invalid trace exporter")
+	}
+
+	// Cache user and invoice lookups, if set. The cache wraps the metrics
+	// and traces, so they only record the calls that reach the database.
+	switch cfg.CacheBackend {
+	case config.CacheBackendNone, "":
+	case config.CacheBackendLRU:
+		store = storage.New(store, cached.Decorator(lru.New(int(cfg.CacheSize), time.Second*cfg.CacheTTL)))
+	default:
+		panic("invalid cache backend")
+	}
+
+	// Create a new relay publishing the events of the outbox, if set. Every
+	// API instance runs its own relay, which claim events from the outbox so
+	// they do not publish the same events at once. An event can still be
+	// published more than once, so consumers drop duplicates by event ID.
+	var sink relay.Sink
+	switch cfg.OutboxSink {
+	case config.OutboxSinkNone, "":
+	case config.OutboxSinkStdout:
+		sink = writer.New(os.Stdout)
+	case config.OutboxSinkFile:
+		f, err := os.OpenFile(cfg.OutboxFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+
+		sink = writer.New(f)
+	case config.OutboxSinkHTTP:
+		sink = webhook.New(cfg.OutboxURL)
+	default:
+		panic("invalid outbox sink")
+	}
+
+	var relayer *relay.Relay
+	if sink != nil {
+		relayer = relay.New(store.Outbox, sink, logger)
+	}
+
+	// Create a new mail sender. Without an SMTP server, mail is only
+	// logged.
+	var mailer mail.Sender
+	if cfg.SMTPHost != "" {
+		mailer = mailsmtp.New(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPass, cfg.MailFrom)
+	} else {
+		mailer = maillogger.New(logger)
+	}
 
 	// Create a new service.
 	fmt.Println("[+] Creating new service...")
-	serv := service.New(store, logger)
+	serv := service.New(store, mailer, logger)
+	serv.User.SetLockoutPolicy(user.LockoutPolicy{
+		Threshold:   cfg.LockoutThreshold,
+		IPThreshold: cfg.LockoutIPThreshold,
+		DelayAfter:  cfg.LockoutDelayAfter,
+		Duration:    time.Minute * cfg.LockoutDuration,
+	})
+
+	// Guard the calls between services, if set, so a cycle between them
+	// fails the request instead of never returning.
+	if cfg.CallGuard {
+		serv.SetCallGuard(&guard.Config{
+			MaxDepth: cfg.CallGuardMaxDepth,
+		})
+	}
 
 	// Create a new router.
 	router := httprouter.New()
 
-	// Create a new API context.
+	// Create a new API context, trusting the X-Forwarded-* headers of the
+	// trusted proxies only.
 	ac := apictx.New(cfg, logger, serv)
+	ac.Proxies, err = proxy.New(cfg.TrustedProxies)
+	if err != nil {
+		panic(err)
+	}
 
-	// Create a new v1 API.
+	// Create the health checks, the metrics endpoint and a new v1 API.
+	health.New(ac, router)
+	apimetrics.New(ac, router, registry)
 	v1.New(ac, router)
 
+	// Create the middleware chain, tracing requests before they are logged
+	// so the log lines carry the trace ID.
+	handler := logging.LogRequests(ac, router)
+	if tracer != nil {
+		handler = tracing.TraceRequests(tracer, router, handler)
+	}
+	handler = instrument.InstrumentRequests(registry, router, handler)
+
 	// Create a new HTTP server.
 	server := &http.Server{
-		Addr:           ":8080",
-		Handler:        router,
+		Addr:           net.JoinHostPort(cfg.APIHost, cfg.APIPort),
+		Handler:        handler,
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
 		MaxHeaderBytes: 1 << 20,
 	}
 
-	fmt.Printf("[+] Running server on port 8080...\n")
+	fmt.Printf("[+] Running server on %s...\n", server.Addr)
 
 	// Start the HTTP server.
-	if err := server.ListenAndServe(); err != nil {
-		panic(err)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.ListenAndServe()
+	}()
+
+	// Wait for the server to fail or a signal to shut down.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case err := <-errc:
+		logger.Error("server.ListenAndServe() error",
+			slog.Any("error", err))
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+
+	// Stop being ready first, giving load balancers time to stop sending
+	// requests before the listener is closed.
+	fmt.Println("[+] Shutting down server...")
+	health.Drain()
+	time.Sleep(time.Second * cfg.ShutdownDelay)
+
+	// Wait for in-flight requests to finish, up to the shutdown timeout.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("server.Shutdown() error",
+			slog.Any("error", err))
+		return
+	}
+
+	// Send any spans not yet exported.
+	if tracer != nil {
+		if err := tracer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("tracer.Shutdown() error",
+				slog.Any("error", err))
+		}
 	}
+
+	// Publish any events written by the last requests.
+	if relayer != nil {
+		if err := relayer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("relayer.Shutdown() error",
+				slog.Any("error", err))
+		}
+	}
+
+	fmt.Println("[+] Server shut down")
 }
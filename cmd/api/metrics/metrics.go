@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"log/slog"
+	"net/http"
+
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/metrics"
+
+	"github.com/beeker1121/httprouter"
+)
+
+// contentType defines the content type of the Prometheus text format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// New creates the route for the metrics endpoint of the API.
+func New(ac *apictx.Context, router *httprouter.Router, registry *metrics.Registry) {
+	// Handle the routes.
+	logging.Handle(router, http.MethodGet, "/metrics", HandleGetMetrics(ac, registry))
+}
+
+// HandleGetMetrics handles the /metrics GET route of the API.
+//
+// This writes every metric in the registry in the Prometheus text format.
+func HandleGetMetrics(ac *apictx.Context, registry *metrics.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		w.Header().Set("Content-Type", contentType)
+		if err := registry.Write(w); err != nil {
+			ac.Logger.Error("registry.Write() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
@@ -2,6 +2,8 @@ package auth
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -9,10 +11,15 @@ import (
 	"strings"
 	"time"
 
+	"dddstructure/cmd/api/config"
 	apictx "dddstructure/cmd/api/context"
 	"dddstructure/cmd/api/errors"
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/cmd/api/middleware/ratelimit"
 	"dddstructure/proto"
+	"dddstructure/service/actor"
 	serverrors "dddstructure/service/errors"
+	"dddstructure/storage/cache"
 
 	"github.com/dgrijalva/jwt-go"
 )
@@ -24,14 +31,19 @@ type key int
 // request context.
 var AuthKey key = 1
 
+// SessionKey is the key used for storing and retrieving the session ID from
+// the request context.
+var SessionKey key = 2
+
 // TokenClaims defines the custom claims we use for the JWT.
 type TokenClaims struct {
-	UserID uint `json:"user_id"`
+	UserID    uint   `json:"user_id"`
+	SessionID string `json:"sid"`
 	jwt.StandardClaims
 }
 
-// NewJWT creates and returns a new signed JWT.
-func NewJWT(ac *apictx.Context, userPassword string, uid uint) (string, error) {
+// NewJWT creates and returns a new signed JWT for the given session.
+func NewJWT(ac *apictx.Context, userPassword string, uid uint, sessionID string) (string, error) {
 	// Set expiry time.
 	issued := time.Now()
 	expires := issued.Add(time.Minute * ac.Config.JWTExpiryTime)
@@ -39,6 +51,7 @@ func NewJWT(ac *apictx.Context, userPassword string, uid uint) (string, error) {
 	// Create the claims.
 	claims := &TokenClaims{
 		uid,
+		sessionID,
 		jwt.StandardClaims{
 			IssuedAt:  issued.Unix(),
 			ExpiresAt: expires.Unix(),
@@ -65,9 +78,17 @@ func NewJWT(ac *apictx.Context, userPassword string, uid uint) (string, error) {
 // API keys should be passed via the Authorization header using Basic Auth.
 //
 // Currently, the only supported authorization method is via JWTs.
+//
+// Authenticated requests are rate limited per user by the api rate limit.
 func AuthenticateEndpoint(ac *apictx.Context, h http.HandlerFunc) http.HandlerFunc {
+	h = ratelimit.LimitEndpoint(ac, config.RateLimitGroupAPI, RateLimitKey, h)
+
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
 		u := &proto.User{}
+		claims := &TokenClaims{}
 		var err error
 
 		// Get the Authorization header.
@@ -81,7 +102,7 @@ func AuthenticateEndpoint(ac *apictx.Context, h http.HandlerFunc) http.HandlerFu
 
 		if len(authHeader) == 2 && authHeader[0] == "Bearer" {
 			// Try authorization via JWT Authorization Bearer header first.
-			u, err = GetUserFromJWT(ac, authHeader[1])
+			u, claims, err = GetUserFromJWT(r.Context(), ac, authHeader[1])
 			if err == ErrJWTUnauthorized {
 				ac.Logger.Error("API authorization via JWT failure")
 				errors.Default(ac.Logger, w, errors.New(http.StatusUnauthorized, "", err.Error()))
@@ -99,18 +120,29 @@ func AuthenticateEndpoint(ac *apictx.Context, h http.HandlerFunc) http.HandlerFu
 			return
 		}
 
-		// Pass user to request context and call next handler.
+		// Log the user with the request, and with every log line from here
+		// on.
+		logging.SetUserID(r, u.ID)
+		r = apictx.WithRequest(r, ac.WithLogger(ac.Logger.With(slog.Uint64("user_id", uint64(u.ID)))))
+
+		// Pass user and session to request context, calling the services on
+		// behalf of the user, and call next handler.
 		ctx := context.WithValue(r.Context(), AuthKey, u)
+		ctx = context.WithValue(ctx, SessionKey, claims.SessionID)
+		ctx = actor.WithUser(ctx, u.ID)
 		h(w, r.WithContext(ctx))
 	}
 }
 
-// GetUserFromJWT retrieves the user from the given JWT.
-func GetUserFromJWT(ac *apictx.Context, headerToken string) (*proto.User, error) {
+// GetUserFromJWT retrieves the user and token claims from the given JWT.
+//
+// The session the JWT was issued for must still exist, so revoking a session
+// revokes its JWTs too, without waiting for them to expire.
+func GetUserFromJWT(ctx context.Context, ac *apictx.Context, headerToken string) (*proto.User, *TokenClaims, error) {
 	// Get the signing key for this user from the JWT claims.
-	signingKey, err := GetUserSigningKey(ac, headerToken)
+	signingKey, err := GetUserSigningKey(ctx, ac, headerToken)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Parse the token.
@@ -123,25 +155,37 @@ func GetUserFromJWT(ac *apictx.Context, headerToken string) (*proto.User, error)
 		return signingKey, nil
 	})
 	if err != nil {
-		return nil, ErrJWTUnauthorized
+		return nil, nil, ErrJWTUnauthorized
 	}
 
 	// Get token claims and check token validity.
 	claims, ok := token.Claims.(*TokenClaims)
 	if !ok || !token.Valid {
-		return nil, ErrJWTUnauthorized
+		return nil, nil, ErrJWTUnauthorized
 	}
 
-	// Get the user using the UserID claim.
-	u, err := ac.Service.User.GetByID(claims.UserID)
+	// Get the user using the UserID claim. The user is only read, so it may
+	// come from the cache.
+	u, err := ac.Service.User.GetByID(cache.Allow(ctx), claims.UserID)
 	switch {
 	case err == serverrors.ErrUserNotFound:
-		return nil, ErrJWTUnauthorized
+		return nil, nil, ErrJWTUnauthorized
 	case err != nil:
-		return nil, err
+		return nil, nil, err
 	}
 
-	return u, nil
+	// Get the session using the SessionID claim.
+	s, err := ac.Service.Session.GetByID(ctx, claims.SessionID)
+	switch {
+	case err == serverrors.ErrSessionNotFound:
+		return nil, nil, ErrJWTUnauthorized
+	case err != nil:
+		return nil, nil, err
+	case s.UserID != u.ID:
+		return nil, nil, ErrJWTUnauthorized
+	}
+
+	return u, claims, nil
 }
 
 // GetUserSigningKey creates the unique JWT signing key for the given user
@@ -149,7 +193,7 @@ func GetUserFromJWT(ac *apictx.Context, headerToken string) (*proto.User, error)
 //
 // The claims are parsed from the payload portion of the token to get the
 // user ID, which is then used to retrieve the hashed user password.
-func GetUserSigningKey(ac *apictx.Context, token string) ([]byte, error) {
+func GetUserSigningKey(ctx context.Context, ac *apictx.Context, token string) ([]byte, error) {
 	// Split token.
 	parts := strings.Split(token, ".")
 	if len(parts) != 3 {
@@ -168,8 +212,10 @@ func GetUserSigningKey(ac *apictx.Context, token string) ([]byte, error) {
 		return []byte{}, err
 	}
 
-	// Get the user from the UserID claim.
-	u, err := ac.Service.User.GetByID(claims.UserID)
+	// Get the user from the UserID claim. The password hash is part of the
+	// key, so the user is never read from the cache, or tokens would keep
+	// working after the password is reset on another instance.
+	u, err := ac.Service.User.GetByID(ctx, claims.UserID)
 	switch {
 	case err == serverrors.ErrUserNotFound:
 		return []byte{}, ErrJWTUnauthorized
@@ -197,3 +243,30 @@ func GetUserFromRequest(r *http.Request) (*proto.User, error) {
 	}
 	return u, nil
 }
+
+// GetSessionIDFromRequest retrieves the authenticated session ID from the
+// request context.
+func GetSessionIDFromRequest(r *http.Request) (string, error) {
+	id, ok := r.Context().Value(SessionKey).(string)
+	if !ok {
+		return "", fmt.Errorf("could not type assert session ID from request context")
+	}
+	return id, nil
+}
+
+// RateLimitKey returns the key a request is rate limited under. This is the
+// authenticated user if there is one, then the API key if one is passed, and
+// otherwise the client IP address.
+func RateLimitKey(ac *apictx.Context, r *http.Request) string {
+	if u, err := GetUserFromRequest(r); err == nil {
+		return fmt.Sprintf("user:%d", u.ID)
+	}
+
+	// Only a hash of the API key is kept as the key.
+	if apiKey, _, ok := r.BasicAuth(); ok {
+		sum := sha256.Sum256([]byte(apiKey))
+		return "key:" + hex.EncodeToString(sum[:])
+	}
+
+	return "ip:" + ClientIP(ac, r)
+}
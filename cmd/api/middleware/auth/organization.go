@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+)
+
+// OrganizationHeader defines the header used to choose the organization a
+// request acts on.
+const OrganizationHeader = "X-Organization-ID"
+
+// MemberKey is the key used for storing and retrieving the organization member
+// data from the request context.
+var MemberKey key = 3
+
+// AuthorizeEndpoint is the middleware for authorizing API requests within an
+// organization.
+//
+// The request is authenticated first. The organization is taken from the
+// X-Organization-ID header, or is the first organization the user is a member
+// of if the header is not set. The role of the user in the organization must
+// grant the given permission.
+//
+// This only rejects requests early with the right status. The services check
+// the permissions of the user again on each call, as they are also called by
+// other processes.
+func AuthorizeEndpoint(ac *apictx.Context, permission proto.OrganizationPermission, h http.HandlerFunc) http.HandlerFunc {
+	return AuthenticateEndpoint(ac, func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this user from the request context.
+		user, err := GetUserFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Get the organization ID.
+		var organizationID uint
+		if header := r.Header.Get(OrganizationHeader); header != "" {
+			id, err := strconv.ParseUint(header, 10, 64)
+			if err != nil {
+				errors.Default(ac.Logger, w, errors.ErrOrganizationIDInvalid)
+				return
+			}
+
+			organizationID = uint(id)
+		} else {
+			organizations, err := ac.Service.Organization.GetForUser(r.Context(), user.ID)
+			if err != nil {
+				ac.Logger.Error("organization.GetForUser() service error",
+					slog.Any("error", err))
+				errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+				return
+			}
+
+			if len(organizations) == 0 {
+				errors.Default(ac.Logger, w, errors.New(http.StatusNotFound, "", serverrors.ErrOrganizationNotFound.Error()))
+				return
+			}
+
+			organizationID = organizations[0].ID
+		}
+
+		// Authorize the user.
+		member, err := ac.Service.Organization.Authorize(r.Context(), &proto.OrganizationAuthorizeParams{
+			OrganizationID: organizationID,
+			UserID:         user.ID,
+			Permission:     permission,
+		})
+		if err == serverrors.ErrOrganizationNotFound {
+			errors.Default(ac.Logger, w, errors.New(http.StatusNotFound, "", err.Error()))
+			return
+		} else if err == serverrors.ErrOrganizationForbidden {
+			errors.Default(ac.Logger, w, errors.New(http.StatusForbidden, "", err.Error()))
+			return
+		} else if err != nil {
+			ac.Logger.Error("organization.Authorize() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Pass member to request context and call next handler.
+		ctx := context.WithValue(r.Context(), MemberKey, member)
+		h(w, r.WithContext(ctx))
+	})
+}
+
+// GetMemberFromRequest retrieves the authorized organization member from the
+// request context.
+func GetMemberFromRequest(r *http.Request) (*proto.OrganizationMember, error) {
+	m, ok := r.Context().Value(MemberKey).(*proto.OrganizationMember)
+	if !ok {
+		return nil, fmt.Errorf("could not type assert organization member from request context")
+	}
+	return m, nil
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/proto"
+)
+
+// Token defines the tokens handed to a client when it logs in or refreshes
+// its session.
+//
+// The access token is a short lived JWT used with the Authorization header.
+// The refresh token is exchanged for a new pair of tokens using the
+// /api/v1/token/refresh route, and can only be used once.
+//
+// When the user has two-factor authentication enabled, logging in only hands
+// out a challenge token instead, which is exchanged for the other tokens
+// along with a code using the /api/v1/login/2fa route.
+type Token struct {
+	AccessToken       string `json:"access_token,omitempty"`
+	RefreshToken      string `json:"refresh_token,omitempty"`
+	TokenType         string `json:"token_type,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+	ExpiresIn         int64  `json:"expires_in"`
+}
+
+// NewToken creates a new session for the given user from the request, and
+// returns its tokens.
+func NewToken(ac *apictx.Context, r *http.Request, u *proto.User) (*Token, error) {
+	// Create the session.
+	s, err := ac.Service.Session.Create(r.Context(), &proto.SessionCreateParams{
+		UserID:    u.ID,
+		UserAgent: r.UserAgent(),
+		IPAddress: ClientIP(ac, r),
+		Expiry:    time.Minute * ac.Config.RefreshExpiryTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSessionToken(ac, u, s)
+}
+
+// NewLoginToken returns the tokens for a user who just proved who they are
+// with their password.
+//
+// If the user has two-factor authentication enabled, only a challenge token
+// is returned, otherwise a new session is created the same as NewToken.
+func NewLoginToken(ac *apictx.Context, r *http.Request, u *proto.User) (*Token, error) {
+	if u.TwoFactorEnabledAt == nil {
+		return NewToken(ac, r, u)
+	}
+
+	// Create the login challenge.
+	c, err := ac.Service.User.CreateLoginChallenge(r.Context(), u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Token{
+		TwoFactorRequired: true,
+		ChallengeToken:    c.Token,
+		ExpiresIn:         int64(time.Until(c.ExpiresAt).Seconds()),
+	}, nil
+}
+
+// NewSessionToken returns the tokens for a session that was just created or
+// refreshed.
+func NewSessionToken(ac *apictx.Context, u *proto.User, s *proto.Session) (*Token, error) {
+	// Issue a new JWT for this session.
+	accessToken, err := NewJWT(ac, u.Password, u.ID, s.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Token{
+		AccessToken:  accessToken,
+		RefreshToken: s.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64((time.Minute * ac.Config.JWTExpiryTime).Seconds()),
+	}, nil
+}
+
+// ClientIP returns the IP address of the client making the request, from
+// the X-Forwarded-For header if the request came from a trusted proxy.
+func ClientIP(ac *apictx.Context, r *http.Request) string {
+	return ac.Proxies.ClientIP(r)
+}
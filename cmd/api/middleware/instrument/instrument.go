@@ -0,0 +1,47 @@
+package instrument
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/cmd/api/response"
+	"dddstructure/metrics"
+
+	"github.com/beeker1121/httprouter"
+)
+
+// unmatchedRoute defines the route requests that match no route are counted
+// under, so unknown paths can not create new series.
+const unmatchedRoute = "unmatched"
+
+// InstrumentRequests is the middleware for recording metrics of API
+// requests.
+//
+// The number of requests and their latency are recorded to the given
+// registry by method, route and status. Routes are recorded by the pattern
+// they were registered with by logging.Handle, as they are logged.
+func InstrumentRequests(registry *metrics.Registry, router *httprouter.Router, h http.Handler) http.Handler {
+	requests := registry.NewCounter("dddstructure_http_requests_total",
+		"Number of HTTP requests.", "method", "route", "status")
+	latency := registry.NewHistogram("dddstructure_http_request_duration_seconds",
+		"Latency of HTTP requests.", metrics.DefaultBuckets, "method", "route", "status")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		// Handle the request.
+		sw := response.NewStatusWriter(w)
+		h.ServeHTTP(sw, r)
+
+		route := logging.Route(router, r)
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(sw.Status())
+
+		requests.Inc(r.Method, route, status)
+		latency.Observe(time.Since(start).Seconds(), r.Method, route, status)
+	})
+}
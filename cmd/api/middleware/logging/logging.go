@@ -0,0 +1,143 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/response"
+	"dddstructure/trace"
+
+	"github.com/beeker1121/httprouter"
+)
+
+// RequestIDHeader defines the header used to pass the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength defines the maximum length of a request ID accepted from
+// a client.
+const maxRequestIDLength = 128
+
+// key is the key type used by this package for the request context.
+type key int
+
+// entryKey is the key used for storing and retrieving the log entry from the
+// request context.
+var entryKey key = 1
+
+// entry defines the details of a request only known once it is handled.
+type entry struct {
+	userID uint
+}
+
+// LogRequests is the middleware for logging API requests.
+//
+// Each request is given the request ID from the X-Request-ID header, or a new
+// one if it is not set or invalid, which is returned in the X-Request-ID
+// header. The handlers and services log with a logger that includes the
+// request ID, and the trace ID if the request is traced, taken from the API
+// context scoped to the request. Once the request is handled, its method,
+// route, status, latency and user ID are logged.
+func LogRequests(ac *apictx.Context, router *httprouter.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		// Get the request ID.
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		// Scope the API context to this request.
+		e := &entry{}
+		logger := ac.Logger.With(slog.String("request_id", id))
+		if sc := trace.SpanFromContext(r.Context()).SpanContext(); sc.IsValid() {
+			logger = logger.With(slog.String("trace_id", sc.TraceID.String()))
+		}
+		rac := ac.WithLogger(logger)
+		r = apictx.WithRequest(r, rac)
+		r = r.WithContext(context.WithValue(r.Context(), entryKey, e))
+
+		// Handle the request.
+		sw := response.NewStatusWriter(w)
+		router.ServeHTTP(sw, r)
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("route", Route(router, r)),
+			slog.Int("status", sw.Status()),
+			slog.Duration("latency", time.Since(start)),
+		}
+		if e.userID != 0 {
+			attrs = append(attrs, slog.Uint64("user_id", uint64(e.userID)))
+		}
+
+		rac.Logger.LogAttrs(r.Context(), slog.LevelInfo, "request", attrs...)
+	})
+}
+
+// SetUserID sets the ID of the authenticated user making the request, to be
+// logged once the request is handled.
+func SetUserID(r *http.Request, userID uint) {
+	if e, ok := r.Context().Value(entryKey).(*entry); ok {
+		e.userID = userID
+	}
+}
+
+// route defines a handler registered with the pattern of its route.
+type route struct {
+	pattern string
+	http.HandlerFunc
+}
+
+// Handle registers the given handler for the given method and route pattern,
+// such as "/api/v1/invoice/:id", recording the pattern so requests to the
+// route are logged, traced and counted by it rather than by their path.
+func Handle(router *httprouter.Router, method, pattern string, h http.HandlerFunc) {
+	router.Handler(method, pattern, &route{pattern: pattern, HandlerFunc: h})
+}
+
+// Route returns the pattern of the route the request matched, or an empty
+// string if it matched no route registered with Handle.
+func Route(router *httprouter.Router, r *http.Request) string {
+	handler, _, _ := router.Lookup(r.Method, r.URL.Path)
+	if rt, ok := handler.(*route); ok {
+		return rt.pattern
+	}
+
+	return ""
+}
+
+// validRequestID checks if a request ID from a client is safe to log and
+// return.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// newRequestID generates a new random request ID.
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dddstructure/cmd/api/config"
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+)
+
+// KeyFunc returns the key a request is rate limited under, using the API
+// context scoped to the request.
+type KeyFunc func(ac *apictx.Context, r *http.Request) string
+
+// LimitEndpoint is the middleware for rate limiting API requests.
+//
+// Requests are limited by the rate limit set for the given route group in
+// the config, under the key returned by the given function. The RateLimit-*
+// headers are set on every response, and once the limit is exceeded the
+// request is rejected with 429 and a Retry-After header.
+func LimitEndpoint(ac *apictx.Context, group config.RateLimitGroup, key KeyFunc, h http.HandlerFunc) http.HandlerFunc {
+	l, ok := ac.Limiters[group]
+	if !ok {
+		return h
+	}
+
+	policy := fmt.Sprintf("%d;w=%d", l.Limit(), int(l.Period()/time.Second))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		result := l.Take(string(group)+":"+key(ac, r), time.Now())
+
+		// Set the rate limit headers.
+		w.Header().Set("RateLimit-Policy", policy)
+		w.Header().Set("RateLimit-Limit", strconv.FormatUint(uint64(result.Limit), 10))
+		w.Header().Set("RateLimit-Remaining", strconv.FormatUint(uint64(result.Remaining), 10))
+		w.Header().Set("RateLimit-Reset", seconds(result.Reset))
+
+		if !result.Allowed {
+			w.Header().Set("Retry-After", seconds(result.RetryAfter))
+			errors.Default(ac.Logger, w, errors.ErrTooManyRequests)
+			return
+		}
+
+		h(w, r)
+	}
+}
+
+// seconds formats a duration as a whole number of seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
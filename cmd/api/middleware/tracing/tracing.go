@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/cmd/api/response"
+	"dddstructure/trace"
+
+	"github.com/beeker1121/httprouter"
+)
+
+// TraceRequests is the middleware for tracing API requests.
+//
+// Each request is handled in a server span named after its method and route,
+// which the spans of the services and storage are children of. If the
+// request has a valid traceparent header the span joins that trace, otherwise
+// a new trace is started.
+func TraceRequests(tracer *trace.Tracer, router *httprouter.Router, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Get the trace context of the caller, if any.
+		remote, _ := trace.ParseTraceparent(r.Header.Get(trace.TraceparentHeader))
+
+		route := logging.Route(router, r)
+		name := r.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		ctx, span := tracer.Start(r.Context(), name, trace.SpanKindServer, remote)
+		defer span.End()
+
+		span.SetAttributes(
+			trace.String("http.request.method", r.Method),
+			trace.String("http.route", route),
+			trace.String("url.path", r.URL.Path),
+		)
+
+		// Handle the request.
+		sw := response.NewStatusWriter(w)
+		h.ServeHTTP(sw, r.WithContext(ctx))
+
+		span.SetAttributes(trace.Int("http.response.status_code", sw.Status()))
+		if sw.Status() >= http.StatusInternalServerError {
+			span.SetError(fmt.Errorf("responded with status %d", sw.Status()))
+		}
+	})
+}
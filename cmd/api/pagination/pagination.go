@@ -0,0 +1,132 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	apictx "dddstructure/cmd/api/context"
+)
+
+// cursorSecretLabel defines the label the secret for signing cursors is
+// derived with.
+const cursorSecretLabel = "dddstructure pagination cursor"
+
+// ErrCursorInvalid is returned when a cursor could not be decoded or its
+// signature does not match.
+var ErrCursorInvalid = errors.New("cursor is invalid")
+
+// Cursor defines a keyset pagination cursor.
+//
+// All list endpoints order their results by created at datetime and ID, so a
+// cursor only has to point at the entry to page from. Before determines if
+// the page before or after that entry is requested.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uint
+	Before    bool
+}
+
+// payload defines the encoded cursor payload.
+type payload struct {
+	CreatedAt int64 `json:"c"`
+	ID        uint  `json:"i"`
+	Before    bool  `json:"b,omitempty"`
+}
+
+// EncodeCursor encodes the given cursor into an opaque string, signed using
+// the given secret.
+//
+// The signature stops clients from crafting their own cursors, so the cursor
+// format can change without breaking anyone relying on it.
+func EncodeCursor(secret string, c Cursor) (string, error) {
+	b, err := json.Marshal(payload{
+		CreatedAt: c.CreatedAt.UnixNano(),
+		ID:        c.ID,
+		Before:    c.Before,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	data := base64.RawURLEncoding.EncodeToString(b)
+	sig := base64.RawURLEncoding.EncodeToString(sign(secret, data))
+
+	return data + "." + sig, nil
+}
+
+// DecodeCursor verifies and decodes the given cursor string.
+func DecodeCursor(secret, s string) (Cursor, error) {
+	// Split the data from the signature.
+	data, sig, ok := strings.Cut(s, ".")
+	if !ok {
+		return Cursor{}, ErrCursorInvalid
+	}
+
+	// Check the signature.
+	sigb, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(sigb, sign(secret, data)) {
+		return Cursor{}, ErrCursorInvalid
+	}
+
+	// Decode the payload.
+	b, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return Cursor{}, ErrCursorInvalid
+	}
+
+	var p payload
+	if err := json.Unmarshal(b, &p); err != nil {
+		return Cursor{}, ErrCursorInvalid
+	}
+
+	return Cursor{
+		CreatedAt: time.Unix(0, p.CreatedAt).UTC(),
+		ID:        p.ID,
+		Before:    p.Before,
+	}, nil
+}
+
+// Link builds a link to the requested endpoint for the given cursor.
+//
+// The Host header is set by the client, so the link only has a scheme and
+// host if the host is one of the public hosts of the API in the config, and
+// is relative otherwise. Every query parameter other than the cursor is
+// kept, so any active filters carry over to the linked page.
+func Link(ac *apictx.Context, r *http.Request, cursor string) string {
+	// Handle query parameters.
+	query := r.URL.Query()
+	query.Del("offset")
+	query.Set("cursor", cursor)
+
+	link := r.URL.Path + "?" + query.Encode()
+
+	// Handle scheme and host.
+	for _, host := range ac.Config.PublicHosts {
+		if strings.EqualFold(r.Host, host) {
+			return ac.Proxies.Scheme(r) + "://" + host + link
+		}
+	}
+
+	return link
+}
+
+// DeriveSecret derives a secret for signing cursors from the given secret,
+// such as the JWT secret, so a cursor signature can never be used as a token
+// signature or the other way around.
+func DeriveSecret(secret string) string {
+	return hex.EncodeToString(sign(secret, cursorSecretLabel))
+}
+
+// sign returns the HMAC-SHA256 signature of the given data.
+func sign(secret, data string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,126 @@
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Trusted defines the proxies in front of the API, such as the ingress,
+// which are trusted to set the X-Forwarded-* headers. The headers of any
+// other client are ignored, as a client can set them to anything.
+//
+// A nil Trusted trusts no proxy.
+type Trusted struct {
+	prefixes []netip.Prefix
+}
+
+// New creates a new set of trusted proxies from the given IP addresses and
+// CIDR ranges, such as "10.0.0.1" or "10.0.0.0/8".
+func New(proxies []string) (*Trusted, error) {
+	t := &Trusted{}
+	for _, p := range proxies {
+		if !strings.Contains(p, "/") {
+			addr, err := netip.ParseAddr(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %s", p)
+			}
+			t.prefixes = append(t.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %s", p)
+		}
+		t.prefixes = append(t.prefixes, prefix.Masked())
+	}
+
+	return t, nil
+}
+
+// Contains returns whether the given IP address is a trusted proxy.
+func (t *Trusted) Contains(ip string) bool {
+	if t == nil {
+		return false
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, p := range t.prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Scheme returns the scheme of the request as the client made it, either
+// "http" or "https".
+//
+// The X-Forwarded-Proto header is only used if the request came from a
+// trusted proxy, and only if it is "http" or "https".
+func (t *Trusted) Scheme(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	if t.Contains(remoteIP(r)) {
+		switch proto := r.Header.Get("X-Forwarded-Proto"); proto {
+		case "http", "https":
+			scheme = proto
+		}
+	}
+
+	return scheme
+}
+
+// ClientIP returns the IP address of the client making the request.
+//
+// Each proxy appends the address it received the request from to the
+// X-Forwarded-For header, so if the request came from a trusted proxy, the
+// client is the last address in the header that is not a trusted proxy.
+// Addresses before it were set by the client, so are never used.
+func (t *Trusted) ClientIP(r *http.Request) string {
+	ip := remoteIP(r)
+	if !t.Contains(ip) {
+		return ip
+	}
+
+	var forwarded []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		for _, addr := range strings.Split(h, ",") {
+			forwarded = append(forwarded, strings.TrimSpace(addr))
+		}
+	}
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		if _, err := netip.ParseAddr(forwarded[i]); err != nil {
+			break
+		}
+
+		ip = forwarded[i]
+		if !t.Contains(ip) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// remoteIP returns the IP address the request was received from.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,200 @@
+package invoice
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"dddstructure/cmd/api/errors"
+	"dddstructure/proto"
+)
+
+// csvHeader defines the CSV columns used for import and export.
+//
+// Exports write every column. Imports find the columns by name using the
+// header row, and ignore the columns that can not be set on create, so an
+// export can be imported again as is.
+var csvHeader = []string{
+	"id",
+	"public_hash",
+	"invoice_number",
+	"po_number",
+	"currency",
+	"due_date",
+	"message",
+	"bill_to_first_name",
+	"bill_to_last_name",
+	"bill_to_company",
+	"bill_to_address_line_1",
+	"bill_to_address_line_2",
+	"bill_to_city",
+	"bill_to_state",
+	"bill_to_postal_code",
+	"bill_to_country",
+	"bill_to_email",
+	"bill_to_phone",
+	"pay_to_first_name",
+	"pay_to_last_name",
+	"pay_to_company",
+	"pay_to_address_line_1",
+	"pay_to_address_line_2",
+	"pay_to_city",
+	"pay_to_state",
+	"pay_to_postal_code",
+	"pay_to_country",
+	"pay_to_email",
+	"pay_to_phone",
+	"line_items",
+	"payment_methods",
+	"tax_rate",
+	"amount_due",
+	"amount_paid",
+	"status",
+	"created_at",
+}
+
+// csvPaymentMethodsSep defines the separator used between payment methods in
+// the payment_methods column.
+const csvPaymentMethodsSep = ";"
+
+// csvRecordToRequest handles mapping a CSV record to the HandlePost request
+// data, using the given column indexes from the header row.
+//
+// Line items are stored as a JSON array in the line_items column, and payment
+// methods are separated by a semicolon in the payment_methods column.
+func csvRecordToRequest(columns map[string]int, record []string) (RequestPost, *errors.Errors) {
+	// Create a new API Errors.
+	errs := &errors.Errors{}
+
+	// get returns the value of the given column.
+	get := func(column string) string {
+		n, ok := columns[column]
+		if !ok || n >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[n])
+	}
+
+	req := RequestPost{
+		InvoiceNumber: get("invoice_number"),
+		PONumber:      get("po_number"),
+		Currency:      get("currency"),
+		Message:       get("message"),
+		BillTo: BillTo{
+			FirstName:    get("bill_to_first_name"),
+			LastName:     get("bill_to_last_name"),
+			Company:      get("bill_to_company"),
+			AddressLine1: get("bill_to_address_line_1"),
+			AddressLine2: get("bill_to_address_line_2"),
+			City:         get("bill_to_city"),
+			State:        get("bill_to_state"),
+			PostalCode:   get("bill_to_postal_code"),
+			Country:      get("bill_to_country"),
+			Email:        get("bill_to_email"),
+			Phone:        get("bill_to_phone"),
+		},
+		PayTo: PayTo{
+			FirstName:    get("pay_to_first_name"),
+			LastName:     get("pay_to_last_name"),
+			Company:      get("pay_to_company"),
+			AddressLine1: get("pay_to_address_line_1"),
+			AddressLine2: get("pay_to_address_line_2"),
+			City:         get("pay_to_city"),
+			State:        get("pay_to_state"),
+			PostalCode:   get("pay_to_postal_code"),
+			Country:      get("pay_to_country"),
+			Email:        get("pay_to_email"),
+			Phone:        get("pay_to_phone"),
+		},
+		TaxRate: get("tax_rate"),
+	}
+
+	// Handle due date.
+	if dueDate := get("due_date"); dueDate != "" {
+		t, err := time.Parse("2006-01-02", dueDate)
+		if err != nil {
+			errs.Add(errors.New(http.StatusBadRequest, "due_date", "invalid due date, must be in YYYY-MM-DD format"))
+		} else {
+			req.DueDate = DueDate{t}
+		}
+	}
+
+	// Handle line items.
+	if lineItems := get("line_items"); lineItems != "" {
+		if err := json.Unmarshal([]byte(lineItems), &req.LineItems); err != nil {
+			errs.Add(errors.New(http.StatusBadRequest, "line_items", "invalid line items, must be a JSON array"))
+		}
+	}
+
+	// Handle payment methods.
+	if paymentMethods := get("payment_methods"); paymentMethods != "" {
+		for _, v := range strings.Split(paymentMethods, csvPaymentMethodsSep) {
+			req.PaymentMethods = append(req.PaymentMethods, proto.InvoicePaymentMethod(strings.TrimSpace(v)))
+		}
+	}
+
+	return req, errs
+}
+
+// invoiceToCSVRecord handles mapping a response invoice type to a CSV record,
+// in the same order as the CSV header.
+func invoiceToCSVRecord(i Invoice) ([]string, error) {
+	// Handle line items.
+	lineItems, err := json.Marshal(i.LineItems)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle payment methods.
+	paymentMethods := []string{}
+	for _, v := range i.PaymentMethods {
+		paymentMethods = append(paymentMethods, string(v))
+	}
+
+	// Handle due date, leaving it empty if it was never set.
+	dueDate := ""
+	if i.DueDate.Year() > 1 {
+		dueDate = i.DueDate.Format("2006-01-02")
+	}
+
+	return []string{
+		strconv.FormatUint(uint64(i.ID), 10),
+		i.PublicHash,
+		i.InvoiceNumber,
+		i.PONumber,
+		i.Currency,
+		dueDate,
+		i.Message,
+		i.BillTo.FirstName,
+		i.BillTo.LastName,
+		i.BillTo.Company,
+		i.BillTo.AddressLine1,
+		i.BillTo.AddressLine2,
+		i.BillTo.City,
+		i.BillTo.State,
+		i.BillTo.PostalCode,
+		i.BillTo.Country,
+		i.BillTo.Email,
+		i.BillTo.Phone,
+		i.PayTo.FirstName,
+		i.PayTo.LastName,
+		i.PayTo.Company,
+		i.PayTo.AddressLine1,
+		i.PayTo.AddressLine2,
+		i.PayTo.City,
+		i.PayTo.State,
+		i.PayTo.PostalCode,
+		i.PayTo.Country,
+		i.PayTo.Email,
+		i.PayTo.Phone,
+		string(lineItems),
+		strings.Join(paymentMethods, csvPaymentMethodsSep),
+		i.TaxRate,
+		strconv.FormatUint(uint64(i.AmountDue), 10),
+		strconv.FormatUint(uint64(i.AmountPaid), 10),
+		i.Status,
+		i.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
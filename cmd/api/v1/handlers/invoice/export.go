@@ -0,0 +1,192 @@
+package invoice
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+)
+
+// exportBatchSize defines how many invoices are read from the service at a
+// time while exporting.
+const exportBatchSize = 100
+
+// exportWriteTimeout defines how long writing each batch of an export may
+// take. The deadline is extended before each batch, so exports are not cut
+// off by the server write timeout.
+const exportWriteTimeout = 10 * time.Second
+
+// exportWriter defines a writer for a single export format.
+type exportWriter interface {
+	Write(i Invoice) error
+	Flush() error
+}
+
+// csvExportWriter writes invoices as CSV records.
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+// Write implements the exportWriter interface.
+func (cw *csvExportWriter) Write(i Invoice) error {
+	record, err := invoiceToCSVRecord(i)
+	if err != nil {
+		return err
+	}
+
+	return cw.w.Write(record)
+}
+
+// Flush implements the exportWriter interface.
+func (cw *csvExportWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonLinesExportWriter writes invoices as JSON Lines.
+type jsonLinesExportWriter struct {
+	enc *json.Encoder
+}
+
+// Write implements the exportWriter interface.
+func (jw *jsonLinesExportWriter) Write(i Invoice) error {
+	return jw.enc.Encode(i)
+}
+
+// Flush implements the exportWriter interface.
+func (jw *jsonLinesExportWriter) Flush() error {
+	return nil
+}
+
+// HandleExport handles the /api/v1/invoice/export GET route of the API.
+//
+// The format query parameter is either csv, the default, or ndjson. The same
+// filters as the /api/v1/invoice GET route are supported.
+//
+// Invoices are read and written in batches using a cursor, so the full export
+// is never held in memory.
+func HandleExport(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new GetParams.
+		params := &proto.InvoiceGetParams{
+			OrganizationID: &member.OrganizationID,
+			Limit:          exportBatchSize,
+		}
+
+		// Create a new API Errors.
+		errs := &errors.Errors{}
+
+		// Handle filters.
+		parseFilters(r, params, errs)
+
+		// Handle format.
+		format := "csv"
+		if formatqs, ok := r.URL.Query()["format"]; ok && len(formatqs) == 1 {
+			format = formatqs[0]
+		}
+		if format != "csv" && format != "ndjson" {
+			errs.Add(errors.New(http.StatusBadRequest, "format", "Format parameter is invalid, must be either 'csv' or 'ndjson'"))
+		}
+
+		// Return if there were errors.
+		if errs.Length() > 0 {
+			errors.Multiple(ac.Logger, w, http.StatusBadRequest, errs)
+			return
+		}
+
+		// Get the first batch of invoices before writing anything, so errors
+		// can still be returned as JSON.
+		invoices, err := ac.Service.Invoice.Get(r.Context(), params)
+		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
+			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
+			return
+		} else if err != nil {
+			ac.Logger.Error("invoice.Get() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create the writer and set headers.
+		var ew exportWriter
+		if format == "csv" {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="invoices.csv"`)
+
+			cw := csv.NewWriter(w)
+			if err := cw.Write(csvHeader); err != nil {
+				ac.Logger.Error("csv.Writer.Write() error",
+					slog.Any("error", err))
+				return
+			}
+
+			ew = &csvExportWriter{w: cw}
+		} else {
+			w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="invoices.ndjson"`)
+
+			ew = &jsonLinesExportWriter{enc: json.NewEncoder(w)}
+		}
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		// Write each batch of invoices.
+		rc := http.NewResponseController(w)
+		for {
+			// Extend the write deadline. This fails if the response writer
+			// does not support deadlines, in which case the server write
+			// timeout applies as usual.
+			rc.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+
+			for _, i := range invoices {
+				if err := ew.Write(protoToInvoice(i)); err != nil {
+					ac.Logger.Error("exportWriter.Write() error",
+						slog.Any("error", err))
+					return
+				}
+			}
+
+			if err := ew.Flush(); err != nil {
+				ac.Logger.Error("exportWriter.Flush() error",
+					slog.Any("error", err))
+				return
+			}
+			rc.Flush()
+
+			// Stop on the last batch.
+			if len(invoices) < exportBatchSize {
+				return
+			}
+
+			// Get the next batch.
+			last := invoices[len(invoices)-1]
+			params.Cursor = &proto.InvoiceGetParamsCursor{
+				CreatedAt: last.CreatedAt,
+				ID:        last.ID,
+			}
+
+			invoices, err = ac.Service.Invoice.Get(r.Context(), params)
+			if err != nil {
+				ac.Logger.Error("invoice.Get() service error",
+					slog.Any("error", err))
+				return
+			}
+		}
+	}
+}
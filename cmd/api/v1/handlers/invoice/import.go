@@ -0,0 +1,307 @@
+package invoice
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/response"
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+)
+
+// importBodyMax defines the maximum size of an import request body.
+const importBodyMax = 10 << 20
+
+// importRow defines a single parsed row of an import.
+type importRow struct {
+	req  RequestPost
+	errs *errors.Errors
+}
+
+// ImportResult defines the result of importing a single invoice.
+type ImportResult struct {
+	Row     uint           `json:"row"`
+	Invoice *Invoice       `json:"invoice"`
+	Errors  *errors.Errors `json:"errors,omitempty"`
+}
+
+// ImportMeta defines the import response top level meta object.
+type ImportMeta struct {
+	Total        uint `json:"total"`
+	Created      uint `json:"created"`
+	Failed       uint `json:"failed"`
+	DryRun       bool `json:"dry_run"`
+	AllOrNothing bool `json:"all_or_nothing"`
+}
+
+// ResultImport defines the response data for the HandleImport handler.
+type ResultImport struct {
+	Data []ImportResult `json:"data"`
+	Meta ImportMeta     `json:"meta"`
+}
+
+// HandleImport handles the /api/v1/invoice/import POST route of the API.
+//
+// The request body is either CSV, using the same columns as the export, or
+// JSON Lines, with one HandlePost request object per line. The format is
+// chosen by the Content-Type header.
+//
+// The dry_run query parameter validates the invoices without creating them,
+// and the all_or_nothing query parameter only creates the invoices if every
+// one of them is valid.
+func HandleImport(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new API Errors.
+		errs := &errors.Errors{}
+
+		// Handle dry run.
+		var dryRun bool
+		if dryRunqs, ok := r.URL.Query()["dry_run"]; ok && len(dryRunqs) == 1 {
+			dryRun, err = strconv.ParseBool(dryRunqs[0])
+			if err != nil {
+				errs.Add(errors.New(http.StatusBadRequest, "dry_run", "Dry run parameter is invalid, must be a boolean"))
+			}
+		}
+
+		// Handle all or nothing.
+		var allOrNothing bool
+		if allOrNothingqs, ok := r.URL.Query()["all_or_nothing"]; ok && len(allOrNothingqs) == 1 {
+			allOrNothing, err = strconv.ParseBool(allOrNothingqs[0])
+			if err != nil {
+				errs.Add(errors.New(http.StatusBadRequest, "all_or_nothing", "All or nothing parameter is invalid, must be a boolean"))
+			}
+		}
+
+		// Return if there were errors.
+		if errs.Length() > 0 {
+			errors.Multiple(ac.Logger, w, http.StatusBadRequest, errs)
+			return
+		}
+
+		// Read the rows from the request body.
+		body := http.MaxBytesReader(w, r.Body, importBodyMax)
+
+		var rows []importRow
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "text/csv":
+			rows, err = readImportCSV(body, ac.Config.ImportLimitMax)
+		case "application/x-ndjson", "application/jsonl":
+			rows, err = readImportJSONLines(body, ac.Config.ImportLimitMax)
+		default:
+			errors.Default(ac.Logger, w, errors.ErrImportContentType)
+			return
+		}
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.New(http.StatusBadRequest, "", "Could not read import: "+err.Error()))
+			return
+		}
+
+		// Check the import limit.
+		if uint(len(rows)) > ac.Config.ImportLimitMax {
+			errors.Default(ac.Logger, w, errors.ErrImportLimitMax(ac.Config.ImportLimitMax))
+			return
+		}
+
+		// Build the invoices to import from the rows that could be parsed.
+		// If any rows could not be parsed in all or nothing mode, the rest
+		// are only validated.
+		params := &proto.InvoiceImportParams{
+			OrganizationID: member.OrganizationID,
+			UserID:         member.UserID,
+			DryRun:         dryRun,
+			AllOrNothing:   allOrNothing,
+		}
+
+		for _, row := range rows {
+			if row.errs.Length() > 0 {
+				if allOrNothing {
+					params.DryRun = true
+				}
+				continue
+			}
+
+			params.Invoices = append(params.Invoices, requestPostToParams(member, row.req))
+		}
+
+		// Import the invoices.
+		results, err := ac.Service.Invoice.Import(r.Context(), params)
+		if err != nil {
+			ac.Logger.Error("invoice.Import() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new Result.
+		result := ResultImport{
+			Data: []ImportResult{},
+			Meta: ImportMeta{
+				Total:        uint(len(rows)),
+				DryRun:       dryRun,
+				AllOrNothing: allOrNothing,
+			},
+		}
+
+		// Loop through the rows, matching them up with the results of the
+		// rows that were imported.
+		n := 0
+		for i, row := range rows {
+			ir := ImportResult{
+				Row: uint(i + 1),
+			}
+
+			if row.errs.Length() > 0 {
+				ir.Errors = row.errs
+			} else {
+				if results[n].Invoice != nil {
+					invoice := protoToInvoice(results[n].Invoice)
+					ir.Invoice = &invoice
+				}
+
+				if results[n].Error != nil {
+					ir.Errors = importErrors(ac, results[n].Error)
+				}
+				n++
+			}
+
+			if ir.Errors != nil {
+				result.Meta.Failed++
+			}
+			if ir.Invoice != nil {
+				result.Meta.Created++
+			}
+
+			result.Data = append(result.Data, ir)
+		}
+
+		// Nothing was created if all or nothing failed.
+		if allOrNothing && result.Meta.Failed > 0 {
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusBadRequest)
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
+
+// readImportCSV reads the import rows from a CSV body.
+//
+// Reading stops once more than max rows have been read.
+func readImportCSV(body io.Reader, max uint) ([]importRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+
+	// Read the header row.
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []importRow{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for n, v := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(v, "\ufeff"))] = n
+	}
+
+	// Read each record.
+	rows := []importRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if uint(len(rows)) > max {
+			break
+		}
+
+		req, errs := csvRecordToRequest(columns, record)
+		rows = append(rows, importRow{req: req, errs: errs})
+	}
+
+	return rows, nil
+}
+
+// readImportJSONLines reads the import rows from a JSON Lines body.
+//
+// Reading stops once more than max rows have been read.
+func readImportJSONLines(body io.Reader, max uint) ([]importRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), importBodyMax)
+
+	// Read each line.
+	rows := []importRow{}
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		if uint(len(rows)) > max {
+			break
+		}
+
+		row := importRow{errs: &errors.Errors{}}
+		if err := json.Unmarshal(line, &row.req); err != nil {
+			row.errs.Add(errors.New(http.StatusBadRequest, "", "invalid JSON: "+err.Error()))
+		}
+
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// importErrors handles mapping an error returned for an imported invoice to
+// API errors.
+func importErrors(ac *apictx.Context, err error) *errors.Errors {
+	errs := &errors.Errors{}
+
+	if pes, ok := err.(*serverrors.ParamErrors); ok {
+		for _, pe := range *pes {
+			errs.Add(errors.New(http.StatusBadRequest, pe.Name, pe.Error()))
+		}
+		return errs
+	}
+
+	ac.Logger.Error("invoice.Import() invoice error",
+		slog.Any("error", err))
+	errs.Add(errors.ErrInternalServerError)
+
+	return errs
+}
@@ -9,12 +9,17 @@ import (
 	"strings"
 	"time"
 
+	"dddstructure/cmd/api/config"
 	apictx "dddstructure/cmd/api/context"
 	"dddstructure/cmd/api/errors"
 	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/cmd/api/middleware/ratelimit"
+	"dddstructure/cmd/api/pagination"
 	"dddstructure/cmd/api/response"
 	"dddstructure/proto"
 	serverrors "dddstructure/service/errors"
+	"dddstructure/storage/cache"
 
 	"github.com/beeker1121/httprouter"
 )
@@ -22,13 +27,29 @@ import (
 // New creates the routes for the invoice endpoints of the API.
 func New(ac *apictx.Context, router *httprouter.Router) {
 	// Handle the routes.
-	router.POST("/api/v1/invoice", auth.AuthenticateEndpoint(ac, HandlePost(ac)))
-	router.GET("/api/v1/invoice", auth.AuthenticateEndpoint(ac, HandleGet(ac)))
-	router.GET("/api/v1/public/invoice/:hash", HandleGetPublicInvoice(ac))
-	router.POST("/api/v1/public/invoice/:hash/pay", HandlePayInvoice(ac))
-	router.GET("/api/v1/invoice/:id", auth.AuthenticateEndpoint(ac, HandleGetInvoice(ac)))
-	router.POST("/api/v1/invoice/:id", auth.AuthenticateEndpoint(ac, HandlePostUpdate(ac)))
-	router.DELETE("/api/v1/invoice/:id", auth.AuthenticateEndpoint(ac, HandleDelete(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/invoice", auth.AuthorizeEndpoint(ac, proto.OrganizationPermissionInvoiceWrite, HandlePost(ac)))
+	logging.Handle(router, http.MethodGet, "/api/v1/invoice", auth.AuthorizeEndpoint(ac, proto.OrganizationPermissionInvoiceRead, HandleGet(ac)))
+	logging.Handle(router, http.MethodGet, "/api/v1/public/invoice/:hash", ratelimit.LimitEndpoint(ac, config.RateLimitGroupPublic, auth.RateLimitKey, HandleGetPublicInvoice(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/public/invoice/:hash/pay", ratelimit.LimitEndpoint(ac, config.RateLimitGroupPublic, auth.RateLimitKey, HandlePayInvoice(ac)))
+	logging.Handle(router, http.MethodGet, "/api/v1/invoice/:id", auth.AuthorizeEndpoint(ac, proto.OrganizationPermissionInvoiceRead, handleID("export", HandleExport(ac), HandleGetInvoice(ac))))
+	logging.Handle(router, http.MethodPost, "/api/v1/invoice/:id", auth.AuthorizeEndpoint(ac, proto.OrganizationPermissionInvoiceWrite, handleID("import", HandleImport(ac), HandlePostUpdate(ac))))
+	logging.Handle(router, http.MethodDelete, "/api/v1/invoice/:id", auth.AuthorizeEndpoint(ac, proto.OrganizationPermissionInvoiceWrite, HandleDelete(ac)))
+}
+
+// handleID calls the static handler if the :id parameter matches the given
+// path segment, and the id handler otherwise.
+//
+// The router does not allow a static path segment next to a wildcard, so
+// routes such as /api/v1/invoice/export have to share the :id route.
+func handleID(segment string, static, id http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if httprouter.GetParam(r, "id") == segment {
+			static(w, r)
+			return
+		}
+
+		id(w, r)
+	}
 }
 
 // BillTo defines the billing information.
@@ -95,6 +116,7 @@ func (dd DueDate) MarshalJSON() ([]byte, error) {
 // Invoice defines an invoice.
 type Invoice struct {
 	ID             uint                         `json:"id"`
+	OrganizationID uint                         `json:"organization_id"`
 	UserID         uint                         `json:"user_id"`
 	PublicHash     string                       `json:"public_hash"`
 	InvoiceNumber  string                       `json:"invoice_number"`
@@ -135,6 +157,9 @@ type ResultPost struct {
 // HandlePost handles the /api/v1/invoice POST route of the API.
 func HandlePost(ac *apictx.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
 		// Parse the parameters from the request body.
 		var req RequestPost
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -142,64 +167,15 @@ func HandlePost(ac *apictx.Context) http.HandlerFunc {
 			return
 		}
 
-		// Get this user from the request context.
-		user, err := auth.GetUserFromRequest(r)
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
 		if err != nil {
 			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
 			return
 		}
 
-		// Handle line items.
-		lineItems := []proto.InvoiceLineItem{}
-		for _, v := range req.LineItems {
-			lineItem := proto.InvoiceLineItem{
-				Name:        v.Name,
-				Description: v.Description,
-				Quantity:    v.Quantity,
-				Price:       v.Price,
-			}
-
-			lineItems = append(lineItems, lineItem)
-		}
-
 		// Create the invoice.
-		invoice, err := ac.Service.Invoice.Create(&proto.InvoiceCreateParams{
-			UserID:        user.ID,
-			InvoiceNumber: req.InvoiceNumber,
-			PONumber:      req.PONumber,
-			Currency:      req.Currency,
-			DueDate:       time.Time(req.DueDate.Time),
-			Message:       req.Message,
-			BillTo: proto.InvoiceBillTo{
-				FirstName:    req.BillTo.FirstName,
-				LastName:     req.BillTo.LastName,
-				Company:      req.BillTo.Company,
-				AddressLine1: req.BillTo.AddressLine1,
-				AddressLine2: req.BillTo.AddressLine2,
-				City:         req.BillTo.City,
-				State:        req.BillTo.State,
-				PostalCode:   req.BillTo.PostalCode,
-				Country:      req.BillTo.Country,
-				Email:        req.BillTo.Email,
-				Phone:        req.BillTo.Phone,
-			},
-			PayTo: proto.InvoicePayTo{
-				FirstName:    req.PayTo.FirstName,
-				LastName:     req.PayTo.LastName,
-				Company:      req.PayTo.Company,
-				AddressLine1: req.PayTo.AddressLine1,
-				AddressLine2: req.PayTo.AddressLine2,
-				City:         req.PayTo.City,
-				State:        req.PayTo.State,
-				PostalCode:   req.PayTo.PostalCode,
-				Country:      req.PayTo.Country,
-				Email:        req.PayTo.Email,
-				Phone:        req.PayTo.Phone,
-			},
-			LineItems:      lineItems,
-			PaymentMethods: req.PaymentMethods,
-			TaxRate:        req.TaxRate,
-		})
+		invoice, err := ac.Service.Invoice.Create(r.Context(), requestPostToParams(member, req))
 		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
 			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
 			return
@@ -225,11 +201,67 @@ func HandlePost(ac *apictx.Context) http.HandlerFunc {
 	}
 }
 
+// requestPostToParams handles mapping the HandlePost request data to the
+// invoice create parameters, creating the invoice in the organization of the
+// given member.
+func requestPostToParams(member *proto.OrganizationMember, req RequestPost) *proto.InvoiceCreateParams {
+	// Handle line items.
+	lineItems := []proto.InvoiceLineItem{}
+	for _, v := range req.LineItems {
+		lineItem := proto.InvoiceLineItem{
+			Name:        v.Name,
+			Description: v.Description,
+			Quantity:    v.Quantity,
+			Price:       v.Price,
+		}
+
+		lineItems = append(lineItems, lineItem)
+	}
+
+	return &proto.InvoiceCreateParams{
+		OrganizationID: member.OrganizationID,
+		UserID:         member.UserID,
+		InvoiceNumber:  req.InvoiceNumber,
+		PONumber:       req.PONumber,
+		Currency:       req.Currency,
+		DueDate:        time.Time(req.DueDate.Time),
+		Message:        req.Message,
+		BillTo: proto.InvoiceBillTo{
+			FirstName:    req.BillTo.FirstName,
+			LastName:     req.BillTo.LastName,
+			Company:      req.BillTo.Company,
+			AddressLine1: req.BillTo.AddressLine1,
+			AddressLine2: req.BillTo.AddressLine2,
+			City:         req.BillTo.City,
+			State:        req.BillTo.State,
+			PostalCode:   req.BillTo.PostalCode,
+			Country:      req.BillTo.Country,
+			Email:        req.BillTo.Email,
+			Phone:        req.BillTo.Phone,
+		},
+		PayTo: proto.InvoicePayTo{
+			FirstName:    req.PayTo.FirstName,
+			LastName:     req.PayTo.LastName,
+			Company:      req.PayTo.Company,
+			AddressLine1: req.PayTo.AddressLine1,
+			AddressLine2: req.PayTo.AddressLine2,
+			City:         req.PayTo.City,
+			State:        req.PayTo.State,
+			PostalCode:   req.PayTo.PostalCode,
+			Country:      req.PayTo.Country,
+			Email:        req.PayTo.Email,
+			Phone:        req.PayTo.Phone,
+		},
+		LineItems:      lineItems,
+		PaymentMethods: req.PaymentMethods,
+		TaxRate:        req.TaxRate,
+	}
+}
+
 // Meta defines the response top level meta object.
 type Meta struct {
-	Offset uint `json:"offset"`
-	Limit  uint `json:"limit"`
-	Total  uint `json:"total"`
+	Limit uint `json:"limit"`
+	Total uint `json:"total"`
 }
 
 // Links defines the response top level links object.
@@ -248,8 +280,11 @@ type ResultGet struct {
 // HandleGet handles the /api/v1/invoice GET route of the API.
 func HandleGet(ac *apictx.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Get this user from the request context.
-		user, err := auth.GetUserFromRequest(r)
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
 		if err != nil {
 			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
 			return
@@ -257,56 +292,33 @@ func HandleGet(ac *apictx.Context) http.HandlerFunc {
 
 		// Create a new GetParams.
 		params := &proto.InvoiceGetParams{
-			UserID: &user.ID,
+			OrganizationID: &member.OrganizationID,
 		}
 
 		// Create a new API Errors.
 		errs := &errors.Errors{}
 
-		// Handle created at start.
-		if createdAtStartqs, ok := r.URL.Query()["created_at_start"]; ok && len(createdAtStartqs) == 1 {
-			if params.CreatedAt == nil {
-				params.CreatedAt = &proto.InvoiceGetParamsCreatedAt{}
-			}
-
-			t, err := time.Parse("2006-01-02 15:04:05", createdAtStartqs[0])
-			if err != nil {
-				errs.Add(errors.New(http.StatusBadRequest, "created_at_start", "invalid created at start date"))
-			} else {
-				params.CreatedAt.StartDate = &t
-			}
-		}
-
-		// Handle created at end.
-		if createdAtEndqs, ok := r.URL.Query()["created_at_end"]; ok && len(createdAtEndqs) == 1 {
-			if params.CreatedAt == nil {
-				params.CreatedAt = &proto.InvoiceGetParamsCreatedAt{}
-			}
-
-			t, err := time.Parse("2006-01-02 15:04:05", createdAtEndqs[0])
-			if err != nil {
-				errs.Add(errors.New(http.StatusBadRequest, "created_at_end", "invalid created at end date"))
-			} else {
-				params.CreatedAt.EndDate = &t
-			}
-		}
+		// Handle filters.
+		parseFilters(r, params, errs)
 
-		// Handle offset.
-		if offsetqs, ok := r.URL.Query()["offset"]; ok && len(offsetqs) == 1 {
-			offset64, err := strconv.ParseInt(offsetqs[0], 10, 32)
+		// Handle cursor.
+		if cursorqs, ok := r.URL.Query()["cursor"]; ok && len(cursorqs) == 1 {
+			cursor, err := pagination.DecodeCursor(ac.Config.CursorSecret, cursorqs[0])
 			if err != nil {
-				errs.Add(errors.ErrOffsetInvalid)
+				errs.Add(errors.ErrCursorInvalid)
 			} else {
-				params.Offset = uint(offset64)
+				params.Cursor = &proto.InvoiceGetParamsCursor{
+					CreatedAt: cursor.CreatedAt,
+					ID:        cursor.ID,
+					Before:    cursor.Before,
+				}
 			}
-		} else {
-			params.Offset = 0
 		}
 
 		// Handle limit.
 		if limitqs, ok := r.URL.Query()["limit"]; ok && len(limitqs) == 1 {
 			limit64, err := strconv.ParseInt(limitqs[0], 10, 32)
-			if err != nil {
+			if err != nil || limit64 < 1 {
 				errs.Add(errors.ErrLimitInvalid)
 			} else {
 				if uint(limit64) > ac.Config.LimitMax {
@@ -325,8 +337,12 @@ func HandleGet(ac *apictx.Context) http.HandlerFunc {
 			return
 		}
 
-		// Get invoices.
-		invoices, err := ac.Service.Invoice.Get(params)
+		// Get invoices, asking for one more than the limit to know if there
+		// is another page in the direction we are paging.
+		limit := params.Limit
+		params.Limit++
+		invoices, err := ac.Service.Invoice.Get(r.Context(), params)
+		params.Limit = limit
 		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
 			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
 			return
@@ -337,8 +353,18 @@ func HandleGet(ac *apictx.Context) http.HandlerFunc {
 			return
 		}
 
+		// Trim the extra invoice, which is the first invoice when paging
+		// before the cursor and the last invoice otherwise.
+		before := params.Cursor != nil && params.Cursor.Before
+		more := uint(len(invoices)) > params.Limit
+		if more && before {
+			invoices = invoices[1:]
+		} else if more {
+			invoices = invoices[:params.Limit]
+		}
+
 		// Get invoices count.
-		invoicesCount, err := ac.Service.Invoice.GetCount(params)
+		invoicesCount, err := ac.Service.Invoice.GetCount(r.Context(), params)
 		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
 			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
 			return
@@ -353,9 +379,8 @@ func HandleGet(ac *apictx.Context) http.HandlerFunc {
 		result := ResultGet{
 			Data: []Invoice{},
 			Meta: Meta{
-				Offset: params.Offset,
-				Limit:  params.Limit,
-				Total:  invoicesCount,
+				Limit: params.Limit,
+				Total: invoicesCount,
 			},
 			Links: Links{},
 		}
@@ -365,28 +390,46 @@ func HandleGet(ac *apictx.Context) http.HandlerFunc {
 			result.Data = append(result.Data, protoToInvoice(i))
 		}
 
-		// Handle previous link.
-		if params.Offset > 0 {
-			limitstr := "&limit=" + strconv.FormatInt(int64(params.Limit), 10)
+		// Handle links.
+		//
+		// When paging forward, there is a previous page if we came from a
+		// cursor. When paging backward, there is always a next page, since
+		// that is where the cursor came from.
+		if len(invoices) > 0 {
+			if (before && more) || (!before && params.Cursor != nil) {
+				first := invoices[0]
+				prev, err := pagination.EncodeCursor(ac.Config.CursorSecret, pagination.Cursor{
+					CreatedAt: first.CreatedAt,
+					ID:        first.ID,
+					Before:    true,
+				})
+				if err != nil {
+					ac.Logger.Error("pagination.EncodeCursor() error",
+						slog.Any("error", err))
+					errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+					return
+				}
 
-			offsetstr := "?offset="
-			if params.Offset-params.Limit < 0 {
-				offsetstr += "0"
-			} else {
-				offsetstr += strconv.FormatInt(int64(params.Offset-params.Limit), 10)
+				link := pagination.Link(ac, r, prev)
+				result.Links.Prev = &link
 			}
 
-			prev := "https://" + ac.Config.APIHost + "/api/v1/invoice" + offsetstr + limitstr
-			result.Links.Prev = &prev
-		}
-
-		// Handle next link.
-		if params.Offset+params.Limit < result.Meta.Total {
-			offsetstr := "?offset=" + strconv.FormatInt(int64(params.Offset+params.Limit), 10)
-			limitstr := "&limit=" + strconv.FormatInt(int64(params.Limit), 10)
+			if before || more {
+				last := invoices[len(invoices)-1]
+				next, err := pagination.EncodeCursor(ac.Config.CursorSecret, pagination.Cursor{
+					CreatedAt: last.CreatedAt,
+					ID:        last.ID,
+				})
+				if err != nil {
+					ac.Logger.Error("pagination.EncodeCursor() error",
+						slog.Any("error", err))
+					errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+					return
+				}
 
-			next := "https://" + ac.Config.APIHost + "/api/v1/invoice" + offsetstr + limitstr
-			result.Links.Next = &next
+				link := pagination.Link(ac, r, next)
+				result.Links.Next = &link
+			}
 		}
 
 		// Respond with JSON.
@@ -399,15 +442,51 @@ func HandleGet(ac *apictx.Context) http.HandlerFunc {
 	}
 }
 
+// parseFilters parses the invoice filters shared by the list and export
+// endpoints from the request query string.
+func parseFilters(r *http.Request, params *proto.InvoiceGetParams, errs *errors.Errors) {
+	// Handle created at start.
+	if createdAtStartqs, ok := r.URL.Query()["created_at_start"]; ok && len(createdAtStartqs) == 1 {
+		if params.CreatedAt == nil {
+			params.CreatedAt = &proto.InvoiceGetParamsCreatedAt{}
+		}
+
+		t, err := time.Parse("2006-01-02 15:04:05", createdAtStartqs[0])
+		if err != nil {
+			errs.Add(errors.New(http.StatusBadRequest, "created_at_start", "invalid created at start date"))
+		} else {
+			params.CreatedAt.StartDate = &t
+		}
+	}
+
+	// Handle created at end.
+	if createdAtEndqs, ok := r.URL.Query()["created_at_end"]; ok && len(createdAtEndqs) == 1 {
+		if params.CreatedAt == nil {
+			params.CreatedAt = &proto.InvoiceGetParamsCreatedAt{}
+		}
+
+		t, err := time.Parse("2006-01-02 15:04:05", createdAtEndqs[0])
+		if err != nil {
+			errs.Add(errors.New(http.StatusBadRequest, "created_at_end", "invalid created at end date"))
+		} else {
+			params.CreatedAt.EndDate = &t
+		}
+	}
+}
+
 // HandleGetPublicInvoice handles the /api/v1/invoice/public/:hash GET route of
 // the API.
 func HandleGetPublicInvoice(ac *apictx.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
 		// Get the hash.
 		hash := httprouter.GetParam(r, "hash")
 
-		// Get the invoice.
-		invoice, err := ac.Service.Invoice.GetByPublicHash(hash)
+		// Get the invoice, which is only shown, so it may come from the
+		// cache.
+		invoice, err := ac.Service.Invoice.GetByPublicHash(cache.Allow(r.Context()), hash)
 		if err == serverrors.ErrInvoiceNotFound {
 			errors.Default(ac.Logger, w, errors.New(http.StatusNotFound, "", err.Error()))
 			return
@@ -446,6 +525,9 @@ type ResultPayInvoice struct {
 // HandlePayInvoice handles the /api/v1/invoice/:id POST route of the API.
 func HandlePayInvoice(ac *apictx.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
 		// Parse the parameters from the request body.
 		var req RequestPayInvoice
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -457,7 +539,7 @@ func HandlePayInvoice(ac *apictx.Context) http.HandlerFunc {
 		hash := httprouter.GetParam(r, "hash")
 
 		// Get the invoice.
-		invoice, err := ac.Service.Invoice.GetByPublicHash(hash)
+		invoice, err := ac.Service.Invoice.GetByPublicHash(r.Context(), hash)
 		if err == serverrors.ErrInvoiceNotFound {
 			errors.Default(ac.Logger, w, errors.New(http.StatusNotFound, "", err.Error()))
 			return
@@ -469,12 +551,15 @@ func HandlePayInvoice(ac *apictx.Context) http.HandlerFunc {
 		}
 
 		// Pay the invoice.
-		invoice, err = ac.Service.Invoice.Pay(invoice.ID, &proto.InvoicePayParams{
+		invoice, err = ac.Service.Invoice.Pay(r.Context(), invoice.ID, &proto.InvoicePayParams{
 			Amount: *req.Amount,
 		})
 		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
 			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
 			return
+		} else if err == serverrors.ErrInvoiceNotFound {
+			errors.Default(ac.Logger, w, errors.New(http.StatusNotFound, "", err.Error()))
+			return
 		} else if err == serverrors.ErrInvoiceStatusNotPending {
 			errors.Default(ac.Logger, w, errors.New(http.StatusBadRequest, "", err.Error()))
 			return
@@ -508,29 +593,32 @@ type ResultGetInvoice struct {
 // HandleGetInvoice handles the /api/v1/invoice/:id GET route of the API.
 func HandleGetInvoice(ac *apictx.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
 		// Try to get the invoice ID.
 		var id uint
-		id64, err := strconv.ParseInt(httprouter.GetParam(r, "id"), 10, 32)
+		id64, err := strconv.ParseUint(httprouter.GetParam(r, "id"), 10, 64)
 		if err != nil {
 			errors.Default(ac.Logger, w, errors.ErrBadRequest)
 			return
 		}
 		id = uint(id64)
 
-		// Get this user from the request context.
-		user, err := auth.GetUserFromRequest(r)
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
 		if err != nil {
 			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
 			return
 		}
 
 		// Get the invoice.
-		invoice, err := ac.Service.Invoice.GetByIDAndUserID(id, user.ID)
+		invoice, err := ac.Service.Invoice.GetByIDAndOrganizationID(r.Context(), id, member.OrganizationID)
 		if err == serverrors.ErrInvoiceNotFound {
 			errors.Default(ac.Logger, w, errors.New(http.StatusNotFound, "", err.Error()))
 			return
 		} else if err != nil {
-			ac.Logger.Error("invoice.GetByIDAndUserID() service error",
+			ac.Logger.Error("invoice.GetByIDAndOrganizationID() service error",
 				slog.Any("error", err))
 			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
 			return
@@ -604,6 +692,9 @@ type ResultPostUpdate struct {
 // HandlePostUpdate handles the /api/v1/invoice/:id POST route of the API.
 func HandlePostUpdate(ac *apictx.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
 		// Parse the parameters from the request body.
 		var req RequestPostUpdate
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -613,15 +704,15 @@ func HandlePostUpdate(ac *apictx.Context) http.HandlerFunc {
 
 		// Try to get the invoice ID.
 		var id uint
-		id64, err := strconv.ParseInt(httprouter.GetParam(r, "id"), 10, 32)
+		id64, err := strconv.ParseUint(httprouter.GetParam(r, "id"), 10, 64)
 		if err != nil {
 			errors.Default(ac.Logger, w, errors.ErrBadRequest)
 			return
 		}
 		id = uint(id64)
 
-		// Get this user from the request context.
-		user, err := auth.GetUserFromRequest(r)
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
 		if err != nil {
 			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
 			return
@@ -630,7 +721,7 @@ func HandlePostUpdate(ac *apictx.Context) http.HandlerFunc {
 		// Handle invoice update params.
 		params := &proto.InvoiceUpdateParams{
 			ID:             &id,
-			UserID:         &user.ID,
+			OrganizationID: &member.OrganizationID,
 			InvoiceNumber:  req.InvoiceNumber,
 			PONumber:       req.PONumber,
 			Currency:       req.Currency,
@@ -695,7 +786,7 @@ func HandlePostUpdate(ac *apictx.Context) http.HandlerFunc {
 		}
 
 		// Update the invoice.
-		invoice, err := ac.Service.Invoice.UpdateForUser(params)
+		invoice, err := ac.Service.Invoice.UpdateForOrganization(r.Context(), params)
 		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
 			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
 			return
@@ -724,41 +815,44 @@ func HandlePostUpdate(ac *apictx.Context) http.HandlerFunc {
 // HandleDelete handles the /api/v1/invoice/:id DELETE route of the API.
 func HandleDelete(ac *apictx.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
 		// Try to get the invoice ID.
 		var id uint
-		id64, err := strconv.ParseInt(httprouter.GetParam(r, "id"), 10, 32)
+		id64, err := strconv.ParseUint(httprouter.GetParam(r, "id"), 10, 64)
 		if err != nil {
 			errors.Default(ac.Logger, w, errors.ErrBadRequest)
 			return
 		}
 		id = uint(id64)
 
-		// Get this user from the request context.
-		user, err := auth.GetUserFromRequest(r)
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
 		if err != nil {
 			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
 			return
 		}
 
 		// Get the invoice.
-		_, err = ac.Service.Invoice.GetByIDAndUserID(id, user.ID)
+		_, err = ac.Service.Invoice.GetByIDAndOrganizationID(r.Context(), id, member.OrganizationID)
 		if err == serverrors.ErrInvoiceNotFound {
 			errors.Default(ac.Logger, w, errors.New(http.StatusNotFound, "", err.Error()))
 			return
 		} else if err != nil {
-			ac.Logger.Error("invoice.GetByIDAndUserID() service error",
+			ac.Logger.Error("invoice.GetByIDAndOrganizationID() service error",
 				slog.Any("error", err))
 			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
 			return
 		}
 
 		// Delete the invoice.
-		err = ac.Service.Invoice.Delete(id)
+		err = ac.Service.Invoice.Delete(r.Context(), id)
 		if err == serverrors.ErrInvoiceNotFound {
 			errors.Default(ac.Logger, w, errors.New(http.StatusNotFound, "", err.Error()))
 			return
 		} else if err != nil {
-			ac.Logger.Error("invoice.GetByIDAndUserID() service error",
+			ac.Logger.Error("invoice.GetByIDAndOrganizationID() service error",
 				slog.Any("error", err))
 			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
 			return
@@ -785,14 +879,15 @@ func protoToInvoice(i *proto.Invoice) Invoice {
 	}
 
 	return Invoice{
-		ID:            i.ID,
-		UserID:        i.UserID,
-		PublicHash:    i.PublicHash,
-		InvoiceNumber: i.InvoiceNumber,
-		PONumber:      i.PONumber,
-		Currency:      i.Currency,
-		DueDate:       DueDate{i.DueDate},
-		Message:       i.Message,
+		ID:             i.ID,
+		OrganizationID: i.OrganizationID,
+		UserID:         i.UserID,
+		PublicHash:     i.PublicHash,
+		InvoiceNumber:  i.InvoiceNumber,
+		PONumber:       i.PONumber,
+		Currency:       i.Currency,
+		DueDate:        DueDate{i.DueDate},
+		Message:        i.Message,
 		BillTo: BillTo{
 			FirstName:    i.BillTo.FirstName,
 			LastName:     i.BillTo.LastName,
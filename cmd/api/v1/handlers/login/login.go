@@ -5,9 +5,12 @@ import (
 	"log/slog"
 	"net/http"
 
+	"dddstructure/cmd/api/config"
 	apictx "dddstructure/cmd/api/context"
 	"dddstructure/cmd/api/errors"
 	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/cmd/api/middleware/ratelimit"
 	"dddstructure/cmd/api/response"
 	"dddstructure/proto"
 	serverrors "dddstructure/service/errors"
@@ -18,7 +21,8 @@ import (
 // New creates the routes for the login endpoints of the API.
 func New(ac *apictx.Context, router *httprouter.Router) {
 	// Handle the routes.
-	router.POST("/api/v1/login", HandlePost(ac))
+	logging.Handle(router, http.MethodPost, "/api/v1/login", ratelimit.LimitEndpoint(ac, config.RateLimitGroupAuth, auth.RateLimitKey, HandlePost(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/login/2fa", ratelimit.LimitEndpoint(ac, config.RateLimitGroupAuth, auth.RateLimitKey, HandlePostTwoFactor(ac)))
 }
 
 // RequestPost defines the request data for the HandlePost handler.
@@ -29,12 +33,20 @@ type RequestPost struct {
 
 // ResultPost defines the response data for the HandlePost handler.
 type ResultPost struct {
-	Data string `json:"data"`
+	Data *auth.Token `json:"data"`
 }
 
 // HandlePost handles the /api/v1/login POST route of the API.
+//
+// If the user has two-factor authentication enabled, only a challenge token
+// is returned, to be used with the /api/v1/login/2fa route. After too many
+// failed attempts for the email or client, 429 is returned until the lockout
+// ends.
 func HandlePost(ac *apictx.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
 		// Parse the parameters from the request body.
 		var req RequestPost
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -43,9 +55,10 @@ func HandlePost(ac *apictx.Context) http.HandlerFunc {
 		}
 
 		// Try to log in the user.
-		user, err := ac.Service.User.Login(&proto.UserLoginParams{
-			Email:    req.Email,
-			Password: req.Password,
+		user, err := ac.Service.User.Login(r.Context(), &proto.UserLoginParams{
+			Email:     req.Email,
+			Password:  req.Password,
+			IPAddress: auth.ClientIP(ac, r),
 		})
 		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
 			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
@@ -53,6 +66,9 @@ func HandlePost(ac *apictx.Context) http.HandlerFunc {
 		} else if err == serverrors.ErrUserInvalidLogin {
 			errors.Default(ac.Logger, w, errors.New(http.StatusUnauthorized, "", err.Error()))
 			return
+		} else if err == serverrors.ErrUserLoginLocked {
+			errors.Default(ac.Logger, w, errors.New(http.StatusTooManyRequests, "", err.Error()))
+			return
 		} else if err != nil {
 			ac.Logger.Error("user.Login() service error",
 				slog.Any("error", err))
@@ -60,10 +76,11 @@ func HandlePost(ac *apictx.Context) http.HandlerFunc {
 			return
 		}
 
-		// Issue a new JWT for this user.
-		token, err := auth.NewJWT(ac, user.Password, user.ID)
+		// Create a new session and issue its tokens, or a login challenge if
+		// two-factor authentication is enabled.
+		token, err := auth.NewLoginToken(ac, r, user)
 		if err != nil {
-			ac.Logger.Error("auth.NewJWT() error",
+			ac.Logger.Error("auth.NewLoginToken() error",
 				slog.Any("error", err))
 			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
 			return
@@ -83,3 +100,75 @@ func HandlePost(ac *apictx.Context) http.HandlerFunc {
 		}
 	}
 }
+
+// RequestPostTwoFactor defines the request data for the HandlePostTwoFactor
+// handler.
+type RequestPostTwoFactor struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+// ResultPostTwoFactor defines the response data for the HandlePostTwoFactor
+// handler.
+type ResultPostTwoFactor struct {
+	Data *auth.Token `json:"data"`
+}
+
+// HandlePostTwoFactor handles the /api/v1/login/2fa POST route of the API.
+//
+// The challenge token from logging in is exchanged for a new session along
+// with a TOTP or recovery code. A challenge can only be used once, so a wrong
+// code means logging in again.
+func HandlePostTwoFactor(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Parse the parameters from the request body.
+		var req RequestPostTwoFactor
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errors.Default(ac.Logger, w, errors.ErrBadRequest)
+			return
+		}
+
+		// Try to finish logging in the user.
+		user, err := ac.Service.User.LoginTwoFactor(r.Context(), &proto.UserLoginTwoFactorParams{
+			ChallengeToken: req.ChallengeToken,
+			Code:           req.Code,
+		})
+		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
+			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
+			return
+		} else if err == serverrors.ErrUserTwoFactorChallengeInvalid || err == serverrors.ErrUserTwoFactorCodeInvalid {
+			errors.Default(ac.Logger, w, errors.New(http.StatusUnauthorized, "", err.Error()))
+			return
+		} else if err != nil {
+			ac.Logger.Error("user.LoginTwoFactor() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new session and issue its tokens.
+		token, err := auth.NewToken(ac, r, user)
+		if err != nil {
+			ac.Logger.Error("auth.NewToken() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new Result.
+		result := ResultPostTwoFactor{
+			Data: token,
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
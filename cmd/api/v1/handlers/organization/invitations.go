@@ -0,0 +1,129 @@
+package organization
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/response"
+	"dddstructure/proto"
+)
+
+// RequestPostInvitation defines the request data for the HandlePostInvitation
+// handler.
+type RequestPostInvitation struct {
+	Email string                 `json:"email"`
+	Role  proto.OrganizationRole `json:"role"`
+}
+
+// HandlePostInvitation handles the /api/v1/organization/invitations POST route
+// of the API.
+//
+// An invitation link is emailed to the given email, and is valid for 7 days.
+// Only owners can invite someone as an owner.
+func HandlePostInvitation(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Parse the parameters from the request body.
+		var req RequestPostInvitation
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errors.Default(ac.Logger, w, errors.ErrBadRequest)
+			return
+		}
+
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Send the invitation.
+		err = ac.Service.Organization.Invite(r.Context(), &proto.OrganizationInviteParams{
+			OrganizationID: member.OrganizationID,
+			Email:          req.Email,
+			Role:           req.Role,
+			ByUserID:       member.UserID,
+			Link:           ac.Config.InviteLink,
+		})
+		if handleMemberError(ac, w, err, "organization.Invite()") {
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// RequestPostAcceptInvitation defines the request data for the
+// HandlePostAcceptInvitation handler.
+type RequestPostAcceptInvitation struct {
+	Token string `json:"token"`
+}
+
+// ResultPostAcceptInvitation defines the response data for the
+// HandlePostAcceptInvitation handler.
+type ResultPostAcceptInvitation struct {
+	Data Organization `json:"data"`
+}
+
+// HandlePostAcceptInvitation handles the
+// /api/v1/organization/invitations/accept POST route of the API.
+//
+// The user must be logged in with the email the invitation was sent to.
+func HandlePostAcceptInvitation(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Parse the parameters from the request body.
+		var req RequestPostAcceptInvitation
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errors.Default(ac.Logger, w, errors.ErrBadRequest)
+			return
+		}
+
+		// Get this user from the request context.
+		user, err := auth.GetUserFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Accept the invitation.
+		member, err := ac.Service.Organization.AcceptInvitation(r.Context(), &proto.OrganizationAcceptInvitationParams{
+			Token:  req.Token,
+			UserID: user.ID,
+		})
+		if handleMemberError(ac, w, err, "organization.AcceptInvitation()") {
+			return
+		}
+
+		// Get the organization.
+		organization, err := ac.Service.Organization.GetByID(r.Context(), member.OrganizationID)
+		if err != nil {
+			ac.Logger.Error("organization.GetByID() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new Result.
+		result := ResultPostAcceptInvitation{
+			Data: protoToOrganization(organization),
+		}
+		result.Data.Role = member.Role
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
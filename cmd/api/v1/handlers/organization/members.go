@@ -0,0 +1,214 @@
+package organization
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/response"
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+
+	"github.com/beeker1121/httprouter"
+)
+
+// Member defines an organization member.
+type Member struct {
+	UserID    uint                   `json:"user_id"`
+	Email     string                 `json:"email"`
+	Role      proto.OrganizationRole `json:"role"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// ResultGetMembers defines the response data for the HandleGetMembers
+// handler.
+type ResultGetMembers struct {
+	Data []Member `json:"data"`
+}
+
+// HandleGetMembers handles the /api/v1/organization/members GET route of the
+// API.
+func HandleGetMembers(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Get the members.
+		members, err := ac.Service.Organization.GetMembers(r.Context(), member.OrganizationID)
+		if err != nil {
+			ac.Logger.Error("organization.GetMembers() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new Result.
+		result := ResultGetMembers{
+			Data: []Member{},
+		}
+
+		for _, v := range members {
+			result.Data = append(result.Data, protoToMember(v))
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
+
+// RequestPostMember defines the request data for the HandlePostMember
+// handler.
+type RequestPostMember struct {
+	Role proto.OrganizationRole `json:"role"`
+}
+
+// ResultPostMember defines the response data for the HandlePostMember
+// handler.
+type ResultPostMember struct {
+	Data Member `json:"data"`
+}
+
+// HandlePostMember handles the /api/v1/organization/members/:user_id POST
+// route of the API.
+//
+// Only owners can grant the owner role or change the role of another owner.
+func HandlePostMember(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Parse the parameters from the request body.
+		var req RequestPostMember
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errors.Default(ac.Logger, w, errors.ErrBadRequest)
+			return
+		}
+
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Try to get the user ID.
+		userID, err := strconv.ParseUint(httprouter.GetParam(r, "user_id"), 10, 64)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrBadRequest)
+			return
+		}
+
+		// Update the member.
+		updated, err := ac.Service.Organization.UpdateMember(r.Context(), &proto.OrganizationUpdateMemberParams{
+			OrganizationID: member.OrganizationID,
+			UserID:         uint(userID),
+			Role:           req.Role,
+			ByUserID:       member.UserID,
+		})
+		if handleMemberError(ac, w, err, "organization.UpdateMember()") {
+			return
+		}
+
+		// Create a new Result.
+		result := ResultPostMember{
+			Data: protoToMember(updated),
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
+
+// HandleDeleteMember handles the /api/v1/organization/members/:user_id DELETE
+// route of the API.
+//
+// Only owners can remove another owner.
+func HandleDeleteMember(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Try to get the user ID.
+		userID, err := strconv.ParseUint(httprouter.GetParam(r, "user_id"), 10, 64)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrBadRequest)
+			return
+		}
+
+		// Remove the member.
+		err = ac.Service.Organization.RemoveMember(r.Context(), &proto.OrganizationRemoveMemberParams{
+			OrganizationID: member.OrganizationID,
+			UserID:         uint(userID),
+			ByUserID:       member.UserID,
+		})
+		if handleMemberError(ac, w, err, "organization.RemoveMember()") {
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// handleMemberError renders an error returned from a service method changing
+// an organization member, returning true if there was an error.
+func handleMemberError(ac *apictx.Context, w http.ResponseWriter, err error, method string) bool {
+	if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
+		errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
+		return true
+	} else if err == serverrors.ErrOrganizationMemberNotFound || err == serverrors.ErrOrganizationNotFound {
+		errors.Default(ac.Logger, w, errors.New(http.StatusNotFound, "", err.Error()))
+		return true
+	} else if err == serverrors.ErrOrganizationForbidden {
+		errors.Default(ac.Logger, w, errors.New(http.StatusForbidden, "", err.Error()))
+		return true
+	} else if err == serverrors.ErrOrganizationLastOwner {
+		errors.Default(ac.Logger, w, errors.New(http.StatusConflict, "", err.Error()))
+		return true
+	} else if err != nil {
+		ac.Logger.Error(method+" service error",
+			slog.Any("error", err))
+		errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+		return true
+	}
+
+	return false
+}
+
+// protoToMember maps a proto OrganizationMember to an API Member.
+func protoToMember(m *proto.OrganizationMember) Member {
+	return Member{
+		UserID:    m.UserID,
+		Email:     m.Email,
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt,
+	}
+}
@@ -0,0 +1,160 @@
+package organization
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/cmd/api/response"
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+
+	"github.com/beeker1121/httprouter"
+)
+
+// New creates the routes for the organization endpoints of the API.
+func New(ac *apictx.Context, router *httprouter.Router) {
+	// Handle the routes.
+	logging.Handle(router, http.MethodGet, "/api/v1/organization", auth.AuthenticateEndpoint(ac, HandleGet(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/organization", auth.AuthenticateEndpoint(ac, HandlePost(ac)))
+	logging.Handle(router, http.MethodGet, "/api/v1/organization/members", auth.AuthorizeEndpoint(ac, proto.OrganizationPermissionRead, HandleGetMembers(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/organization/members/:user_id", auth.AuthorizeEndpoint(ac, proto.OrganizationPermissionMembersWrite, HandlePostMember(ac)))
+	logging.Handle(router, http.MethodDelete, "/api/v1/organization/members/:user_id", auth.AuthorizeEndpoint(ac, proto.OrganizationPermissionMembersWrite, HandleDeleteMember(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/organization/invitations", auth.AuthorizeEndpoint(ac, proto.OrganizationPermissionMembersWrite, HandlePostInvitation(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/organization/invitations/accept", auth.AuthenticateEndpoint(ac, HandlePostAcceptInvitation(ac)))
+}
+
+// Organization defines an organization.
+type Organization struct {
+	ID        uint                   `json:"id"`
+	Name      string                 `json:"name"`
+	Role      proto.OrganizationRole `json:"role"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// ResultGet defines the response data for the HandleGet handler.
+type ResultGet struct {
+	Data []Organization `json:"data"`
+}
+
+// HandleGet handles the /api/v1/organization GET route of the API.
+//
+// The organizations the user is a member of are returned along with the role
+// of the user in each.
+func HandleGet(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this user from the request context.
+		user, err := auth.GetUserFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Get the organizations.
+		organizations, err := ac.Service.Organization.GetForUser(r.Context(), user.ID)
+		if err != nil {
+			ac.Logger.Error("organization.GetForUser() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new Result.
+		result := ResultGet{
+			Data: []Organization{},
+		}
+
+		for _, v := range organizations {
+			result.Data = append(result.Data, protoToOrganization(v))
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
+
+// RequestPost defines the request data for the HandlePost handler.
+type RequestPost struct {
+	Name string `json:"name"`
+}
+
+// ResultPost defines the response data for the HandlePost handler.
+type ResultPost struct {
+	Data Organization `json:"data"`
+}
+
+// HandlePost handles the /api/v1/organization POST route of the API.
+//
+// The user creating the organization becomes its owner.
+func HandlePost(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Parse the parameters from the request body.
+		var req RequestPost
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errors.Default(ac.Logger, w, errors.ErrBadRequest)
+			return
+		}
+
+		// Get this user from the request context.
+		user, err := auth.GetUserFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create the organization.
+		organization, err := ac.Service.Organization.Create(r.Context(), &proto.OrganizationCreateParams{
+			Name:   req.Name,
+			UserID: user.ID,
+		})
+		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
+			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
+			return
+		} else if err != nil {
+			ac.Logger.Error("organization.Create() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new Result.
+		result := ResultPost{
+			Data: protoToOrganization(organization),
+		}
+		result.Data.Role = proto.OrganizationRoleOwner
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
+
+// protoToOrganization maps a proto Organization to an API Organization.
+func protoToOrganization(o *proto.Organization) Organization {
+	return Organization{
+		ID:        o.ID,
+		Name:      o.Name,
+		Role:      o.Role,
+		CreatedAt: o.CreatedAt,
+	}
+}
@@ -0,0 +1,136 @@
+package password
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"dddstructure/cmd/api/config"
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/cmd/api/middleware/ratelimit"
+	"dddstructure/cmd/api/response"
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+
+	"github.com/beeker1121/httprouter"
+)
+
+// New creates the routes for the password endpoints of the API.
+func New(ac *apictx.Context, router *httprouter.Router) {
+	// Handle the routes.
+	logging.Handle(router, http.MethodPost, "/api/v1/password/forgot", ratelimit.LimitEndpoint(ac, config.RateLimitGroupAuth, auth.RateLimitKey, HandlePostForgot(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/password/reset", ratelimit.LimitEndpoint(ac, config.RateLimitGroupAuth, auth.RateLimitKey, HandlePostReset(ac)))
+}
+
+// RequestPostForgot defines the request data for the HandlePostForgot
+// handler.
+type RequestPostForgot struct {
+	Email string `json:"email"`
+}
+
+// HandlePostForgot handles the /api/v1/password/forgot POST route of the
+// API.
+//
+// The response is the same whether or not an account exists for the email.
+func HandlePostForgot(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Parse the parameters from the request body.
+		var req RequestPostForgot
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errors.Default(ac.Logger, w, errors.ErrBadRequest)
+			return
+		}
+
+		// Send the password reset token.
+		err := ac.Service.User.ForgotPassword(r.Context(), &proto.UserForgotPasswordParams{
+			Email: req.Email,
+			Link:  ac.Config.ResetLink,
+		})
+		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
+			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
+			return
+		} else if err != nil {
+			ac.Logger.Error("user.ForgotPassword() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// RequestPostReset defines the request data for the HandlePostReset handler.
+type RequestPostReset struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// ResultPostReset defines the response data for the HandlePostReset handler.
+type ResultPostReset struct {
+	Data *auth.Token `json:"data"`
+}
+
+// HandlePostReset handles the /api/v1/password/reset POST route of the API.
+//
+// A new JWT is returned on success, as all of the user's existing JWTs stop
+// working once the password changes. If the user has two-factor
+// authentication enabled, a login challenge is returned instead, since the
+// reset token only proves access to the email.
+func HandlePostReset(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Parse the parameters from the request body.
+		var req RequestPostReset
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errors.Default(ac.Logger, w, errors.ErrBadRequest)
+			return
+		}
+
+		// Reset the password.
+		user, err := ac.Service.User.ResetPassword(r.Context(), &proto.UserResetPasswordParams{
+			Token:    req.Token,
+			Password: req.Password,
+		})
+		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
+			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
+			return
+		} else if err != nil {
+			ac.Logger.Error("user.ResetPassword() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new session and issue its tokens, or a login challenge if
+		// two-factor authentication is enabled.
+		token, err := auth.NewLoginToken(ac, r, user)
+		if err != nil {
+			ac.Logger.Error("auth.NewLoginToken() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new Result.
+		result := ResultPostReset{
+			Data: token,
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
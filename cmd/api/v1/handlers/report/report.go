@@ -0,0 +1,338 @@
+package report
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/cmd/api/response"
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+
+	"github.com/beeker1121/httprouter"
+)
+
+// New creates the routes for the report endpoints of the API.
+func New(ac *apictx.Context, router *httprouter.Router) {
+	// Handle the routes.
+	logging.Handle(router, http.MethodGet, "/api/v1/report/aging", auth.AuthorizeEndpoint(ac, proto.OrganizationPermissionReportRead, HandleGetAging(ac)))
+	logging.Handle(router, http.MethodGet, "/api/v1/report/revenue", auth.AuthorizeEndpoint(ac, proto.OrganizationPermissionReportRead, HandleGetRevenue(ac)))
+	logging.Handle(router, http.MethodGet, "/api/v1/report/balances", auth.AuthorizeEndpoint(ac, proto.OrganizationPermissionReportRead, HandleGetBalances(ac)))
+}
+
+// AgingBucket defines an aging bucket.
+type AgingBucket struct {
+	Name      string `json:"name"`
+	Count     uint   `json:"count"`
+	AmountDue uint   `json:"amount_due"`
+}
+
+// Aging defines the aging of a single currency.
+type Aging struct {
+	Currency  string        `json:"currency"`
+	Buckets   []AgingBucket `json:"buckets"`
+	Count     uint          `json:"count"`
+	AmountDue uint          `json:"amount_due"`
+}
+
+// AgingMeta defines the aging response top level meta object.
+type AgingMeta struct {
+	AsOf string `json:"as_of"`
+}
+
+// ResultGetAging defines the response data for the HandleGetAging handler.
+type ResultGetAging struct {
+	Data []Aging   `json:"data"`
+	Meta AgingMeta `json:"meta"`
+}
+
+// HandleGetAging handles the /api/v1/report/aging GET route of the API.
+//
+// Unpaid invoices are aged by the number of days they are past their due
+// date, as of the date given by the as_of query parameter or today.
+func HandleGetAging(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new AgingParams.
+		params := &proto.ReportAgingParams{
+			OrganizationID: member.OrganizationID,
+			AsOf:           time.Now().UTC(),
+		}
+
+		// Handle as of date.
+		if asOfqs, ok := r.URL.Query()["as_of"]; ok && len(asOfqs) == 1 {
+			t, err := time.Parse("2006-01-02", asOfqs[0])
+			if err != nil {
+				errors.Default(ac.Logger, w, errors.New(http.StatusBadRequest, "as_of", "invalid as of date, must be in YYYY-MM-DD format"))
+				return
+			}
+			params.AsOf = t
+		}
+
+		// Get the aging.
+		aging, err := ac.Service.Report.GetAging(r.Context(), params)
+		if err != nil {
+			ac.Logger.Error("report.GetAging() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new Result.
+		result := ResultGetAging{
+			Data: []Aging{},
+			Meta: AgingMeta{
+				AsOf: params.AsOf.Format("2006-01-02"),
+			},
+		}
+
+		for _, a := range aging {
+			buckets := []AgingBucket{}
+			for _, b := range a.Buckets {
+				buckets = append(buckets, AgingBucket{
+					Name:      string(b.Name),
+					Count:     b.Count,
+					AmountDue: b.AmountDue,
+				})
+			}
+
+			result.Data = append(result.Data, Aging{
+				Currency:  a.Currency,
+				Buckets:   buckets,
+				Count:     a.Count,
+				AmountDue: a.AmountDue,
+			})
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
+
+// Revenue defines the revenue of a single currency within a single period.
+type Revenue struct {
+	Period    string `json:"period"`
+	Currency  string `json:"currency"`
+	Count     uint   `json:"count"`
+	Collected uint   `json:"collected"`
+	Refunded  uint   `json:"refunded"`
+	Net       int    `json:"net"`
+}
+
+// ResultGetRevenue defines the response data for the HandleGetRevenue
+// handler.
+type ResultGetRevenue struct {
+	Data []Revenue `json:"data"`
+}
+
+// HandleGetRevenue handles the /api/v1/report/revenue GET route of the API.
+//
+// The interval query parameter is either day, the default, week or month.
+// The start_date and end_date query parameters limit the transactions
+// counted, and both are inclusive.
+func HandleGetRevenue(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new RevenueParams.
+		params := &proto.ReportRevenueParams{
+			OrganizationID: member.OrganizationID,
+			Interval:       proto.ReportRevenueIntervalDay,
+		}
+
+		// Create a new API Errors.
+		errs := &errors.Errors{}
+
+		// Handle interval.
+		if intervalqs, ok := r.URL.Query()["interval"]; ok && len(intervalqs) == 1 {
+			params.Interval = proto.ReportRevenueInterval(intervalqs[0])
+		}
+
+		// Handle date range.
+		params.StartDate, params.EndDate = parseDateRange(r, errs)
+
+		// Return if there were errors.
+		if errs.Length() > 0 {
+			errors.Multiple(ac.Logger, w, http.StatusBadRequest, errs)
+			return
+		}
+
+		// Get the revenue.
+		revenue, err := ac.Service.Report.GetRevenue(r.Context(), params)
+		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
+			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
+			return
+		} else if err != nil {
+			ac.Logger.Error("report.GetRevenue() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new Result.
+		result := ResultGetRevenue{
+			Data: []Revenue{},
+		}
+
+		for _, v := range revenue {
+			result.Data = append(result.Data, Revenue{
+				Period:    v.Period.Format("2006-01-02"),
+				Currency:  v.Currency,
+				Count:     v.Count,
+				Collected: v.Collected,
+				Refunded:  v.Refunded,
+				Net:       v.Net,
+			})
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
+
+// Balance defines the outstanding and collected totals of a single currency.
+type Balance struct {
+	Currency    string `json:"currency"`
+	Count       uint   `json:"count"`
+	Outstanding uint   `json:"outstanding"`
+	Collected   uint   `json:"collected"`
+}
+
+// ResultGetBalances defines the response data for the HandleGetBalances
+// handler.
+type ResultGetBalances struct {
+	Data []Balance `json:"data"`
+}
+
+// HandleGetBalances handles the /api/v1/report/balances GET route of the
+// API.
+//
+// The start_date and end_date query parameters limit the invoices counted by
+// their created at date, and both are inclusive.
+func HandleGetBalances(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new BalancesParams.
+		params := &proto.ReportBalancesParams{
+			OrganizationID: member.OrganizationID,
+		}
+
+		// Create a new API Errors.
+		errs := &errors.Errors{}
+
+		// Handle date range.
+		params.StartDate, params.EndDate = parseDateRange(r, errs)
+
+		// Return if there were errors.
+		if errs.Length() > 0 {
+			errors.Multiple(ac.Logger, w, http.StatusBadRequest, errs)
+			return
+		}
+
+		// Get the balances.
+		balances, err := ac.Service.Report.GetBalances(r.Context(), params)
+		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
+			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
+			return
+		} else if err != nil {
+			ac.Logger.Error("report.GetBalances() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new Result.
+		result := ResultGetBalances{
+			Data: []Balance{},
+		}
+
+		for _, v := range balances {
+			result.Data = append(result.Data, Balance{
+				Currency:    v.Currency,
+				Count:       v.Count,
+				Outstanding: v.Outstanding,
+				Collected:   v.Collected,
+			})
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
+
+// parseDateRange parses the start_date and end_date query parameters from
+// the request, adding any errors to the given API errors.
+//
+// Dates are in YYYY-MM-DD format. The end date is moved to the end of its
+// day, so the whole day is included.
+func parseDateRange(r *http.Request, errs *errors.Errors) (*time.Time, *time.Time) {
+	var start, end *time.Time
+
+	// Handle start date.
+	if startDateqs, ok := r.URL.Query()["start_date"]; ok && len(startDateqs) == 1 {
+		t, err := time.Parse("2006-01-02", startDateqs[0])
+		if err != nil {
+			errs.Add(errors.New(http.StatusBadRequest, "start_date", "invalid start date, must be in YYYY-MM-DD format"))
+		} else {
+			start = &t
+		}
+	}
+
+	// Handle end date.
+	if endDateqs, ok := r.URL.Query()["end_date"]; ok && len(endDateqs) == 1 {
+		t, err := time.Parse("2006-01-02", endDateqs[0])
+		if err != nil {
+			errs.Add(errors.New(http.StatusBadRequest, "end_date", "invalid end date, must be in YYYY-MM-DD format"))
+		} else {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			end = &t
+		}
+	}
+
+	return start, end
+}
@@ -5,9 +5,12 @@ import (
 	"log/slog"
 	"net/http"
 
+	"dddstructure/cmd/api/config"
 	apictx "dddstructure/cmd/api/context"
 	"dddstructure/cmd/api/errors"
 	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/cmd/api/middleware/ratelimit"
 	"dddstructure/cmd/api/response"
 	"dddstructure/proto"
 	serverrors "dddstructure/service/errors"
@@ -18,7 +21,7 @@ import (
 // New creates the routes for the signup endpoints of the API.
 func New(ac *apictx.Context, router *httprouter.Router) {
 	// Handle the routes.
-	router.POST("/api/v1/signup", HandlePost(ac))
+	logging.Handle(router, http.MethodPost, "/api/v1/signup", ratelimit.LimitEndpoint(ac, config.RateLimitGroupSignup, auth.RateLimitKey, HandlePost(ac)))
 }
 
 // RequestPost defines the request data for the HandlePost handler.
@@ -29,12 +32,15 @@ type RequestPost struct {
 
 // ResultPost defines the response data for the HandlePost handler.
 type ResultPost struct {
-	Data string `json:"data"`
+	Data *auth.Token `json:"data"`
 }
 
 // HandlePost handles the /api/v1/signup POST route of the API.
 func HandlePost(ac *apictx.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
 		// Parse the parameters from the request body.
 		var req RequestPost
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -43,7 +49,7 @@ func HandlePost(ac *apictx.Context) http.HandlerFunc {
 		}
 
 		// Create the user.
-		user, err := ac.Service.User.Create(&proto.UserCreateParams{
+		user, err := ac.Service.User.Create(r.Context(), &proto.UserCreateParams{
 			Email:    req.Email,
 			Password: req.Password,
 		})
@@ -57,10 +63,20 @@ func HandlePost(ac *apictx.Context) http.HandlerFunc {
 			return
 		}
 
-		// Issue a new JWT for this user.
-		token, err := auth.NewJWT(ac, user.Password, user.ID)
+		// Send the email verification token. The user is already created, so
+		// this only logs any error, and a new token can be requested later.
+		if err := ac.Service.User.SendEmailVerification(r.Context(), &proto.UserSendEmailVerificationParams{
+			ID:   user.ID,
+			Link: ac.Config.VerifyLink,
+		}); err != nil {
+			ac.Logger.Error("user.SendEmailVerification() service error",
+				slog.Any("error", err))
+		}
+
+		// Create a new session and issue its tokens.
+		token, err := auth.NewToken(ac, r, user)
 		if err != nil {
-			ac.Logger.Error("auth.NewJWT() error",
+			ac.Logger.Error("auth.NewToken() error",
 				slog.Any("error", err))
 			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
 			return
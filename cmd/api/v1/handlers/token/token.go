@@ -0,0 +1,138 @@
+package token
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"dddstructure/cmd/api/config"
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/cmd/api/middleware/ratelimit"
+	"dddstructure/cmd/api/response"
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+
+	"github.com/beeker1121/httprouter"
+)
+
+// New creates the routes for the token endpoints of the API.
+func New(ac *apictx.Context, router *httprouter.Router) {
+	// Handle the routes.
+	logging.Handle(router, http.MethodPost, "/api/v1/token/refresh", ratelimit.LimitEndpoint(ac, config.RateLimitGroupAuth, auth.RateLimitKey, HandlePostRefresh(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/logout", auth.AuthenticateEndpoint(ac, HandlePostLogout(ac)))
+}
+
+// RequestPostRefresh defines the request data for the HandlePostRefresh
+// handler.
+type RequestPostRefresh struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ResultPostRefresh defines the response data for the HandlePostRefresh
+// handler.
+type ResultPostRefresh struct {
+	Data *auth.Token `json:"data"`
+}
+
+// HandlePostRefresh handles the /api/v1/token/refresh POST route of the API.
+//
+// The refresh token is exchanged for a new access token and refresh token.
+// Using a refresh token a second time revokes its session.
+func HandlePostRefresh(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Parse the parameters from the request body.
+		var req RequestPostRefresh
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errors.Default(ac.Logger, w, errors.ErrBadRequest)
+			return
+		}
+
+		// Refresh the session.
+		session, err := ac.Service.Session.Refresh(r.Context(), &proto.SessionRefreshParams{
+			RefreshToken: req.RefreshToken,
+			UserAgent:    r.UserAgent(),
+			IPAddress:    auth.ClientIP(ac, r),
+			Expiry:       time.Minute * ac.Config.RefreshExpiryTime,
+		})
+		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
+			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
+			return
+		} else if err == serverrors.ErrSessionRefreshTokenInvalid || err == serverrors.ErrSessionRefreshTokenReused {
+			errors.Default(ac.Logger, w, errors.New(http.StatusUnauthorized, "", err.Error()))
+			return
+		} else if err != nil {
+			ac.Logger.Error("session.Refresh() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Get the user of the session.
+		user, err := ac.Service.User.GetByID(r.Context(), session.UserID)
+		if err == serverrors.ErrUserNotFound {
+			errors.Default(ac.Logger, w, errors.New(http.StatusUnauthorized, "", serverrors.ErrSessionRefreshTokenInvalid.Error()))
+			return
+		} else if err != nil {
+			ac.Logger.Error("user.GetByID() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Issue the session tokens.
+		token, err := auth.NewSessionToken(ac, user, session)
+		if err != nil {
+			ac.Logger.Error("auth.NewSessionToken() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new Result.
+		result := ResultPostRefresh{
+			Data: token,
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
+
+// HandlePostLogout handles the /api/v1/logout POST route of the API.
+//
+// The current session is revoked, along with its access and refresh tokens.
+func HandlePostLogout(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this session from the request context.
+		sessionID, err := auth.GetSessionIDFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Delete the session.
+		if err := ac.Service.Session.Delete(r.Context(), sessionID); err != nil {
+			ac.Logger.Error("session.Delete() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"time"
 
 	apictx "dddstructure/cmd/api/context"
 	"dddstructure/cmd/api/errors"
 	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/middleware/logging"
 	"dddstructure/cmd/api/response"
 	"dddstructure/proto"
 	serverrors "dddstructure/service/errors"
@@ -18,18 +20,20 @@ import (
 // New creates the routes for the transaction endpoints of the API.
 func New(ac *apictx.Context, router *httprouter.Router) {
 	// Handle the routes.
-	router.POST("/api/v1/transaction", auth.AuthenticateEndpoint(ac, HandlePost(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/transaction", auth.AuthorizeEndpoint(ac, proto.OrganizationPermissionTransaction, HandlePost(ac)))
 }
 
 // Transaction defines a transaction.
 type Transaction struct {
-	ID             uint   `json:"id"`
-	UserID         uint   `json:"user_id"`
-	Type           string `json:"type"`
-	CardType       string `json:"card_type"`
-	AmountCaptured uint   `json:"amount_captured"`
-	InvoiceID      uint   `json:"invoice_id"`
-	Status         string `json:"status"`
+	ID             uint      `json:"id"`
+	OrganizationID uint      `json:"organization_id"`
+	UserID         uint      `json:"user_id"`
+	Type           string    `json:"type"`
+	CardType       string    `json:"card_type"`
+	AmountCaptured uint      `json:"amount_captured"`
+	InvoiceID      uint      `json:"invoice_id"`
+	Status         string    `json:"status"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 // PaymentMethod defines the transaction payment method.
@@ -59,6 +63,9 @@ type ResultPost struct {
 // HandlePost handles the /api/v1/transaction POST route of the API.
 func HandlePost(ac *apictx.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
 		// Parse the parameters from the request body.
 		var req RequestPost
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -66,8 +73,8 @@ func HandlePost(ac *apictx.Context) http.HandlerFunc {
 			return
 		}
 
-		// Get this user from the request context.
-		user, err := auth.GetUserFromRequest(r)
+		// Get this member from the request context.
+		member, err := auth.GetMemberFromRequest(r)
 		if err != nil {
 			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
 			return
@@ -83,12 +90,13 @@ func HandlePost(ac *apictx.Context) http.HandlerFunc {
 		}
 
 		// Process the transaction.
-		transaction, err := ac.Service.Transaction.Process(&proto.TransactionProcessParams{
-			UserID:        user.ID,
-			Type:          req.Type,
-			Amount:        req.Amount,
-			PaymentMethod: paymentMethod,
-			InvoiceID:     req.InvoiceID,
+		transaction, err := ac.Service.Transaction.Process(r.Context(), &proto.TransactionProcessParams{
+			OrganizationID: member.OrganizationID,
+			UserID:         member.UserID,
+			Type:           req.Type,
+			Amount:         req.Amount,
+			PaymentMethod:  paymentMethod,
+			InvoiceID:      req.InvoiceID,
 		})
 		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
 			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
@@ -104,12 +112,14 @@ func HandlePost(ac *apictx.Context) http.HandlerFunc {
 		result := ResultPost{
 			Data: Transaction{
 				ID:             transaction.ID,
+				OrganizationID: transaction.OrganizationID,
 				UserID:         transaction.UserID,
 				Type:           transaction.Type,
 				CardType:       transaction.CardType,
 				AmountCaptured: transaction.AmountCaptured,
 				InvoiceID:      transaction.InvoiceID,
 				Status:         transaction.Status,
+				CreatedAt:      transaction.CreatedAt,
 			},
 		}
 
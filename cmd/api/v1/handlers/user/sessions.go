@@ -0,0 +1,121 @@
+package user
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/response"
+	serverrors "dddstructure/service/errors"
+
+	"github.com/beeker1121/httprouter"
+)
+
+// Session defines a user session.
+type Session struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ResultGetSessions defines the response data for the HandleGetSessions
+// handler.
+type ResultGetSessions struct {
+	Data []Session `json:"data"`
+}
+
+// HandleGetSessions handles the /api/v1/user/sessions GET route of the API.
+//
+// The session making the request is flagged as current.
+func HandleGetSessions(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this user and session from the request context.
+		user, err := auth.GetUserFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		sessionID, err := auth.GetSessionIDFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Get the sessions.
+		sessions, err := ac.Service.Session.GetByUserID(r.Context(), user.ID)
+		if err != nil {
+			ac.Logger.Error("session.GetByUserID() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Create a new Result.
+		result := ResultGetSessions{
+			Data: []Session{},
+		}
+
+		for _, v := range sessions {
+			result.Data = append(result.Data, Session{
+				ID:         v.ID,
+				UserAgent:  v.UserAgent,
+				IPAddress:  v.IPAddress,
+				Current:    v.ID == sessionID,
+				CreatedAt:  v.CreatedAt,
+				LastUsedAt: v.LastUsedAt,
+				ExpiresAt:  v.ExpiresAt,
+			})
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
+
+// HandleDeleteSession handles the /api/v1/user/sessions/:id DELETE route of
+// the API.
+//
+// The session is revoked, along with its access and refresh tokens.
+func HandleDeleteSession(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this user from the request context.
+		user, err := auth.GetUserFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Delete the session.
+		err = ac.Service.Session.DeleteForUser(r.Context(), httprouter.GetParam(r, "id"), user.ID)
+		if err == serverrors.ErrSessionNotFound {
+			errors.Default(ac.Logger, w, errors.New(http.StatusNotFound, "", err.Error()))
+			return
+		} else if err != nil {
+			ac.Logger.Error("session.DeleteForUser() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,262 @@
+package user
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/response"
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+)
+
+// TwoFactorEnrollment defines a two-factor authentication enrollment.
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// RecoveryCodes defines a set of two-factor authentication recovery codes.
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// ResultPostTwoFactorEnroll defines the response data for the
+// HandlePostTwoFactorEnroll handler.
+type ResultPostTwoFactorEnroll struct {
+	Data TwoFactorEnrollment `json:"data"`
+}
+
+// HandlePostTwoFactorEnroll handles the /api/v1/user/2fa/enroll POST route of
+// the API.
+//
+// The URI is the otpauth:// provisioning URI to show as a QR code.
+// Two-factor authentication is not enabled until a code is confirmed.
+func HandlePostTwoFactorEnroll(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this user from the request context.
+		user, err := auth.GetUserFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Start enrollment.
+		enrollment, err := ac.Service.User.EnrollTwoFactor(r.Context(), &proto.UserEnrollTwoFactorParams{
+			ID:     user.ID,
+			Issuer: ac.Config.TOTPIssuer,
+		})
+		if handleTwoFactorError(ac, w, err, "user.EnrollTwoFactor()") {
+			return
+		}
+
+		// Create a new Result.
+		result := ResultPostTwoFactorEnroll{
+			Data: TwoFactorEnrollment{
+				Secret: enrollment.Secret,
+				URI:    enrollment.URI,
+			},
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
+
+// RequestPostTwoFactorConfirm defines the request data for the
+// HandlePostTwoFactorConfirm handler.
+type RequestPostTwoFactorConfirm struct {
+	Code string `json:"code"`
+}
+
+// ResultPostTwoFactorConfirm defines the response data for the
+// HandlePostTwoFactorConfirm handler.
+type ResultPostTwoFactorConfirm struct {
+	Data RecoveryCodes `json:"data"`
+}
+
+// HandlePostTwoFactorConfirm handles the /api/v1/user/2fa/confirm POST route
+// of the API.
+//
+// Two-factor authentication is enabled, and the recovery codes returned are
+// only shown this once.
+func HandlePostTwoFactorConfirm(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Parse the parameters from the request body.
+		var req RequestPostTwoFactorConfirm
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errors.Default(ac.Logger, w, errors.ErrBadRequest)
+			return
+		}
+
+		// Get this user from the request context.
+		user, err := auth.GetUserFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Confirm enrollment.
+		codes, err := ac.Service.User.ConfirmTwoFactor(r.Context(), &proto.UserConfirmTwoFactorParams{
+			ID:   user.ID,
+			Code: req.Code,
+		})
+		if handleTwoFactorError(ac, w, err, "user.ConfirmTwoFactor()") {
+			return
+		}
+
+		// Create a new Result.
+		result := ResultPostTwoFactorConfirm{
+			Data: RecoveryCodes{
+				RecoveryCodes: codes,
+			},
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
+
+// RequestPostTwoFactorDisable defines the request data for the
+// HandlePostTwoFactorDisable handler.
+type RequestPostTwoFactorDisable struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// HandlePostTwoFactorDisable handles the /api/v1/user/2fa/disable POST route
+// of the API.
+//
+// Both the password and a TOTP or recovery code are needed.
+func HandlePostTwoFactorDisable(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Parse the parameters from the request body.
+		var req RequestPostTwoFactorDisable
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errors.Default(ac.Logger, w, errors.ErrBadRequest)
+			return
+		}
+
+		// Get this user from the request context.
+		user, err := auth.GetUserFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Disable two-factor authentication.
+		err = ac.Service.User.DisableTwoFactor(r.Context(), &proto.UserDisableTwoFactorParams{
+			ID:       user.ID,
+			Password: req.Password,
+			Code:     req.Code,
+		})
+		if handleTwoFactorError(ac, w, err, "user.DisableTwoFactor()") {
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// RequestPostRecoveryCodes defines the request data for the
+// HandlePostRecoveryCodes handler.
+type RequestPostRecoveryCodes struct {
+	Code string `json:"code"`
+}
+
+// ResultPostRecoveryCodes defines the response data for the
+// HandlePostRecoveryCodes handler.
+type ResultPostRecoveryCodes struct {
+	Data RecoveryCodes `json:"data"`
+}
+
+// HandlePostRecoveryCodes handles the /api/v1/user/2fa/recovery-codes POST
+// route of the API.
+//
+// The existing recovery codes are replaced, and the new ones are only shown
+// this once.
+func HandlePostRecoveryCodes(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Parse the parameters from the request body.
+		var req RequestPostRecoveryCodes
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errors.Default(ac.Logger, w, errors.ErrBadRequest)
+			return
+		}
+
+		// Get this user from the request context.
+		user, err := auth.GetUserFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Regenerate the recovery codes.
+		codes, err := ac.Service.User.RegenerateRecoveryCodes(r.Context(), &proto.UserRegenerateRecoveryCodesParams{
+			ID:   user.ID,
+			Code: req.Code,
+		})
+		if handleTwoFactorError(ac, w, err, "user.RegenerateRecoveryCodes()") {
+			return
+		}
+
+		// Create a new Result.
+		result := ResultPostRecoveryCodes{
+			Data: RecoveryCodes{
+				RecoveryCodes: codes,
+			},
+		}
+
+		// Respond with JSON.
+		if err := response.JSON(w, true, result); err != nil {
+			ac.Logger.Error("response.JSON() error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+	}
+}
+
+// handleTwoFactorError renders an error returned from a two-factor
+// authentication service method, returning true if there was an error.
+func handleTwoFactorError(ac *apictx.Context, w http.ResponseWriter, err error, method string) bool {
+	if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
+		errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
+		return true
+	} else if err == serverrors.ErrUserTwoFactorEnabled || err == serverrors.ErrUserTwoFactorNotEnabled {
+		errors.Default(ac.Logger, w, errors.New(http.StatusConflict, "", err.Error()))
+		return true
+	} else if err != nil {
+		ac.Logger.Error(method+" service error",
+			slog.Any("error", err))
+		errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+		return true
+	}
+
+	return false
+}
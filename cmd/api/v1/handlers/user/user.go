@@ -8,6 +8,7 @@ import (
 	apictx "dddstructure/cmd/api/context"
 	"dddstructure/cmd/api/errors"
 	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/middleware/logging"
 	"dddstructure/cmd/api/response"
 	"dddstructure/proto"
 	serverrors "dddstructure/service/errors"
@@ -18,14 +19,22 @@ import (
 // New creates the routes for the user endpoints of the API.
 func New(ac *apictx.Context, router *httprouter.Router) {
 	// Handle the routes.
-	router.GET("/api/v1/user", auth.AuthenticateEndpoint(ac, HandleGet(ac)))
-	router.POST("/api/v1/user", auth.AuthenticateEndpoint(ac, HandlePost(ac)))
+	logging.Handle(router, http.MethodGet, "/api/v1/user", auth.AuthenticateEndpoint(ac, HandleGet(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/user", auth.AuthenticateEndpoint(ac, HandlePost(ac)))
+	logging.Handle(router, http.MethodGet, "/api/v1/user/sessions", auth.AuthenticateEndpoint(ac, HandleGetSessions(ac)))
+	logging.Handle(router, http.MethodDelete, "/api/v1/user/sessions/:id", auth.AuthenticateEndpoint(ac, HandleDeleteSession(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/user/2fa/enroll", auth.AuthenticateEndpoint(ac, HandlePostTwoFactorEnroll(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/user/2fa/confirm", auth.AuthenticateEndpoint(ac, HandlePostTwoFactorConfirm(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/user/2fa/disable", auth.AuthenticateEndpoint(ac, HandlePostTwoFactorDisable(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/user/2fa/recovery-codes", auth.AuthenticateEndpoint(ac, HandlePostRecoveryCodes(ac)))
 }
 
 // User defines a user.
 type User struct {
-	ID    uint   `json:"id"`
-	Email string `json:"email"`
+	ID               uint   `json:"id"`
+	Email            string `json:"email"`
+	EmailVerified    bool   `json:"email_verified"`
+	TwoFactorEnabled bool   `json:"two_factor_enabled"`
 }
 
 // ResultGet defines the response data for the HandleGet handler.
@@ -36,6 +45,9 @@ type ResultGet struct {
 // HandleGet handles the /api/v1/user GET route of the API.
 func HandleGet(ac *apictx.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
 		// Get this user from the request context.
 		user, err := auth.GetUserFromRequest(r)
 		if err != nil {
@@ -44,7 +56,7 @@ func HandleGet(ac *apictx.Context) http.HandlerFunc {
 		}
 
 		// Get the user.
-		serviceu, err := ac.Service.User.GetByID(user.ID)
+		serviceu, err := ac.Service.User.GetByID(r.Context(), user.ID)
 		if err != nil {
 			ac.Logger.Error("user.GetByID() service error",
 				slog.Any("error", err))
@@ -55,8 +67,10 @@ func HandleGet(ac *apictx.Context) http.HandlerFunc {
 		// Create a new Result.
 		result := ResultGet{
 			Data: User{
-				ID:    serviceu.ID,
-				Email: serviceu.Email,
+				ID:               serviceu.ID,
+				Email:            serviceu.Email,
+				EmailVerified:    serviceu.EmailVerifiedAt != nil,
+				TwoFactorEnabled: serviceu.TwoFactorEnabledAt != nil,
 			},
 		}
 
@@ -84,6 +98,9 @@ type ResultPost struct {
 // HandlePost handles the /api/v1/user POST route of the API.
 func HandlePost(ac *apictx.Context) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
 		// Parse the parameters from the request body.
 		var req RequestPost
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -99,7 +116,8 @@ func HandlePost(ac *apictx.Context) http.HandlerFunc {
 		}
 
 		// Update the user.
-		user, err = ac.Service.User.Update(&proto.UserUpdateParams{
+		oldEmail := user.Email
+		user, err = ac.Service.User.Update(r.Context(), &proto.UserUpdateParams{
 			ID:       &user.ID,
 			Email:    req.Email,
 			Password: req.Password,
@@ -114,11 +132,24 @@ func HandlePost(ac *apictx.Context) http.HandlerFunc {
 			return
 		}
 
+		// Send an email verification token if the email changed.
+		if user.Email != oldEmail {
+			if err := ac.Service.User.SendEmailVerification(r.Context(), &proto.UserSendEmailVerificationParams{
+				ID:   user.ID,
+				Link: ac.Config.VerifyLink,
+			}); err != nil {
+				ac.Logger.Error("user.SendEmailVerification() service error",
+					slog.Any("error", err))
+			}
+		}
+
 		// Create a new Result.
 		result := ResultPost{
 			Data: User{
-				ID:    user.ID,
-				Email: user.Email,
+				ID:               user.ID,
+				Email:            user.Email,
+				EmailVerified:    user.EmailVerifiedAt != nil,
+				TwoFactorEnabled: user.TwoFactorEnabledAt != nil,
 			},
 		}
 
@@ -0,0 +1,90 @@
+package verify
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"dddstructure/cmd/api/config"
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/errors"
+	"dddstructure/cmd/api/middleware/auth"
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/cmd/api/middleware/ratelimit"
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+
+	"github.com/beeker1121/httprouter"
+)
+
+// New creates the routes for the email verification endpoints of the API.
+func New(ac *apictx.Context, router *httprouter.Router) {
+	// Handle the routes.
+	logging.Handle(router, http.MethodPost, "/api/v1/verify-email", ratelimit.LimitEndpoint(ac, config.RateLimitGroupAuth, auth.RateLimitKey, HandlePost(ac)))
+	logging.Handle(router, http.MethodPost, "/api/v1/verify-email/resend", auth.AuthenticateEndpoint(ac, HandlePostResend(ac)))
+}
+
+// RequestPost defines the request data for the HandlePost handler.
+type RequestPost struct {
+	Token string `json:"token"`
+}
+
+// HandlePost handles the /api/v1/verify-email POST route of the API.
+func HandlePost(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Parse the parameters from the request body.
+		var req RequestPost
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errors.Default(ac.Logger, w, errors.ErrBadRequest)
+			return
+		}
+
+		// Verify the email.
+		_, err := ac.Service.User.VerifyEmail(r.Context(), &proto.UserVerifyEmailParams{
+			Token: req.Token,
+		})
+		if pes, ok := err.(*serverrors.ParamErrors); ok && err != nil {
+			errors.Params(ac.Logger, w, http.StatusBadRequest, pes)
+			return
+		} else if err != nil {
+			ac.Logger.Error("user.VerifyEmail() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// HandlePostResend handles the /api/v1/verify-email/resend POST route of the
+// API.
+func HandlePostResend(ac *apictx.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Use the API context scoped to this request.
+		ac := ac.FromRequest(r)
+
+		// Get this user from the request context.
+		user, err := auth.GetUserFromRequest(r)
+		if err != nil {
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		// Send the email verification token.
+		if err := ac.Service.User.SendEmailVerification(r.Context(), &proto.UserSendEmailVerificationParams{
+			ID:   user.ID,
+			Link: ac.Config.VerifyLink,
+		}); err != nil {
+			ac.Logger.Error("user.SendEmailVerification() service error",
+				slog.Any("error", err))
+			errors.Default(ac.Logger, w, errors.ErrInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -4,9 +4,14 @@ import (
 	apictx "dddstructure/cmd/api/context"
 	"dddstructure/cmd/api/v1/handlers/invoice"
 	"dddstructure/cmd/api/v1/handlers/login"
+	"dddstructure/cmd/api/v1/handlers/organization"
+	"dddstructure/cmd/api/v1/handlers/password"
+	"dddstructure/cmd/api/v1/handlers/report"
 	"dddstructure/cmd/api/v1/handlers/signup"
+	"dddstructure/cmd/api/v1/handlers/token"
 	"dddstructure/cmd/api/v1/handlers/transaction"
 	"dddstructure/cmd/api/v1/handlers/user"
+	"dddstructure/cmd/api/v1/handlers/verify"
 
 	"github.com/beeker1121/httprouter"
 )
@@ -15,7 +20,12 @@ import (
 func New(ac *apictx.Context, r *httprouter.Router) {
 	invoice.New(ac, r)
 	login.New(ac, r)
+	organization.New(ac, r)
+	password.New(ac, r)
+	report.New(ac, r)
 	signup.New(ac, r)
+	token.New(ac, r)
 	transaction.New(ac, r)
 	user.New(ac, r)
+	verify.New(ac, r)
 }
@@ -1,14 +1,16 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
 
+	maillogger "dddstructure/mail/logger"
 	"dddstructure/proto"
 	"dddstructure/service"
-	"dddstructure/storage/mock"
+	"dddstructure/service/actor"
+	"dddstructure/storage/memory"
 )
 
 func main() {
@@ -17,16 +19,17 @@ func main() {
 	// Create a new logger.
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
-	// Create a new mock storage implementation.
-	fmt.Println("[+] Creating new mock storage implementation...")
-	store := mock.New(&sql.DB{})
+	// Create a new memory storage implementation.
+	fmt.Println("[+] Creating new memory storage implementation...")
+	store := memory.New()
 
 	// Create a new service.
 	fmt.Println("[+] Creating new service...")
-	serv := service.New(store, logger)
+	serv := service.New(store, maillogger.New(logger), logger)
+	ctx := context.Background()
 
 	// Create a user.
-	u, err := serv.User.Create(&proto.UserCreateParams{
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
 		Email:    "johndoe@gmail.com",
 		Password: "TestPassword123",
 	})
@@ -34,8 +37,19 @@ func main() {
 		panic(err)
 	}
 
+	// Get the user's personal organization.
+	orgs, err := serv.Organization.GetForUser(ctx, u.ID)
+	if err != nil {
+		panic(err)
+	}
+	org := orgs[0]
+
+	// Call the services on behalf of the user.
+	ctx = actor.WithUser(ctx, u.ID)
+
 	// Create an invoice.
-	i, err := serv.Invoice.Create(&proto.InvoiceCreateParams{
+	i, err := serv.Invoice.Create(ctx, &proto.InvoiceCreateParams{
+		OrganizationID: org.ID,
 		UserID:         u.ID,
 		PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
 		BillTo: proto.InvoiceBillTo{
@@ -59,7 +73,7 @@ func main() {
 	fmt.Printf("[+] New invoice: %+v\n", *i)
 
 	// Pay an invoice, will call transaction.Process service.
-	i, err = serv.Invoice.Pay(i.ID, &proto.InvoicePayParams{
+	i, err = serv.Invoice.Pay(ctx, i.ID, &proto.InvoicePayParams{
 		Amount: 100,
 	})
 	if err != nil {
@@ -68,11 +82,12 @@ func main() {
 	fmt.Printf("[+] Paid invoice: %+v\n", *i)
 
 	// Process a transaction, will call invoice.Update service.
-	t, err := serv.Transaction.Process(&proto.TransactionProcessParams{
-		UserID:    i.UserID,
-		Type:      "refund",
-		Amount:    100,
-		InvoiceID: i.ID,
+	t, err := serv.Transaction.Process(ctx, &proto.TransactionProcessParams{
+		OrganizationID: i.OrganizationID,
+		UserID:         i.UserID,
+		Type:           "refund",
+		Amount:         100,
+		InvoiceID:      i.ID,
 	})
 	if err != nil {
 		panic(err)
@@ -80,7 +95,7 @@ func main() {
 	fmt.Printf("[+] New transaction processed: %+v\n", *t)
 
 	// Get the invoice again.
-	i, err = serv.Invoice.GetByID(i.ID)
+	i, err = serv.Invoice.GetByID(ctx, i.ID)
 	if err != nil {
 		panic(err)
 	}
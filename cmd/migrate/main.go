@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"dddstructure/db"
+	"dddstructure/db/migrate"
+	mysqlmigrate "dddstructure/storage/mysql/migrate"
+	"dddstructure/storage/postgres"
+	pgmigrate "dddstructure/storage/postgres/migrate"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
+
+// usage defines the usage of the command.
+const usage = `Usage: migrate [flags] <command>
+
+Commands:
+  up              apply every migration not yet applied
+  down            roll back the latest applied migration
+  status          show whether each migration is applied
+  to <version>    apply or roll back migrations until version is the latest
+                  applied, where 0 rolls back every migration
+  baseline [version]
+                  record migrations as applied without running them, until
+                  version is the latest recorded, for a MySQL database
+                  created before migrations. The version defaults to 1, the
+                  baseline
+
+The database is set by the DB_HOST, DB_PORT, DB_NAME, DB_USER and DB_PASS
+environment variables, and for Postgres the SSL mode by DB_SSLMODE. MySQL
+databases are migrated with db/migrations and Postgres databases with
+db/postgres/migrations.
+
+Flags:
+`
+
+func main() {
+	driver := flag.String("driver", "mysql", "database to migrate, mysql or postgres")
+	lockTimeout := flag.Uint("lock-timeout", 60, "seconds to wait for another migration to finish")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Create a new logger.
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	// Stop on a signal.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var m *migrate.Migrator
+	switch *driver {
+	case "mysql":
+		// Load the migrations.
+		migrations, err := migrate.Load(db.Migrations, "migrations")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		// Connect to the MySQL database. Migration files hold many
+		// statements, so they are allowed in a single query.
+		conn, err := sql.Open("mysql", os.Getenv("DB_USER")+":"+os.Getenv("DB_PASS")+"@tcp("+os.Getenv("DB_HOST")+":"+os.Getenv("DB_PORT")+")/"+os.Getenv("DB_NAME")+"?parseTime=true&multiStatements=true")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer conn.Close()
+
+		m = mysqlmigrate.New(conn, migrations, time.Second*time.Duration(*lockTimeout), logger)
+	case "postgres":
+		// Load the migrations, which are laid out the same way.
+		migrations, err := migrate.Load(db.PostgresMigrations, "postgres/migrations")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		// Connect to the Postgres database, which runs many statements in
+		// a single query without any option.
+		conn, err := sql.Open("postgres", postgres.DSN(os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_NAME"), os.Getenv("DB_USER"), os.Getenv("DB_PASS"), os.Getenv("DB_SSLMODE")))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer conn.Close()
+
+		m = pgmigrate.New(conn, migrations, time.Second*time.Duration(*lockTimeout), logger)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown driver %s\n", *driver)
+		os.Exit(2)
+	}
+
+	// Every Postgres database was created by the migrations, so there is no
+	// baseline to record.
+	if *driver == "postgres" && flag.Arg(0) == "baseline" {
+		fmt.Fprintln(os.Stderr, "baseline is only needed for MySQL databases created before migrations")
+		os.Exit(2)
+	}
+
+	if err := run(ctx, m, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run runs the given command.
+func run(ctx context.Context, m *migrate.Migrator, args []string) error {
+	switch args[0] {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		return m.Down(ctx)
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Migration.Version, s.Migration.Name, applied)
+		}
+		return nil
+	case "to":
+		if len(args) != 2 {
+			return fmt.Errorf("to requires a version")
+		}
+
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid version %s", args[1])
+		}
+		return m.To(ctx, uint(version))
+	case "baseline":
+		if len(args) == 1 {
+			return m.Baseline(ctx, 1)
+		}
+
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid version %s", args[1])
+		}
+		return m.Baseline(ctx, uint(version))
+	default:
+		return fmt.Errorf("unknown command %s", args[0])
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"dddstructure/mail"
+	maillogger "dddstructure/mail/logger"
+	mailsmtp "dddstructure/mail/smtp"
+	"dddstructure/service"
+	"dddstructure/service/remote"
+	"dddstructure/storage"
+	"dddstructure/storage/memory"
+	storagemysql "dddstructure/storage/mysql"
+	"dddstructure/storage/postgres"
+	"dddstructure/storage/sqlite"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
+
+// usage defines the usage of the command.
+const usage = `Usage: service [flags] <domain>
+
+Runs the invoice, transaction or user service on its own, serving it over
+HTTP/JSON to the other services with the clients of service/remote.
+
+Domains:
+  invoice         serve the invoice service
+  transaction     serve the transaction service
+  user            serve the user service
+
+The database is set by the DB_HOST, DB_PORT, DB_NAME, DB_USER and DB_PASS
+environment variables, and for Postgres the SSL mode by DB_SSLMODE. Every
+service must use the same database.
+
+The services of the other domains are called at the URLs in the
+INVOICE_SERVICE_URL, TRANSACTION_SERVICE_URL and USER_SERVICE_URL
+environment variables, and are run in this process if not set. Calls must
+have the token in the SERVICE_TOKEN environment variable, which is required,
+and the other services are called with it. Mail is sent through the SMTP
+server in the SMTP_HOST, SMTP_PORT, SMTP_USER, SMTP_PASS and MAIL_FROM
+environment variables, or only logged if SMTP_HOST is not set.
+
+Flags:
+`
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8081", "address to listen on, which must only be reachable by the other services")
+	driver := flag.String("storage", "mysql", "storage backend, mysql, postgres, sqlite or memory")
+	sqlitePath := flag.String("sqlite-path", "dddstructure.db", "path of the SQLite database")
+	dbTimeout := flag.Uint("db-timeout", 5, "seconds to wait for each database call")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	domain := flag.Arg(0)
+
+	// Create a new logger.
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil)).With(slog.String("domain", domain))
+
+	// Create a new server, which only serves calls with the token.
+	token := os.Getenv("SERVICE_TOKEN")
+	rs, err := remote.NewServer(token, logger)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "SERVICE_TOKEN must be set")
+		os.Exit(2)
+	}
+
+	// Create a new storage implementation.
+	timeout := time.Second * time.Duration(*dbTimeout)
+
+	var store *storage.Storage
+	switch *driver {
+	case "mysql":
+		db, err := sql.Open("mysql", os.Getenv("DB_USER")+":"+os.Getenv("DB_PASS")+"@tcp("+os.Getenv("DB_HOST")+":"+os.Getenv("DB_PORT")+")/"+os.Getenv("DB_NAME")+"?parseTime=true")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		store = storagemysql.New(db, timeout, nil)
+	case "postgres":
+		db, err := sql.Open("postgres", postgres.DSN(os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_NAME"), os.Getenv("DB_USER"), os.Getenv("DB_PASS"), os.Getenv("DB_SSLMODE")))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		store = postgres.New(db, timeout, nil)
+	case "sqlite":
+		db, err := sqlite.Open(context.Background(), *sqlitePath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		store = sqlite.New(db, timeout, nil)
+	case "memory":
+		// The data is only seen by this process, so this is only for
+		// running every domain in one process.
+		store = memory.New()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown storage %s\n", *driver)
+		os.Exit(2)
+	}
+
+	// Create a new mail sender.
+	var mailer mail.Sender
+	if os.Getenv("SMTP_HOST") != "" {
+		mailer = mailsmtp.New(os.Getenv("SMTP_HOST"), os.Getenv("SMTP_PORT"), os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASS"), os.Getenv("MAIL_FROM"))
+	} else {
+		mailer = maillogger.New(logger)
+	}
+
+	// Create a new service, calling the services of the other domains in
+	// their own processes if set.
+	serv := service.New(store, mailer, logger)
+
+	var r service.Remote
+	if url := os.Getenv("INVOICE_SERVICE_URL"); url != "" && domain != "invoice" {
+		r.Invoice = remote.NewInvoice(remote.NewClient(url, token))
+	}
+	if url := os.Getenv("TRANSACTION_SERVICE_URL"); url != "" && domain != "transaction" {
+		r.Transaction = remote.NewTransaction(remote.NewClient(url, token))
+	}
+	if url := os.Getenv("USER_SERVICE_URL"); url != "" && domain != "user" {
+		r.User = remote.NewUser(remote.NewClient(url, token))
+	}
+	serv.SetRemote(r)
+
+	// Serve the service of the domain.
+	switch domain {
+	case "invoice":
+		rs.RegisterInvoice(serv.Invoice)
+	case "transaction":
+		rs.RegisterTransaction(serv.Transaction)
+	case "user":
+		rs.RegisterUser(serv.User)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown domain %s\n", domain)
+		os.Exit(2)
+	}
+
+	// Create a new HTTP server.
+	server := &http.Server{
+		Addr:           *addr,
+		Handler:        rs,
+		ReadTimeout:    10 * time.Second,
+		WriteTimeout:   30 * time.Second,
+		MaxHeaderBytes: 1 << 20,
+	}
+
+	fmt.Printf("[+] Running %s service on %s...\n", domain, server.Addr)
+
+	// Start the HTTP server.
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.ListenAndServe()
+	}()
+
+	// Wait for the server to fail or a signal to shut down.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case err := <-errc:
+		logger.Error("server.ListenAndServe() error",
+			slog.Any("error", err))
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+
+	// Wait for in-flight calls to finish.
+	fmt.Println("[+] Shutting down server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("server.Shutdown() error",
+			slog.Any("error", err))
+		return
+	}
+
+	fmt.Println("[+] Server shut down")
+}
@@ -0,0 +1,19 @@
+package db
+
+import "embed"
+
+// Migrations holds the versioned schema migrations, embedded so every binary
+// carries the migrations of the schema it expects.
+//
+// Each migration is a pair of files named <version>_<name>.up.sql and
+// <version>_<name>.down.sql, where the version is a number unique to it.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// PostgresMigrations holds the versioned schema migrations of the Postgres
+// database, named the same way as Migrations in the postgres/migrations
+// directory.
+//
+//go:embed postgres/migrations/*.sql
+var PostgresMigrations embed.FS
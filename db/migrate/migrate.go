@@ -0,0 +1,451 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrLocked is returned when another migration holds the lock for longer
+	// than the lock timeout.
+	ErrLocked = errors.New("another migration is running")
+
+	// ErrUnknownVersion is returned when a version has no migration.
+	ErrUnknownVersion = errors.New("unknown migration version")
+
+	// ErrAlreadyApplied is returned when recording a baseline on a database
+	// that already has migrations applied.
+	ErrAlreadyApplied = errors.New("migrations are already applied")
+)
+
+// fileRegexp matches the file name of a migration.
+var fileRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration defines a single versioned migration.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status defines whether a migration is applied.
+type Status struct {
+	Migration *Migration
+	AppliedAt *time.Time
+}
+
+// Load loads the migrations in the given directory of the given file system,
+// in order of version.
+//
+// Every migration must have both an up and a down file, and each version
+// must be unique.
+func Load(fsys fs.FS, dir string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		match := fileRegexp.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", e.Name())
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", e.Name())
+		}
+
+		b, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %d", version)
+		}
+
+		// Versions are numbers, so 0001_a and 1_a are the same version and
+		// only one of them may be loaded.
+		file := &m.Up
+		if match[3] == "down" {
+			file = &m.Down
+		}
+		if *file != "" {
+			return nil, fmt.Errorf("duplicate migration %d_%s.%s.sql", version, m.Name, match[3])
+		}
+		*file = string(b)
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Driver defines the parts of migrating that differ between databases.
+type Driver interface {
+	// Lock takes the migration lock on the given connection, so only one
+	// deploy migrates the database at a time, waiting up to the given
+	// timeout. It returns ErrLocked if the lock is still held by another
+	// connection after the timeout.
+	Lock(ctx context.Context, conn *sql.Conn, timeout time.Duration) error
+
+	// Unlock releases the migration lock held by the given connection.
+	Unlock(ctx context.Context, conn *sql.Conn) error
+
+	// CreateTable returns the statement creating the schema_migrations
+	// table if it does not exist, with version, name and applied_at
+	// columns.
+	CreateTable() string
+
+	// Placeholder returns the placeholder of the nth argument of a query,
+	// starting at 1.
+	Placeholder(n int) string
+
+	// Transactional returns whether schema changes can be rolled back, so
+	// each migration is run in a transaction with its record.
+	Transactional() bool
+}
+
+// Migrator defines a migrator, which applies and rolls back migrations.
+type Migrator struct {
+	db          *sql.DB
+	driver      Driver
+	migrations  []*Migration
+	lockTimeout time.Duration
+	logger      *slog.Logger
+}
+
+// New creates a new migrator for the given migrations of the database of the
+// given driver, waiting up to the given lock timeout for other migrations to
+// finish. Each migration applied or rolled back is logged to the given
+// logger.
+//
+// The database connection must allow multiple statements per query, as a
+// migration file is run as a single query.
+func New(db *sql.DB, d Driver, migrations []*Migration, lockTimeout time.Duration, l *slog.Logger) *Migrator {
+	return &Migrator{
+		db:          db,
+		driver:      d,
+		migrations:  migrations,
+		lockTimeout: lockTimeout,
+		logger:      l,
+	}
+}
+
+// Latest returns the version of the latest migration, or 0 if there are
+// none.
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status returns whether each migration is applied, in order of version.
+func (m *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	var statuses []*Status
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := getApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			s := &Status{Migration: mig}
+			if t, ok := applied[mig.Version]; ok {
+				s.AppliedAt = &t
+			}
+			statuses = append(statuses, s)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// Up applies every migration not yet applied.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down rolls back the latest applied migration, which must be known to this
+// binary.
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := getApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		// The latest applied migration can not be rolled back if this
+		// binary does not know it.
+		for v := range applied {
+			if v > m.Latest() {
+				return fmt.Errorf("%w %d is applied and can not be rolled back", ErrUnknownVersion, v)
+			}
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; ok {
+				return m.rollback(ctx, conn, mig)
+			}
+		}
+
+		return nil
+	})
+}
+
+// To applies or rolls back migrations until the given version is the latest
+// applied. Version 0 rolls back every migration.
+//
+// Applied versions newer than any migration known to this binary are left
+// alone when migrating up, so an older binary can still run during a rolling
+// deploy, but can not be rolled back.
+func (m *Migrator) To(ctx context.Context, version uint) error {
+	if version != 0 && m.find(version) == nil {
+		return ErrUnknownVersion
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := getApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		// Roll back the migrations after the version, newest first. Unknown
+		// versions can not be rolled back, but are left alone when migrating
+		// to the latest version.
+		for v := range applied {
+			if v > version && m.find(v) == nil && version != m.Latest() {
+				return fmt.Errorf("%w %d is applied and can not be rolled back", ErrUnknownVersion, v)
+			}
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; ok && mig.Version > version {
+				if err := m.rollback(ctx, conn, mig); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Apply the migrations up to the version, oldest first.
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; !ok && mig.Version <= version {
+				if err := m.apply(ctx, conn, mig); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// Baseline records every migration up to the given version as applied,
+// without running them, for a database that already has their schema.
+func (m *Migrator) Baseline(ctx context.Context, version uint) error {
+	if m.find(version) == nil {
+		return ErrUnknownVersion
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := getApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if len(applied) != 0 {
+			return ErrAlreadyApplied
+		}
+
+		for _, mig := range m.migrations {
+			if mig.Version > version {
+				break
+			}
+
+			if err := m.record(ctx, conn, mig); err != nil {
+				return err
+			}
+
+			m.logger.Info("migration recorded as applied",
+				slog.Uint64("version", uint64(mig.Version)),
+				slog.String("name", mig.Name))
+		}
+
+		return nil
+	})
+}
+
+// find returns the migration with the given version, or nil if there is
+// none.
+func (m *Migrator) find(version uint) *Migration {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return mig
+		}
+	}
+
+	return nil
+}
+
+// execer defines a connection or transaction running statements.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// apply runs the up file of a migration and records it as applied.
+//
+// If the driver can not roll back schema changes, such as MySQL, which
+// commits them as they are made, a migration that fails part way is not
+// rolled back, and must be fixed by hand before retrying.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig *Migration) error {
+	start := time.Now()
+
+	err := m.inTx(ctx, conn, func(e execer) error {
+		if _, err := e.ExecContext(ctx, mig.Up); err != nil {
+			return fmt.Errorf("migration %d_%s up: %w", mig.Version, mig.Name, err)
+		}
+
+		return m.record(ctx, e, mig)
+	})
+	if err != nil {
+		return err
+	}
+
+	m.logger.Info("migration applied",
+		slog.Uint64("version", uint64(mig.Version)),
+		slog.String("name", mig.Name),
+		slog.Duration("duration", time.Since(start)))
+
+	return nil
+}
+
+// rollback runs the down file of a migration and removes its record.
+func (m *Migrator) rollback(ctx context.Context, conn *sql.Conn, mig *Migration) error {
+	start := time.Now()
+
+	err := m.inTx(ctx, conn, func(e execer) error {
+		if _, err := e.ExecContext(ctx, mig.Down); err != nil {
+			return fmt.Errorf("migration %d_%s down: %w", mig.Version, mig.Name, err)
+		}
+
+		_, err := e.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version="+m.driver.Placeholder(1), mig.Version)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	m.logger.Info("migration rolled back",
+		slog.Uint64("version", uint64(mig.Version)),
+		slog.String("name", mig.Name),
+		slog.Duration("duration", time.Since(start)))
+
+	return nil
+}
+
+// record records a migration as applied.
+func (m *Migrator) record(ctx context.Context, e execer, mig *Migration) error {
+	_, err := e.ExecContext(ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES ("+
+		m.driver.Placeholder(1)+", "+m.driver.Placeholder(2)+", "+m.driver.Placeholder(3)+")",
+		mig.Version, mig.Name, time.Now().UTC())
+	return err
+}
+
+// inTx runs the given function in a transaction on the given connection if
+// the driver can roll back schema changes, committing it if the function
+// succeeds and rolling it back otherwise, or on the connection itself if not.
+func (m *Migrator) inTx(ctx context.Context, conn *sql.Conn, fn func(e execer) error) error {
+	if !m.driver.Transactional() {
+		return fn(conn)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// withLock runs the given function on a single connection holding the
+// migration lock, after creating the migrations table if needed.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := m.driver.Lock(ctx, conn, m.lockTimeout); err != nil {
+		return err
+	}
+	defer m.driver.Unlock(context.Background(), conn)
+
+	if _, err := conn.ExecContext(ctx, m.driver.CreateTable()); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// getApplied returns the time each applied migration was applied, by
+// version.
+func getApplied(ctx context.Context, conn *sql.Conn) (map[uint]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[uint]time.Time)
+	for rows.Next() {
+		var version uint
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt.UTC()
+	}
+
+	return applied, rows.Err()
+}
@@ -0,0 +1,3 @@
+DROP TABLE `transactions`;
+DROP TABLE `invoices`;
+DROP TABLE `users`;
@@ -1,6 +1,5 @@
-CREATE DATABASE IF NOT EXISTS `dddstructure`;
-
-USE `dddstructure`;
+-- The baseline schema, as it was created by the original db/init.sql. The
+-- database is the one cmd/migrate connects to, so it is not created here.
 
 CREATE TABLE `users` (
     `id` int UNSIGNED NOT NULL,
@@ -59,4 +58,4 @@ CREATE TABLE `transactions` (
     `invoice_id` int UNSIGNED NOT NULL,
     `status` enum('approved', 'declined') NOT NULL,
     PRIMARY KEY (`id`)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
\ No newline at end of file
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+ALTER TABLE `invoices` DROP KEY `user_id_created_at_id`;
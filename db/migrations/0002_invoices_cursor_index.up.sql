@@ -0,0 +1,2 @@
+-- Keyset pagination orders invoices by created_at and id per user.
+ALTER TABLE `invoices` ADD KEY `user_id_created_at_id` (`user_id`, `created_at`, `id`);
@@ -0,0 +1,2 @@
+ALTER TABLE `transactions` DROP KEY `user_id_created_at`;
+ALTER TABLE `transactions` DROP COLUMN `created_at`;
@@ -0,0 +1,5 @@
+-- Revenue reports group transactions by when they were processed. Existing
+-- transactions never recorded this, so they take the time of the migration.
+ALTER TABLE `transactions` ADD COLUMN `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP AFTER `status`;
+ALTER TABLE `transactions` ALTER COLUMN `created_at` DROP DEFAULT;
+ALTER TABLE `transactions` ADD KEY `user_id_created_at` (`user_id`, `created_at`);
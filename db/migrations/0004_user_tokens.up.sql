@@ -0,0 +1,13 @@
+-- Users verify their email through a token sent to it.
+ALTER TABLE `users` ADD COLUMN `email_verified_at` datetime DEFAULT NULL AFTER `password`;
+
+-- Password reset and email verification tokens, stored as SHA-256 hashes.
+CREATE TABLE `user_tokens` (
+    `hash` char(64) NOT NULL,
+    `user_id` int UNSIGNED NOT NULL,
+    `type` enum('password_reset', 'email_verification') NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `created_at` datetime NOT NULL,
+    PRIMARY KEY (`hash`),
+    KEY `user_id_type` (`user_id`, `type`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
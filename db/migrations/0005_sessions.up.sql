@@ -0,0 +1,23 @@
+-- Sessions are created on login and kept alive by refresh tokens.
+CREATE TABLE `sessions` (
+    `id` char(36) NOT NULL,
+    `user_id` int UNSIGNED NOT NULL,
+    `user_agent` varchar(255) NOT NULL,
+    `ip_address` varchar(45) NOT NULL,
+    `created_at` datetime NOT NULL,
+    `last_used_at` datetime NOT NULL,
+    `expires_at` datetime NOT NULL,
+    PRIMARY KEY (`id`),
+    KEY `user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Refresh tokens are stored as SHA-256 hashes. Used tokens are kept until
+-- their session is deleted, so reuse of an old token can be detected.
+CREATE TABLE `refresh_tokens` (
+    `hash` char(64) NOT NULL,
+    `session_id` char(36) NOT NULL,
+    `used_at` datetime DEFAULT NULL,
+    `created_at` datetime NOT NULL,
+    PRIMARY KEY (`hash`),
+    KEY `session_id` (`session_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
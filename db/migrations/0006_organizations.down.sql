@@ -0,0 +1,13 @@
+-- Invoices and transactions go back to being scoped by the user who created
+-- them. Organizations, and anything shared through them, are lost.
+ALTER TABLE `transactions` DROP KEY `organization_id_created_at`;
+ALTER TABLE `transactions` ADD KEY `user_id_created_at` (`user_id`, `created_at`);
+ALTER TABLE `transactions` DROP COLUMN `organization_id`;
+
+ALTER TABLE `invoices` DROP KEY `organization_id_created_at_id`;
+ALTER TABLE `invoices` ADD KEY `user_id_created_at_id` (`user_id`, `created_at`, `id`);
+ALTER TABLE `invoices` DROP COLUMN `organization_id`;
+
+DROP TABLE `organization_invitations`;
+DROP TABLE `organization_members`;
+DROP TABLE `organizations`;
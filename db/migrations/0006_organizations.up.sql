@@ -0,0 +1,49 @@
+-- Organizations own invoices and transactions, and users access them through
+-- their membership role.
+CREATE TABLE `organizations` (
+    `id` int UNSIGNED NOT NULL,
+    `name` varchar(255) NOT NULL,
+    `created_at` datetime NOT NULL,
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `organization_members` (
+    `organization_id` int UNSIGNED NOT NULL,
+    `user_id` int UNSIGNED NOT NULL,
+    `role` enum('owner', 'admin', 'accountant', 'read_only') NOT NULL,
+    `created_at` datetime NOT NULL,
+    PRIMARY KEY (`organization_id`, `user_id`),
+    KEY `user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Invitation tokens are stored as SHA-256 hashes, like user tokens.
+CREATE TABLE `organization_invitations` (
+    `hash` char(64) NOT NULL,
+    `organization_id` int UNSIGNED NOT NULL,
+    `email` varchar(255) NOT NULL,
+    `role` enum('owner', 'admin', 'accountant', 'read_only') NOT NULL,
+    `invited_by` int UNSIGNED NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `created_at` datetime NOT NULL,
+    PRIMARY KEY (`hash`),
+    KEY `organization_id` (`organization_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Every existing user gets a personal organization with the same ID, which
+-- takes over their invoices and transactions.
+INSERT INTO `organizations` (`id`, `name`, `created_at`)
+    SELECT `id`, `email`, UTC_TIMESTAMP() FROM `users`;
+INSERT INTO `organization_members` (`organization_id`, `user_id`, `role`, `created_at`)
+    SELECT `id`, `id`, 'owner', UTC_TIMESTAMP() FROM `users`;
+
+ALTER TABLE `invoices` ADD COLUMN `organization_id` int UNSIGNED NOT NULL DEFAULT 0 AFTER `id`;
+UPDATE `invoices` SET `organization_id`=`user_id`;
+ALTER TABLE `invoices` ALTER COLUMN `organization_id` DROP DEFAULT;
+ALTER TABLE `invoices` DROP KEY `user_id_created_at_id`;
+ALTER TABLE `invoices` ADD KEY `organization_id_created_at_id` (`organization_id`, `created_at`, `id`);
+
+ALTER TABLE `transactions` ADD COLUMN `organization_id` int UNSIGNED NOT NULL DEFAULT 0 AFTER `id`;
+UPDATE `transactions` SET `organization_id`=`user_id`;
+ALTER TABLE `transactions` ALTER COLUMN `organization_id` DROP DEFAULT;
+ALTER TABLE `transactions` DROP KEY `user_id_created_at`;
+ALTER TABLE `transactions` ADD KEY `organization_id_created_at` (`organization_id`, `created_at`);
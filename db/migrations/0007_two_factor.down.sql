@@ -0,0 +1,11 @@
+DROP TABLE `recovery_codes`;
+
+-- Pending login challenges can't be kept without their type.
+DELETE FROM `user_tokens` WHERE `type`='two_factor_challenge';
+ALTER TABLE `user_tokens`
+    MODIFY COLUMN `type` enum('password_reset', 'email_verification') NOT NULL;
+
+ALTER TABLE `users`
+    DROP COLUMN `totp_last_step`,
+    DROP COLUMN `totp_enabled_at`,
+    DROP COLUMN `totp_secret`;
@@ -0,0 +1,21 @@
+-- The TOTP secret is set on enrollment, and two-factor authentication is only
+-- turned on once totp_enabled_at is set by confirming a code. The last used
+-- time step is kept so a code can't be used twice.
+ALTER TABLE `users`
+    ADD COLUMN `totp_secret` varchar(64) DEFAULT NULL AFTER `email_verified_at`,
+    ADD COLUMN `totp_enabled_at` datetime DEFAULT NULL AFTER `totp_secret`,
+    ADD COLUMN `totp_last_step` bigint UNSIGNED DEFAULT NULL AFTER `totp_enabled_at`;
+
+-- Login challenges are handed out after the password is checked, and are
+-- exchanged for a session with a TOTP or recovery code.
+ALTER TABLE `user_tokens`
+    MODIFY COLUMN `type` enum('password_reset', 'email_verification', 'two_factor_challenge') NOT NULL;
+
+-- Recovery codes are stored as SHA-256 hashes and deleted once used.
+CREATE TABLE `recovery_codes` (
+    `hash` char(64) NOT NULL,
+    `user_id` int UNSIGNED NOT NULL,
+    `created_at` datetime NOT NULL,
+    PRIMARY KEY (`hash`),
+    KEY `user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
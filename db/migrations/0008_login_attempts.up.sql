@@ -0,0 +1,8 @@
+-- Failed logins are counted per email and per client IP address, and the
+-- count is forgotten once no attempt has failed for the lockout duration.
+CREATE TABLE `login_attempts` (
+    `attempt_key` varchar(255) NOT NULL,
+    `failures` int UNSIGNED NOT NULL,
+    `last_failed_at` datetime NOT NULL,
+    PRIMARY KEY (`attempt_key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
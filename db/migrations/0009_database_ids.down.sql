@@ -0,0 +1,24 @@
+-- This fails if any sortable IDs were assigned, as they do not fit.
+ALTER TABLE `transactions`
+    MODIFY COLUMN `id` int UNSIGNED NOT NULL,
+    MODIFY COLUMN `organization_id` int UNSIGNED NOT NULL,
+    MODIFY COLUMN `user_id` int UNSIGNED NOT NULL,
+    MODIFY COLUMN `invoice_id` int UNSIGNED NOT NULL;
+
+ALTER TABLE `invoices`
+    MODIFY COLUMN `id` int UNSIGNED NOT NULL,
+    MODIFY COLUMN `organization_id` int UNSIGNED NOT NULL,
+    MODIFY COLUMN `user_id` int UNSIGNED NOT NULL;
+
+ALTER TABLE `organization_invitations`
+    MODIFY COLUMN `organization_id` int UNSIGNED NOT NULL,
+    MODIFY COLUMN `invited_by` int UNSIGNED NOT NULL;
+ALTER TABLE `organization_members`
+    MODIFY COLUMN `organization_id` int UNSIGNED NOT NULL,
+    MODIFY COLUMN `user_id` int UNSIGNED NOT NULL;
+ALTER TABLE `organizations` MODIFY COLUMN `id` int UNSIGNED NOT NULL;
+
+ALTER TABLE `sessions` MODIFY COLUMN `user_id` int UNSIGNED NOT NULL;
+ALTER TABLE `recovery_codes` MODIFY COLUMN `user_id` int UNSIGNED NOT NULL;
+ALTER TABLE `user_tokens` MODIFY COLUMN `user_id` int UNSIGNED NOT NULL;
+ALTER TABLE `users` MODIFY COLUMN `id` int UNSIGNED NOT NULL;
@@ -0,0 +1,25 @@
+-- IDs are assigned by the database, and widened so they can also be assigned
+-- by the sortable ID strategy. Columns referencing them are widened to match.
+ALTER TABLE `users` MODIFY COLUMN `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT;
+ALTER TABLE `user_tokens` MODIFY COLUMN `user_id` bigint UNSIGNED NOT NULL;
+ALTER TABLE `recovery_codes` MODIFY COLUMN `user_id` bigint UNSIGNED NOT NULL;
+ALTER TABLE `sessions` MODIFY COLUMN `user_id` bigint UNSIGNED NOT NULL;
+
+ALTER TABLE `organizations` MODIFY COLUMN `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT;
+ALTER TABLE `organization_members`
+    MODIFY COLUMN `organization_id` bigint UNSIGNED NOT NULL,
+    MODIFY COLUMN `user_id` bigint UNSIGNED NOT NULL;
+ALTER TABLE `organization_invitations`
+    MODIFY COLUMN `organization_id` bigint UNSIGNED NOT NULL,
+    MODIFY COLUMN `invited_by` bigint UNSIGNED NOT NULL;
+
+ALTER TABLE `invoices`
+    MODIFY COLUMN `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+    MODIFY COLUMN `organization_id` bigint UNSIGNED NOT NULL,
+    MODIFY COLUMN `user_id` bigint UNSIGNED NOT NULL;
+
+ALTER TABLE `transactions`
+    MODIFY COLUMN `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+    MODIFY COLUMN `organization_id` bigint UNSIGNED NOT NULL,
+    MODIFY COLUMN `user_id` bigint UNSIGNED NOT NULL,
+    MODIFY COLUMN `invoice_id` bigint UNSIGNED NOT NULL;
@@ -0,0 +1,13 @@
+-- Domain events waiting to be published by the outbox relay, written in the
+-- same transaction as the change they record. The sequence orders events as
+-- they were written, and the ID is the dedupe ID given to consumers.
+CREATE TABLE `outbox_events` (
+    `sequence` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+    `id` char(36) NOT NULL,
+    `type` varchar(64) NOT NULL,
+    `version` int UNSIGNED NOT NULL,
+    `payload` json NOT NULL,
+    `created_at` datetime NOT NULL,
+    PRIMARY KEY (`sequence`),
+    UNIQUE KEY `id` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,7 @@
+ALTER TABLE `outbox_events` DROP KEY `claim_id`;
+ALTER TABLE `outbox_events`
+    DROP COLUMN `claim_id`,
+    DROP COLUMN `available_at`,
+    DROP COLUMN `attempts`,
+    DROP COLUMN `last_error`,
+    DROP COLUMN `dead_at`;
@@ -0,0 +1,11 @@
+-- Claims and retries of outbox events, so several relays can share the
+-- outbox. A relay claims events by setting claim_id, and available_at to
+-- when its lease ends. A failed event is released with available_at set to
+-- when it is tried again, and dead_at is set once it is given up on.
+ALTER TABLE `outbox_events`
+    ADD COLUMN `claim_id` char(36) NULL,
+    ADD COLUMN `available_at` datetime NULL,
+    ADD COLUMN `attempts` int UNSIGNED NOT NULL DEFAULT 0,
+    ADD COLUMN `last_error` text NULL,
+    ADD COLUMN `dead_at` datetime NULL;
+ALTER TABLE `outbox_events` ADD KEY `claim_id` (`claim_id`);
@@ -0,0 +1,17 @@
+DROP TABLE transactions;
+DROP TABLE invoices;
+DROP TABLE organization_invitations;
+DROP TABLE organization_members;
+DROP TABLE organizations;
+DROP TABLE refresh_tokens;
+DROP TABLE sessions;
+DROP TABLE login_attempts;
+DROP TABLE recovery_codes;
+DROP TABLE user_tokens;
+DROP TABLE users;
+
+DROP TYPE transaction_status;
+DROP TYPE transaction_type;
+DROP TYPE invoice_status;
+DROP TYPE organization_role;
+DROP TYPE user_token_type;
@@ -0,0 +1,155 @@
+-- The Postgres schema, matching the MySQL schema after 0002_database_ids.
+--
+-- Postgres has no unsigned types, so IDs and amounts are bigints checked to
+-- not be negative. Datetimes are timestamps without a time zone holding UTC,
+-- rounded to the second like MySQL datetimes. Fixed length MySQL chars are
+-- varchars, as Postgres keeps the padding of shorter values.
+
+CREATE TYPE user_token_type AS ENUM ('password_reset', 'email_verification', 'two_factor_challenge');
+CREATE TYPE organization_role AS ENUM ('owner', 'admin', 'accountant', 'read_only');
+CREATE TYPE invoice_status AS ENUM ('pending', 'paid', 'past_due');
+CREATE TYPE transaction_type AS ENUM ('authorize', 'capture', 'sale', 'void', 'refund');
+CREATE TYPE transaction_status AS ENUM ('approved', 'declined');
+
+CREATE TABLE users (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY CHECK (id > 0),
+    email varchar(255) NOT NULL,
+    password varchar(60) NOT NULL,
+    email_verified_at timestamp(0) DEFAULT NULL,
+    totp_secret varchar(64) DEFAULT NULL,
+    totp_enabled_at timestamp(0) DEFAULT NULL,
+    totp_last_step bigint DEFAULT NULL CHECK (totp_last_step >= 0)
+);
+
+-- Emails are matched ignoring case, like the MySQL collation.
+CREATE INDEX users_email ON users (lower(email));
+
+CREATE TABLE user_tokens (
+    hash varchar(64) NOT NULL PRIMARY KEY,
+    user_id bigint NOT NULL,
+    type user_token_type NOT NULL,
+    expires_at timestamp(0) NOT NULL,
+    created_at timestamp(0) NOT NULL
+);
+
+CREATE INDEX user_tokens_user_id_type ON user_tokens (user_id, type);
+
+CREATE TABLE recovery_codes (
+    hash varchar(64) NOT NULL PRIMARY KEY,
+    user_id bigint NOT NULL,
+    created_at timestamp(0) NOT NULL
+);
+
+CREATE INDEX recovery_codes_user_id ON recovery_codes (user_id);
+
+CREATE TABLE login_attempts (
+    attempt_key varchar(255) NOT NULL PRIMARY KEY,
+    failures bigint NOT NULL CHECK (failures >= 0),
+    last_failed_at timestamp(0) NOT NULL
+);
+
+CREATE TABLE sessions (
+    id varchar(36) NOT NULL PRIMARY KEY,
+    user_id bigint NOT NULL,
+    user_agent varchar(255) NOT NULL,
+    ip_address varchar(45) NOT NULL,
+    created_at timestamp(0) NOT NULL,
+    last_used_at timestamp(0) NOT NULL,
+    expires_at timestamp(0) NOT NULL
+);
+
+CREATE INDEX sessions_user_id ON sessions (user_id);
+
+CREATE TABLE refresh_tokens (
+    hash varchar(64) NOT NULL PRIMARY KEY,
+    session_id varchar(36) NOT NULL,
+    used_at timestamp(0) DEFAULT NULL,
+    created_at timestamp(0) NOT NULL
+);
+
+CREATE INDEX refresh_tokens_session_id ON refresh_tokens (session_id);
+
+CREATE TABLE organizations (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY CHECK (id > 0),
+    name varchar(255) NOT NULL,
+    created_at timestamp(0) NOT NULL
+);
+
+CREATE TABLE organization_members (
+    organization_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    role organization_role NOT NULL,
+    created_at timestamp(0) NOT NULL,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX organization_members_user_id ON organization_members (user_id);
+
+CREATE TABLE organization_invitations (
+    hash varchar(64) NOT NULL PRIMARY KEY,
+    organization_id bigint NOT NULL,
+    email varchar(255) NOT NULL,
+    role organization_role NOT NULL,
+    invited_by bigint NOT NULL,
+    expires_at timestamp(0) NOT NULL,
+    created_at timestamp(0) NOT NULL
+);
+
+CREATE INDEX organization_invitations_organization_id ON organization_invitations (organization_id);
+
+CREATE TABLE invoices (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY CHECK (id > 0),
+    organization_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    public_hash varchar(36) NOT NULL,
+    invoice_number varchar(50) NOT NULL,
+    po_number varchar(50) NOT NULL,
+    currency varchar(3) NOT NULL,
+    due_date date NOT NULL,
+    message varchar(255) NOT NULL,
+    bill_to_first_name varchar(255) NOT NULL,
+    bill_to_last_name varchar(255) NOT NULL,
+    bill_to_company varchar(255) NOT NULL,
+    bill_to_address_line_1 varchar(255) NOT NULL,
+    bill_to_address_line_2 varchar(255) NOT NULL,
+    bill_to_city varchar(255) NOT NULL,
+    bill_to_state varchar(3) NOT NULL,
+    bill_to_postal_code varchar(12) NOT NULL,
+    bill_to_country varchar(2) NOT NULL,
+    bill_to_email varchar(255) NOT NULL,
+    bill_to_phone varchar(15) NOT NULL,
+    pay_to_first_name varchar(255) NOT NULL,
+    pay_to_last_name varchar(255) NOT NULL,
+    pay_to_company varchar(255) NOT NULL,
+    pay_to_address_line_1 varchar(255) NOT NULL,
+    pay_to_address_line_2 varchar(255) NOT NULL,
+    pay_to_city varchar(255) NOT NULL,
+    pay_to_state varchar(3) NOT NULL,
+    pay_to_postal_code varchar(12) NOT NULL,
+    pay_to_country varchar(2) NOT NULL,
+    pay_to_email varchar(255) NOT NULL,
+    pay_to_phone varchar(15) NOT NULL,
+    line_items jsonb DEFAULT NULL,
+    payment_methods jsonb DEFAULT NULL,
+    tax_rate varchar(10) NOT NULL,
+    amount_due bigint NOT NULL CHECK (amount_due >= 0),
+    amount_paid bigint NOT NULL CHECK (amount_paid >= 0),
+    status invoice_status NOT NULL,
+    created_at timestamp(0) NOT NULL
+);
+
+CREATE INDEX invoices_organization_id_created_at_id ON invoices (organization_id, created_at, id);
+
+CREATE TABLE transactions (
+    id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY CHECK (id > 0),
+    organization_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    type transaction_type NOT NULL,
+    card_type varchar(255) NOT NULL,
+    amount_captured bigint NOT NULL CHECK (amount_captured >= 0),
+    invoice_id bigint NOT NULL,
+    status transaction_status NOT NULL,
+    created_at timestamp(0) NOT NULL
+);
+
+CREATE INDEX transactions_organization_id_created_at ON transactions (organization_id, created_at);
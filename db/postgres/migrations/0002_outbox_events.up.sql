@@ -0,0 +1,10 @@
+-- Domain events waiting to be published by the outbox relay, matching the
+-- MySQL 0003_outbox_events migration.
+CREATE TABLE outbox_events (
+    sequence bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    id varchar(36) NOT NULL UNIQUE,
+    type varchar(64) NOT NULL,
+    version integer NOT NULL CHECK (version > 0),
+    payload jsonb NOT NULL,
+    created_at timestamp(0) NOT NULL
+);
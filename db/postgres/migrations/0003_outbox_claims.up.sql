@@ -0,0 +1,10 @@
+-- Claims and retries of outbox events, matching the MySQL 0004_outbox_claims
+-- migration.
+ALTER TABLE outbox_events
+    ADD COLUMN claim_id varchar(36) NULL,
+    ADD COLUMN available_at timestamp(0) NULL,
+    ADD COLUMN attempts integer NOT NULL DEFAULT 0,
+    ADD COLUMN last_error text NULL,
+    ADD COLUMN dead_at timestamp(0) NULL;
+
+CREATE INDEX outbox_events_claim_id ON outbox_events (claim_id);
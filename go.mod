@@ -5,6 +5,8 @@ go 1.18
 require (
 	github.com/beeker1121/httprouter v0.0.0-20160817010721-ee8b3818a7f5
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.14
 	golang.org/x/crypto v0.29.0
 )
 
@@ -15,6 +17,7 @@ require (
 	github.com/Masterminds/sprig/v3 v3.2.2 // indirect
 	github.com/beeker1121/creek v1.0.0 // indirect
 	github.com/friendsofgo/errors v0.9.2 // indirect
+	github.com/ericlagergren/decimal v0.0.0-20190420051523-6335edbaa640 // indirect
 	github.com/fsnotify/fsnotify v1.5.4 // indirect
 	github.com/go-sql-driver/mysql v1.8.1 // indirect
 	github.com/gofrs/uuid v4.2.0+incompatible // indirect
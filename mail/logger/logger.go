@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"log/slog"
+
+	"dddstructure/mail"
+)
+
+// Sender defines the sender.
+type Sender struct {
+	logger *slog.Logger
+}
+
+// New creates a new sender that writes mail to the given logger instead of
+// delivering it, for use in development.
+func New(l *slog.Logger) *Sender {
+	return &Sender{
+		logger: l,
+	}
+}
+
+// Send implements the mail.Sender interface.
+func (s *Sender) Send(m *mail.Message) error {
+	s.logger.Info("mail sent",
+		slog.String("to", m.To),
+		slog.String("subject", m.Subject),
+		slog.String("body", m.Body))
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package mail
+
+// Sender defines a mail sender.
+//
+// Services send mail through this interface, so the actual delivery method
+// can be swapped out, for example for SMTP in production and a mock in tests.
+type Sender interface {
+	Send(m *Message) error
+}
+
+// Message defines a plain text mail message.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
@@ -0,0 +1,52 @@
+package mock
+
+import (
+	"sync"
+
+	"dddstructure/mail"
+)
+
+// Sender defines the sender.
+type Sender struct {
+	mu       sync.Mutex
+	messages []*mail.Message
+}
+
+// New creates a new sender that keeps every message sent, so tests can check
+// what was sent.
+func New() *Sender {
+	return &Sender{}
+}
+
+// Send implements the mail.Sender interface.
+func (s *Sender) Send(m *mail.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, m)
+
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first.
+func (s *Sender) Messages() []*mail.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*mail.Message{}, s.messages...)
+}
+
+// Last returns the last message sent to the given address, or nil if none
+// were sent to it.
+func (s *Sender) Last(to string) *mail.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for n := len(s.messages) - 1; n >= 0; n-- {
+		if s.messages[n].To == to {
+			return s.messages[n]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package smtp
+
+import (
+	"net"
+	"net/smtp"
+	"strings"
+
+	"dddstructure/mail"
+)
+
+// headerReplacer removes line breaks from header values.
+var headerReplacer = strings.NewReplacer("\r", "", "\n", "")
+
+// Sender defines the sender.
+type Sender struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// New creates a new sender that sends mail through the given SMTP server.
+//
+// If the username is empty, mail is sent without authentication.
+func New(host, port, username, password, from string) *Sender {
+	s := &Sender{
+		addr: net.JoinHostPort(host, port),
+		from: from,
+	}
+
+	if username != "" {
+		s.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return s
+}
+
+// Send implements the mail.Sender interface.
+func (s *Sender) Send(m *mail.Message) error {
+	// Build the message, using CRLF line endings as required by SMTP. Line
+	// breaks are removed from header values so they can't add headers.
+	var b strings.Builder
+	b.WriteString("From: " + headerReplacer.Replace(s.from) + "\r\n")
+	b.WriteString("To: " + headerReplacer.Replace(m.To) + "\r\n")
+	b.WriteString("Subject: " + headerReplacer.Replace(m.Subject) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{m.To}, []byte(b.String()))
+}
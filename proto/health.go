@@ -0,0 +1,23 @@
+package proto
+
+// HealthStatus defines the status of a dependency.
+type HealthStatus string
+
+const (
+	HealthStatusUp   HealthStatus = "up"
+	HealthStatusDown HealthStatus = "down"
+)
+
+// HealthCheck defines the status of a single dependency.
+type HealthCheck struct {
+	Name   string
+	Status HealthStatus
+	Error  string
+}
+
+// Health defines the status of every dependency. The status is only up if
+// every dependency is up.
+type Health struct {
+	Status HealthStatus
+	Checks []*HealthCheck
+}
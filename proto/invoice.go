@@ -49,8 +49,12 @@ type InvoiceLineItem struct {
 }
 
 // Invoice defines an invoice.
+//
+// Invoices are owned by an organization. UserID is the member who created
+// the invoice.
 type Invoice struct {
 	ID             uint
+	OrganizationID uint
 	UserID         uint
 	PublicHash     string
 	InvoiceNumber  string
@@ -72,6 +76,7 @@ type Invoice struct {
 // InvoiceCreateParams defines the invoice create parameters.
 type InvoiceCreateParams struct {
 	ID             uint
+	OrganizationID uint
 	UserID         uint
 	InvoiceNumber  string
 	PONumber       string
@@ -91,14 +96,26 @@ type InvoiceGetParamsCreatedAt struct {
 	EndDate   *time.Time
 }
 
+// InvoiceGetParamsCursor defines a keyset pagination cursor.
+//
+// Invoices are ordered by created at datetime and ID, newest first. The
+// cursor points at the invoice to page from, and Before determines if the
+// invoices before (newer) or after (older) it are returned.
+type InvoiceGetParamsCursor struct {
+	CreatedAt time.Time
+	ID        uint
+	Before    bool
+}
+
 // InvoiceGetParams defines the invoice get parameters.
 type InvoiceGetParams struct {
-	ID        *uint
-	UserID    *uint
-	Status    *string
-	CreatedAt *InvoiceGetParamsCreatedAt
-	Offset    uint
-	Limit     uint
+	ID             *uint
+	OrganizationID *uint
+	Status         *string
+	CreatedAt      *InvoiceGetParamsCreatedAt
+	Cursor         *InvoiceGetParamsCursor
+	Offset         uint
+	Limit          uint
 }
 
 // InvoiceBillToUpdate defines the invoice billing information for update.
@@ -134,7 +151,7 @@ type InvoicePayToUpdate struct {
 // InvoiceUpdateParams defines the invoice update parameters.
 type InvoiceUpdateParams struct {
 	ID             *uint
-	UserID         *uint
+	OrganizationID *uint
 	InvoiceNumber  *string
 	PONumber       *string
 	Currency       *string
@@ -160,3 +177,21 @@ type InvoiceUpdateForTransactionParams struct {
 type InvoicePayParams struct {
 	Amount uint
 }
+
+// InvoiceImportParams defines the invoice import parameters.
+type InvoiceImportParams struct {
+	OrganizationID uint
+	UserID         uint
+	Invoices       []*InvoiceCreateParams
+	DryRun         bool
+	AllOrNothing   bool
+}
+
+// InvoiceImportResult defines the result of importing a single invoice.
+//
+// Invoice is only set if the invoice was created, and Error is set if the
+// invoice was invalid or could not be created.
+type InvoiceImportResult struct {
+	Invoice *Invoice
+	Error   error
+}
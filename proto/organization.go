@@ -0,0 +1,102 @@
+package proto
+
+import "time"
+
+// OrganizationRole defines the role of a member in an organization.
+type OrganizationRole string
+
+const (
+	OrganizationRoleOwner      OrganizationRole = "owner"
+	OrganizationRoleAdmin      OrganizationRole = "admin"
+	OrganizationRoleAccountant OrganizationRole = "accountant"
+	OrganizationRoleReadOnly   OrganizationRole = "read_only"
+)
+
+// OrganizationPermission defines a permission granted to members of an
+// organization by their role.
+type OrganizationPermission string
+
+const (
+	OrganizationPermissionRead         OrganizationPermission = "organization.read"
+	OrganizationPermissionMembersWrite OrganizationPermission = "organization.members.write"
+	OrganizationPermissionInvoiceRead  OrganizationPermission = "invoice.read"
+	OrganizationPermissionInvoiceWrite OrganizationPermission = "invoice.write"
+	OrganizationPermissionTransaction  OrganizationPermission = "transaction.write"
+	OrganizationPermissionReportRead   OrganizationPermission = "report.read"
+)
+
+// Organization defines an organization.
+//
+// Role is the role of the user the organization was fetched for, and is only
+// set by GetForUser.
+type Organization struct {
+	ID        uint
+	Name      string
+	CreatedAt time.Time
+	Role      OrganizationRole
+}
+
+// OrganizationMember defines a member of an organization.
+type OrganizationMember struct {
+	OrganizationID uint
+	UserID         uint
+	Email          string
+	Role           OrganizationRole
+	CreatedAt      time.Time
+}
+
+// OrganizationCreateParams defines the organization create parameters.
+//
+// UserID is the user creating the organization, who becomes its owner.
+type OrganizationCreateParams struct {
+	ID     uint
+	Name   string
+	UserID uint
+}
+
+// OrganizationAuthorizeParams defines the organization authorize parameters.
+type OrganizationAuthorizeParams struct {
+	OrganizationID uint
+	UserID         uint
+	Permission     OrganizationPermission
+}
+
+// OrganizationUpdateMemberParams defines the organization update member
+// parameters.
+//
+// ByUserID is the member making the change.
+type OrganizationUpdateMemberParams struct {
+	OrganizationID uint
+	UserID         uint
+	Role           OrganizationRole
+	ByUserID       uint
+}
+
+// OrganizationRemoveMemberParams defines the organization remove member
+// parameters.
+//
+// ByUserID is the member making the change.
+type OrganizationRemoveMemberParams struct {
+	OrganizationID uint
+	UserID         uint
+	ByUserID       uint
+}
+
+// OrganizationInviteParams defines the organization invite parameters.
+//
+// Link is where the invited user accepts the invitation, and gets the
+// invitation token added as the token query parameter.
+type OrganizationInviteParams struct {
+	OrganizationID uint
+	Email          string
+	Role           OrganizationRole
+	ByUserID       uint
+	Link           string
+}
+
+// OrganizationAcceptInvitationParams defines the organization accept
+// invitation parameters.
+type OrganizationAcceptInvitationParams struct {
+	Token  string
+	UserID uint
+}
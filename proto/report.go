@@ -0,0 +1,79 @@
+package proto
+
+import "time"
+
+// ReportRevenueInterval defines a revenue report interval.
+type ReportRevenueInterval string
+
+const (
+	ReportRevenueIntervalDay   ReportRevenueInterval = "day"
+	ReportRevenueIntervalWeek  ReportRevenueInterval = "week"
+	ReportRevenueIntervalMonth ReportRevenueInterval = "month"
+)
+
+// ReportAgingBucketName defines the name of an aging bucket.
+type ReportAgingBucketName string
+
+const (
+	ReportAgingBucketCurrent ReportAgingBucketName = "current"
+	ReportAgingBucket1To30   ReportAgingBucketName = "1_30"
+	ReportAgingBucket31To60  ReportAgingBucketName = "31_60"
+	ReportAgingBucket61To90  ReportAgingBucketName = "61_90"
+	ReportAgingBucket90Plus  ReportAgingBucketName = "90_plus"
+)
+
+// ReportAgingParams defines the aging report parameters.
+type ReportAgingParams struct {
+	OrganizationID uint
+	AsOf           time.Time
+}
+
+// ReportAgingBucket defines the unpaid invoices in a single aging bucket.
+type ReportAgingBucket struct {
+	Name      ReportAgingBucketName
+	Count     uint
+	AmountDue uint
+}
+
+// ReportAging defines the accounts receivable aging of a single currency.
+type ReportAging struct {
+	Currency  string
+	Buckets   []ReportAgingBucket
+	Count     uint
+	AmountDue uint
+}
+
+// ReportRevenueParams defines the revenue report parameters.
+type ReportRevenueParams struct {
+	OrganizationID uint
+	Interval       ReportRevenueInterval
+	StartDate      *time.Time
+	EndDate        *time.Time
+}
+
+// ReportRevenue defines the revenue of a single currency within a single
+// period.
+type ReportRevenue struct {
+	Period    time.Time
+	Currency  string
+	Count     uint
+	Collected uint
+	Refunded  uint
+	Net       int
+}
+
+// ReportBalancesParams defines the balances report parameters.
+type ReportBalancesParams struct {
+	OrganizationID uint
+	StartDate      *time.Time
+	EndDate        *time.Time
+}
+
+// ReportBalance defines the outstanding and collected totals of a single
+// currency.
+type ReportBalance struct {
+	Currency    string
+	Count       uint
+	Outstanding uint
+	Collected   uint
+}
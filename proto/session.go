@@ -0,0 +1,39 @@
+package proto
+
+import "time"
+
+// Session defines a login session.
+//
+// RefreshToken is only set when a session is created or refreshed, since only
+// the hash of the token is stored.
+type Session struct {
+	ID           string
+	UserID       uint
+	UserAgent    string
+	IPAddress    string
+	CreatedAt    time.Time
+	LastUsedAt   time.Time
+	ExpiresAt    time.Time
+	RefreshToken string
+}
+
+// SessionCreateParams defines the session create parameters.
+//
+// Expiry is how long the session lasts without being refreshed.
+type SessionCreateParams struct {
+	UserID    uint
+	UserAgent string
+	IPAddress string
+	Expiry    time.Duration
+}
+
+// SessionRefreshParams defines the session refresh parameters.
+//
+// Expiry is how long the session lasts from now without being refreshed
+// again.
+type SessionRefreshParams struct {
+	RefreshToken string
+	UserAgent    string
+	IPAddress    string
+	Expiry       time.Duration
+}
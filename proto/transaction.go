@@ -1,14 +1,18 @@
 package proto
 
+import "time"
+
 // Transaction defines a transaction.
 type Transaction struct {
 	ID             uint
+	OrganizationID uint
 	UserID         uint
 	Type           string
 	CardType       string
 	AmountCaptured uint
 	InvoiceID      uint
 	Status         string
+	CreatedAt      time.Time
 }
 
 // TransactionPaymentMethodCard defines the card payment method.
@@ -24,10 +28,11 @@ type TransactionPaymentMethod struct {
 
 // TransactionProcessParams defines the transaction process parameters.
 type TransactionProcessParams struct {
-	ID            uint
-	UserID        uint
-	Type          string
-	Amount        uint
-	PaymentMethod TransactionPaymentMethod
-	InvoiceID     uint
+	ID             uint
+	OrganizationID uint
+	UserID         uint
+	Type           string
+	Amount         uint
+	PaymentMethod  TransactionPaymentMethod
+	InvoiceID      uint
 }
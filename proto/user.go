@@ -1,10 +1,17 @@
 package proto
 
+import "time"
+
 // User defines a user.
+//
+// TwoFactorEnabledAt is set once TOTP two-factor authentication has been
+// confirmed, after which logging in needs a code as well as the password.
 type User struct {
-	ID       uint
-	Email    string
-	Password string
+	ID                 uint
+	Email              string
+	Password           string
+	EmailVerifiedAt    *time.Time
+	TwoFactorEnabledAt *time.Time
 }
 
 // UserCreateParams defines the user create parameters.
@@ -15,9 +22,13 @@ type UserCreateParams struct {
 }
 
 // UserLoginParams defines the user login parameters.
+//
+// IPAddress is the address of the client logging in, used to count failed
+// attempts per client as well as per email.
 type UserLoginParams struct {
-	Email    string
-	Password string
+	Email     string
+	Password  string
+	IPAddress string
 }
 
 // UserUpdateParams defines the user update parameters.
@@ -26,3 +37,85 @@ type UserUpdateParams struct {
 	Email    *string
 	Password *string
 }
+
+// UserForgotPasswordParams defines the user forgot password parameters.
+//
+// Link is where the user resets their password, and gets the reset token
+// added as the token query parameter.
+type UserForgotPasswordParams struct {
+	Email string
+	Link  string
+}
+
+// UserResetPasswordParams defines the user reset password parameters.
+type UserResetPasswordParams struct {
+	Token    string
+	Password string
+}
+
+// UserSendEmailVerificationParams defines the user send email verification
+// parameters.
+//
+// Link is where the user verifies their email, and gets the verification
+// token added as the token query parameter.
+type UserSendEmailVerificationParams struct {
+	ID   uint
+	Link string
+}
+
+// UserVerifyEmailParams defines the user verify email parameters.
+type UserVerifyEmailParams struct {
+	Token string
+}
+
+// UserLoginChallenge defines a login challenge, handed out instead of a
+// session when a user with two-factor authentication enabled logs in.
+type UserLoginChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// UserLoginTwoFactorParams defines the user login two-factor parameters.
+//
+// Code is either a TOTP code or an unused recovery code.
+type UserLoginTwoFactorParams struct {
+	ChallengeToken string
+	Code           string
+}
+
+// UserEnrollTwoFactorParams defines the user enroll two-factor parameters.
+//
+// Issuer is the name authenticator apps show the account under.
+type UserEnrollTwoFactorParams struct {
+	ID     uint
+	Issuer string
+}
+
+// UserTwoFactorEnrollment defines the TOTP secret handed out on enrollment,
+// along with its otpauth:// provisioning URI.
+type UserTwoFactorEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// UserConfirmTwoFactorParams defines the user confirm two-factor parameters.
+type UserConfirmTwoFactorParams struct {
+	ID   uint
+	Code string
+}
+
+// UserDisableTwoFactorParams defines the user disable two-factor parameters.
+//
+// Code is either a TOTP code or an unused recovery code.
+type UserDisableTwoFactorParams struct {
+	ID       uint
+	Password string
+	Code     string
+}
+
+// UserRegenerateRecoveryCodesParams defines the user regenerate recovery
+// codes parameters.
+type UserRegenerateRecoveryCodesParams struct {
+	ID   uint
+	Code string
+}
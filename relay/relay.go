@@ -0,0 +1,251 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"dddstructure/storage/outbox"
+)
+
+const (
+	// pollInterval defines how often the outbox is checked for pending
+	// events.
+	pollInterval = time.Second
+
+	// batchSize defines the number of pending events claimed from the
+	// outbox at a time.
+	batchSize = 100
+
+	// claimLease defines how long claimed events are kept from other
+	// relays, which must be longer than publishing a batch takes. Events a
+	// relay has claimed but not published by then are claimed again by
+	// another relay.
+	claimLease = 5 * time.Minute
+)
+
+// RetryPolicy defines how events the sink fails to publish are tried again.
+//
+// After each failure the event waits twice as long as after the last one,
+// starting at MinBackoff and at most MaxBackoff. Once an event has failed
+// MaxAttempts times it is dead-lettered, so it is kept in the outbox but
+// never tried again.
+type RetryPolicy struct {
+	MaxAttempts uint
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultRetryPolicy defines the retry policy used unless another one is set
+// with SetRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 20,
+	MinBackoff:  time.Second,
+	MaxBackoff:  time.Hour,
+}
+
+// Sink defines where events are published.
+//
+// An event may be published more than once, for example if the relay stops
+// after publishing an event but before deleting it from the outbox, so sinks
+// must pass the event ID on for consumers to drop events they have already
+// seen.
+type Sink interface {
+	Publish(ctx context.Context, e *outbox.Event) error
+}
+
+// Envelope defines an event as it is published, shared by every sink.
+type Envelope struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Version   uint            `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// Encode encodes an event as a JSON envelope.
+func Encode(e *outbox.Event) ([]byte, error) {
+	return json.Marshal(&Envelope{
+		ID:        e.ID,
+		Type:      e.Type,
+		Version:   e.Version,
+		CreatedAt: e.CreatedAt.UTC(),
+		Payload:   json.RawMessage(e.Payload),
+	})
+}
+
+// Relay defines a relay, which publishes the pending events of an outbox to
+// a sink.
+//
+// Events are claimed from the outbox in the order they were written, so
+// several relays can share an outbox, and each is deleted only once the sink
+// accepts it. If the sink fails, the event is tried again after a backoff
+// while the events after it are published, so a failing event delays only
+// itself, and every event is published at least once unless it is
+// dead-lettered.
+type Relay struct {
+	db     outbox.Database
+	sink   Sink
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	retry    RetryPolicy
+	quit     chan struct{}
+	done     chan struct{}
+	quitOnce sync.Once
+}
+
+// New creates a new relay publishing the events of the given outbox to the
+// given sink, polling for pending events until it is shut down. Failures to
+// publish events are logged to the given logger.
+func New(db outbox.Database, sink Sink, l *slog.Logger) *Relay {
+	r := &Relay{
+		db:     db,
+		sink:   sink,
+		logger: l,
+		retry:  DefaultRetryPolicy,
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	go r.run()
+
+	return r
+}
+
+// SetRetryPolicy sets the retry policy. Any zero fields are taken from
+// DefaultRetryPolicy.
+func (r *Relay) SetRetryPolicy(p RetryPolicy) {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.MinBackoff == 0 {
+		p.MinBackoff = DefaultRetryPolicy.MinBackoff
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.retry = p
+}
+
+// Flush publishes every pending event, returning the number published.
+//
+// Events the sink fails to publish are recorded to be tried again later, and
+// the first failure is returned once the rest of the batch is published.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	published := 0
+	for {
+		events, err := r.db.Claim(ctx, batchSize, time.Now().Add(claimLease))
+		if err != nil {
+			return published, err
+		}
+
+		var failed error
+		for _, e := range events {
+			if publishErr := r.sink.Publish(ctx, e); publishErr != nil {
+				// The claims of the remaining events end with their lease,
+				// so they are published by a later flush.
+				if ctx.Err() != nil {
+					return published, publishErr
+				}
+
+				// The lease may have ended and another relay published and
+				// deleted the event, which leaves nothing to record.
+				if err := r.fail(ctx, e, publishErr); err != nil && !errors.Is(err, outbox.ErrEventNotFound) {
+					return published, err
+				}
+				if failed == nil {
+					failed = publishErr
+				}
+				continue
+			}
+			published++
+
+			// The lease may have ended and another relay published and
+			// deleted the event, which is fine as consumers drop duplicates.
+			if err := r.db.Delete(ctx, e.ID); err != nil && !errors.Is(err, outbox.ErrEventNotFound) {
+				return published, err
+			}
+		}
+
+		if failed != nil {
+			return published, failed
+		}
+		if len(events) < batchSize {
+			return published, nil
+		}
+	}
+}
+
+// fail records a failed attempt to publish an event, which is tried again
+// after its backoff, or dead-lettered once it has failed too many times.
+func (r *Relay) fail(ctx context.Context, e *outbox.Event, publishErr error) error {
+	attempts := e.Attempts + 1
+	if attempts >= r.retry.MaxAttempts {
+		r.logger.Error("outbox event dead-lettered",
+			slog.String("id", e.ID),
+			slog.String("type", e.Type),
+			slog.Uint64("attempts", uint64(attempts)),
+			slog.Any("error", publishErr))
+
+		return r.db.DeadLetter(ctx, e.ID, publishErr.Error())
+	}
+
+	backoff := r.retry.MinBackoff
+	for n := uint(1); n < attempts && backoff < r.retry.MaxBackoff; n++ {
+		backoff *= 2
+	}
+	if backoff > r.retry.MaxBackoff {
+		backoff = r.retry.MaxBackoff
+	}
+
+	return r.db.Fail(ctx, e.ID, publishErr.Error(), time.Now().Add(backoff))
+}
+
+// Shutdown publishes the pending events and stops the relay, waiting until
+// the given context is done at most.
+func (r *Relay) Shutdown(ctx context.Context) error {
+	r.quitOnce.Do(func() {
+		close(r.quit)
+	})
+
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	_, err := r.Flush(ctx)
+	return err
+}
+
+// run publishes the pending events on every poll interval, until the relay
+// is shut down.
+func (r *Relay) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := r.Flush(context.Background()); err != nil {
+				r.logger.Error("r.Flush() error",
+					slog.Any("error", err))
+			}
+		case <-r.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"dddstructure/relay"
+	"dddstructure/storage/outbox"
+)
+
+const (
+	// IdempotencyKeyHeader defines the header holding the event ID, which
+	// is the same each time an event is published.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// EventTypeHeader defines the header holding the event type, so
+	// receivers can route events without reading the body.
+	EventTypeHeader = "X-Event-Type"
+
+	// requestTimeout defines the timeout of each request to the endpoint.
+	requestTimeout = 10 * time.Second
+)
+
+// Sink defines the sink.
+type Sink struct {
+	url    string
+	client *http.Client
+}
+
+// New creates a new sink that posts each event to the given URL as a JSON
+// envelope. Any 2xx response means the event is published, and any other
+// response or error means it is posted again later.
+func New(url string) *Sink {
+	return &Sink{
+		url:    url,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Publish implements the relay.Sink interface.
+func (s *Sink) Publish(ctx context.Context, e *outbox.Event) error {
+	body, err := relay.Encode(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyKeyHeader, e.ID)
+	req.Header.Set(EventTypeHeader, e.Type)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"dddstructure/relay"
+	"dddstructure/storage/outbox"
+)
+
+// syncer defines a writer that can flush its writes to stable storage, such
+// as an *os.File.
+type syncer interface {
+	Sync() error
+}
+
+// Sink defines the sink.
+type Sink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// New creates a new sink that writes each event to the given writer as a
+// line of JSON, such as to standard output or a file opened for appending.
+//
+// If the writer is a file, each event is synced to disk before it is
+// deleted from the outbox, so events are not lost if the machine stops.
+func New(w io.Writer) *Sink {
+	return &Sink{
+		w: w,
+	}
+}
+
+// Publish implements the relay.Sink interface.
+func (s *Sink) Publish(ctx context.Context, e *outbox.Event) error {
+	b, err := relay.Encode(e)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+
+	if f, ok := s.w.(syncer); ok {
+		return f.Sync()
+	}
+
+	return nil
+}
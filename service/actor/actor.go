@@ -0,0 +1,104 @@
+package actor
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/service/interfaces"
+)
+
+// Header defines the header the actor of a call is sent in between
+// processes.
+const Header = "X-Actor"
+
+// ErrInvalid is returned when parsing an actor that is not valid.
+var ErrInvalid = errors.New("invalid actor")
+
+// Actor defines who a service method is called on behalf of, which is
+// either a user or the system itself.
+//
+// The system acts on every organization, such as when handling events or
+// paying an invoice through its public link, while a user only acts on the
+// organizations their role grants the permission to.
+type Actor struct {
+	UserID uint
+	System bool
+}
+
+// String returns the actor as it is sent between processes, which is
+// "system" or "user:" followed by the user ID.
+func (a Actor) String() string {
+	if a.System {
+		return "system"
+	}
+
+	return "user:" + strconv.FormatUint(uint64(a.UserID), 10)
+}
+
+// Parse parses an actor returned by Actor.String.
+func Parse(s string) (Actor, error) {
+	if s == "system" {
+		return Actor{System: true}, nil
+	}
+
+	id, err := strconv.ParseUint(strings.TrimPrefix(s, "user:"), 10, 64)
+	if !strings.HasPrefix(s, "user:") || err != nil || id == 0 {
+		return Actor{}, ErrInvalid
+	}
+
+	return Actor{UserID: uint(id)}, nil
+}
+
+// contextKey defines the type of the context key holding the actor.
+type contextKey struct{}
+
+// NewContext returns a copy of the context where calls are made on behalf
+// of the given actor.
+func NewContext(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, contextKey{}, a)
+}
+
+// WithUser returns a copy of the context where calls are made on behalf of
+// the given user.
+func WithUser(ctx context.Context, userID uint) context.Context {
+	return NewContext(ctx, Actor{UserID: userID})
+}
+
+// WithSystem returns a copy of the context where calls are made on behalf
+// of the system.
+func WithSystem(ctx context.Context) context.Context {
+	return NewContext(ctx, Actor{System: true})
+}
+
+// FromContext returns the actor of the given context, if set.
+func FromContext(ctx context.Context) (Actor, bool) {
+	a, ok := ctx.Value(contextKey{}).(Actor)
+	return a, ok
+}
+
+// Authorize checks the actor of the given context may act on an
+// organization with the given permission.
+//
+// The system is always allowed. A user is checked with the Authorize method
+// of the given organization service, returning its errors, and
+// ErrOrganizationForbidden is returned if there is no actor.
+func Authorize(ctx context.Context, organizations interfaces.Organization, organizationID uint, permission proto.OrganizationPermission) error {
+	a, ok := FromContext(ctx)
+	if !ok {
+		return serverrors.ErrOrganizationForbidden
+	}
+	if a.System {
+		return nil
+	}
+
+	_, err := organizations.Authorize(ctx, &proto.OrganizationAuthorizeParams{
+		OrganizationID: organizationID,
+		UserID:         a.UserID,
+		Permission:     permission,
+	})
+	return err
+}
@@ -17,6 +17,10 @@ var (
 	// method is invalid.
 	ErrInvoicePaymentMethodInvalid = errors.New("invalid payment method, must be either 'card' or 'ach'")
 
+	// ErrInvoiceTaxRateInvalid is returned when the tax rate is not a valid
+	// number.
+	ErrInvoiceTaxRateInvalid = errors.New("invalid tax rate, must be a number")
+
 	// ErrInvoiceAmountDueLimit is returned when the invoice amount due is over
 	// the max limit.
 	ErrInvoiceAmountDueLimit = errors.New("amount due is over limit")
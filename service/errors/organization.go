@@ -0,0 +1,35 @@
+package errors
+
+import "errors"
+
+var (
+	// ErrOrganizationNotFound is returned when an organization could not be
+	// found, or the user is not a member of it.
+	ErrOrganizationNotFound = errors.New("organization not found")
+
+	// ErrOrganizationNameEmpty is returned when the name param is empty.
+	ErrOrganizationNameEmpty = errors.New("name parameter is empty")
+
+	// ErrOrganizationForbidden is returned when the role of a member does not
+	// allow what they are trying to do.
+	ErrOrganizationForbidden = errors.New("you do not have permission to do this in the organization")
+
+	// ErrOrganizationRoleInvalid is returned when the role param is invalid.
+	ErrOrganizationRoleInvalid = errors.New("invalid role, must be one of 'owner', 'admin', 'accountant' or 'read_only'")
+
+	// ErrOrganizationMemberNotFound is returned when an organization member
+	// could not be found.
+	ErrOrganizationMemberNotFound = errors.New("organization member not found")
+
+	// ErrOrganizationMemberExists is returned when a user is already a member
+	// of the organization.
+	ErrOrganizationMemberExists = errors.New("user is already a member of the organization")
+
+	// ErrOrganizationLastOwner is returned when a change would leave an
+	// organization without an owner.
+	ErrOrganizationLastOwner = errors.New("an organization must have at least one owner")
+
+	// ErrOrganizationInvitationInvalid is returned when an invitation does
+	// not exist, was already used, has expired, or was sent to another email.
+	ErrOrganizationInvitationInvalid = errors.New("invitation is invalid or has expired")
+)
@@ -0,0 +1,13 @@
+package errors
+
+import "errors"
+
+var (
+	// ErrReportIntervalInvalid is returned when the revenue report interval
+	// is invalid.
+	ErrReportIntervalInvalid = errors.New("invalid interval, must be either 'day', 'week' or 'month'")
+
+	// ErrReportDateRangeInvalid is returned when the report start date is
+	// after the end date.
+	ErrReportDateRangeInvalid = errors.New("invalid date range, start date must be before end date")
+)
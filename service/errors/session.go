@@ -0,0 +1,21 @@
+package errors
+
+import "errors"
+
+var (
+	// ErrSessionNotFound is returned when a session could not be found.
+	ErrSessionNotFound = errors.New("session not found")
+
+	// ErrSessionRefreshTokenEmpty is returned when the refresh token param is
+	// empty.
+	ErrSessionRefreshTokenEmpty = errors.New("refresh token parameter is empty")
+
+	// ErrSessionRefreshTokenInvalid is returned when a refresh token does not
+	// exist, or its session has expired or was revoked.
+	ErrSessionRefreshTokenInvalid = errors.New("refresh token is invalid or has expired")
+
+	// ErrSessionRefreshTokenReused is returned when a refresh token that was
+	// already exchanged is used again. The session is revoked when this
+	// happens, since the token has likely been stolen.
+	ErrSessionRefreshTokenReused = errors.New("refresh token was already used, session revoked")
+)
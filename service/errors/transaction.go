@@ -10,4 +10,8 @@ var (
 	// ErrTransactionAmountLimit is returned when the transaction amount is
 	// over the max limit.
 	ErrTransactionAmountLimit = errors.New("transaction amount is over limit")
+
+	// ErrTransactionRefundAmountPaid is returned when a refund is for more
+	// than the amount paid on its invoice.
+	ErrTransactionRefundAmountPaid = errors.New("refund amount is over the amount paid on the invoice")
 )
@@ -18,4 +18,36 @@ var (
 	// ErrUserInvalidLogin is returned when the email and/or password used with
 	// login is invalid.
 	ErrUserInvalidLogin = errors.New("email and/or password is invalid")
+
+	// ErrUserLoginLocked is returned when there have been too many failed
+	// login attempts for the email or client. It is returned whether or not
+	// the email belongs to a user.
+	ErrUserLoginLocked = errors.New("too many failed login attempts, try again later")
+
+	// ErrUserTokenInvalid is returned when a password reset or email
+	// verification token does not exist, was already used, or has expired.
+	ErrUserTokenInvalid = errors.New("token is invalid or has expired")
+
+	// ErrUserPasswordIncorrect is returned when the password param does not
+	// match the password of the user.
+	ErrUserPasswordIncorrect = errors.New("password is incorrect")
+
+	// ErrUserTwoFactorEnabled is returned when enrolling in two-factor
+	// authentication while it is already enabled.
+	ErrUserTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+
+	// ErrUserTwoFactorNotEnabled is returned when two-factor authentication
+	// needs to be enabled, or at least enrolled in, but is not.
+	ErrUserTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+
+	// ErrUserTwoFactorCodeEmpty is returned when the code param is empty.
+	ErrUserTwoFactorCodeEmpty = errors.New("code parameter is empty")
+
+	// ErrUserTwoFactorCodeInvalid is returned when a TOTP or recovery code is
+	// wrong, was already used, or has expired.
+	ErrUserTwoFactorCodeInvalid = errors.New("two-factor authentication code is invalid")
+
+	// ErrUserTwoFactorChallengeInvalid is returned when a login challenge
+	// token does not exist, was already used, or has expired.
+	ErrUserTwoFactorChallengeInvalid = errors.New("login challenge is invalid or has expired")
 )
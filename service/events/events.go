@@ -0,0 +1,72 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"dddstructure/proto"
+)
+
+// Bus defines the event bus, with a topic for each type of event.
+//
+// Services publish events about their own domain, and other services
+// subscribe to them, so a service reacts to another without either calling
+// the other. Handlers run synchronously in the order they subscribed, after
+// the change the event records is saved, and an error from a handler is
+// returned to the publisher. As the change can't be undone by then, the
+// publisher logs the error rather than failing its own caller.
+type Bus struct {
+	TransactionApproved Topic[*TransactionApproved]
+	RefundIssued        Topic[*RefundIssued]
+}
+
+// New creates a new event bus with no subscribers.
+func New() *Bus {
+	return &Bus{}
+}
+
+// TransactionApproved is published once an approved transaction is saved,
+// whatever its type.
+type TransactionApproved struct {
+	Transaction *proto.Transaction
+}
+
+// RefundIssued is published once an approved refund is saved, after
+// TransactionApproved.
+type RefundIssued struct {
+	Transaction *proto.Transaction
+}
+
+// Handler defines a handler of events of type T.
+type Handler[T any] func(ctx context.Context, e T) error
+
+// Topic defines a topic, which passes the events published to it to every
+// handler subscribed.
+type Topic[T any] struct {
+	mu       sync.RWMutex
+	handlers []Handler[T]
+}
+
+// Subscribe subscribes the given handler to the topic.
+func (t *Topic[T]) Subscribe(h Handler[T]) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.handlers = append(t.handlers, h)
+}
+
+// Publish passes the given event to every handler, in the order they
+// subscribed. It stops at the first handler to fail, returning its error.
+func (t *Topic[T]) Publish(ctx context.Context, e T) error {
+	t.mu.RLock()
+	handlers := t.handlers
+	t.mu.RUnlock()
+
+	for _, h := range handlers {
+		if err := h(ctx, e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
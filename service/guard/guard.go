@@ -0,0 +1,143 @@
+package guard
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"dddstructure/service/interfaces"
+)
+
+var (
+	// ErrMaxDepth is returned when a call between services would go deeper
+	// than the maximum depth.
+	ErrMaxDepth = errors.New("service call depth exceeded")
+
+	// ErrCycle is returned when a service method is called again while it
+	// is already running in the same call chain.
+	ErrCycle = errors.New("service call cycle")
+)
+
+// DefaultConfig defines the config used in tests, which allows call chains
+// well beyond the deepest one between the services today.
+var DefaultConfig = Config{
+	MaxDepth: 8,
+}
+
+// Config defines the guard config.
+type Config struct {
+	// MaxDepth is the number of calls between services allowed in a single
+	// call chain. Zero means no limit.
+	MaxDepth uint
+
+	// AllowReentry lists the methods, such as "Invoice.GetByID", that may
+	// be called again while they are already running in the call chain.
+	AllowReentry []string
+}
+
+// CallError defines the error returned when a call is stopped by the guard,
+// with the chain of calls that led to it.
+type CallError struct {
+	Err   error
+	Chain []string
+}
+
+// Error implements the error interface.
+func (e *CallError) Error() string {
+	return e.Err.Error() + ": " + strings.Join(e.Chain, " -> ")
+}
+
+// Unwrap returns ErrMaxDepth or ErrCycle.
+func (e *CallError) Unwrap() error {
+	return e.Err
+}
+
+// contextKey defines the type of the context key holding the call chain.
+type contextKey struct{}
+
+// guard defines the guard shared by the wrapped services.
+type guard struct {
+	maxDepth     uint
+	allowReentry map[string]bool
+	logger       *slog.Logger
+}
+
+// New returns a new services interface wrapping each service of the given
+// services interface, tracking the chain of calls between services in the
+// context of each call.
+//
+// A call is stopped with a *CallError, which is logged to the given logger
+// with its call chain, if it would go deeper than the max depth, or if it is
+// a method already running in the chain that is not allowed to be reentered.
+// Only calls made through the services interface are tracked, so the first
+// method called directly, such as by an API handler, is not in the chain.
+//
+// The health service is not wrapped, as its Check method can not return an
+// error and calls no other service.
+func New(s *interfaces.Service, cfg Config, l *slog.Logger) *interfaces.Service {
+	g := &guard{
+		maxDepth:     cfg.MaxDepth,
+		allowReentry: make(map[string]bool),
+		logger:       l,
+	}
+	for _, m := range cfg.AllowReentry {
+		g.allowReentry[m] = true
+	}
+
+	return interfaces.NewService(interfaces.NewServiceParams{
+		User:         &userService{next: s.User, g: g},
+		Invoice:      &invoiceService{next: s.Invoice, g: g},
+		Transaction:  &transactionService{next: s.Transaction, g: g},
+		Report:       &reportService{next: s.Report, g: g},
+		Session:      &sessionService{next: s.Session, g: g},
+		Organization: &organizationService{next: s.Organization, g: g},
+		Health:       s.Health,
+		Events:       s.Events,
+	})
+}
+
+// Chain returns the chain of calls between services in the given context,
+// oldest first.
+func Chain(ctx context.Context) []string {
+	chain, _ := ctx.Value(contextKey{}).([]string)
+	return chain
+}
+
+// enter adds the given method to the call chain of the given context,
+// returning the context to make the call with, or an error if the call is
+// not allowed.
+func (g *guard) enter(ctx context.Context, method string) (context.Context, error) {
+	parent := Chain(ctx)
+
+	// Copy the chain, so calls made one after another from the same method
+	// do not share it.
+	chain := make([]string, len(parent), len(parent)+1)
+	copy(chain, parent)
+	chain = append(chain, method)
+
+	if g.maxDepth != 0 && uint(len(chain)) > g.maxDepth {
+		return ctx, g.stop(ErrMaxDepth, chain)
+	}
+
+	if !g.allowReentry[method] {
+		for _, m := range parent {
+			if m == method {
+				return ctx, g.stop(ErrCycle, chain)
+			}
+		}
+	}
+
+	return context.WithValue(ctx, contextKey{}, chain), nil
+}
+
+// stop logs and returns the error stopping a call.
+func (g *guard) stop(err error, chain []string) error {
+	cerr := &CallError{Err: err, Chain: chain}
+
+	g.logger.Error("service call stopped",
+		slog.Any("error", err),
+		slog.String("chain", strings.Join(chain, " -> ")))
+
+	return cerr
+}
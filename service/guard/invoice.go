@@ -0,0 +1,134 @@
+package guard
+
+import (
+	"context"
+
+	"dddstructure/proto"
+	"dddstructure/service/interfaces"
+)
+
+// invoiceService guards each call to the invoice service.
+type invoiceService struct {
+	next interfaces.Invoice
+	g    *guard
+}
+
+// Create guards Create.
+func (s *invoiceService) Create(ctx context.Context, params *proto.InvoiceCreateParams) (*proto.Invoice, error) {
+	ctx, err := s.g.enter(ctx, "Invoice.Create")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.Create(ctx, params)
+}
+
+// Get guards Get.
+func (s *invoiceService) Get(ctx context.Context, params *proto.InvoiceGetParams) ([]*proto.Invoice, error) {
+	ctx, err := s.g.enter(ctx, "Invoice.Get")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.Get(ctx, params)
+}
+
+// GetCount guards GetCount.
+func (s *invoiceService) GetCount(ctx context.Context, params *proto.InvoiceGetParams) (uint, error) {
+	ctx, err := s.g.enter(ctx, "Invoice.GetCount")
+	if err != nil {
+		return 0, err
+	}
+
+	return s.next.GetCount(ctx, params)
+}
+
+// GetByID guards GetByID.
+func (s *invoiceService) GetByID(ctx context.Context, id uint) (*proto.Invoice, error) {
+	ctx, err := s.g.enter(ctx, "Invoice.GetByID")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.GetByID(ctx, id)
+}
+
+// GetByIDAndOrganizationID guards GetByIDAndOrganizationID.
+func (s *invoiceService) GetByIDAndOrganizationID(ctx context.Context, id, organizationID uint) (*proto.Invoice, error) {
+	ctx, err := s.g.enter(ctx, "Invoice.GetByIDAndOrganizationID")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.GetByIDAndOrganizationID(ctx, id, organizationID)
+}
+
+// GetByPublicHash guards GetByPublicHash.
+func (s *invoiceService) GetByPublicHash(ctx context.Context, hash string) (*proto.Invoice, error) {
+	ctx, err := s.g.enter(ctx, "Invoice.GetByPublicHash")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.GetByPublicHash(ctx, hash)
+}
+
+// Update guards Update.
+func (s *invoiceService) Update(ctx context.Context, params *proto.InvoiceUpdateParams) (*proto.Invoice, error) {
+	ctx, err := s.g.enter(ctx, "Invoice.Update")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.Update(ctx, params)
+}
+
+// UpdateForOrganization guards UpdateForOrganization.
+func (s *invoiceService) UpdateForOrganization(ctx context.Context, params *proto.InvoiceUpdateParams) (*proto.Invoice, error) {
+	ctx, err := s.g.enter(ctx, "Invoice.UpdateForOrganization")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.UpdateForOrganization(ctx, params)
+}
+
+// UpdateForTransaction guards UpdateForTransaction.
+func (s *invoiceService) UpdateForTransaction(ctx context.Context, params *proto.InvoiceUpdateForTransactionParams) (*proto.Invoice, error) {
+	ctx, err := s.g.enter(ctx, "Invoice.UpdateForTransaction")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.UpdateForTransaction(ctx, params)
+}
+
+// Delete guards Delete.
+func (s *invoiceService) Delete(ctx context.Context, id uint) error {
+	ctx, err := s.g.enter(ctx, "Invoice.Delete")
+	if err != nil {
+		return err
+	}
+
+	return s.next.Delete(ctx, id)
+}
+
+// Pay guards Pay.
+func (s *invoiceService) Pay(ctx context.Context, id uint, params *proto.InvoicePayParams) (*proto.Invoice, error) {
+	ctx, err := s.g.enter(ctx, "Invoice.Pay")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.Pay(ctx, id, params)
+}
+
+// Import guards Import.
+func (s *invoiceService) Import(ctx context.Context, params *proto.InvoiceImportParams) ([]*proto.InvoiceImportResult, error) {
+	ctx, err := s.g.enter(ctx, "Invoice.Import")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.Import(ctx, params)
+}
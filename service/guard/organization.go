@@ -0,0 +1,104 @@
+package guard
+
+import (
+	"context"
+
+	"dddstructure/proto"
+	"dddstructure/service/interfaces"
+)
+
+// organizationService guards each call to the organization service.
+type organizationService struct {
+	next interfaces.Organization
+	g    *guard
+}
+
+// Create guards Create.
+func (s *organizationService) Create(ctx context.Context, params *proto.OrganizationCreateParams) (*proto.Organization, error) {
+	ctx, err := s.g.enter(ctx, "Organization.Create")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.Create(ctx, params)
+}
+
+// GetByID guards GetByID.
+func (s *organizationService) GetByID(ctx context.Context, id uint) (*proto.Organization, error) {
+	ctx, err := s.g.enter(ctx, "Organization.GetByID")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.GetByID(ctx, id)
+}
+
+// GetForUser guards GetForUser.
+func (s *organizationService) GetForUser(ctx context.Context, userID uint) ([]*proto.Organization, error) {
+	ctx, err := s.g.enter(ctx, "Organization.GetForUser")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.GetForUser(ctx, userID)
+}
+
+// Authorize guards Authorize.
+func (s *organizationService) Authorize(ctx context.Context, params *proto.OrganizationAuthorizeParams) (*proto.OrganizationMember, error) {
+	ctx, err := s.g.enter(ctx, "Organization.Authorize")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.Authorize(ctx, params)
+}
+
+// GetMembers guards GetMembers.
+func (s *organizationService) GetMembers(ctx context.Context, organizationID uint) ([]*proto.OrganizationMember, error) {
+	ctx, err := s.g.enter(ctx, "Organization.GetMembers")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.GetMembers(ctx, organizationID)
+}
+
+// UpdateMember guards UpdateMember.
+func (s *organizationService) UpdateMember(ctx context.Context, params *proto.OrganizationUpdateMemberParams) (*proto.OrganizationMember, error) {
+	ctx, err := s.g.enter(ctx, "Organization.UpdateMember")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.UpdateMember(ctx, params)
+}
+
+// RemoveMember guards RemoveMember.
+func (s *organizationService) RemoveMember(ctx context.Context, params *proto.OrganizationRemoveMemberParams) error {
+	ctx, err := s.g.enter(ctx, "Organization.RemoveMember")
+	if err != nil {
+		return err
+	}
+
+	return s.next.RemoveMember(ctx, params)
+}
+
+// Invite guards Invite.
+func (s *organizationService) Invite(ctx context.Context, params *proto.OrganizationInviteParams) error {
+	ctx, err := s.g.enter(ctx, "Organization.Invite")
+	if err != nil {
+		return err
+	}
+
+	return s.next.Invite(ctx, params)
+}
+
+// AcceptInvitation guards AcceptInvitation.
+func (s *organizationService) AcceptInvitation(ctx context.Context, params *proto.OrganizationAcceptInvitationParams) (*proto.OrganizationMember, error) {
+	ctx, err := s.g.enter(ctx, "Organization.AcceptInvitation")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.AcceptInvitation(ctx, params)
+}
@@ -0,0 +1,44 @@
+package guard
+
+import (
+	"context"
+
+	"dddstructure/proto"
+	"dddstructure/service/interfaces"
+)
+
+// reportService guards each call to the report service.
+type reportService struct {
+	next interfaces.Report
+	g    *guard
+}
+
+// GetAging guards GetAging.
+func (s *reportService) GetAging(ctx context.Context, params *proto.ReportAgingParams) ([]*proto.ReportAging, error) {
+	ctx, err := s.g.enter(ctx, "Report.GetAging")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.GetAging(ctx, params)
+}
+
+// GetRevenue guards GetRevenue.
+func (s *reportService) GetRevenue(ctx context.Context, params *proto.ReportRevenueParams) ([]*proto.ReportRevenue, error) {
+	ctx, err := s.g.enter(ctx, "Report.GetRevenue")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.GetRevenue(ctx, params)
+}
+
+// GetBalances guards GetBalances.
+func (s *reportService) GetBalances(ctx context.Context, params *proto.ReportBalancesParams) ([]*proto.ReportBalance, error) {
+	ctx, err := s.g.enter(ctx, "Report.GetBalances")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.GetBalances(ctx, params)
+}
@@ -0,0 +1,84 @@
+package guard
+
+import (
+	"context"
+
+	"dddstructure/proto"
+	"dddstructure/service/interfaces"
+)
+
+// sessionService guards each call to the session service.
+type sessionService struct {
+	next interfaces.Session
+	g    *guard
+}
+
+// Create guards Create.
+func (s *sessionService) Create(ctx context.Context, params *proto.SessionCreateParams) (*proto.Session, error) {
+	ctx, err := s.g.enter(ctx, "Session.Create")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.Create(ctx, params)
+}
+
+// Refresh guards Refresh.
+func (s *sessionService) Refresh(ctx context.Context, params *proto.SessionRefreshParams) (*proto.Session, error) {
+	ctx, err := s.g.enter(ctx, "Session.Refresh")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.Refresh(ctx, params)
+}
+
+// GetByID guards GetByID.
+func (s *sessionService) GetByID(ctx context.Context, id string) (*proto.Session, error) {
+	ctx, err := s.g.enter(ctx, "Session.GetByID")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.GetByID(ctx, id)
+}
+
+// GetByUserID guards GetByUserID.
+func (s *sessionService) GetByUserID(ctx context.Context, userID uint) ([]*proto.Session, error) {
+	ctx, err := s.g.enter(ctx, "Session.GetByUserID")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.GetByUserID(ctx, userID)
+}
+
+// Delete guards Delete.
+func (s *sessionService) Delete(ctx context.Context, id string) error {
+	ctx, err := s.g.enter(ctx, "Session.Delete")
+	if err != nil {
+		return err
+	}
+
+	return s.next.Delete(ctx, id)
+}
+
+// DeleteForUser guards DeleteForUser.
+func (s *sessionService) DeleteForUser(ctx context.Context, id string, userID uint) error {
+	ctx, err := s.g.enter(ctx, "Session.DeleteForUser")
+	if err != nil {
+		return err
+	}
+
+	return s.next.DeleteForUser(ctx, id, userID)
+}
+
+// DeleteByUserID guards DeleteByUserID.
+func (s *sessionService) DeleteByUserID(ctx context.Context, userID uint) error {
+	ctx, err := s.g.enter(ctx, "Session.DeleteByUserID")
+	if err != nil {
+		return err
+	}
+
+	return s.next.DeleteByUserID(ctx, userID)
+}
@@ -0,0 +1,24 @@
+package guard
+
+import (
+	"context"
+
+	"dddstructure/proto"
+	"dddstructure/service/interfaces"
+)
+
+// transactionService guards each call to the transaction service.
+type transactionService struct {
+	next interfaces.Transaction
+	g    *guard
+}
+
+// Process guards Process.
+func (s *transactionService) Process(ctx context.Context, params *proto.TransactionProcessParams) (*proto.Transaction, error) {
+	ctx, err := s.g.enter(ctx, "Transaction.Process")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.Process(ctx, params)
+}
@@ -0,0 +1,164 @@
+package guard
+
+import (
+	"context"
+
+	"dddstructure/proto"
+	"dddstructure/service/interfaces"
+)
+
+// userService guards each call to the user service.
+type userService struct {
+	next interfaces.User
+	g    *guard
+}
+
+// Create guards Create.
+func (s *userService) Create(ctx context.Context, params *proto.UserCreateParams) (*proto.User, error) {
+	ctx, err := s.g.enter(ctx, "User.Create")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.Create(ctx, params)
+}
+
+// Login guards Login.
+func (s *userService) Login(ctx context.Context, params *proto.UserLoginParams) (*proto.User, error) {
+	ctx, err := s.g.enter(ctx, "User.Login")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.Login(ctx, params)
+}
+
+// GetByID guards GetByID.
+func (s *userService) GetByID(ctx context.Context, id uint) (*proto.User, error) {
+	ctx, err := s.g.enter(ctx, "User.GetByID")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.GetByID(ctx, id)
+}
+
+// GetByEmail guards GetByEmail.
+func (s *userService) GetByEmail(ctx context.Context, email string) (*proto.User, error) {
+	ctx, err := s.g.enter(ctx, "User.GetByEmail")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.GetByEmail(ctx, email)
+}
+
+// Update guards Update.
+func (s *userService) Update(ctx context.Context, params *proto.UserUpdateParams) (*proto.User, error) {
+	ctx, err := s.g.enter(ctx, "User.Update")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.Update(ctx, params)
+}
+
+// ForgotPassword guards ForgotPassword.
+func (s *userService) ForgotPassword(ctx context.Context, params *proto.UserForgotPasswordParams) error {
+	ctx, err := s.g.enter(ctx, "User.ForgotPassword")
+	if err != nil {
+		return err
+	}
+
+	return s.next.ForgotPassword(ctx, params)
+}
+
+// ResetPassword guards ResetPassword.
+func (s *userService) ResetPassword(ctx context.Context, params *proto.UserResetPasswordParams) (*proto.User, error) {
+	ctx, err := s.g.enter(ctx, "User.ResetPassword")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.ResetPassword(ctx, params)
+}
+
+// SendEmailVerification guards SendEmailVerification.
+func (s *userService) SendEmailVerification(ctx context.Context, params *proto.UserSendEmailVerificationParams) error {
+	ctx, err := s.g.enter(ctx, "User.SendEmailVerification")
+	if err != nil {
+		return err
+	}
+
+	return s.next.SendEmailVerification(ctx, params)
+}
+
+// VerifyEmail guards VerifyEmail.
+func (s *userService) VerifyEmail(ctx context.Context, params *proto.UserVerifyEmailParams) (*proto.User, error) {
+	ctx, err := s.g.enter(ctx, "User.VerifyEmail")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.VerifyEmail(ctx, params)
+}
+
+// CreateLoginChallenge guards CreateLoginChallenge.
+func (s *userService) CreateLoginChallenge(ctx context.Context, id uint) (*proto.UserLoginChallenge, error) {
+	ctx, err := s.g.enter(ctx, "User.CreateLoginChallenge")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.CreateLoginChallenge(ctx, id)
+}
+
+// LoginTwoFactor guards LoginTwoFactor.
+func (s *userService) LoginTwoFactor(ctx context.Context, params *proto.UserLoginTwoFactorParams) (*proto.User, error) {
+	ctx, err := s.g.enter(ctx, "User.LoginTwoFactor")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.LoginTwoFactor(ctx, params)
+}
+
+// EnrollTwoFactor guards EnrollTwoFactor.
+func (s *userService) EnrollTwoFactor(ctx context.Context, params *proto.UserEnrollTwoFactorParams) (*proto.UserTwoFactorEnrollment, error) {
+	ctx, err := s.g.enter(ctx, "User.EnrollTwoFactor")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.EnrollTwoFactor(ctx, params)
+}
+
+// ConfirmTwoFactor guards ConfirmTwoFactor.
+func (s *userService) ConfirmTwoFactor(ctx context.Context, params *proto.UserConfirmTwoFactorParams) ([]string, error) {
+	ctx, err := s.g.enter(ctx, "User.ConfirmTwoFactor")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.ConfirmTwoFactor(ctx, params)
+}
+
+// DisableTwoFactor guards DisableTwoFactor.
+func (s *userService) DisableTwoFactor(ctx context.Context, params *proto.UserDisableTwoFactorParams) error {
+	ctx, err := s.g.enter(ctx, "User.DisableTwoFactor")
+	if err != nil {
+		return err
+	}
+
+	return s.next.DisableTwoFactor(ctx, params)
+}
+
+// RegenerateRecoveryCodes guards RegenerateRecoveryCodes.
+func (s *userService) RegenerateRecoveryCodes(ctx context.Context, params *proto.UserRegenerateRecoveryCodesParams) ([]string, error) {
+	ctx, err := s.g.enter(ctx, "User.RegenerateRecoveryCodes")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.next.RegenerateRecoveryCodes(ctx, params)
+}
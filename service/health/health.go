@@ -0,0 +1,69 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+
+	"dddstructure/proto"
+	"dddstructure/service/interfaces"
+	"dddstructure/storage"
+	"dddstructure/trace"
+)
+
+// Service defines the health service.
+type Service struct {
+	storage  *storage.Storage
+	services *interfaces.Service
+	logger   *slog.Logger
+}
+
+// SetServices sets the services interface.
+func (s *Service) SetServices(services *interfaces.Service) {
+	s.services = services
+}
+
+// WithLogger returns a copy of the service that logs to the given logger.
+func (s *Service) WithLogger(l *slog.Logger) *Service {
+	c := *s
+	c.logger = l
+	return &c
+}
+
+// New creates a new service.
+func New(s *storage.Storage, l *slog.Logger) *Service {
+	return &Service{
+		storage: s,
+		logger:  l,
+	}
+}
+
+// Check checks each dependency of the services can be reached.
+func (s *Service) Check(ctx context.Context) *proto.Health {
+	ctx, span := trace.Start(ctx, "service.Health.Check")
+	defer span.End()
+
+	health := &proto.Health{
+		Status: proto.HealthStatusUp,
+	}
+
+	// Check the database.
+	database := &proto.HealthCheck{
+		Name:   "database",
+		Status: proto.HealthStatusUp,
+	}
+	if err := s.storage.Health.Ping(ctx); err != nil {
+		s.logger.Error("storage.Health.Ping() error",
+			slog.Any("error", err))
+		database.Status = proto.HealthStatusDown
+		database.Error = err.Error()
+	}
+	health.Checks = append(health.Checks, database)
+
+	for _, c := range health.Checks {
+		if c.Status != proto.HealthStatusUp {
+			health.Status = proto.HealthStatusDown
+		}
+	}
+
+	return health
+}
@@ -1,55 +1,125 @@
 package interfaces
 
-import "dddstructure/proto"
+import (
+	"context"
+	"dddstructure/proto"
+	"dddstructure/service/events"
+)
 
 // Service defines the main business logic service interface struct that will
 // be used between services to call each other.
+//
+// Services that only need to react to another service's changes subscribe
+// to its events on the Events bus instead of being called directly.
 type Service struct {
-	User        User
-	Invoice     Invoice
-	Transaction Transaction
+	User         User
+	Invoice      Invoice
+	Transaction  Transaction
+	Report       Report
+	Session      Session
+	Organization Organization
+	Health       Health
+	Events       *events.Bus
 }
 
 // NewServiceParams defines the new service params.
 type NewServiceParams struct {
-	User        User
-	Invoice     Invoice
-	Transaction Transaction
+	User         User
+	Invoice      Invoice
+	Transaction  Transaction
+	Report       Report
+	Session      Session
+	Organization Organization
+	Health       Health
+	Events       *events.Bus
 }
 
 // NewService creates a new service.
 func NewService(params NewServiceParams) *Service {
 	return &Service{
-		User:        params.User,
-		Invoice:     params.Invoice,
-		Transaction: params.Transaction,
+		User:         params.User,
+		Invoice:      params.Invoice,
+		Transaction:  params.Transaction,
+		Report:       params.Report,
+		Session:      params.Session,
+		Organization: params.Organization,
+		Health:       params.Health,
+		Events:       params.Events,
 	}
 }
 
 // User defines the user service.
 type User interface {
-	Create(params *proto.UserCreateParams) (*proto.User, error)
-	Login(params *proto.UserLoginParams) (*proto.User, error)
-	GetByID(id uint) (*proto.User, error)
-	Update(params *proto.UserUpdateParams) (*proto.User, error)
+	Create(ctx context.Context, params *proto.UserCreateParams) (*proto.User, error)
+	Login(ctx context.Context, params *proto.UserLoginParams) (*proto.User, error)
+	GetByID(ctx context.Context, id uint) (*proto.User, error)
+	GetByEmail(ctx context.Context, email string) (*proto.User, error)
+	Update(ctx context.Context, params *proto.UserUpdateParams) (*proto.User, error)
+	ForgotPassword(ctx context.Context, params *proto.UserForgotPasswordParams) error
+	ResetPassword(ctx context.Context, params *proto.UserResetPasswordParams) (*proto.User, error)
+	SendEmailVerification(ctx context.Context, params *proto.UserSendEmailVerificationParams) error
+	VerifyEmail(ctx context.Context, params *proto.UserVerifyEmailParams) (*proto.User, error)
+	CreateLoginChallenge(ctx context.Context, id uint) (*proto.UserLoginChallenge, error)
+	LoginTwoFactor(ctx context.Context, params *proto.UserLoginTwoFactorParams) (*proto.User, error)
+	EnrollTwoFactor(ctx context.Context, params *proto.UserEnrollTwoFactorParams) (*proto.UserTwoFactorEnrollment, error)
+	ConfirmTwoFactor(ctx context.Context, params *proto.UserConfirmTwoFactorParams) ([]string, error)
+	DisableTwoFactor(ctx context.Context, params *proto.UserDisableTwoFactorParams) error
+	RegenerateRecoveryCodes(ctx context.Context, params *proto.UserRegenerateRecoveryCodesParams) ([]string, error)
 }
 
 // Invoice defines the invoice service.
 type Invoice interface {
-	Create(params *proto.InvoiceCreateParams) (*proto.Invoice, error)
-	Get(params *proto.InvoiceGetParams) ([]*proto.Invoice, error)
-	GetCount(params *proto.InvoiceGetParams) (uint, error)
-	GetByID(id uint) (*proto.Invoice, error)
-	GetByIDAndUserID(id, userID uint) (*proto.Invoice, error)
-	GetByPublicHash(hash string) (*proto.Invoice, error)
-	Update(params *proto.InvoiceUpdateParams) (*proto.Invoice, error)
-	UpdateForUser(params *proto.InvoiceUpdateParams) (*proto.Invoice, error)
-	UpdateForTransaction(params *proto.InvoiceUpdateForTransactionParams) (*proto.Invoice, error)
-	Delete(id uint) error
-	Pay(id uint, params *proto.InvoicePayParams) (*proto.Invoice, error)
+	Create(ctx context.Context, params *proto.InvoiceCreateParams) (*proto.Invoice, error)
+	Get(ctx context.Context, params *proto.InvoiceGetParams) ([]*proto.Invoice, error)
+	GetCount(ctx context.Context, params *proto.InvoiceGetParams) (uint, error)
+	GetByID(ctx context.Context, id uint) (*proto.Invoice, error)
+	GetByIDAndOrganizationID(ctx context.Context, id, organizationID uint) (*proto.Invoice, error)
+	GetByPublicHash(ctx context.Context, hash string) (*proto.Invoice, error)
+	Update(ctx context.Context, params *proto.InvoiceUpdateParams) (*proto.Invoice, error)
+	UpdateForOrganization(ctx context.Context, params *proto.InvoiceUpdateParams) (*proto.Invoice, error)
+	UpdateForTransaction(ctx context.Context, params *proto.InvoiceUpdateForTransactionParams) (*proto.Invoice, error)
+	Delete(ctx context.Context, id uint) error
+	Pay(ctx context.Context, id uint, params *proto.InvoicePayParams) (*proto.Invoice, error)
+	Import(ctx context.Context, params *proto.InvoiceImportParams) ([]*proto.InvoiceImportResult, error)
 }
 
 // Transaction defines the transaction service.
 type Transaction interface {
-	Process(params *proto.TransactionProcessParams) (*proto.Transaction, error)
+	Process(ctx context.Context, params *proto.TransactionProcessParams) (*proto.Transaction, error)
+}
+
+// Report defines the report service.
+type Report interface {
+	GetAging(ctx context.Context, params *proto.ReportAgingParams) ([]*proto.ReportAging, error)
+	GetRevenue(ctx context.Context, params *proto.ReportRevenueParams) ([]*proto.ReportRevenue, error)
+	GetBalances(ctx context.Context, params *proto.ReportBalancesParams) ([]*proto.ReportBalance, error)
+}
+
+// Session defines the session service.
+type Session interface {
+	Create(ctx context.Context, params *proto.SessionCreateParams) (*proto.Session, error)
+	Refresh(ctx context.Context, params *proto.SessionRefreshParams) (*proto.Session, error)
+	GetByID(ctx context.Context, id string) (*proto.Session, error)
+	GetByUserID(ctx context.Context, userID uint) ([]*proto.Session, error)
+	Delete(ctx context.Context, id string) error
+	DeleteForUser(ctx context.Context, id string, userID uint) error
+	DeleteByUserID(ctx context.Context, userID uint) error
+}
+
+// Organization defines the organization service.
+type Organization interface {
+	Create(ctx context.Context, params *proto.OrganizationCreateParams) (*proto.Organization, error)
+	GetByID(ctx context.Context, id uint) (*proto.Organization, error)
+	GetForUser(ctx context.Context, userID uint) ([]*proto.Organization, error)
+	Authorize(ctx context.Context, params *proto.OrganizationAuthorizeParams) (*proto.OrganizationMember, error)
+	GetMembers(ctx context.Context, organizationID uint) ([]*proto.OrganizationMember, error)
+	UpdateMember(ctx context.Context, params *proto.OrganizationUpdateMemberParams) (*proto.OrganizationMember, error)
+	RemoveMember(ctx context.Context, params *proto.OrganizationRemoveMemberParams) error
+	Invite(ctx context.Context, params *proto.OrganizationInviteParams) error
+	AcceptInvitation(ctx context.Context, params *proto.OrganizationAcceptInvitationParams) (*proto.OrganizationMember, error)
+}
+
+// Health defines the health service.
+type Health interface {
+	Check(ctx context.Context) *proto.Health
 }
@@ -1,21 +1,22 @@
 package invoice
 
 import (
+	"context"
 	"log/slog"
 
 	"dddstructure/proto"
+	"dddstructure/service/actor"
 	serverrors "dddstructure/service/errors"
+	"dddstructure/service/events"
 	"dddstructure/service/interfaces"
 	"dddstructure/storage"
 	"dddstructure/storage/invoice"
+	"dddstructure/trace"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// idCounter handles increasing the ID.
-var idCounter uint = 1
-
 // Service defines the invoice service.
 type Service struct {
 	storage  *storage.Storage
@@ -28,6 +29,19 @@ func (s *Service) SetServices(services *interfaces.Service) {
 	s.services = services
 }
 
+// Subscribe subscribes the service to the events it handles on the given
+// bus.
+func (s *Service) Subscribe(bus *events.Bus) {
+	bus.RefundIssued.Subscribe(s.handleRefundIssued)
+}
+
+// WithLogger returns a copy of the service that logs to the given logger.
+func (s *Service) WithLogger(l *slog.Logger) *Service {
+	c := *s
+	c.logger = l
+	return &c
+}
+
 // New creates a new service.
 func New(s *storage.Storage, l *slog.Logger) *Service {
 	return &Service{
@@ -37,91 +51,33 @@ func New(s *storage.Storage, l *slog.Logger) *Service {
 }
 
 // Create creates a new invoice.
-func (s *Service) Create(params *proto.InvoiceCreateParams) (*proto.Invoice, error) {
-	// Validate parameters.
-	if err := s.ValidateCreateParams(params); err != nil {
-		return nil, err
-	}
+func (s *Service) Create(ctx context.Context, params *proto.InvoiceCreateParams) (*proto.Invoice, error) {
+	ctx, span := trace.Start(ctx, "service.Invoice.Create")
+	defer span.End()
 
-	// Handle ID.
-	if params.ID == 0 {
-		params.ID = idCounter
-		idCounter++
+	// Authorize the actor.
+	if err := actor.Authorize(ctx, s.services.Organization, params.OrganizationID, proto.OrganizationPermissionInvoiceWrite); err != nil {
+		return nil, err
 	}
 
-	// Handle line items.
-	lineItems := []invoice.LineItem{}
-	for _, v := range params.LineItems {
-		lineItem := invoice.LineItem{
-			Name:        v.Name,
-			Description: v.Description,
-			Quantity:    v.Quantity,
-			Price:       v.Price,
-		}
-
-		lineItems = append(lineItems, lineItem)
+	// Validate parameters.
+	if err := s.ValidateCreateParams(ctx, params); err != nil {
+		return nil, err
 	}
 
-	// Calculate invoice amounts.
-	amounts, err := CalculateAmounts(CalculateAmountsParams{
-		LineItems: params.LineItems,
-		TaxRate:   params.TaxRate,
-	})
-	if err != nil {
-		s.logger.Error("CalculateAmounts() error",
-			slog.Any("error", err))
-		return nil, serverrors.ErrInvoiceCalculatingAmounts
-	}
+	return s.create(ctx, params)
+}
 
+// create creates a new invoice from validated parameters, once the actor is
+// authorized.
+func (s *Service) create(ctx context.Context, params *proto.InvoiceCreateParams) (*proto.Invoice, error) {
 	// Create an invoice.
-	paymentMethods := []string{}
-	for _, v := range params.PaymentMethods {
-		paymentMethods = append(paymentMethods, string(v))
+	storagei, err := s.createParamsToStorage(params)
+	if err != nil {
+		return nil, err
 	}
 
-	storagei, err := s.storage.Invoice.Create(&invoice.Invoice{
-		ID:            params.ID,
-		UserID:        params.UserID,
-		PublicHash:    uuid.New().String(),
-		InvoiceNumber: params.InvoiceNumber,
-		PONumber:      params.PONumber,
-		Currency:      params.Currency,
-		DueDate:       params.DueDate,
-		Message:       params.Message,
-		BillTo: invoice.BillTo{
-			FirstName:    params.BillTo.FirstName,
-			LastName:     params.BillTo.LastName,
-			Company:      params.BillTo.Company,
-			AddressLine1: params.BillTo.AddressLine1,
-			AddressLine2: params.BillTo.AddressLine2,
-			City:         params.BillTo.City,
-			State:        params.BillTo.State,
-			PostalCode:   params.BillTo.PostalCode,
-			Country:      params.BillTo.Country,
-			Email:        params.BillTo.Email,
-			Phone:        params.BillTo.Phone,
-		},
-		PayTo: invoice.PayTo{
-			FirstName:    params.PayTo.FirstName,
-			LastName:     params.PayTo.LastName,
-			Company:      params.PayTo.Company,
-			AddressLine1: params.PayTo.AddressLine1,
-			AddressLine2: params.PayTo.AddressLine2,
-			City:         params.PayTo.City,
-			State:        params.PayTo.State,
-			PostalCode:   params.PayTo.PostalCode,
-			Country:      params.PayTo.Country,
-			Email:        params.PayTo.Email,
-			Phone:        params.PayTo.Phone,
-		},
-		LineItems:      lineItems,
-		PaymentMethods: paymentMethods,
-		TaxRate:        params.TaxRate,
-		AmountDue:      amounts.AmountDue,
-		AmountPaid:     0,
-		Status:         "pending",
-		CreatedAt:      time.Now().UTC(),
-	})
+	storagei, err = s.storage.Invoice.Create(ctx, storagei)
 	if err != nil {
 		s.logger.Error("storage.Invoice.Create() error",
 			slog.Any("error", err))
@@ -132,9 +88,18 @@ func (s *Service) Create(params *proto.InvoiceCreateParams) (*proto.Invoice, err
 }
 
 // Get gets a set of invoices.
-func (s *Service) Get(params *proto.InvoiceGetParams) ([]*proto.Invoice, error) {
+func (s *Service) Get(ctx context.Context, params *proto.InvoiceGetParams) ([]*proto.Invoice, error) {
+	ctx, span := trace.Start(ctx, "service.Invoice.Get")
+	defer span.End()
+
+	// Authorize the actor, where only the system may get the invoices of
+	// every organization.
+	if err := s.authorizeGet(ctx, params); err != nil {
+		return nil, err
+	}
+
 	// Validate parameters.
-	if err := s.ValidateGetParams(params); err != nil {
+	if err := s.ValidateGetParams(ctx, params); err != nil {
 		return nil, err
 	}
 
@@ -149,9 +114,9 @@ func (s *Service) Get(params *proto.InvoiceGetParams) ([]*proto.Invoice, error)
 		getParams.ID = params.ID
 	}
 
-	// Check user ID.
-	if params.UserID != nil {
-		getParams.UserID = params.UserID
+	// Scope by organization.
+	if params.OrganizationID != nil {
+		getParams.OrganizationID = params.OrganizationID
 	}
 
 	// Check status.
@@ -170,8 +135,17 @@ func (s *Service) Get(params *proto.InvoiceGetParams) ([]*proto.Invoice, error)
 		}
 	}
 
+	// Check cursor.
+	if params.Cursor != nil {
+		getParams.Cursor = &invoice.GetParamsCursor{
+			CreatedAt: params.Cursor.CreatedAt,
+			ID:        params.Cursor.ID,
+			Before:    params.Cursor.Before,
+		}
+	}
+
 	// Get invoices from storage.
-	storageis, err := s.storage.Invoice.Get(getParams)
+	storageis, err := s.storage.Invoice.Get(ctx, getParams)
 	if err != nil {
 		s.logger.Error("storage.Invoice.Get() error",
 			slog.Any("error", err))
@@ -194,9 +168,18 @@ func (s *Service) Get(params *proto.InvoiceGetParams) ([]*proto.Invoice, error)
 }
 
 // GetCount gets the count of a set of invoices.
-func (s *Service) GetCount(params *proto.InvoiceGetParams) (uint, error) {
+func (s *Service) GetCount(ctx context.Context, params *proto.InvoiceGetParams) (uint, error) {
+	ctx, span := trace.Start(ctx, "service.Invoice.GetCount")
+	defer span.End()
+
+	// Authorize the actor, where only the system may get the invoices of
+	// every organization.
+	if err := s.authorizeGet(ctx, params); err != nil {
+		return 0, err
+	}
+
 	// Validate parameters.
-	if err := s.ValidateGetParams(params); err != nil {
+	if err := s.ValidateGetParams(ctx, params); err != nil {
 		return 0, err
 	}
 
@@ -208,9 +191,9 @@ func (s *Service) GetCount(params *proto.InvoiceGetParams) (uint, error) {
 		getParams.ID = params.ID
 	}
 
-	// Check user ID.
-	if params.UserID != nil {
-		getParams.UserID = params.UserID
+	// Scope by organization.
+	if params.OrganizationID != nil {
+		getParams.OrganizationID = params.OrganizationID
 	}
 
 	// Check status.
@@ -218,8 +201,19 @@ func (s *Service) GetCount(params *proto.InvoiceGetParams) (uint, error) {
 		getParams.Status = params.Status
 	}
 
+	// Check created at.
+	if params.CreatedAt != nil {
+		getParams.CreatedAt = &invoice.GetParamsCreatedAt{}
+		if params.CreatedAt.StartDate != nil {
+			getParams.CreatedAt.StartDate = params.CreatedAt.StartDate
+		}
+		if params.CreatedAt.EndDate != nil {
+			getParams.CreatedAt.EndDate = params.CreatedAt.EndDate
+		}
+	}
+
 	// Get invoices count from storage.
-	count, err := s.storage.Invoice.GetCount(getParams)
+	count, err := s.storage.Invoice.GetCount(ctx, getParams)
 	if err != nil {
 		s.logger.Error("storage.Invoice.GetCount() error",
 			slog.Any("error", err))
@@ -230,26 +224,34 @@ func (s *Service) GetCount(params *proto.InvoiceGetParams) (uint, error) {
 }
 
 // GetByID gets an invoice by the given ID.
-func (s *Service) GetByID(id uint) (*proto.Invoice, error) {
+func (s *Service) GetByID(ctx context.Context, id uint) (*proto.Invoice, error) {
+	ctx, span := trace.Start(ctx, "service.Invoice.GetByID")
+	defer span.End()
+
 	// Get invoice by ID.
-	storagei, err := s.storage.Invoice.GetByID(id)
+	storagei, err := s.getAuthorized(ctx, id, proto.OrganizationPermissionInvoiceRead)
 	if err != nil {
-		if err == invoice.ErrInvoiceNotFound {
-			return nil, serverrors.ErrInvoiceNotFound
-		}
-
-		s.logger.Error("storage.Invoice.GetByID() error",
-			slog.Any("error", err))
 		return nil, err
 	}
 
 	return storageToProto(storagei), nil
 }
 
-// GetByID gets an invoice by the given ID and user ID.
-func (s *Service) GetByIDAndUserID(id, userID uint) (*proto.Invoice, error) {
+// GetByIDAndOrganizationID gets an invoice by the given ID and organization
+// ID.
+func (s *Service) GetByIDAndOrganizationID(ctx context.Context, id, organizationID uint) (*proto.Invoice, error) {
+	ctx, span := trace.Start(ctx, "service.Invoice.GetByIDAndOrganizationID")
+	defer span.End()
+
+	// Authorize the actor.
+	if err := actor.Authorize(ctx, s.services.Organization, organizationID, proto.OrganizationPermissionInvoiceRead); err == serverrors.ErrOrganizationNotFound {
+		return nil, serverrors.ErrInvoiceNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
 	// Get invoice by ID.
-	storagei, err := s.storage.Invoice.GetByID(id)
+	storagei, err := s.storage.Invoice.GetByID(ctx, id)
 	if err != nil {
 		if err == invoice.ErrInvoiceNotFound {
 			return nil, serverrors.ErrInvoiceNotFound
@@ -260,8 +262,8 @@ func (s *Service) GetByIDAndUserID(id, userID uint) (*proto.Invoice, error) {
 		return nil, err
 	}
 
-	// Check user ID.
-	if storagei.UserID != userID {
+	// Check organization ID.
+	if storagei.OrganizationID != organizationID {
 		return nil, serverrors.ErrInvoiceNotFound
 	}
 
@@ -269,9 +271,12 @@ func (s *Service) GetByIDAndUserID(id, userID uint) (*proto.Invoice, error) {
 }
 
 // GetByPublicHash gets an invoice by the given public hash.
-func (s *Service) GetByPublicHash(hash string) (*proto.Invoice, error) {
+func (s *Service) GetByPublicHash(ctx context.Context, hash string) (*proto.Invoice, error) {
+	ctx, span := trace.Start(ctx, "service.Invoice.GetByPublicHash")
+	defer span.End()
+
 	// Get invoice by ID.
-	storagei, err := s.storage.Invoice.GetByPublicHash(hash)
+	storagei, err := s.storage.Invoice.GetByPublicHash(ctx, hash)
 	if err != nil {
 		if err == invoice.ErrInvoiceNotFound {
 			return nil, serverrors.ErrInvoiceNotFound
@@ -286,17 +291,18 @@ func (s *Service) GetByPublicHash(hash string) (*proto.Invoice, error) {
 }
 
 // Update handles updating an invoice.
-func (s *Service) Update(params *proto.InvoiceUpdateParams) (*proto.Invoice, error) {
+func (s *Service) Update(ctx context.Context, params *proto.InvoiceUpdateParams) (*proto.Invoice, error) {
+	ctx, span := trace.Start(ctx, "service.Invoice.Update")
+	defer span.End()
+
 	// Validate parameters.
-	if err := s.ValidateUpdateParams(params); err != nil {
+	if err := s.ValidateUpdateParams(ctx, params); err != nil {
 		return nil, err
 	}
 
 	// Get invoice from storage.
-	storagei, err := s.storage.Invoice.GetByID(*params.ID)
+	storagei, err := s.getAuthorized(ctx, *params.ID, proto.OrganizationPermissionInvoiceWrite)
 	if err != nil {
-		s.logger.Error("storage.Invoice.GetByID() error",
-			slog.Any("error", err))
 		return nil, err
 	}
 
@@ -446,7 +452,7 @@ func (s *Service) Update(params *proto.InvoiceUpdateParams) (*proto.Invoice, err
 	storagei.AmountDue = amounts.AmountDue
 
 	// Update the invoice.
-	storagei, err = s.storage.Invoice.Update(storagei)
+	storagei, err = s.storage.Invoice.Update(ctx, storagei)
 	if err != nil {
 		s.logger.Error("storage.Invoice.Update() error",
 			slog.Any("error", err))
@@ -456,30 +462,34 @@ func (s *Service) Update(params *proto.InvoiceUpdateParams) (*proto.Invoice, err
 	return storageToProto(storagei), nil
 }
 
-// UpdateForUser handles updating an invoice for a user.
-func (s *Service) UpdateForUser(params *proto.InvoiceUpdateParams) (*proto.Invoice, error) {
-	// Get by ID and user ID.
-	_, err := s.GetByIDAndUserID(*params.ID, *params.UserID)
+// UpdateForOrganization handles updating an invoice for an organization.
+func (s *Service) UpdateForOrganization(ctx context.Context, params *proto.InvoiceUpdateParams) (*proto.Invoice, error) {
+	ctx, span := trace.Start(ctx, "service.Invoice.UpdateForOrganization")
+	defer span.End()
+
+	// Get by ID and organization ID.
+	_, err := s.GetByIDAndOrganizationID(ctx, *params.ID, *params.OrganizationID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Call update.
-	return s.Update(params)
+	return s.Update(ctx, params)
 }
 
 // UpdateForTransaction handles updating an invoice for a transaction.
-func (s *Service) UpdateForTransaction(params *proto.InvoiceUpdateForTransactionParams) (*proto.Invoice, error) {
+func (s *Service) UpdateForTransaction(ctx context.Context, params *proto.InvoiceUpdateForTransactionParams) (*proto.Invoice, error) {
+	ctx, span := trace.Start(ctx, "service.Invoice.UpdateForTransaction")
+	defer span.End()
+
 	// Validate parameters.
-	if err := s.ValidateUpdateForTransactionParams(params); err != nil {
+	if err := s.ValidateUpdateForTransactionParams(ctx, params); err != nil {
 		return nil, err
 	}
 
 	// Get invoice from storage.
-	storagei, err := s.storage.Invoice.GetByID(*params.ID)
+	storagei, err := s.getAuthorized(ctx, *params.ID, proto.OrganizationPermissionTransaction)
 	if err != nil {
-		s.logger.Error("storage.Invoice.GetByID() error",
-			slog.Any("error", err))
 		return nil, err
 	}
 
@@ -499,7 +509,7 @@ func (s *Service) UpdateForTransaction(params *proto.InvoiceUpdateForTransaction
 	}
 
 	// Update the invoice.
-	storagei, err = s.storage.Invoice.Update(storagei)
+	storagei, err = s.storage.Invoice.Update(ctx, storagei)
 	if err != nil {
 		s.logger.Error("storage.Invoice.Update() error",
 			slog.Any("error", err))
@@ -509,10 +519,64 @@ func (s *Service) UpdateForTransaction(params *proto.InvoiceUpdateForTransaction
 	return storageToProto(storagei), nil
 }
 
+// handleRefundIssued handles a refund being issued, returning the refunded
+// amount to its invoice, which is pending payment again.
+//
+// The refund was authorized when it was processed, so the invoice is
+// updated on behalf of the system.
+func (s *Service) handleRefundIssued(ctx context.Context, e *events.RefundIssued) error {
+	ctx, span := trace.Start(ctx, "service.Invoice.handleRefundIssued")
+	defer span.End()
+
+	ctx = actor.WithSystem(ctx)
+
+	// Get the refunded invoice.
+	servicei, err := s.GetByID(ctx, e.Transaction.InvoiceID)
+	if err != nil {
+		return err
+	}
+
+	// Change amounts and status. Refunds over the amount paid are rejected
+	// when processed, but another refund may have been saved since, so no
+	// more than the amount paid is returned.
+	refunded := e.Transaction.AmountCaptured
+	if refunded > servicei.AmountPaid {
+		s.logger.Warn("refund is over the amount paid",
+			slog.Uint64("invoice_id", uint64(servicei.ID)),
+			slog.Uint64("transaction_id", uint64(e.Transaction.ID)))
+		refunded = servicei.AmountPaid
+	}
+
+	servicei.AmountDue += refunded
+	servicei.AmountPaid -= refunded
+	servicei.Status = "pending"
+
+	if _, err := s.UpdateForTransaction(ctx, &proto.InvoiceUpdateForTransactionParams{
+		ID:         &servicei.ID,
+		AmountDue:  &servicei.AmountDue,
+		AmountPaid: &servicei.AmountPaid,
+		Status:     &servicei.Status,
+	}); err != nil {
+		s.logger.Error("s.UpdateForTransaction() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
 // Delete deletes an invoice by the given ID.
-func (s *Service) Delete(id uint) error {
+func (s *Service) Delete(ctx context.Context, id uint) error {
+	ctx, span := trace.Start(ctx, "service.Invoice.Delete")
+	defer span.End()
+
+	// Authorize the actor.
+	if _, err := s.getAuthorized(ctx, id, proto.OrganizationPermissionInvoiceWrite); err != nil {
+		return err
+	}
+
 	// Delete invoice by ID.
-	err := s.storage.Invoice.Delete(id)
+	err := s.storage.Invoice.Delete(ctx, id)
 	if err != nil {
 		if err == invoice.ErrInvoiceNotFound {
 			return serverrors.ErrInvoiceNotFound
@@ -527,15 +591,23 @@ func (s *Service) Delete(id uint) error {
 }
 
 // Pay handles paying an invoice.
-func (s *Service) Pay(id uint, params *proto.InvoicePayParams) (*proto.Invoice, error) {
+//
+// Anyone with the public link of an invoice may pay it, so there is no
+// actor to authorize, and the payment is processed on behalf of the system.
+func (s *Service) Pay(ctx context.Context, id uint, params *proto.InvoicePayParams) (*proto.Invoice, error) {
+	ctx, span := trace.Start(ctx, "service.Invoice.Pay")
+	defer span.End()
+
 	// Validate parameters.
-	if err := s.ValidatePayParams(params); err != nil {
+	if err := s.ValidatePayParams(ctx, params); err != nil {
 		return nil, err
 	}
 
 	// Get the invoice.
-	storagei, err := s.storage.Invoice.GetByID(id)
-	if err != nil {
+	storagei, err := s.storage.Invoice.GetByID(ctx, id)
+	if err == invoice.ErrInvoiceNotFound {
+		return nil, serverrors.ErrInvoiceNotFound
+	} else if err != nil {
 		s.logger.Error("storage.Invoice.GetByID() error",
 			slog.Any("error", err))
 		return nil, err
@@ -547,11 +619,12 @@ func (s *Service) Pay(id uint, params *proto.InvoicePayParams) (*proto.Invoice,
 	}
 
 	// Pay the invoice using the transaction service.
-	t, err := s.services.Transaction.Process(&proto.TransactionProcessParams{
-		UserID:    storagei.UserID,
-		Type:      "sale",
-		Amount:    params.Amount,
-		InvoiceID: id,
+	t, err := s.services.Transaction.Process(actor.WithSystem(ctx), &proto.TransactionProcessParams{
+		OrganizationID: storagei.OrganizationID,
+		UserID:         storagei.UserID,
+		Type:           "sale",
+		Amount:         params.Amount,
+		InvoiceID:      id,
 	})
 	if err != nil {
 		return nil, err
@@ -562,8 +635,10 @@ func (s *Service) Pay(id uint, params *proto.InvoicePayParams) (*proto.Invoice,
 	storagei.AmountDue -= t.AmountCaptured
 	storagei.Status = "paid"
 
-	storagei, err = s.storage.Invoice.Update(storagei)
-	if err != nil {
+	storagei, err = s.storage.Invoice.Update(ctx, storagei)
+	if err == invoice.ErrInvoiceNotFound {
+		return nil, serverrors.ErrInvoiceNotFound
+	} else if err != nil {
 		s.logger.Error("storage.Invoice.Update() error",
 			slog.Any("error", err))
 		return nil, err
@@ -572,6 +647,206 @@ func (s *Service) Pay(id uint, params *proto.InvoicePayParams) (*proto.Invoice,
 	return storageToProto(storagei), nil
 }
 
+// Import handles importing a set of invoices for an organization.
+//
+// Every invoice is validated first, and a result is returned for each in the
+// same order they were given. In dry run mode no invoices are created. In all
+// or nothing mode no invoices are created unless all of them are valid, and
+// they are created in a single storage transaction, so if creating one fails
+// none are created.
+func (s *Service) Import(ctx context.Context, params *proto.InvoiceImportParams) ([]*proto.InvoiceImportResult, error) {
+	ctx, span := trace.Start(ctx, "service.Invoice.Import")
+	defer span.End()
+
+	// Authorize the actor.
+	if err := actor.Authorize(ctx, s.services.Organization, params.OrganizationID, proto.OrganizationPermissionInvoiceWrite); err != nil {
+		return nil, err
+	}
+
+	// Validate each invoice.
+	results := []*proto.InvoiceImportResult{}
+	valid := true
+	for _, v := range params.Invoices {
+		v.OrganizationID = params.OrganizationID
+		v.UserID = params.UserID
+
+		result := &proto.InvoiceImportResult{}
+		if err := s.ValidateCreateParams(ctx, v); err != nil {
+			result.Error = err
+			valid = false
+		}
+
+		results = append(results, result)
+	}
+
+	// Return if nothing should be created.
+	if params.DryRun || (params.AllOrNothing && !valid) {
+		return results, nil
+	}
+
+	if params.AllOrNothing {
+		return s.importAll(ctx, params, results)
+	}
+
+	// Create the valid invoices.
+	for n, v := range params.Invoices {
+		if results[n].Error != nil {
+			continue
+		}
+
+		i, err := s.create(ctx, v)
+		if err != nil {
+			results[n].Error = err
+			continue
+		}
+
+		results[n].Invoice = i
+	}
+
+	return results, nil
+}
+
+// importAll creates every invoice of an import in a single storage
+// transaction, setting the invoice of each result.
+func (s *Service) importAll(ctx context.Context, params *proto.InvoiceImportParams, results []*proto.InvoiceImportResult) ([]*proto.InvoiceImportResult, error) {
+	invoices := []*invoice.Invoice{}
+	for _, v := range params.Invoices {
+		storagei, err := s.createParamsToStorage(v)
+		if err != nil {
+			return nil, err
+		}
+
+		invoices = append(invoices, storagei)
+	}
+
+	created, err := s.storage.Invoice.CreateBatch(ctx, invoices)
+	if err != nil {
+		s.logger.Error("storage.Invoice.CreateBatch() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	for n, i := range created {
+		results[n].Invoice = storageToProto(i)
+	}
+
+	return results, nil
+}
+
+// getAuthorized gets an invoice by the given ID from storage, once the actor
+// is authorized on its organization with the given permission.
+//
+// ErrInvoiceNotFound is returned if the actor is not a member of the
+// organization, so the invoices of other organizations are not revealed.
+func (s *Service) getAuthorized(ctx context.Context, id uint, permission proto.OrganizationPermission) (*invoice.Invoice, error) {
+	storagei, err := s.storage.Invoice.GetByID(ctx, id)
+	if err == invoice.ErrInvoiceNotFound {
+		return nil, serverrors.ErrInvoiceNotFound
+	} else if err != nil {
+		s.logger.Error("storage.Invoice.GetByID() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	if err := actor.Authorize(ctx, s.services.Organization, storagei.OrganizationID, permission); err == serverrors.ErrOrganizationNotFound {
+		return nil, serverrors.ErrInvoiceNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return storagei, nil
+}
+
+// authorizeGet checks the actor may get the invoices of the organization of
+// the given parameters. Only the system may leave the organization unset.
+func (s *Service) authorizeGet(ctx context.Context, params *proto.InvoiceGetParams) error {
+	var organizationID uint
+	if params.OrganizationID != nil {
+		organizationID = *params.OrganizationID
+	}
+
+	return actor.Authorize(ctx, s.services.Organization, organizationID, proto.OrganizationPermissionInvoiceRead)
+}
+
+// createParamsToStorage handles mapping validated invoice create parameters
+// to a new storage invoice, calculating its amounts.
+func (s *Service) createParamsToStorage(params *proto.InvoiceCreateParams) (*invoice.Invoice, error) {
+	// Handle line items.
+	lineItems := []invoice.LineItem{}
+	for _, v := range params.LineItems {
+		lineItem := invoice.LineItem{
+			Name:        v.Name,
+			Description: v.Description,
+			Quantity:    v.Quantity,
+			Price:       v.Price,
+		}
+
+		lineItems = append(lineItems, lineItem)
+	}
+
+	// Calculate invoice amounts.
+	amounts, err := CalculateAmounts(CalculateAmountsParams{
+		LineItems: params.LineItems,
+		TaxRate:   params.TaxRate,
+	})
+	if err != nil {
+		s.logger.Error("CalculateAmounts() error",
+			slog.Any("error", err))
+		return nil, serverrors.ErrInvoiceCalculatingAmounts
+	}
+
+	// Handle payment methods.
+	paymentMethods := []string{}
+	for _, v := range params.PaymentMethods {
+		paymentMethods = append(paymentMethods, string(v))
+	}
+
+	return &invoice.Invoice{
+		ID:             params.ID,
+		OrganizationID: params.OrganizationID,
+		UserID:         params.UserID,
+		PublicHash:     uuid.New().String(),
+		InvoiceNumber:  params.InvoiceNumber,
+		PONumber:       params.PONumber,
+		Currency:       params.Currency,
+		DueDate:        params.DueDate,
+		Message:        params.Message,
+		BillTo: invoice.BillTo{
+			FirstName:    params.BillTo.FirstName,
+			LastName:     params.BillTo.LastName,
+			Company:      params.BillTo.Company,
+			AddressLine1: params.BillTo.AddressLine1,
+			AddressLine2: params.BillTo.AddressLine2,
+			City:         params.BillTo.City,
+			State:        params.BillTo.State,
+			PostalCode:   params.BillTo.PostalCode,
+			Country:      params.BillTo.Country,
+			Email:        params.BillTo.Email,
+			Phone:        params.BillTo.Phone,
+		},
+		PayTo: invoice.PayTo{
+			FirstName:    params.PayTo.FirstName,
+			LastName:     params.PayTo.LastName,
+			Company:      params.PayTo.Company,
+			AddressLine1: params.PayTo.AddressLine1,
+			AddressLine2: params.PayTo.AddressLine2,
+			City:         params.PayTo.City,
+			State:        params.PayTo.State,
+			PostalCode:   params.PayTo.PostalCode,
+			Country:      params.PayTo.Country,
+			Email:        params.PayTo.Email,
+			Phone:        params.PayTo.Phone,
+		},
+		LineItems:      lineItems,
+		PaymentMethods: paymentMethods,
+		TaxRate:        params.TaxRate,
+		AmountDue:      amounts.AmountDue,
+		AmountPaid:     0,
+		Status:         "pending",
+		CreatedAt:      time.Now().UTC(),
+	}, nil
+}
+
 // storageLineItemsToProto handles mappings the storage invoice line items type
 // to the proto invoice line items type.
 func storageLineItemsToProto(li []invoice.LineItem) []proto.InvoiceLineItem {
@@ -602,14 +877,15 @@ func storageToProto(s *invoice.Invoice) *proto.Invoice {
 	}
 
 	return &proto.Invoice{
-		ID:            s.ID,
-		UserID:        s.UserID,
-		PublicHash:    s.PublicHash,
-		InvoiceNumber: s.InvoiceNumber,
-		PONumber:      s.PONumber,
-		Currency:      s.Currency,
-		DueDate:       s.DueDate,
-		Message:       s.Message,
+		ID:             s.ID,
+		OrganizationID: s.OrganizationID,
+		UserID:         s.UserID,
+		PublicHash:     s.PublicHash,
+		InvoiceNumber:  s.InvoiceNumber,
+		PONumber:       s.PONumber,
+		Currency:       s.Currency,
+		DueDate:        s.DueDate,
+		Message:        s.Message,
 		BillTo: proto.InvoiceBillTo{
 			FirstName:    s.BillTo.FirstName,
 			LastName:     s.BillTo.LastName,
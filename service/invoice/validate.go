@@ -1,14 +1,16 @@
 package invoice
 
 import (
+	"context"
 	"errors"
+	"strconv"
 
 	"dddstructure/proto"
 	serverrors "dddstructure/service/errors"
 )
 
 // ValidateCreateParams validates the create parameters.
-func (s *Service) ValidateCreateParams(params *proto.InvoiceCreateParams) error {
+func (s *Service) ValidateCreateParams(ctx context.Context, params *proto.InvoiceCreateParams) error {
 	// Create a new ParamErrors.
 	pes := serverrors.NewParamErrors()
 
@@ -34,6 +36,13 @@ func (s *Service) ValidateCreateParams(params *proto.InvoiceCreateParams) error
 		}
 	}
 
+	// Check tax rate.
+	if params.TaxRate != "" {
+		if _, err := strconv.ParseFloat(params.TaxRate, 64); err != nil {
+			pes.Add(serverrors.NewParamError("tax_rate", serverrors.ErrInvoiceTaxRateInvalid))
+		}
+	}
+
 	// Return if there were parameter errors.
 	if pes.Length() > 0 {
 		return pes
@@ -43,7 +52,7 @@ func (s *Service) ValidateCreateParams(params *proto.InvoiceCreateParams) error
 }
 
 // ValidateGetParams validates the get parameters.
-func (s *Service) ValidateGetParams(params *proto.InvoiceGetParams) error {
+func (s *Service) ValidateGetParams(ctx context.Context, params *proto.InvoiceGetParams) error {
 	// Create a new ParamErrors.
 	pes := serverrors.NewParamErrors()
 
@@ -58,7 +67,7 @@ func (s *Service) ValidateGetParams(params *proto.InvoiceGetParams) error {
 }
 
 // ValidateUpdateParams validates the update parameters.
-func (s *Service) ValidateUpdateParams(params *proto.InvoiceUpdateParams) error {
+func (s *Service) ValidateUpdateParams(ctx context.Context, params *proto.InvoiceUpdateParams) error {
 	// Create a new ParamErrors.
 	pes := serverrors.NewParamErrors()
 
@@ -83,6 +92,13 @@ func (s *Service) ValidateUpdateParams(params *proto.InvoiceUpdateParams) error
 		}
 	}
 
+	// Check tax rate.
+	if params.TaxRate != nil && *params.TaxRate != "" {
+		if _, err := strconv.ParseFloat(*params.TaxRate, 64); err != nil {
+			pes.Add(serverrors.NewParamError("tax_rate", serverrors.ErrInvoiceTaxRateInvalid))
+		}
+	}
+
 	// Return if there were parameter errors.
 	if pes.Length() > 0 {
 		return pes
@@ -92,7 +108,7 @@ func (s *Service) ValidateUpdateParams(params *proto.InvoiceUpdateParams) error
 }
 
 // ValidateUpdateForTransactionParams validates the update parameters.
-func (s *Service) ValidateUpdateForTransactionParams(params *proto.InvoiceUpdateForTransactionParams) error {
+func (s *Service) ValidateUpdateForTransactionParams(ctx context.Context, params *proto.InvoiceUpdateForTransactionParams) error {
 	// Create a new ParamErrors.
 	pes := serverrors.NewParamErrors()
 
@@ -112,7 +128,7 @@ func (s *Service) ValidateUpdateForTransactionParams(params *proto.InvoiceUpdate
 }
 
 // ValidatePayParams validates the pay parameters.
-func (s *Service) ValidatePayParams(params *proto.InvoicePayParams) error {
+func (s *Service) ValidatePayParams(ctx context.Context, params *proto.InvoicePayParams) error {
 	// Create a new ParamErrors.
 	pes := serverrors.NewParamErrors()
 
@@ -0,0 +1,543 @@
+package organization
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"dddstructure/mail"
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/service/interfaces"
+	"dddstructure/storage"
+	"dddstructure/storage/organization"
+	"dddstructure/trace"
+	"dddstructure/utils"
+)
+
+// invitationExpiry defines how long an invitation is valid for.
+const invitationExpiry = 7 * 24 * time.Hour
+
+// rolePermissions defines the permissions granted by each role.
+//
+// Owners and admins can do everything, except that only owners can grant,
+// change or remove the owner role. Accountants manage invoices and
+// transactions, and read only members can only read.
+var rolePermissions = map[proto.OrganizationRole][]proto.OrganizationPermission{
+	proto.OrganizationRoleOwner: {
+		proto.OrganizationPermissionRead,
+		proto.OrganizationPermissionMembersWrite,
+		proto.OrganizationPermissionInvoiceRead,
+		proto.OrganizationPermissionInvoiceWrite,
+		proto.OrganizationPermissionTransaction,
+		proto.OrganizationPermissionReportRead,
+	},
+	proto.OrganizationRoleAdmin: {
+		proto.OrganizationPermissionRead,
+		proto.OrganizationPermissionMembersWrite,
+		proto.OrganizationPermissionInvoiceRead,
+		proto.OrganizationPermissionInvoiceWrite,
+		proto.OrganizationPermissionTransaction,
+		proto.OrganizationPermissionReportRead,
+	},
+	proto.OrganizationRoleAccountant: {
+		proto.OrganizationPermissionRead,
+		proto.OrganizationPermissionInvoiceRead,
+		proto.OrganizationPermissionInvoiceWrite,
+		proto.OrganizationPermissionTransaction,
+		proto.OrganizationPermissionReportRead,
+	},
+	proto.OrganizationRoleReadOnly: {
+		proto.OrganizationPermissionRead,
+		proto.OrganizationPermissionInvoiceRead,
+		proto.OrganizationPermissionReportRead,
+	},
+}
+
+// Service defines the organization service.
+type Service struct {
+	storage  *storage.Storage
+	services *interfaces.Service
+	mailer   mail.Sender
+	logger   *slog.Logger
+}
+
+// SetServices sets the services interface.
+func (s *Service) SetServices(services *interfaces.Service) {
+	s.services = services
+}
+
+// WithLogger returns a copy of the service that logs to the given logger.
+func (s *Service) WithLogger(l *slog.Logger) *Service {
+	c := *s
+	c.logger = l
+	return &c
+}
+
+// New creates a new service.
+func New(s *storage.Storage, m mail.Sender, l *slog.Logger) *Service {
+	return &Service{
+		storage: s,
+		mailer:  m,
+		logger:  l,
+	}
+}
+
+// Create creates a new organization, with the given user as its owner.
+func (s *Service) Create(ctx context.Context, params *proto.OrganizationCreateParams) (*proto.Organization, error) {
+	ctx, span := trace.Start(ctx, "service.Organization.Create")
+	defer span.End()
+
+	// Validate parameters.
+	if err := s.ValidateCreateParams(ctx, params); err != nil {
+		return nil, err
+	}
+
+	// Create the organization, with the user as its owner.
+	now := time.Now().UTC()
+	storageo, err := s.storage.Organization.Create(ctx, &organization.Organization{
+		ID:        params.ID,
+		Name:      params.Name,
+		CreatedAt: now,
+	}, &organization.Member{
+		UserID:    params.UserID,
+		Role:      string(proto.OrganizationRoleOwner),
+		CreatedAt: now,
+	})
+	if err != nil {
+		s.logger.Error("storage.Organization.Create() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Map to service type.
+	serviceo := storageToProto(storageo)
+	serviceo.Role = proto.OrganizationRoleOwner
+
+	return serviceo, nil
+}
+
+// GetByID gets an organization by the given ID.
+func (s *Service) GetByID(ctx context.Context, id uint) (*proto.Organization, error) {
+	ctx, span := trace.Start(ctx, "service.Organization.GetByID")
+	defer span.End()
+
+	// Get organization by ID.
+	storageo, err := s.storage.Organization.GetByID(ctx, id)
+	if err == organization.ErrOrganizationNotFound {
+		return nil, serverrors.ErrOrganizationNotFound
+	} else if err != nil {
+		s.logger.Error("storage.Organization.GetByID() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	return storageToProto(storageo), nil
+}
+
+// GetForUser gets the organizations a user is a member of, along with their
+// role in each, ordered by ID.
+func (s *Service) GetForUser(ctx context.Context, userID uint) ([]*proto.Organization, error) {
+	ctx, span := trace.Start(ctx, "service.Organization.GetForUser")
+	defer span.End()
+
+	// Get the organizations of the user.
+	storagem, err := s.storage.Organization.GetByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("storage.Organization.GetByUserID() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Map to service type.
+	serviceo := []*proto.Organization{}
+	for _, v := range storagem {
+		o := storageToProto(&v.Organization)
+		o.Role = proto.OrganizationRole(v.Role)
+		serviceo = append(serviceo, o)
+	}
+
+	return serviceo, nil
+}
+
+// Authorize checks if a user is a member of an organization with a role that
+// grants the given permission, and returns their membership.
+//
+// ErrOrganizationNotFound is returned if the user is not a member, and
+// ErrOrganizationForbidden if their role does not grant the permission.
+func (s *Service) Authorize(ctx context.Context, params *proto.OrganizationAuthorizeParams) (*proto.OrganizationMember, error) {
+	ctx, span := trace.Start(ctx, "service.Organization.Authorize")
+	defer span.End()
+
+	// Get the member.
+	member, err := s.getMember(ctx, params.OrganizationID, params.UserID)
+	if err == serverrors.ErrOrganizationMemberNotFound {
+		return nil, serverrors.ErrOrganizationNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Check the permission.
+	if !HasPermission(member.Role, params.Permission) {
+		return nil, serverrors.ErrOrganizationForbidden
+	}
+
+	return member, nil
+}
+
+// GetMembers gets the members of an organization, ordered by when they
+// joined.
+func (s *Service) GetMembers(ctx context.Context, organizationID uint) ([]*proto.OrganizationMember, error) {
+	ctx, span := trace.Start(ctx, "service.Organization.GetMembers")
+	defer span.End()
+
+	// Get members from storage.
+	storagem, err := s.storage.Organization.GetMembers(ctx, organizationID)
+	if err != nil {
+		s.logger.Error("storage.Organization.GetMembers() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Map to service type, adding the email of each member.
+	servicem := []*proto.OrganizationMember{}
+	for _, v := range storagem {
+		member := memberStorageToProto(v)
+
+		u, err := s.services.User.GetByID(ctx, v.UserID)
+		if err != nil && err != serverrors.ErrUserNotFound {
+			return nil, err
+		} else if err == nil {
+			member.Email = u.Email
+		}
+
+		servicem = append(servicem, member)
+	}
+
+	return servicem, nil
+}
+
+// UpdateMember handles changing the role of an organization member.
+//
+// Only owners can grant the owner role or change the role of another owner,
+// and the last owner can't be changed to another role.
+func (s *Service) UpdateMember(ctx context.Context, params *proto.OrganizationUpdateMemberParams) (*proto.OrganizationMember, error) {
+	ctx, span := trace.Start(ctx, "service.Organization.UpdateMember")
+	defer span.End()
+
+	// Validate parameters.
+	if err := s.ValidateUpdateMemberParams(ctx, params); err != nil {
+		return nil, err
+	}
+
+	// Get the member making the change.
+	by, err := s.getMember(ctx, params.OrganizationID, params.ByUserID)
+	if err == serverrors.ErrOrganizationMemberNotFound {
+		return nil, serverrors.ErrOrganizationNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Get the member.
+	member, err := s.getMember(ctx, params.OrganizationID, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the owner role.
+	if (params.Role == proto.OrganizationRoleOwner || member.Role == proto.OrganizationRoleOwner) && by.Role != proto.OrganizationRoleOwner {
+		return nil, serverrors.ErrOrganizationForbidden
+	}
+
+	if member.Role == proto.OrganizationRoleOwner && params.Role != proto.OrganizationRoleOwner {
+		if err := s.checkOtherOwner(ctx, params.OrganizationID, params.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Update the member.
+	storagem, err := s.storage.Organization.UpdateMember(ctx, &organization.Member{
+		OrganizationID: member.OrganizationID,
+		UserID:         member.UserID,
+		Role:           string(params.Role),
+		CreatedAt:      member.CreatedAt,
+	})
+	if err != nil {
+		s.logger.Error("storage.Organization.UpdateMember() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	return memberStorageToProto(storagem), nil
+}
+
+// RemoveMember handles removing a member from an organization.
+//
+// Only owners can remove another owner, and the last owner can't be removed.
+func (s *Service) RemoveMember(ctx context.Context, params *proto.OrganizationRemoveMemberParams) error {
+	ctx, span := trace.Start(ctx, "service.Organization.RemoveMember")
+	defer span.End()
+
+	// Get the member making the change.
+	by, err := s.getMember(ctx, params.OrganizationID, params.ByUserID)
+	if err == serverrors.ErrOrganizationMemberNotFound {
+		return serverrors.ErrOrganizationNotFound
+	} else if err != nil {
+		return err
+	}
+
+	// Get the member.
+	member, err := s.getMember(ctx, params.OrganizationID, params.UserID)
+	if err != nil {
+		return err
+	}
+
+	// Check the owner role.
+	if member.Role == proto.OrganizationRoleOwner {
+		if by.Role != proto.OrganizationRoleOwner {
+			return serverrors.ErrOrganizationForbidden
+		}
+
+		if err := s.checkOtherOwner(ctx, params.OrganizationID, params.UserID); err != nil {
+			return err
+		}
+	}
+
+	// Delete the member.
+	if err := s.storage.Organization.DeleteMember(ctx, params.OrganizationID, params.UserID); err != nil {
+		s.logger.Error("storage.Organization.DeleteMember() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+// Invite handles sending an invitation to join an organization to an email.
+//
+// Only owners can invite someone as an owner.
+func (s *Service) Invite(ctx context.Context, params *proto.OrganizationInviteParams) error {
+	ctx, span := trace.Start(ctx, "service.Organization.Invite")
+	defer span.End()
+
+	// Validate parameters.
+	if err := s.ValidateInviteParams(ctx, params); err != nil {
+		return err
+	}
+
+	// Get the member sending the invitation.
+	by, err := s.getMember(ctx, params.OrganizationID, params.ByUserID)
+	if err == serverrors.ErrOrganizationMemberNotFound {
+		return serverrors.ErrOrganizationNotFound
+	} else if err != nil {
+		return err
+	}
+
+	// Check the owner role.
+	if params.Role == proto.OrganizationRoleOwner && by.Role != proto.OrganizationRoleOwner {
+		return serverrors.ErrOrganizationForbidden
+	}
+
+	// Check if a user with this email is already a member.
+	u, err := s.services.User.GetByEmail(ctx, params.Email)
+	if err != nil && err != serverrors.ErrUserNotFound {
+		return err
+	} else if err == nil {
+		_, err := s.getMember(ctx, params.OrganizationID, u.ID)
+		if err == nil {
+			return serverrors.NewParamErrors(serverrors.NewParamError("email", serverrors.ErrOrganizationMemberExists))
+		} else if err != serverrors.ErrOrganizationMemberNotFound {
+			return err
+		}
+	}
+
+	// Get the organization.
+	serviceo, err := s.GetByID(ctx, params.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	// Generate the invitation token.
+	token, hash, err := utils.NewToken()
+	if err != nil {
+		s.logger.Error("error generating invitation token",
+			slog.Any("error", err))
+		return err
+	}
+
+	// Create the invitation.
+	now := time.Now().UTC()
+	if _, err := s.storage.Organization.CreateInvitation(ctx, &organization.Invitation{
+		Hash:           hash,
+		OrganizationID: params.OrganizationID,
+		Email:          params.Email,
+		Role:           string(params.Role),
+		InvitedBy:      params.ByUserID,
+		ExpiresAt:      now.Add(invitationExpiry),
+		CreatedAt:      now,
+	}); err != nil {
+		s.logger.Error("storage.Organization.CreateInvitation() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	// Send the invitation.
+	if err := s.mailer.Send(&mail.Message{
+		To:      params.Email,
+		Subject: "You have been invited to join " + serviceo.Name,
+		Body: "You have been invited to join " + serviceo.Name + ". Sign up or log in with this email, then use the link below within the next 7 days to accept:\n\n" +
+			utils.TokenLink(params.Link, token) + "\n",
+	}); err != nil {
+		s.logger.Error("mailer.Send() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+// AcceptInvitation handles adding a user to an organization using an
+// invitation token.
+//
+// The invitation can only be accepted by the user with the email it was sent
+// to.
+func (s *Service) AcceptInvitation(ctx context.Context, params *proto.OrganizationAcceptInvitationParams) (*proto.OrganizationMember, error) {
+	ctx, span := trace.Start(ctx, "service.Organization.AcceptInvitation")
+	defer span.End()
+
+	// Validate parameters.
+	if err := s.ValidateAcceptInvitationParams(ctx, params); err != nil {
+		return nil, err
+	}
+
+	invalid := serverrors.NewParamErrors(serverrors.NewParamError("token", serverrors.ErrOrganizationInvitationInvalid))
+
+	// Get the invitation.
+	storagei, err := s.storage.Organization.GetInvitationByHash(ctx, utils.HashToken(params.Token))
+	if err == organization.ErrInvitationNotFound {
+		return nil, invalid
+	} else if err != nil {
+		s.logger.Error("storage.Organization.GetInvitationByHash() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Check the email.
+	u, err := s.services.User.GetByID(ctx, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(u.Email, storagei.Email) {
+		return nil, invalid
+	}
+
+	// Delete the invitation. Only one request can delete it, so an
+	// invitation used by two requests at once still only works once.
+	err = s.storage.Organization.DeleteInvitation(ctx, storagei.Hash)
+	if err == organization.ErrInvitationNotFound {
+		return nil, invalid
+	} else if err != nil {
+		s.logger.Error("storage.Organization.DeleteInvitation() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Check expiry.
+	if time.Now().After(storagei.ExpiresAt) {
+		return nil, invalid
+	}
+
+	// Check if the user is already a member.
+	_, err = s.getMember(ctx, storagei.OrganizationID, u.ID)
+	if err == nil {
+		return nil, serverrors.NewParamErrors(serverrors.NewParamError("token", serverrors.ErrOrganizationMemberExists))
+	} else if err != serverrors.ErrOrganizationMemberNotFound {
+		return nil, err
+	}
+
+	// Add the member.
+	storagem, err := s.storage.Organization.CreateMember(ctx, &organization.Member{
+		OrganizationID: storagei.OrganizationID,
+		UserID:         u.ID,
+		Role:           storagei.Role,
+		CreatedAt:      time.Now().UTC(),
+	})
+	if err != nil {
+		s.logger.Error("storage.Organization.CreateMember() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	servicem := memberStorageToProto(storagem)
+	servicem.Email = u.Email
+
+	return servicem, nil
+}
+
+// HasPermission returns if a role grants the given permission.
+func HasPermission(role proto.OrganizationRole, permission proto.OrganizationPermission) bool {
+	for _, v := range rolePermissions[role] {
+		if v == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getMember gets the member of an organization by the given user ID.
+func (s *Service) getMember(ctx context.Context, organizationID, userID uint) (*proto.OrganizationMember, error) {
+	storagem, err := s.storage.Organization.GetMember(ctx, organizationID, userID)
+	if err == organization.ErrMemberNotFound {
+		return nil, serverrors.ErrOrganizationMemberNotFound
+	} else if err != nil {
+		s.logger.Error("storage.Organization.GetMember() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	return memberStorageToProto(storagem), nil
+}
+
+// checkOtherOwner checks if an organization has an owner other than the
+// given user.
+//
+// ErrOrganizationLastOwner is returned if it does not.
+func (s *Service) checkOtherOwner(ctx context.Context, organizationID, userID uint) error {
+	storagem, err := s.storage.Organization.GetMembers(ctx, organizationID)
+	if err != nil {
+		s.logger.Error("storage.Organization.GetMembers() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	for _, v := range storagem {
+		if v.UserID != userID && v.Role == string(proto.OrganizationRoleOwner) {
+			return nil
+		}
+	}
+
+	return serverrors.ErrOrganizationLastOwner
+}
+
+// storageToProto handles mapping a storage organization to a service
+// organization.
+func storageToProto(o *organization.Organization) *proto.Organization {
+	return &proto.Organization{
+		ID:        o.ID,
+		Name:      o.Name,
+		CreatedAt: o.CreatedAt,
+	}
+}
+
+// memberStorageToProto handles mapping a storage member to a service member.
+func memberStorageToProto(m *organization.Member) *proto.OrganizationMember {
+	return &proto.OrganizationMember{
+		OrganizationID: m.OrganizationID,
+		UserID:         m.UserID,
+		Role:           proto.OrganizationRole(m.Role),
+		CreatedAt:      m.CreatedAt,
+	}
+}
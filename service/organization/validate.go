@@ -0,0 +1,90 @@
+package organization
+
+import (
+	"context"
+	"dddstructure/proto"
+	"dddstructure/service/errors"
+)
+
+// ValidateCreateParams validates the create parameters.
+func (s *Service) ValidateCreateParams(ctx context.Context, params *proto.OrganizationCreateParams) error {
+	// Create a new ParamErrors.
+	pes := errors.NewParamErrors()
+
+	// Check name.
+	if params.Name == "" {
+		pes.Add(errors.NewParamError("name", errors.ErrOrganizationNameEmpty))
+	}
+
+	// Return if there were parameter errors.
+	if pes.Length() > 0 {
+		return pes
+	}
+
+	return nil
+}
+
+// ValidateUpdateMemberParams validates the update member parameters.
+func (s *Service) ValidateUpdateMemberParams(ctx context.Context, params *proto.OrganizationUpdateMemberParams) error {
+	// Create a new ParamErrors.
+	pes := errors.NewParamErrors()
+
+	// Check role.
+	if !validRole(params.Role) {
+		pes.Add(errors.NewParamError("role", errors.ErrOrganizationRoleInvalid))
+	}
+
+	// Return if there were parameter errors.
+	if pes.Length() > 0 {
+		return pes
+	}
+
+	return nil
+}
+
+// ValidateInviteParams validates the invite parameters.
+func (s *Service) ValidateInviteParams(ctx context.Context, params *proto.OrganizationInviteParams) error {
+	// Create a new ParamErrors.
+	pes := errors.NewParamErrors()
+
+	// Check email.
+	if params.Email == "" {
+		pes.Add(errors.NewParamError("email", errors.ErrUserEmailEmpty))
+	}
+
+	// Check role.
+	if !validRole(params.Role) {
+		pes.Add(errors.NewParamError("role", errors.ErrOrganizationRoleInvalid))
+	}
+
+	// Return if there were parameter errors.
+	if pes.Length() > 0 {
+		return pes
+	}
+
+	return nil
+}
+
+// ValidateAcceptInvitationParams validates the accept invitation parameters.
+func (s *Service) ValidateAcceptInvitationParams(ctx context.Context, params *proto.OrganizationAcceptInvitationParams) error {
+	// Create a new ParamErrors.
+	pes := errors.NewParamErrors()
+
+	// Check token.
+	if params.Token == "" {
+		pes.Add(errors.NewParamError("token", errors.ErrOrganizationInvitationInvalid))
+	}
+
+	// Return if there were parameter errors.
+	if pes.Length() > 0 {
+		return pes
+	}
+
+	return nil
+}
+
+// validRole returns if the given role exists.
+func validRole(role proto.OrganizationRole) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
@@ -0,0 +1,97 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"dddstructure/service/actor"
+	"dddstructure/trace"
+)
+
+// requestTimeout defines the timeout of each call to a remote service.
+const requestTimeout = 30 * time.Second
+
+// Client defines a client of the services served by a Server.
+type Client struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewClient creates a new client of the server at the given base URL, such
+// as "http://invoice:8081". The token is sent with each call and must match
+// the token of the server.
+func NewClient(url, token string) *Client {
+	return &Client{
+		url:    strings.TrimSuffix(url, "/"),
+		token:  token,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// call posts the given arguments to the given method, such as
+// "Invoice.GetByID", and decodes its result into result, which may be nil
+// if the method only returns an error.
+//
+// The error returned by the method is returned as is, and any other error,
+// such as the server not responding, is returned wrapped with the method.
+func (c *Client) call(ctx context.Context, method string, args, result interface{}) error {
+	// Trace the call, and have the server join the trace.
+	ctx, span := trace.StartKind(ctx, "remote "+method, trace.SpanKindClient)
+	defer span.End()
+
+	body, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("remote %s: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/"+strings.Replace(method, ".", "/", 1), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("remote %s: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if span != nil {
+		req.Header.Set(trace.TraceparentHeader, span.SpanContext().Traceparent())
+	}
+	if a, ok := actor.FromContext(ctx); ok {
+		req.Header.Set(actor.Header, a.String())
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		span.SetError(err)
+		return fmt.Errorf("remote %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		err := fmt.Errorf("remote %s: server responded with status %d", method, resp.StatusCode)
+		span.SetError(err)
+		return err
+	}
+
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("remote %s: %w", method, err)
+	}
+
+	if r.Error != nil {
+		return decodeError(r.Error)
+	}
+
+	if result != nil && r.Result != nil {
+		if err := json.Unmarshal(r.Result, result); err != nil {
+			return fmt.Errorf("remote %s: %w", method, err)
+		}
+	}
+
+	return nil
+}
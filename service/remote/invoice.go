@@ -0,0 +1,216 @@
+package remote
+
+import (
+	"context"
+
+	"dddstructure/proto"
+	"dddstructure/service/interfaces"
+)
+
+// idArgs defines the arguments of the methods taking an ID.
+type idArgs struct {
+	ID uint `json:"id"`
+}
+
+// invoiceGetByIDAndOrganizationIDArgs defines the arguments of
+// Invoice.GetByIDAndOrganizationID.
+type invoiceGetByIDAndOrganizationIDArgs struct {
+	ID             uint `json:"id"`
+	OrganizationID uint `json:"organization_id"`
+}
+
+// invoiceGetByPublicHashArgs defines the arguments of Invoice.GetByPublicHash.
+type invoiceGetByPublicHashArgs struct {
+	Hash string `json:"hash"`
+}
+
+// invoicePayArgs defines the arguments of Invoice.Pay.
+type invoicePayArgs struct {
+	ID     uint                    `json:"id"`
+	Params *proto.InvoicePayParams `json:"params"`
+}
+
+// invoiceImportResult defines an invoice import result, as the error of
+// each invoice is sent like the error of a call.
+type invoiceImportResult struct {
+	Invoice *proto.Invoice `json:"invoice"`
+	Error   *wireError     `json:"error,omitempty"`
+}
+
+// Invoice defines a client of a remote invoice service, which implements
+// the interfaces.Invoice interface.
+type Invoice struct {
+	c *Client
+}
+
+// NewInvoice creates a new client of the invoice service of the given
+// client's server.
+func NewInvoice(c *Client) *Invoice {
+	return &Invoice{
+		c: c,
+	}
+}
+
+// Create calls Invoice.Create.
+func (i *Invoice) Create(ctx context.Context, params *proto.InvoiceCreateParams) (*proto.Invoice, error) {
+	var inv *proto.Invoice
+	if err := i.c.call(ctx, "Invoice.Create", params, &inv); err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// Get calls Invoice.Get.
+func (i *Invoice) Get(ctx context.Context, params *proto.InvoiceGetParams) ([]*proto.Invoice, error) {
+	var invs []*proto.Invoice
+	if err := i.c.call(ctx, "Invoice.Get", params, &invs); err != nil {
+		return nil, err
+	}
+
+	return invs, nil
+}
+
+// GetCount calls Invoice.GetCount.
+func (i *Invoice) GetCount(ctx context.Context, params *proto.InvoiceGetParams) (uint, error) {
+	var count uint
+	if err := i.c.call(ctx, "Invoice.GetCount", params, &count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetByID calls Invoice.GetByID.
+func (i *Invoice) GetByID(ctx context.Context, id uint) (*proto.Invoice, error) {
+	var inv *proto.Invoice
+	if err := i.c.call(ctx, "Invoice.GetByID", &idArgs{ID: id}, &inv); err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// GetByIDAndOrganizationID calls Invoice.GetByIDAndOrganizationID.
+func (i *Invoice) GetByIDAndOrganizationID(ctx context.Context, id, organizationID uint) (*proto.Invoice, error) {
+	var inv *proto.Invoice
+	if err := i.c.call(ctx, "Invoice.GetByIDAndOrganizationID", &invoiceGetByIDAndOrganizationIDArgs{ID: id, OrganizationID: organizationID}, &inv); err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// GetByPublicHash calls Invoice.GetByPublicHash.
+func (i *Invoice) GetByPublicHash(ctx context.Context, hash string) (*proto.Invoice, error) {
+	var inv *proto.Invoice
+	if err := i.c.call(ctx, "Invoice.GetByPublicHash", &invoiceGetByPublicHashArgs{Hash: hash}, &inv); err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// Update calls Invoice.Update.
+func (i *Invoice) Update(ctx context.Context, params *proto.InvoiceUpdateParams) (*proto.Invoice, error) {
+	var inv *proto.Invoice
+	if err := i.c.call(ctx, "Invoice.Update", params, &inv); err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// UpdateForOrganization calls Invoice.UpdateForOrganization.
+func (i *Invoice) UpdateForOrganization(ctx context.Context, params *proto.InvoiceUpdateParams) (*proto.Invoice, error) {
+	var inv *proto.Invoice
+	if err := i.c.call(ctx, "Invoice.UpdateForOrganization", params, &inv); err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// UpdateForTransaction calls Invoice.UpdateForTransaction.
+func (i *Invoice) UpdateForTransaction(ctx context.Context, params *proto.InvoiceUpdateForTransactionParams) (*proto.Invoice, error) {
+	var inv *proto.Invoice
+	if err := i.c.call(ctx, "Invoice.UpdateForTransaction", params, &inv); err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// Delete calls Invoice.Delete.
+func (i *Invoice) Delete(ctx context.Context, id uint) error {
+	return i.c.call(ctx, "Invoice.Delete", &idArgs{ID: id}, nil)
+}
+
+// Pay calls Invoice.Pay.
+func (i *Invoice) Pay(ctx context.Context, id uint, params *proto.InvoicePayParams) (*proto.Invoice, error) {
+	var inv *proto.Invoice
+	if err := i.c.call(ctx, "Invoice.Pay", &invoicePayArgs{ID: id, Params: params}, &inv); err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// Import calls Invoice.Import.
+func (i *Invoice) Import(ctx context.Context, params *proto.InvoiceImportParams) ([]*proto.InvoiceImportResult, error) {
+	var irs []*invoiceImportResult
+	if err := i.c.call(ctx, "Invoice.Import", params, &irs); err != nil {
+		return nil, err
+	}
+
+	results := make([]*proto.InvoiceImportResult, len(irs))
+	for n, ir := range irs {
+		results[n] = &proto.InvoiceImportResult{
+			Invoice: ir.Invoice,
+			Error:   decodeError(ir.Error),
+		}
+	}
+
+	return results, nil
+}
+
+// RegisterInvoice serves the given invoice service.
+func (s *Server) RegisterInvoice(i interfaces.Invoice) {
+	handle(s, "Invoice.Create", i.Create)
+	handle(s, "Invoice.Get", i.Get)
+	handle(s, "Invoice.GetCount", i.GetCount)
+	handle(s, "Invoice.GetByID", func(ctx context.Context, args *idArgs) (*proto.Invoice, error) {
+		return i.GetByID(ctx, args.ID)
+	})
+	handle(s, "Invoice.GetByIDAndOrganizationID", func(ctx context.Context, args *invoiceGetByIDAndOrganizationIDArgs) (*proto.Invoice, error) {
+		return i.GetByIDAndOrganizationID(ctx, args.ID, args.OrganizationID)
+	})
+	handle(s, "Invoice.GetByPublicHash", func(ctx context.Context, args *invoiceGetByPublicHashArgs) (*proto.Invoice, error) {
+		return i.GetByPublicHash(ctx, args.Hash)
+	})
+	handle(s, "Invoice.Update", i.Update)
+	handle(s, "Invoice.UpdateForOrganization", i.UpdateForOrganization)
+	handle(s, "Invoice.UpdateForTransaction", i.UpdateForTransaction)
+	handle(s, "Invoice.Delete", func(ctx context.Context, args *idArgs) (interface{}, error) {
+		return nil, i.Delete(ctx, args.ID)
+	})
+	handle(s, "Invoice.Pay", func(ctx context.Context, args *invoicePayArgs) (*proto.Invoice, error) {
+		return i.Pay(ctx, args.ID, args.Params)
+	})
+	handle(s, "Invoice.Import", func(ctx context.Context, args *proto.InvoiceImportParams) ([]*invoiceImportResult, error) {
+		results, err := i.Import(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+
+		irs := make([]*invoiceImportResult, len(results))
+		for n, r := range results {
+			irs[n] = &invoiceImportResult{
+				Invoice: r.Invoice,
+				Error:   encodeError(r.Error),
+			}
+		}
+
+		return irs, nil
+	})
+}
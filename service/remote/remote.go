@@ -0,0 +1,151 @@
+// Package remote calls the invoice, transaction and user services of another
+// process over HTTP/JSON, and serves them to other processes.
+//
+// Each method is posted to /<Service>/<Method>, such as /Invoice/GetByID,
+// with its arguments as a JSON object. The response holds either the result
+// or the error of the method. Errors of the services are sent with their
+// message and the name of each parameter they are for, so the client returns
+// the same serverrors values and *serverrors.ParamErrors the service did.
+// The actor of each call is sent in the X-Actor header, so the service checks
+// the permissions of the same user as the caller.
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+
+	serverrors "dddstructure/service/errors"
+)
+
+// Error defines an error returned by a remote service that is not one of the
+// service errors, such as an unexpected storage error.
+type Error struct {
+	Message string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// response defines the response to each call.
+type response struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *wireError      `json:"error,omitempty"`
+}
+
+// wireError defines an error as it is sent between processes. Params is only
+// set for *serverrors.ParamErrors.
+type wireError struct {
+	Message string           `json:"message"`
+	Params  []wireParamError `json:"params,omitempty"`
+}
+
+// wireParamError defines a single parameter error.
+type wireParamError struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// serviceErrors defines the service errors returned as the same value by the
+// client, by message.
+var serviceErrors = map[string]error{}
+
+func init() {
+	for _, err := range []error{
+		serverrors.ErrInvoiceNotFound,
+		serverrors.ErrInvoiceLineItemRequired,
+		serverrors.ErrInvoicePaymentMethodRequired,
+		serverrors.ErrInvoicePaymentMethodInvalid,
+		serverrors.ErrInvoiceTaxRateInvalid,
+		serverrors.ErrInvoiceAmountDueLimit,
+		serverrors.ErrInvoiceCalculatingAmounts,
+		serverrors.ErrInvoiceStatusNotPending,
+		serverrors.ErrOrganizationNotFound,
+		serverrors.ErrOrganizationNameEmpty,
+		serverrors.ErrOrganizationForbidden,
+		serverrors.ErrOrganizationRoleInvalid,
+		serverrors.ErrOrganizationMemberNotFound,
+		serverrors.ErrOrganizationMemberExists,
+		serverrors.ErrOrganizationLastOwner,
+		serverrors.ErrOrganizationInvitationInvalid,
+		serverrors.ErrReportIntervalInvalid,
+		serverrors.ErrReportDateRangeInvalid,
+		serverrors.ErrSessionNotFound,
+		serverrors.ErrSessionRefreshTokenEmpty,
+		serverrors.ErrSessionRefreshTokenInvalid,
+		serverrors.ErrSessionRefreshTokenReused,
+		serverrors.ErrTransactionNotFound,
+		serverrors.ErrTransactionAmountLimit,
+		serverrors.ErrUserNotFound,
+		serverrors.ErrUserEmailEmpty,
+		serverrors.ErrUserEmailExists,
+		serverrors.ErrUserPassword,
+		serverrors.ErrUserInvalidLogin,
+		serverrors.ErrUserLoginLocked,
+		serverrors.ErrUserTokenInvalid,
+		serverrors.ErrUserPasswordIncorrect,
+		serverrors.ErrUserTwoFactorEnabled,
+		serverrors.ErrUserTwoFactorNotEnabled,
+		serverrors.ErrUserTwoFactorCodeEmpty,
+		serverrors.ErrUserTwoFactorCodeInvalid,
+		serverrors.ErrUserTwoFactorChallengeInvalid,
+	} {
+		if _, ok := serviceErrors[err.Error()]; ok {
+			panic("remote: duplicate service error message: " + err.Error())
+		}
+		serviceErrors[err.Error()] = err
+	}
+}
+
+// encodeError returns the given error as it is sent between processes, or
+// nil if it is nil.
+func encodeError(err error) *wireError {
+	if err == nil {
+		return nil
+	}
+
+	we := &wireError{
+		Message: err.Error(),
+	}
+
+	var pes *serverrors.ParamErrors
+	if errors.As(err, &pes) {
+		we.Params = []wireParamError{}
+		for _, pe := range *pes {
+			we.Params = append(we.Params, wireParamError{
+				Name:    pe.Name,
+				Message: pe.ErrorType.Error(),
+			})
+		}
+	}
+
+	return we
+}
+
+// decodeError returns the error sent by encodeError, or nil if it is nil.
+func decodeError(we *wireError) error {
+	if we == nil {
+		return nil
+	}
+
+	if we.Params != nil {
+		pes := serverrors.NewParamErrors()
+		for _, p := range we.Params {
+			pes.Add(serverrors.NewParamError(p.Name, decodeMessage(p.Message)))
+		}
+		return pes
+	}
+
+	return decodeMessage(we.Message)
+}
+
+// decodeMessage returns the service error with the given message, or an
+// *Error if there is none.
+func decodeMessage(message string) error {
+	if err, ok := serviceErrors[message]; ok {
+		return err
+	}
+
+	return &Error{Message: message}
+}
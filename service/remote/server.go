@@ -0,0 +1,153 @@
+package remote
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"dddstructure/service/actor"
+	"dddstructure/trace"
+)
+
+// ErrTokenRequired is returned when a server is created without a token.
+var ErrTokenRequired = errors.New("remote: a token is required")
+
+// maxRequestSize defines the max size of the arguments of a call, which
+// leaves room for invoice imports.
+const maxRequestSize = 32 << 20
+
+// method defines a method served by the server, decoding its own arguments.
+type method func(ctx context.Context, args json.RawMessage) (json.RawMessage, error)
+
+// argsError is returned by a method when its arguments can not be decoded.
+type argsError struct {
+	err error
+}
+
+// Error implements the error interface.
+func (e *argsError) Error() string {
+	return e.err.Error()
+}
+
+// Server defines a server of services, which is an http.Handler.
+type Server struct {
+	token   string
+	tracer  *trace.Tracer
+	methods map[string]method
+	logger  *slog.Logger
+}
+
+// NewServer creates a new server with no services. Register the services to
+// serve with RegisterInvoice, RegisterTransaction and RegisterUser.
+//
+// Calls must have the given token, and ErrTokenRequired is returned if it is
+// empty. The services trust every caller with the token, including the actor
+// it calls on behalf of, so the server must still only be reachable by the
+// other services.
+func NewServer(token string, l *slog.Logger) (*Server, error) {
+	if token == "" {
+		return nil, ErrTokenRequired
+	}
+
+	return &Server{
+		token:   token,
+		methods: map[string]method{},
+		logger:  l,
+	}, nil
+}
+
+// SetTracer traces each call in a server span of the given tracer, which
+// joins the trace of the client.
+func (s *Server) SetTracer(t *trace.Tracer) {
+	s.tracer = t
+}
+
+// handle serves the given method, such as "Invoice.GetByID", which decodes
+// its arguments into A and calls fn.
+func handle[A any, R any](s *Server, name string, fn func(ctx context.Context, args *A) (R, error)) {
+	s.methods["/"+strings.Replace(name, ".", "/", 1)] = func(ctx context.Context, raw json.RawMessage) (json.RawMessage, error) {
+		args := new(A)
+		if err := json.Unmarshal(raw, args); err != nil {
+			return nil, &argsError{err: err}
+		}
+
+		result, err := fn(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(result)
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	m, ok := s.methods[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Join the trace of the client, if traced.
+	ctx := r.Context()
+	if s.tracer != nil {
+		remote, _ := trace.ParseTraceparent(r.Header.Get(trace.TraceparentHeader))
+
+		var span *trace.Span
+		ctx, span = s.tracer.Start(ctx, "remote "+strings.Replace(strings.TrimPrefix(r.URL.Path, "/"), "/", ".", 1), trace.SpanKindServer, remote)
+		defer span.End()
+	}
+
+	// Call the method on behalf of the actor of the client, if set.
+	if header := r.Header.Get(actor.Header); header != "" {
+		a, err := actor.Parse(header)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		ctx = actor.NewContext(ctx, a)
+	}
+
+	var args json.RawMessage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&args); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	// Call the method, sending any error it returns in the response.
+	var resp response
+	result, err := m(ctx, args)
+	if _, ok := err.(*argsError); ok {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		trace.SpanFromContext(ctx).SetError(err)
+		resp.Error = encodeError(err)
+	} else {
+		resp.Result = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		s.logger.Error("json.NewEncoder.Encode() error",
+			slog.String("path", r.URL.Path),
+			slog.Any("error", err))
+	}
+}
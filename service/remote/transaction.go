@@ -0,0 +1,37 @@
+package remote
+
+import (
+	"context"
+
+	"dddstructure/proto"
+	"dddstructure/service/interfaces"
+)
+
+// Transaction defines a client of a remote transaction service, which
+// implements the interfaces.Transaction interface.
+type Transaction struct {
+	c *Client
+}
+
+// NewTransaction creates a new client of the transaction service of the
+// given client's server.
+func NewTransaction(c *Client) *Transaction {
+	return &Transaction{
+		c: c,
+	}
+}
+
+// Process calls Transaction.Process.
+func (t *Transaction) Process(ctx context.Context, params *proto.TransactionProcessParams) (*proto.Transaction, error) {
+	var tx *proto.Transaction
+	if err := t.c.call(ctx, "Transaction.Process", params, &tx); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// RegisterTransaction serves the given transaction service.
+func (s *Server) RegisterTransaction(t interfaces.Transaction) {
+	handle(s, "Transaction.Process", t.Process)
+}
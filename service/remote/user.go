@@ -0,0 +1,192 @@
+package remote
+
+import (
+	"context"
+
+	"dddstructure/proto"
+	"dddstructure/service/interfaces"
+)
+
+// userGetByEmailArgs defines the arguments of User.GetByEmail.
+type userGetByEmailArgs struct {
+	Email string `json:"email"`
+}
+
+// User defines a client of a remote user service, which implements the
+// interfaces.User interface.
+//
+// The server never sends the password hash of a user, so the Password of
+// every user returned is empty.
+type User struct {
+	c *Client
+}
+
+// NewUser creates a new client of the user service of the given client's
+// server.
+func NewUser(c *Client) *User {
+	return &User{
+		c: c,
+	}
+}
+
+// Create calls User.Create.
+func (u *User) Create(ctx context.Context, params *proto.UserCreateParams) (*proto.User, error) {
+	return u.callUser(ctx, "User.Create", params)
+}
+
+// Login calls User.Login.
+func (u *User) Login(ctx context.Context, params *proto.UserLoginParams) (*proto.User, error) {
+	return u.callUser(ctx, "User.Login", params)
+}
+
+// GetByID calls User.GetByID.
+func (u *User) GetByID(ctx context.Context, id uint) (*proto.User, error) {
+	return u.callUser(ctx, "User.GetByID", &idArgs{ID: id})
+}
+
+// GetByEmail calls User.GetByEmail.
+func (u *User) GetByEmail(ctx context.Context, email string) (*proto.User, error) {
+	return u.callUser(ctx, "User.GetByEmail", &userGetByEmailArgs{Email: email})
+}
+
+// Update calls User.Update.
+func (u *User) Update(ctx context.Context, params *proto.UserUpdateParams) (*proto.User, error) {
+	return u.callUser(ctx, "User.Update", params)
+}
+
+// ForgotPassword calls User.ForgotPassword.
+func (u *User) ForgotPassword(ctx context.Context, params *proto.UserForgotPasswordParams) error {
+	return u.c.call(ctx, "User.ForgotPassword", params, nil)
+}
+
+// ResetPassword calls User.ResetPassword.
+func (u *User) ResetPassword(ctx context.Context, params *proto.UserResetPasswordParams) (*proto.User, error) {
+	return u.callUser(ctx, "User.ResetPassword", params)
+}
+
+// SendEmailVerification calls User.SendEmailVerification.
+func (u *User) SendEmailVerification(ctx context.Context, params *proto.UserSendEmailVerificationParams) error {
+	return u.c.call(ctx, "User.SendEmailVerification", params, nil)
+}
+
+// VerifyEmail calls User.VerifyEmail.
+func (u *User) VerifyEmail(ctx context.Context, params *proto.UserVerifyEmailParams) (*proto.User, error) {
+	return u.callUser(ctx, "User.VerifyEmail", params)
+}
+
+// CreateLoginChallenge calls User.CreateLoginChallenge.
+func (u *User) CreateLoginChallenge(ctx context.Context, id uint) (*proto.UserLoginChallenge, error) {
+	var lc *proto.UserLoginChallenge
+	if err := u.c.call(ctx, "User.CreateLoginChallenge", &idArgs{ID: id}, &lc); err != nil {
+		return nil, err
+	}
+
+	return lc, nil
+}
+
+// LoginTwoFactor calls User.LoginTwoFactor.
+func (u *User) LoginTwoFactor(ctx context.Context, params *proto.UserLoginTwoFactorParams) (*proto.User, error) {
+	return u.callUser(ctx, "User.LoginTwoFactor", params)
+}
+
+// EnrollTwoFactor calls User.EnrollTwoFactor.
+func (u *User) EnrollTwoFactor(ctx context.Context, params *proto.UserEnrollTwoFactorParams) (*proto.UserTwoFactorEnrollment, error) {
+	var e *proto.UserTwoFactorEnrollment
+	if err := u.c.call(ctx, "User.EnrollTwoFactor", params, &e); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// ConfirmTwoFactor calls User.ConfirmTwoFactor.
+func (u *User) ConfirmTwoFactor(ctx context.Context, params *proto.UserConfirmTwoFactorParams) ([]string, error) {
+	var codes []string
+	if err := u.c.call(ctx, "User.ConfirmTwoFactor", params, &codes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTwoFactor calls User.DisableTwoFactor.
+func (u *User) DisableTwoFactor(ctx context.Context, params *proto.UserDisableTwoFactorParams) error {
+	return u.c.call(ctx, "User.DisableTwoFactor", params, nil)
+}
+
+// RegenerateRecoveryCodes calls User.RegenerateRecoveryCodes.
+func (u *User) RegenerateRecoveryCodes(ctx context.Context, params *proto.UserRegenerateRecoveryCodesParams) ([]string, error) {
+	var codes []string
+	if err := u.c.call(ctx, "User.RegenerateRecoveryCodes", params, &codes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// callUser calls the given method returning a user.
+func (u *User) callUser(ctx context.Context, method string, args interface{}) (*proto.User, error) {
+	var user *proto.User
+	if err := u.c.call(ctx, method, args, &user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// RegisterUser serves the given user service.
+func (s *Server) RegisterUser(u interfaces.User) {
+	handle(s, "User.Create", func(ctx context.Context, args *proto.UserCreateParams) (*proto.User, error) {
+		return withoutPassword(u.Create(ctx, args))
+	})
+	handle(s, "User.Login", func(ctx context.Context, args *proto.UserLoginParams) (*proto.User, error) {
+		return withoutPassword(u.Login(ctx, args))
+	})
+	handle(s, "User.GetByID", func(ctx context.Context, args *idArgs) (*proto.User, error) {
+		return withoutPassword(u.GetByID(ctx, args.ID))
+	})
+	handle(s, "User.GetByEmail", func(ctx context.Context, args *userGetByEmailArgs) (*proto.User, error) {
+		return withoutPassword(u.GetByEmail(ctx, args.Email))
+	})
+	handle(s, "User.Update", func(ctx context.Context, args *proto.UserUpdateParams) (*proto.User, error) {
+		return withoutPassword(u.Update(ctx, args))
+	})
+	handle(s, "User.ForgotPassword", func(ctx context.Context, args *proto.UserForgotPasswordParams) (interface{}, error) {
+		return nil, u.ForgotPassword(ctx, args)
+	})
+	handle(s, "User.ResetPassword", func(ctx context.Context, args *proto.UserResetPasswordParams) (*proto.User, error) {
+		return withoutPassword(u.ResetPassword(ctx, args))
+	})
+	handle(s, "User.SendEmailVerification", func(ctx context.Context, args *proto.UserSendEmailVerificationParams) (interface{}, error) {
+		return nil, u.SendEmailVerification(ctx, args)
+	})
+	handle(s, "User.VerifyEmail", func(ctx context.Context, args *proto.UserVerifyEmailParams) (*proto.User, error) {
+		return withoutPassword(u.VerifyEmail(ctx, args))
+	})
+	handle(s, "User.CreateLoginChallenge", func(ctx context.Context, args *idArgs) (*proto.UserLoginChallenge, error) {
+		return u.CreateLoginChallenge(ctx, args.ID)
+	})
+	handle(s, "User.LoginTwoFactor", func(ctx context.Context, args *proto.UserLoginTwoFactorParams) (*proto.User, error) {
+		return withoutPassword(u.LoginTwoFactor(ctx, args))
+	})
+	handle(s, "User.EnrollTwoFactor", u.EnrollTwoFactor)
+	handle(s, "User.ConfirmTwoFactor", u.ConfirmTwoFactor)
+	handle(s, "User.DisableTwoFactor", func(ctx context.Context, args *proto.UserDisableTwoFactorParams) (interface{}, error) {
+		return nil, u.DisableTwoFactor(ctx, args)
+	})
+	handle(s, "User.RegenerateRecoveryCodes", u.RegenerateRecoveryCodes)
+}
+
+// withoutPassword returns a copy of the given user without its password
+// hash, which never leaves the process of the user service, along with the
+// given error.
+func withoutPassword(u *proto.User, err error) (*proto.User, error) {
+	if u == nil {
+		return nil, err
+	}
+
+	cp := *u
+	cp.Password = ""
+
+	return &cp, err
+}
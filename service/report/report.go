@@ -0,0 +1,201 @@
+package report
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"dddstructure/proto"
+	"dddstructure/service/actor"
+	"dddstructure/service/interfaces"
+	"dddstructure/storage"
+	"dddstructure/storage/report"
+	"dddstructure/trace"
+)
+
+// agingBuckets defines the aging buckets in the order they are returned.
+var agingBuckets = []proto.ReportAgingBucketName{
+	proto.ReportAgingBucketCurrent,
+	proto.ReportAgingBucket1To30,
+	proto.ReportAgingBucket31To60,
+	proto.ReportAgingBucket61To90,
+	proto.ReportAgingBucket90Plus,
+}
+
+// Service defines the report service.
+type Service struct {
+	storage  *storage.Storage
+	services *interfaces.Service
+	logger   *slog.Logger
+}
+
+// SetServices sets the services interface.
+func (s *Service) SetServices(services *interfaces.Service) {
+	s.services = services
+}
+
+// WithLogger returns a copy of the service that logs to the given logger.
+func (s *Service) WithLogger(l *slog.Logger) *Service {
+	c := *s
+	c.logger = l
+	return &c
+}
+
+// New creates a new service.
+func New(s *storage.Storage, l *slog.Logger) *Service {
+	return &Service{
+		storage: s,
+		logger:  l,
+	}
+}
+
+// GetAging gets the accounts receivable aging of an organization's unpaid
+// invoices, per currency.
+//
+// Every currency includes all of the aging buckets, in order, even when they
+// are empty. If the as of date is not set, the invoices are aged to today.
+func (s *Service) GetAging(ctx context.Context, params *proto.ReportAgingParams) ([]*proto.ReportAging, error) {
+	ctx, span := trace.Start(ctx, "service.Report.GetAging")
+	defer span.End()
+
+	// Authorize the actor.
+	if err := actor.Authorize(ctx, s.services.Organization, params.OrganizationID, proto.OrganizationPermissionReportRead); err != nil {
+		return nil, err
+	}
+
+	// Handle as of date.
+	asOf := params.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now().UTC()
+	}
+
+	// Get the aging buckets.
+	storageb, err := s.storage.Report.GetAging(ctx, &report.AgingParams{
+		OrganizationID: params.OrganizationID,
+		AsOf:           asOf,
+	})
+	if err != nil {
+		s.logger.Error("storage.Report.GetAging() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Group the buckets by currency.
+	aging := []*proto.ReportAging{}
+	currencies := make(map[string]*proto.ReportAging)
+	for _, v := range storageb {
+		a, ok := currencies[v.Currency]
+		if !ok {
+			a = &proto.ReportAging{
+				Currency: v.Currency,
+			}
+			for _, name := range agingBuckets {
+				a.Buckets = append(a.Buckets, proto.ReportAgingBucket{Name: name})
+			}
+
+			currencies[v.Currency] = a
+			aging = append(aging, a)
+		}
+
+		for n := range a.Buckets {
+			if string(a.Buckets[n].Name) == v.Bucket {
+				a.Buckets[n].Count += v.Count
+				a.Buckets[n].AmountDue += v.AmountDue
+			}
+		}
+
+		a.Count += v.Count
+		a.AmountDue += v.AmountDue
+	}
+
+	return aging, nil
+}
+
+// GetRevenue gets the revenue collected from an organization's transactions,
+// per period and currency.
+//
+// Periods without any transactions are left out.
+func (s *Service) GetRevenue(ctx context.Context, params *proto.ReportRevenueParams) ([]*proto.ReportRevenue, error) {
+	ctx, span := trace.Start(ctx, "service.Report.GetRevenue")
+	defer span.End()
+
+	// Authorize the actor.
+	if err := actor.Authorize(ctx, s.services.Organization, params.OrganizationID, proto.OrganizationPermissionReportRead); err != nil {
+		return nil, err
+	}
+
+	// Validate parameters.
+	if err := s.ValidateRevenueParams(ctx, params); err != nil {
+		return nil, err
+	}
+
+	// Get the revenue.
+	storager, err := s.storage.Report.GetRevenue(ctx, &report.RevenueParams{
+		OrganizationID: params.OrganizationID,
+		Interval:       string(params.Interval),
+		StartDate:      params.StartDate,
+		EndDate:        params.EndDate,
+	})
+	if err != nil {
+		s.logger.Error("storage.Report.GetRevenue() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Build revenue slice.
+	revenue := []*proto.ReportRevenue{}
+	for _, v := range storager {
+		revenue = append(revenue, &proto.ReportRevenue{
+			Period:    v.Period,
+			Currency:  v.Currency,
+			Count:     v.Count,
+			Collected: v.Collected,
+			Refunded:  v.Refunded,
+			Net:       int(v.Collected) - int(v.Refunded),
+		})
+	}
+
+	return revenue, nil
+}
+
+// GetBalances gets the outstanding and collected totals of an
+// organization's invoices, per currency.
+func (s *Service) GetBalances(ctx context.Context, params *proto.ReportBalancesParams) ([]*proto.ReportBalance, error) {
+	ctx, span := trace.Start(ctx, "service.Report.GetBalances")
+	defer span.End()
+
+	// Authorize the actor.
+	if err := actor.Authorize(ctx, s.services.Organization, params.OrganizationID, proto.OrganizationPermissionReportRead); err != nil {
+		return nil, err
+	}
+
+	// Validate parameters.
+	if err := s.ValidateBalancesParams(ctx, params); err != nil {
+		return nil, err
+	}
+
+	// Get the balances.
+	storageb, err := s.storage.Report.GetBalances(ctx, &report.BalancesParams{
+		OrganizationID: params.OrganizationID,
+		StartDate:      params.StartDate,
+		EndDate:        params.EndDate,
+	})
+	if err != nil {
+		s.logger.Error("storage.Report.GetBalances() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Build balances slice.
+	balances := []*proto.ReportBalance{}
+	for _, v := range storageb {
+		balances = append(balances, &proto.ReportBalance{
+			Currency:    v.Currency,
+			Count:       v.Count,
+			Outstanding: v.Outstanding,
+			Collected:   v.Collected,
+		})
+	}
+
+	return balances, nil
+}
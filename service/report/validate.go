@@ -0,0 +1,58 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/proto"
+	"dddstructure/service/errors"
+)
+
+// ValidateRevenueParams validates the revenue parameters.
+func (s *Service) ValidateRevenueParams(ctx context.Context, params *proto.ReportRevenueParams) error {
+	// Create a new ParamErrors.
+	pes := errors.NewParamErrors()
+
+	// Check interval.
+	switch params.Interval {
+	case proto.ReportRevenueIntervalDay, proto.ReportRevenueIntervalWeek, proto.ReportRevenueIntervalMonth:
+	default:
+		pes.Add(errors.NewParamError("interval", errors.ErrReportIntervalInvalid))
+	}
+
+	// Check date range.
+	if !validDateRange(params.StartDate, params.EndDate) {
+		pes.Add(errors.NewParamError("end_date", errors.ErrReportDateRangeInvalid))
+	}
+
+	// Return if there were parameter errors.
+	if pes.Length() > 0 {
+		return pes
+	}
+
+	return nil
+}
+
+// ValidateBalancesParams validates the balances parameters.
+func (s *Service) ValidateBalancesParams(ctx context.Context, params *proto.ReportBalancesParams) error {
+	// Create a new ParamErrors.
+	pes := errors.NewParamErrors()
+
+	// Check date range.
+	if !validDateRange(params.StartDate, params.EndDate) {
+		pes.Add(errors.NewParamError("end_date", errors.ErrReportDateRangeInvalid))
+	}
+
+	// Return if there were parameter errors.
+	if pes.Length() > 0 {
+		return pes
+	}
+
+	return nil
+}
+
+// validDateRange checks that the start date is not after the end date, when
+// both are set.
+func validDateRange(start, end *time.Time) bool {
+	return start == nil || end == nil || !start.After(*end)
+}
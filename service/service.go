@@ -3,8 +3,15 @@ package service
 import (
 	"log/slog"
 
+	"dddstructure/mail"
+	"dddstructure/service/events"
+	"dddstructure/service/guard"
+	"dddstructure/service/health"
 	"dddstructure/service/interfaces"
 	"dddstructure/service/invoice"
+	"dddstructure/service/organization"
+	"dddstructure/service/report"
+	"dddstructure/service/session"
 	"dddstructure/service/transaction"
 	"dddstructure/service/user"
 	"dddstructure/storage"
@@ -12,9 +19,27 @@ import (
 
 // Service defines the main business logic service.
 type Service struct {
-	User        *user.Service
-	Invoice     *invoice.Service
-	Transaction *transaction.Service
+	User         *user.Service
+	Invoice      *invoice.Service
+	Transaction  *transaction.Service
+	Report       *report.Service
+	Session      *session.Service
+	Organization *organization.Service
+	Health       *health.Service
+
+	// callGuard guards the calls between services if set.
+	callGuard *guard.Config
+	remote    Remote
+	logger    *slog.Logger
+}
+
+// Remote defines the services run by another process, which are called
+// instead of the services of this process. Each one left nil is called in
+// this process.
+type Remote struct {
+	User        interfaces.User
+	Invoice     interfaces.Invoice
+	Transaction interfaces.Transaction
 }
 
 // SetServices sets the services interface for all individual services.
@@ -27,26 +52,115 @@ func (s *Service) SetServices(services *interfaces.Service) {
 	s.User.SetServices(services)
 	s.Invoice.SetServices(services)
 	s.Transaction.SetServices(services)
+	s.Report.SetServices(services)
+	s.Session.SetServices(services)
+	s.Organization.SetServices(services)
+	s.Health.SetServices(services)
 }
 
 // New creates a new service.
-func New(s *storage.Storage, l *slog.Logger) *Service {
+func New(s *storage.Storage, m mail.Sender, l *slog.Logger) *Service {
 	// Create services.
 	serv := &Service{
-		User:        user.New(s, l),
-		Invoice:     invoice.New(s, l),
-		Transaction: transaction.New(s, l),
+		User:         user.New(s, m, l),
+		Invoice:      invoice.New(s, l),
+		Transaction:  transaction.New(s, l),
+		Report:       report.New(s, l),
+		Session:      session.New(s, l),
+		Organization: organization.New(s, m, l),
+		Health:       health.New(s, l),
+		logger:       l,
 	}
 
-	// Create services interface.
-	servi := interfaces.NewService(interfaces.NewServiceParams{
-		User:        serv.User,
-		Invoice:     serv.Invoice,
-		Transaction: serv.Transaction,
-	})
+	// Set services interfaces for all services.
+	serv.setInterfaces()
+
+	return serv
+}
+
+// WithLogger returns a copy of the service where every individual service
+// logs to the given logger, such as a logger scoped to a single request.
+//
+// The individual services call each other through the copies, so the logger
+// is kept across service calls.
+func (s *Service) WithLogger(l *slog.Logger) *Service {
+	// Copy services.
+	serv := &Service{
+		User:         s.User.WithLogger(l),
+		Invoice:      s.Invoice.WithLogger(l),
+		Transaction:  s.Transaction.WithLogger(l),
+		Report:       s.Report.WithLogger(l),
+		Session:      s.Session.WithLogger(l),
+		Organization: s.Organization.WithLogger(l),
+		Health:       s.Health.WithLogger(l),
+		callGuard:    s.callGuard,
+		remote:       s.remote,
+		logger:       l,
+	}
 
 	// Set services interfaces for all services.
-	serv.SetServices(servi)
+	serv.setInterfaces()
 
 	return serv
 }
+
+// SetCallGuard guards the calls between services with the given config, or
+// stops guarding them if it is nil. See guard.New.
+func (s *Service) SetCallGuard(cfg *guard.Config) {
+	s.callGuard = cfg
+	s.setInterfaces()
+}
+
+// SetRemote has the individual services call the given services of another
+// process, such as the clients of package remote, instead of their own.
+//
+// Only the calls between services go to the other process. Events are
+// still handled in the process that publishes them, so every process must
+// use the same storage.
+func (s *Service) SetRemote(r Remote) {
+	s.remote = r
+	s.setInterfaces()
+}
+
+// setInterfaces creates the services interface and sets it for all
+// individual services.
+//
+// Each set of services gets its own event bus, so the copies made by
+// WithLogger handle events with the same logger that published them.
+func (s *Service) setInterfaces() {
+	// Create event bus and subscribe services.
+	bus := events.New()
+	s.Invoice.Subscribe(bus)
+
+	// Create services interface.
+	params := interfaces.NewServiceParams{
+		User:         s.User,
+		Invoice:      s.Invoice,
+		Transaction:  s.Transaction,
+		Report:       s.Report,
+		Session:      s.Session,
+		Organization: s.Organization,
+		Health:       s.Health,
+		Events:       bus,
+	}
+
+	// Call the services of another process, if set.
+	if s.remote.User != nil {
+		params.User = s.remote.User
+	}
+	if s.remote.Invoice != nil {
+		params.Invoice = s.remote.Invoice
+	}
+	if s.remote.Transaction != nil {
+		params.Transaction = s.remote.Transaction
+	}
+
+	servi := interfaces.NewService(params)
+
+	// Guard the calls between services, if set.
+	if s.callGuard != nil {
+		servi = guard.New(servi, *s.callGuard, s.logger)
+	}
+
+	s.SetServices(servi)
+}
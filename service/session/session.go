@@ -0,0 +1,330 @@
+package session
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/service/interfaces"
+	"dddstructure/storage"
+	"dddstructure/storage/session"
+	"dddstructure/trace"
+	"dddstructure/utils"
+
+	"github.com/google/uuid"
+)
+
+// userAgentMax defines the maximum length of a stored user agent.
+const userAgentMax = 255
+
+// Service defines the session service.
+type Service struct {
+	storage  *storage.Storage
+	services *interfaces.Service
+	logger   *slog.Logger
+}
+
+// SetServices sets the services interface.
+func (s *Service) SetServices(services *interfaces.Service) {
+	s.services = services
+}
+
+// WithLogger returns a copy of the service that logs to the given logger.
+func (s *Service) WithLogger(l *slog.Logger) *Service {
+	c := *s
+	c.logger = l
+	return &c
+}
+
+// New creates a new service.
+func New(s *storage.Storage, l *slog.Logger) *Service {
+	return &Service{
+		storage: s,
+		logger:  l,
+	}
+}
+
+// Create handles creating a new session for a user, along with its first
+// refresh token.
+func (s *Service) Create(ctx context.Context, params *proto.SessionCreateParams) (*proto.Session, error) {
+	ctx, span := trace.Start(ctx, "service.Session.Create")
+	defer span.End()
+
+	// Create the session.
+	now := time.Now().UTC()
+	storages, err := s.storage.Session.Create(ctx, &session.Session{
+		ID:         uuid.New().String(),
+		UserID:     params.UserID,
+		UserAgent:  truncate(params.UserAgent, userAgentMax),
+		IPAddress:  params.IPAddress,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(params.Expiry),
+	})
+	if err != nil {
+		s.logger.Error("storage.Session.Create() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Issue the refresh token.
+	token, err := s.issueRefreshToken(ctx, storages.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map to service type.
+	services := storageToProto(storages)
+	services.RefreshToken = token
+
+	return services, nil
+}
+
+// Refresh handles exchanging a refresh token for a new one, extending the
+// session it belongs to.
+//
+// Every refresh token can only be used once. If a token that was already
+// used is seen again, either the client or an attacker holds a stolen copy,
+// so the whole session is revoked and ErrSessionRefreshTokenReused is
+// returned.
+func (s *Service) Refresh(ctx context.Context, params *proto.SessionRefreshParams) (*proto.Session, error) {
+	ctx, span := trace.Start(ctx, "service.Session.Refresh")
+	defer span.End()
+
+	// Validate parameters.
+	if err := s.ValidateRefreshParams(ctx, params); err != nil {
+		return nil, err
+	}
+
+	// Get the refresh token.
+	storaget, err := s.storage.Session.GetRefreshTokenByHash(ctx, utils.HashToken(params.RefreshToken))
+	if err == session.ErrRefreshTokenNotFound {
+		return nil, serverrors.ErrSessionRefreshTokenInvalid
+	} else if err != nil {
+		s.logger.Error("storage.Session.GetRefreshTokenByHash() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Get the session.
+	storages, err := s.storage.Session.GetByID(ctx, storaget.SessionID)
+	if err == session.ErrSessionNotFound {
+		return nil, serverrors.ErrSessionRefreshTokenInvalid
+	} else if err != nil {
+		s.logger.Error("storage.Session.GetByID() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Revoke the session if the token was already used.
+	if storaget.UsedAt != nil {
+		if err := s.Delete(ctx, storages.ID); err != nil {
+			return nil, err
+		}
+		return nil, serverrors.ErrSessionRefreshTokenReused
+	}
+
+	// Remove the session if it has expired.
+	now := time.Now().UTC()
+	if now.After(storages.ExpiresAt) {
+		if err := s.Delete(ctx, storages.ID); err != nil {
+			return nil, err
+		}
+		return nil, serverrors.ErrSessionRefreshTokenInvalid
+	}
+
+	// Use the refresh token. Only one request can mark it as used, so if
+	// two requests use the same token at once, the other one counts as
+	// reuse.
+	err = s.storage.Session.UseRefreshToken(ctx, storaget.Hash, now)
+	if err == session.ErrRefreshTokenUsed {
+		if err := s.Delete(ctx, storages.ID); err != nil {
+			return nil, err
+		}
+		return nil, serverrors.ErrSessionRefreshTokenReused
+	} else if err != nil {
+		s.logger.Error("storage.Session.UseRefreshToken() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Issue the next refresh token.
+	token, err := s.issueRefreshToken(ctx, storages.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the session.
+	storages.UserAgent = truncate(params.UserAgent, userAgentMax)
+	storages.IPAddress = params.IPAddress
+	storages.LastUsedAt = now
+	storages.ExpiresAt = now.Add(params.Expiry)
+
+	storages, err = s.storage.Session.Update(ctx, storages)
+	if err != nil {
+		s.logger.Error("storage.Session.Update() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Map to service type.
+	services := storageToProto(storages)
+	services.RefreshToken = token
+
+	return services, nil
+}
+
+// GetByID handles getting a session by ID.
+//
+// Expired sessions are treated as not found.
+func (s *Service) GetByID(ctx context.Context, id string) (*proto.Session, error) {
+	ctx, span := trace.Start(ctx, "service.Session.GetByID")
+	defer span.End()
+
+	// Get session from storage.
+	storages, err := s.storage.Session.GetByID(ctx, id)
+	if err == session.ErrSessionNotFound {
+		return nil, serverrors.ErrSessionNotFound
+	} else if err != nil {
+		s.logger.Error("storage.Session.GetByID() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Check expiry.
+	if time.Now().After(storages.ExpiresAt) {
+		return nil, serverrors.ErrSessionNotFound
+	}
+
+	return storageToProto(storages), nil
+}
+
+// GetByUserID handles getting the active sessions of a user, most recently
+// used first.
+func (s *Service) GetByUserID(ctx context.Context, userID uint) ([]*proto.Session, error) {
+	ctx, span := trace.Start(ctx, "service.Session.GetByUserID")
+	defer span.End()
+
+	// Get sessions from storage.
+	storages, err := s.storage.Session.GetByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("storage.Session.GetByUserID() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Map to service type, skipping expired sessions.
+	now := time.Now()
+	services := []*proto.Session{}
+	for _, v := range storages {
+		if now.After(v.ExpiresAt) {
+			continue
+		}
+		services = append(services, storageToProto(v))
+	}
+
+	return services, nil
+}
+
+// Delete handles deleting a session, revoking its refresh tokens.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	ctx, span := trace.Start(ctx, "service.Session.Delete")
+	defer span.End()
+
+	if err := s.storage.Session.Delete(ctx, id); err != nil {
+		s.logger.Error("storage.Session.Delete() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteForUser handles deleting a session that belongs to the given user.
+//
+// ErrSessionNotFound is returned if the session belongs to another user.
+func (s *Service) DeleteForUser(ctx context.Context, id string, userID uint) error {
+	ctx, span := trace.Start(ctx, "service.Session.DeleteForUser")
+	defer span.End()
+
+	// Get the session.
+	services, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Check the user.
+	if services.UserID != userID {
+		return serverrors.ErrSessionNotFound
+	}
+
+	return s.Delete(ctx, id)
+}
+
+// DeleteByUserID handles deleting all sessions of a user.
+func (s *Service) DeleteByUserID(ctx context.Context, userID uint) error {
+	ctx, span := trace.Start(ctx, "service.Session.DeleteByUserID")
+	defer span.End()
+
+	if err := s.storage.Session.DeleteByUserID(ctx, userID); err != nil {
+		s.logger.Error("storage.Session.DeleteByUserID() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+// issueRefreshToken creates a new refresh token for a session.
+func (s *Service) issueRefreshToken(ctx context.Context, sessionID string, now time.Time) (string, error) {
+	// Generate the token.
+	token, hash, err := utils.NewToken()
+	if err != nil {
+		s.logger.Error("error generating refresh token",
+			slog.Any("error", err))
+		return "", err
+	}
+
+	// Create the refresh token.
+	if _, err := s.storage.Session.CreateRefreshToken(ctx, &session.RefreshToken{
+		Hash:      hash,
+		SessionID: sessionID,
+		CreatedAt: now,
+	}); err != nil {
+		s.logger.Error("storage.Session.CreateRefreshToken() error",
+			slog.Any("error", err))
+		return "", err
+	}
+
+	return token, nil
+}
+
+// truncate shortens a string to at most max bytes, without splitting a UTF-8
+// character.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+
+	return s[:max]
+}
+
+// storageToProto handles mapping a storage session to a service session.
+func storageToProto(s *session.Session) *proto.Session {
+	return &proto.Session{
+		ID:         s.ID,
+		UserID:     s.UserID,
+		UserAgent:  s.UserAgent,
+		IPAddress:  s.IPAddress,
+		CreatedAt:  s.CreatedAt,
+		LastUsedAt: s.LastUsedAt,
+		ExpiresAt:  s.ExpiresAt,
+	}
+}
@@ -0,0 +1,25 @@
+package session
+
+import (
+	"context"
+	"dddstructure/proto"
+	"dddstructure/service/errors"
+)
+
+// ValidateRefreshParams validates the refresh parameters.
+func (s *Service) ValidateRefreshParams(ctx context.Context, params *proto.SessionRefreshParams) error {
+	// Create a new ParamErrors.
+	pes := errors.NewParamErrors()
+
+	// Check refresh token.
+	if params.RefreshToken == "" {
+		pes.Add(errors.NewParamError("refresh_token", errors.ErrSessionRefreshTokenEmpty))
+	}
+
+	// Return if there were parameter errors.
+	if pes.Length() > 0 {
+		return pes
+	}
+
+	return nil
+}
@@ -0,0 +1,382 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"dddstructure/cmd/api/config"
+	apictx "dddstructure/cmd/api/context"
+	"dddstructure/cmd/api/middleware/logging"
+	"dddstructure/cmd/api/pagination"
+	"dddstructure/cmd/api/proxy"
+	v1 "dddstructure/cmd/api/v1"
+	mailmock "dddstructure/mail/mock"
+	"dddstructure/service/tests/servicetest"
+	"dddstructure/storage"
+	"dddstructure/storage/idgen"
+	"dddstructure/storage/memory"
+	"dddstructure/storage/sqlite"
+
+	"github.com/beeker1121/httprouter"
+)
+
+// newConfig returns the config of the API in tests.
+func newConfig() *config.Config {
+	return &config.Config{
+		JWTSecret:         "jwtsecret",
+		JWTExpiryTime:     15,
+		RefreshExpiryTime: 60,
+		CursorSecret:      "cursorsecret",
+		LimitDefault:      10,
+		LimitMax:          500,
+		ImportLimitMax:    1000,
+	}
+}
+
+// newAPI returns the handler of the API with the given config and storage,
+// with the same routes and request logging as the API.
+func newAPI(t *testing.T, cfg *config.Config, store *storage.Storage) http.Handler {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	serv := servicetest.New(store, mailmock.New(), logger)
+
+	router := httprouter.New()
+	ac := apictx.New(cfg, logger, serv)
+	proxies, err := proxy.New(cfg.TrustedProxies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ac.Proxies = proxies
+	v1.New(ac, router)
+
+	return logging.LogRequests(ac, router)
+}
+
+// request defines a request to the API.
+type request struct {
+	method         string
+	path           string
+	token          string
+	organizationID uint
+	body           interface{}
+}
+
+// do sends the given request to the API and decodes the data of the
+// response into data, if set.
+func do(t *testing.T, h http.Handler, req request, data interface{}) *httptest.ResponseRecorder {
+	var body io.Reader
+	if req.body != nil {
+		b, err := json.Marshal(req.body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	r := httptest.NewRequest(req.method, req.path, body)
+	if req.token != "" {
+		r.Header.Set("Authorization", "Bearer "+req.token)
+	}
+	if req.organizationID != 0 {
+		r.Header.Set("X-Organization-ID", strconv.FormatUint(uint64(req.organizationID), 10))
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if data != nil && w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &struct {
+			Data interface{} `json:"data"`
+		}{Data: data}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return w
+}
+
+// signup signs up a new user and returns its access token.
+func signup(t *testing.T, h http.Handler, email string) string {
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	w := do(t, h, request{
+		method: http.MethodPost,
+		path:   "/api/v1/signup",
+		body: map[string]string{
+			"email":    email,
+			"password": "TestPassword123",
+		},
+	}, &token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected signup status to be '%d', got '%d': %s", http.StatusOK, w.Code, w.Body)
+	}
+
+	return token.AccessToken
+}
+
+func TestSortableIDs(t *testing.T) {
+	t.Parallel()
+
+	// Create a new SQLite storage implementation with sortable IDs, which
+	// are well beyond 32 bits.
+	db, err := sqlite.Open(context.Background(), ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	h := newAPI(t, newConfig(), sqlite.New(db, 0, idgen.NewSortable()))
+	token := signup(t, h, "johndoe@test.com")
+
+	// Get the user's personal organization.
+	var orgs []struct {
+		ID uint `json:"id"`
+	}
+	if w := do(t, h, request{method: http.MethodGet, path: "/api/v1/organization", token: token}, &orgs); w.Code != http.StatusOK {
+		t.Fatalf("Expected status to be '%d', got '%d': %s", http.StatusOK, w.Code, w.Body)
+	}
+	if orgs[0].ID <= 1<<32 {
+		t.Fatalf("Expected organization ID to be over 32 bits, got '%d'", orgs[0].ID)
+	}
+
+	// Create an invoice in the organization.
+	var invoice struct {
+		ID uint `json:"id"`
+	}
+	w := do(t, h, request{
+		method:         http.MethodPost,
+		path:           "/api/v1/invoice",
+		token:          token,
+		organizationID: orgs[0].ID,
+		body: map[string]interface{}{
+			"payment_methods": []string{"card"},
+			"line_items": []map[string]interface{}{
+				{"quantity": 1, "price": 100},
+			},
+		},
+	}, &invoice)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status to be '%d', got '%d': %s", http.StatusOK, w.Code, w.Body)
+	}
+
+	// Get, update and delete the invoice by its ID.
+	path := "/api/v1/invoice/" + strconv.FormatUint(uint64(invoice.ID), 10)
+	for _, req := range []request{
+		{method: http.MethodGet, path: path},
+		{method: http.MethodPost, path: path, body: map[string]string{"message": "Thanks"}},
+		{method: http.MethodDelete, path: path},
+	} {
+		req.token = token
+		req.organizationID = orgs[0].ID
+		if w := do(t, h, req, nil); w.Code != http.StatusOK {
+			t.Errorf("Expected %s status to be '%d', got '%d': %s", req.method, http.StatusOK, w.Code, w.Body)
+		}
+	}
+}
+
+func TestInvoiceLimit(t *testing.T) {
+	h := newAPI(t, newConfig(), memory.New())
+	token := signup(t, h, "limit@test.com")
+
+	for _, tc := range []struct {
+		limit string
+		code  int
+	}{
+		{"1", http.StatusOK},
+		{"500", http.StatusOK},
+		{"0", http.StatusBadRequest},
+		{"-1", http.StatusBadRequest},
+		{"501", http.StatusBadRequest},
+		{"ten", http.StatusBadRequest},
+	} {
+		w := do(t, h, request{
+			method: http.MethodGet,
+			path:   "/api/v1/invoice?limit=" + tc.limit,
+			token:  token,
+		}, nil)
+		if w.Code != tc.code {
+			t.Errorf("Expected status of limit '%s' to be '%d', got '%d': %s", tc.limit, tc.code, w.Code, w.Body)
+		}
+	}
+}
+
+func TestRoute(t *testing.T) {
+	t.Parallel()
+
+	router := httprouter.New()
+	v1.New(apictx.New(newConfig(), &slog.Logger{}, nil), router)
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		route  string
+	}{
+		{http.MethodGet, "/api/v1/invoice", "/api/v1/invoice"},
+		{http.MethodGet, "/api/v1/invoice/123", "/api/v1/invoice/:id"},
+		{http.MethodDelete, "/api/v1/invoice/456", "/api/v1/invoice/:id"},
+		// Parameters with the value of another segment.
+		{http.MethodGet, "/api/v1/public/invoice/invoice", "/api/v1/public/invoice/:hash"},
+		{http.MethodPost, "/api/v1/public/invoice/pay/pay", "/api/v1/public/invoice/:hash/pay"},
+		{http.MethodGet, "/api/v1/unknown/123", ""},
+	} {
+		r := httptest.NewRequest(tc.method, tc.path, nil)
+		if route := logging.Route(router, r); route != tc.route {
+			t.Errorf("Expected route of %s %s to be '%s', got '%s'", tc.method, tc.path, tc.route, route)
+		}
+	}
+}
+
+func TestPaginationLink(t *testing.T) {
+	t.Parallel()
+
+	cfg := newConfig()
+	cfg.PublicHosts = []string{"api.example.com"}
+
+	proxies, err := proxy.New([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ac := apictx.New(cfg, &slog.Logger{}, nil)
+	ac.Proxies = proxies
+
+	for _, tc := range []struct {
+		name       string
+		host       string
+		remoteAddr string
+		proto      string
+		link       string
+	}{
+		{"public host", "api.example.com", "203.0.113.1:1234", "", "http://api.example.com/api/v1/invoice?cursor=c&limit=5"},
+		{"public host of another case", "API.example.com", "203.0.113.1:1234", "", "http://api.example.com/api/v1/invoice?cursor=c&limit=5"},
+		{"unknown host", "evil.example.com", "203.0.113.1:1234", "", "/api/v1/invoice?cursor=c&limit=5"},
+		{"trusted proxy", "api.example.com", "10.1.2.3:1234", "https", "https://api.example.com/api/v1/invoice?cursor=c&limit=5"},
+		{"trusted proxy address", "api.example.com", "192.168.1.1:1234", "https", "https://api.example.com/api/v1/invoice?cursor=c&limit=5"},
+		{"untrusted proxy", "api.example.com", "203.0.113.1:1234", "https", "http://api.example.com/api/v1/invoice?cursor=c&limit=5"},
+		{"invalid proto", "api.example.com", "10.1.2.3:1234", "javascript", "http://api.example.com/api/v1/invoice?cursor=c&limit=5"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/invoice?limit=5&offset=10&cursor=old", nil)
+		r.Host = tc.host
+		r.RemoteAddr = tc.remoteAddr
+		if tc.proto != "" {
+			r.Header.Set("X-Forwarded-Proto", tc.proto)
+		}
+
+		if link := pagination.Link(ac, r, "c"); link != tc.link {
+			t.Errorf("Expected link of %s to be '%s', got '%s'", tc.name, tc.link, link)
+		}
+	}
+
+	// Check cursors are not signed with the secret they are derived from.
+	if secret := pagination.DeriveSecret("jwtsecret"); secret == "" || secret == "jwtsecret" || secret != pagination.DeriveSecret("jwtsecret") {
+		t.Errorf("Expected derived secret to be stable and differ from '%s', got '%s'", "jwtsecret", secret)
+	}
+
+	// Check invalid proxies are rejected.
+	if _, err := proxy.New([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("Expected error for invalid proxy")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	// Allow a single signup per minute from each client.
+	cfg := newConfig()
+	cfg.RateLimits = map[config.RateLimitGroup]config.RateLimit{
+		config.RateLimitGroupSignup: {Requests: 1, Period: 60},
+	}
+	cfg.TrustedProxies = []string{"10.0.0.0/8"}
+
+	newHandler := func() http.Handler {
+		return newAPI(t, cfg, memory.New())
+	}
+
+	// signupFrom signs up a new user from the given address through the
+	// given handler, with the given X-Forwarded-For header.
+	n := 0
+	signupFrom := func(h http.Handler, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+		n++
+		b, err := json.Marshal(map[string]string{
+			"email":    "johndoe" + strconv.Itoa(n) + "@test.com",
+			"password": "TestPassword123",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/signup", bytes.NewReader(b))
+		r.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	h := newHandler()
+
+	// Check the headers of an allowed request.
+	w := signupFrom(h, "203.0.113.1:1234", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status to be '%d', got '%d': %s", http.StatusOK, w.Code, w.Body)
+	}
+	for header, value := range map[string]string{
+		"RateLimit-Policy":    "1;w=60",
+		"RateLimit-Limit":     "1",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "60",
+		"Retry-After":         "",
+	} {
+		if got := w.Header().Get(header); got != value {
+			t.Errorf("Expected %s header to be '%s', got '%s'", header, value, got)
+		}
+	}
+
+	// Check the headers of a limited request.
+	w = signupFrom(h, "203.0.113.1:1234", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status to be '%d', got '%d': %s", http.StatusTooManyRequests, w.Code, w.Body)
+	}
+	for header, value := range map[string]string{
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "60",
+		"Retry-After":         "60",
+	} {
+		if got := w.Header().Get(header); got != value {
+			t.Errorf("Expected %s header to be '%s', got '%s'", header, value, got)
+		}
+	}
+
+	// Check clients are told apart behind a trusted proxy, but not by the
+	// header of an untrusted client.
+	for _, tc := range []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		status       int
+	}{
+		{"client behind proxy", "10.0.0.1:1234", "198.51.100.1", http.StatusOK},
+		{"other client behind proxy", "10.0.0.1:1234", "198.51.100.2", http.StatusOK},
+		{"client behind proxies", "10.0.0.2:1234", "198.51.100.1, 10.0.0.1", http.StatusTooManyRequests},
+		{"client spoofing its address", "10.0.0.1:1234", "198.51.100.3, 198.51.100.1", http.StatusTooManyRequests},
+		{"untrusted client", "203.0.113.1:1234", "198.51.100.4", http.StatusTooManyRequests},
+	} {
+		if w := signupFrom(h, tc.remoteAddr, tc.forwardedFor); w.Code != tc.status {
+			t.Errorf("Expected status of %s to be '%d', got '%d'", tc.name, tc.status, w.Code)
+		}
+	}
+
+	// Check each API has its own limiters.
+	if w := signupFrom(newHandler(), "203.0.113.1:1234", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected status of new API to be '%d', got '%d'", http.StatusOK, w.Code)
+	}
+}
@@ -0,0 +1,376 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	mailmock "dddstructure/mail/mock"
+	"dddstructure/proto"
+	"dddstructure/service/actor"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/service/tests/servicetest"
+	"dddstructure/storage"
+	storagecache "dddstructure/storage/cache"
+	"dddstructure/storage/cache/lru"
+	"dddstructure/storage/cached"
+	"dddstructure/storage/invoice"
+	"dddstructure/storage/memory"
+	"dddstructure/storage/user"
+)
+
+// countingUsers counts the lookups that reach the wrapped user database.
+type countingUsers struct {
+	user.Database
+	gets int
+}
+
+// GetByID counts GetByID.
+func (db *countingUsers) GetByID(ctx context.Context, id uint) (*user.User, error) {
+	db.gets++
+	return db.Database.GetByID(ctx, id)
+}
+
+// countingInvoices counts the lookups that reach the wrapped invoice
+// database.
+type countingInvoices struct {
+	invoice.Database
+	gets int
+}
+
+// GetByID counts GetByID.
+func (db *countingInvoices) GetByID(ctx context.Context, id uint) (*invoice.Invoice, error) {
+	db.gets++
+	return db.Database.GetByID(ctx, id)
+}
+
+// GetByPublicHash counts GetByPublicHash.
+func (db *countingInvoices) GetByPublicHash(ctx context.Context, hash string) (*invoice.Invoice, error) {
+	db.gets++
+	return db.Database.GetByPublicHash(ctx, hash)
+}
+
+func TestLRU(t *testing.T) {
+	t.Parallel()
+
+	// Create a cache holding two values.
+	c := lru.New(2, 0)
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	// Use a, so b is the least recently used, then evict it.
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("Expected a to be '%v', got '%v'", 1, v)
+	}
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("Expected b to be evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("Expected a to be '%v', got '%v'", 1, v)
+	}
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Errorf("Expected c to be '%v', got '%v'", 3, v)
+	}
+	if c.Len() != 2 {
+		t.Errorf("Expected length to be '%d', got '%d'", 2, c.Len())
+	}
+
+	// Replace and delete values.
+	c.Set("a", 4)
+	if v, _ := c.Get("a"); v != 4 {
+		t.Errorf("Expected a to be '%v', got '%v'", 4, v)
+	}
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Expected a to be deleted")
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	t.Parallel()
+
+	// Create a cache expiring values quickly.
+	c := lru.New(0, 20*time.Millisecond)
+	c.Set("a", 1)
+
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("Expected a to be found")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Expected a to be expired")
+	}
+	if c.Len() != 0 {
+		t.Errorf("Expected length to be '%d', got '%d'", 0, c.Len())
+	}
+}
+
+func TestUser(t *testing.T) {
+	t.Parallel()
+	ctx := storagecache.Allow(context.Background())
+
+	// Wrap a memory user database, counting the lookups reaching it.
+	next := &countingUsers{Database: memory.New().User}
+	db := cached.NewUser(next, lru.New(10, time.Minute))
+
+	u, err := db.Create(ctx, &user.User{Email: "johndoe@test.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the second lookup is cached.
+	for n := 0; n < 2; n++ {
+		got, err := db.GetByID(ctx, u.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Changing the returned user does not change the cached user.
+		got.Email = "changed@test.com"
+	}
+	if next.gets != 1 {
+		t.Errorf("Expected gets to be '%d', got '%d'", 1, next.gets)
+	}
+
+	got, err := db.GetByID(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Email != "johndoe@test.com" {
+		t.Errorf("Expected email to be '%s', got '%s'", "johndoe@test.com", got.Email)
+	}
+
+	// Check an update removes the user from the cache.
+	got.Email = "janedoe@test.com"
+	if _, err := db.Update(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = db.GetByID(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Email != "janedoe@test.com" {
+		t.Errorf("Expected email to be '%s', got '%s'", "janedoe@test.com", got.Email)
+	}
+	if next.gets != 2 {
+		t.Errorf("Expected gets to be '%d', got '%d'", 2, next.gets)
+	}
+
+	// Check users not found are not cached.
+	if _, err := db.GetByID(ctx, u.ID+1); err != user.ErrUserNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", user.ErrUserNotFound, err)
+	}
+	if _, err := db.Create(ctx, &user.User{Email: "new@test.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetByID(ctx, u.ID+1); err != nil {
+		t.Errorf("Expected error to be '%v', got '%v'", nil, err)
+	}
+
+	// Check a lookup the context does not allow to be cached reads the
+	// database, such as after the user was updated by another process.
+	gets := next.gets
+	if _, err := db.GetByID(context.Background(), u.ID); err != nil {
+		t.Fatal(err)
+	}
+	if next.gets != gets+1 {
+		t.Errorf("Expected gets to be '%d', got '%d'", gets+1, next.gets)
+	}
+
+	// Check changing the times of a cached user returned does not change
+	// the cached user.
+	verifiedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	got.EmailVerifiedAt = &verifiedAt
+	if _, err := db.Update(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.GetByID(ctx, u.ID); err != nil {
+		t.Fatal(err)
+	}
+	for n := 0; n < 2; n++ {
+		got, err = db.GetByID(ctx, u.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.EmailVerifiedAt.Equal(verifiedAt) {
+			t.Errorf("Expected email verified at to be '%v', got '%v'", verifiedAt, got.EmailVerifiedAt)
+		}
+		*got.EmailVerifiedAt = time.Time{}
+	}
+}
+
+func TestInvoice(t *testing.T) {
+	t.Parallel()
+	ctx := storagecache.Allow(context.Background())
+
+	// Wrap a memory invoice database, counting the lookups reaching it.
+	next := &countingInvoices{Database: memory.New().Invoice}
+	db := cached.NewInvoice(next, lru.New(10, time.Minute))
+
+	i, err := db.Create(ctx, &invoice.Invoice{
+		PublicHash:     "hash",
+		LineItems:      []invoice.LineItem{{Name: "Item", Quantity: 1, Price: 100, Subtotal: 100}},
+		PaymentMethods: []string{"card"},
+		Status:         "pending",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the invoice is cached by public hash and by ID.
+	got, err := db.GetByPublicHash(ctx, "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.LineItems[0].Name = "Changed"
+	got.PaymentMethods[0] = "changed"
+
+	got, err = db.GetByID(ctx, i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetByPublicHash(ctx, "hash"); err != nil {
+		t.Fatal(err)
+	}
+	if next.gets != 1 {
+		t.Errorf("Expected gets to be '%d', got '%d'", 1, next.gets)
+	}
+
+	// Check changing a returned invoice does not change the cached invoice.
+	if got.LineItems[0].Name != "Item" || got.PaymentMethods[0] != "card" {
+		t.Errorf("Expected cached invoice to be unchanged, got '%+v'", got)
+	}
+
+	// Check an update removes the invoice from the cache for both.
+	got.Status = "paid"
+	if _, err := db.Update(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = db.GetByPublicHash(ctx, "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != "paid" {
+		t.Errorf("Expected status to be '%s', got '%s'", "paid", got.Status)
+	}
+	if next.gets != 2 {
+		t.Errorf("Expected gets to be '%d', got '%d'", 2, next.gets)
+	}
+
+	// Check a delete removes the invoice from the cache for both.
+	if err := db.Delete(ctx, i.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetByID(ctx, i.ID); err != invoice.ErrInvoiceNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", invoice.ErrInvoiceNotFound, err)
+	}
+	if _, err := db.GetByPublicHash(ctx, "hash"); err != invoice.ErrInvoiceNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", invoice.ErrInvoiceNotFound, err)
+	}
+}
+
+func TestStorageNew(t *testing.T) {
+	t.Parallel()
+
+	// Wrap a storage with the caching decorator.
+	s := memory.New()
+	wrapped := storage.New(s, cached.Decorator(lru.New(10, time.Minute)))
+
+	if _, ok := wrapped.User.(*cached.UserDatabase); !ok {
+		t.Errorf("Expected user database to be cached, got '%T'", wrapped.User)
+	}
+	if _, ok := wrapped.Invoice.(*cached.InvoiceDatabase); !ok {
+		t.Errorf("Expected invoice database to be cached, got '%T'", wrapped.Invoice)
+	}
+	if wrapped.Session != s.Session {
+		t.Errorf("Expected session database to be unchanged")
+	}
+}
+
+func TestInstances(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// Run two instances on the same database, each with its own cache, as
+	// when running several API instances.
+	store := memory.New()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	a := servicetest.New(storage.New(store, cached.Decorator(lru.New(10, time.Minute))), mailmock.New(), logger)
+	b := servicetest.New(storage.New(store, cached.Decorator(lru.New(10, time.Minute))), mailmock.New(), logger)
+
+	u, err := a.User.Create(ctx, &proto.UserCreateParams{
+		Email:    "johndoe@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the user on the first instance, so it is cached there, then
+	// change its email on the second.
+	if _, err := a.User.GetByID(storagecache.Allow(ctx), u.ID); err != nil {
+		t.Fatal(err)
+	}
+	email := "janedoe@test.com"
+	if _, err := b.User.Update(ctx, &proto.UserUpdateParams{ID: &u.ID, Email: &email}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing the password on the first instance keeps the new email, as
+	// the user is not read from its cache before being written back.
+	password := "NewPassword123"
+	if _, err := a.User.Update(ctx, &proto.UserUpdateParams{ID: &u.ID, Password: &password}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := b.User.GetByID(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Email != email {
+		t.Errorf("Expected email to be '%s', got '%s'", email, got.Email)
+	}
+
+	// Read a pending invoice on the first instance, so it is cached there,
+	// then pay it on the second.
+	orgs, err := a.Organization.GetForUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i, err := a.Invoice.Create(actor.WithUser(ctx, u.ID), &proto.InvoiceCreateParams{
+		OrganizationID: orgs[0].ID,
+		UserID:         u.ID,
+		PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
+		LineItems: []proto.InvoiceLineItem{
+			{
+				Quantity: 1,
+				Price:    100,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Invoice.GetByPublicHash(storagecache.Allow(ctx), i.PublicHash); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Invoice.Pay(ctx, i.ID, &proto.InvoicePayParams{Amount: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Paying it again on the first instance sees it is already paid.
+	if _, err := a.Invoice.Pay(ctx, i.ID, &proto.InvoicePayParams{Amount: 100}); err != serverrors.ErrInvoiceStatusNotPending {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrInvoiceStatusNotPending, err)
+	}
+}
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"dddstructure/proto"
+	"dddstructure/service/events"
+)
+
+func TestTopic(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// Subscribe handlers to a bus, recording the order they run in.
+	bus := events.New()
+
+	var calls []string
+	bus.RefundIssued.Subscribe(func(ctx context.Context, e *events.RefundIssued) error {
+		calls = append(calls, "first")
+		return nil
+	})
+	bus.RefundIssued.Subscribe(func(ctx context.Context, e *events.RefundIssued) error {
+		calls = append(calls, "second")
+		return nil
+	})
+
+	// Publish an event, which runs every handler in order before returning.
+	e := &events.RefundIssued{Transaction: &proto.Transaction{ID: 1}}
+	if err := bus.RefundIssued.Publish(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0] != "first" || calls[1] != "second" {
+		t.Errorf("Expected handlers to run in order, got '%v'", calls)
+	}
+
+	// Other topics are not affected.
+	if err := bus.TransactionApproved.Publish(ctx, &events.TransactionApproved{Transaction: e.Transaction}); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 {
+		t.Errorf("Expected '%d' handler calls, got '%d'", 2, len(calls))
+	}
+
+	// A failing handler stops the handlers after it, and its error is
+	// returned.
+	errFailed := errors.New("handler failed")
+	bus = events.New()
+	calls = nil
+	bus.RefundIssued.Subscribe(func(ctx context.Context, e *events.RefundIssued) error {
+		calls = append(calls, "failing")
+		return errFailed
+	})
+	bus.RefundIssued.Subscribe(func(ctx context.Context, e *events.RefundIssued) error {
+		calls = append(calls, "after")
+		return nil
+	})
+
+	if err := bus.RefundIssued.Publish(ctx, e); err != errFailed {
+		t.Errorf("Expected error to be '%v', got '%v'", errFailed, err)
+	}
+	if len(calls) != 1 {
+		t.Errorf("Expected '%d' handler calls, got '%v'", 1, calls)
+	}
+}
@@ -0,0 +1,98 @@
+package guard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"dddstructure/proto"
+	"dddstructure/service/guard"
+	"dddstructure/service/interfaces"
+)
+
+// loopInvoice defines an invoice service whose GetByID calls itself again
+// through the services interface, recording the call chain of each call.
+type loopInvoice struct {
+	interfaces.Invoice
+	services *interfaces.Service
+	chains   [][]string
+}
+
+// GetByID calls GetByID again through the services interface.
+func (s *loopInvoice) GetByID(ctx context.Context, id uint) (*proto.Invoice, error) {
+	s.chains = append(s.chains, guard.Chain(ctx))
+	return s.services.Invoice.GetByID(ctx, id)
+}
+
+func TestCycle(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// Guard a service that calls itself.
+	var buf bytes.Buffer
+	inv := &loopInvoice{}
+	inv.services = guard.New(&interfaces.Service{Invoice: inv}, guard.Config{MaxDepth: 8}, slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	// The second call is stopped as a cycle.
+	_, err := inv.services.Invoice.GetByID(ctx, 1)
+	if !errors.Is(err, guard.ErrCycle) {
+		t.Fatalf("Expected error to be '%v', got '%v'", guard.ErrCycle, err)
+	}
+
+	var cerr *guard.CallError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("Expected error to be a call error, got '%T'", err)
+	}
+	if strings.Join(cerr.Chain, " -> ") != "Invoice.GetByID -> Invoice.GetByID" {
+		t.Errorf("Expected chain to be '%s', got '%v'", "Invoice.GetByID -> Invoice.GetByID", cerr.Chain)
+	}
+
+	// The call ran once, with itself in the chain.
+	if len(inv.chains) != 1 || len(inv.chains[0]) != 1 || inv.chains[0][0] != "Invoice.GetByID" {
+		t.Errorf("Expected one call with chain '%s', got '%v'", "Invoice.GetByID", inv.chains)
+	}
+
+	// The chain is logged.
+	if !strings.Contains(buf.String(), `"chain":"Invoice.GetByID -> Invoice.GetByID"`) {
+		t.Errorf("Expected call chain to be logged, got '%s'", buf.String())
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// Guard a service that calls itself, allowing it to be reentered.
+	inv := &loopInvoice{}
+	inv.services = guard.New(&interfaces.Service{Invoice: inv}, guard.Config{
+		MaxDepth:     3,
+		AllowReentry: []string{"Invoice.GetByID"},
+	}, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+
+	// The fourth call is stopped as too deep.
+	_, err := inv.services.Invoice.GetByID(ctx, 1)
+	if !errors.Is(err, guard.ErrMaxDepth) {
+		t.Fatalf("Expected error to be '%v', got '%v'", guard.ErrMaxDepth, err)
+	}
+
+	var cerr *guard.CallError
+	if !errors.As(err, &cerr) || len(cerr.Chain) != 4 {
+		t.Errorf("Expected call error with a chain of '%d' calls, got '%v'", 4, err)
+	}
+	if len(inv.chains) != 3 {
+		t.Errorf("Expected '%d' calls, got '%d'", 3, len(inv.chains))
+	}
+
+	// Calls made one after another do not share a chain.
+	if len(guard.Chain(ctx)) != 0 {
+		t.Errorf("Expected the caller's chain to be empty, got '%v'", guard.Chain(ctx))
+	}
+	for n, chain := range inv.chains {
+		if len(chain) != n+1 {
+			t.Errorf("Expected call '%d' to have a chain of '%d' calls, got '%v'", n, n+1, chain)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	mailmock "dddstructure/mail/mock"
+	"dddstructure/proto"
+	"dddstructure/service/tests/servicetest"
+	"dddstructure/storage/memory"
+)
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
+
+	// Check the dependencies.
+	health := serv.Health.Check(ctx)
+	if health.Status != proto.HealthStatusUp {
+		t.Errorf("Expected status to be '%s', got '%s'", proto.HealthStatusUp, health.Status)
+	}
+	if len(health.Checks) != 1 || health.Checks[0].Name != "database" || health.Checks[0].Status != proto.HealthStatusUp {
+		t.Errorf("Expected the database to be up, got '%+v'", health.Checks)
+	}
+}
@@ -0,0 +1,63 @@
+package idgen
+
+import (
+	"sync"
+	"testing"
+
+	"dddstructure/storage/idgen"
+	"dddstructure/storage/memory/sequence"
+)
+
+func TestSortable(t *testing.T) {
+	g := idgen.NewSortable()
+
+	// Generate IDs faster than one per millisecond, so the sequence runs over.
+	var last uint
+	for i := 0; i < 10000; i++ {
+		id := g.NewID()
+		if id <= last {
+			t.Fatalf("Expected ID '%d' to be greater than '%d'", id, last)
+		}
+		if id >= 1<<53 {
+			t.Fatalf("Expected ID '%d' to fit in 53 bits", id)
+		}
+		last = id
+	}
+}
+
+func TestSequence(t *testing.T) {
+	s := sequence.New()
+
+	// Assign IDs concurrently.
+	var mu sync.Mutex
+	seen := make(map[uint]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				id := s.Assign(0)
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("Expected ID '%d' to be unique", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != 1000 {
+		t.Errorf("Expected assigned IDs to be '%d', got '%d'", 1000, len(seen))
+	}
+
+	// Keep a given ID, and assign the next IDs after it.
+	if id := s.Assign(5000); id != 5000 {
+		t.Errorf("Expected ID to be '%d', got '%d'", 5000, id)
+	}
+	if id := s.Assign(0); id != 5001 {
+		t.Errorf("Expected ID to be '%d', got '%d'", 5001, id)
+	}
+}
@@ -1,24 +1,31 @@
 package invoice
 
 import (
-	"database/sql"
+	"context"
+	"io"
 	"log/slog"
 	"testing"
+	"time"
 
+	mailmock "dddstructure/mail/mock"
 	"dddstructure/proto"
-	"dddstructure/service"
-	"dddstructure/storage/mock"
+	"dddstructure/service/actor"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/service/tests/servicetest"
+	"dddstructure/storage/memory"
 )
 
 func TestPay(t *testing.T) {
-	// Create a new mock storage implementation.
-	store := mock.New(&sql.DB{})
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
 
 	// Create a new service.
-	serv := service.New(store, &slog.Logger{})
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
 
 	// Create a user.
-	u, err := serv.User.Create(&proto.UserCreateParams{
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
 		Email:    "johndoe@test.com",
 		Password: "TestPassword123",
 	})
@@ -26,9 +33,21 @@ func TestPay(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// Get the user's personal organization.
+	orgs, err := serv.Organization.GetForUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	org := orgs[0]
+
+	// Call the services on behalf of the user.
+	ctx = actor.WithUser(ctx, u.ID)
+
 	// Create an invoice.
-	i, err := serv.Invoice.Create(&proto.InvoiceCreateParams{
-		UserID: u.ID,
+	i, err := serv.Invoice.Create(ctx, &proto.InvoiceCreateParams{
+		OrganizationID: org.ID,
+		UserID:         u.ID,
+		PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
 		BillTo: proto.InvoiceBillTo{
 			FirstName: "John",
 			LastName:  "Smith",
@@ -60,7 +79,7 @@ func TestPay(t *testing.T) {
 	}
 
 	// Pay invoice.
-	i, err = serv.Invoice.Pay(i.ID, &proto.InvoicePayParams{
+	i, err = serv.Invoice.Pay(ctx, i.ID, &proto.InvoicePayParams{
 		Amount: 100,
 	})
 	if err != nil {
@@ -77,4 +96,259 @@ func TestPay(t *testing.T) {
 	if i.Status != "paid" {
 		t.Errorf("Expected status to be '%s', got '%s'", "paid", i.Status)
 	}
+
+	// Pay an invoice that does not exist.
+	if _, err := serv.Invoice.Pay(ctx, i.ID+1, &proto.InvoicePayParams{
+		Amount: 100,
+	}); err != serverrors.ErrInvoiceNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrInvoiceNotFound, err)
+	}
+}
+
+func TestGetCursor(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
+
+	// Create a user.
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
+		Email:    "janedoe@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Get the user's personal organization.
+	orgs, err := serv.Organization.GetForUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	org := orgs[0]
+
+	// Call the services on behalf of the user.
+	ctx = actor.WithUser(ctx, u.ID)
+
+	// Create invoices, oldest first.
+	ids := []uint{}
+	for n := 0; n < 5; n++ {
+		i, err := serv.Invoice.Create(ctx, &proto.InvoiceCreateParams{
+			OrganizationID: org.ID,
+			UserID:         u.ID,
+			PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
+			LineItems: []proto.InvoiceLineItem{
+				{
+					Quantity: 1,
+					Price:    100,
+				},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids = append(ids, i.ID)
+	}
+
+	// checkPage gets a page of invoices and checks the returned IDs.
+	checkPage := func(cursor *proto.InvoiceGetParamsCursor, expected ...uint) []*proto.Invoice {
+		t.Helper()
+
+		invoices, err := serv.Invoice.Get(ctx, &proto.InvoiceGetParams{
+			OrganizationID: &org.ID,
+			Cursor:         cursor,
+			Limit:          2,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(invoices) != len(expected) {
+			t.Fatalf("Expected '%d' invoices, got '%d'", len(expected), len(invoices))
+		}
+		for n, i := range invoices {
+			if i.ID != expected[n] {
+				t.Errorf("Expected invoice '%d' to have ID '%d', got '%d'", n, expected[n], i.ID)
+			}
+		}
+
+		return invoices
+	}
+
+	// Page forward, newest first.
+	page := checkPage(nil, ids[4], ids[3])
+	page = checkPage(&proto.InvoiceGetParamsCursor{
+		CreatedAt: page[1].CreatedAt,
+		ID:        page[1].ID,
+	}, ids[2], ids[1])
+	checkPage(&proto.InvoiceGetParamsCursor{
+		CreatedAt: page[1].CreatedAt,
+		ID:        page[1].ID,
+	}, ids[0])
+
+	// Page backward from the second page.
+	checkPage(&proto.InvoiceGetParamsCursor{
+		CreatedAt: page[0].CreatedAt,
+		ID:        page[0].ID,
+		Before:    true,
+	}, ids[4], ids[3])
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	serv := servicetest.New(store, mailmock.New(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Create a user.
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
+		Email:    "importer@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Get the user's personal organization.
+	orgs, err := serv.Organization.GetForUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	org := orgs[0]
+
+	// Call the services on behalf of the user.
+	ctx = actor.WithUser(ctx, u.ID)
+
+	// importParams returns import parameters with one valid and one invalid
+	// invoice.
+	importParams := func() *proto.InvoiceImportParams {
+		return &proto.InvoiceImportParams{
+			OrganizationID: org.ID,
+			UserID:         u.ID,
+			Invoices: []*proto.InvoiceCreateParams{
+				{
+					PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
+					LineItems: []proto.InvoiceLineItem{
+						{
+							Quantity: 1,
+							Price:    100,
+						},
+					},
+				},
+				{
+					PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
+					TaxRate:        "abc",
+				},
+			},
+		}
+	}
+
+	// checkCount checks the number of invoices for the user.
+	checkCount := func(expected uint) {
+		t.Helper()
+
+		count, err := serv.Invoice.GetCount(ctx, &proto.InvoiceGetParams{
+			OrganizationID: &org.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != expected {
+			t.Errorf("Expected invoice count to be '%d', got '%d'", expected, count)
+		}
+	}
+
+	// Import in dry run mode.
+	params := importParams()
+	params.DryRun = true
+	results, err := serv.Invoice.Import(ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Error != nil {
+		t.Errorf("Expected first invoice to be valid, got '%v'", results[0].Error)
+	}
+	if pes, ok := results[1].Error.(*serverrors.ParamErrors); !ok || pes.Length() != 2 {
+		t.Errorf("Expected second invoice to have '%d' parameter errors, got '%v'", 2, results[1].Error)
+	}
+	checkCount(0)
+
+	// Import in all or nothing mode.
+	params = importParams()
+	params.AllOrNothing = true
+	if _, err = serv.Invoice.Import(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	checkCount(0)
+
+	// Import the valid invoices.
+	results, err = serv.Invoice.Import(ctx, importParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Invoice == nil || results[0].Invoice.UserID != u.ID {
+		t.Errorf("Expected first invoice to be created for user '%d'", u.ID)
+	}
+	if results[1].Invoice != nil {
+		t.Errorf("Expected second invoice to not be created")
+	}
+	checkCount(1)
+
+	// checkEvents checks the number of events in the outbox, which are
+	// claimed with a lease that has already ended so they stay pending.
+	checkEvents := func(expected int) {
+		t.Helper()
+
+		events, err := store.Outbox.Claim(ctx, 10, time.Now().Add(-time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != expected {
+			t.Errorf("Expected '%d' events, got '%d'", expected, len(events))
+		}
+	}
+	checkEvents(1)
+
+	// validParams returns import parameters with two valid invoices of the
+	// given IDs.
+	validParams := func(first, second uint) *proto.InvoiceImportParams {
+		params := importParams()
+		params.AllOrNothing = true
+		params.Invoices[1] = &proto.InvoiceCreateParams{
+			PaymentMethods: params.Invoices[0].PaymentMethods,
+			LineItems:      params.Invoices[0].LineItems,
+		}
+		params.Invoices[0].ID = first
+		params.Invoices[1].ID = second
+
+		return params
+	}
+
+	// Import valid invoices in all or nothing mode where storage fails on
+	// the second, which creates neither invoice nor their events.
+	if _, err := serv.Invoice.Import(ctx, validParams(1000, 1000)); err == nil {
+		t.Error("Expected error for duplicate ID")
+	}
+	checkCount(1)
+	checkEvents(1)
+
+	// Import valid invoices in all or nothing mode.
+	results, err = serv.Invoice.Import(ctx, validParams(1000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, r := range results {
+		if r.Error != nil || r.Invoice == nil {
+			t.Errorf("Expected invoice '%d' to be created, got error '%v'", n, r.Error)
+		}
+	}
+	checkCount(3)
+	checkEvents(3)
 }
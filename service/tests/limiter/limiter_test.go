@@ -0,0 +1,89 @@
+package limiter
+
+import (
+	"testing"
+	"time"
+
+	"dddstructure/cmd/api/limiter"
+)
+
+func TestTake(t *testing.T) {
+	t.Parallel()
+
+	// Allow bursts of 2 requests, refilling a token every 5 seconds.
+	l := limiter.New(2, 10*time.Second)
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name       string
+		key        string
+		after      time.Duration
+		allowed    bool
+		remaining  uint
+		reset      time.Duration
+		retryAfter time.Duration
+	}{
+		{"first", "a", 0, true, 1, 5 * time.Second, 0},
+		{"second", "a", 0, true, 0, 10 * time.Second, 0},
+		{"empty", "a", 0, false, 0, 10 * time.Second, 5 * time.Second},
+		{"other key", "b", 0, true, 1, 5 * time.Second, 0},
+		{"half refilled", "a", 2500 * time.Millisecond, false, 0, 7500 * time.Millisecond, 2500 * time.Millisecond},
+		{"refilled", "a", 5 * time.Second, true, 0, 10 * time.Second, 0},
+		{"refilled past the limit", "a", time.Hour, true, 1, 5 * time.Second, 0},
+	} {
+		result := l.Take(tc.key, now.Add(tc.after))
+		if result.Allowed != tc.allowed {
+			t.Errorf("Expected %s allowed to be '%t', got '%t'", tc.name, tc.allowed, result.Allowed)
+		}
+		if result.Limit != 2 {
+			t.Errorf("Expected %s limit to be '%d', got '%d'", tc.name, 2, result.Limit)
+		}
+		if result.Remaining != tc.remaining {
+			t.Errorf("Expected %s remaining to be '%d', got '%d'", tc.name, tc.remaining, result.Remaining)
+		}
+		if result.Reset != tc.reset {
+			t.Errorf("Expected %s reset to be '%v', got '%v'", tc.name, tc.reset, result.Reset)
+		}
+		if result.RetryAfter != tc.retryAfter {
+			t.Errorf("Expected %s retry after to be '%v', got '%v'", tc.name, tc.retryAfter, result.RetryAfter)
+		}
+	}
+}
+
+func TestSweep(t *testing.T) {
+	t.Parallel()
+
+	l := limiter.New(2, 10*time.Second)
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// Take a token for two keys, and both tokens for a third.
+	l.Take("a", now)
+	l.Take("b", now.Add(5*time.Second))
+	l.Take("c", now.Add(5*time.Second))
+	l.Take("c", now.Add(5*time.Second))
+	if l.Len() != 3 {
+		t.Fatalf("Expected len to be '%d', got '%d'", 3, l.Len())
+	}
+
+	// Check buckets are not swept again within a period, even once full.
+	l.Take("d", now.Add(9*time.Second))
+	if l.Len() != 4 {
+		t.Errorf("Expected len to be '%d', got '%d'", 4, l.Len())
+	}
+
+	// Check only the buckets that have refilled are swept once a period
+	// has passed.
+	l.Take("e", now.Add(11*time.Second))
+	if l.Len() != 3 {
+		t.Errorf("Expected len to be '%d', got '%d'", 3, l.Len())
+	}
+	if result := l.Take("c", now.Add(11*time.Second)); result.Remaining != 0 {
+		t.Errorf("Expected remaining of unswept bucket to be '%d', got '%d'", 0, result.Remaining)
+	}
+
+	// Check every bucket is swept once all have refilled.
+	l.Take("f", now.Add(time.Hour))
+	if l.Len() != 1 {
+		t.Errorf("Expected len to be '%d', got '%d'", 1, l.Len())
+	}
+}
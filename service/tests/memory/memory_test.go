@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"dddstructure/storage/invoice"
+	"dddstructure/storage/memory"
+	"dddstructure/storage/user"
+)
+
+func TestInstances(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// Create two memory storage implementations.
+	a := memory.New()
+	b := memory.New()
+
+	// Create a user in the first.
+	u, err := a.User.Create(ctx, &user.User{Email: "johndoe@test.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 1 {
+		t.Errorf("Expected ID to be '%d', got '%d'", 1, u.ID)
+	}
+
+	// Check the second does not have it.
+	if _, err := b.User.GetByID(ctx, u.ID); err != user.ErrUserNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", user.ErrUserNotFound, err)
+	}
+
+	// Check emails are matched ignoring case, like MySQL.
+	if _, err := a.User.GetByEmail(ctx, "JohnDoe@test.com"); err != nil {
+		t.Errorf("Expected error to be '%v', got '%v'", nil, err)
+	}
+
+	// Check changing a returned user does not change the stored user.
+	u.Email = "changed@test.com"
+	stored, err := a.User.GetByID(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Email != "johndoe@test.com" {
+		t.Errorf("Expected email to be '%s', got '%s'", "johndoe@test.com", stored.Email)
+	}
+}
+
+func TestInvoiceFilters(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create invoices on different days for two organizations.
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	for n := 0; n < 4; n++ {
+		for _, orgID := range []uint{1, 2} {
+			if _, err := store.Invoice.Create(ctx, &invoice.Invoice{
+				OrganizationID: orgID,
+				CreatedAt:      day.AddDate(0, 0, n),
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Get the invoices of the first organization created on the middle
+	// days.
+	orgID := uint(1)
+	start := day.AddDate(0, 0, 1)
+	end := day.AddDate(0, 0, 2)
+	params := &invoice.GetParams{
+		OrganizationID: &orgID,
+		CreatedAt: &invoice.GetParamsCreatedAt{
+			StartDate: &start,
+			EndDate:   &end,
+		},
+		Limit: 10,
+	}
+
+	invoices, err := store.Invoice.Get(ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invoices) != 2 {
+		t.Fatalf("Expected invoices to be '%d', got '%d'", 2, len(invoices))
+	}
+	if !invoices[0].CreatedAt.Equal(end) || !invoices[1].CreatedAt.Equal(start) {
+		t.Errorf("Expected invoices to be newest first, got '%v' and '%v'", invoices[0].CreatedAt, invoices[1].CreatedAt)
+	}
+
+	count, err := store.Invoice.GetCount(ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("Expected count to be '%d', got '%d'", 2, count)
+	}
+
+	// Delete an invoice that does not exist.
+	if err := store.Invoice.Delete(ctx, 100); err != invoice.ErrInvoiceNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", invoice.ErrInvoiceNotFound, err)
+	}
+}
+
+func TestConcurrentCreate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create invoices concurrently.
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := 0; m < 10; m++ {
+				if _, err := store.Invoice.Create(ctx, &invoice.Invoice{}); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	count, err := store.Invoice.GetCount(ctx, &invoice.GetParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 100 {
+		t.Errorf("Expected count to be '%d', got '%d'", 100, count)
+	}
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	mailmock "dddstructure/mail/mock"
+	"dddstructure/metrics"
+	"dddstructure/proto"
+	"dddstructure/service/actor"
+	"dddstructure/service/tests/servicetest"
+	"dddstructure/storage/instrumented"
+	"dddstructure/storage/memory"
+)
+
+func TestStorageMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation, recording metrics.
+	registry := metrics.NewRegistry()
+	store := instrumented.New(memory.New(), registry)
+
+	// Create a new service.
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
+
+	// Create a user.
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
+		Email:    "johndoe@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Get the user's personal organization.
+	orgs, err := serv.Organization.GetForUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	org := orgs[0]
+
+	// Call the services on behalf of the user.
+	ctx = actor.WithUser(ctx, u.ID)
+
+	// Create an invoice.
+	i, err := serv.Invoice.Create(ctx, &proto.InvoiceCreateParams{
+		OrganizationID: org.ID,
+		UserID:         u.ID,
+		PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
+		BillTo: proto.InvoiceBillTo{
+			FirstName: "John",
+			LastName:  "Smith",
+		},
+		PayTo: proto.InvoicePayTo{
+			FirstName: "John",
+			LastName:  "Doe",
+		},
+		LineItems: []proto.InvoiceLineItem{
+			{
+				Quantity: 1,
+				Price:    100,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pay the invoice.
+	if _, err := serv.Invoice.Pay(ctx, i.ID, &proto.InvoicePayParams{
+		Amount: 100,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Refund part of the invoice.
+	if _, err := serv.Transaction.Process(ctx, &proto.TransactionProcessParams{
+		OrganizationID: org.ID,
+		UserID:         u.ID,
+		InvoiceID:      i.ID,
+		Type:           "refund",
+		Amount:         40,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write the metrics.
+	var buf bytes.Buffer
+	if err := registry.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	// Check the metrics.
+	for _, line := range []string{
+		`dddstructure_invoices_created_total{currency="` + i.Currency + `"} 1`,
+		`dddstructure_payments_total{status="approved"} 1`,
+		`dddstructure_refunded_amount_total{currency="` + i.Currency + `"} 40`,
+		`dddstructure_storage_call_duration_seconds_count{repository="invoice",method="Create"} 1`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Expected metrics to contain '%s', got '%s'", line, out)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"dddstructure/db"
+	"dddstructure/db/migrate"
+	mysqlmigrate "dddstructure/storage/mysql/migrate"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestLoad(t *testing.T) {
+	// Load the embedded migrations.
+	migrations, err := migrate.Load(db.Migrations, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the baseline migration comes first.
+	if len(migrations) == 0 {
+		t.Fatal("Expected migrations to be embedded")
+	}
+	if migrations[0].Version != 1 || migrations[0].Name != "baseline" {
+		t.Errorf("Expected first migration to be '%s', got '%d_%s'", "1_baseline", migrations[0].Version, migrations[0].Name)
+	}
+
+	// Check the migrations are in order.
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version <= migrations[i-1].Version {
+			t.Errorf("Expected migration '%d' to come after '%d'", migrations[i].Version, migrations[i-1].Version)
+		}
+	}
+
+	// Load migrations in order of version, not name.
+	migrations, err = migrate.Load(fstest.MapFS{
+		"m/10_b.up.sql":   {Data: []byte("up 10")},
+		"m/10_b.down.sql": {Data: []byte("down 10")},
+		"m/9_a.up.sql":    {Data: []byte("up 9")},
+		"m/9_a.down.sql":  {Data: []byte("down 9")},
+	}, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 9 || migrations[1].Version != 10 {
+		t.Fatalf("Expected migrations '9' and '10' in order, got '%+v'", migrations)
+	}
+	if migrations[0].Up != "up 9" || migrations[0].Down != "down 9" {
+		t.Errorf("Expected migration '9' files to be loaded, got '%+v'", migrations[0])
+	}
+
+	// Load invalid migrations.
+	for name, fsys := range map[string]fstest.MapFS{
+		"missing down file": {
+			"m/1_a.up.sql": {Data: []byte("up")},
+		},
+		"duplicate version": {
+			"m/1_a.up.sql":   {Data: []byte("up")},
+			"m/1_a.down.sql": {Data: []byte("down")},
+			"m/1_b.up.sql":   {Data: []byte("up")},
+			"m/1_b.down.sql": {Data: []byte("down")},
+		},
+		"duplicate padded version": {
+			"m/0001_a.up.sql": {Data: []byte("up")},
+			"m/1_a.up.sql":    {Data: []byte("up")},
+			"m/1_a.down.sql":  {Data: []byte("down")},
+		},
+		"invalid file name": {
+			"m/a.up.sql": {Data: []byte("up")},
+		},
+		"version zero": {
+			"m/0_a.up.sql":   {Data: []byte("up")},
+			"m/0_a.down.sql": {Data: []byte("down")},
+		},
+	} {
+		if _, err := migrate.Load(fsys, "m"); err == nil {
+			t.Errorf("Expected error for %s", name)
+		}
+	}
+}
+
+func TestLoadPostgres(t *testing.T) {
+	// Load the embedded Postgres migrations.
+	migrations, err := migrate.Load(db.PostgresMigrations, "postgres/migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the baseline migration comes first.
+	if len(migrations) == 0 {
+		t.Fatal("Expected migrations to be embedded")
+	}
+	if migrations[0].Version != 1 || migrations[0].Name != "baseline" {
+		t.Errorf("Expected first migration to be '%s', got '%d_%s'", "1_baseline", migrations[0].Version, migrations[0].Name)
+	}
+}
+
+// TestMySQLMigrator runs the migrator against the MySQL database in the
+// STORAGETEST_MYSQL_DSN environment variable, such as
+// "root:pass@tcp(localhost:3306)/dddstructure_test?parseTime=true". The
+// schema_migrations table is dropped before and after the test, so never
+// point it at real data.
+func TestMySQLMigrator(t *testing.T) {
+	dsn := os.Getenv("STORAGETEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("STORAGETEST_MYSQL_DSN is not set")
+	}
+
+	ctx := context.Background()
+
+	// Migration files hold many statements, so they are allowed in a single
+	// query.
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.MultiStatements = true
+
+	conn, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	reset := func() {
+		for _, table := range []string{"schema_migrations", "migrate_test_a", "migrate_test_b", "migrate_test_c"} {
+			if _, err := conn.Exec("DROP TABLE IF EXISTS `" + table + "`"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	reset()
+	defer reset()
+
+	migrations, err := migrate.Load(fstest.MapFS{
+		"m/1_a.up.sql":   {Data: []byte("CREATE TABLE `migrate_test_a` (`id` int NOT NULL PRIMARY KEY)")},
+		"m/1_a.down.sql": {Data: []byte("DROP TABLE `migrate_test_a`")},
+		"m/2_b.up.sql":   {Data: []byte("CREATE TABLE `migrate_test_b` (`id` int NOT NULL PRIMARY KEY)")},
+		"m/2_b.down.sql": {Data: []byte("DROP TABLE `migrate_test_b`")},
+		"m/3_c.up.sql":   {Data: []byte("CREATE TABLE `migrate_test_c` (`id` int NOT NULL PRIMARY KEY); INSERT INTO `migrate_test_c` (`id`) VALUES (1)")},
+		"m/3_c.down.sql": {Data: []byte("DROP TABLE `migrate_test_c`")},
+	}, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := mysqlmigrate.New(conn, migrations, time.Second, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// check checks the given versions are applied, and only their tables
+	// exist.
+	check := func(step string, versions ...uint) {
+		t.Helper()
+
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		applied := []uint{}
+		for _, s := range statuses {
+			if s.AppliedAt != nil {
+				applied = append(applied, s.Migration.Version)
+			}
+		}
+		if versions == nil {
+			versions = []uint{}
+		}
+		if !reflect.DeepEqual(applied, versions) {
+			t.Errorf("Expected applied versions after %s to be '%v', got '%v'", step, versions, applied)
+		}
+
+		for i, table := range []string{"migrate_test_a", "migrate_test_b", "migrate_test_c"} {
+			_, err := conn.Exec("SELECT 1 FROM `" + table + "`")
+			if exists := err == nil; exists != (len(versions) > i) {
+				t.Errorf("Expected table %s after %s to exist to be '%t', got '%t'", table, step, len(versions) > i, exists)
+			}
+		}
+	}
+
+	// Migrate to a version, then up, down and back to nothing.
+	if err := m.To(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	check("to 2", 1, 2)
+
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	check("up", 1, 2, 3)
+
+	if err := m.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+	check("down", 1, 2)
+
+	if err := m.To(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	check("to 0")
+
+	if err := m.Down(ctx); err != nil {
+		t.Errorf("Expected error of down with nothing applied to be '%v', got '%v'", nil, err)
+	}
+
+	if err := m.To(ctx, 4); err != migrate.ErrUnknownVersion {
+		t.Errorf("Expected error to be '%v', got '%v'", migrate.ErrUnknownVersion, err)
+	}
+
+	// Record a baseline without running the migrations, which is only
+	// allowed once.
+	if err := m.Baseline(ctx, 4); err != migrate.ErrUnknownVersion {
+		t.Errorf("Expected error to be '%v', got '%v'", migrate.ErrUnknownVersion, err)
+	}
+	if err := m.Baseline(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses[0].AppliedAt == nil || statuses[1].AppliedAt != nil {
+		t.Errorf("Expected only version '%d' to be recorded, got '%+v'", 1, statuses)
+	}
+	if err := m.Baseline(ctx, 1); err != migrate.ErrAlreadyApplied {
+		t.Errorf("Expected error to be '%v', got '%v'", migrate.ErrAlreadyApplied, err)
+	}
+	if _, err := conn.Exec("DELETE FROM `schema_migrations`"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Check a version applied by a newer binary is left alone when
+	// migrating up, but can not be rolled back.
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec("INSERT INTO `schema_migrations` (`version`, `name`, `applied_at`) VALUES (4, 'd', ?)", time.Now().UTC()); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(ctx); err != nil {
+		t.Errorf("Expected error of up to be '%v', got '%v'", nil, err)
+	}
+	if err := m.Down(ctx); !errors.Is(err, migrate.ErrUnknownVersion) {
+		t.Errorf("Expected error of down to be '%v', got '%v'", migrate.ErrUnknownVersion, err)
+	}
+	if err := m.To(ctx, 2); !errors.Is(err, migrate.ErrUnknownVersion) {
+		t.Errorf("Expected error of to 2 to be '%v', got '%v'", migrate.ErrUnknownVersion, err)
+	}
+	check("unknown version", 1, 2, 3)
+	if _, err := conn.Exec("DELETE FROM `schema_migrations` WHERE `version`=4"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Check only one migration runs at a time.
+	lock, err := conn.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Close()
+
+	var locked int
+	if err := lock.QueryRowContext(ctx, "SELECT GET_LOCK('dddstructure_migrate', 0)").Scan(&locked); err != nil {
+		t.Fatal(err)
+	}
+	if locked != 1 {
+		t.Fatal("Expected lock to be taken")
+	}
+
+	if err := mysqlmigrate.New(conn, migrations, 0, slog.New(slog.NewTextHandler(io.Discard, nil))).Down(ctx); err != migrate.ErrLocked {
+		t.Errorf("Expected error to be '%v', got '%v'", migrate.ErrLocked, err)
+	}
+
+	if err := lock.QueryRowContext(ctx, "SELECT RELEASE_LOCK('dddstructure_migrate')").Scan(&locked); err != nil {
+		t.Fatal(err)
+	}
+	check("locked down", 1, 2, 3)
+
+	if err := m.To(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	check("unlocked to 0")
+}
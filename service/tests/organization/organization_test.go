@@ -0,0 +1,443 @@
+package organization
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+	"testing"
+
+	mailmock "dddstructure/mail/mock"
+	"dddstructure/proto"
+	"dddstructure/service"
+	"dddstructure/service/actor"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/service/tests/servicetest"
+	"dddstructure/storage/memory"
+)
+
+// createUser creates a user and returns it with its personal organization.
+func createUser(t *testing.T, serv *service.Service, email string) (*proto.User, *proto.Organization) {
+	ctx := context.Background()
+
+	t.Helper()
+
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
+		Email:    email,
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orgs, err := serv.Organization.GetForUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orgs) != 1 {
+		t.Fatalf("Expected user to be a member of '%d' organization, got '%d'", 1, len(orgs))
+	}
+
+	return u, orgs[0]
+}
+
+// addMember adds a user to an organization with the given role by inviting
+// them and accepting the invitation.
+func addMember(t *testing.T, serv *service.Service, mailer *mailmock.Sender, org *proto.Organization, by, u *proto.User, role proto.OrganizationRole) {
+	ctx := context.Background()
+
+	t.Helper()
+
+	if err := serv.Organization.Invite(ctx, &proto.OrganizationInviteParams{
+		OrganizationID: org.ID,
+		Email:          u.Email,
+		Role:           role,
+		ByUserID:       by.ID,
+		Link:           "https://example.com/invitations/accept",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := serv.Organization.AcceptInvitation(ctx, &proto.OrganizationAcceptInvitationParams{
+		Token:  mailToken(t, mailer, u.Email),
+		UserID: u.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// mailToken returns the token from the link in the last message sent to the
+// given address.
+func mailToken(t *testing.T, mailer *mailmock.Sender, to string) string {
+	m := mailer.Last(to)
+	if m == nil {
+		t.Fatalf("Expected a message to be sent to '%s'", to)
+	}
+
+	for _, field := range strings.Fields(m.Body) {
+		if u, err := url.Parse(field); err == nil && u.Query().Get("token") != "" {
+			return u.Query().Get("token")
+		}
+	}
+
+	t.Fatalf("Expected message to contain a token link, got '%s'", m.Body)
+	return ""
+}
+
+// hasParamError checks if the given error is a ParamErrors containing an
+// error for the given parameter.
+func hasParamError(err error, name string) bool {
+	pes, ok := err.(*serverrors.ParamErrors)
+	if !ok {
+		return false
+	}
+
+	for _, pe := range *pes {
+		if pe.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestAuthorize(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	mailer := mailmock.New()
+	serv := servicetest.New(store, mailer, &slog.Logger{})
+
+	// Create an owner and a read only member.
+	owner, org := createUser(t, serv, "owner@test.com")
+	reader, _ := createUser(t, serv, "reader@test.com")
+	outsider, _ := createUser(t, serv, "outsider@test.com")
+	addMember(t, serv, mailer, org, owner, reader, proto.OrganizationRoleReadOnly)
+
+	// checkAuthorize checks authorizing a user for a permission.
+	checkAuthorize := func(u *proto.User, permission proto.OrganizationPermission, expected error) {
+		t.Helper()
+
+		_, err := serv.Organization.Authorize(ctx, &proto.OrganizationAuthorizeParams{
+			OrganizationID: org.ID,
+			UserID:         u.ID,
+			Permission:     permission,
+		})
+		if err != expected {
+			t.Errorf("Expected authorizing '%s' for '%s' to return '%v', got '%v'", u.Email, permission, expected, err)
+		}
+	}
+
+	checkAuthorize(owner, proto.OrganizationPermissionMembersWrite, nil)
+	checkAuthorize(owner, proto.OrganizationPermissionInvoiceWrite, nil)
+	checkAuthorize(reader, proto.OrganizationPermissionInvoiceRead, nil)
+	checkAuthorize(reader, proto.OrganizationPermissionReportRead, nil)
+	checkAuthorize(reader, proto.OrganizationPermissionInvoiceWrite, serverrors.ErrOrganizationForbidden)
+	checkAuthorize(reader, proto.OrganizationPermissionTransaction, serverrors.ErrOrganizationForbidden)
+	checkAuthorize(outsider, proto.OrganizationPermissionInvoiceRead, serverrors.ErrOrganizationNotFound)
+
+	// Check invoices are shared within the organization.
+	i, err := serv.Invoice.Create(actor.WithUser(ctx, owner.ID), &proto.InvoiceCreateParams{
+		OrganizationID: org.ID,
+		UserID:         owner.ID,
+		PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
+		LineItems: []proto.InvoiceLineItem{
+			{
+				Quantity: 1,
+				Price:    100,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orgs, err := serv.Organization.GetForUser(ctx, reader.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orgs) != 2 || orgs[0].ID != org.ID || orgs[0].Role != proto.OrganizationRoleReadOnly {
+		t.Fatalf("Expected reader to be a read only member of organization '%d'", org.ID)
+	}
+
+	readerCtx := actor.WithUser(ctx, reader.ID)
+	if _, err := serv.Invoice.GetByIDAndOrganizationID(readerCtx, i.ID, orgs[0].ID); err != nil {
+		t.Errorf("Expected reader to get the invoice, got '%v'", err)
+	}
+	if _, err := serv.Invoice.GetByIDAndOrganizationID(readerCtx, i.ID, orgs[1].ID); err != serverrors.ErrInvoiceNotFound {
+		t.Errorf("Expected invoice to not be found in another organization, got '%v'", err)
+	}
+}
+
+func TestAuthorizeServices(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	mailer := mailmock.New()
+	serv := servicetest.New(store, mailer, &slog.Logger{})
+
+	// Create an owner, a read only member and a user of another
+	// organization.
+	owner, org := createUser(t, serv, "owner@test.com")
+	reader, _ := createUser(t, serv, "reader@test.com")
+	outsider, _ := createUser(t, serv, "outsider@test.com")
+	addMember(t, serv, mailer, org, owner, reader, proto.OrganizationRoleReadOnly)
+
+	ownerCtx := actor.WithUser(ctx, owner.ID)
+	readerCtx := actor.WithUser(ctx, reader.ID)
+	outsiderCtx := actor.WithUser(ctx, outsider.ID)
+
+	params := &proto.InvoiceCreateParams{
+		OrganizationID: org.ID,
+		UserID:         owner.ID,
+		PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
+		LineItems: []proto.InvoiceLineItem{
+			{
+				Quantity: 1,
+				Price:    100,
+			},
+		},
+	}
+
+	// Calls without an actor are forbidden.
+	if _, err := serv.Invoice.Create(ctx, params); err != serverrors.ErrOrganizationForbidden {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrOrganizationForbidden, err)
+	}
+
+	// Read only members can not create invoices.
+	if _, err := serv.Invoice.Create(readerCtx, params); err != serverrors.ErrOrganizationForbidden {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrOrganizationForbidden, err)
+	}
+
+	i, err := serv.Invoice.Create(ownerCtx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read only members can read the invoice, but not change it.
+	if _, err := serv.Invoice.GetByID(readerCtx, i.ID); err != nil {
+		t.Errorf("Expected reader to get the invoice, got '%v'", err)
+	}
+	message := "Changed"
+	if _, err := serv.Invoice.Update(readerCtx, &proto.InvoiceUpdateParams{ID: &i.ID, Message: &message}); err != serverrors.ErrOrganizationForbidden {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrOrganizationForbidden, err)
+	}
+	if err := serv.Invoice.Delete(readerCtx, i.ID); err != serverrors.ErrOrganizationForbidden {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrOrganizationForbidden, err)
+	}
+
+	// The invoice is not found by users of other organizations.
+	if _, err := serv.Invoice.GetByID(outsiderCtx, i.ID); err != serverrors.ErrInvoiceNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrInvoiceNotFound, err)
+	}
+	if _, err := serv.Invoice.Update(outsiderCtx, &proto.InvoiceUpdateParams{ID: &i.ID, Message: &message}); err != serverrors.ErrInvoiceNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrInvoiceNotFound, err)
+	}
+	if err := serv.Invoice.Delete(outsiderCtx, i.ID); err != serverrors.ErrInvoiceNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrInvoiceNotFound, err)
+	}
+	orgID := org.ID
+	if _, err := serv.Invoice.Get(outsiderCtx, &proto.InvoiceGetParams{OrganizationID: &orgID}); err != serverrors.ErrOrganizationNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrOrganizationNotFound, err)
+	}
+
+	// Only the system gets the invoices of every organization.
+	if _, err := serv.Invoice.Get(ownerCtx, &proto.InvoiceGetParams{}); err != serverrors.ErrOrganizationNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrOrganizationNotFound, err)
+	}
+	invoices, err := serv.Invoice.Get(actor.WithSystem(ctx), &proto.InvoiceGetParams{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invoices) != 1 {
+		t.Errorf("Expected '%d' invoices, got '%d'", 1, len(invoices))
+	}
+
+	// Read only members can not process transactions, and reports are only
+	// read by members.
+	if _, err := serv.Transaction.Process(readerCtx, &proto.TransactionProcessParams{
+		OrganizationID: org.ID,
+		UserID:         reader.ID,
+		Type:           "sale",
+		Amount:         100,
+		InvoiceID:      i.ID,
+	}); err != serverrors.ErrOrganizationForbidden {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrOrganizationForbidden, err)
+	}
+	if _, err := serv.Report.GetBalances(readerCtx, &proto.ReportBalancesParams{OrganizationID: org.ID}); err != nil {
+		t.Errorf("Expected reader to get balances, got '%v'", err)
+	}
+	if _, err := serv.Report.GetBalances(outsiderCtx, &proto.ReportBalancesParams{OrganizationID: org.ID}); err != serverrors.ErrOrganizationNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrOrganizationNotFound, err)
+	}
+
+	// The owner can delete the invoice.
+	if err := serv.Invoice.Delete(ownerCtx, i.ID); err != nil {
+		t.Errorf("Expected owner to delete the invoice, got '%v'", err)
+	}
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	mailer := mailmock.New()
+	serv := servicetest.New(store, mailer, &slog.Logger{})
+
+	// Create the users.
+	owner, org := createUser(t, serv, "inviter@test.com")
+	invited, _ := createUser(t, serv, "invited@test.com")
+	other, _ := createUser(t, serv, "other@test.com")
+
+	// Invite a user.
+	if err := serv.Organization.Invite(ctx, &proto.OrganizationInviteParams{
+		OrganizationID: org.ID,
+		Email:          invited.Email,
+		Role:           proto.OrganizationRoleAccountant,
+		ByUserID:       owner.ID,
+		Link:           "https://example.com/invitations/accept",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	token := mailToken(t, mailer, invited.Email)
+
+	// Accept the invitation as another user.
+	_, err := serv.Organization.AcceptInvitation(ctx, &proto.OrganizationAcceptInvitationParams{
+		Token:  token,
+		UserID: other.ID,
+	})
+	if !hasParamError(err, "token") {
+		t.Errorf("Expected a token parameter error, got '%v'", err)
+	}
+
+	// Accept the invitation.
+	member, err := serv.Organization.AcceptInvitation(ctx, &proto.OrganizationAcceptInvitationParams{
+		Token:  token,
+		UserID: invited.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member.OrganizationID != org.ID || member.Role != proto.OrganizationRoleAccountant {
+		t.Errorf("Expected user to be an accountant of organization '%d', got '%s' of '%d'", org.ID, member.Role, member.OrganizationID)
+	}
+
+	// Accept the invitation a second time.
+	_, err = serv.Organization.AcceptInvitation(ctx, &proto.OrganizationAcceptInvitationParams{
+		Token:  token,
+		UserID: invited.ID,
+	})
+	if !hasParamError(err, "token") {
+		t.Errorf("Expected a token parameter error, got '%v'", err)
+	}
+
+	// Invite a user who is already a member.
+	err = serv.Organization.Invite(ctx, &proto.OrganizationInviteParams{
+		OrganizationID: org.ID,
+		Email:          invited.Email,
+		Role:           proto.OrganizationRoleAdmin,
+		ByUserID:       owner.ID,
+		Link:           "https://example.com/invitations/accept",
+	})
+	if !hasParamError(err, "email") {
+		t.Errorf("Expected an email parameter error, got '%v'", err)
+	}
+
+	// Check members.
+	members, err := serv.Organization.GetMembers(ctx, org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 {
+		t.Fatalf("Expected '%d' members, got '%d'", 2, len(members))
+	}
+	if members[1].Email != invited.Email {
+		t.Errorf("Expected member email to be '%s', got '%s'", invited.Email, members[1].Email)
+	}
+}
+
+func TestOwners(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	mailer := mailmock.New()
+	serv := servicetest.New(store, mailer, &slog.Logger{})
+
+	// Create an owner and an admin.
+	owner, org := createUser(t, serv, "founder@test.com")
+	admin, _ := createUser(t, serv, "admin@test.com")
+	addMember(t, serv, mailer, org, owner, admin, proto.OrganizationRoleAdmin)
+
+	// Grant the owner role as an admin.
+	_, err := serv.Organization.UpdateMember(ctx, &proto.OrganizationUpdateMemberParams{
+		OrganizationID: org.ID,
+		UserID:         admin.ID,
+		Role:           proto.OrganizationRoleOwner,
+		ByUserID:       admin.ID,
+	})
+	if err != serverrors.ErrOrganizationForbidden {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrOrganizationForbidden, err)
+	}
+
+	// Remove the owner as an admin.
+	err = serv.Organization.RemoveMember(ctx, &proto.OrganizationRemoveMemberParams{
+		OrganizationID: org.ID,
+		UserID:         owner.ID,
+		ByUserID:       admin.ID,
+	})
+	if err != serverrors.ErrOrganizationForbidden {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrOrganizationForbidden, err)
+	}
+
+	// Change the role of the last owner.
+	_, err = serv.Organization.UpdateMember(ctx, &proto.OrganizationUpdateMemberParams{
+		OrganizationID: org.ID,
+		UserID:         owner.ID,
+		Role:           proto.OrganizationRoleAdmin,
+		ByUserID:       owner.ID,
+	})
+	if err != serverrors.ErrOrganizationLastOwner {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrOrganizationLastOwner, err)
+	}
+
+	// Grant the owner role as the owner, after which the first owner can
+	// leave.
+	if _, err := serv.Organization.UpdateMember(ctx, &proto.OrganizationUpdateMemberParams{
+		OrganizationID: org.ID,
+		UserID:         admin.ID,
+		Role:           proto.OrganizationRoleOwner,
+		ByUserID:       owner.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := serv.Organization.RemoveMember(ctx, &proto.OrganizationRemoveMemberParams{
+		OrganizationID: org.ID,
+		UserID:         owner.ID,
+		ByUserID:       owner.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove the last owner.
+	err = serv.Organization.RemoveMember(ctx, &proto.OrganizationRemoveMemberParams{
+		OrganizationID: org.ID,
+		UserID:         admin.ID,
+		ByUserID:       admin.ID,
+	})
+	if err != serverrors.ErrOrganizationLastOwner {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrOrganizationLastOwner, err)
+	}
+}
@@ -0,0 +1,340 @@
+package relay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"dddstructure/relay"
+	"dddstructure/relay/webhook"
+	"dddstructure/relay/writer"
+	"dddstructure/storage/invoice"
+	storagememory "dddstructure/storage/memory"
+	"dddstructure/storage/outbox"
+	"dddstructure/storage/transaction"
+)
+
+// recordingSink records the events it publishes, failing while fail is set,
+// and always failing events of the type failType.
+type recordingSink struct {
+	mu       sync.Mutex
+	fail     bool
+	failType string
+	events   []*outbox.Event
+}
+
+// Publish implements the relay.Sink interface.
+func (s *recordingSink) Publish(ctx context.Context, e *outbox.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fail {
+		return errors.New("sink unavailable")
+	}
+	if e.Type == s.failType {
+		return errors.New("sink rejected event")
+	}
+	s.events = append(s.events, e)
+
+	return nil
+}
+
+func TestRelay(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation, and an invoice which is
+	// paid then refunded.
+	store := storagememory.New()
+
+	inv, err := store.Invoice.Create(ctx, &invoice.Invoice{
+		OrganizationID: 1,
+		InvoiceNumber:  "INV-1",
+		Currency:       "USD",
+		AmountDue:      1000,
+		Status:         "pending",
+		DueDate:        time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC),
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inv.Status = "paid"
+	inv.AmountPaid = 1000
+	if _, err := store.Invoice.Update(ctx, inv); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Transaction.Create(ctx, &transaction.Transaction{
+		OrganizationID: 1,
+		Type:           "refund",
+		AmountCaptured: 1000,
+		InvoiceID:      inv.ID,
+		Status:         "approved",
+		CreatedAt:      time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Flush while the sink fails, which publishes nothing and keeps the
+	// events. They are tried again almost at once, rather than after a
+	// second.
+	sink := &recordingSink{fail: true}
+	r := relay.New(store.Outbox, sink, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	r.SetRetryPolicy(relay.RetryPolicy{MinBackoff: time.Nanosecond})
+
+	if n, err := r.Flush(ctx); err == nil || n != 0 {
+		t.Errorf("Expected error and '%d' events published, got '%v' and '%d'", 0, err, n)
+	}
+
+	pending := getPending(t, store.Outbox)
+	if len(pending) != 3 {
+		t.Fatalf("Expected '%d' pending events, got '%d'", 3, len(pending))
+	}
+	for n, e := range pending {
+		if e.Attempts != 1 || e.LastError != "sink unavailable" {
+			t.Errorf("Expected event '%d' to have '%d' attempt, got '%d' and error '%s'", n, 1, e.Attempts, e.LastError)
+		}
+	}
+
+	// Flush once the sink recovers, which publishes every event in order.
+	sink.mu.Lock()
+	sink.fail = false
+	sink.mu.Unlock()
+
+	if n, err := r.Flush(ctx); err != nil || n != 3 {
+		t.Fatalf("Expected '%d' events published, got '%d' and error '%v'", 3, n, err)
+	}
+
+	want := []string{outbox.TypeInvoiceCreated, outbox.TypeInvoicePaid, outbox.TypeTransactionRefunded}
+	for n, e := range sink.events {
+		if e.Type != want[n] {
+			t.Errorf("Expected event '%d' to be '%s', got '%s'", n, want[n], e.Type)
+		}
+		if e.ID != pending[n].ID {
+			t.Errorf("Expected event '%d' to keep ID '%s', got '%s'", n, pending[n].ID, e.ID)
+		}
+	}
+
+	if pending := getPending(t, store.Outbox); len(pending) != 0 {
+		t.Errorf("Expected '%d' pending events, got '%d'", 0, len(pending))
+	}
+
+	if err := r.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRelayDeadLetter(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// Create a new memory storage implementation, and an invoice which is
+	// paid, whose created event the sink always rejects.
+	store := storagememory.New()
+
+	inv, err := store.Invoice.Create(ctx, &invoice.Invoice{
+		OrganizationID: 1,
+		InvoiceNumber:  "INV-1",
+		Currency:       "USD",
+		AmountDue:      1000,
+		Status:         "pending",
+		DueDate:        time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC),
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inv.Status = "paid"
+	inv.AmountPaid = 1000
+	if _, err := store.Invoice.Update(ctx, inv); err != nil {
+		t.Fatal(err)
+	}
+
+	sink := &recordingSink{failType: outbox.TypeInvoiceCreated}
+	r := relay.New(store.Outbox, sink, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	r.SetRetryPolicy(relay.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Nanosecond})
+
+	// Flush, which publishes the paid event behind the rejected one.
+	if n, err := r.Flush(ctx); err == nil || n != 1 {
+		t.Errorf("Expected error and '%d' events published, got '%v' and '%d'", 1, err, n)
+	}
+	if len(sink.events) != 1 || sink.events[0].Type != outbox.TypeInvoicePaid {
+		t.Fatalf("Expected only the '%s' event to be published, got '%d' events", outbox.TypeInvoicePaid, len(sink.events))
+	}
+
+	// Flush until the rejected event has failed every attempt, which
+	// dead-letters it.
+	for i := 0; i < 2; i++ {
+		if n, err := r.Flush(ctx); err == nil || n != 0 {
+			t.Errorf("Expected error and '%d' events published, got '%v' and '%d'", 0, err, n)
+		}
+	}
+
+	if pending := getPending(t, store.Outbox); len(pending) != 0 {
+		t.Errorf("Expected '%d' pending events, got '%d'", 0, len(pending))
+	}
+
+	dead, err := store.Outbox.GetDeadLetters(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 1 || dead[0].Type != outbox.TypeInvoiceCreated {
+		t.Fatalf("Expected the '%s' event to be dead, got '%d' events", outbox.TypeInvoiceCreated, len(dead))
+	}
+	if dead[0].Attempts != 3 || dead[0].LastError != "sink rejected event" {
+		t.Errorf("Expected '%d' attempts and error '%s', got '%d' and '%s'", 3, "sink rejected event", dead[0].Attempts, dead[0].LastError)
+	}
+
+	// Flush again, which no longer tries the dead event.
+	if n, err := r.Flush(ctx); err != nil || n != 0 {
+		t.Errorf("Expected '%d' events published, got '%d' and error '%v'", 0, n, err)
+	}
+
+	if err := r.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	e := &outbox.Event{
+		ID:        "1f0c9d2e-7a64-4b4e-9a0a-3f7f0f1d2c3b",
+		Type:      outbox.TypeInvoiceCreated,
+		Version:   1,
+		Payload:   []byte(`{"id":1}`),
+		CreatedAt: time.Date(2024, time.April, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	// Write events as lines of JSON.
+	var buf bytes.Buffer
+	sink := writer.New(&buf)
+	for i := 0; i < 2; i++ {
+		if err := sink.Publish(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected '%d' lines, got '%d'", 2, len(lines))
+	}
+
+	want := `{"id":"1f0c9d2e-7a64-4b4e-9a0a-3f7f0f1d2c3b","type":"invoice.created","version":1,"created_at":"2024-04-01T12:00:00Z","payload":{"id":1}}`
+	if lines[0] != want {
+		t.Errorf("Expected line to be '%s', got '%s'", want, lines[0])
+	}
+
+	// Append events to a file.
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := writer.New(f).Publish(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != want+"\n" {
+		t.Errorf("Expected file to be '%s', got '%s'", want+"\n", b)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// Create a server that fails the first request, and records the rest.
+	var mu sync.Mutex
+	var requests int
+	var got []*http.Request
+	var bodies []relay.Envelope
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var env relay.Envelope
+		if err := json.NewDecoder(r.Body).Decode(&env); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		got = append(got, r)
+		bodies = append(bodies, env)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	e := &outbox.Event{
+		ID:        "1f0c9d2e-7a64-4b4e-9a0a-3f7f0f1d2c3b",
+		Type:      outbox.TypeInvoicePaid,
+		Version:   1,
+		Payload:   []byte(`{"id":1,"status":"paid"}`),
+		CreatedAt: time.Date(2024, time.April, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	// The first post fails, and the second succeeds.
+	sink := webhook.New(server.URL)
+	if err := sink.Publish(ctx, e); err == nil {
+		t.Error("Expected error for failed response")
+	}
+	if err := sink.Publish(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(got) != 1 {
+		t.Fatalf("Expected '%d' requests recorded, got '%d'", 1, len(got))
+	}
+	if v := got[0].Header.Get(webhook.IdempotencyKeyHeader); v != e.ID {
+		t.Errorf("Expected idempotency key to be '%s', got '%s'", e.ID, v)
+	}
+	if v := got[0].Header.Get(webhook.EventTypeHeader); v != e.Type {
+		t.Errorf("Expected event type to be '%s', got '%s'", e.Type, v)
+	}
+	if bodies[0].ID != e.ID || bodies[0].Type != e.Type || bodies[0].Version != e.Version || string(bodies[0].Payload) != string(e.Payload) {
+		t.Errorf("Expected envelope of event '%+v', got '%+v'", e, bodies[0])
+	}
+}
+
+// getPending gets the pending events of an outbox, failing the test on
+// error. The events are claimed with a lease that has already ended, so they
+// stay pending.
+func getPending(t *testing.T, db outbox.Database) []*outbox.Event {
+	t.Helper()
+
+	events, err := db.Claim(context.Background(), 10, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return events
+}
@@ -0,0 +1,401 @@
+package remote
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mailmock "dddstructure/mail/mock"
+	"dddstructure/proto"
+	"dddstructure/service"
+	"dddstructure/service/actor"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/service/interfaces"
+	"dddstructure/service/remote"
+	"dddstructure/service/tests/servicetest"
+	"dddstructure/storage"
+	"dddstructure/storage/memory"
+)
+
+// token defines the token shared by the servers and clients.
+const token = "secret"
+
+// split runs the invoice, transaction and user services as if each was its
+// own process, sharing the given storage, and returns a client of each.
+func split(t *testing.T, store *storage.Storage) (*remote.Invoice, *remote.Transaction, *remote.User) {
+	// Create a service for each process.
+	invoiceServ := servicetest.New(store, mailmock.New(), &slog.Logger{})
+	transactionServ := servicetest.New(store, mailmock.New(), &slog.Logger{})
+	userServ := servicetest.New(store, mailmock.New(), &slog.Logger{})
+
+	// Serve the service of each process over loopback.
+	invoiceServer, err := remote.NewServer(token, &slog.Logger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	invoiceServer.RegisterInvoice(invoiceServ.Invoice)
+	invoiceHTTP := httptest.NewServer(invoiceServer)
+	t.Cleanup(invoiceHTTP.Close)
+
+	transactionServer, err := remote.NewServer(token, &slog.Logger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transactionServer.RegisterTransaction(transactionServ.Transaction)
+	transactionHTTP := httptest.NewServer(transactionServer)
+	t.Cleanup(transactionHTTP.Close)
+
+	userServer, err := remote.NewServer(token, &slog.Logger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	userServer.RegisterUser(userServ.User)
+	userHTTP := httptest.NewServer(userServer)
+	t.Cleanup(userHTTP.Close)
+
+	// Have each process call the services of the others remotely.
+	invoice := remote.NewInvoice(remote.NewClient(invoiceHTTP.URL, token))
+	transaction := remote.NewTransaction(remote.NewClient(transactionHTTP.URL, token))
+	user := remote.NewUser(remote.NewClient(userHTTP.URL, token))
+
+	invoiceServ.SetRemote(service.Remote{Transaction: transaction, User: user})
+	transactionServ.SetRemote(service.Remote{Invoice: invoice, User: user})
+	userServ.SetRemote(service.Remote{Invoice: invoice, Transaction: transaction})
+
+	return invoice, transaction, user
+}
+
+func TestInvoice(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create the clients, as the services they implement.
+	var invoice interfaces.Invoice
+	var transaction interfaces.Transaction
+	invoice, transaction, user := split(t, store)
+
+	// Create a local service to look up organizations.
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
+
+	// Create a user.
+	u, err := user.Create(ctx, &proto.UserCreateParams{
+		Email:    "johndoe@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orgs, err := serv.Organization.GetForUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	org := orgs[0]
+
+	// Call the services on behalf of the user.
+	ctx = actor.WithUser(ctx, u.ID)
+
+	// Create an invoice.
+	i, err := invoice.Create(ctx, &proto.InvoiceCreateParams{
+		OrganizationID: org.ID,
+		UserID:         u.ID,
+		PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
+		BillTo: proto.InvoiceBillTo{
+			FirstName: "Bill",
+		},
+		LineItems: []proto.InvoiceLineItem{
+			{
+				Quantity: 1,
+				Price:    100,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.AmountDue != 100 {
+		t.Errorf("Expected amount due to be '%d', got '%d'", 100, i.AmountDue)
+	}
+	if i.BillTo.FirstName != "Bill" {
+		t.Errorf("Expected bill to first name to be '%s', got '%s'", "Bill", i.BillTo.FirstName)
+	}
+
+	// Pay the invoice, where the invoice process calls the transaction
+	// process.
+	i, err = invoice.Pay(ctx, i.ID, &proto.InvoicePayParams{
+		Amount: 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.Status != "paid" {
+		t.Errorf("Expected status to be '%s', got '%s'", "paid", i.Status)
+	}
+
+	// Refund part of the invoice, where the transaction process calls the
+	// invoice process.
+	if _, err := transaction.Process(ctx, &proto.TransactionProcessParams{
+		OrganizationID: org.ID,
+		UserID:         u.ID,
+		Type:           "refund",
+		Amount:         40,
+		InvoiceID:      i.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	i, err = invoice.GetByIDAndOrganizationID(ctx, i.ID, org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.AmountDue != 40 {
+		t.Errorf("Expected amount due to be '%d', got '%d'", 40, i.AmountDue)
+	}
+	if i.Status != "pending" {
+		t.Errorf("Expected status to be '%s', got '%s'", "pending", i.Status)
+	}
+
+	// Refund an invoice that does not exist. The transaction process only
+	// returns an invoice ID param error if it gets the same error the
+	// invoice process returned.
+	_, err = transaction.Process(ctx, &proto.TransactionProcessParams{
+		OrganizationID: org.ID,
+		UserID:         u.ID,
+		Type:           "refund",
+		Amount:         40,
+		InvoiceID:      999,
+	})
+	pes, ok := err.(*serverrors.ParamErrors)
+	if !ok {
+		t.Fatalf("Expected error to be param errors, got '%v'", err)
+	}
+	if pes.Length() != 1 || (*pes)[0].Name != "invoice_id" || (*pes)[0].ErrorType != serverrors.ErrInvoiceNotFound {
+		t.Errorf("Expected error to be '%v' for '%s', got '%v'", serverrors.ErrInvoiceNotFound, "invoice_id", err)
+	}
+
+	// Get the invoices.
+	count, err := invoice.GetCount(ctx, &proto.InvoiceGetParams{OrganizationID: &org.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("Expected count to be '%d', got '%d'", 1, count)
+	}
+
+	// Get them without an actor, which the invoice process forbids, and as
+	// a user of another organization, which it does not find.
+	if _, err := invoice.GetCount(context.Background(), &proto.InvoiceGetParams{OrganizationID: &org.ID}); err != serverrors.ErrOrganizationForbidden {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrOrganizationForbidden, err)
+	}
+	if _, err := invoice.GetByID(actor.WithUser(ctx, u.ID+1), i.ID); err != serverrors.ErrInvoiceNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrInvoiceNotFound, err)
+	}
+
+	// Delete the invoice.
+	if err := invoice.Delete(ctx, i.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := invoice.GetByID(ctx, i.ID); err != serverrors.ErrInvoiceNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrInvoiceNotFound, err)
+	}
+}
+
+func TestInvoiceImport(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+	invoice, _, user := split(t, store)
+
+	// Create a local service to look up organizations.
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
+
+	// Create a user.
+	u, err := user.Create(ctx, &proto.UserCreateParams{
+		Email:    "johndoe@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orgs, err := serv.Organization.GetForUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Call the services on behalf of the user.
+	ctx = actor.WithUser(ctx, u.ID)
+
+	// Import a valid and an invalid invoice.
+	results, err := invoice.Import(ctx, &proto.InvoiceImportParams{
+		OrganizationID: orgs[0].ID,
+		UserID:         u.ID,
+		Invoices: []*proto.InvoiceCreateParams{
+			{
+				PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
+				LineItems: []proto.InvoiceLineItem{
+					{
+						Quantity: 1,
+						Price:    100,
+					},
+				},
+			},
+			{
+				PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected results length to be '%d', got '%d'", 2, len(results))
+	}
+	if results[0].Invoice == nil || results[0].Error != nil {
+		t.Errorf("Expected first invoice to be created, got '%v'", results[0].Error)
+	}
+
+	// Check the error of the invalid invoice.
+	pes, ok := results[1].Error.(*serverrors.ParamErrors)
+	if !ok {
+		t.Fatalf("Expected error to be param errors, got '%v'", results[1].Error)
+	}
+	if (*pes)[0].ErrorType != serverrors.ErrInvoiceLineItemRequired {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrInvoiceLineItemRequired, (*pes)[0].ErrorType)
+	}
+}
+
+func TestUser(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create the client, as the service it implements.
+	var user interfaces.User
+	_, _, user = split(t, store)
+
+	// Create a user.
+	u, err := user.Create(ctx, &proto.UserCreateParams{
+		Email:    "johndoe@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Get the user.
+	got, err := user.GetByEmail(ctx, "johndoe@test.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != u.ID {
+		t.Errorf("Expected user ID to be '%d', got '%d'", u.ID, got.ID)
+	}
+	if got.Password != "" || u.Password != "" {
+		t.Errorf("Expected password hash to not be sent, got '%s'", got.Password)
+	}
+
+	// Create the user again.
+	_, err = user.Create(ctx, &proto.UserCreateParams{
+		Email:    "johndoe@test.com",
+		Password: "TestPassword123",
+	})
+	pes, ok := err.(*serverrors.ParamErrors)
+	if !ok {
+		t.Fatalf("Expected error to be param errors, got '%v'", err)
+	}
+	if pes.Length() != 1 || (*pes)[0].Name != "email" || (*pes)[0].ErrorType != serverrors.ErrUserEmailExists {
+		t.Errorf("Expected error to be '%v' for '%s', got '%v'", serverrors.ErrUserEmailExists, "email", err)
+	}
+
+	// Log in with the wrong password.
+	if _, err := user.Login(ctx, &proto.UserLoginParams{
+		Email:    "johndoe@test.com",
+		Password: "WrongPassword123",
+	}); err != serverrors.ErrUserInvalidLogin {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrUserInvalidLogin, err)
+	}
+
+	// Send an email verification, which returns no result.
+	if err := user.SendEmailVerification(ctx, &proto.UserSendEmailVerificationParams{
+		ID:   u.ID,
+		Link: "https://example.com/verify",
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// Create a server without a token.
+	if _, err := remote.NewServer("", &slog.Logger{}); err != remote.ErrTokenRequired {
+		t.Errorf("Expected error to be '%v', got '%v'", remote.ErrTokenRequired, err)
+	}
+
+	// Serve a user service.
+	serv := servicetest.New(memory.New(), mailmock.New(), &slog.Logger{})
+	server, err := remote.NewServer(token, &slog.Logger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.RegisterUser(serv.User)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	// Call it with the wrong token.
+	user := remote.NewUser(remote.NewClient(ts.URL, "wrong"))
+	if _, err := user.GetByID(ctx, 1); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected error to be for status '%d', got '%v'", http.StatusUnauthorized, err)
+	}
+
+	// Call a service that is not served.
+	invoice := remote.NewInvoice(remote.NewClient(ts.URL, token))
+	if _, err := invoice.GetByID(ctx, 1); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected error to be for status '%d', got '%v'", http.StatusNotFound, err)
+	}
+
+	// Call a method with invalid arguments.
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/User/GetByID", strings.NewReader(`{"id":"one"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status to be '%d', got '%d'", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	// Call a method on behalf of an invalid actor.
+	req, err = http.NewRequest(http.MethodPost, ts.URL+"/User/GetByID", strings.NewReader(`{"id":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(actor.Header, "user:one")
+
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status to be '%d', got '%d'", http.StatusBadRequest, resp.StatusCode)
+	}
+}
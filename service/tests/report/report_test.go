@@ -0,0 +1,173 @@
+package report
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	mailmock "dddstructure/mail/mock"
+	"dddstructure/proto"
+	"dddstructure/service/actor"
+	"dddstructure/service/tests/servicetest"
+	"dddstructure/storage/memory"
+)
+
+func TestReports(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
+
+	// Create a user.
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
+		Email:    "johndoe@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Get the user's personal organization.
+	orgs, err := serv.Organization.GetForUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	org := orgs[0]
+
+	// Call the services on behalf of the user.
+	ctx = actor.WithUser(ctx, u.ID)
+
+	// Create invoices due at different times.
+	asOf := time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)
+
+	create := func(currency string, dueDate time.Time, price uint) *proto.Invoice {
+		i, err := serv.Invoice.Create(ctx, &proto.InvoiceCreateParams{
+			OrganizationID: org.ID,
+			UserID:         u.ID,
+			Currency:       currency,
+			DueDate:        dueDate,
+			PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
+			LineItems: []proto.InvoiceLineItem{
+				{
+					Quantity: 1,
+					Price:    price,
+				},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return i
+	}
+
+	create("USD", asOf.AddDate(0, 0, -10), 100)
+	create("USD", asOf.AddDate(0, 0, -45), 200)
+	create("EUR", asOf.AddDate(0, 0, 5), 300)
+	paid := create("USD", asOf.AddDate(0, 0, 5), 400)
+
+	// Pay an invoice and partially refund it.
+	if _, err := serv.Invoice.Pay(ctx, paid.ID, &proto.InvoicePayParams{
+		Amount: 400,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := serv.Transaction.Process(ctx, &proto.TransactionProcessParams{
+		OrganizationID: org.ID,
+		UserID:         u.ID,
+		Type:           "refund",
+		Amount:         150,
+		InvoiceID:      paid.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Check aging.
+	aging, err := serv.Report.GetAging(ctx, &proto.ReportAgingParams{
+		OrganizationID: org.ID,
+		AsOf:           asOf,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(aging) != 2 {
+		t.Fatalf("Expected aging length to be '%d', got '%d'", 2, len(aging))
+	}
+
+	expectedAging := map[string]map[proto.ReportAgingBucketName]uint{
+		"EUR": {proto.ReportAgingBucketCurrent: 300},
+		"USD": {proto.ReportAgingBucketCurrent: 150, proto.ReportAgingBucket1To30: 100, proto.ReportAgingBucket31To60: 200},
+	}
+	for _, a := range aging {
+		if len(a.Buckets) != 5 {
+			t.Errorf("Expected %s aging buckets length to be '%d', got '%d'", a.Currency, 5, len(a.Buckets))
+		}
+		for _, b := range a.Buckets {
+			if b.AmountDue != expectedAging[a.Currency][b.Name] {
+				t.Errorf("Expected %s aging bucket %s amount due to be '%d', got '%d'", a.Currency, b.Name, expectedAging[a.Currency][b.Name], b.AmountDue)
+			}
+		}
+	}
+
+	// Check revenue.
+	revenue, err := serv.Report.GetRevenue(ctx, &proto.ReportRevenueParams{
+		OrganizationID: org.ID,
+		Interval:       proto.ReportRevenueIntervalMonth,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(revenue) != 1 {
+		t.Fatalf("Expected revenue length to be '%d', got '%d'", 1, len(revenue))
+	}
+	if revenue[0].Currency != "USD" {
+		t.Errorf("Expected revenue currency to be '%s', got '%s'", "USD", revenue[0].Currency)
+	}
+	if revenue[0].Period.Day() != 1 {
+		t.Errorf("Expected revenue period day to be '%d', got '%d'", 1, revenue[0].Period.Day())
+	}
+	if revenue[0].Collected != 400 {
+		t.Errorf("Expected revenue collected to be '%d', got '%d'", 400, revenue[0].Collected)
+	}
+	if revenue[0].Refunded != 150 {
+		t.Errorf("Expected revenue refunded to be '%d', got '%d'", 150, revenue[0].Refunded)
+	}
+	if revenue[0].Net != 250 {
+		t.Errorf("Expected revenue net to be '%d', got '%d'", 250, revenue[0].Net)
+	}
+
+	// Check revenue with an invalid interval.
+	if _, err := serv.Report.GetRevenue(ctx, &proto.ReportRevenueParams{
+		OrganizationID: org.ID,
+		Interval:       "year",
+	}); err == nil {
+		t.Errorf("Expected an error for an invalid interval")
+	}
+
+	// Check balances.
+	balances, err := serv.Report.GetBalances(ctx, &proto.ReportBalancesParams{
+		OrganizationID: org.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(balances) != 2 {
+		t.Fatalf("Expected balances length to be '%d', got '%d'", 2, len(balances))
+	}
+	if balances[1].Currency != "USD" {
+		t.Errorf("Expected balance currency to be '%s', got '%s'", "USD", balances[1].Currency)
+	}
+	if balances[1].Outstanding != 450 {
+		t.Errorf("Expected balance outstanding to be '%d', got '%d'", 450, balances[1].Outstanding)
+	}
+	if balances[1].Collected != 250 {
+		t.Errorf("Expected balance collected to be '%d', got '%d'", 250, balances[1].Collected)
+	}
+}
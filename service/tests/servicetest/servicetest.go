@@ -0,0 +1,23 @@
+// Package servicetest creates the services used by the tests.
+package servicetest
+
+import (
+	"log/slog"
+
+	"dddstructure/mail"
+	"dddstructure/service"
+	"dddstructure/service/guard"
+	"dddstructure/storage"
+)
+
+// New creates a new service like service.New, with the calls between
+// services guarded by guard.DefaultConfig so a cycle fails the test instead
+// of never returning.
+func New(s *storage.Storage, m mail.Sender, l *slog.Logger) *service.Service {
+	serv := service.New(s, m, l)
+
+	cfg := guard.DefaultConfig
+	serv.SetCallGuard(&cfg)
+
+	return serv
+}
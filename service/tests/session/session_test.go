@@ -0,0 +1,204 @@
+package session
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	mailmock "dddstructure/mail/mock"
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/service/tests/servicetest"
+	"dddstructure/storage/memory"
+)
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
+
+	// Create a session.
+	s, err := serv.Session.Create(ctx, &proto.SessionCreateParams{
+		UserID:    1,
+		UserAgent: "test",
+		IPAddress: "127.0.0.1",
+		Expiry:    time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.RefreshToken == "" {
+		t.Fatalf("Expected session to have a refresh token")
+	}
+
+	// Refresh the session.
+	refreshed, err := serv.Session.Refresh(ctx, &proto.SessionRefreshParams{
+		RefreshToken: s.RefreshToken,
+		Expiry:       time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.ID != s.ID {
+		t.Errorf("Expected refreshed session ID to be '%s', got '%s'", s.ID, refreshed.ID)
+	}
+	if refreshed.RefreshToken == "" || refreshed.RefreshToken == s.RefreshToken {
+		t.Errorf("Expected refresh token to be rotated")
+	}
+
+	// Refresh the session again with the new token.
+	refreshed, err = serv.Session.Refresh(ctx, &proto.SessionRefreshParams{
+		RefreshToken: refreshed.RefreshToken,
+		Expiry:       time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check an unknown token is invalid.
+	_, err = serv.Session.Refresh(ctx, &proto.SessionRefreshParams{
+		RefreshToken: "invalid",
+		Expiry:       time.Hour,
+	})
+	if err != serverrors.ErrSessionRefreshTokenInvalid {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrSessionRefreshTokenInvalid, err)
+	}
+
+	// Check reusing the first token revokes the session.
+	_, err = serv.Session.Refresh(ctx, &proto.SessionRefreshParams{
+		RefreshToken: s.RefreshToken,
+		Expiry:       time.Hour,
+	})
+	if err != serverrors.ErrSessionRefreshTokenReused {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrSessionRefreshTokenReused, err)
+	}
+	if _, err := serv.Session.GetByID(ctx, s.ID); err != serverrors.ErrSessionNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrSessionNotFound, err)
+	}
+
+	// Check the latest token stopped working with the session.
+	_, err = serv.Session.Refresh(ctx, &proto.SessionRefreshParams{
+		RefreshToken: refreshed.RefreshToken,
+		Expiry:       time.Hour,
+	})
+	if err != serverrors.ErrSessionRefreshTokenInvalid {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrSessionRefreshTokenInvalid, err)
+	}
+}
+
+func TestRefreshExpired(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
+
+	// Create a session that has already expired.
+	s, err := serv.Session.Create(ctx, &proto.SessionCreateParams{
+		UserID: 1,
+		Expiry: -time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the session can't be refreshed.
+	_, err = serv.Session.Refresh(ctx, &proto.SessionRefreshParams{
+		RefreshToken: s.RefreshToken,
+		Expiry:       time.Hour,
+	})
+	if err != serverrors.ErrSessionRefreshTokenInvalid {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrSessionRefreshTokenInvalid, err)
+	}
+}
+
+func TestDeleteForUser(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
+
+	// Create a session.
+	s, err := serv.Session.Create(ctx, &proto.SessionCreateParams{
+		UserID: 10,
+		Expiry: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check another user can't delete the session.
+	if err := serv.Session.DeleteForUser(ctx, s.ID, 11); err != serverrors.ErrSessionNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrSessionNotFound, err)
+	}
+
+	// Delete the session.
+	if err := serv.Session.DeleteForUser(ctx, s.ID, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := serv.Session.GetByUserID(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("Expected user to have '%d' sessions, got '%d'", 0, len(sessions))
+	}
+}
+
+func TestResetPasswordRevokesSessions(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	mailer := mailmock.New()
+	serv := servicetest.New(store, mailer, &slog.Logger{})
+
+	// Create a user.
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
+		Email:    "sessions@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a session.
+	s, err := serv.Session.Create(ctx, &proto.SessionCreateParams{
+		UserID: u.ID,
+		Expiry: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Change the password.
+	password := "NewPassword123"
+	if _, err := serv.User.Update(ctx, &proto.UserUpdateParams{
+		ID:       &u.ID,
+		Password: &password,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the session was revoked.
+	_, err = serv.Session.Refresh(ctx, &proto.SessionRefreshParams{
+		RefreshToken: s.RefreshToken,
+		Expiry:       time.Hour,
+	})
+	if err != serverrors.ErrSessionRefreshTokenInvalid {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrSessionRefreshTokenInvalid, err)
+	}
+}
@@ -0,0 +1,163 @@
+package trace
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	mailmock "dddstructure/mail/mock"
+	"dddstructure/proto"
+	"dddstructure/service/actor"
+	"dddstructure/service/tests/servicetest"
+	storagememory "dddstructure/storage/memory"
+	"dddstructure/storage/traced"
+	"dddstructure/trace"
+	"dddstructure/trace/memory"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	// Parse a valid traceparent.
+	v := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := trace.ParseTraceparent(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check span context.
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected trace ID to be '%s', got '%s'", "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID)
+	}
+	if sc.SpanID.String() != "00f067aa0ba902b7" {
+		t.Errorf("Expected span ID to be '%s', got '%s'", "00f067aa0ba902b7", sc.SpanID)
+	}
+	if !sc.Sampled {
+		t.Errorf("Expected span context to be sampled")
+	}
+	if sc.Traceparent() != v {
+		t.Errorf("Expected traceparent to be '%s', got '%s'", v, sc.Traceparent())
+	}
+
+	// Parse invalid traceparents.
+	for _, v := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, err := trace.ParseTraceparent(v); err != trace.ErrInvalidTraceparent {
+			t.Errorf("Expected error '%v' for '%s', got '%v'", trace.ErrInvalidTraceparent, v, err)
+		}
+	}
+}
+
+func TestSpans(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation, tracing every call.
+	store := traced.New(storagememory.New())
+
+	// Create a new service.
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
+
+	// Create a new tracer.
+	exporter := memory.New()
+	tracer := trace.NewTracer(exporter)
+
+	// Create a user.
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
+		Email:    "johndoe@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Get the user's personal organization.
+	orgs, err := serv.Organization.GetForUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	org := orgs[0]
+
+	// Call the services on behalf of the user.
+	ctx = actor.WithUser(ctx, u.ID)
+
+	// Create an invoice.
+	i, err := serv.Invoice.Create(ctx, &proto.InvoiceCreateParams{
+		OrganizationID: org.ID,
+		UserID:         u.ID,
+		PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
+		BillTo: proto.InvoiceBillTo{
+			FirstName: "John",
+			LastName:  "Smith",
+		},
+		PayTo: proto.InvoicePayTo{
+			FirstName: "John",
+			LastName:  "Doe",
+		},
+		LineItems: []proto.InvoiceLineItem{
+			{
+				Quantity: 1,
+				Price:    100,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check nothing is traced outside of a span.
+	if spans := exporter.Spans(); len(spans) != 0 {
+		t.Fatalf("Expected no spans, got '%d'", len(spans))
+	}
+
+	// Pay the invoice, joining the trace of a caller.
+	remote, err := trace.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootCtx, root := tracer.Start(ctx, "POST /pay", trace.SpanKindServer, remote)
+	if _, err := serv.Invoice.Pay(rootCtx, i.ID, &proto.InvoicePayParams{
+		Amount: 100,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	root.End()
+
+	// Index the spans by name.
+	spans := make(map[string]*trace.SpanData)
+	for _, s := range exporter.Spans() {
+		if s.TraceID != remote.TraceID {
+			t.Errorf("Expected span '%s' trace ID to be '%s', got '%s'", s.Name, remote.TraceID, s.TraceID)
+		}
+		spans[s.Name] = s
+	}
+
+	// Check the spans are nested.
+	for _, c := range []struct {
+		name   string
+		parent string
+	}{
+		{"service.Invoice.Pay", "POST /pay"},
+		{"service.Transaction.Process", "service.Invoice.Pay"},
+		{"storage.transaction.Create", "service.Transaction.Process"},
+		{"storage.invoice.Update", "service.Invoice.Pay"},
+	} {
+		s, ok := spans[c.name]
+		if !ok {
+			t.Errorf("Expected span '%s' to be exported", c.name)
+			continue
+		}
+		if p, ok := spans[c.parent]; !ok || s.ParentSpanID != p.SpanID {
+			t.Errorf("Expected span '%s' parent to be '%s'", c.name, c.parent)
+		}
+	}
+
+	if spans["POST /pay"].ParentSpanID != remote.SpanID {
+		t.Errorf("Expected root span parent to be '%s', got '%s'", remote.SpanID, spans["POST /pay"].ParentSpanID)
+	}
+}
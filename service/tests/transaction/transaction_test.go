@@ -1,24 +1,45 @@
 package transaction
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"io"
 	"log/slog"
 	"testing"
 
+	mailmock "dddstructure/mail/mock"
 	"dddstructure/proto"
-	"dddstructure/service"
-	"dddstructure/storage/mock"
+	"dddstructure/service/actor"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/service/tests/servicetest"
+	"dddstructure/storage/invoice"
+	"dddstructure/storage/memory"
 )
 
+// errUpdate is returned by failingInvoices.
+var errUpdate = errors.New("update failed")
+
+// failingInvoices fails every update of the wrapped invoice database.
+type failingInvoices struct {
+	invoice.Database
+}
+
+// Update returns errUpdate.
+func (db *failingInvoices) Update(ctx context.Context, i *invoice.Invoice) (*invoice.Invoice, error) {
+	return nil, errUpdate
+}
+
 func TestProcess(t *testing.T) {
-	// Create a new mock storage implementation.
-	store := mock.New(&sql.DB{})
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
 
 	// Create a new service.
-	serv := service.New(store, &slog.Logger{})
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
 
 	// Create a user.
-	u, err := serv.User.Create(&proto.UserCreateParams{
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
 		Email:    "johndoe@test.com",
 		Password: "TestPassword123",
 	})
@@ -26,11 +47,21 @@ func TestProcess(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// Get the user's personal organization.
+	orgs, err := serv.Organization.GetForUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Call the services on behalf of the user.
+	ctx = actor.WithUser(ctx, u.ID)
+
 	// Process a transaction.
-	tx, err := serv.Transaction.Process(&proto.TransactionProcessParams{
-		UserID: 1,
-		Type:   "sale",
-		Amount: 100,
+	tx, err := serv.Transaction.Process(ctx, &proto.TransactionProcessParams{
+		OrganizationID: orgs[0].ID,
+		UserID:         1,
+		Type:           "sale",
+		Amount:         100,
 		PaymentMethod: proto.TransactionPaymentMethod{
 			Card: &proto.TransactionPaymentMethodCard{
 				Number:         "4111111111111111",
@@ -59,3 +90,194 @@ func TestProcess(t *testing.T) {
 		t.Errorf("Expected transaction amount captured to be '%d', got '%d'", 100, tx.AmountCaptured)
 	}
 }
+
+func TestProcessRefund(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
+
+	// Create a user.
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
+		Email:    "johndoe@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Get the user's personal organization.
+	orgs, err := serv.Organization.GetForUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	org := orgs[0]
+
+	// Call the services on behalf of the user.
+	ctx = actor.WithUser(ctx, u.ID)
+
+	// Create and pay an invoice.
+	i, err := serv.Invoice.Create(ctx, &proto.InvoiceCreateParams{
+		OrganizationID: org.ID,
+		UserID:         u.ID,
+		PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
+		LineItems: []proto.InvoiceLineItem{
+			{
+				Quantity: 1,
+				Price:    100,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := serv.Invoice.Pay(ctx, i.ID, &proto.InvoicePayParams{
+		Amount: 100,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Refund part of the invoice, which the invoice service handles by
+	// returning the amount to the invoice.
+	if _, err := serv.Transaction.Process(ctx, &proto.TransactionProcessParams{
+		OrganizationID: org.ID,
+		UserID:         u.ID,
+		Type:           "refund",
+		Amount:         40,
+		InvoiceID:      i.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Check invoice.
+	i, err = serv.Invoice.GetByID(ctx, i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.AmountDue != 40 {
+		t.Errorf("Expected amount due to be '%d', got '%d'", 40, i.AmountDue)
+	}
+	if i.AmountPaid != 60 {
+		t.Errorf("Expected amount paid to be '%d', got '%d'", 60, i.AmountPaid)
+	}
+	if i.Status != "pending" {
+		t.Errorf("Expected status to be '%s', got '%s'", "pending", i.Status)
+	}
+
+	// Refund more than is paid, which creates no transaction.
+	if _, err := serv.Transaction.Process(ctx, &proto.TransactionProcessParams{
+		OrganizationID: org.ID,
+		UserID:         u.ID,
+		Type:           "refund",
+		Amount:         70,
+		InvoiceID:      i.ID,
+	}); err == nil {
+		t.Error("Expected an error for a refund over the amount paid")
+	} else if pes, ok := err.(*serverrors.ParamErrors); !ok || pes.Length() != 1 || (*pes)[0].ErrorType != serverrors.ErrTransactionRefundAmountPaid {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrTransactionRefundAmountPaid, err)
+	}
+	if _, err := store.Transaction.GetByID(ctx, 3); err == nil {
+		t.Error("Expected no transaction to be created")
+	}
+
+	// Refund without an invoice, which creates no transaction.
+	if _, err := serv.Transaction.Process(ctx, &proto.TransactionProcessParams{
+		OrganizationID: org.ID,
+		UserID:         u.ID,
+		Type:           "refund",
+		Amount:         40,
+	}); err != serverrors.ErrInvoiceNotFound {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrInvoiceNotFound, err)
+	}
+	if _, err := store.Transaction.GetByID(ctx, 3); err == nil {
+		t.Error("Expected no transaction to be created")
+	}
+}
+
+func TestProcessRefundSubscriberError(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service, discarding the errors it logs.
+	serv := servicetest.New(store, mailmock.New(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Create a user.
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
+		Email:    "johndoe@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Get the user's personal organization.
+	orgs, err := serv.Organization.GetForUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	org := orgs[0]
+
+	// Call the services on behalf of the user.
+	ctx = actor.WithUser(ctx, u.ID)
+
+	// Create and pay an invoice.
+	i, err := serv.Invoice.Create(ctx, &proto.InvoiceCreateParams{
+		OrganizationID: org.ID,
+		UserID:         u.ID,
+		PaymentMethods: []proto.InvoicePaymentMethod{proto.InvoicePaymentMethodCard},
+		LineItems: []proto.InvoiceLineItem{
+			{
+				Quantity: 1,
+				Price:    100,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := serv.Invoice.Pay(ctx, i.ID, &proto.InvoicePayParams{
+		Amount: 100,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fail the invoice service returning the refund to the invoice.
+	store.Invoice = &failingInvoices{Database: store.Invoice}
+
+	// Refund part of the invoice, which is saved even though the invoice
+	// could not be updated, so it is returned without an error.
+	tx, err := serv.Transaction.Process(ctx, &proto.TransactionProcessParams{
+		OrganizationID: org.ID,
+		UserID:         u.ID,
+		Type:           "refund",
+		Amount:         40,
+		InvoiceID:      i.ID,
+	})
+	if err != nil {
+		t.Fatalf("Expected error to be '%v', got '%v'", nil, err)
+	}
+
+	got, err := store.Transaction.GetByID(ctx, tx.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != "refund" || got.AmountCaptured != 40 {
+		t.Errorf("Expected a refund of '%d', got a %s of '%d'", 40, got.Type, got.AmountCaptured)
+	}
+
+	// Check invoice.
+	i, err = serv.Invoice.GetByID(ctx, i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.AmountPaid != 100 {
+		t.Errorf("Expected amount paid to be '%d', got '%d'", 100, i.AmountPaid)
+	}
+}
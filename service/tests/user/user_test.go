@@ -1,24 +1,35 @@
 package user
 
 import (
-	"database/sql"
+	"context"
+	"fmt"
+	"io"
 	"log/slog"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	mailmock "dddstructure/mail/mock"
 	"dddstructure/proto"
-	"dddstructure/service"
-	"dddstructure/storage/mock"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/service/tests/servicetest"
+	"dddstructure/service/user"
+	"dddstructure/storage/memory"
+	"dddstructure/utils"
 )
 
 func TestCreate(t *testing.T) {
-	// Create a new mock storage implementation.
-	store := mock.New(&sql.DB{})
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
 
 	// Create a new service.
-	serv := service.New(store, &slog.Logger{})
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
 
 	// Create a user.
-	u, err := serv.User.Create(&proto.UserCreateParams{
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
 		Email:    "johndoe@test.com",
 		Password: "TestPassword123",
 	})
@@ -34,3 +45,456 @@ func TestCreate(t *testing.T) {
 		t.Errorf("Expected user email to be '%s', got '%s'", "johndoe@test.com", u.Email)
 	}
 }
+
+// mailToken returns the token from the link in the last message sent to the
+// given address.
+func mailToken(t *testing.T, mailer *mailmock.Sender, to string) string {
+	m := mailer.Last(to)
+	if m == nil {
+		t.Fatalf("Expected a message to be sent to '%s'", to)
+	}
+
+	for _, field := range strings.Fields(m.Body) {
+		if u, err := url.Parse(field); err == nil && u.Query().Get("token") != "" {
+			return u.Query().Get("token")
+		}
+	}
+
+	t.Fatalf("Expected message to contain a token link, got '%s'", m.Body)
+	return ""
+}
+
+// hasParamError checks if the given error is a ParamErrors containing an
+// error for the given parameter.
+func hasParamError(err error, name string) bool {
+	pes, ok := err.(*serverrors.ParamErrors)
+	if !ok {
+		return false
+	}
+
+	for _, pe := range *pes {
+		if pe.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	mailer := mailmock.New()
+	serv := servicetest.New(store, mailer, &slog.Logger{})
+
+	// Create a user.
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
+		Email:    "reset@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ask for a password reset for an unknown email.
+	if err := serv.User.ForgotPassword(ctx, &proto.UserForgotPasswordParams{
+		Email: "unknown@test.com",
+		Link:  "https://example.com/password/reset",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(mailer.Messages()) != 0 {
+		t.Errorf("Expected no messages to be sent, got '%d'", len(mailer.Messages()))
+	}
+
+	// Ask for a password reset.
+	if err := serv.User.ForgotPassword(ctx, &proto.UserForgotPasswordParams{
+		Email: u.Email,
+		Link:  "https://example.com/password/reset",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	token := mailToken(t, mailer, u.Email)
+
+	// Reset the password with an invalid token.
+	_, err = serv.User.ResetPassword(ctx, &proto.UserResetPasswordParams{
+		Token:    "invalid",
+		Password: "NewPassword123",
+	})
+	if !hasParamError(err, "token") {
+		t.Errorf("Expected a token parameter error, got '%v'", err)
+	}
+
+	// Reset the password.
+	u, err = serv.User.ResetPassword(ctx, &proto.UserResetPasswordParams{
+		Token:    token,
+		Password: "NewPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.EmailVerifiedAt == nil {
+		t.Errorf("Expected user email to be verified")
+	}
+
+	// Check the new password works.
+	if _, err := serv.User.Login(ctx, &proto.UserLoginParams{
+		Email:    u.Email,
+		Password: "NewPassword123",
+	}); err != nil {
+		t.Errorf("Expected login with the new password to work, got '%v'", err)
+	}
+
+	// Check the token can't be used again.
+	_, err = serv.User.ResetPassword(ctx, &proto.UserResetPasswordParams{
+		Token:    token,
+		Password: "OtherPassword123",
+	})
+	if !hasParamError(err, "token") {
+		t.Errorf("Expected a token parameter error, got '%v'", err)
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	mailer := mailmock.New()
+	serv := servicetest.New(store, mailer, &slog.Logger{})
+
+	// Create a user.
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
+		Email:    "verify@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.EmailVerifiedAt != nil {
+		t.Errorf("Expected user email to not be verified")
+	}
+
+	// Send the email verification.
+	if err := serv.User.SendEmailVerification(ctx, &proto.UserSendEmailVerificationParams{
+		ID:   u.ID,
+		Link: "https://example.com/verify-email",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	token := mailToken(t, mailer, u.Email)
+
+	// Check a password reset token can't verify the email.
+	if err := serv.User.ForgotPassword(ctx, &proto.UserForgotPasswordParams{
+		Email: u.Email,
+		Link:  "https://example.com/password/reset",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = serv.User.VerifyEmail(ctx, &proto.UserVerifyEmailParams{
+		Token: mailToken(t, mailer, u.Email),
+	})
+	if !hasParamError(err, "token") {
+		t.Errorf("Expected a token parameter error, got '%v'", err)
+	}
+
+	// Verify the email.
+	u, err = serv.User.VerifyEmail(ctx, &proto.UserVerifyEmailParams{
+		Token: token,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.EmailVerifiedAt == nil {
+		t.Errorf("Expected user email to be verified")
+	}
+
+	// Check changing the email resets verification.
+	email := "changed@test.com"
+	u, err = serv.User.Update(ctx, &proto.UserUpdateParams{
+		ID:    &u.ID,
+		Email: &email,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.EmailVerifiedAt != nil {
+		t.Errorf("Expected changed user email to not be verified")
+	}
+}
+
+func TestTwoFactor(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service.
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
+
+	// Create a user.
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
+		Email:    "twofactor@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Enroll and confirm with a code.
+	enrollment, err := serv.User.EnrollTwoFactor(ctx, &proto.UserEnrollTwoFactorParams{
+		ID:     u.ID,
+		Issuer: "Test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(enrollment.URI, "otpauth://totp/") {
+		t.Errorf("Expected provisioning URI, got '%s'", enrollment.URI)
+	}
+
+	step := utils.TOTPStep(time.Now())
+	code, err := utils.TOTPCode(enrollment.Secret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = serv.User.ConfirmTwoFactor(ctx, &proto.UserConfirmTwoFactorParams{
+		ID:   u.ID,
+		Code: "000000" + code,
+	})
+	if !hasParamError(err, "code") {
+		t.Errorf("Expected a code parameter error, got '%v'", err)
+	}
+
+	recoveryCodes, err := serv.User.ConfirmTwoFactor(ctx, &proto.UserConfirmTwoFactorParams{
+		ID:   u.ID,
+		Code: code,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recoveryCodes) != 10 {
+		t.Fatalf("Expected '%d' recovery codes, got '%d'", 10, len(recoveryCodes))
+	}
+
+	// Log in, which now needs a second step.
+	u, err = serv.User.Login(ctx, &proto.UserLoginParams{
+		Email:    u.Email,
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.TwoFactorEnabledAt == nil {
+		t.Fatalf("Expected two-factor authentication to be enabled")
+	}
+
+	// loginTwoFactor creates a login challenge and uses it with a code.
+	loginTwoFactor := func(code string) error {
+		t.Helper()
+
+		challenge, err := serv.User.CreateLoginChallenge(ctx, u.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = serv.User.LoginTwoFactor(ctx, &proto.UserLoginTwoFactorParams{
+			ChallengeToken: challenge.Token,
+			Code:           code,
+		})
+		return err
+	}
+
+	// Reuse the code used to confirm.
+	if err := loginTwoFactor(code); err != serverrors.ErrUserTwoFactorCodeInvalid {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrUserTwoFactorCodeInvalid, err)
+	}
+
+	// Use a recovery code, typed in upper case, then reuse it.
+	if err := loginTwoFactor(strings.ToUpper(recoveryCodes[0])); err != nil {
+		t.Errorf("Expected recovery code to log in, got '%v'", err)
+	}
+	if err := loginTwoFactor(recoveryCodes[0]); err != serverrors.ErrUserTwoFactorCodeInvalid {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrUserTwoFactorCodeInvalid, err)
+	}
+
+	// Use the next code.
+	code, err = utils.TOTPCode(enrollment.Secret, step+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loginTwoFactor(code); err != nil {
+		t.Errorf("Expected code to log in, got '%v'", err)
+	}
+
+	// Use a challenge a second time.
+	challenge, err := serv.User.CreateLoginChallenge(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := 0; n < 2; n++ {
+		_, err = serv.User.LoginTwoFactor(ctx, &proto.UserLoginTwoFactorParams{
+			ChallengeToken: challenge.Token,
+			Code:           recoveryCodes[n+1],
+		})
+	}
+	if err != serverrors.ErrUserTwoFactorChallengeInvalid {
+		t.Errorf("Expected error to be '%v', got '%v'", serverrors.ErrUserTwoFactorChallengeInvalid, err)
+	}
+
+	// Disable with the wrong password, then with a recovery code.
+	err = serv.User.DisableTwoFactor(ctx, &proto.UserDisableTwoFactorParams{
+		ID:       u.ID,
+		Password: "WrongPassword123",
+		Code:     recoveryCodes[2],
+	})
+	if !hasParamError(err, "password") {
+		t.Errorf("Expected a password parameter error, got '%v'", err)
+	}
+
+	if err := serv.User.DisableTwoFactor(ctx, &proto.UserDisableTwoFactorParams{
+		ID:       u.ID,
+		Password: "TestPassword123",
+		Code:     recoveryCodes[2],
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	u, err = serv.User.GetByID(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.TwoFactorEnabledAt != nil {
+		t.Errorf("Expected two-factor authentication to be disabled")
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service, locking after a few failures without delays.
+	serv := servicetest.New(store, mailmock.New(), &slog.Logger{})
+	serv.User.SetLockoutPolicy(user.LockoutPolicy{
+		Threshold:   3,
+		IPThreshold: 4,
+		DelayAfter:  3,
+		Duration:    time.Minute,
+	})
+
+	// Create the users.
+	for _, email := range []string{"locked@test.com", "cleared@test.com"} {
+		if _, err := serv.User.Create(ctx, &proto.UserCreateParams{
+			Email:    email,
+			Password: "TestPassword123",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// checkLogin checks logging in returns the expected error.
+	checkLogin := func(email, password, ip string, expected error) {
+		t.Helper()
+
+		_, err := serv.User.Login(ctx, &proto.UserLoginParams{
+			Email:     email,
+			Password:  password,
+			IPAddress: ip,
+		})
+		if err != expected {
+			t.Errorf("Expected logging in as '%s' from '%s' to return '%v', got '%v'", email, ip, expected, err)
+		}
+	}
+
+	// Check an account is locked, even with the correct password.
+	for n := 0; n < 3; n++ {
+		checkLogin("locked@test.com", "WrongPassword123", "10.0.0.1", serverrors.ErrUserInvalidLogin)
+	}
+	checkLogin("locked@test.com", "TestPassword123", "10.0.0.1", serverrors.ErrUserLoginLocked)
+	checkLogin("Locked@Test.com", "TestPassword123", "10.0.0.2", serverrors.ErrUserLoginLocked)
+
+	// Check an email without an account is locked the same way.
+	for n := 0; n < 3; n++ {
+		checkLogin("nobody@test.com", "WrongPassword123", "10.0.0.3", serverrors.ErrUserInvalidLogin)
+	}
+	checkLogin("nobody@test.com", "WrongPassword123", "10.0.0.3", serverrors.ErrUserLoginLocked)
+
+	// Check a client IP address is locked across emails.
+	for n := 0; n < 4; n++ {
+		checkLogin(fmt.Sprintf("guess%d@test.com", n), "WrongPassword123", "10.0.0.4", serverrors.ErrUserInvalidLogin)
+	}
+	checkLogin("cleared@test.com", "TestPassword123", "10.0.0.4", serverrors.ErrUserLoginLocked)
+
+	// Check a successful login does not clear the failures of its client IP
+	// address.
+	for n := 0; n < 3; n++ {
+		checkLogin(fmt.Sprintf("guess%d@test.com", n), "WrongPassword123", "10.0.0.6", serverrors.ErrUserInvalidLogin)
+	}
+	checkLogin("cleared@test.com", "TestPassword123", "10.0.0.6", nil)
+	checkLogin("guess3@test.com", "WrongPassword123", "10.0.0.6", serverrors.ErrUserInvalidLogin)
+	checkLogin("cleared@test.com", "TestPassword123", "10.0.0.6", serverrors.ErrUserLoginLocked)
+
+	// Check a successful login clears the failures of its email.
+	for n := 0; n < 2; n++ {
+		checkLogin("cleared@test.com", "WrongPassword123", "10.0.0.5", serverrors.ErrUserInvalidLogin)
+	}
+	checkLogin("cleared@test.com", "TestPassword123", "10.0.0.5", nil)
+	for n := 0; n < 2; n++ {
+		checkLogin("cleared@test.com", "WrongPassword123", "10.0.0.7", serverrors.ErrUserInvalidLogin)
+	}
+	checkLogin("cleared@test.com", "TestPassword123", "10.0.0.7", nil)
+}
+
+func TestCancelledContext(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a new memory storage implementation.
+	store := memory.New()
+
+	// Create a new service, discarding the errors it logs.
+	serv := servicetest.New(store, mailmock.New(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Create a user.
+	u, err := serv.User.Create(ctx, &proto.UserCreateParams{
+		Email:    "cancelled@test.com",
+		Password: "TestPassword123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Get the user with a cancelled context.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if _, err := serv.User.GetByID(cancelled, u.ID); err != context.Canceled {
+		t.Errorf("Expected error to be '%v', got '%v'", context.Canceled, err)
+	}
+
+	// Update the user with a cancelled context.
+	email := "updated@test.com"
+	if _, err := serv.User.Update(cancelled, &proto.UserUpdateParams{
+		ID:    &u.ID,
+		Email: &email,
+	}); err != context.Canceled {
+		t.Errorf("Expected error to be '%v', got '%v'", context.Canceled, err)
+	}
+
+	// Check the user was not updated.
+	got, err := serv.User.GetByID(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Email != u.Email {
+		t.Errorf("Expected email to be '%s', got '%s'", u.Email, got.Email)
+	}
+}
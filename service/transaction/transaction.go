@@ -1,18 +1,21 @@
 package transaction
 
 import (
+	"context"
 	"log/slog"
 	"strings"
+	"time"
 
 	"dddstructure/proto"
+	"dddstructure/service/actor"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/service/events"
 	"dddstructure/service/interfaces"
 	"dddstructure/storage"
 	"dddstructure/storage/transaction"
+	"dddstructure/trace"
 )
 
-// idCounter handles increasing the ID.
-var idCounter uint = 1
-
 // Service defines the transaction service.
 type Service struct {
 	storage  *storage.Storage
@@ -25,6 +28,13 @@ func (s *Service) SetServices(services *interfaces.Service) {
 	s.services = services
 }
 
+// WithLogger returns a copy of the service that logs to the given logger.
+func (s *Service) WithLogger(l *slog.Logger) *Service {
+	c := *s
+	c.logger = l
+	return &c
+}
+
 // New creates a new service.
 func New(s *storage.Storage, l *slog.Logger) *Service {
 	return &Service{
@@ -34,16 +44,41 @@ func New(s *storage.Storage, l *slog.Logger) *Service {
 }
 
 // Process handles processing a transaction.
-func (s *Service) Process(params *proto.TransactionProcessParams) (*proto.Transaction, error) {
+func (s *Service) Process(ctx context.Context, params *proto.TransactionProcessParams) (*proto.Transaction, error) {
+	ctx, span := trace.Start(ctx, "service.Transaction.Process")
+	defer span.End()
+
+	// Authorize the actor.
+	if err := actor.Authorize(ctx, s.services.Organization, params.OrganizationID, proto.OrganizationPermissionTransaction); err != nil {
+		return nil, err
+	}
+
 	// Validate parameters.
-	if err := s.ValidateProcessParams(params); err != nil {
+	if err := s.ValidateProcessParams(ctx, params); err != nil {
 		return nil, err
 	}
 
-	// Handle ID.
-	if params.ID == 0 {
-		params.ID = idCounter
-		idCounter++
+	// Check the invoice belongs to the organization.
+	var servicei *proto.Invoice
+	if params.InvoiceID != 0 {
+		var err error
+		servicei, err = s.services.Invoice.GetByIDAndOrganizationID(ctx, params.InvoiceID, params.OrganizationID)
+		if err == serverrors.ErrInvoiceNotFound {
+			return nil, serverrors.NewParamErrors(serverrors.NewParamError("invoice_id", err))
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	// Refunds need an invoice to be returned to, and can't return more than
+	// was paid on it.
+	if params.Type == "refund" {
+		if servicei == nil {
+			return nil, serverrors.ErrInvoiceNotFound
+		}
+		if params.Amount > servicei.AmountPaid {
+			return nil, serverrors.NewParamErrors(serverrors.NewParamError("amount", serverrors.ErrTransactionRefundAmountPaid))
+		}
 	}
 
 	// Get card type.
@@ -55,14 +90,16 @@ func (s *Service) Process(params *proto.TransactionProcessParams) (*proto.Transa
 	}
 
 	// Create a transaction.
-	storaget, err := s.storage.Transaction.Create(&transaction.Transaction{
+	storaget, err := s.storage.Transaction.Create(ctx, &transaction.Transaction{
 		ID:             params.ID,
+		OrganizationID: params.OrganizationID,
 		UserID:         params.UserID,
 		Type:           params.Type,
 		CardType:       cardType,
 		AmountCaptured: params.Amount,
 		InvoiceID:      params.InvoiceID,
 		Status:         "approved",
+		CreatedAt:      time.Now().UTC(),
 	})
 	if err != nil {
 		s.logger.Error("storage.Transaction.Create() error",
@@ -70,39 +107,37 @@ func (s *Service) Process(params *proto.TransactionProcessParams) (*proto.Transa
 		return nil, err
 	}
 
-	// Update an invoice.
-	if params.Type == "refund" {
-		// Get the invoice.
-		servicei, err := s.services.Invoice.GetByID(params.InvoiceID)
-		if err != nil {
-			return nil, err
-		}
-
-		// Change amounts and status.
-		servicei.AmountDue += storaget.AmountCaptured
-		servicei.AmountPaid -= storaget.AmountCaptured
-		servicei.Status = "pending"
-
-		if _, err := s.services.Invoice.UpdateForTransaction(&proto.InvoiceUpdateForTransactionParams{
-			ID:         &servicei.ID,
-			AmountDue:  &servicei.AmountDue,
-			AmountPaid: &servicei.AmountPaid,
-			Status:     &servicei.Status,
-		}); err != nil {
-			s.logger.Error("storage.Invoice.UpdateForTransaction() error",
-				slog.Any("error", err))
-			return nil, err
-		}
-	}
-
 	ret := &proto.Transaction{
 		ID:             storaget.ID,
+		OrganizationID: storaget.OrganizationID,
 		UserID:         storaget.UserID,
 		Type:           storaget.Type,
 		CardType:       storaget.CardType,
 		AmountCaptured: storaget.AmountCaptured,
 		InvoiceID:      storaget.InvoiceID,
 		Status:         storaget.Status,
+		CreatedAt:      storaget.CreatedAt,
+	}
+
+	// Publish the transaction events. Subscribers run before returning, but
+	// the transaction is already saved, so if one fails, such as the invoice
+	// service returning a refund to its invoice, the error is logged and the
+	// transaction is still returned. Returning the error would have the
+	// caller retry, processing the transaction twice.
+	if ret.Status == "approved" {
+		if err := s.services.Events.TransactionApproved.Publish(ctx, &events.TransactionApproved{Transaction: ret}); err != nil {
+			s.logger.Error("events.TransactionApproved.Publish() error",
+				slog.Uint64("transaction_id", uint64(ret.ID)),
+				slog.Any("error", err))
+		}
+
+		if ret.Type == "refund" {
+			if err := s.services.Events.RefundIssued.Publish(ctx, &events.RefundIssued{Transaction: ret}); err != nil {
+				s.logger.Error("events.RefundIssued.Publish() error",
+					slog.Uint64("transaction_id", uint64(ret.ID)),
+					slog.Any("error", err))
+			}
+		}
 	}
 
 	return ret, nil
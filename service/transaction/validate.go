@@ -1,12 +1,13 @@
 package transaction
 
 import (
+	"context"
 	"dddstructure/proto"
 	"dddstructure/service/errors"
 )
 
 // ValidateProcessParams validates the process parameters.
-func (s *Service) ValidateProcessParams(params *proto.TransactionProcessParams) error {
+func (s *Service) ValidateProcessParams(ctx context.Context, params *proto.TransactionProcessParams) error {
 	// Create a new ParamErrors.
 	pes := errors.NewParamErrors()
 
@@ -0,0 +1,117 @@
+package user
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"dddstructure/mail"
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/storage/user"
+	"dddstructure/storage/usertoken"
+	"dddstructure/trace"
+	"dddstructure/utils"
+)
+
+// emailVerificationExpiry defines how long an email verification token is
+// valid for.
+const emailVerificationExpiry = 48 * time.Hour
+
+// SendEmailVerification handles sending an email verification token to a
+// user.
+//
+// Nothing is sent if the user's email is already verified.
+func (s *Service) SendEmailVerification(ctx context.Context, params *proto.UserSendEmailVerificationParams) error {
+	ctx, span := trace.Start(ctx, "service.User.SendEmailVerification")
+	defer span.End()
+
+	// Get the user.
+	storageu, err := s.storage.User.GetByID(ctx, params.ID)
+	if err == user.ErrUserNotFound {
+		return serverrors.ErrUserNotFound
+	} else if err != nil {
+		s.logger.Error("storage.User.GetByID() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	// Check if the email is already verified.
+	if storageu.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	// Issue an email verification token.
+	token, err := s.issueToken(ctx, storageu.ID, usertoken.TypeEmailVerification, emailVerificationExpiry)
+	if err != nil {
+		return err
+	}
+
+	// Send the token.
+	if err := s.mailer.Send(&mail.Message{
+		To:      storageu.Email,
+		Subject: "Verify your email",
+		Body: "Please verify your email by using the link below within the next 48 hours:\n\n" +
+			utils.TokenLink(params.Link, token) + "\n",
+	}); err != nil {
+		s.logger.Error("mailer.Send() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+// VerifyEmail handles verifying a user's email using an email verification
+// token.
+func (s *Service) VerifyEmail(ctx context.Context, params *proto.UserVerifyEmailParams) (*proto.User, error) {
+	ctx, span := trace.Start(ctx, "service.User.VerifyEmail")
+	defer span.End()
+
+	// Validate parameters.
+	if err := s.ValidateVerifyEmailParams(ctx, params); err != nil {
+		return nil, err
+	}
+
+	// Use the token.
+	storaget, err := s.consumeToken(ctx, params.Token, usertoken.TypeEmailVerification)
+	if err == serverrors.ErrUserTokenInvalid {
+		return nil, serverrors.NewParamErrors(serverrors.NewParamError("token", err))
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Get user from storage.
+	storageu, err := s.storage.User.GetByID(ctx, storaget.UserID)
+	if err == user.ErrUserNotFound {
+		return nil, serverrors.NewParamErrors(serverrors.NewParamError("token", serverrors.ErrUserTokenInvalid))
+	} else if err != nil {
+		s.logger.Error("storage.User.GetByID() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Handle email verified.
+	if storageu.EmailVerifiedAt == nil {
+		now := time.Now().UTC()
+		storageu.EmailVerifiedAt = &now
+
+		storageu, err = s.storage.User.Update(ctx, storageu)
+		if err != nil {
+			s.logger.Error("storage.User.Update() error",
+				slog.Any("error", err))
+			return nil, err
+		}
+	}
+
+	// Map to service type.
+	serviceu := &proto.User{
+		ID:                 storageu.ID,
+		Email:              storageu.Email,
+		Password:           storageu.Password,
+		EmailVerifiedAt:    storageu.EmailVerifiedAt,
+		TwoFactorEnabledAt: storageu.TOTPEnabledAt,
+	}
+
+	return serviceu, nil
+}
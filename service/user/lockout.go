@@ -0,0 +1,161 @@
+package user
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/storage/loginattempt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LockoutPolicy defines how failed login attempts are limited.
+//
+// After DelayAfter failures for an email, each attempt has to wait twice as
+// long as the last one, starting at a second. After Threshold failures for an
+// email, or IPThreshold failures from a client IP address, login is locked for
+// Duration. Failures are forgotten once none have happened for Duration.
+type LockoutPolicy struct {
+	Threshold   uint
+	IPThreshold uint
+	DelayAfter  uint
+	Duration    time.Duration
+}
+
+// DefaultLockoutPolicy defines the lockout policy used unless another one is
+// set with SetLockoutPolicy.
+var DefaultLockoutPolicy = LockoutPolicy{
+	Threshold:   10,
+	IPThreshold: 50,
+	DelayAfter:  3,
+	Duration:    15 * time.Minute,
+}
+
+// dummyPasswordHash is compared against when logging in with an email that
+// does not belong to a user, so it takes as long as a wrong password.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// SetLockoutPolicy sets the lockout policy. Any zero fields are taken from
+// DefaultLockoutPolicy.
+func (s *Service) SetLockoutPolicy(p LockoutPolicy) {
+	if p.Threshold == 0 {
+		p.Threshold = DefaultLockoutPolicy.Threshold
+	}
+	if p.IPThreshold == 0 {
+		p.IPThreshold = DefaultLockoutPolicy.IPThreshold
+	}
+	if p.DelayAfter == 0 {
+		p.DelayAfter = DefaultLockoutPolicy.DelayAfter
+	}
+	if p.Duration == 0 {
+		p.Duration = DefaultLockoutPolicy.Duration
+	}
+
+	s.lockout = p
+}
+
+// loginAttemptKey defines a key failed login attempts are counted under, and
+// if it is for a client IP address rather than an email.
+type loginAttemptKey struct {
+	key string
+	ip  bool
+}
+
+// loginAttemptKeys returns the keys failed login attempts are counted under
+// for the given login.
+func loginAttemptKeys(params *proto.UserLoginParams) []loginAttemptKey {
+	keys := []loginAttemptKey{
+		{key: "email:" + strings.ToLower(strings.TrimSpace(params.Email))},
+	}
+
+	if params.IPAddress != "" {
+		keys = append(keys, loginAttemptKey{key: "ip:" + params.IPAddress, ip: true})
+	}
+
+	return keys
+}
+
+// checkLockout returns ErrUserLoginLocked if any of the keys have to wait
+// before trying again.
+func (s *Service) checkLockout(ctx context.Context, keys []loginAttemptKey) error {
+	now := time.Now()
+
+	for _, k := range keys {
+		a, err := s.storage.LoginAttempt.Get(ctx, k.key)
+		if err == loginattempt.ErrLoginAttemptNotFound {
+			continue
+		} else if err != nil {
+			s.logger.Error("storage.LoginAttempt.Get() error",
+				slog.Any("error", err))
+			return err
+		}
+
+		if now.Before(s.retryAt(a, k.ip)) {
+			return serverrors.ErrUserLoginLocked
+		}
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a failed login attempt for each of the keys,
+// returning ErrUserInvalidLogin if that worked.
+func (s *Service) recordLoginFailure(ctx context.Context, keys []loginAttemptKey) error {
+	now := time.Now().UTC()
+
+	for _, k := range keys {
+		if _, err := s.storage.LoginAttempt.RecordFailure(ctx, k.key, now, now.Add(-s.lockout.Duration)); err != nil {
+			s.logger.Error("storage.LoginAttempt.RecordFailure() error",
+				slog.Any("error", err))
+			return err
+		}
+	}
+
+	return serverrors.ErrUserInvalidLogin
+}
+
+// clearLoginFailures deletes the failed login attempts for each email key.
+//
+// Client IP address keys are kept until they expire, as one account logging
+// in successfully says nothing about the other emails tried from its address.
+func (s *Service) clearLoginFailures(ctx context.Context, keys []loginAttemptKey) error {
+	for _, k := range keys {
+		if k.ip {
+			continue
+		}
+
+		if err := s.storage.LoginAttempt.Delete(ctx, k.key); err != nil {
+			s.logger.Error("storage.LoginAttempt.Delete() error",
+				slog.Any("error", err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// retryAt returns when the next login attempt is allowed after the given
+// failed attempts.
+func (s *Service) retryAt(a *loginattempt.LoginAttempt, ip bool) time.Time {
+	threshold := s.lockout.Threshold
+	if ip {
+		threshold = s.lockout.IPThreshold
+	}
+
+	var wait time.Duration
+	switch {
+	case a.Failures >= threshold:
+		wait = s.lockout.Duration
+	case !ip && a.Failures >= s.lockout.DelayAfter:
+		wait = s.lockout.Duration
+		if shift := a.Failures - s.lockout.DelayAfter; shift < 32 && time.Second<<shift < wait {
+			wait = time.Second << shift
+		}
+	}
+
+	return a.LastFailedAt.Add(wait)
+}
@@ -0,0 +1,140 @@
+package user
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"dddstructure/mail"
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/storage/user"
+	"dddstructure/storage/usertoken"
+	"dddstructure/trace"
+	"dddstructure/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordResetExpiry defines how long a password reset token is valid for.
+const passwordResetExpiry = time.Hour
+
+// ForgotPassword handles sending a password reset token to a user.
+//
+// Nothing is sent if no user has the given email, but no error is returned
+// either, so this can't be used to find out which emails have accounts.
+func (s *Service) ForgotPassword(ctx context.Context, params *proto.UserForgotPasswordParams) error {
+	ctx, span := trace.Start(ctx, "service.User.ForgotPassword")
+	defer span.End()
+
+	// Validate parameters.
+	if err := s.ValidateForgotPasswordParams(ctx, params); err != nil {
+		return err
+	}
+
+	// Get the user.
+	storageu, err := s.storage.User.GetByEmail(ctx, params.Email)
+	if err == user.ErrUserNotFound {
+		return nil
+	} else if err != nil {
+		s.logger.Error("storage.User.GetByEmail() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	// Issue a password reset token.
+	token, err := s.issueToken(ctx, storageu.ID, usertoken.TypePasswordReset, passwordResetExpiry)
+	if err != nil {
+		return err
+	}
+
+	// Send the token.
+	if err := s.mailer.Send(&mail.Message{
+		To:      storageu.Email,
+		Subject: "Reset your password",
+		Body: "Someone asked to reset the password for your account. If this was you, use the link below within the next hour:\n\n" +
+			utils.TokenLink(params.Link, token) + "\n\n" +
+			"If not, you can ignore this email and your password will stay the same.\n",
+	}); err != nil {
+		s.logger.Error("mailer.Send() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+// ResetPassword handles resetting a user's password using a password reset
+// token.
+//
+// Changing the password changes the key used to sign the user's JWTs, so all
+// existing JWTs stop working, and all of the user's sessions are revoked so
+// they can't be refreshed either. Since the token was sent to the user's email,
+// the email is marked as verified too.
+func (s *Service) ResetPassword(ctx context.Context, params *proto.UserResetPasswordParams) (*proto.User, error) {
+	ctx, span := trace.Start(ctx, "service.User.ResetPassword")
+	defer span.End()
+
+	// Validate parameters.
+	if err := s.ValidateResetPasswordParams(ctx, params); err != nil {
+		return nil, err
+	}
+
+	// Use the token.
+	storaget, err := s.consumeToken(ctx, params.Token, usertoken.TypePasswordReset)
+	if err == serverrors.ErrUserTokenInvalid {
+		return nil, serverrors.NewParamErrors(serverrors.NewParamError("token", err))
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Get user from storage.
+	storageu, err := s.storage.User.GetByID(ctx, storaget.UserID)
+	if err == user.ErrUserNotFound {
+		return nil, serverrors.NewParamErrors(serverrors.NewParamError("token", serverrors.ErrUserTokenInvalid))
+	} else if err != nil {
+		s.logger.Error("storage.User.GetByID() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Hash the password.
+	pwHash, err := bcrypt.GenerateFromPassword([]byte(params.Password), bcrypt.DefaultCost)
+	if err != nil {
+		s.logger.Error("error generating password hash",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	storageu.Password = string(pwHash)
+
+	// Handle email verified.
+	if storageu.EmailVerifiedAt == nil {
+		now := time.Now().UTC()
+		storageu.EmailVerifiedAt = &now
+	}
+
+	// Update the user.
+	storageu, err = s.storage.User.Update(ctx, storageu)
+	if err != nil {
+		s.logger.Error("storage.User.Update() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Revoke all sessions.
+	if err := s.services.Session.DeleteByUserID(ctx, storageu.ID); err != nil {
+		return nil, err
+	}
+
+	// Map to service type.
+	serviceu := &proto.User{
+		ID:                 storageu.ID,
+		Email:              storageu.Email,
+		Password:           storageu.Password,
+		EmailVerifiedAt:    storageu.EmailVerifiedAt,
+		TwoFactorEnabledAt: storageu.TOTPEnabledAt,
+	}
+
+	return serviceu, nil
+}
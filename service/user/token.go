@@ -0,0 +1,86 @@
+package user
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	serverrors "dddstructure/service/errors"
+	"dddstructure/storage/usertoken"
+	"dddstructure/utils"
+)
+
+// issueToken creates a new token of the given type for a user, replacing any
+// tokens of that type the user already had.
+func (s *Service) issueToken(ctx context.Context, userID uint, tokenType string, expiry time.Duration) (string, error) {
+	// Delete the existing tokens, so only the latest one sent can be used.
+	if err := s.storage.UserToken.DeleteByUserID(ctx, userID, tokenType); err != nil {
+		s.logger.Error("storage.UserToken.DeleteByUserID() error",
+			slog.Any("error", err))
+		return "", err
+	}
+
+	// Generate the token.
+	token, hash, err := utils.NewToken()
+	if err != nil {
+		s.logger.Error("error generating user token",
+			slog.Any("error", err))
+		return "", err
+	}
+
+	// Create the user token.
+	now := time.Now().UTC()
+	if _, err := s.storage.UserToken.Create(ctx, &usertoken.UserToken{
+		Hash:      hash,
+		UserID:    userID,
+		Type:      tokenType,
+		ExpiresAt: now.Add(expiry),
+		CreatedAt: now,
+	}); err != nil {
+		s.logger.Error("storage.UserToken.Create() error",
+			slog.Any("error", err))
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeToken checks a token of the given type and deletes it, so it can't
+// be used again.
+//
+// ErrUserTokenInvalid is returned if the token does not exist, is of another
+// type, was already used, or has expired.
+func (s *Service) consumeToken(ctx context.Context, token, tokenType string) (*usertoken.UserToken, error) {
+	// Get the user token.
+	storaget, err := s.storage.UserToken.GetByHash(ctx, utils.HashToken(token))
+	if err == usertoken.ErrUserTokenNotFound {
+		return nil, serverrors.ErrUserTokenInvalid
+	} else if err != nil {
+		s.logger.Error("storage.UserToken.GetByHash() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Check type.
+	if storaget.Type != tokenType {
+		return nil, serverrors.ErrUserTokenInvalid
+	}
+
+	// Delete the user token. Only one request can delete it, so a token
+	// used by two requests at once still only works once.
+	err = s.storage.UserToken.Delete(ctx, storaget.Hash)
+	if err == usertoken.ErrUserTokenNotFound {
+		return nil, serverrors.ErrUserTokenInvalid
+	} else if err != nil {
+		s.logger.Error("storage.UserToken.Delete() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Check expiry.
+	if time.Now().After(storaget.ExpiresAt) {
+		return nil, serverrors.ErrUserTokenInvalid
+	}
+
+	return storaget, nil
+}
@@ -0,0 +1,486 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"log/slog"
+	"strings"
+	"time"
+
+	"dddstructure/proto"
+	serverrors "dddstructure/service/errors"
+	"dddstructure/storage/recoverycode"
+	"dddstructure/storage/user"
+	"dddstructure/storage/usertoken"
+	"dddstructure/trace"
+	"dddstructure/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// loginChallengeExpiry defines how long a login challenge is valid for.
+	loginChallengeExpiry = 5 * time.Minute
+
+	// recoveryCodeCount defines how many recovery codes are issued at once.
+	recoveryCodeCount = 10
+
+	// recoveryCodeLength defines the number of characters in a recovery
+	// code, not counting the dash in the middle.
+	recoveryCodeLength = 10
+
+	// totpSkew defines how many time steps before and after the current one
+	// a TOTP code is accepted for, to allow for clock drift.
+	totpSkew = 1
+)
+
+// recoveryCodeAlphabet defines the characters of a recovery code. There are
+// 32 of them, so each random byte maps to one without bias.
+const recoveryCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+// EnrollTwoFactor handles starting TOTP two-factor authentication enrollment
+// for a user.
+//
+// A new secret is generated each time, and two-factor authentication is not
+// enabled until a code from it is confirmed with ConfirmTwoFactor.
+func (s *Service) EnrollTwoFactor(ctx context.Context, params *proto.UserEnrollTwoFactorParams) (*proto.UserTwoFactorEnrollment, error) {
+	ctx, span := trace.Start(ctx, "service.User.EnrollTwoFactor")
+	defer span.End()
+
+	// Get user from storage.
+	storageu, err := s.getStorageUser(ctx, params.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if storageu.TOTPEnabledAt != nil {
+		return nil, serverrors.ErrUserTwoFactorEnabled
+	}
+
+	// Generate the secret.
+	secret, err := utils.NewTOTPSecret()
+	if err != nil {
+		s.logger.Error("error generating TOTP secret",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	storageu.TOTPSecret = secret
+	storageu.TOTPLastStep = 0
+
+	// Update the user.
+	if _, err := s.storage.User.Update(ctx, storageu); err != nil {
+		s.logger.Error("storage.User.Update() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	enrollment := &proto.UserTwoFactorEnrollment{
+		Secret: secret,
+		URI:    utils.TOTPURI(params.Issuer, storageu.Email, secret),
+	}
+
+	return enrollment, nil
+}
+
+// ConfirmTwoFactor handles enabling two-factor authentication for a user by
+// confirming a code from the secret handed out by EnrollTwoFactor.
+//
+// The recovery codes returned are only shown this once.
+func (s *Service) ConfirmTwoFactor(ctx context.Context, params *proto.UserConfirmTwoFactorParams) ([]string, error) {
+	ctx, span := trace.Start(ctx, "service.User.ConfirmTwoFactor")
+	defer span.End()
+
+	// Validate parameters.
+	if err := s.ValidateConfirmTwoFactorParams(ctx, params); err != nil {
+		return nil, err
+	}
+
+	// Get user from storage.
+	storageu, err := s.getStorageUser(ctx, params.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if storageu.TOTPEnabledAt != nil {
+		return nil, serverrors.ErrUserTwoFactorEnabled
+	} else if storageu.TOTPSecret == "" {
+		return nil, serverrors.ErrUserTwoFactorNotEnabled
+	}
+
+	// Check the code.
+	step, err := checkTOTP(storageu, params.Code)
+	if err == serverrors.ErrUserTwoFactorCodeInvalid {
+		return nil, serverrors.NewParamErrors(serverrors.NewParamError("code", err))
+	} else if err != nil {
+		s.logger.Error("error checking TOTP code",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Enable two-factor authentication.
+	now := time.Now().UTC()
+	storageu.TOTPEnabledAt = &now
+	storageu.TOTPLastStep = step
+
+	if _, err := s.storage.User.Update(ctx, storageu); err != nil {
+		s.logger.Error("storage.User.Update() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, storageu.ID)
+}
+
+// DisableTwoFactor handles turning off two-factor authentication for a user.
+//
+// Both the password and a TOTP or recovery code are needed, so a stolen
+// session alone can't be used to turn it off.
+func (s *Service) DisableTwoFactor(ctx context.Context, params *proto.UserDisableTwoFactorParams) error {
+	ctx, span := trace.Start(ctx, "service.User.DisableTwoFactor")
+	defer span.End()
+
+	// Validate parameters.
+	if err := s.ValidateDisableTwoFactorParams(ctx, params); err != nil {
+		return err
+	}
+
+	// Get user from storage.
+	storageu, err := s.getStorageUser(ctx, params.ID)
+	if err != nil {
+		return err
+	}
+
+	if storageu.TOTPEnabledAt == nil {
+		return serverrors.ErrUserTwoFactorNotEnabled
+	}
+
+	// Check the password.
+	if err := bcrypt.CompareHashAndPassword([]byte(storageu.Password), []byte(params.Password)); err != nil {
+		return serverrors.NewParamErrors(serverrors.NewParamError("password", serverrors.ErrUserPasswordIncorrect))
+	}
+
+	// Check the code.
+	if err := s.verifyTwoFactorCode(ctx, storageu, params.Code); err == serverrors.ErrUserTwoFactorCodeInvalid {
+		return serverrors.NewParamErrors(serverrors.NewParamError("code", err))
+	} else if err != nil {
+		return err
+	}
+
+	// Turn off two-factor authentication.
+	storageu.TOTPSecret = ""
+	storageu.TOTPEnabledAt = nil
+	storageu.TOTPLastStep = 0
+
+	if _, err := s.storage.User.Update(ctx, storageu); err != nil {
+		s.logger.Error("storage.User.Update() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	// Delete the recovery codes and any outstanding login challenges.
+	if err := s.storage.RecoveryCode.DeleteByUserID(ctx, storageu.ID); err != nil {
+		s.logger.Error("storage.RecoveryCode.DeleteByUserID() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	if err := s.storage.UserToken.DeleteByUserID(ctx, storageu.ID, usertoken.TypeTwoFactorChallenge); err != nil {
+		s.logger.Error("storage.UserToken.DeleteByUserID() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes handles replacing all of a user's recovery codes
+// with new ones.
+//
+// A TOTP code is needed rather than a recovery code, and the recovery codes
+// returned are only shown this once.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, params *proto.UserRegenerateRecoveryCodesParams) ([]string, error) {
+	ctx, span := trace.Start(ctx, "service.User.RegenerateRecoveryCodes")
+	defer span.End()
+
+	// Validate parameters.
+	if err := s.ValidateRegenerateRecoveryCodesParams(ctx, params); err != nil {
+		return nil, err
+	}
+
+	// Get user from storage.
+	storageu, err := s.getStorageUser(ctx, params.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if storageu.TOTPEnabledAt == nil {
+		return nil, serverrors.ErrUserTwoFactorNotEnabled
+	}
+
+	// Check the code.
+	if err := s.useTOTP(ctx, storageu, params.Code); err == serverrors.ErrUserTwoFactorCodeInvalid {
+		return nil, serverrors.NewParamErrors(serverrors.NewParamError("code", err))
+	} else if err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, storageu.ID)
+}
+
+// CreateLoginChallenge handles creating a login challenge for a user with
+// two-factor authentication enabled, once their password has been checked.
+//
+// Only the latest challenge for a user can be used.
+func (s *Service) CreateLoginChallenge(ctx context.Context, id uint) (*proto.UserLoginChallenge, error) {
+	ctx, span := trace.Start(ctx, "service.User.CreateLoginChallenge")
+	defer span.End()
+
+	expiresAt := time.Now().UTC().Add(loginChallengeExpiry)
+
+	token, err := s.issueToken(ctx, id, usertoken.TypeTwoFactorChallenge, loginChallengeExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := &proto.UserLoginChallenge{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}
+
+	return challenge, nil
+}
+
+// LoginTwoFactor handles finishing a login by exchanging a login challenge
+// and a TOTP or recovery code for the user.
+//
+// The challenge can only be used once, even with a wrong code, so codes can't
+// be guessed without logging in with the password again.
+func (s *Service) LoginTwoFactor(ctx context.Context, params *proto.UserLoginTwoFactorParams) (*proto.User, error) {
+	ctx, span := trace.Start(ctx, "service.User.LoginTwoFactor")
+	defer span.End()
+
+	// Validate parameters.
+	if err := s.ValidateLoginTwoFactorParams(ctx, params); err != nil {
+		return nil, err
+	}
+
+	// Use the challenge.
+	storaget, err := s.consumeToken(ctx, params.ChallengeToken, usertoken.TypeTwoFactorChallenge)
+	if err == serverrors.ErrUserTokenInvalid {
+		return nil, serverrors.ErrUserTwoFactorChallengeInvalid
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Get user from storage.
+	storageu, err := s.storage.User.GetByID(ctx, storaget.UserID)
+	if err == user.ErrUserNotFound {
+		return nil, serverrors.ErrUserTwoFactorChallengeInvalid
+	} else if err != nil {
+		s.logger.Error("storage.User.GetByID() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	if storageu.TOTPEnabledAt == nil {
+		return nil, serverrors.ErrUserTwoFactorChallengeInvalid
+	}
+
+	// Check the code.
+	if err := s.verifyTwoFactorCode(ctx, storageu, params.Code); err != nil {
+		return nil, err
+	}
+
+	// Map to service type.
+	serviceu := &proto.User{
+		ID:                 storageu.ID,
+		Email:              storageu.Email,
+		Password:           storageu.Password,
+		EmailVerifiedAt:    storageu.EmailVerifiedAt,
+		TwoFactorEnabledAt: storageu.TOTPEnabledAt,
+	}
+
+	return serviceu, nil
+}
+
+// getStorageUser gets a user from storage, returning ErrUserNotFound if it
+// does not exist.
+func (s *Service) getStorageUser(ctx context.Context, id uint) (*user.User, error) {
+	storageu, err := s.storage.User.GetByID(ctx, id)
+	if err == user.ErrUserNotFound {
+		return nil, serverrors.ErrUserNotFound
+	} else if err != nil {
+		s.logger.Error("storage.User.GetByID() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	return storageu, nil
+}
+
+// verifyTwoFactorCode checks a TOTP or recovery code for a user, using it up
+// so it can't be used again.
+//
+// ErrUserTwoFactorCodeInvalid is returned if the code is wrong or was already
+// used.
+func (s *Service) verifyTwoFactorCode(ctx context.Context, storageu *user.User, code string) error {
+	// TOTP codes are all digits, which recovery codes never are.
+	code = strings.TrimSpace(code)
+	if strings.Trim(code, "0123456789 ") == "" {
+		return s.useTOTP(ctx, storageu, code)
+	}
+
+	return s.useRecoveryCode(ctx, storageu.ID, code)
+}
+
+// useTOTP checks a TOTP code for a user and saves its time step, so it can't
+// be used again.
+func (s *Service) useTOTP(ctx context.Context, storageu *user.User, code string) error {
+	step, err := checkTOTP(storageu, code)
+	if err == serverrors.ErrUserTwoFactorCodeInvalid {
+		return err
+	} else if err != nil {
+		s.logger.Error("error checking TOTP code",
+			slog.Any("error", err))
+		return err
+	}
+
+	storageu.TOTPLastStep = step
+
+	if _, err := s.storage.User.Update(ctx, storageu); err != nil {
+		s.logger.Error("storage.User.Update() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+// useRecoveryCode checks a recovery code for a user and deletes it, so it
+// can't be used again.
+func (s *Service) useRecoveryCode(ctx context.Context, userID uint, code string) error {
+	// Get the recovery code.
+	hash := utils.HashToken(normalizeRecoveryCode(code))
+
+	storagec, err := s.storage.RecoveryCode.GetByHash(ctx, hash)
+	if err == recoverycode.ErrRecoveryCodeNotFound {
+		return serverrors.ErrUserTwoFactorCodeInvalid
+	} else if err != nil {
+		s.logger.Error("storage.RecoveryCode.GetByHash() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	if storagec.UserID != userID {
+		return serverrors.ErrUserTwoFactorCodeInvalid
+	}
+
+	// Delete the recovery code. Only one request can delete it, so a code
+	// used by two requests at once still only works once.
+	err = s.storage.RecoveryCode.Delete(ctx, hash)
+	if err == recoverycode.ErrRecoveryCodeNotFound {
+		return serverrors.ErrUserTwoFactorCodeInvalid
+	} else if err != nil {
+		s.logger.Error("storage.RecoveryCode.Delete() error",
+			slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+// issueRecoveryCodes creates new recovery codes for a user, replacing any the
+// user already had.
+func (s *Service) issueRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+	// Delete the existing recovery codes.
+	if err := s.storage.RecoveryCode.DeleteByUserID(ctx, userID); err != nil {
+		s.logger.Error("storage.RecoveryCode.DeleteByUserID() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Create the recovery codes.
+	now := time.Now().UTC()
+	codes := []string{}
+	for n := 0; n < recoveryCodeCount; n++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			s.logger.Error("error generating recovery code",
+				slog.Any("error", err))
+			return nil, err
+		}
+
+		if _, err := s.storage.RecoveryCode.Create(ctx, &recoverycode.RecoveryCode{
+			Hash:      utils.HashToken(normalizeRecoveryCode(code)),
+			UserID:    userID,
+			CreatedAt: now,
+		}); err != nil {
+			s.logger.Error("storage.RecoveryCode.Create() error",
+				slog.Any("error", err))
+			return nil, err
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// checkTOTP checks a TOTP code against the secret of a user, returning the
+// time step it matched.
+//
+// Codes from the last used time step or before are rejected, so a code can't
+// be used twice.
+func checkTOTP(storageu *user.User, code string) (uint64, error) {
+	code = strings.ReplaceAll(code, " ", "")
+	current := utils.TOTPStep(time.Now())
+
+	for d := -totpSkew; d <= totpSkew; d++ {
+		step := current + uint64(d)
+		if step <= storageu.TOTPLastStep {
+			continue
+		}
+
+		expected, err := utils.TOTPCode(storageu.TOTPSecret, step)
+		if err != nil {
+			return 0, err
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, nil
+		}
+	}
+
+	return 0, serverrors.ErrUserTwoFactorCodeInvalid
+}
+
+// newRecoveryCode generates a new random recovery code, with a dash in the
+// middle to make it easier to read.
+func newRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := make([]byte, 0, recoveryCodeLength+1)
+	for n, v := range b {
+		if n == recoveryCodeLength/2 {
+			code = append(code, '-')
+		}
+		code = append(code, recoveryCodeAlphabet[int(v)%len(recoveryCodeAlphabet)])
+	}
+
+	return string(code), nil
+}
+
+// normalizeRecoveryCode removes the dash and spaces from a recovery code and
+// lowercases it, so it matches however it was typed in.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+
+	return code
+}
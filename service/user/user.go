@@ -1,25 +1,28 @@
 package user
 
 import (
+	"context"
 	"log/slog"
 
+	"dddstructure/mail"
 	"dddstructure/proto"
 	serverrors "dddstructure/service/errors"
 	"dddstructure/service/interfaces"
 	"dddstructure/storage"
 	"dddstructure/storage/user"
+	"dddstructure/storage/usertoken"
+	"dddstructure/trace"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
-// idCounter handles increasing the ID.
-var idCounter uint = 1
-
 // Service defines the user service.
 type Service struct {
 	storage  *storage.Storage
 	services *interfaces.Service
+	mailer   mail.Sender
 	logger   *slog.Logger
+	lockout  LockoutPolicy
 }
 
 // SetServices sets the services interface.
@@ -27,24 +30,39 @@ func (s *Service) SetServices(services *interfaces.Service) {
 	s.services = services
 }
 
+// WithLogger returns a copy of the service that logs to the given logger.
+func (s *Service) WithLogger(l *slog.Logger) *Service {
+	c := *s
+	c.logger = l
+	return &c
+}
+
 // New creates a new service.
-func New(s *storage.Storage, l *slog.Logger) *Service {
+func New(s *storage.Storage, m mail.Sender, l *slog.Logger) *Service {
 	return &Service{
 		storage: s,
+		mailer:  m,
 		logger:  l,
+		lockout: DefaultLockoutPolicy,
 	}
 }
 
 // Create creates a new user.
-func (s *Service) Create(params *proto.UserCreateParams) (*proto.User, error) {
+//
+// Every user gets a personal organization they own, so they can create
+// invoices right away.
+func (s *Service) Create(ctx context.Context, params *proto.UserCreateParams) (*proto.User, error) {
+	ctx, span := trace.Start(ctx, "service.User.Create")
+	defer span.End()
+
 	// Validate parameters.
-	if err := s.ValidateCreateParams(params); err != nil {
+	if err := s.ValidateCreateParams(ctx, params); err != nil {
 		return nil, err
 	}
 
 	// Handle email.
 	pes := serverrors.NewParamErrors()
-	_, err := s.storage.User.GetByEmail(params.Email)
+	_, err := s.storage.User.GetByEmail(ctx, params.Email)
 	if err == nil {
 		pes.Add(serverrors.NewParamError("email", serverrors.ErrUserEmailExists))
 	} else if err != nil && err != user.ErrUserNotFound {
@@ -58,12 +76,6 @@ func (s *Service) Create(params *proto.UserCreateParams) (*proto.User, error) {
 		return nil, pes
 	}
 
-	// Handle ID.
-	if params.ID == 0 {
-		params.ID = idCounter
-		idCounter++
-	}
-
 	// Hash the password.
 	pwHash, err := bcrypt.GenerateFromPassword([]byte(params.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -73,7 +85,7 @@ func (s *Service) Create(params *proto.UserCreateParams) (*proto.User, error) {
 	}
 
 	// Create a user.
-	storageu, err := s.storage.User.Create(&user.User{
+	storageu, err := s.storage.User.Create(ctx, &user.User{
 		ID:       params.ID,
 		Email:    params.Email,
 		Password: string(pwHash),
@@ -84,27 +96,57 @@ func (s *Service) Create(params *proto.UserCreateParams) (*proto.User, error) {
 		return nil, err
 	}
 
+	// Create a personal organization for the user.
+	if _, err := s.services.Organization.Create(ctx, &proto.OrganizationCreateParams{
+		Name:   storageu.Email,
+		UserID: storageu.ID,
+	}); err != nil {
+		return nil, err
+	}
+
 	// Map to service type.
 	serviceu := &proto.User{
-		ID:       storageu.ID,
-		Email:    storageu.Email,
-		Password: storageu.Password,
+		ID:                 storageu.ID,
+		Email:              storageu.Email,
+		Password:           storageu.Password,
+		EmailVerifiedAt:    storageu.EmailVerifiedAt,
+		TwoFactorEnabledAt: storageu.TOTPEnabledAt,
 	}
 
 	return serviceu, nil
 }
 
 // Login checks if a user exists in the database and can log in.
-func (s *Service) Login(params *proto.UserLoginParams) (*proto.User, error) {
+//
+// Failed attempts are counted per email and per client IP address. Once
+// either has failed too many times, ErrUserLoginLocked is returned until the
+// lockout ends, whether or not the email belongs to a user. A successful login
+// clears the count for its email, while the count for its client IP address
+// is only forgotten once no attempt from it has failed for the lockout
+// duration.
+func (s *Service) Login(ctx context.Context, params *proto.UserLoginParams) (*proto.User, error) {
+	ctx, span := trace.Start(ctx, "service.User.Login")
+	defer span.End()
+
 	// Validate parameters.
-	if err := s.ValidateLoginParams(params); err != nil {
+	if err := s.ValidateLoginParams(ctx, params); err != nil {
+		return nil, err
+	}
+
+	// Check the lockout.
+	keys := loginAttemptKeys(params)
+	if err := s.checkLockout(ctx, keys); err != nil {
 		return nil, err
 	}
 
 	// Try to pull this user from the database by email.
-	storageu, err := s.storage.User.GetByEmail(params.Email)
+	storageu, err := s.storage.User.GetByEmail(ctx, params.Email)
 	if err == user.ErrUserNotFound {
-		return nil, serverrors.ErrUserInvalidLogin
+		// Compare against a dummy hash, so a missing user takes as long as
+		// a wrong password.
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(params.Password))
+
+		return nil, s.recordLoginFailure(ctx, keys)
 	} else if err != nil {
 		s.logger.Error("storage.User.GetByEmail() error",
 			slog.Any("error", err))
@@ -113,23 +155,33 @@ func (s *Service) Login(params *proto.UserLoginParams) (*proto.User, error) {
 
 	// Validate the password.
 	if err := bcrypt.CompareHashAndPassword([]byte(storageu.Password), []byte(params.Password)); err != nil {
-		return nil, serverrors.ErrUserInvalidLogin
+		return nil, s.recordLoginFailure(ctx, keys)
+	}
+
+	// Clear the failed attempts.
+	if err := s.clearLoginFailures(ctx, keys); err != nil {
+		return nil, err
 	}
 
 	// Map to service type.
 	serviceu := &proto.User{
-		ID:       storageu.ID,
-		Email:    storageu.Email,
-		Password: storageu.Password,
+		ID:                 storageu.ID,
+		Email:              storageu.Email,
+		Password:           storageu.Password,
+		EmailVerifiedAt:    storageu.EmailVerifiedAt,
+		TwoFactorEnabledAt: storageu.TOTPEnabledAt,
 	}
 
 	return serviceu, nil
 }
 
 // GetByID gets a user by the given ID.
-func (s *Service) GetByID(id uint) (*proto.User, error) {
+func (s *Service) GetByID(ctx context.Context, id uint) (*proto.User, error) {
+	ctx, span := trace.Start(ctx, "service.User.GetByID")
+	defer span.End()
+
 	// Get user by ID.
-	storageu, err := s.storage.User.GetByID(id)
+	storageu, err := s.storage.User.GetByID(ctx, id)
 	if err != nil {
 		if err == user.ErrUserNotFound {
 			return nil, serverrors.ErrUserNotFound
@@ -142,23 +194,57 @@ func (s *Service) GetByID(id uint) (*proto.User, error) {
 
 	// Map to service type.
 	serviceu := &proto.User{
-		ID:       storageu.ID,
-		Email:    storageu.Email,
-		Password: storageu.Password,
+		ID:                 storageu.ID,
+		Email:              storageu.Email,
+		Password:           storageu.Password,
+		EmailVerifiedAt:    storageu.EmailVerifiedAt,
+		TwoFactorEnabledAt: storageu.TOTPEnabledAt,
+	}
+
+	return serviceu, nil
+}
+
+// GetByEmail gets a user by the given email.
+func (s *Service) GetByEmail(ctx context.Context, email string) (*proto.User, error) {
+	ctx, span := trace.Start(ctx, "service.User.GetByEmail")
+	defer span.End()
+
+	// Get user by email.
+	storageu, err := s.storage.User.GetByEmail(ctx, email)
+	if err != nil {
+		if err == user.ErrUserNotFound {
+			return nil, serverrors.ErrUserNotFound
+		}
+
+		s.logger.Error("storage.User.GetByEmail() error",
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	// Map to service type.
+	serviceu := &proto.User{
+		ID:                 storageu.ID,
+		Email:              storageu.Email,
+		Password:           storageu.Password,
+		EmailVerifiedAt:    storageu.EmailVerifiedAt,
+		TwoFactorEnabledAt: storageu.TOTPEnabledAt,
 	}
 
 	return serviceu, nil
 }
 
 // Update handles updating a user.
-func (s *Service) Update(params *proto.UserUpdateParams) (*proto.User, error) {
+func (s *Service) Update(ctx context.Context, params *proto.UserUpdateParams) (*proto.User, error) {
+	ctx, span := trace.Start(ctx, "service.User.Update")
+	defer span.End()
+
 	// Validate parameters.
-	if err := s.ValidateUpdateParams(params); err != nil {
+	if err := s.ValidateUpdateParams(ctx, params); err != nil {
 		return nil, err
 	}
 
 	// Get the user.
-	serviceu, err := s.GetByID(*params.ID)
+	serviceu, err := s.GetByID(ctx, *params.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +252,7 @@ func (s *Service) Update(params *proto.UserUpdateParams) (*proto.User, error) {
 	// Check email.
 	pes := serverrors.NewParamErrors()
 	if params.Email != nil && *params.Email != serviceu.Email {
-		_, err := s.storage.User.GetByEmail(*params.Email)
+		_, err := s.storage.User.GetByEmail(ctx, *params.Email)
 		if err == nil {
 			pes.Add(serverrors.NewParamError("email", serverrors.ErrUserEmailExists))
 		} else if err != nil && err != user.ErrUserNotFound {
@@ -182,16 +268,24 @@ func (s *Service) Update(params *proto.UserUpdateParams) (*proto.User, error) {
 	}
 
 	// Get user from storage.
-	storageu, err := s.storage.User.GetByID(*params.ID)
+	storageu, err := s.storage.User.GetByID(ctx, *params.ID)
 	if err != nil {
 		s.logger.Error("storage.User.GetByID() error",
 			slog.Any("error", err))
 		return nil, err
 	}
 
-	// Handle email.
+	// Handle email. A new email has to be verified again, so any tokens
+	// sent to the old email are deleted.
 	if params.Email != nil && *params.Email != serviceu.Email {
 		storageu.Email = *params.Email
+		storageu.EmailVerifiedAt = nil
+
+		if err := s.storage.UserToken.DeleteByUserID(ctx, storageu.ID, usertoken.TypeEmailVerification); err != nil {
+			s.logger.Error("storage.UserToken.DeleteByUserID() error",
+				slog.Any("error", err))
+			return nil, err
+		}
 	}
 
 	// Hash the password.
@@ -207,18 +301,28 @@ func (s *Service) Update(params *proto.UserUpdateParams) (*proto.User, error) {
 	}
 
 	// Update the user.
-	storageu, err = s.storage.User.Update(storageu)
+	storageu, err = s.storage.User.Update(ctx, storageu)
 	if err != nil {
 		s.logger.Error("storage.User.Update() error",
 			slog.Any("error", err))
 		return nil, err
 	}
 
+	// Revoke all sessions if the password changed, the same way all
+	// existing JWTs stop working.
+	if params.Password != nil {
+		if err := s.services.Session.DeleteByUserID(ctx, storageu.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Map to service type.
 	serviceu = &proto.User{
-		ID:       storageu.ID,
-		Email:    storageu.Email,
-		Password: storageu.Password,
+		ID:                 storageu.ID,
+		Email:              storageu.Email,
+		Password:           storageu.Password,
+		EmailVerifiedAt:    storageu.EmailVerifiedAt,
+		TwoFactorEnabledAt: storageu.TOTPEnabledAt,
 	}
 
 	return serviceu, nil
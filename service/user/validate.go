@@ -1,12 +1,13 @@
 package user
 
 import (
+	"context"
 	"dddstructure/proto"
 	"dddstructure/service/errors"
 )
 
 // ValidateCreateParams validates the create parameters.
-func (s *Service) ValidateCreateParams(params *proto.UserCreateParams) error {
+func (s *Service) ValidateCreateParams(ctx context.Context, params *proto.UserCreateParams) error {
 	// Create a new ParamErrors.
 	pes := errors.NewParamErrors()
 
@@ -29,7 +30,7 @@ func (s *Service) ValidateCreateParams(params *proto.UserCreateParams) error {
 }
 
 // ValidateLoginParams validates the login parameters.
-func (s *Service) ValidateLoginParams(params *proto.UserLoginParams) error {
+func (s *Service) ValidateLoginParams(ctx context.Context, params *proto.UserLoginParams) error {
 	// Create a new ParamErrors.
 	pes := errors.NewParamErrors()
 
@@ -47,7 +48,7 @@ func (s *Service) ValidateLoginParams(params *proto.UserLoginParams) error {
 }
 
 // ValidateUpdateParams validates the update parameters.
-func (s *Service) ValidateUpdateParams(params *proto.UserUpdateParams) error {
+func (s *Service) ValidateUpdateParams(ctx context.Context, params *proto.UserUpdateParams) error {
 	// Update a new ParamErrors.
 	pes := errors.NewParamErrors()
 
@@ -72,3 +73,145 @@ func (s *Service) ValidateUpdateParams(params *proto.UserUpdateParams) error {
 
 	return nil
 }
+
+// ValidateForgotPasswordParams validates the forgot password parameters.
+func (s *Service) ValidateForgotPasswordParams(ctx context.Context, params *proto.UserForgotPasswordParams) error {
+	// Create a new ParamErrors.
+	pes := errors.NewParamErrors()
+
+	// Check email.
+	if params.Email == "" {
+		pes.Add(errors.NewParamError("email", errors.ErrUserEmailEmpty))
+	}
+
+	// Return if there were parameter errors.
+	if pes.Length() > 0 {
+		return pes
+	}
+
+	return nil
+}
+
+// ValidateResetPasswordParams validates the reset password parameters.
+func (s *Service) ValidateResetPasswordParams(ctx context.Context, params *proto.UserResetPasswordParams) error {
+	// Create a new ParamErrors.
+	pes := errors.NewParamErrors()
+
+	// Check token.
+	if params.Token == "" {
+		pes.Add(errors.NewParamError("token", errors.ErrUserTokenInvalid))
+	}
+
+	// Check password.
+	if len(params.Password) < 8 {
+		pes.Add(errors.NewParamError("password", errors.ErrUserPassword))
+	}
+
+	// Return if there were parameter errors.
+	if pes.Length() > 0 {
+		return pes
+	}
+
+	return nil
+}
+
+// ValidateVerifyEmailParams validates the verify email parameters.
+func (s *Service) ValidateVerifyEmailParams(ctx context.Context, params *proto.UserVerifyEmailParams) error {
+	// Create a new ParamErrors.
+	pes := errors.NewParamErrors()
+
+	// Check token.
+	if params.Token == "" {
+		pes.Add(errors.NewParamError("token", errors.ErrUserTokenInvalid))
+	}
+
+	// Return if there were parameter errors.
+	if pes.Length() > 0 {
+		return pes
+	}
+
+	return nil
+}
+
+// ValidateLoginTwoFactorParams validates the login two-factor parameters.
+func (s *Service) ValidateLoginTwoFactorParams(ctx context.Context, params *proto.UserLoginTwoFactorParams) error {
+	// Create a new ParamErrors.
+	pes := errors.NewParamErrors()
+
+	// Check challenge token.
+	if params.ChallengeToken == "" {
+		pes.Add(errors.NewParamError("challenge_token", errors.ErrUserTwoFactorChallengeInvalid))
+	}
+
+	// Check code.
+	if params.Code == "" {
+		pes.Add(errors.NewParamError("code", errors.ErrUserTwoFactorCodeEmpty))
+	}
+
+	// Return if there were parameter errors.
+	if pes.Length() > 0 {
+		return pes
+	}
+
+	return nil
+}
+
+// ValidateConfirmTwoFactorParams validates the confirm two-factor parameters.
+func (s *Service) ValidateConfirmTwoFactorParams(ctx context.Context, params *proto.UserConfirmTwoFactorParams) error {
+	// Create a new ParamErrors.
+	pes := errors.NewParamErrors()
+
+	// Check code.
+	if params.Code == "" {
+		pes.Add(errors.NewParamError("code", errors.ErrUserTwoFactorCodeEmpty))
+	}
+
+	// Return if there were parameter errors.
+	if pes.Length() > 0 {
+		return pes
+	}
+
+	return nil
+}
+
+// ValidateDisableTwoFactorParams validates the disable two-factor parameters.
+func (s *Service) ValidateDisableTwoFactorParams(ctx context.Context, params *proto.UserDisableTwoFactorParams) error {
+	// Create a new ParamErrors.
+	pes := errors.NewParamErrors()
+
+	// Check password.
+	if params.Password == "" {
+		pes.Add(errors.NewParamError("password", errors.ErrUserPasswordIncorrect))
+	}
+
+	// Check code.
+	if params.Code == "" {
+		pes.Add(errors.NewParamError("code", errors.ErrUserTwoFactorCodeEmpty))
+	}
+
+	// Return if there were parameter errors.
+	if pes.Length() > 0 {
+		return pes
+	}
+
+	return nil
+}
+
+// ValidateRegenerateRecoveryCodesParams validates the regenerate recovery
+// codes parameters.
+func (s *Service) ValidateRegenerateRecoveryCodesParams(ctx context.Context, params *proto.UserRegenerateRecoveryCodesParams) error {
+	// Create a new ParamErrors.
+	pes := errors.NewParamErrors()
+
+	// Check code.
+	if params.Code == "" {
+		pes.Add(errors.NewParamError("code", errors.ErrUserTwoFactorCodeEmpty))
+	}
+
+	// Return if there were parameter errors.
+	if pes.Length() > 0 {
+		return pes
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package cache
+
+import "context"
+
+// Cache defines a cache of storage lookups, used by storage/cached to keep
+// users and invoices between calls.
+//
+// Values are stored as given, so they must not be changed after being set,
+// and callers copy values they get before changing them. Every method must
+// be safe for concurrent use.
+type Cache interface {
+	// Get gets the value of a key, and whether it was found. Values that
+	// have expired or been evicted are not found.
+	Get(key string) (interface{}, bool)
+
+	// Set sets the value of a key, replacing any value it already has.
+	Set(key string, value interface{})
+
+	// Delete deletes the value of a key, if it has one.
+	Delete(key string)
+}
+
+// allowKey defines the context key marking lookups that may be served from
+// the cache.
+type allowKey struct{}
+
+// Allow returns a copy of the given context where lookups may be served from
+// the cache, for lookups whose result is only read, such as showing a public
+// invoice.
+//
+// Lookups read the storage unless they are allowed, as the cache of one
+// process is not told of changes made by another, and a record that is read
+// before being changed and written back, such as an invoice being paid, must
+// not be stale. The values read are cached either way.
+func Allow(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowKey{}, true)
+}
+
+// Allowed returns whether lookups in the given context may be served from
+// the cache.
+func Allowed(ctx context.Context) bool {
+	a, _ := ctx.Value(allowKey{}).(bool)
+	return a
+}
@@ -0,0 +1,116 @@
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entry defines a cached value.
+type entry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// Cache defines an in-process least recently used cache, implementing
+// cache.Cache.
+//
+// Each process has its own cache, so with several API instances a value
+// changed by one instance can be read from another until it expires.
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]*list.Element
+
+	// order holds the entries, most recently used first.
+	order *list.List
+}
+
+// New creates a new cache holding up to the given number of values, each
+// expiring the given time after it is set. Once full, setting a value evicts
+// the least recently used one. A size of zero has no limit, and a TTL of
+// zero keeps values until they are evicted.
+func New(size int, ttl time.Duration) *Cache {
+	return &Cache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get gets the value of a key, and whether it was found.
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	// Expired values are removed when they are next read.
+	e := el.Value.(*entry)
+	if c.ttl > 0 && !time.Now().Before(e.expiresAt) {
+		c.remove(el)
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+
+	return e.value, true
+}
+
+// Set sets the value of a key.
+func (c *Cache) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = time.Now().Add(c.ttl)
+	}
+
+	// Replace the value of an existing key.
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry)
+		e.value = value
+		e.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expiresAt: expiresAt})
+
+	// Evict the least recently used value once over the size.
+	if c.size > 0 && c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// Delete deletes the value of a key.
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+}
+
+// Len returns the number of values in the cache, including expired values
+// not yet removed.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// remove removes an entry from the cache.
+func (c *Cache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*entry).key)
+}
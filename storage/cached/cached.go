@@ -0,0 +1,31 @@
+package cached
+
+import (
+	"dddstructure/storage"
+	"dddstructure/storage/cache"
+)
+
+// New returns a new implementation of storage.Storage that wraps the user and
+// invoice databases of the given storage, caching lookups in the given cache.
+// The other databases are used as they are.
+//
+// Users are cached by ID and invoices by ID and public hash, and both are
+// removed from the cache when they are updated or deleted through it. Only
+// lookups in a context marked with cache.Allow are served from the cache, as
+// records changed by another process stay cached until they expire. Only
+// found records are cached, so a record created later is found at once.
+func New(s *storage.Storage, c cache.Cache) *storage.Storage {
+	wrapped := *s
+	wrapped.User = NewUser(s.User, c)
+	wrapped.Invoice = NewInvoice(s.Invoice, c)
+
+	return &wrapped
+}
+
+// Decorator returns a storage.Decorator caching lookups in the given cache,
+// to be passed to storage.New.
+func Decorator(c cache.Cache) storage.Decorator {
+	return func(s *storage.Storage) *storage.Storage {
+		return New(s, c)
+	}
+}
@@ -0,0 +1,144 @@
+package cached
+
+import (
+	"context"
+	"strconv"
+
+	"dddstructure/storage/cache"
+	"dddstructure/storage/invoice"
+)
+
+// InvoiceDatabase defines an invoice database caching invoices by ID and
+// public hash.
+type InvoiceDatabase struct {
+	next  invoice.Database
+	cache cache.Cache
+}
+
+// NewInvoice creates a new invoice database that wraps the given database,
+// caching invoices by ID and public hash in the given cache.
+func NewInvoice(next invoice.Database, c cache.Cache) *InvoiceDatabase {
+	return &InvoiceDatabase{
+		next:  next,
+		cache: c,
+	}
+}
+
+// Create creates a new invoice.
+func (db *InvoiceDatabase) Create(ctx context.Context, i *invoice.Invoice) (*invoice.Invoice, error) {
+	return db.next.Create(ctx, i)
+}
+
+// CreateBatch creates new invoices in a single transaction.
+func (db *InvoiceDatabase) CreateBatch(ctx context.Context, invoices []*invoice.Invoice) ([]*invoice.Invoice, error) {
+	return db.next.CreateBatch(ctx, invoices)
+}
+
+// Get gets a set of invoices. Lists change with every invoice created, so
+// these are not cached.
+func (db *InvoiceDatabase) Get(ctx context.Context, params *invoice.GetParams) ([]*invoice.Invoice, error) {
+	return db.next.Get(ctx, params)
+}
+
+// GetCount gets the count of a set of invoices, which is not cached.
+func (db *InvoiceDatabase) GetCount(ctx context.Context, params *invoice.GetParams) (uint, error) {
+	return db.next.GetCount(ctx, params)
+}
+
+// GetByID gets an invoice by the given ID, from the cache if it is there and
+// the context allows it.
+func (db *InvoiceDatabase) GetByID(ctx context.Context, id uint) (*invoice.Invoice, error) {
+	if i, ok := db.getCached(id); ok && cache.Allowed(ctx) {
+		return i, nil
+	}
+
+	i, err := db.next.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	db.setCached(i)
+
+	return i, nil
+}
+
+// GetByPublicHash gets an invoice by the given public hash, from the cache if
+// it is there and the context allows it.
+//
+// The public hash is cached as the ID of its invoice, so updating or deleting
+// the invoice by ID also removes it from the cache for its public hash.
+func (db *InvoiceDatabase) GetByPublicHash(ctx context.Context, hash string) (*invoice.Invoice, error) {
+	if v, ok := db.cache.Get(publicHashKey(hash)); ok && cache.Allowed(ctx) {
+		if i, ok := db.getCached(v.(uint)); ok && i.PublicHash == hash {
+			return i, nil
+		}
+	}
+
+	i, err := db.next.GetByPublicHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	db.setCached(i)
+
+	return i, nil
+}
+
+// Update updates an invoice, removing it from the cache.
+//
+// The invoice is removed even if the update fails, as it may have been
+// updated anyway, such as when the context is cancelled after the query is
+// sent.
+func (db *InvoiceDatabase) Update(ctx context.Context, i *invoice.Invoice) (*invoice.Invoice, error) {
+	defer db.cache.Delete(invoiceKey(i.ID))
+	return db.next.Update(ctx, i)
+}
+
+// Delete deletes an invoice, removing it from the cache.
+func (db *InvoiceDatabase) Delete(ctx context.Context, id uint) error {
+	defer db.cache.Delete(invoiceKey(id))
+	return db.next.Delete(ctx, id)
+}
+
+// getCached gets a copy of the cached invoice with the given ID, and whether
+// it was found.
+func (db *InvoiceDatabase) getCached(id uint) (*invoice.Invoice, bool) {
+	v, ok := db.cache.Get(invoiceKey(id))
+	if !ok {
+		return nil, false
+	}
+
+	return clone(v.(*invoice.Invoice)), true
+}
+
+// setCached caches a copy of an invoice by its ID, and its ID by its public
+// hash.
+func (db *InvoiceDatabase) setCached(i *invoice.Invoice) {
+	db.cache.Set(invoiceKey(i.ID), clone(i))
+	db.cache.Set(publicHashKey(i.PublicHash), i.ID)
+}
+
+// invoiceKey returns the cache key of the invoice with the given ID.
+func invoiceKey(id uint) string {
+	return "invoice:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// publicHashKey returns the cache key of the invoice ID with the given public
+// hash.
+func publicHashKey(hash string) string {
+	return "invoice_public_hash:" + hash
+}
+
+// clone returns a copy of an invoice, including its line items and payment
+// methods, so changing one does not change the other.
+func clone(i *invoice.Invoice) *invoice.Invoice {
+	c := *i
+	if i.LineItems != nil {
+		c.LineItems = append([]invoice.LineItem{}, i.LineItems...)
+	}
+	if i.PaymentMethods != nil {
+		c.PaymentMethods = append([]string{}, i.PaymentMethods...)
+	}
+
+	return &c
+}
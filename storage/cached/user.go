@@ -0,0 +1,84 @@
+package cached
+
+import (
+	"context"
+	"strconv"
+
+	"dddstructure/storage/cache"
+	"dddstructure/storage/user"
+)
+
+// UserDatabase defines a user database caching users by ID.
+type UserDatabase struct {
+	next  user.Database
+	cache cache.Cache
+}
+
+// NewUser creates a new user database that wraps the given database, caching
+// users by ID in the given cache.
+func NewUser(next user.Database, c cache.Cache) *UserDatabase {
+	return &UserDatabase{
+		next:  next,
+		cache: c,
+	}
+}
+
+// Create creates a new user.
+func (db *UserDatabase) Create(ctx context.Context, u *user.User) (*user.User, error) {
+	return db.next.Create(ctx, u)
+}
+
+// GetByID gets a user by the given ID, from the cache if it is there and the
+// context allows it.
+func (db *UserDatabase) GetByID(ctx context.Context, id uint) (*user.User, error) {
+	if cache.Allowed(ctx) {
+		if v, ok := db.cache.Get(userKey(id)); ok {
+			return cloneUser(v.(*user.User)), nil
+		}
+	}
+
+	u, err := db.next.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache a copy, so the caller can change the returned user.
+	db.cache.Set(userKey(id), cloneUser(u))
+
+	return u, nil
+}
+
+// GetByEmail gets a user by the given email. Emails are matched differently
+// by each backend, so these are not cached.
+func (db *UserDatabase) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	return db.next.GetByEmail(ctx, email)
+}
+
+// Update updates a user, removing it from the cache.
+//
+// The user is removed even if the update fails, as it may have been updated
+// anyway, such as when the context is cancelled after the query is sent.
+func (db *UserDatabase) Update(ctx context.Context, u *user.User) (*user.User, error) {
+	defer db.cache.Delete(userKey(u.ID))
+	return db.next.Update(ctx, u)
+}
+
+// userKey returns the cache key of the user with the given ID.
+func userKey(id uint) string {
+	return "user:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// cloneUser returns a copy of the given user that shares nothing with it.
+func cloneUser(u *user.User) *user.User {
+	c := *u
+	if u.EmailVerifiedAt != nil {
+		t := *u.EmailVerifiedAt
+		c.EmailVerifiedAt = &t
+	}
+	if u.TOTPEnabledAt != nil {
+		t := *u.TOTPEnabledAt
+		c.TOTPEnabledAt = &t
+	}
+
+	return &c
+}
@@ -0,0 +1,17 @@
+package deadline
+
+import (
+	"context"
+	"time"
+)
+
+// Context returns a copy of ctx that is cancelled once the given timeout
+// passes, so a single query can not run for longer. If the timeout is zero,
+// ctx is only cancelled when its parent is.
+func Context(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,8 @@
+package health
+
+import "context"
+
+// Database defines the health database interface.
+type Database interface {
+	Ping(ctx context.Context) error
+}
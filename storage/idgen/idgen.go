@@ -0,0 +1,83 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// Generator defines a generator of IDs for new records.
+type Generator interface {
+	// NewID returns a new unique ID.
+	NewID() uint
+}
+
+const (
+	// sequenceBits defines the number of low bits of a sortable ID holding
+	// the sequence within a millisecond.
+	sequenceBits = 12
+
+	// maxSequence defines the largest sequence within a millisecond.
+	maxSequence = 1<<sequenceBits - 1
+
+	// maxStartSequence defines the largest random sequence a millisecond
+	// starts at, leaving room for the IDs after it.
+	maxStartSequence = 1<<(sequenceBits-1) - 1
+)
+
+// epoch defines the time sortable IDs count milliseconds from.
+var epoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Sortable defines a generator of IDs that sort by the time they were
+// generated, like ULIDs, but fit in 53 bits so they are exact as JSON numbers
+// in JavaScript.
+//
+// The high 41 bits are the milliseconds since 2024, and the low 12 bits are a
+// sequence that starts at a random value each millisecond, so IDs do not show
+// how many records there are. IDs from a single generator never repeat, but
+// generators in separate processes can collide, with a small chance when both
+// generate IDs in the same millisecond.
+type Sortable struct {
+	mu       sync.Mutex
+	lastMS   uint64
+	sequence uint64
+}
+
+// NewSortable creates a new sortable ID generator.
+func NewSortable() *Sortable {
+	return &Sortable{}
+}
+
+// NewID returns a new sortable ID.
+func (s *Sortable) NewID() uint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms := uint64(time.Since(epoch).Milliseconds())
+
+	switch {
+	case ms > s.lastMS:
+		// Start a new millisecond.
+		s.lastMS = ms
+		s.sequence = randomSequence()
+	case s.sequence < maxSequence:
+		// Keep counting within the last millisecond, which is also the case
+		// if the clock went backwards.
+		s.sequence++
+	default:
+		// The sequence ran out, so move on to the next millisecond early.
+		s.lastMS++
+		s.sequence = randomSequence()
+	}
+
+	return uint(s.lastMS<<sequenceBits | s.sequence)
+}
+
+// randomSequence returns a random sequence to start a millisecond at.
+func randomSequence() uint64 {
+	var b [8]byte
+	rand.Read(b[:])
+
+	return binary.BigEndian.Uint64(b[:]) & maxStartSequence
+}
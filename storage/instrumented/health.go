@@ -0,0 +1,20 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/storage/health"
+)
+
+// healthDatabase records the latency of each health database call.
+type healthDatabase struct {
+	next health.Database
+	m    *storageMetrics
+}
+
+// Ping records the latency of Ping.
+func (db *healthDatabase) Ping(ctx context.Context) error {
+	defer db.m.observe("health", "Ping", time.Now())
+	return db.next.Ping(ctx)
+}
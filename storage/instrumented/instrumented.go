@@ -0,0 +1,83 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/metrics"
+	"dddstructure/storage"
+	"dddstructure/storage/invoice"
+	"dddstructure/storage/transaction"
+)
+
+// storageMetrics defines the metrics recorded by the storage decorators.
+type storageMetrics struct {
+	calls           *metrics.Histogram
+	invoicesCreated *metrics.Counter
+	payments        *metrics.Counter
+	refunded        *metrics.Counter
+
+	// invoices is used to look up the currency of refunded invoices.
+	invoices invoice.Database
+}
+
+// New returns a new implementation of storage.Storage that wraps each
+// database of the given storage, recording metrics to the given registry.
+//
+// The latency of every call is recorded per repository and method. Business
+// metrics are recorded as invoices and transactions are created, so they
+// count the same whichever service or backend creates them.
+func New(s *storage.Storage, r *metrics.Registry) *storage.Storage {
+	m := &storageMetrics{
+		calls: r.NewHistogram("dddstructure_storage_call_duration_seconds",
+			"Latency of storage calls.", metrics.DefaultBuckets, "repository", "method"),
+		invoicesCreated: r.NewCounter("dddstructure_invoices_created_total",
+			"Number of invoices created.", "currency"),
+		payments: r.NewCounter("dddstructure_payments_total",
+			"Number of invoice payments, by whether they were approved or declined.", "status"),
+		refunded: r.NewCounter("dddstructure_refunded_amount_total",
+			"Amount refunded, in the smallest unit of the currency.", "currency"),
+		invoices: s.Invoice,
+	}
+
+	return &storage.Storage{
+		User:         &userDatabase{next: s.User, m: m},
+		UserToken:    &usertokenDatabase{next: s.UserToken, m: m},
+		RecoveryCode: &recoverycodeDatabase{next: s.RecoveryCode, m: m},
+		LoginAttempt: &loginattemptDatabase{next: s.LoginAttempt, m: m},
+		Session:      &sessionDatabase{next: s.Session, m: m},
+		Organization: &organizationDatabase{next: s.Organization, m: m},
+		Invoice:      &invoiceDatabase{next: s.Invoice, m: m},
+		Transaction:  &transactionDatabase{next: s.Transaction, m: m},
+		Report:       &reportDatabase{next: s.Report, m: m},
+		Outbox:       &outboxDatabase{next: s.Outbox, m: m},
+		Health:       &healthDatabase{next: s.Health, m: m},
+	}
+}
+
+// observe records the latency of a call started at the given time.
+func (m *storageMetrics) observe(repository, method string, start time.Time) {
+	m.calls.Observe(time.Since(start).Seconds(), repository, method)
+}
+
+// recordTransaction records the business metrics of a created transaction.
+//
+// Transactions do not have a currency, so the currency of a refund is taken
+// from its invoice.
+func (m *storageMetrics) recordTransaction(ctx context.Context, t *transaction.Transaction) {
+	switch t.Type {
+	case "sale":
+		m.payments.Inc(t.Status)
+	case "refund":
+		if t.Status != "approved" {
+			return
+		}
+
+		currency := "unknown"
+		if i, err := m.invoices.GetByID(ctx, t.InvoiceID); err == nil {
+			currency = i.Currency
+		}
+
+		m.refunded.Add(float64(t.AmountCaptured), currency)
+	}
+}
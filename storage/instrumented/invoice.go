@@ -0,0 +1,78 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/storage/invoice"
+)
+
+// invoiceDatabase records the latency of each invoice database call.
+type invoiceDatabase struct {
+	next invoice.Database
+	m    *storageMetrics
+}
+
+// Create records the latency of Create.
+func (db *invoiceDatabase) Create(ctx context.Context, i *invoice.Invoice) (*invoice.Invoice, error) {
+	defer db.m.observe("invoice", "Create", time.Now())
+
+	created, err := db.next.Create(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+
+	db.m.invoicesCreated.Inc(created.Currency)
+	return created, nil
+}
+
+// CreateBatch records the latency of CreateBatch.
+func (db *invoiceDatabase) CreateBatch(ctx context.Context, invoices []*invoice.Invoice) ([]*invoice.Invoice, error) {
+	defer db.m.observe("invoice", "CreateBatch", time.Now())
+
+	created, err := db.next.CreateBatch(ctx, invoices)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range created {
+		db.m.invoicesCreated.Inc(i.Currency)
+	}
+	return created, nil
+}
+
+// Get records the latency of Get.
+func (db *invoiceDatabase) Get(ctx context.Context, params *invoice.GetParams) ([]*invoice.Invoice, error) {
+	defer db.m.observe("invoice", "Get", time.Now())
+	return db.next.Get(ctx, params)
+}
+
+// GetCount records the latency of GetCount.
+func (db *invoiceDatabase) GetCount(ctx context.Context, params *invoice.GetParams) (uint, error) {
+	defer db.m.observe("invoice", "GetCount", time.Now())
+	return db.next.GetCount(ctx, params)
+}
+
+// GetByID records the latency of GetByID.
+func (db *invoiceDatabase) GetByID(ctx context.Context, id uint) (*invoice.Invoice, error) {
+	defer db.m.observe("invoice", "GetByID", time.Now())
+	return db.next.GetByID(ctx, id)
+}
+
+// GetByPublicHash records the latency of GetByPublicHash.
+func (db *invoiceDatabase) GetByPublicHash(ctx context.Context, hash string) (*invoice.Invoice, error) {
+	defer db.m.observe("invoice", "GetByPublicHash", time.Now())
+	return db.next.GetByPublicHash(ctx, hash)
+}
+
+// Update records the latency of Update.
+func (db *invoiceDatabase) Update(ctx context.Context, i *invoice.Invoice) (*invoice.Invoice, error) {
+	defer db.m.observe("invoice", "Update", time.Now())
+	return db.next.Update(ctx, i)
+}
+
+// Delete records the latency of Delete.
+func (db *invoiceDatabase) Delete(ctx context.Context, id uint) error {
+	defer db.m.observe("invoice", "Delete", time.Now())
+	return db.next.Delete(ctx, id)
+}
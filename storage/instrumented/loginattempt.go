@@ -0,0 +1,32 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/storage/loginattempt"
+)
+
+// loginattemptDatabase records the latency of each login attempt database call.
+type loginattemptDatabase struct {
+	next loginattempt.Database
+	m    *storageMetrics
+}
+
+// Get records the latency of Get.
+func (db *loginattemptDatabase) Get(ctx context.Context, key string) (*loginattempt.LoginAttempt, error) {
+	defer db.m.observe("loginattempt", "Get", time.Now())
+	return db.next.Get(ctx, key)
+}
+
+// RecordFailure records the latency of RecordFailure.
+func (db *loginattemptDatabase) RecordFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (*loginattempt.LoginAttempt, error) {
+	defer db.m.observe("loginattempt", "RecordFailure", time.Now())
+	return db.next.RecordFailure(ctx, key, failedAt, resetBefore)
+}
+
+// Delete records the latency of Delete.
+func (db *loginattemptDatabase) Delete(ctx context.Context, key string) error {
+	defer db.m.observe("loginattempt", "Delete", time.Now())
+	return db.next.Delete(ctx, key)
+}
@@ -0,0 +1,80 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/storage/organization"
+)
+
+// organizationDatabase records the latency of each organization database call.
+type organizationDatabase struct {
+	next organization.Database
+	m    *storageMetrics
+}
+
+// Create records the latency of Create.
+func (db *organizationDatabase) Create(ctx context.Context, o *organization.Organization, owner *organization.Member) (*organization.Organization, error) {
+	defer db.m.observe("organization", "Create", time.Now())
+	return db.next.Create(ctx, o, owner)
+}
+
+// GetByID records the latency of GetByID.
+func (db *organizationDatabase) GetByID(ctx context.Context, id uint) (*organization.Organization, error) {
+	defer db.m.observe("organization", "GetByID", time.Now())
+	return db.next.GetByID(ctx, id)
+}
+
+// CreateMember records the latency of CreateMember.
+func (db *organizationDatabase) CreateMember(ctx context.Context, m *organization.Member) (*organization.Member, error) {
+	defer db.m.observe("organization", "CreateMember", time.Now())
+	return db.next.CreateMember(ctx, m)
+}
+
+// GetMember records the latency of GetMember.
+func (db *organizationDatabase) GetMember(ctx context.Context, organizationID, userID uint) (*organization.Member, error) {
+	defer db.m.observe("organization", "GetMember", time.Now())
+	return db.next.GetMember(ctx, organizationID, userID)
+}
+
+// GetMembers records the latency of GetMembers.
+func (db *organizationDatabase) GetMembers(ctx context.Context, organizationID uint) ([]*organization.Member, error) {
+	defer db.m.observe("organization", "GetMembers", time.Now())
+	return db.next.GetMembers(ctx, organizationID)
+}
+
+// GetByUserID records the latency of GetByUserID.
+func (db *organizationDatabase) GetByUserID(ctx context.Context, userID uint) ([]*organization.Membership, error) {
+	defer db.m.observe("organization", "GetByUserID", time.Now())
+	return db.next.GetByUserID(ctx, userID)
+}
+
+// UpdateMember records the latency of UpdateMember.
+func (db *organizationDatabase) UpdateMember(ctx context.Context, m *organization.Member) (*organization.Member, error) {
+	defer db.m.observe("organization", "UpdateMember", time.Now())
+	return db.next.UpdateMember(ctx, m)
+}
+
+// DeleteMember records the latency of DeleteMember.
+func (db *organizationDatabase) DeleteMember(ctx context.Context, organizationID, userID uint) error {
+	defer db.m.observe("organization", "DeleteMember", time.Now())
+	return db.next.DeleteMember(ctx, organizationID, userID)
+}
+
+// CreateInvitation records the latency of CreateInvitation.
+func (db *organizationDatabase) CreateInvitation(ctx context.Context, i *organization.Invitation) (*organization.Invitation, error) {
+	defer db.m.observe("organization", "CreateInvitation", time.Now())
+	return db.next.CreateInvitation(ctx, i)
+}
+
+// GetInvitationByHash records the latency of GetInvitationByHash.
+func (db *organizationDatabase) GetInvitationByHash(ctx context.Context, hash string) (*organization.Invitation, error) {
+	defer db.m.observe("organization", "GetInvitationByHash", time.Now())
+	return db.next.GetInvitationByHash(ctx, hash)
+}
+
+// DeleteInvitation records the latency of DeleteInvitation.
+func (db *organizationDatabase) DeleteInvitation(ctx context.Context, hash string) error {
+	defer db.m.observe("organization", "DeleteInvitation", time.Now())
+	return db.next.DeleteInvitation(ctx, hash)
+}
@@ -0,0 +1,44 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/storage/outbox"
+)
+
+// outboxDatabase records the latency of each outbox database call.
+type outboxDatabase struct {
+	next outbox.Database
+	m    *storageMetrics
+}
+
+// Claim records the latency of Claim.
+func (db *outboxDatabase) Claim(ctx context.Context, limit uint, until time.Time) ([]*outbox.Event, error) {
+	defer db.m.observe("outbox", "Claim", time.Now())
+	return db.next.Claim(ctx, limit, until)
+}
+
+// Delete records the latency of Delete.
+func (db *outboxDatabase) Delete(ctx context.Context, id string) error {
+	defer db.m.observe("outbox", "Delete", time.Now())
+	return db.next.Delete(ctx, id)
+}
+
+// Fail records the latency of Fail.
+func (db *outboxDatabase) Fail(ctx context.Context, id, reason string, retryAt time.Time) error {
+	defer db.m.observe("outbox", "Fail", time.Now())
+	return db.next.Fail(ctx, id, reason, retryAt)
+}
+
+// DeadLetter records the latency of DeadLetter.
+func (db *outboxDatabase) DeadLetter(ctx context.Context, id, reason string) error {
+	defer db.m.observe("outbox", "DeadLetter", time.Now())
+	return db.next.DeadLetter(ctx, id, reason)
+}
+
+// GetDeadLetters records the latency of GetDeadLetters.
+func (db *outboxDatabase) GetDeadLetters(ctx context.Context, limit uint) ([]*outbox.Event, error) {
+	defer db.m.observe("outbox", "GetDeadLetters", time.Now())
+	return db.next.GetDeadLetters(ctx, limit)
+}
@@ -0,0 +1,38 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/storage/recoverycode"
+)
+
+// recoverycodeDatabase records the latency of each recovery code database call.
+type recoverycodeDatabase struct {
+	next recoverycode.Database
+	m    *storageMetrics
+}
+
+// Create records the latency of Create.
+func (db *recoverycodeDatabase) Create(ctx context.Context, c *recoverycode.RecoveryCode) (*recoverycode.RecoveryCode, error) {
+	defer db.m.observe("recoverycode", "Create", time.Now())
+	return db.next.Create(ctx, c)
+}
+
+// GetByHash records the latency of GetByHash.
+func (db *recoverycodeDatabase) GetByHash(ctx context.Context, hash string) (*recoverycode.RecoveryCode, error) {
+	defer db.m.observe("recoverycode", "GetByHash", time.Now())
+	return db.next.GetByHash(ctx, hash)
+}
+
+// Delete records the latency of Delete.
+func (db *recoverycodeDatabase) Delete(ctx context.Context, hash string) error {
+	defer db.m.observe("recoverycode", "Delete", time.Now())
+	return db.next.Delete(ctx, hash)
+}
+
+// DeleteByUserID records the latency of DeleteByUserID.
+func (db *recoverycodeDatabase) DeleteByUserID(ctx context.Context, userID uint) error {
+	defer db.m.observe("recoverycode", "DeleteByUserID", time.Now())
+	return db.next.DeleteByUserID(ctx, userID)
+}
@@ -0,0 +1,32 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/storage/report"
+)
+
+// reportDatabase records the latency of each report database call.
+type reportDatabase struct {
+	next report.Database
+	m    *storageMetrics
+}
+
+// GetAging records the latency of GetAging.
+func (db *reportDatabase) GetAging(ctx context.Context, params *report.AgingParams) ([]*report.AgingBucket, error) {
+	defer db.m.observe("report", "GetAging", time.Now())
+	return db.next.GetAging(ctx, params)
+}
+
+// GetRevenue records the latency of GetRevenue.
+func (db *reportDatabase) GetRevenue(ctx context.Context, params *report.RevenueParams) ([]*report.Revenue, error) {
+	defer db.m.observe("report", "GetRevenue", time.Now())
+	return db.next.GetRevenue(ctx, params)
+}
+
+// GetBalances records the latency of GetBalances.
+func (db *reportDatabase) GetBalances(ctx context.Context, params *report.BalancesParams) ([]*report.Balance, error) {
+	defer db.m.observe("report", "GetBalances", time.Now())
+	return db.next.GetBalances(ctx, params)
+}
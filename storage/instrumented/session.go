@@ -0,0 +1,68 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/storage/session"
+)
+
+// sessionDatabase records the latency of each session database call.
+type sessionDatabase struct {
+	next session.Database
+	m    *storageMetrics
+}
+
+// Create records the latency of Create.
+func (db *sessionDatabase) Create(ctx context.Context, s *session.Session) (*session.Session, error) {
+	defer db.m.observe("session", "Create", time.Now())
+	return db.next.Create(ctx, s)
+}
+
+// GetByID records the latency of GetByID.
+func (db *sessionDatabase) GetByID(ctx context.Context, id string) (*session.Session, error) {
+	defer db.m.observe("session", "GetByID", time.Now())
+	return db.next.GetByID(ctx, id)
+}
+
+// GetByUserID records the latency of GetByUserID.
+func (db *sessionDatabase) GetByUserID(ctx context.Context, userID uint) ([]*session.Session, error) {
+	defer db.m.observe("session", "GetByUserID", time.Now())
+	return db.next.GetByUserID(ctx, userID)
+}
+
+// Update records the latency of Update.
+func (db *sessionDatabase) Update(ctx context.Context, s *session.Session) (*session.Session, error) {
+	defer db.m.observe("session", "Update", time.Now())
+	return db.next.Update(ctx, s)
+}
+
+// Delete records the latency of Delete.
+func (db *sessionDatabase) Delete(ctx context.Context, id string) error {
+	defer db.m.observe("session", "Delete", time.Now())
+	return db.next.Delete(ctx, id)
+}
+
+// DeleteByUserID records the latency of DeleteByUserID.
+func (db *sessionDatabase) DeleteByUserID(ctx context.Context, userID uint) error {
+	defer db.m.observe("session", "DeleteByUserID", time.Now())
+	return db.next.DeleteByUserID(ctx, userID)
+}
+
+// CreateRefreshToken records the latency of CreateRefreshToken.
+func (db *sessionDatabase) CreateRefreshToken(ctx context.Context, t *session.RefreshToken) (*session.RefreshToken, error) {
+	defer db.m.observe("session", "CreateRefreshToken", time.Now())
+	return db.next.CreateRefreshToken(ctx, t)
+}
+
+// GetRefreshTokenByHash records the latency of GetRefreshTokenByHash.
+func (db *sessionDatabase) GetRefreshTokenByHash(ctx context.Context, hash string) (*session.RefreshToken, error) {
+	defer db.m.observe("session", "GetRefreshTokenByHash", time.Now())
+	return db.next.GetRefreshTokenByHash(ctx, hash)
+}
+
+// UseRefreshToken records the latency of UseRefreshToken.
+func (db *sessionDatabase) UseRefreshToken(ctx context.Context, hash string, usedAt time.Time) error {
+	defer db.m.observe("session", "UseRefreshToken", time.Now())
+	return db.next.UseRefreshToken(ctx, hash, usedAt)
+}
@@ -0,0 +1,33 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/storage/transaction"
+)
+
+// transactionDatabase records the latency of each transaction database call.
+type transactionDatabase struct {
+	next transaction.Database
+	m    *storageMetrics
+}
+
+// Create records the latency of Create.
+func (db *transactionDatabase) Create(ctx context.Context, i *transaction.Transaction) (*transaction.Transaction, error) {
+	defer db.m.observe("transaction", "Create", time.Now())
+
+	created, err := db.next.Create(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+
+	db.m.recordTransaction(ctx, created)
+	return created, nil
+}
+
+// GetByID records the latency of GetByID.
+func (db *transactionDatabase) GetByID(ctx context.Context, id uint) (*transaction.Transaction, error) {
+	defer db.m.observe("transaction", "GetByID", time.Now())
+	return db.next.GetByID(ctx, id)
+}
@@ -0,0 +1,38 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/storage/user"
+)
+
+// userDatabase records the latency of each user database call.
+type userDatabase struct {
+	next user.Database
+	m    *storageMetrics
+}
+
+// Create records the latency of Create.
+func (db *userDatabase) Create(ctx context.Context, u *user.User) (*user.User, error) {
+	defer db.m.observe("user", "Create", time.Now())
+	return db.next.Create(ctx, u)
+}
+
+// GetByID records the latency of GetByID.
+func (db *userDatabase) GetByID(ctx context.Context, id uint) (*user.User, error) {
+	defer db.m.observe("user", "GetByID", time.Now())
+	return db.next.GetByID(ctx, id)
+}
+
+// GetByEmail records the latency of GetByEmail.
+func (db *userDatabase) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	defer db.m.observe("user", "GetByEmail", time.Now())
+	return db.next.GetByEmail(ctx, email)
+}
+
+// Update records the latency of Update.
+func (db *userDatabase) Update(ctx context.Context, u *user.User) (*user.User, error) {
+	defer db.m.observe("user", "Update", time.Now())
+	return db.next.Update(ctx, u)
+}
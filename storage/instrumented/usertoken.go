@@ -0,0 +1,38 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/storage/usertoken"
+)
+
+// usertokenDatabase records the latency of each user token database call.
+type usertokenDatabase struct {
+	next usertoken.Database
+	m    *storageMetrics
+}
+
+// Create records the latency of Create.
+func (db *usertokenDatabase) Create(ctx context.Context, t *usertoken.UserToken) (*usertoken.UserToken, error) {
+	defer db.m.observe("usertoken", "Create", time.Now())
+	return db.next.Create(ctx, t)
+}
+
+// GetByHash records the latency of GetByHash.
+func (db *usertokenDatabase) GetByHash(ctx context.Context, hash string) (*usertoken.UserToken, error) {
+	defer db.m.observe("usertoken", "GetByHash", time.Now())
+	return db.next.GetByHash(ctx, hash)
+}
+
+// Delete records the latency of Delete.
+func (db *usertokenDatabase) Delete(ctx context.Context, hash string) error {
+	defer db.m.observe("usertoken", "Delete", time.Now())
+	return db.next.Delete(ctx, hash)
+}
+
+// DeleteByUserID records the latency of DeleteByUserID.
+func (db *usertokenDatabase) DeleteByUserID(ctx context.Context, userID uint, tokenType string) error {
+	defer db.m.observe("usertoken", "DeleteByUserID", time.Now())
+	return db.next.DeleteByUserID(ctx, userID, tokenType)
+}
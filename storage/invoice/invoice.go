@@ -1,16 +1,23 @@
 package invoice
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Database defines the invoice database interface.
+//
+// CreateBatch creates every invoice in a single database transaction, along
+// with their events, so either all of them are created or none are.
 type Database interface {
-	Create(i *Invoice) (*Invoice, error)
-	Get(params *GetParams) ([]*Invoice, error)
-	GetCount(params *GetParams) (uint, error)
-	GetByID(id uint) (*Invoice, error)
-	GetByPublicHash(hash string) (*Invoice, error)
-	Update(i *Invoice) (*Invoice, error)
-	Delete(id uint) error
+	Create(ctx context.Context, i *Invoice) (*Invoice, error)
+	CreateBatch(ctx context.Context, invoices []*Invoice) ([]*Invoice, error)
+	Get(ctx context.Context, params *GetParams) ([]*Invoice, error)
+	GetCount(ctx context.Context, params *GetParams) (uint, error)
+	GetByID(ctx context.Context, id uint) (*Invoice, error)
+	GetByPublicHash(ctx context.Context, hash string) (*Invoice, error)
+	Update(ctx context.Context, i *Invoice) (*Invoice, error)
+	Delete(ctx context.Context, id uint) error
 }
 
 // BillTo defines the billing information.
@@ -54,6 +61,7 @@ type LineItem struct {
 // Invoice defines an invoice.
 type Invoice struct {
 	ID             uint
+	OrganizationID uint
 	UserID         uint
 	PublicHash     string
 	InvoiceNumber  string
@@ -78,12 +86,25 @@ type GetParamsCreatedAt struct {
 	EndDate   *time.Time
 }
 
+// GetParamsCursor defines a keyset pagination cursor.
+//
+// Invoices are ordered by created at datetime and ID, newest first. The
+// cursor points at the invoice to page from, and Before determines if the
+// invoices before (newer) or after (older) it are returned. Either way, the
+// returned invoices keep the newest first ordering.
+type GetParamsCursor struct {
+	CreatedAt time.Time
+	ID        uint
+	Before    bool
+}
+
 // GetParams defines the get parameters.
 type GetParams struct {
-	ID        *uint
-	UserID    *uint
-	Status    *string
-	CreatedAt *GetParamsCreatedAt
-	Offset    uint
-	Limit     uint
+	ID             *uint
+	OrganizationID *uint
+	Status         *string
+	CreatedAt      *GetParamsCreatedAt
+	Cursor         *GetParamsCursor
+	Offset         uint
+	Limit          uint
 }
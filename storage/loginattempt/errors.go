@@ -0,0 +1,9 @@
+package loginattempt
+
+import "errors"
+
+var (
+	// ErrLoginAttemptNotFound is returned when no failed login attempts
+	// could be found for a key.
+	ErrLoginAttemptNotFound = errors.New("login attempt not found")
+)
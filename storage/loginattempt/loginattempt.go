@@ -0,0 +1,21 @@
+package loginattempt
+
+import (
+	"context"
+	"time"
+)
+
+// Database defines the login attempt database interface.
+type Database interface {
+	Get(ctx context.Context, key string) (*LoginAttempt, error)
+	RecordFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (*LoginAttempt, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// LoginAttempt defines the failed login attempts for a key, such as an email
+// or a client IP address.
+type LoginAttempt struct {
+	Key          string
+	Failures     uint
+	LastFailedAt time.Time
+}
@@ -0,0 +1,22 @@
+package health
+
+import (
+	"context"
+)
+
+// Database defines the database.
+type Database struct{}
+
+// New creates a new database.
+func New() *Database {
+	return &Database{}
+}
+
+// Ping checks the database can be reached, which memory always can.
+func (db *Database) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,326 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"dddstructure/storage/invoice"
+	memoryoutbox "dddstructure/storage/memory/outbox"
+	"dddstructure/storage/memory/sequence"
+	"dddstructure/storage/outbox"
+)
+
+// errDuplicateID is returned when creating an invoice with the ID of an
+// existing invoice, as MySQL returns a duplicate key error.
+var errDuplicateID = errors.New("duplicate invoice ID")
+
+// Database defines the database.
+type Database struct {
+	mu       sync.RWMutex
+	invoices map[uint]*invoice.Invoice
+	ids      *sequence.Sequence
+	events   *memoryoutbox.Database
+}
+
+// New creates a new database, writing the events of each change to the
+// given outbox.
+func New(events *memoryoutbox.Database) *Database {
+	return &Database{
+		invoices: make(map[uint]*invoice.Invoice),
+		ids:      sequence.New(),
+		events:   events,
+	}
+}
+
+// Create creates a new invoice.
+func (db *Database) Create(ctx context.Context, i *invoice.Invoice) (*invoice.Invoice, error) {
+	invoices, err := db.CreateBatch(ctx, []*invoice.Invoice{i})
+	if err != nil {
+		return nil, err
+	}
+
+	return invoices[0], nil
+}
+
+// CreateBatch creates new invoices, either all of them or none.
+func (db *Database) CreateBatch(ctx context.Context, invoices []*invoice.Invoice) ([]*invoice.Invoice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// Every invoice is checked before any is stored, like a database
+	// transaction.
+	created := []*invoice.Invoice{}
+	ids := map[uint]bool{}
+	var events []*outbox.Event
+	for _, i := range invoices {
+		inv := clone(i)
+		inv.ID = db.ids.Assign(i.ID)
+
+		if _, ok := db.invoices[inv.ID]; ok || ids[inv.ID] {
+			return nil, errDuplicateID
+		}
+		ids[inv.ID] = true
+
+		e, err := outbox.InvoiceEvents(nil, inv)
+		if err != nil {
+			return nil, err
+		}
+
+		created = append(created, inv)
+		events = append(events, e...)
+	}
+
+	ret := []*invoice.Invoice{}
+	for _, inv := range created {
+		db.invoices[inv.ID] = inv
+		ret = append(ret, clone(inv))
+	}
+	db.events.Add(events)
+
+	return ret, nil
+}
+
+// Get gets a set of invoices.
+func (db *Database) Get(ctx context.Context, params *invoice.GetParams) ([]*invoice.Invoice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	invoices := []*invoice.Invoice{}
+	for _, i := range db.invoices {
+		if !matches(i, params) {
+			continue
+		}
+
+		// Handle cursor.
+		if params.Cursor != nil {
+			cmp := compareNewestFirst(i, params.Cursor.CreatedAt, params.Cursor.ID)
+			if params.Cursor.Before && cmp >= 0 {
+				continue
+			}
+			if !params.Cursor.Before && cmp <= 0 {
+				continue
+			}
+		}
+
+		invoices = append(invoices, i)
+	}
+
+	// Sort newest first, or oldest first when paging before the cursor so
+	// the offset and limit apply to the invoices closest to the cursor.
+	before := params.Cursor != nil && params.Cursor.Before
+	sort.Slice(invoices, func(a, b int) bool {
+		cmp := compareNewestFirst(invoices[a], invoices[b].CreatedAt, invoices[b].ID)
+		if before {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+
+	// Handle offset and limit.
+	if params.Offset >= uint(len(invoices)) {
+		invoices = []*invoice.Invoice{}
+	} else {
+		invoices = invoices[params.Offset:]
+	}
+
+	if params.Limit < uint(len(invoices)) {
+		invoices = invoices[:params.Limit]
+	}
+
+	// Restore newest first ordering.
+	if before {
+		for l, r := 0, len(invoices)-1; l < r; l, r = l+1, r-1 {
+			invoices[l], invoices[r] = invoices[r], invoices[l]
+		}
+	}
+
+	for k, i := range invoices {
+		invoices[k] = clone(i)
+	}
+
+	return invoices, nil
+}
+
+// GetCount gets the count of a set of invoices.
+func (db *Database) GetCount(ctx context.Context, params *invoice.GetParams) (uint, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var count uint
+	for _, i := range db.invoices {
+		if matches(i, params) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// GetByID gets an invoice by the given ID.
+func (db *Database) GetByID(ctx context.Context, id uint) (*invoice.Invoice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	i, ok := db.invoices[id]
+	if !ok {
+		return nil, invoice.ErrInvoiceNotFound
+	}
+
+	return clone(i), nil
+}
+
+// GetByPublicHash gets an invoice by the given public hash.
+func (db *Database) GetByPublicHash(ctx context.Context, hash string) (*invoice.Invoice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, i := range db.invoices {
+		if i.PublicHash == hash {
+			return clone(i), nil
+		}
+	}
+
+	return nil, invoice.ErrInvoiceNotFound
+}
+
+// Update updates an invoice.
+//
+// Like MySQL, updating an invoice that does not exist does nothing.
+func (db *Database) Update(ctx context.Context, i *invoice.Invoice) (*invoice.Invoice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if old, ok := db.invoices[i.ID]; ok {
+		events, err := outbox.InvoiceEvents(old, i)
+		if err != nil {
+			return nil, err
+		}
+
+		db.invoices[i.ID] = clone(i)
+		db.events.Add(events)
+	}
+
+	return i, nil
+}
+
+// Delete deletes an invoice.
+//
+// If the invoice does not exist, ErrInvoiceNotFound is returned.
+func (db *Database) Delete(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.invoices[id]; !ok {
+		return invoice.ErrInvoiceNotFound
+	}
+
+	delete(db.invoices, id)
+
+	return nil
+}
+
+// All gets every invoice.
+//
+// This is not part of the invoice.Database interface, it lets the memory
+// report database aggregate invoices the way MySQL does.
+func (db *Database) All() []*invoice.Invoice {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	invoices := []*invoice.Invoice{}
+	for _, i := range db.invoices {
+		invoices = append(invoices, clone(i))
+	}
+
+	return invoices
+}
+
+// matches checks if an invoice matches the filters of the given get params,
+// the same filters the MySQL database applies.
+func matches(i *invoice.Invoice, params *invoice.GetParams) bool {
+	// Handle ID.
+	if params.ID != nil && i.ID != *params.ID {
+		return false
+	}
+
+	// Handle organization ID.
+	if params.OrganizationID != nil && i.OrganizationID != *params.OrganizationID {
+		return false
+	}
+
+	// Handle status.
+	if params.Status != nil && i.Status != *params.Status {
+		return false
+	}
+
+	// Handle created at.
+	if params.CreatedAt != nil {
+		if params.CreatedAt.StartDate != nil && i.CreatedAt.Before(*params.CreatedAt.StartDate) {
+			return false
+		}
+		if params.CreatedAt.EndDate != nil && i.CreatedAt.After(*params.CreatedAt.EndDate) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareNewestFirst compares the given invoice to the given created at
+// datetime and ID, returning -1 if the invoice sorts first when ordering
+// newest first, 1 if it sorts last, and 0 if they are equal.
+func compareNewestFirst(i *invoice.Invoice, createdAt time.Time, id uint) int {
+	switch {
+	case i.CreatedAt.After(createdAt):
+		return -1
+	case i.CreatedAt.Before(createdAt):
+		return 1
+	case i.ID > id:
+		return -1
+	case i.ID < id:
+		return 1
+	}
+
+	return 0
+}
+
+// clone returns a copy of an invoice, including its line items and payment
+// methods.
+func clone(i *invoice.Invoice) *invoice.Invoice {
+	c := *i
+	c.LineItems = append([]invoice.LineItem(nil), i.LineItems...)
+	c.PaymentMethods = append([]string(nil), i.PaymentMethods...)
+
+	return &c
+}
@@ -0,0 +1,81 @@
+package loginattempt
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"dddstructure/storage/loginattempt"
+)
+
+// Database defines the database.
+type Database struct {
+	mu       sync.Mutex
+	attempts map[string]*loginattempt.LoginAttempt
+}
+
+// New creates a new database.
+func New() *Database {
+	return &Database{
+		attempts: make(map[string]*loginattempt.LoginAttempt),
+	}
+}
+
+// Get gets the failed login attempts for the given key.
+func (db *Database) Get(ctx context.Context, key string) (*loginattempt.LoginAttempt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	a, ok := db.attempts[key]
+	if !ok {
+		return nil, loginattempt.ErrLoginAttemptNotFound
+	}
+
+	attempt := *a
+	return &attempt, nil
+}
+
+// RecordFailure adds a failed login attempt for the given key, returning the
+// updated attempts.
+//
+// The count starts over if the last failure was before resetBefore.
+func (db *Database) RecordFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (*loginattempt.LoginAttempt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	a, ok := db.attempts[key]
+	if !ok || a.LastFailedAt.Before(resetBefore) {
+		a = &loginattempt.LoginAttempt{
+			Key: key,
+		}
+		db.attempts[key] = a
+	}
+
+	a.Failures++
+	a.LastFailedAt = failedAt
+
+	attempt := *a
+	return &attempt, nil
+}
+
+// Delete deletes the failed login attempts for the given key.
+func (db *Database) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	delete(db.attempts, key)
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"dddstructure/storage"
+	"dddstructure/storage/memory/health"
+	"dddstructure/storage/memory/invoice"
+	"dddstructure/storage/memory/loginattempt"
+	"dddstructure/storage/memory/organization"
+	"dddstructure/storage/memory/outbox"
+	"dddstructure/storage/memory/recoverycode"
+	"dddstructure/storage/memory/report"
+	"dddstructure/storage/memory/session"
+	"dddstructure/storage/memory/transaction"
+	"dddstructure/storage/memory/user"
+	"dddstructure/storage/memory/usertoken"
+)
+
+// New returns a new implementation of storage.Storage that keeps everything
+// in memory.
+//
+// Each call returns storage with its own empty state, so separate instances
+// never see each other's data. Every database is safe for concurrent use,
+// and returns copies of what it stores, so callers can not change stored
+// data without an update.
+func New() *storage.Storage {
+	events := outbox.New()
+	invoices := invoice.New(events)
+	transactions := transaction.New(events)
+
+	s := &storage.Storage{
+		User:         user.New(),
+		UserToken:    usertoken.New(),
+		RecoveryCode: recoverycode.New(),
+		LoginAttempt: loginattempt.New(),
+		Session:      session.New(),
+		Organization: organization.New(),
+		Invoice:      invoices,
+		Transaction:  transactions,
+		Report:       report.New(invoices, transactions),
+		Outbox:       events,
+		Health:       health.New(),
+	}
+
+	return s
+}
@@ -0,0 +1,299 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"dddstructure/storage/memory/sequence"
+	"dddstructure/storage/organization"
+)
+
+var (
+	// errDuplicateID is returned when creating an organization with the ID
+	// of an existing organization, as MySQL returns a duplicate key error.
+	errDuplicateID = errors.New("duplicate organization ID")
+
+	// errDuplicateMember is returned when adding a user to an organization
+	// they are already a member of.
+	errDuplicateMember = errors.New("duplicate organization member")
+
+	// errInvalidRole is returned when adding a member with a role the
+	// database does not know, as the MySQL role column is an enum.
+	errInvalidRole = errors.New("invalid organization member role")
+
+	// errDuplicateHash is returned when creating an invitation with the hash
+	// of an existing invitation.
+	errDuplicateHash = errors.New("duplicate organization invitation hash")
+)
+
+// roles defines the roles a member can have.
+var roles = map[string]bool{
+	"owner":      true,
+	"admin":      true,
+	"accountant": true,
+	"read_only":  true,
+}
+
+// memberKey defines the key of a member in the member map.
+type memberKey struct {
+	organizationID uint
+	userID         uint
+}
+
+// Database defines the database.
+type Database struct {
+	mu            sync.RWMutex
+	organizations map[uint]*organization.Organization
+	members       map[memberKey]*organization.Member
+	invitations   map[string]*organization.Invitation
+	ids           *sequence.Sequence
+}
+
+// New creates a new database.
+func New() *Database {
+	return &Database{
+		organizations: make(map[uint]*organization.Organization),
+		members:       make(map[memberKey]*organization.Member),
+		invitations:   make(map[string]*organization.Invitation),
+		ids:           sequence.New(),
+	}
+}
+
+// Create creates a new organization, with the given member as its owner.
+func (db *Database) Create(ctx context.Context, o *organization.Organization, owner *organization.Member) (*organization.Organization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.organizations[o.ID]; ok {
+		return nil, errDuplicateID
+	}
+	if !roles[owner.Role] {
+		return nil, errInvalidRole
+	}
+
+	org := *o
+	org.ID = db.ids.Assign(o.ID)
+
+	member := *owner
+	member.OrganizationID = org.ID
+
+	db.organizations[org.ID] = &org
+	db.members[memberKey{member.OrganizationID, member.UserID}] = &member
+
+	created := org
+	return &created, nil
+}
+
+// GetByID gets an organization by the given ID.
+func (db *Database) GetByID(ctx context.Context, id uint) (*organization.Organization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	o, ok := db.organizations[id]
+	if !ok {
+		return nil, organization.ErrOrganizationNotFound
+	}
+
+	org := *o
+	return &org, nil
+}
+
+// CreateMember creates a new organization member.
+func (db *Database) CreateMember(ctx context.Context, m *organization.Member) (*organization.Member, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key := memberKey{m.OrganizationID, m.UserID}
+	if _, ok := db.members[key]; ok {
+		return nil, errDuplicateMember
+	}
+	if !roles[m.Role] {
+		return nil, errInvalidRole
+	}
+
+	member := *m
+	db.members[key] = &member
+
+	created := member
+	return &created, nil
+}
+
+// GetMember gets the member of an organization by the given user ID.
+func (db *Database) GetMember(ctx context.Context, organizationID, userID uint) (*organization.Member, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	m, ok := db.members[memberKey{organizationID, userID}]
+	if !ok {
+		return nil, organization.ErrMemberNotFound
+	}
+
+	member := *m
+	return &member, nil
+}
+
+// GetMembers gets the members of an organization, ordered by when they
+// joined.
+func (db *Database) GetMembers(ctx context.Context, organizationID uint) ([]*organization.Member, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	members := []*organization.Member{}
+	for _, m := range db.members {
+		if m.OrganizationID == organizationID {
+			member := *m
+			members = append(members, &member)
+		}
+	}
+
+	sort.Slice(members, func(a, b int) bool {
+		if !members[a].CreatedAt.Equal(members[b].CreatedAt) {
+			return members[a].CreatedAt.Before(members[b].CreatedAt)
+		}
+		return members[a].UserID < members[b].UserID
+	})
+
+	return members, nil
+}
+
+// GetByUserID gets the organizations a user is a member of, along with their
+// role in each, ordered by ID.
+func (db *Database) GetByUserID(ctx context.Context, userID uint) ([]*organization.Membership, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	memberships := []*organization.Membership{}
+	for _, m := range db.members {
+		if m.UserID == userID {
+			memberships = append(memberships, &organization.Membership{
+				Organization: *db.organizations[m.OrganizationID],
+				Role:         m.Role,
+			})
+		}
+	}
+
+	sort.Slice(memberships, func(a, b int) bool {
+		return memberships[a].ID < memberships[b].ID
+	})
+
+	return memberships, nil
+}
+
+// UpdateMember updates an organization member.
+//
+// Like MySQL, updating a member that does not exist does nothing.
+func (db *Database) UpdateMember(ctx context.Context, m *organization.Member) (*organization.Member, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key := memberKey{m.OrganizationID, m.UserID}
+	if _, ok := db.members[key]; ok {
+		member := *m
+		db.members[key] = &member
+	}
+
+	return m, nil
+}
+
+// DeleteMember deletes an organization member.
+func (db *Database) DeleteMember(ctx context.Context, organizationID, userID uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	delete(db.members, memberKey{organizationID, userID})
+
+	return nil
+}
+
+// CreateInvitation creates a new organization invitation.
+func (db *Database) CreateInvitation(ctx context.Context, i *organization.Invitation) (*organization.Invitation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.invitations[i.Hash]; ok {
+		return nil, errDuplicateHash
+	}
+
+	invitation := *i
+	db.invitations[invitation.Hash] = &invitation
+
+	created := invitation
+	return &created, nil
+}
+
+// GetInvitationByHash gets an organization invitation by the given hash.
+func (db *Database) GetInvitationByHash(ctx context.Context, hash string) (*organization.Invitation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	i, ok := db.invitations[hash]
+	if !ok {
+		return nil, organization.ErrInvitationNotFound
+	}
+
+	invitation := *i
+	return &invitation, nil
+}
+
+// DeleteInvitation deletes an organization invitation.
+//
+// If the invitation does not exist, ErrInvitationNotFound is returned. Only
+// one of any concurrent deletes of the same invitation succeeds.
+func (db *Database) DeleteInvitation(ctx context.Context, hash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.invitations[hash]; !ok {
+		return organization.ErrInvitationNotFound
+	}
+
+	delete(db.invitations, hash)
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"dddstructure/storage/outbox"
+)
+
+// Database defines the database.
+type Database struct {
+	mu     sync.Mutex
+	events []*record
+}
+
+// record defines an event with its claim. An event is available to claim
+// once availableAt has passed, unless it is dead.
+type record struct {
+	event       *outbox.Event
+	availableAt time.Time
+	dead        bool
+}
+
+// New creates a new database.
+func New() *Database {
+	return &Database{}
+}
+
+// Claim claims up to the given number of events waiting to be published,
+// oldest first, until the given time. Events claimed by another relay are
+// skipped until their claim ends.
+func (db *Database) Claim(ctx context.Context, limit uint, until time.Time) ([]*outbox.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	now := time.Now()
+	events := []*outbox.Event{}
+	for _, r := range db.events {
+		if uint(len(events)) == limit {
+			break
+		}
+		if r.dead || r.availableAt.After(now) {
+			continue
+		}
+
+		r.availableAt = until
+		events = append(events, clone(r.event))
+	}
+
+	return events, nil
+}
+
+// Delete deletes an event once it is published.
+//
+// If the event does not exist, ErrEventNotFound is returned.
+func (db *Database) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for n, r := range db.events {
+		if r.event.ID == id {
+			db.events = append(db.events[:n], db.events[n+1:]...)
+			return nil
+		}
+	}
+
+	return outbox.ErrEventNotFound
+}
+
+// Fail records a failed attempt to publish an event, releasing its claim so
+// it is claimed again from the given time.
+//
+// If the event does not exist, ErrEventNotFound is returned.
+func (db *Database) Fail(ctx context.Context, id, reason string, retryAt time.Time) error {
+	return db.fail(ctx, id, reason, func(r *record) {
+		r.availableAt = retryAt
+	})
+}
+
+// DeadLetter records a failed attempt to publish an event, which is never
+// claimed again.
+//
+// If the event does not exist, ErrEventNotFound is returned.
+func (db *Database) DeadLetter(ctx context.Context, id, reason string) error {
+	return db.fail(ctx, id, reason, func(r *record) {
+		r.dead = true
+	})
+}
+
+// GetDeadLetters gets up to the given number of dead events, oldest first.
+func (db *Database) GetDeadLetters(ctx context.Context, limit uint) ([]*outbox.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	events := []*outbox.Event{}
+	for _, r := range db.events {
+		if uint(len(events)) == limit {
+			break
+		}
+		if r.dead {
+			events = append(events, clone(r.event))
+		}
+	}
+
+	return events, nil
+}
+
+// fail records a failed attempt to publish an event, then releases it with
+// the given function.
+func (db *Database) fail(ctx context.Context, id, reason string, release func(r *record)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, r := range db.events {
+		if r.event.ID == id {
+			r.event.Attempts++
+			r.event.LastError = reason
+			release(r)
+			return nil
+		}
+	}
+
+	return outbox.ErrEventNotFound
+}
+
+// Add adds events in the order they are given.
+//
+// This is not part of the outbox.Database interface, it lets the memory
+// invoice and transaction databases write events while they hold their own
+// lock, like a database transaction.
+func (db *Database) Add(events []*outbox.Event) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, e := range events {
+		db.events = append(db.events, &record{event: clone(e)})
+	}
+}
+
+// clone returns a copy of an event, including its payload.
+func clone(e *outbox.Event) *outbox.Event {
+	c := *e
+	c.Payload = append([]byte(nil), e.Payload...)
+
+	return &c
+}
@@ -0,0 +1,103 @@
+package recoverycode
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"dddstructure/storage/recoverycode"
+)
+
+// errDuplicateHash is returned when creating a recovery code with the hash of
+// an existing code, as MySQL returns a duplicate key error.
+var errDuplicateHash = errors.New("duplicate recovery code hash")
+
+// Database defines the database.
+type Database struct {
+	mu    sync.RWMutex
+	codes map[string]*recoverycode.RecoveryCode
+}
+
+// New creates a new database.
+func New() *Database {
+	return &Database{
+		codes: make(map[string]*recoverycode.RecoveryCode),
+	}
+}
+
+// Create creates a new recovery code.
+func (db *Database) Create(ctx context.Context, c *recoverycode.RecoveryCode) (*recoverycode.RecoveryCode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.codes[c.Hash]; ok {
+		return nil, errDuplicateHash
+	}
+
+	rc := *c
+	db.codes[rc.Hash] = &rc
+
+	created := rc
+	return &created, nil
+}
+
+// GetByHash gets a recovery code by the given hash.
+func (db *Database) GetByHash(ctx context.Context, hash string) (*recoverycode.RecoveryCode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	c, ok := db.codes[hash]
+	if !ok {
+		return nil, recoverycode.ErrRecoveryCodeNotFound
+	}
+
+	rc := *c
+	return &rc, nil
+}
+
+// Delete deletes a recovery code.
+//
+// If the recovery code does not exist, ErrRecoveryCodeNotFound is returned.
+// Only one of any concurrent deletes of the same code succeeds.
+func (db *Database) Delete(ctx context.Context, hash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.codes[hash]; !ok {
+		return recoverycode.ErrRecoveryCodeNotFound
+	}
+
+	delete(db.codes, hash)
+
+	return nil
+}
+
+// DeleteByUserID deletes all recovery codes for a user.
+func (db *Database) DeleteByUserID(ctx context.Context, userID uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for hash, c := range db.codes {
+		if c.UserID == userID {
+			delete(db.codes, hash)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"dddstructure/storage/memory/invoice"
+	"dddstructure/storage/memory/transaction"
+	"dddstructure/storage/report"
+)
+
+// Database defines the database.
+type Database struct {
+	invoices     *invoice.Database
+	transactions *transaction.Database
+}
+
+// New creates a new database.
+//
+// Reports aggregate over invoices and transactions, so the memory invoice
+// and transaction databases are read directly.
+func New(invoices *invoice.Database, transactions *transaction.Database) *Database {
+	return &Database{
+		invoices:     invoices,
+		transactions: transactions,
+	}
+}
+
+// GetAging gets the amount due on unpaid invoices per currency and aging
+// bucket.
+func (db *Database) GetAging(ctx context.Context, params *report.AgingParams) ([]*report.AgingBucket, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	asOf := truncateDay(params.AsOf)
+
+	// Sum the invoices into their buckets.
+	bucketMap := make(map[[2]string]*report.AgingBucket)
+	for _, i := range db.invoices.All() {
+		// Handle organization ID, status and amount due.
+		if i.OrganizationID != params.OrganizationID || i.Status == "paid" || i.AmountDue == 0 {
+			continue
+		}
+
+		// Handle bucket.
+		days := int(asOf.Sub(truncateDay(i.DueDate)).Hours() / 24)
+
+		var name string
+		switch {
+		case days <= 0:
+			name = report.AgingBucketCurrent
+		case days <= 30:
+			name = report.AgingBucket1To30
+		case days <= 60:
+			name = report.AgingBucket31To60
+		case days <= 90:
+			name = report.AgingBucket61To90
+		default:
+			name = report.AgingBucket90Plus
+		}
+
+		key := [2]string{i.Currency, name}
+		if _, ok := bucketMap[key]; !ok {
+			bucketMap[key] = &report.AgingBucket{
+				Currency: i.Currency,
+				Bucket:   name,
+			}
+		}
+
+		bucketMap[key].Count++
+		bucketMap[key].AmountDue += i.AmountDue
+	}
+
+	// Build buckets slice.
+	buckets := []*report.AgingBucket{}
+	for _, b := range bucketMap {
+		buckets = append(buckets, b)
+	}
+
+	sort.Slice(buckets, func(a, b int) bool {
+		if buckets[a].Currency != buckets[b].Currency {
+			return buckets[a].Currency < buckets[b].Currency
+		}
+		return buckets[a].Bucket < buckets[b].Bucket
+	})
+
+	return buckets, nil
+}
+
+// GetRevenue gets the revenue collected and refunded per period and
+// currency.
+//
+// Transactions take the currency of the invoice they belong to, so
+// transactions without an invoice are not counted.
+func (db *Database) GetRevenue(ctx context.Context, params *report.RevenueParams) ([]*report.Revenue, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Map the invoice currencies.
+	currencies := make(map[uint]string)
+	for _, i := range db.invoices.All() {
+		currencies[i.ID] = i.Currency
+	}
+
+	// Sum the transactions into their periods.
+	revenueMap := make(map[string]*report.Revenue)
+	for _, t := range db.transactions.All() {
+		// Handle organization ID and status.
+		if t.OrganizationID != params.OrganizationID || t.Status != "approved" {
+			continue
+		}
+
+		// Handle type.
+		if t.Type != "sale" && t.Type != "capture" && t.Type != "refund" {
+			continue
+		}
+
+		// Handle created at.
+		if params.StartDate != nil && t.CreatedAt.Before(*params.StartDate) {
+			continue
+		}
+		if params.EndDate != nil && t.CreatedAt.After(*params.EndDate) {
+			continue
+		}
+
+		// Handle currency.
+		currency, ok := currencies[t.InvoiceID]
+		if !ok {
+			continue
+		}
+
+		// Handle period.
+		day := truncateDay(t.CreatedAt)
+
+		var period time.Time
+		switch params.Interval {
+		case report.RevenueIntervalDay:
+			period = day
+		case report.RevenueIntervalWeek:
+			period = day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		case report.RevenueIntervalMonth:
+			period = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		default:
+			return nil, fmt.Errorf("unknown revenue interval %q", params.Interval)
+		}
+
+		key := period.Format("2006-01-02") + currency
+		if _, ok := revenueMap[key]; !ok {
+			revenueMap[key] = &report.Revenue{
+				Period:   period,
+				Currency: currency,
+			}
+		}
+
+		revenueMap[key].Count++
+		if t.Type == "refund" {
+			revenueMap[key].Refunded += t.AmountCaptured
+		} else {
+			revenueMap[key].Collected += t.AmountCaptured
+		}
+	}
+
+	// Build revenue slice.
+	revenue := []*report.Revenue{}
+	for _, r := range revenueMap {
+		revenue = append(revenue, r)
+	}
+
+	sort.Slice(revenue, func(a, b int) bool {
+		if !revenue[a].Period.Equal(revenue[b].Period) {
+			return revenue[a].Period.Before(revenue[b].Period)
+		}
+		return revenue[a].Currency < revenue[b].Currency
+	})
+
+	return revenue, nil
+}
+
+// GetBalances gets the outstanding and collected totals of invoices per
+// currency.
+func (db *Database) GetBalances(ctx context.Context, params *report.BalancesParams) ([]*report.Balance, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Sum the invoices into their currencies.
+	balanceMap := make(map[string]*report.Balance)
+	for _, i := range db.invoices.All() {
+		// Handle organization ID.
+		if i.OrganizationID != params.OrganizationID {
+			continue
+		}
+
+		// Handle created at.
+		if params.StartDate != nil && i.CreatedAt.Before(*params.StartDate) {
+			continue
+		}
+		if params.EndDate != nil && i.CreatedAt.After(*params.EndDate) {
+			continue
+		}
+
+		if _, ok := balanceMap[i.Currency]; !ok {
+			balanceMap[i.Currency] = &report.Balance{
+				Currency: i.Currency,
+			}
+		}
+
+		balanceMap[i.Currency].Count++
+		balanceMap[i.Currency].Collected += i.AmountPaid
+		if i.Status != "paid" {
+			balanceMap[i.Currency].Outstanding += i.AmountDue
+		}
+	}
+
+	// Build balances slice.
+	balances := []*report.Balance{}
+	for _, b := range balanceMap {
+		balances = append(balances, b)
+	}
+
+	sort.Slice(balances, func(a, b int) bool {
+		return balances[a].Currency < balances[b].Currency
+	})
+
+	return balances, nil
+}
+
+// truncateDay returns the start of the UTC day of the given time, the same
+// way MySQL compares dates.
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,33 @@
+package sequence
+
+import "sync"
+
+// Sequence defines a sequence of IDs, acting like a MySQL AUTO_INCREMENT
+// column for the memory databases.
+type Sequence struct {
+	mu   sync.Mutex
+	next uint
+}
+
+// New creates a new sequence starting at 1.
+func New() *Sequence {
+	return &Sequence{
+		next: 1,
+	}
+}
+
+// Assign returns the given ID if it is set, moving the sequence past it, or
+// the next ID of the sequence otherwise.
+func (s *Sequence) Assign(id uint) uint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id == 0 {
+		id = s.next
+	}
+	if id >= s.next {
+		s.next = id + 1
+	}
+
+	return id
+}
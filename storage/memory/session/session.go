@@ -0,0 +1,223 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"dddstructure/storage/session"
+)
+
+var (
+	// errDuplicateID is returned when creating a session with the ID of an
+	// existing session, as MySQL returns a duplicate key error.
+	errDuplicateID = errors.New("duplicate session ID")
+
+	// errDuplicateHash is returned when creating a refresh token with the
+	// hash of an existing token.
+	errDuplicateHash = errors.New("duplicate refresh token hash")
+)
+
+// Database defines the database.
+type Database struct {
+	mu            sync.RWMutex
+	sessions      map[string]*session.Session
+	refreshTokens map[string]*session.RefreshToken
+}
+
+// New creates a new database.
+func New() *Database {
+	return &Database{
+		sessions:      make(map[string]*session.Session),
+		refreshTokens: make(map[string]*session.RefreshToken),
+	}
+}
+
+// Create creates a new session.
+func (db *Database) Create(ctx context.Context, s *session.Session) (*session.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.sessions[s.ID]; ok {
+		return nil, errDuplicateID
+	}
+
+	sess := *s
+	db.sessions[sess.ID] = &sess
+
+	created := sess
+	return &created, nil
+}
+
+// GetByID gets a session by the given ID.
+func (db *Database) GetByID(ctx context.Context, id string) (*session.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	s, ok := db.sessions[id]
+	if !ok {
+		return nil, session.ErrSessionNotFound
+	}
+
+	sess := *s
+	return &sess, nil
+}
+
+// GetByUserID gets the sessions of a user, most recently used first.
+func (db *Database) GetByUserID(ctx context.Context, userID uint) ([]*session.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	sessions := []*session.Session{}
+	for _, s := range db.sessions {
+		if s.UserID == userID {
+			sess := *s
+			sessions = append(sessions, &sess)
+		}
+	}
+
+	sort.Slice(sessions, func(a, b int) bool {
+		if !sessions[a].LastUsedAt.Equal(sessions[b].LastUsedAt) {
+			return sessions[a].LastUsedAt.After(sessions[b].LastUsedAt)
+		}
+		return sessions[a].ID < sessions[b].ID
+	})
+
+	return sessions, nil
+}
+
+// Update updates a session.
+//
+// Like MySQL, updating a session that does not exist does nothing.
+func (db *Database) Update(ctx context.Context, s *session.Session) (*session.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.sessions[s.ID]; ok {
+		sess := *s
+		db.sessions[s.ID] = &sess
+	}
+
+	return s, nil
+}
+
+// Delete deletes a session and its refresh tokens.
+func (db *Database) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.delete(id)
+
+	return nil
+}
+
+// DeleteByUserID deletes all sessions of a user and their refresh tokens.
+func (db *Database) DeleteByUserID(ctx context.Context, userID uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for id, s := range db.sessions {
+		if s.UserID == userID {
+			db.delete(id)
+		}
+	}
+
+	return nil
+}
+
+// CreateRefreshToken creates a new refresh token.
+func (db *Database) CreateRefreshToken(ctx context.Context, t *session.RefreshToken) (*session.RefreshToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.refreshTokens[t.Hash]; ok {
+		return nil, errDuplicateHash
+	}
+
+	rt := *t
+	db.refreshTokens[rt.Hash] = &rt
+
+	created := rt
+	return &created, nil
+}
+
+// GetRefreshTokenByHash gets a refresh token by the given hash.
+func (db *Database) GetRefreshTokenByHash(ctx context.Context, hash string) (*session.RefreshToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	t, ok := db.refreshTokens[hash]
+	if !ok {
+		return nil, session.ErrRefreshTokenNotFound
+	}
+
+	rt := *t
+	return &rt, nil
+}
+
+// UseRefreshToken marks a refresh token as used.
+//
+// If the token was already used, ErrRefreshTokenUsed is returned, so only one
+// request can use a token.
+func (db *Database) UseRefreshToken(ctx context.Context, hash string, usedAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	t, ok := db.refreshTokens[hash]
+	if !ok || t.UsedAt != nil {
+		return session.ErrRefreshTokenUsed
+	}
+
+	t.UsedAt = &usedAt
+
+	return nil
+}
+
+// delete deletes a session and its refresh tokens. The lock must be held.
+func (db *Database) delete(id string) {
+	for hash, t := range db.refreshTokens {
+		if t.SessionID == id {
+			delete(db.refreshTokens, hash)
+		}
+	}
+
+	delete(db.sessions, id)
+}
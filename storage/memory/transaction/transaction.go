@@ -0,0 +1,97 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	memoryoutbox "dddstructure/storage/memory/outbox"
+	"dddstructure/storage/memory/sequence"
+	"dddstructure/storage/outbox"
+	"dddstructure/storage/transaction"
+)
+
+// errDuplicateID is returned when creating a transaction with the ID of an
+// existing transaction, as MySQL returns a duplicate key error.
+var errDuplicateID = errors.New("duplicate transaction ID")
+
+// Database defines the database.
+type Database struct {
+	mu           sync.RWMutex
+	transactions map[uint]*transaction.Transaction
+	ids          *sequence.Sequence
+	events       *memoryoutbox.Database
+}
+
+// New creates a new database, writing the events of each transaction to the
+// given outbox.
+func New(events *memoryoutbox.Database) *Database {
+	return &Database{
+		transactions: make(map[uint]*transaction.Transaction),
+		ids:          sequence.New(),
+		events:       events,
+	}
+}
+
+// Create creates a new transaction.
+func (db *Database) Create(ctx context.Context, t *transaction.Transaction) (*transaction.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.transactions[t.ID]; ok {
+		return nil, errDuplicateID
+	}
+
+	trans := *t
+	trans.ID = db.ids.Assign(t.ID)
+
+	events, err := outbox.TransactionEvents(&trans)
+	if err != nil {
+		return nil, err
+	}
+
+	db.transactions[trans.ID] = &trans
+	db.events.Add(events)
+
+	created := trans
+	return &created, nil
+}
+
+// GetByID gets a transaction by the given ID.
+func (db *Database) GetByID(ctx context.Context, id uint) (*transaction.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	t, ok := db.transactions[id]
+	if !ok {
+		return nil, transaction.ErrTransactionNotFound
+	}
+
+	trans := *t
+	return &trans, nil
+}
+
+// All gets every transaction.
+//
+// This is not part of the transaction.Database interface, it lets the memory
+// report database aggregate transactions the way MySQL does.
+func (db *Database) All() []*transaction.Transaction {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	transactions := []*transaction.Transaction{}
+	for _, t := range db.transactions {
+		trans := *t
+		transactions = append(transactions, &trans)
+	}
+
+	return transactions
+}
@@ -0,0 +1,112 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"dddstructure/storage/memory/sequence"
+	"dddstructure/storage/user"
+)
+
+// errDuplicateID is returned when creating a user with the ID of an existing
+// user, as MySQL returns a duplicate key error.
+var errDuplicateID = errors.New("duplicate user ID")
+
+// Database defines the database.
+type Database struct {
+	mu    sync.RWMutex
+	users map[uint]*user.User
+	ids   *sequence.Sequence
+}
+
+// New creates a new database.
+func New() *Database {
+	return &Database{
+		users: make(map[uint]*user.User),
+		ids:   sequence.New(),
+	}
+}
+
+// Create creates a new user.
+func (db *Database) Create(ctx context.Context, u *user.User) (*user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.users[u.ID]; ok {
+		return nil, errDuplicateID
+	}
+
+	use := *u
+	use.ID = db.ids.Assign(u.ID)
+
+	db.users[use.ID] = &use
+
+	created := use
+	return &created, nil
+}
+
+// GetByID gets a user by the given ID.
+func (db *Database) GetByID(ctx context.Context, id uint) (*user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	u, ok := db.users[id]
+	if !ok {
+		return nil, user.ErrUserNotFound
+	}
+
+	use := *u
+	return &use, nil
+}
+
+// GetByEmail gets a user by the given email.
+//
+// Emails are compared ignoring case, as MySQL compares them with a case
+// insensitive collation.
+func (db *Database) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	// Loop through users.
+	for _, v := range db.users {
+		if strings.EqualFold(v.Email, email) {
+			use := *v
+			return &use, nil
+		}
+	}
+
+	return nil, user.ErrUserNotFound
+}
+
+// Update updates a user.
+//
+// Like MySQL, updating a user that does not exist does nothing.
+func (db *Database) Update(ctx context.Context, u *user.User) (*user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.users[u.ID]; ok {
+		use := *u
+		db.users[u.ID] = &use
+	}
+
+	return u, nil
+}
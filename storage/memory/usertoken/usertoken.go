@@ -0,0 +1,103 @@
+package usertoken
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"dddstructure/storage/usertoken"
+)
+
+// errDuplicateHash is returned when creating a user token with the hash of
+// an existing token, as MySQL returns a duplicate key error.
+var errDuplicateHash = errors.New("duplicate user token hash")
+
+// Database defines the database.
+type Database struct {
+	mu     sync.RWMutex
+	tokens map[string]*usertoken.UserToken
+}
+
+// New creates a new database.
+func New() *Database {
+	return &Database{
+		tokens: make(map[string]*usertoken.UserToken),
+	}
+}
+
+// Create creates a new user token.
+func (db *Database) Create(ctx context.Context, t *usertoken.UserToken) (*usertoken.UserToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.tokens[t.Hash]; ok {
+		return nil, errDuplicateHash
+	}
+
+	ut := *t
+	db.tokens[ut.Hash] = &ut
+
+	created := ut
+	return &created, nil
+}
+
+// GetByHash gets a user token by the given hash.
+func (db *Database) GetByHash(ctx context.Context, hash string) (*usertoken.UserToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	t, ok := db.tokens[hash]
+	if !ok {
+		return nil, usertoken.ErrUserTokenNotFound
+	}
+
+	ut := *t
+	return &ut, nil
+}
+
+// Delete deletes a user token.
+//
+// If the user token does not exist, ErrUserTokenNotFound is returned. Only
+// one of any concurrent deletes of the same token succeeds.
+func (db *Database) Delete(ctx context.Context, hash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.tokens[hash]; !ok {
+		return usertoken.ErrUserTokenNotFound
+	}
+
+	delete(db.tokens, hash)
+
+	return nil
+}
+
+// DeleteByUserID deletes all user tokens of the given type for a user.
+func (db *Database) DeleteByUserID(ctx context.Context, userID uint, tokenType string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for hash, t := range db.tokens {
+		if t.UserID == userID && t.Type == tokenType {
+			delete(db.tokens, hash)
+		}
+	}
+
+	return nil
+}
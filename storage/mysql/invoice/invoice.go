@@ -4,9 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"time"
 
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/idgen"
 	"dddstructure/storage/invoice"
 	"dddstructure/storage/mysql/models"
+	mysqloutbox "dddstructure/storage/mysql/outbox"
+	"dddstructure/storage/outbox"
 
 	"github.com/volatiletech/null/v8"
 	"github.com/volatiletech/sqlboiler/v4/boil"
@@ -15,56 +20,124 @@ import (
 
 // Database defines the database.
 type Database struct {
-	db *sql.DB
+	db      *sql.DB
+	timeout time.Duration
+	ids     idgen.Generator
 }
 
-// New creates a new database.
-func New(db *sql.DB) *Database {
+// New creates a new database, where each query is cancelled after the
+// given timeout. The IDs of new invoices are taken from the given generator,
+// or assigned by the AUTO_INCREMENT column if it is nil.
+func New(db *sql.DB, timeout time.Duration, ids idgen.Generator) *Database {
 	return &Database{
-		db: db,
+		db:      db,
+		timeout: timeout,
+		ids:     ids,
 	}
 }
 
 // Create creates a new invoice.
-func (db *Database) Create(i *invoice.Invoice) (*invoice.Invoice, error) {
-	// Map to model.
-	model, err := storageToModel(i)
+func (db *Database) Create(ctx context.Context, i *invoice.Invoice) (*invoice.Invoice, error) {
+	invoices, err := db.CreateBatch(ctx, []*invoice.Invoice{i})
 	if err != nil {
 		return nil, err
 	}
 
-	// Insert into database.
-	err = model.Insert(context.Background(), db.db, boil.Infer())
+	return invoices[0], nil
+}
+
+// CreateBatch creates new invoices in a single transaction, so either all of
+// them are created or none are.
+func (db *Database) CreateBatch(ctx context.Context, invoices []*invoice.Invoice) ([]*invoice.Invoice, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Insert into database, along with the events of the new invoices.
+	tx, err := db.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
+
+	// The invoices are copied, so the IDs of a batch that is rolled back
+	// are not left on them.
+	created := []*invoice.Invoice{}
+	for _, i := range invoices {
+		c := *i
+		if err := db.insert(ctx, tx, &c); err != nil {
+			return nil, err
+		}
 
-	return i, nil
+		created = append(created, &c)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// insert inserts an invoice and its events in the given transaction.
+func (db *Database) insert(ctx context.Context, tx *sql.Tx, i *invoice.Invoice) error {
+	// Map to model.
+	model, err := storageToModel(i)
+	if err != nil {
+		return err
+	}
+
+	// Handle ID.
+	if model.ID == 0 && db.ids != nil {
+		model.ID = db.ids.NewID()
+	}
+
+	if err := model.Insert(ctx, tx, boil.Infer()); err != nil {
+		return err
+	}
+
+	// The ID is read back from the insert when assigned by the database.
+	i.ID = model.ID
+
+	events, err := outbox.InvoiceEvents(nil, i)
+	if err != nil {
+		return err
+	}
+
+	return mysqloutbox.Insert(ctx, tx, events)
 }
 
 // Get gets a set of invoices.
-func (db *Database) Get(params *invoice.GetParams) ([]*invoice.Invoice, error) {
-	var filter []qm.QueryMod
+func (db *Database) Get(ctx context.Context, params *invoice.GetParams) ([]*invoice.Invoice, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
 
 	// Handle get params.
-	if params.UserID != nil {
-		filter = append(filter, qm.Where("user_id=?", params.UserID))
+	filter := getFilter(params)
+
+	// Handle cursor and ordering.
+	//
+	// Invoices are returned newest first. When paging before the cursor,
+	// the order is flipped so the limit applies to the invoices closest to
+	// the cursor, and the results are reversed back afterwards.
+	if params.Cursor != nil {
+		if params.Cursor.Before {
+			filter = append(filter, qm.And("(created_at>? OR (created_at=? AND id>?))", params.Cursor.CreatedAt, params.Cursor.CreatedAt, params.Cursor.ID))
+		} else {
+			filter = append(filter, qm.And("(created_at<? OR (created_at=? AND id<?))", params.Cursor.CreatedAt, params.Cursor.CreatedAt, params.Cursor.ID))
+		}
 	}
 
-	if params.CreatedAt != nil {
-		if params.CreatedAt.StartDate != nil {
-			filter = append(filter, qm.And("created_at>=?", params.CreatedAt.StartDate))
-		}
-		if params.CreatedAt.EndDate != nil {
-			filter = append(filter, qm.And("created_at<=?", params.CreatedAt.EndDate))
-		}
+	if params.Cursor != nil && params.Cursor.Before {
+		filter = append(filter, qm.OrderBy("created_at ASC, id ASC"))
+	} else {
+		filter = append(filter, qm.OrderBy("created_at DESC, id DESC"))
 	}
 
 	filter = append(filter, qm.Offset(int(params.Offset)))
 	filter = append(filter, qm.Limit(int(params.Limit)))
 
 	// Get from database.
-	modelInvoices, err := models.Invoices(filter...).All(context.Background(), db.db)
+	modelInvoices, err := models.Invoices(filter...).All(ctx, db.db)
 	if err != nil {
 		return nil, err
 	}
@@ -80,20 +153,26 @@ func (db *Database) Get(params *invoice.GetParams) ([]*invoice.Invoice, error) {
 		invoices = append(invoices, &i)
 	}
 
+	// Restore newest first ordering.
+	if params.Cursor != nil && params.Cursor.Before {
+		for l, r := 0, len(invoices)-1; l < r; l, r = l+1, r-1 {
+			invoices[l], invoices[r] = invoices[r], invoices[l]
+		}
+	}
+
 	return invoices, nil
 }
 
 // GetCount gets the count of a set of invoices.
-func (db *Database) GetCount(params *invoice.GetParams) (uint, error) {
-	var filter []qm.QueryMod
+func (db *Database) GetCount(ctx context.Context, params *invoice.GetParams) (uint, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
 
 	// Handle get params.
-	if params.UserID != nil {
-		filter = append(filter, qm.Where("user_id=?", params.UserID))
-	}
+	filter := getFilter(params)
 
 	// Get from database.
-	count, err := models.Invoices(filter...).Count(context.Background(), db.db)
+	count, err := models.Invoices(filter...).Count(ctx, db.db)
 	if err != nil {
 		return 0, err
 	}
@@ -102,8 +181,11 @@ func (db *Database) GetCount(params *invoice.GetParams) (uint, error) {
 }
 
 // GetByID gets an invoice by the given ID.
-func (db *Database) GetByID(id uint) (*invoice.Invoice, error) {
-	model, err := models.Invoices(qm.Where("id=?", id)).One(context.Background(), db.db)
+func (db *Database) GetByID(ctx context.Context, id uint) (*invoice.Invoice, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	model, err := models.Invoices(qm.Where("id=?", id)).One(ctx, db.db)
 	if err == sql.ErrNoRows {
 		return nil, invoice.ErrInvoiceNotFound
 	} else if err != nil {
@@ -120,8 +202,11 @@ func (db *Database) GetByID(id uint) (*invoice.Invoice, error) {
 }
 
 // GetByPublicHash gets an invoice by the given public hash.
-func (db *Database) GetByPublicHash(hash string) (*invoice.Invoice, error) {
-	model, err := models.Invoices(qm.Where("public_hash=?", hash)).One(context.Background(), db.db)
+func (db *Database) GetByPublicHash(ctx context.Context, hash string) (*invoice.Invoice, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	model, err := models.Invoices(qm.Where("public_hash=?", hash)).One(ctx, db.db)
 	if err == sql.ErrNoRows {
 		return nil, invoice.ErrInvoiceNotFound
 	} else if err != nil {
@@ -138,25 +223,59 @@ func (db *Database) GetByPublicHash(hash string) (*invoice.Invoice, error) {
 }
 
 // Update updates an invoice.
-func (db *Database) Update(i *invoice.Invoice) (*invoice.Invoice, error) {
+func (db *Database) Update(ctx context.Context, i *invoice.Invoice) (*invoice.Invoice, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
 	// Map to model.
 	model, err := storageToModel(i)
 	if err != nil {
 		return nil, err
 	}
 
-	// Update in database.
-	_, err = model.Update(context.Background(), db.db, boil.Infer())
+	// Update in database, along with the events of the change. The current
+	// status is locked until the commit, so two updates paying the same
+	// invoice record a single paid event.
+	tx, err := db.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
+
+	current, err := models.Invoices(qm.Select("status"), qm.Where("id=?", i.ID), qm.For("UPDATE")).One(ctx, tx)
+	if err == sql.ErrNoRows {
+		return i, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	_, err = model.Update(ctx, tx, boil.Infer())
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := outbox.InvoiceEvents(&invoice.Invoice{Status: current.Status.String()}, i)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mysqloutbox.Insert(ctx, tx, events); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
 	return i, nil
 }
 
 // Delete deletes an invoice.
-func (db *Database) Delete(id uint) error {
-	model, err := models.Invoices(qm.Where("id=?", id)).One(context.Background(), db.db)
+func (db *Database) Delete(ctx context.Context, id uint) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	model, err := models.Invoices(qm.Where("id=?", id)).One(ctx, db.db)
 	if err == sql.ErrNoRows {
 		return invoice.ErrInvoiceNotFound
 	} else if err != nil {
@@ -164,7 +283,7 @@ func (db *Database) Delete(id uint) error {
 	}
 
 	// Delete from database.
-	_, err = model.Delete(context.Background(), db.db)
+	_, err = model.Delete(ctx, db.db)
 	if err != nil {
 		return err
 	}
@@ -172,6 +291,35 @@ func (db *Database) Delete(id uint) error {
 	return nil
 }
 
+// getFilter returns the query mods filtering invoices by the given get
+// params, shared by Get and GetCount so they always count the same invoices.
+func getFilter(params *invoice.GetParams) []qm.QueryMod {
+	var filter []qm.QueryMod
+
+	if params.ID != nil {
+		filter = append(filter, qm.Where("id=?", params.ID))
+	}
+
+	if params.OrganizationID != nil {
+		filter = append(filter, qm.Where("organization_id=?", params.OrganizationID))
+	}
+
+	if params.Status != nil {
+		filter = append(filter, qm.Where("status=?", params.Status))
+	}
+
+	if params.CreatedAt != nil {
+		if params.CreatedAt.StartDate != nil {
+			filter = append(filter, qm.Where("created_at>=?", params.CreatedAt.StartDate))
+		}
+		if params.CreatedAt.EndDate != nil {
+			filter = append(filter, qm.Where("created_at<=?", params.CreatedAt.EndDate))
+		}
+	}
+
+	return filter
+}
+
 // storageToModel handles mapping a storage invoice type to the model invoice
 // type.
 func storageToModel(i *invoice.Invoice) (models.Invoice, error) {
@@ -199,6 +347,7 @@ func storageToModel(i *invoice.Invoice) (models.Invoice, error) {
 
 	return models.Invoice{
 		ID:                 i.ID,
+		OrganizationID:     i.OrganizationID,
 		UserID:             i.UserID,
 		PublicHash:         i.PublicHash,
 		InvoiceNumber:      i.InvoiceNumber,
@@ -254,14 +403,15 @@ func modelToStorage(i *models.Invoice) (invoice.Invoice, error) {
 	}
 
 	return invoice.Invoice{
-		ID:            i.ID,
-		UserID:        i.UserID,
-		PublicHash:    i.PublicHash,
-		InvoiceNumber: i.InvoiceNumber,
-		PONumber:      i.PoNumber,
-		Currency:      i.Currency,
-		DueDate:       i.DueDate,
-		Message:       i.Message,
+		ID:             i.ID,
+		OrganizationID: i.OrganizationID,
+		UserID:         i.UserID,
+		PublicHash:     i.PublicHash,
+		InvoiceNumber:  i.InvoiceNumber,
+		PONumber:       i.PoNumber,
+		Currency:       i.Currency,
+		DueDate:        i.DueDate,
+		Message:        i.Message,
 		BillTo: invoice.BillTo{
 			FirstName:    i.BillToFirstName,
 			LastName:     i.BillToLastName,
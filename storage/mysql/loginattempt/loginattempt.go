@@ -0,0 +1,81 @@
+package loginattempt
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/loginattempt"
+	"dddstructure/storage/mysql/models"
+
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout.
+func New(db *sql.DB, timeout time.Duration) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+	}
+}
+
+// Get gets the failed login attempts for the given key.
+func (db *Database) Get(ctx context.Context, key string) (*loginattempt.LoginAttempt, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	model, err := models.LoginAttempts(qm.Where("attempt_key=?", key)).One(ctx, db.db)
+	if err == sql.ErrNoRows {
+		return nil, loginattempt.ErrLoginAttemptNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Map to login attempt type.
+	a := &loginattempt.LoginAttempt{
+		Key:          model.AttemptKey,
+		Failures:     model.Failures,
+		LastFailedAt: model.LastFailedAt,
+	}
+
+	return a, nil
+}
+
+// RecordFailure adds a failed login attempt for the given key, returning the
+// updated attempts.
+//
+// The count starts over if the last failure was before resetBefore. This is
+// done in a single statement, so failures at the same time are all counted.
+func (db *Database) RecordFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (*loginattempt.LoginAttempt, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	query := `INSERT INTO login_attempts (attempt_key, failures, last_failed_at) VALUES (?, 1, ?)
+		ON DUPLICATE KEY UPDATE
+			failures = IF(last_failed_at < ?, 1, failures + 1),
+			last_failed_at = VALUES(last_failed_at)`
+
+	if _, err := db.db.ExecContext(ctx, query, key, failedAt, resetBefore); err != nil {
+		return nil, err
+	}
+
+	return db.Get(ctx, key)
+}
+
+// Delete deletes the failed login attempts for the given key.
+func (db *Database) Delete(ctx context.Context, key string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	_, err := models.LoginAttempts(qm.Where("attempt_key=?", key)).DeleteAll(ctx, db.db)
+
+	return err
+}
@@ -0,0 +1,68 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"dddstructure/db/migrate"
+)
+
+// lockName defines the name of the MySQL lock held while migrating, so only
+// one deploy migrates the database at a time.
+const lockName = "dddstructure_migrate"
+
+// createTable creates the table recording the applied migrations.
+const createTable = "CREATE TABLE IF NOT EXISTS `schema_migrations` (" +
+	"`version` int UNSIGNED NOT NULL, " +
+	"`name` varchar(255) NOT NULL, " +
+	"`applied_at` datetime NOT NULL, " +
+	"PRIMARY KEY (`version`)" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci"
+
+// Driver defines the MySQL driver, which implements the migrate.Driver
+// interface.
+type Driver struct{}
+
+// New creates a new migrator of the given MySQL database, as migrate.New
+// does.
+func New(db *sql.DB, migrations []*migrate.Migration, lockTimeout time.Duration, l *slog.Logger) *migrate.Migrator {
+	return migrate.New(db, Driver{}, migrations, lockTimeout, l)
+}
+
+// Lock takes the MySQL lock, which belongs to the connection, so it is also
+// released if the process dies.
+func (Driver) Lock(ctx context.Context, conn *sql.Conn, timeout time.Duration) error {
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(timeout/time.Second)).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return migrate.ErrLocked
+	}
+
+	return nil
+}
+
+// Unlock releases the MySQL lock.
+func (Driver) Unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", lockName)
+	return err
+}
+
+// CreateTable returns the statement creating the schema_migrations table.
+func (Driver) CreateTable() string {
+	return createTable
+}
+
+// Placeholder returns "?", as MySQL placeholders are not numbered.
+func (Driver) Placeholder(n int) string {
+	return "?"
+}
+
+// Transactional returns false, as MySQL commits schema changes as they are
+// made.
+func (Driver) Transactional() bool {
+	return false
+}
@@ -4,11 +4,29 @@
 package models
 
 var TableNames = struct {
-	Invoices     string
-	Transactions string
-	Users        string
+	Invoices                string
+	LoginAttempts           string
+	OrganizationInvitations string
+	OrganizationMembers     string
+	Organizations           string
+	OutboxEvents            string
+	RecoveryCodes           string
+	RefreshTokens           string
+	Sessions                string
+	Transactions            string
+	UserTokens              string
+	Users                   string
 }{
-	Invoices:     "invoices",
-	Transactions: "transactions",
-	Users:        "users",
+	Invoices:                "invoices",
+	LoginAttempts:           "login_attempts",
+	OrganizationInvitations: "organization_invitations",
+	OrganizationMembers:     "organization_members",
+	Organizations:           "organizations",
+	OutboxEvents:            "outbox_events",
+	RecoveryCodes:           "recovery_codes",
+	RefreshTokens:           "refresh_tokens",
+	Sessions:                "sessions",
+	Transactions:            "transactions",
+	UserTokens:              "user_tokens",
+	Users:                   "users",
 }
@@ -95,6 +95,102 @@ func (e InvoicesStatus) Ordinal() int {
 	}
 }
 
+type OrganizationInvitationsRole string
+
+// Enum values for OrganizationInvitationsRole
+const (
+	OrganizationInvitationsRoleOwner      OrganizationInvitationsRole = "owner"
+	OrganizationInvitationsRoleAdmin      OrganizationInvitationsRole = "admin"
+	OrganizationInvitationsRoleAccountant OrganizationInvitationsRole = "accountant"
+	OrganizationInvitationsRoleReadOnly   OrganizationInvitationsRole = "read_only"
+)
+
+func AllOrganizationInvitationsRole() []OrganizationInvitationsRole {
+	return []OrganizationInvitationsRole{
+		OrganizationInvitationsRoleOwner,
+		OrganizationInvitationsRoleAdmin,
+		OrganizationInvitationsRoleAccountant,
+		OrganizationInvitationsRoleReadOnly,
+	}
+}
+
+func (e OrganizationInvitationsRole) IsValid() error {
+	switch e {
+	case OrganizationInvitationsRoleOwner, OrganizationInvitationsRoleAdmin, OrganizationInvitationsRoleAccountant, OrganizationInvitationsRoleReadOnly:
+		return nil
+	default:
+		return errors.New("enum is not valid")
+	}
+}
+
+func (e OrganizationInvitationsRole) String() string {
+	return string(e)
+}
+
+func (e OrganizationInvitationsRole) Ordinal() int {
+	switch e {
+	case OrganizationInvitationsRoleOwner:
+		return 0
+	case OrganizationInvitationsRoleAdmin:
+		return 1
+	case OrganizationInvitationsRoleAccountant:
+		return 2
+	case OrganizationInvitationsRoleReadOnly:
+		return 3
+
+	default:
+		panic(errors.New("enum is not valid"))
+	}
+}
+
+type OrganizationMembersRole string
+
+// Enum values for OrganizationMembersRole
+const (
+	OrganizationMembersRoleOwner      OrganizationMembersRole = "owner"
+	OrganizationMembersRoleAdmin      OrganizationMembersRole = "admin"
+	OrganizationMembersRoleAccountant OrganizationMembersRole = "accountant"
+	OrganizationMembersRoleReadOnly   OrganizationMembersRole = "read_only"
+)
+
+func AllOrganizationMembersRole() []OrganizationMembersRole {
+	return []OrganizationMembersRole{
+		OrganizationMembersRoleOwner,
+		OrganizationMembersRoleAdmin,
+		OrganizationMembersRoleAccountant,
+		OrganizationMembersRoleReadOnly,
+	}
+}
+
+func (e OrganizationMembersRole) IsValid() error {
+	switch e {
+	case OrganizationMembersRoleOwner, OrganizationMembersRoleAdmin, OrganizationMembersRoleAccountant, OrganizationMembersRoleReadOnly:
+		return nil
+	default:
+		return errors.New("enum is not valid")
+	}
+}
+
+func (e OrganizationMembersRole) String() string {
+	return string(e)
+}
+
+func (e OrganizationMembersRole) Ordinal() int {
+	switch e {
+	case OrganizationMembersRoleOwner:
+		return 0
+	case OrganizationMembersRoleAdmin:
+		return 1
+	case OrganizationMembersRoleAccountant:
+		return 2
+	case OrganizationMembersRoleReadOnly:
+		return 3
+
+	default:
+		panic(errors.New("enum is not valid"))
+	}
+}
+
 type TransactionsType string
 
 // Enum values for TransactionsType
@@ -186,3 +282,47 @@ func (e TransactionsStatus) Ordinal() int {
 		panic(errors.New("enum is not valid"))
 	}
 }
+
+type UserTokensType string
+
+// Enum values for UserTokensType
+const (
+	UserTokensTypePasswordReset      UserTokensType = "password_reset"
+	UserTokensTypeEmailVerification  UserTokensType = "email_verification"
+	UserTokensTypeTwoFactorChallenge UserTokensType = "two_factor_challenge"
+)
+
+func AllUserTokensType() []UserTokensType {
+	return []UserTokensType{
+		UserTokensTypePasswordReset,
+		UserTokensTypeEmailVerification,
+		UserTokensTypeTwoFactorChallenge,
+	}
+}
+
+func (e UserTokensType) IsValid() error {
+	switch e {
+	case UserTokensTypePasswordReset, UserTokensTypeEmailVerification, UserTokensTypeTwoFactorChallenge:
+		return nil
+	default:
+		return errors.New("enum is not valid")
+	}
+}
+
+func (e UserTokensType) String() string {
+	return string(e)
+}
+
+func (e UserTokensType) Ordinal() int {
+	switch e {
+	case UserTokensTypePasswordReset:
+		return 0
+	case UserTokensTypeEmailVerification:
+		return 1
+	case UserTokensTypeTwoFactorChallenge:
+		return 2
+
+	default:
+		panic(errors.New("enum is not valid"))
+	}
+}
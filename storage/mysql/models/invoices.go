@@ -25,6 +25,7 @@ import (
 // Invoice is an object representing the database table.
 type Invoice struct {
 	ID                 uint           `boil:"id" json:"id" toml:"id" yaml:"id"`
+	OrganizationID     uint           `boil:"organization_id" json:"organization_id" toml:"organization_id" yaml:"organization_id"`
 	UserID             uint           `boil:"user_id" json:"user_id" toml:"user_id" yaml:"user_id"`
 	PublicHash         string         `boil:"public_hash" json:"public_hash" toml:"public_hash" yaml:"public_hash"`
 	InvoiceNumber      string         `boil:"invoice_number" json:"invoice_number" toml:"invoice_number" yaml:"invoice_number"`
@@ -68,6 +69,7 @@ type Invoice struct {
 
 var InvoiceColumns = struct {
 	ID                 string
+	OrganizationID     string
 	UserID             string
 	PublicHash         string
 	InvoiceNumber      string
@@ -106,6 +108,7 @@ var InvoiceColumns = struct {
 	CreatedAt          string
 }{
 	ID:                 "id",
+	OrganizationID:     "organization_id",
 	UserID:             "user_id",
 	PublicHash:         "public_hash",
 	InvoiceNumber:      "invoice_number",
@@ -146,6 +149,7 @@ var InvoiceColumns = struct {
 
 var InvoiceTableColumns = struct {
 	ID                 string
+	OrganizationID     string
 	UserID             string
 	PublicHash         string
 	InvoiceNumber      string
@@ -184,6 +188,7 @@ var InvoiceTableColumns = struct {
 	CreatedAt          string
 }{
 	ID:                 "invoices.id",
+	OrganizationID:     "invoices.organization_id",
 	UserID:             "invoices.user_id",
 	PublicHash:         "invoices.public_hash",
 	InvoiceNumber:      "invoices.invoice_number",
@@ -354,6 +359,7 @@ func (w whereHelperInvoicesStatus) NIN(slice []InvoicesStatus) qm.QueryMod {
 
 var InvoiceWhere = struct {
 	ID                 whereHelperuint
+	OrganizationID     whereHelperuint
 	UserID             whereHelperuint
 	PublicHash         whereHelperstring
 	InvoiceNumber      whereHelperstring
@@ -392,6 +398,7 @@ var InvoiceWhere = struct {
 	CreatedAt          whereHelpertime_Time
 }{
 	ID:                 whereHelperuint{field: "`invoices`.`id`"},
+	OrganizationID:     whereHelperuint{field: "`invoices`.`organization_id`"},
 	UserID:             whereHelperuint{field: "`invoices`.`user_id`"},
 	PublicHash:         whereHelperstring{field: "`invoices`.`public_hash`"},
 	InvoiceNumber:      whereHelperstring{field: "`invoices`.`invoice_number`"},
@@ -447,9 +454,9 @@ func (*invoiceR) NewStruct() *invoiceR {
 type invoiceL struct{}
 
 var (
-	invoiceAllColumns            = []string{"id", "user_id", "public_hash", "invoice_number", "po_number", "currency", "due_date", "message", "bill_to_first_name", "bill_to_last_name", "bill_to_company", "bill_to_address_line_1", "bill_to_address_line_2", "bill_to_city", "bill_to_state", "bill_to_postal_code", "bill_to_country", "bill_to_email", "bill_to_phone", "pay_to_first_name", "pay_to_last_name", "pay_to_company", "pay_to_address_line_1", "pay_to_address_line_2", "pay_to_city", "pay_to_state", "pay_to_postal_code", "pay_to_country", "pay_to_email", "pay_to_phone", "line_items", "payment_methods", "tax_rate", "amount_due", "amount_paid", "status", "created_at"}
-	invoiceColumnsWithoutDefault = []string{"id", "user_id", "public_hash", "invoice_number", "po_number", "currency", "due_date", "message", "bill_to_first_name", "bill_to_last_name", "bill_to_company", "bill_to_address_line_1", "bill_to_address_line_2", "bill_to_city", "bill_to_state", "bill_to_postal_code", "bill_to_country", "bill_to_email", "bill_to_phone", "pay_to_first_name", "pay_to_last_name", "pay_to_company", "pay_to_address_line_1", "pay_to_address_line_2", "pay_to_city", "pay_to_state", "pay_to_postal_code", "pay_to_country", "pay_to_email", "pay_to_phone", "line_items", "payment_methods", "tax_rate", "amount_due", "amount_paid", "status", "created_at"}
-	invoiceColumnsWithDefault    = []string{}
+	invoiceAllColumns            = []string{"id", "organization_id", "user_id", "public_hash", "invoice_number", "po_number", "currency", "due_date", "message", "bill_to_first_name", "bill_to_last_name", "bill_to_company", "bill_to_address_line_1", "bill_to_address_line_2", "bill_to_city", "bill_to_state", "bill_to_postal_code", "bill_to_country", "bill_to_email", "bill_to_phone", "pay_to_first_name", "pay_to_last_name", "pay_to_company", "pay_to_address_line_1", "pay_to_address_line_2", "pay_to_city", "pay_to_state", "pay_to_postal_code", "pay_to_country", "pay_to_email", "pay_to_phone", "line_items", "payment_methods", "tax_rate", "amount_due", "amount_paid", "status", "created_at"}
+	invoiceColumnsWithoutDefault = []string{"organization_id", "user_id", "public_hash", "invoice_number", "po_number", "currency", "due_date", "message", "bill_to_first_name", "bill_to_last_name", "bill_to_company", "bill_to_address_line_1", "bill_to_address_line_2", "bill_to_city", "bill_to_state", "bill_to_postal_code", "bill_to_country", "bill_to_email", "bill_to_phone", "pay_to_first_name", "pay_to_last_name", "pay_to_company", "pay_to_address_line_1", "pay_to_address_line_2", "pay_to_city", "pay_to_state", "pay_to_postal_code", "pay_to_country", "pay_to_email", "pay_to_phone", "line_items", "payment_methods", "tax_rate", "amount_due", "amount_paid", "status", "created_at"}
+	invoiceColumnsWithDefault    = []string{"id"}
 	invoicePrimaryKeyColumns     = []string{"id"}
 	invoiceGeneratedColumns      = []string{}
 )
@@ -866,18 +873,29 @@ func (o *Invoice) Insert(ctx context.Context, exec boil.ContextExecutor, columns
 		fmt.Fprintln(writer, cache.query)
 		fmt.Fprintln(writer, vals)
 	}
-	_, err = exec.ExecContext(ctx, cache.query, vals...)
+	result, err := exec.ExecContext(ctx, cache.query, vals...)
 
 	if err != nil {
 		return errors.Wrap(err, "models: unable to insert into invoices")
 	}
 
+	var lastID int64
 	var identifierCols []interface{}
 
 	if len(cache.retMapping) == 0 {
 		goto CacheNoHooks
 	}
 
+	lastID, err = result.LastInsertId()
+	if err != nil {
+		return ErrSyncFail
+	}
+
+	o.ID = uint(lastID)
+	if lastID != 0 && len(cache.retMapping) == 1 && cache.retMapping[0] == invoiceMapping["id"] {
+		goto CacheNoHooks
+	}
+
 	identifierCols = []interface{}{
 		o.ID,
 	}
@@ -1137,12 +1155,13 @@ func (o *Invoice) Upsert(ctx context.Context, exec boil.ContextExecutor, updateC
 		fmt.Fprintln(writer, cache.query)
 		fmt.Fprintln(writer, vals)
 	}
-	_, err = exec.ExecContext(ctx, cache.query, vals...)
+	result, err := exec.ExecContext(ctx, cache.query, vals...)
 
 	if err != nil {
 		return errors.Wrap(err, "models: unable to upsert for invoices")
 	}
 
+	var lastID int64
 	var uniqueMap []uint64
 	var nzUniqueCols []interface{}
 
@@ -1150,6 +1169,16 @@ func (o *Invoice) Upsert(ctx context.Context, exec boil.ContextExecutor, updateC
 		goto CacheNoHooks
 	}
 
+	lastID, err = result.LastInsertId()
+	if err != nil {
+		return ErrSyncFail
+	}
+
+	o.ID = uint(lastID)
+	if lastID != 0 && len(cache.retMapping) == 1 && cache.retMapping[0] == invoiceMapping["id"] {
+		goto CacheNoHooks
+	}
+
 	uniqueMap, err = queries.BindMapping(invoiceType, invoiceMapping, nzUniques)
 	if err != nil {
 		return errors.Wrap(err, "models: unable to retrieve unique values for invoices")
@@ -0,0 +1,968 @@
+// Code generated by SQLBoiler 4.17.1 (https://github.com/volatiletech/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/friendsofgo/errors"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+	"github.com/volatiletech/sqlboiler/v4/queries/qmhelper"
+	"github.com/volatiletech/strmangle"
+)
+
+// LoginAttempt is an object representing the database table.
+type LoginAttempt struct {
+	AttemptKey   string    `boil:"attempt_key" json:"attempt_key" toml:"attempt_key" yaml:"attempt_key"`
+	Failures     uint      `boil:"failures" json:"failures" toml:"failures" yaml:"failures"`
+	LastFailedAt time.Time `boil:"last_failed_at" json:"last_failed_at" toml:"last_failed_at" yaml:"last_failed_at"`
+
+	R *loginAttemptR `boil:"-" json:"-" toml:"-" yaml:"-"`
+	L loginAttemptL  `boil:"-" json:"-" toml:"-" yaml:"-"`
+}
+
+var LoginAttemptColumns = struct {
+	AttemptKey   string
+	Failures     string
+	LastFailedAt string
+}{
+	AttemptKey:   "attempt_key",
+	Failures:     "failures",
+	LastFailedAt: "last_failed_at",
+}
+
+var LoginAttemptTableColumns = struct {
+	AttemptKey   string
+	Failures     string
+	LastFailedAt string
+}{
+	AttemptKey:   "login_attempts.attempt_key",
+	Failures:     "login_attempts.failures",
+	LastFailedAt: "login_attempts.last_failed_at",
+}
+
+// Generated where
+
+var LoginAttemptWhere = struct {
+	AttemptKey   whereHelperstring
+	Failures     whereHelperuint
+	LastFailedAt whereHelpertime_Time
+}{
+	AttemptKey:   whereHelperstring{field: "`login_attempts`.`attempt_key`"},
+	Failures:     whereHelperuint{field: "`login_attempts`.`failures`"},
+	LastFailedAt: whereHelpertime_Time{field: "`login_attempts`.`last_failed_at`"},
+}
+
+// LoginAttemptRels is where relationship names are stored.
+var LoginAttemptRels = struct {
+}{}
+
+// loginAttemptR is where relationships are stored.
+type loginAttemptR struct {
+}
+
+// NewStruct creates a new relationship struct
+func (*loginAttemptR) NewStruct() *loginAttemptR {
+	return &loginAttemptR{}
+}
+
+// loginAttemptL is where Load methods for each relationship are stored.
+type loginAttemptL struct{}
+
+var (
+	loginAttemptAllColumns            = []string{"attempt_key", "failures", "last_failed_at"}
+	loginAttemptColumnsWithoutDefault = []string{"attempt_key", "failures", "last_failed_at"}
+	loginAttemptColumnsWithDefault    = []string{}
+	loginAttemptPrimaryKeyColumns     = []string{"attempt_key"}
+	loginAttemptGeneratedColumns      = []string{}
+)
+
+type (
+	// LoginAttemptSlice is an alias for a slice of pointers to LoginAttempt.
+	// This should almost always be used instead of []LoginAttempt.
+	LoginAttemptSlice []*LoginAttempt
+	// LoginAttemptHook is the signature for custom LoginAttempt hook methods
+	LoginAttemptHook func(context.Context, boil.ContextExecutor, *LoginAttempt) error
+
+	loginAttemptQuery struct {
+		*queries.Query
+	}
+)
+
+// Cache for insert, update and upsert
+var (
+	loginAttemptType                 = reflect.TypeOf(&LoginAttempt{})
+	loginAttemptMapping              = queries.MakeStructMapping(loginAttemptType)
+	loginAttemptPrimaryKeyMapping, _ = queries.BindMapping(loginAttemptType, loginAttemptMapping, loginAttemptPrimaryKeyColumns)
+	loginAttemptInsertCacheMut       sync.RWMutex
+	loginAttemptInsertCache          = make(map[string]insertCache)
+	loginAttemptUpdateCacheMut       sync.RWMutex
+	loginAttemptUpdateCache          = make(map[string]updateCache)
+	loginAttemptUpsertCacheMut       sync.RWMutex
+	loginAttemptUpsertCache          = make(map[string]insertCache)
+)
+
+var (
+	// Force time package dependency for automated UpdatedAt/CreatedAt.
+	_ = time.Second
+	// Force qmhelper dependency for where clause generation (which doesn't
+	// always happen)
+	_ = qmhelper.Where
+)
+
+var loginAttemptAfterSelectMu sync.Mutex
+var loginAttemptAfterSelectHooks []LoginAttemptHook
+
+var loginAttemptBeforeInsertMu sync.Mutex
+var loginAttemptBeforeInsertHooks []LoginAttemptHook
+var loginAttemptAfterInsertMu sync.Mutex
+var loginAttemptAfterInsertHooks []LoginAttemptHook
+
+var loginAttemptBeforeUpdateMu sync.Mutex
+var loginAttemptBeforeUpdateHooks []LoginAttemptHook
+var loginAttemptAfterUpdateMu sync.Mutex
+var loginAttemptAfterUpdateHooks []LoginAttemptHook
+
+var loginAttemptBeforeDeleteMu sync.Mutex
+var loginAttemptBeforeDeleteHooks []LoginAttemptHook
+var loginAttemptAfterDeleteMu sync.Mutex
+var loginAttemptAfterDeleteHooks []LoginAttemptHook
+
+var loginAttemptBeforeUpsertMu sync.Mutex
+var loginAttemptBeforeUpsertHooks []LoginAttemptHook
+var loginAttemptAfterUpsertMu sync.Mutex
+var loginAttemptAfterUpsertHooks []LoginAttemptHook
+
+// doAfterSelectHooks executes all "after Select" hooks.
+func (o *LoginAttempt) doAfterSelectHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginAttemptAfterSelectHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeInsertHooks executes all "before insert" hooks.
+func (o *LoginAttempt) doBeforeInsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginAttemptBeforeInsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterInsertHooks executes all "after Insert" hooks.
+func (o *LoginAttempt) doAfterInsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginAttemptAfterInsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpdateHooks executes all "before Update" hooks.
+func (o *LoginAttempt) doBeforeUpdateHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginAttemptBeforeUpdateHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpdateHooks executes all "after Update" hooks.
+func (o *LoginAttempt) doAfterUpdateHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginAttemptAfterUpdateHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeDeleteHooks executes all "before Delete" hooks.
+func (o *LoginAttempt) doBeforeDeleteHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginAttemptBeforeDeleteHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterDeleteHooks executes all "after Delete" hooks.
+func (o *LoginAttempt) doAfterDeleteHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginAttemptAfterDeleteHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpsertHooks executes all "before Upsert" hooks.
+func (o *LoginAttempt) doBeforeUpsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginAttemptBeforeUpsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpsertHooks executes all "after Upsert" hooks.
+func (o *LoginAttempt) doAfterUpsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range loginAttemptAfterUpsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddLoginAttemptHook registers your hook function for all future operations.
+func AddLoginAttemptHook(hookPoint boil.HookPoint, loginAttemptHook LoginAttemptHook) {
+	switch hookPoint {
+	case boil.AfterSelectHook:
+		loginAttemptAfterSelectMu.Lock()
+		loginAttemptAfterSelectHooks = append(loginAttemptAfterSelectHooks, loginAttemptHook)
+		loginAttemptAfterSelectMu.Unlock()
+	case boil.BeforeInsertHook:
+		loginAttemptBeforeInsertMu.Lock()
+		loginAttemptBeforeInsertHooks = append(loginAttemptBeforeInsertHooks, loginAttemptHook)
+		loginAttemptBeforeInsertMu.Unlock()
+	case boil.AfterInsertHook:
+		loginAttemptAfterInsertMu.Lock()
+		loginAttemptAfterInsertHooks = append(loginAttemptAfterInsertHooks, loginAttemptHook)
+		loginAttemptAfterInsertMu.Unlock()
+	case boil.BeforeUpdateHook:
+		loginAttemptBeforeUpdateMu.Lock()
+		loginAttemptBeforeUpdateHooks = append(loginAttemptBeforeUpdateHooks, loginAttemptHook)
+		loginAttemptBeforeUpdateMu.Unlock()
+	case boil.AfterUpdateHook:
+		loginAttemptAfterUpdateMu.Lock()
+		loginAttemptAfterUpdateHooks = append(loginAttemptAfterUpdateHooks, loginAttemptHook)
+		loginAttemptAfterUpdateMu.Unlock()
+	case boil.BeforeDeleteHook:
+		loginAttemptBeforeDeleteMu.Lock()
+		loginAttemptBeforeDeleteHooks = append(loginAttemptBeforeDeleteHooks, loginAttemptHook)
+		loginAttemptBeforeDeleteMu.Unlock()
+	case boil.AfterDeleteHook:
+		loginAttemptAfterDeleteMu.Lock()
+		loginAttemptAfterDeleteHooks = append(loginAttemptAfterDeleteHooks, loginAttemptHook)
+		loginAttemptAfterDeleteMu.Unlock()
+	case boil.BeforeUpsertHook:
+		loginAttemptBeforeUpsertMu.Lock()
+		loginAttemptBeforeUpsertHooks = append(loginAttemptBeforeUpsertHooks, loginAttemptHook)
+		loginAttemptBeforeUpsertMu.Unlock()
+	case boil.AfterUpsertHook:
+		loginAttemptAfterUpsertMu.Lock()
+		loginAttemptAfterUpsertHooks = append(loginAttemptAfterUpsertHooks, loginAttemptHook)
+		loginAttemptAfterUpsertMu.Unlock()
+	}
+}
+
+// One returns a single loginAttempt record from the query.
+func (q loginAttemptQuery) One(ctx context.Context, exec boil.ContextExecutor) (*LoginAttempt, error) {
+	o := &LoginAttempt{}
+
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Bind(ctx, exec, o)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: failed to execute a one query for login_attempts")
+	}
+
+	if err := o.doAfterSelectHooks(ctx, exec); err != nil {
+		return o, err
+	}
+
+	return o, nil
+}
+
+// All returns all LoginAttempt records from the query.
+func (q loginAttemptQuery) All(ctx context.Context, exec boil.ContextExecutor) (LoginAttemptSlice, error) {
+	var o []*LoginAttempt
+
+	err := q.Bind(ctx, exec, &o)
+	if err != nil {
+		return nil, errors.Wrap(err, "models: failed to assign all query results to LoginAttempt slice")
+	}
+
+	if len(loginAttemptAfterSelectHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterSelectHooks(ctx, exec); err != nil {
+				return o, err
+			}
+		}
+	}
+
+	return o, nil
+}
+
+// Count returns the count of all LoginAttempt records in the query.
+func (q loginAttemptQuery) Count(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+
+	err := q.Query.QueryRowContext(ctx, exec).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to count login_attempts rows")
+	}
+
+	return count, nil
+}
+
+// Exists checks if the row exists in the table.
+func (q loginAttemptQuery) Exists(ctx context.Context, exec boil.ContextExecutor) (bool, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Query.QueryRowContext(ctx, exec).Scan(&count)
+	if err != nil {
+		return false, errors.Wrap(err, "models: failed to check if login_attempts exists")
+	}
+
+	return count > 0, nil
+}
+
+// LoginAttempts retrieves all the records using an executor.
+func LoginAttempts(mods ...qm.QueryMod) loginAttemptQuery {
+	mods = append(mods, qm.From("`login_attempts`"))
+	q := NewQuery(mods...)
+	if len(queries.GetSelect(q)) == 0 {
+		queries.SetSelect(q, []string{"`login_attempts`.*"})
+	}
+
+	return loginAttemptQuery{q}
+}
+
+// FindLoginAttempt retrieves a single record by ID with an executor.
+// If selectCols is empty Find will return all columns.
+func FindLoginAttempt(ctx context.Context, exec boil.ContextExecutor, attemptKey string, selectCols ...string) (*LoginAttempt, error) {
+	loginAttemptObj := &LoginAttempt{}
+
+	sel := "*"
+	if len(selectCols) > 0 {
+		sel = strings.Join(strmangle.IdentQuoteSlice(dialect.LQ, dialect.RQ, selectCols), ",")
+	}
+	query := fmt.Sprintf(
+		"select %s from `login_attempts` where `attempt_key`=?", sel,
+	)
+
+	q := queries.Raw(query, attemptKey)
+
+	err := q.Bind(ctx, exec, loginAttemptObj)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: unable to select from login_attempts")
+	}
+
+	if err = loginAttemptObj.doAfterSelectHooks(ctx, exec); err != nil {
+		return loginAttemptObj, err
+	}
+
+	return loginAttemptObj, nil
+}
+
+// Insert a single record using an executor.
+// See boil.Columns.InsertColumnSet documentation to understand column list inference for inserts.
+func (o *LoginAttempt) Insert(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no login_attempts provided for insertion")
+	}
+
+	var err error
+
+	if err := o.doBeforeInsertHooks(ctx, exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(loginAttemptColumnsWithDefault, o)
+
+	key := makeCacheKey(columns, nzDefaults)
+	loginAttemptInsertCacheMut.RLock()
+	cache, cached := loginAttemptInsertCache[key]
+	loginAttemptInsertCacheMut.RUnlock()
+
+	if !cached {
+		wl, returnColumns := columns.InsertColumnSet(
+			loginAttemptAllColumns,
+			loginAttemptColumnsWithDefault,
+			loginAttemptColumnsWithoutDefault,
+			nzDefaults,
+		)
+
+		cache.valueMapping, err = queries.BindMapping(loginAttemptType, loginAttemptMapping, wl)
+		if err != nil {
+			return err
+		}
+		cache.retMapping, err = queries.BindMapping(loginAttemptType, loginAttemptMapping, returnColumns)
+		if err != nil {
+			return err
+		}
+		if len(wl) != 0 {
+			cache.query = fmt.Sprintf("INSERT INTO `login_attempts` (`%s`) %%sVALUES (%s)%%s", strings.Join(wl, "`,`"), strmangle.Placeholders(dialect.UseIndexPlaceholders, len(wl), 1, 1))
+		} else {
+			cache.query = "INSERT INTO `login_attempts` () VALUES ()%s%s"
+		}
+
+		var queryOutput, queryReturning string
+
+		if len(cache.retMapping) != 0 {
+			cache.retQuery = fmt.Sprintf("SELECT `%s` FROM `login_attempts` WHERE %s", strings.Join(returnColumns, "`,`"), strmangle.WhereClause("`", "`", 0, loginAttemptPrimaryKeyColumns))
+		}
+
+		cache.query = fmt.Sprintf(cache.query, queryOutput, queryReturning)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, vals)
+	}
+	_, err = exec.ExecContext(ctx, cache.query, vals...)
+
+	if err != nil {
+		return errors.Wrap(err, "models: unable to insert into login_attempts")
+	}
+
+	var identifierCols []interface{}
+
+	if len(cache.retMapping) == 0 {
+		goto CacheNoHooks
+	}
+
+	identifierCols = []interface{}{
+		o.AttemptKey,
+	}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.retQuery)
+		fmt.Fprintln(writer, identifierCols...)
+	}
+	err = exec.QueryRowContext(ctx, cache.retQuery, identifierCols...).Scan(queries.PtrsFromMapping(value, cache.retMapping)...)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to populate default values for login_attempts")
+	}
+
+CacheNoHooks:
+	if !cached {
+		loginAttemptInsertCacheMut.Lock()
+		loginAttemptInsertCache[key] = cache
+		loginAttemptInsertCacheMut.Unlock()
+	}
+
+	return o.doAfterInsertHooks(ctx, exec)
+}
+
+// Update uses an executor to update the LoginAttempt.
+// See boil.Columns.UpdateColumnSet documentation to understand column list inference for updates.
+// Update does not automatically update the record in case of default values. Use .Reload() to refresh the records.
+func (o *LoginAttempt) Update(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) (int64, error) {
+	var err error
+	if err = o.doBeforeUpdateHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+	key := makeCacheKey(columns, nil)
+	loginAttemptUpdateCacheMut.RLock()
+	cache, cached := loginAttemptUpdateCache[key]
+	loginAttemptUpdateCacheMut.RUnlock()
+
+	if !cached {
+		wl := columns.UpdateColumnSet(
+			loginAttemptAllColumns,
+			loginAttemptPrimaryKeyColumns,
+		)
+
+		if !columns.IsWhitelist() {
+			wl = strmangle.SetComplement(wl, []string{"created_at"})
+		}
+		if len(wl) == 0 {
+			return 0, errors.New("models: unable to update login_attempts, could not build whitelist")
+		}
+
+		cache.query = fmt.Sprintf("UPDATE `login_attempts` SET %s WHERE %s",
+			strmangle.SetParamNames("`", "`", 0, wl),
+			strmangle.WhereClause("`", "`", 0, loginAttemptPrimaryKeyColumns),
+		)
+		cache.valueMapping, err = queries.BindMapping(loginAttemptType, loginAttemptMapping, append(wl, loginAttemptPrimaryKeyColumns...))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	values := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), cache.valueMapping)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, values)
+	}
+	var result sql.Result
+	result, err = exec.ExecContext(ctx, cache.query, values...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update login_attempts row")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by update for login_attempts")
+	}
+
+	if !cached {
+		loginAttemptUpdateCacheMut.Lock()
+		loginAttemptUpdateCache[key] = cache
+		loginAttemptUpdateCacheMut.Unlock()
+	}
+
+	return rowsAff, o.doAfterUpdateHooks(ctx, exec)
+}
+
+// UpdateAll updates all rows with the specified column values.
+func (q loginAttemptQuery) UpdateAll(ctx context.Context, exec boil.ContextExecutor, cols M) (int64, error) {
+	queries.SetUpdate(q.Query, cols)
+
+	result, err := q.Query.ExecContext(ctx, exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all for login_attempts")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected for login_attempts")
+	}
+
+	return rowsAff, nil
+}
+
+// UpdateAll updates all rows with the specified column values, using an executor.
+func (o LoginAttemptSlice) UpdateAll(ctx context.Context, exec boil.ContextExecutor, cols M) (int64, error) {
+	ln := int64(len(o))
+	if ln == 0 {
+		return 0, nil
+	}
+
+	if len(cols) == 0 {
+		return 0, errors.New("models: update all requires at least one column argument")
+	}
+
+	colNames := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+
+	i := 0
+	for name, value := range cols {
+		colNames[i] = name
+		args[i] = value
+		i++
+	}
+
+	// Append all of the primary key values for each column
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), loginAttemptPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := fmt.Sprintf("UPDATE `login_attempts` SET %s WHERE %s",
+		strmangle.SetParamNames("`", "`", 0, colNames),
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 0, loginAttemptPrimaryKeyColumns, len(o)))
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args...)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all in loginAttempt slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected all in update all loginAttempt")
+	}
+	return rowsAff, nil
+}
+
+var mySQLLoginAttemptUniqueColumns = []string{
+	"attempt_key",
+}
+
+// Upsert attempts an insert using an executor, and does an update or ignore on conflict.
+// See boil.Columns documentation for how to properly use updateColumns and insertColumns.
+func (o *LoginAttempt) Upsert(ctx context.Context, exec boil.ContextExecutor, updateColumns, insertColumns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no login_attempts provided for upsert")
+	}
+
+	if err := o.doBeforeUpsertHooks(ctx, exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(loginAttemptColumnsWithDefault, o)
+	nzUniques := queries.NonZeroDefaultSet(mySQLLoginAttemptUniqueColumns, o)
+
+	if len(nzUniques) == 0 {
+		return errors.New("cannot upsert with a table that cannot conflict on a unique column")
+	}
+
+	// Build cache key in-line uglily - mysql vs psql problems
+	buf := strmangle.GetBuffer()
+	buf.WriteString(strconv.Itoa(updateColumns.Kind))
+	for _, c := range updateColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(insertColumns.Kind))
+	for _, c := range insertColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	for _, c := range nzDefaults {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	for _, c := range nzUniques {
+		buf.WriteString(c)
+	}
+	key := buf.String()
+	strmangle.PutBuffer(buf)
+
+	loginAttemptUpsertCacheMut.RLock()
+	cache, cached := loginAttemptUpsertCache[key]
+	loginAttemptUpsertCacheMut.RUnlock()
+
+	var err error
+
+	if !cached {
+		insert, _ := insertColumns.InsertColumnSet(
+			loginAttemptAllColumns,
+			loginAttemptColumnsWithDefault,
+			loginAttemptColumnsWithoutDefault,
+			nzDefaults,
+		)
+
+		update := updateColumns.UpdateColumnSet(
+			loginAttemptAllColumns,
+			loginAttemptPrimaryKeyColumns,
+		)
+
+		if !updateColumns.IsNone() && len(update) == 0 {
+			return errors.New("models: unable to upsert login_attempts, could not build update column list")
+		}
+
+		ret := strmangle.SetComplement(loginAttemptAllColumns, strmangle.SetIntersect(insert, update))
+
+		cache.query = buildUpsertQueryMySQL(dialect, "`login_attempts`", update, insert)
+		cache.retQuery = fmt.Sprintf(
+			"SELECT %s FROM `login_attempts` WHERE %s",
+			strings.Join(strmangle.IdentQuoteSlice(dialect.LQ, dialect.RQ, ret), ","),
+			strmangle.WhereClause("`", "`", 0, nzUniques),
+		)
+
+		cache.valueMapping, err = queries.BindMapping(loginAttemptType, loginAttemptMapping, insert)
+		if err != nil {
+			return err
+		}
+		if len(ret) != 0 {
+			cache.retMapping, err = queries.BindMapping(loginAttemptType, loginAttemptMapping, ret)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+	var returns []interface{}
+	if len(cache.retMapping) != 0 {
+		returns = queries.PtrsFromMapping(value, cache.retMapping)
+	}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, vals)
+	}
+	_, err = exec.ExecContext(ctx, cache.query, vals...)
+
+	if err != nil {
+		return errors.Wrap(err, "models: unable to upsert for login_attempts")
+	}
+
+	var uniqueMap []uint64
+	var nzUniqueCols []interface{}
+
+	if len(cache.retMapping) == 0 {
+		goto CacheNoHooks
+	}
+
+	uniqueMap, err = queries.BindMapping(loginAttemptType, loginAttemptMapping, nzUniques)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to retrieve unique values for login_attempts")
+	}
+	nzUniqueCols = queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), uniqueMap)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.retQuery)
+		fmt.Fprintln(writer, nzUniqueCols...)
+	}
+	err = exec.QueryRowContext(ctx, cache.retQuery, nzUniqueCols...).Scan(returns...)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to populate default values for login_attempts")
+	}
+
+CacheNoHooks:
+	if !cached {
+		loginAttemptUpsertCacheMut.Lock()
+		loginAttemptUpsertCache[key] = cache
+		loginAttemptUpsertCacheMut.Unlock()
+	}
+
+	return o.doAfterUpsertHooks(ctx, exec)
+}
+
+// Delete deletes a single LoginAttempt record with an executor.
+// Delete will match against the primary key column to find the record to delete.
+func (o *LoginAttempt) Delete(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if o == nil {
+		return 0, errors.New("models: no LoginAttempt provided for delete")
+	}
+
+	if err := o.doBeforeDeleteHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+
+	args := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), loginAttemptPrimaryKeyMapping)
+	sql := "DELETE FROM `login_attempts` WHERE `attempt_key`=?"
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args...)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete from login_attempts")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by delete for login_attempts")
+	}
+
+	if err := o.doAfterDeleteHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all matching rows.
+func (q loginAttemptQuery) DeleteAll(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if q.Query == nil {
+		return 0, errors.New("models: no loginAttemptQuery provided for delete all")
+	}
+
+	queries.SetDelete(q.Query)
+
+	result, err := q.Query.ExecContext(ctx, exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from login_attempts")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for login_attempts")
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all rows in the slice, using an executor.
+func (o LoginAttemptSlice) DeleteAll(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if len(o) == 0 {
+		return 0, nil
+	}
+
+	if len(loginAttemptBeforeDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doBeforeDeleteHooks(ctx, exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	var args []interface{}
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), loginAttemptPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "DELETE FROM `login_attempts` WHERE " +
+		strmangle.WhereInClause(string(dialect.LQ), string(dialect.RQ), 0, loginAttemptPrimaryKeyColumns, len(o))
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from loginAttempt slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for login_attempts")
+	}
+
+	if len(loginAttemptAfterDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterDeleteHooks(ctx, exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return rowsAff, nil
+}
+
+// Reload refetches the object from the database
+// using the primary keys with an executor.
+func (o *LoginAttempt) Reload(ctx context.Context, exec boil.ContextExecutor) error {
+	ret, err := FindLoginAttempt(ctx, exec, o.AttemptKey)
+	if err != nil {
+		return err
+	}
+
+	*o = *ret
+	return nil
+}
+
+// ReloadAll refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *LoginAttemptSlice) ReloadAll(ctx context.Context, exec boil.ContextExecutor) error {
+	if o == nil || len(*o) == 0 {
+		return nil
+	}
+
+	slice := LoginAttemptSlice{}
+	var args []interface{}
+	for _, obj := range *o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), loginAttemptPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "SELECT `login_attempts`.* FROM `login_attempts` WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 0, loginAttemptPrimaryKeyColumns, len(*o))
+
+	q := queries.Raw(sql, args...)
+
+	err := q.Bind(ctx, exec, &slice)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to reload all in LoginAttemptSlice")
+	}
+
+	*o = slice
+
+	return nil
+}
+
+// LoginAttemptExists checks if the LoginAttempt row exists.
+func LoginAttemptExists(ctx context.Context, exec boil.ContextExecutor, attemptKey string) (bool, error) {
+	var exists bool
+	sql := "select exists(select 1 from `login_attempts` where `attempt_key`=? limit 1)"
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, attemptKey)
+	}
+	row := exec.QueryRowContext(ctx, sql, attemptKey)
+
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, errors.Wrap(err, "models: unable to check if login_attempts exists")
+	}
+
+	return exists, nil
+}
+
+// Exists checks if the LoginAttempt row exists.
+func (o *LoginAttempt) Exists(ctx context.Context, exec boil.ContextExecutor) (bool, error) {
+	return LoginAttemptExists(ctx, exec, o.AttemptKey)
+}
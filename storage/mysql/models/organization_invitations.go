@@ -0,0 +1,1045 @@
+// Code generated by SQLBoiler 4.17.1 (https://github.com/volatiletech/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/friendsofgo/errors"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+	"github.com/volatiletech/sqlboiler/v4/queries/qmhelper"
+	"github.com/volatiletech/strmangle"
+)
+
+// OrganizationInvitation is an object representing the database table.
+type OrganizationInvitation struct {
+	Hash           string                      `boil:"hash" json:"hash" toml:"hash" yaml:"hash"`
+	OrganizationID uint                        `boil:"organization_id" json:"organization_id" toml:"organization_id" yaml:"organization_id"`
+	Email          string                      `boil:"email" json:"email" toml:"email" yaml:"email"`
+	Role           OrganizationInvitationsRole `boil:"role" json:"role" toml:"role" yaml:"role"`
+	InvitedBy      uint                        `boil:"invited_by" json:"invited_by" toml:"invited_by" yaml:"invited_by"`
+	ExpiresAt      time.Time                   `boil:"expires_at" json:"expires_at" toml:"expires_at" yaml:"expires_at"`
+	CreatedAt      time.Time                   `boil:"created_at" json:"created_at" toml:"created_at" yaml:"created_at"`
+
+	R *organizationInvitationR `boil:"-" json:"-" toml:"-" yaml:"-"`
+	L organizationInvitationL  `boil:"-" json:"-" toml:"-" yaml:"-"`
+}
+
+var OrganizationInvitationColumns = struct {
+	Hash           string
+	OrganizationID string
+	Email          string
+	Role           string
+	InvitedBy      string
+	ExpiresAt      string
+	CreatedAt      string
+}{
+	Hash:           "hash",
+	OrganizationID: "organization_id",
+	Email:          "email",
+	Role:           "role",
+	InvitedBy:      "invited_by",
+	ExpiresAt:      "expires_at",
+	CreatedAt:      "created_at",
+}
+
+var OrganizationInvitationTableColumns = struct {
+	Hash           string
+	OrganizationID string
+	Email          string
+	Role           string
+	InvitedBy      string
+	ExpiresAt      string
+	CreatedAt      string
+}{
+	Hash:           "organization_invitations.hash",
+	OrganizationID: "organization_invitations.organization_id",
+	Email:          "organization_invitations.email",
+	Role:           "organization_invitations.role",
+	InvitedBy:      "organization_invitations.invited_by",
+	ExpiresAt:      "organization_invitations.expires_at",
+	CreatedAt:      "organization_invitations.created_at",
+}
+
+// Generated where
+
+type whereHelperOrganizationInvitationsRole struct{ field string }
+
+func (w whereHelperOrganizationInvitationsRole) EQ(x OrganizationInvitationsRole) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.EQ, x)
+}
+func (w whereHelperOrganizationInvitationsRole) NEQ(x OrganizationInvitationsRole) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.NEQ, x)
+}
+func (w whereHelperOrganizationInvitationsRole) LT(x OrganizationInvitationsRole) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LT, x)
+}
+func (w whereHelperOrganizationInvitationsRole) LTE(x OrganizationInvitationsRole) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LTE, x)
+}
+func (w whereHelperOrganizationInvitationsRole) GT(x OrganizationInvitationsRole) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GT, x)
+}
+func (w whereHelperOrganizationInvitationsRole) GTE(x OrganizationInvitationsRole) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GTE, x)
+}
+func (w whereHelperOrganizationInvitationsRole) IN(slice []OrganizationInvitationsRole) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereIn(fmt.Sprintf("%s IN ?", w.field), values...)
+}
+func (w whereHelperOrganizationInvitationsRole) NIN(slice []OrganizationInvitationsRole) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereNotIn(fmt.Sprintf("%s NOT IN ?", w.field), values...)
+}
+
+var OrganizationInvitationWhere = struct {
+	Hash           whereHelperstring
+	OrganizationID whereHelperuint
+	Email          whereHelperstring
+	Role           whereHelperOrganizationInvitationsRole
+	InvitedBy      whereHelperuint
+	ExpiresAt      whereHelpertime_Time
+	CreatedAt      whereHelpertime_Time
+}{
+	Hash:           whereHelperstring{field: "`organization_invitations`.`hash`"},
+	OrganizationID: whereHelperuint{field: "`organization_invitations`.`organization_id`"},
+	Email:          whereHelperstring{field: "`organization_invitations`.`email`"},
+	Role:           whereHelperOrganizationInvitationsRole{field: "`organization_invitations`.`role`"},
+	InvitedBy:      whereHelperuint{field: "`organization_invitations`.`invited_by`"},
+	ExpiresAt:      whereHelpertime_Time{field: "`organization_invitations`.`expires_at`"},
+	CreatedAt:      whereHelpertime_Time{field: "`organization_invitations`.`created_at`"},
+}
+
+// OrganizationInvitationRels is where relationship names are stored.
+var OrganizationInvitationRels = struct {
+}{}
+
+// organizationInvitationR is where relationships are stored.
+type organizationInvitationR struct {
+}
+
+// NewStruct creates a new relationship struct
+func (*organizationInvitationR) NewStruct() *organizationInvitationR {
+	return &organizationInvitationR{}
+}
+
+// organizationInvitationL is where Load methods for each relationship are stored.
+type organizationInvitationL struct{}
+
+var (
+	organizationInvitationAllColumns            = []string{"hash", "organization_id", "email", "role", "invited_by", "expires_at", "created_at"}
+	organizationInvitationColumnsWithoutDefault = []string{"hash", "organization_id", "email", "role", "invited_by", "expires_at", "created_at"}
+	organizationInvitationColumnsWithDefault    = []string{}
+	organizationInvitationPrimaryKeyColumns     = []string{"hash"}
+	organizationInvitationGeneratedColumns      = []string{}
+)
+
+type (
+	// OrganizationInvitationSlice is an alias for a slice of pointers to OrganizationInvitation.
+	// This should almost always be used instead of []OrganizationInvitation.
+	OrganizationInvitationSlice []*OrganizationInvitation
+	// OrganizationInvitationHook is the signature for custom OrganizationInvitation hook methods
+	OrganizationInvitationHook func(context.Context, boil.ContextExecutor, *OrganizationInvitation) error
+
+	organizationInvitationQuery struct {
+		*queries.Query
+	}
+)
+
+// Cache for insert, update and upsert
+var (
+	organizationInvitationType                 = reflect.TypeOf(&OrganizationInvitation{})
+	organizationInvitationMapping              = queries.MakeStructMapping(organizationInvitationType)
+	organizationInvitationPrimaryKeyMapping, _ = queries.BindMapping(organizationInvitationType, organizationInvitationMapping, organizationInvitationPrimaryKeyColumns)
+	organizationInvitationInsertCacheMut       sync.RWMutex
+	organizationInvitationInsertCache          = make(map[string]insertCache)
+	organizationInvitationUpdateCacheMut       sync.RWMutex
+	organizationInvitationUpdateCache          = make(map[string]updateCache)
+	organizationInvitationUpsertCacheMut       sync.RWMutex
+	organizationInvitationUpsertCache          = make(map[string]insertCache)
+)
+
+var (
+	// Force time package dependency for automated UpdatedAt/CreatedAt.
+	_ = time.Second
+	// Force qmhelper dependency for where clause generation (which doesn't
+	// always happen)
+	_ = qmhelper.Where
+)
+
+var organizationInvitationAfterSelectMu sync.Mutex
+var organizationInvitationAfterSelectHooks []OrganizationInvitationHook
+
+var organizationInvitationBeforeInsertMu sync.Mutex
+var organizationInvitationBeforeInsertHooks []OrganizationInvitationHook
+var organizationInvitationAfterInsertMu sync.Mutex
+var organizationInvitationAfterInsertHooks []OrganizationInvitationHook
+
+var organizationInvitationBeforeUpdateMu sync.Mutex
+var organizationInvitationBeforeUpdateHooks []OrganizationInvitationHook
+var organizationInvitationAfterUpdateMu sync.Mutex
+var organizationInvitationAfterUpdateHooks []OrganizationInvitationHook
+
+var organizationInvitationBeforeDeleteMu sync.Mutex
+var organizationInvitationBeforeDeleteHooks []OrganizationInvitationHook
+var organizationInvitationAfterDeleteMu sync.Mutex
+var organizationInvitationAfterDeleteHooks []OrganizationInvitationHook
+
+var organizationInvitationBeforeUpsertMu sync.Mutex
+var organizationInvitationBeforeUpsertHooks []OrganizationInvitationHook
+var organizationInvitationAfterUpsertMu sync.Mutex
+var organizationInvitationAfterUpsertHooks []OrganizationInvitationHook
+
+// doAfterSelectHooks executes all "after Select" hooks.
+func (o *OrganizationInvitation) doAfterSelectHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range organizationInvitationAfterSelectHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeInsertHooks executes all "before insert" hooks.
+func (o *OrganizationInvitation) doBeforeInsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range organizationInvitationBeforeInsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterInsertHooks executes all "after Insert" hooks.
+func (o *OrganizationInvitation) doAfterInsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range organizationInvitationAfterInsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpdateHooks executes all "before Update" hooks.
+func (o *OrganizationInvitation) doBeforeUpdateHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range organizationInvitationBeforeUpdateHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpdateHooks executes all "after Update" hooks.
+func (o *OrganizationInvitation) doAfterUpdateHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range organizationInvitationAfterUpdateHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeDeleteHooks executes all "before Delete" hooks.
+func (o *OrganizationInvitation) doBeforeDeleteHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range organizationInvitationBeforeDeleteHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterDeleteHooks executes all "after Delete" hooks.
+func (o *OrganizationInvitation) doAfterDeleteHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range organizationInvitationAfterDeleteHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doBeforeUpsertHooks executes all "before Upsert" hooks.
+func (o *OrganizationInvitation) doBeforeUpsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range organizationInvitationBeforeUpsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doAfterUpsertHooks executes all "after Upsert" hooks.
+func (o *OrganizationInvitation) doAfterUpsertHooks(ctx context.Context, exec boil.ContextExecutor) (err error) {
+	if boil.HooksAreSkipped(ctx) {
+		return nil
+	}
+
+	for _, hook := range organizationInvitationAfterUpsertHooks {
+		if err := hook(ctx, exec, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddOrganizationInvitationHook registers your hook function for all future operations.
+func AddOrganizationInvitationHook(hookPoint boil.HookPoint, organizationInvitationHook OrganizationInvitationHook) {
+	switch hookPoint {
+	case boil.AfterSelectHook:
+		organizationInvitationAfterSelectMu.Lock()
+		organizationInvitationAfterSelectHooks = append(organizationInvitationAfterSelectHooks, organizationInvitationHook)
+		organizationInvitationAfterSelectMu.Unlock()
+	case boil.BeforeInsertHook:
+		organizationInvitationBeforeInsertMu.Lock()
+		organizationInvitationBeforeInsertHooks = append(organizationInvitationBeforeInsertHooks, organizationInvitationHook)
+		organizationInvitationBeforeInsertMu.Unlock()
+	case boil.AfterInsertHook:
+		organizationInvitationAfterInsertMu.Lock()
+		organizationInvitationAfterInsertHooks = append(organizationInvitationAfterInsertHooks, organizationInvitationHook)
+		organizationInvitationAfterInsertMu.Unlock()
+	case boil.BeforeUpdateHook:
+		organizationInvitationBeforeUpdateMu.Lock()
+		organizationInvitationBeforeUpdateHooks = append(organizationInvitationBeforeUpdateHooks, organizationInvitationHook)
+		organizationInvitationBeforeUpdateMu.Unlock()
+	case boil.AfterUpdateHook:
+		organizationInvitationAfterUpdateMu.Lock()
+		organizationInvitationAfterUpdateHooks = append(organizationInvitationAfterUpdateHooks, organizationInvitationHook)
+		organizationInvitationAfterUpdateMu.Unlock()
+	case boil.BeforeDeleteHook:
+		organizationInvitationBeforeDeleteMu.Lock()
+		organizationInvitationBeforeDeleteHooks = append(organizationInvitationBeforeDeleteHooks, organizationInvitationHook)
+		organizationInvitationBeforeDeleteMu.Unlock()
+	case boil.AfterDeleteHook:
+		organizationInvitationAfterDeleteMu.Lock()
+		organizationInvitationAfterDeleteHooks = append(organizationInvitationAfterDeleteHooks, organizationInvitationHook)
+		organizationInvitationAfterDeleteMu.Unlock()
+	case boil.BeforeUpsertHook:
+		organizationInvitationBeforeUpsertMu.Lock()
+		organizationInvitationBeforeUpsertHooks = append(organizationInvitationBeforeUpsertHooks, organizationInvitationHook)
+		organizationInvitationBeforeUpsertMu.Unlock()
+	case boil.AfterUpsertHook:
+		organizationInvitationAfterUpsertMu.Lock()
+		organizationInvitationAfterUpsertHooks = append(organizationInvitationAfterUpsertHooks, organizationInvitationHook)
+		organizationInvitationAfterUpsertMu.Unlock()
+	}
+}
+
+// One returns a single organizationInvitation record from the query.
+func (q organizationInvitationQuery) One(ctx context.Context, exec boil.ContextExecutor) (*OrganizationInvitation, error) {
+	o := &OrganizationInvitation{}
+
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Bind(ctx, exec, o)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: failed to execute a one query for organization_invitations")
+	}
+
+	if err := o.doAfterSelectHooks(ctx, exec); err != nil {
+		return o, err
+	}
+
+	return o, nil
+}
+
+// All returns all OrganizationInvitation records from the query.
+func (q organizationInvitationQuery) All(ctx context.Context, exec boil.ContextExecutor) (OrganizationInvitationSlice, error) {
+	var o []*OrganizationInvitation
+
+	err := q.Bind(ctx, exec, &o)
+	if err != nil {
+		return nil, errors.Wrap(err, "models: failed to assign all query results to OrganizationInvitation slice")
+	}
+
+	if len(organizationInvitationAfterSelectHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterSelectHooks(ctx, exec); err != nil {
+				return o, err
+			}
+		}
+	}
+
+	return o, nil
+}
+
+// Count returns the count of all OrganizationInvitation records in the query.
+func (q organizationInvitationQuery) Count(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+
+	err := q.Query.QueryRowContext(ctx, exec).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to count organization_invitations rows")
+	}
+
+	return count, nil
+}
+
+// Exists checks if the row exists in the table.
+func (q organizationInvitationQuery) Exists(ctx context.Context, exec boil.ContextExecutor) (bool, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Query.QueryRowContext(ctx, exec).Scan(&count)
+	if err != nil {
+		return false, errors.Wrap(err, "models: failed to check if organization_invitations exists")
+	}
+
+	return count > 0, nil
+}
+
+// OrganizationInvitations retrieves all the records using an executor.
+func OrganizationInvitations(mods ...qm.QueryMod) organizationInvitationQuery {
+	mods = append(mods, qm.From("`organization_invitations`"))
+	q := NewQuery(mods...)
+	if len(queries.GetSelect(q)) == 0 {
+		queries.SetSelect(q, []string{"`organization_invitations`.*"})
+	}
+
+	return organizationInvitationQuery{q}
+}
+
+// FindOrganizationInvitation retrieves a single record by ID with an executor.
+// If selectCols is empty Find will return all columns.
+func FindOrganizationInvitation(ctx context.Context, exec boil.ContextExecutor, hash string, selectCols ...string) (*OrganizationInvitation, error) {
+	organizationInvitationObj := &OrganizationInvitation{}
+
+	sel := "*"
+	if len(selectCols) > 0 {
+		sel = strings.Join(strmangle.IdentQuoteSlice(dialect.LQ, dialect.RQ, selectCols), ",")
+	}
+	query := fmt.Sprintf(
+		"select %s from `organization_invitations` where `hash`=?", sel,
+	)
+
+	q := queries.Raw(query, hash)
+
+	err := q.Bind(ctx, exec, organizationInvitationObj)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "models: unable to select from organization_invitations")
+	}
+
+	if err = organizationInvitationObj.doAfterSelectHooks(ctx, exec); err != nil {
+		return organizationInvitationObj, err
+	}
+
+	return organizationInvitationObj, nil
+}
+
+// Insert a single record using an executor.
+// See boil.Columns.InsertColumnSet documentation to understand column list inference for inserts.
+func (o *OrganizationInvitation) Insert(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no organization_invitations provided for insertion")
+	}
+
+	var err error
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		if o.CreatedAt.IsZero() {
+			o.CreatedAt = currTime
+		}
+	}
+
+	if err := o.doBeforeInsertHooks(ctx, exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(organizationInvitationColumnsWithDefault, o)
+
+	key := makeCacheKey(columns, nzDefaults)
+	organizationInvitationInsertCacheMut.RLock()
+	cache, cached := organizationInvitationInsertCache[key]
+	organizationInvitationInsertCacheMut.RUnlock()
+
+	if !cached {
+		wl, returnColumns := columns.InsertColumnSet(
+			organizationInvitationAllColumns,
+			organizationInvitationColumnsWithDefault,
+			organizationInvitationColumnsWithoutDefault,
+			nzDefaults,
+		)
+
+		cache.valueMapping, err = queries.BindMapping(organizationInvitationType, organizationInvitationMapping, wl)
+		if err != nil {
+			return err
+		}
+		cache.retMapping, err = queries.BindMapping(organizationInvitationType, organizationInvitationMapping, returnColumns)
+		if err != nil {
+			return err
+		}
+		if len(wl) != 0 {
+			cache.query = fmt.Sprintf("INSERT INTO `organization_invitations` (`%s`) %%sVALUES (%s)%%s", strings.Join(wl, "`,`"), strmangle.Placeholders(dialect.UseIndexPlaceholders, len(wl), 1, 1))
+		} else {
+			cache.query = "INSERT INTO `organization_invitations` () VALUES ()%s%s"
+		}
+
+		var queryOutput, queryReturning string
+
+		if len(cache.retMapping) != 0 {
+			cache.retQuery = fmt.Sprintf("SELECT `%s` FROM `organization_invitations` WHERE %s", strings.Join(returnColumns, "`,`"), strmangle.WhereClause("`", "`", 0, organizationInvitationPrimaryKeyColumns))
+		}
+
+		cache.query = fmt.Sprintf(cache.query, queryOutput, queryReturning)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, vals)
+	}
+	_, err = exec.ExecContext(ctx, cache.query, vals...)
+
+	if err != nil {
+		return errors.Wrap(err, "models: unable to insert into organization_invitations")
+	}
+
+	var identifierCols []interface{}
+
+	if len(cache.retMapping) == 0 {
+		goto CacheNoHooks
+	}
+
+	identifierCols = []interface{}{
+		o.Hash,
+	}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.retQuery)
+		fmt.Fprintln(writer, identifierCols...)
+	}
+	err = exec.QueryRowContext(ctx, cache.retQuery, identifierCols...).Scan(queries.PtrsFromMapping(value, cache.retMapping)...)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to populate default values for organization_invitations")
+	}
+
+CacheNoHooks:
+	if !cached {
+		organizationInvitationInsertCacheMut.Lock()
+		organizationInvitationInsertCache[key] = cache
+		organizationInvitationInsertCacheMut.Unlock()
+	}
+
+	return o.doAfterInsertHooks(ctx, exec)
+}
+
+// Update uses an executor to update the OrganizationInvitation.
+// See boil.Columns.UpdateColumnSet documentation to understand column list inference for updates.
+// Update does not automatically update the record in case of default values. Use .Reload() to refresh the records.
+func (o *OrganizationInvitation) Update(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) (int64, error) {
+	var err error
+	if err = o.doBeforeUpdateHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+	key := makeCacheKey(columns, nil)
+	organizationInvitationUpdateCacheMut.RLock()
+	cache, cached := organizationInvitationUpdateCache[key]
+	organizationInvitationUpdateCacheMut.RUnlock()
+
+	if !cached {
+		wl := columns.UpdateColumnSet(
+			organizationInvitationAllColumns,
+			organizationInvitationPrimaryKeyColumns,
+		)
+
+		if !columns.IsWhitelist() {
+			wl = strmangle.SetComplement(wl, []string{"created_at"})
+		}
+		if len(wl) == 0 {
+			return 0, errors.New("models: unable to update organization_invitations, could not build whitelist")
+		}
+
+		cache.query = fmt.Sprintf("UPDATE `organization_invitations` SET %s WHERE %s",
+			strmangle.SetParamNames("`", "`", 0, wl),
+			strmangle.WhereClause("`", "`", 0, organizationInvitationPrimaryKeyColumns),
+		)
+		cache.valueMapping, err = queries.BindMapping(organizationInvitationType, organizationInvitationMapping, append(wl, organizationInvitationPrimaryKeyColumns...))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	values := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), cache.valueMapping)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, values)
+	}
+	var result sql.Result
+	result, err = exec.ExecContext(ctx, cache.query, values...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update organization_invitations row")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by update for organization_invitations")
+	}
+
+	if !cached {
+		organizationInvitationUpdateCacheMut.Lock()
+		organizationInvitationUpdateCache[key] = cache
+		organizationInvitationUpdateCacheMut.Unlock()
+	}
+
+	return rowsAff, o.doAfterUpdateHooks(ctx, exec)
+}
+
+// UpdateAll updates all rows with the specified column values.
+func (q organizationInvitationQuery) UpdateAll(ctx context.Context, exec boil.ContextExecutor, cols M) (int64, error) {
+	queries.SetUpdate(q.Query, cols)
+
+	result, err := q.Query.ExecContext(ctx, exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all for organization_invitations")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected for organization_invitations")
+	}
+
+	return rowsAff, nil
+}
+
+// UpdateAll updates all rows with the specified column values, using an executor.
+func (o OrganizationInvitationSlice) UpdateAll(ctx context.Context, exec boil.ContextExecutor, cols M) (int64, error) {
+	ln := int64(len(o))
+	if ln == 0 {
+		return 0, nil
+	}
+
+	if len(cols) == 0 {
+		return 0, errors.New("models: update all requires at least one column argument")
+	}
+
+	colNames := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+
+	i := 0
+	for name, value := range cols {
+		colNames[i] = name
+		args[i] = value
+		i++
+	}
+
+	// Append all of the primary key values for each column
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), organizationInvitationPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := fmt.Sprintf("UPDATE `organization_invitations` SET %s WHERE %s",
+		strmangle.SetParamNames("`", "`", 0, colNames),
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 0, organizationInvitationPrimaryKeyColumns, len(o)))
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args...)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to update all in organizationInvitation slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to retrieve rows affected all in update all organizationInvitation")
+	}
+	return rowsAff, nil
+}
+
+var mySQLOrganizationInvitationUniqueColumns = []string{
+	"hash",
+}
+
+// Upsert attempts an insert using an executor, and does an update or ignore on conflict.
+// See boil.Columns documentation for how to properly use updateColumns and insertColumns.
+func (o *OrganizationInvitation) Upsert(ctx context.Context, exec boil.ContextExecutor, updateColumns, insertColumns boil.Columns) error {
+	if o == nil {
+		return errors.New("models: no organization_invitations provided for upsert")
+	}
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		if o.CreatedAt.IsZero() {
+			o.CreatedAt = currTime
+		}
+	}
+
+	if err := o.doBeforeUpsertHooks(ctx, exec); err != nil {
+		return err
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(organizationInvitationColumnsWithDefault, o)
+	nzUniques := queries.NonZeroDefaultSet(mySQLOrganizationInvitationUniqueColumns, o)
+
+	if len(nzUniques) == 0 {
+		return errors.New("cannot upsert with a table that cannot conflict on a unique column")
+	}
+
+	// Build cache key in-line uglily - mysql vs psql problems
+	buf := strmangle.GetBuffer()
+	buf.WriteString(strconv.Itoa(updateColumns.Kind))
+	for _, c := range updateColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(insertColumns.Kind))
+	for _, c := range insertColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	for _, c := range nzDefaults {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	for _, c := range nzUniques {
+		buf.WriteString(c)
+	}
+	key := buf.String()
+	strmangle.PutBuffer(buf)
+
+	organizationInvitationUpsertCacheMut.RLock()
+	cache, cached := organizationInvitationUpsertCache[key]
+	organizationInvitationUpsertCacheMut.RUnlock()
+
+	var err error
+
+	if !cached {
+		insert, _ := insertColumns.InsertColumnSet(
+			organizationInvitationAllColumns,
+			organizationInvitationColumnsWithDefault,
+			organizationInvitationColumnsWithoutDefault,
+			nzDefaults,
+		)
+
+		update := updateColumns.UpdateColumnSet(
+			organizationInvitationAllColumns,
+			organizationInvitationPrimaryKeyColumns,
+		)
+
+		if !updateColumns.IsNone() && len(update) == 0 {
+			return errors.New("models: unable to upsert organization_invitations, could not build update column list")
+		}
+
+		ret := strmangle.SetComplement(organizationInvitationAllColumns, strmangle.SetIntersect(insert, update))
+
+		cache.query = buildUpsertQueryMySQL(dialect, "`organization_invitations`", update, insert)
+		cache.retQuery = fmt.Sprintf(
+			"SELECT %s FROM `organization_invitations` WHERE %s",
+			strings.Join(strmangle.IdentQuoteSlice(dialect.LQ, dialect.RQ, ret), ","),
+			strmangle.WhereClause("`", "`", 0, nzUniques),
+		)
+
+		cache.valueMapping, err = queries.BindMapping(organizationInvitationType, organizationInvitationMapping, insert)
+		if err != nil {
+			return err
+		}
+		if len(ret) != 0 {
+			cache.retMapping, err = queries.BindMapping(organizationInvitationType, organizationInvitationMapping, ret)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+	var returns []interface{}
+	if len(cache.retMapping) != 0 {
+		returns = queries.PtrsFromMapping(value, cache.retMapping)
+	}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, vals)
+	}
+	_, err = exec.ExecContext(ctx, cache.query, vals...)
+
+	if err != nil {
+		return errors.Wrap(err, "models: unable to upsert for organization_invitations")
+	}
+
+	var uniqueMap []uint64
+	var nzUniqueCols []interface{}
+
+	if len(cache.retMapping) == 0 {
+		goto CacheNoHooks
+	}
+
+	uniqueMap, err = queries.BindMapping(organizationInvitationType, organizationInvitationMapping, nzUniques)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to retrieve unique values for organization_invitations")
+	}
+	nzUniqueCols = queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), uniqueMap)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.retQuery)
+		fmt.Fprintln(writer, nzUniqueCols...)
+	}
+	err = exec.QueryRowContext(ctx, cache.retQuery, nzUniqueCols...).Scan(returns...)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to populate default values for organization_invitations")
+	}
+
+CacheNoHooks:
+	if !cached {
+		organizationInvitationUpsertCacheMut.Lock()
+		organizationInvitationUpsertCache[key] = cache
+		organizationInvitationUpsertCacheMut.Unlock()
+	}
+
+	return o.doAfterUpsertHooks(ctx, exec)
+}
+
+// Delete deletes a single OrganizationInvitation record with an executor.
+// Delete will match against the primary key column to find the record to delete.
+func (o *OrganizationInvitation) Delete(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if o == nil {
+		return 0, errors.New("models: no OrganizationInvitation provided for delete")
+	}
+
+	if err := o.doBeforeDeleteHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+
+	args := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), organizationInvitationPrimaryKeyMapping)
+	sql := "DELETE FROM `organization_invitations` WHERE `hash`=?"
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args...)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete from organization_invitations")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by delete for organization_invitations")
+	}
+
+	if err := o.doAfterDeleteHooks(ctx, exec); err != nil {
+		return 0, err
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all matching rows.
+func (q organizationInvitationQuery) DeleteAll(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if q.Query == nil {
+		return 0, errors.New("models: no organizationInvitationQuery provided for delete all")
+	}
+
+	queries.SetDelete(q.Query)
+
+	result, err := q.Query.ExecContext(ctx, exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from organization_invitations")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for organization_invitations")
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all rows in the slice, using an executor.
+func (o OrganizationInvitationSlice) DeleteAll(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if len(o) == 0 {
+		return 0, nil
+	}
+
+	if len(organizationInvitationBeforeDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doBeforeDeleteHooks(ctx, exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	var args []interface{}
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), organizationInvitationPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "DELETE FROM `organization_invitations` WHERE " +
+		strmangle.WhereInClause(string(dialect.LQ), string(dialect.RQ), 0, organizationInvitationPrimaryKeyColumns, len(o))
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "models: unable to delete all from organizationInvitation slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "models: failed to get rows affected by deleteall for organization_invitations")
+	}
+
+	if len(organizationInvitationAfterDeleteHooks) != 0 {
+		for _, obj := range o {
+			if err := obj.doAfterDeleteHooks(ctx, exec); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return rowsAff, nil
+}
+
+// Reload refetches the object from the database
+// using the primary keys with an executor.
+func (o *OrganizationInvitation) Reload(ctx context.Context, exec boil.ContextExecutor) error {
+	ret, err := FindOrganizationInvitation(ctx, exec, o.Hash)
+	if err != nil {
+		return err
+	}
+
+	*o = *ret
+	return nil
+}
+
+// ReloadAll refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *OrganizationInvitationSlice) ReloadAll(ctx context.Context, exec boil.ContextExecutor) error {
+	if o == nil || len(*o) == 0 {
+		return nil
+	}
+
+	slice := OrganizationInvitationSlice{}
+	var args []interface{}
+	for _, obj := range *o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), organizationInvitationPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "SELECT `organization_invitations`.* FROM `organization_invitations` WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 0, organizationInvitationPrimaryKeyColumns, len(*o))
+
+	q := queries.Raw(sql, args...)
+
+	err := q.Bind(ctx, exec, &slice)
+	if err != nil {
+		return errors.Wrap(err, "models: unable to reload all in OrganizationInvitationSlice")
+	}
+
+	*o = slice
+
+	return nil
+}
+
+// OrganizationInvitationExists checks if the OrganizationInvitation row exists.
+func OrganizationInvitationExists(ctx context.Context, exec boil.ContextExecutor, hash string) (bool, error) {
+	var exists bool
+	sql := "select exists(select 1 from `organization_invitations` where `hash`=? limit 1)"
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, hash)
+	}
+	row := exec.QueryRowContext(ctx, sql, hash)
+
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, errors.Wrap(err, "models: unable to check if organization_invitations exists")
+	}
+
+	return exists, nil
+}
+
+// Exists checks if the OrganizationInvitation row exists.
+func (o *OrganizationInvitation) Exists(ctx context.Context, exec boil.ContextExecutor) (bool, error) {
+	return OrganizationInvitationExists(ctx, exec, o.Hash)
+}
@@ -24,12 +24,14 @@ import (
 // Transaction is an object representing the database table.
 type Transaction struct {
 	ID             uint               `boil:"id" json:"id" toml:"id" yaml:"id"`
+	OrganizationID uint               `boil:"organization_id" json:"organization_id" toml:"organization_id" yaml:"organization_id"`
 	UserID         uint               `boil:"user_id" json:"user_id" toml:"user_id" yaml:"user_id"`
 	Type           TransactionsType   `boil:"type" json:"type" toml:"type" yaml:"type"`
 	CardType       string             `boil:"card_type" json:"card_type" toml:"card_type" yaml:"card_type"`
 	AmountCaptured uint               `boil:"amount_captured" json:"amount_captured" toml:"amount_captured" yaml:"amount_captured"`
 	InvoiceID      uint               `boil:"invoice_id" json:"invoice_id" toml:"invoice_id" yaml:"invoice_id"`
 	Status         TransactionsStatus `boil:"status" json:"status" toml:"status" yaml:"status"`
+	CreatedAt      time.Time          `boil:"created_at" json:"created_at" toml:"created_at" yaml:"created_at"`
 
 	R *transactionR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L transactionL  `boil:"-" json:"-" toml:"-" yaml:"-"`
@@ -37,38 +39,46 @@ type Transaction struct {
 
 var TransactionColumns = struct {
 	ID             string
+	OrganizationID string
 	UserID         string
 	Type           string
 	CardType       string
 	AmountCaptured string
 	InvoiceID      string
 	Status         string
+	CreatedAt      string
 }{
 	ID:             "id",
+	OrganizationID: "organization_id",
 	UserID:         "user_id",
 	Type:           "type",
 	CardType:       "card_type",
 	AmountCaptured: "amount_captured",
 	InvoiceID:      "invoice_id",
 	Status:         "status",
+	CreatedAt:      "created_at",
 }
 
 var TransactionTableColumns = struct {
 	ID             string
+	OrganizationID string
 	UserID         string
 	Type           string
 	CardType       string
 	AmountCaptured string
 	InvoiceID      string
 	Status         string
+	CreatedAt      string
 }{
 	ID:             "transactions.id",
+	OrganizationID: "transactions.organization_id",
 	UserID:         "transactions.user_id",
 	Type:           "transactions.type",
 	CardType:       "transactions.card_type",
 	AmountCaptured: "transactions.amount_captured",
 	InvoiceID:      "transactions.invoice_id",
 	Status:         "transactions.status",
+	CreatedAt:      "transactions.created_at",
 }
 
 // Generated where
@@ -145,20 +155,24 @@ func (w whereHelperTransactionsStatus) NIN(slice []TransactionsStatus) qm.QueryM
 
 var TransactionWhere = struct {
 	ID             whereHelperuint
+	OrganizationID whereHelperuint
 	UserID         whereHelperuint
 	Type           whereHelperTransactionsType
 	CardType       whereHelperstring
 	AmountCaptured whereHelperuint
 	InvoiceID      whereHelperuint
 	Status         whereHelperTransactionsStatus
+	CreatedAt      whereHelpertime_Time
 }{
 	ID:             whereHelperuint{field: "`transactions`.`id`"},
+	OrganizationID: whereHelperuint{field: "`transactions`.`organization_id`"},
 	UserID:         whereHelperuint{field: "`transactions`.`user_id`"},
 	Type:           whereHelperTransactionsType{field: "`transactions`.`type`"},
 	CardType:       whereHelperstring{field: "`transactions`.`card_type`"},
 	AmountCaptured: whereHelperuint{field: "`transactions`.`amount_captured`"},
 	InvoiceID:      whereHelperuint{field: "`transactions`.`invoice_id`"},
 	Status:         whereHelperTransactionsStatus{field: "`transactions`.`status`"},
+	CreatedAt:      whereHelpertime_Time{field: "`transactions`.`created_at`"},
 }
 
 // TransactionRels is where relationship names are stored.
@@ -178,9 +192,9 @@ func (*transactionR) NewStruct() *transactionR {
 type transactionL struct{}
 
 var (
-	transactionAllColumns            = []string{"id", "user_id", "type", "card_type", "amount_captured", "invoice_id", "status"}
-	transactionColumnsWithoutDefault = []string{"id", "user_id", "type", "card_type", "amount_captured", "invoice_id", "status"}
-	transactionColumnsWithDefault    = []string{}
+	transactionAllColumns            = []string{"id", "organization_id", "user_id", "type", "card_type", "amount_captured", "invoice_id", "status", "created_at"}
+	transactionColumnsWithoutDefault = []string{"organization_id", "user_id", "type", "card_type", "amount_captured", "invoice_id", "status", "created_at"}
+	transactionColumnsWithDefault    = []string{"id"}
 	transactionPrimaryKeyColumns     = []string{"id"}
 	transactionGeneratedColumns      = []string{}
 )
@@ -539,6 +553,13 @@ func (o *Transaction) Insert(ctx context.Context, exec boil.ContextExecutor, col
 	}
 
 	var err error
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		if o.CreatedAt.IsZero() {
+			o.CreatedAt = currTime
+		}
+	}
 
 	if err := o.doBeforeInsertHooks(ctx, exec); err != nil {
 		return err
@@ -590,18 +611,29 @@ func (o *Transaction) Insert(ctx context.Context, exec boil.ContextExecutor, col
 		fmt.Fprintln(writer, cache.query)
 		fmt.Fprintln(writer, vals)
 	}
-	_, err = exec.ExecContext(ctx, cache.query, vals...)
+	result, err := exec.ExecContext(ctx, cache.query, vals...)
 
 	if err != nil {
 		return errors.Wrap(err, "models: unable to insert into transactions")
 	}
 
+	var lastID int64
 	var identifierCols []interface{}
 
 	if len(cache.retMapping) == 0 {
 		goto CacheNoHooks
 	}
 
+	lastID, err = result.LastInsertId()
+	if err != nil {
+		return ErrSyncFail
+	}
+
+	o.ID = uint(lastID)
+	if lastID != 0 && len(cache.retMapping) == 1 && cache.retMapping[0] == transactionMapping["id"] {
+		goto CacheNoHooks
+	}
+
 	identifierCols = []interface{}{
 		o.ID,
 	}
@@ -764,6 +796,13 @@ func (o *Transaction) Upsert(ctx context.Context, exec boil.ContextExecutor, upd
 	if o == nil {
 		return errors.New("models: no transactions provided for upsert")
 	}
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		if o.CreatedAt.IsZero() {
+			o.CreatedAt = currTime
+		}
+	}
 
 	if err := o.doBeforeUpsertHooks(ctx, exec); err != nil {
 		return err
@@ -854,12 +893,13 @@ func (o *Transaction) Upsert(ctx context.Context, exec boil.ContextExecutor, upd
 		fmt.Fprintln(writer, cache.query)
 		fmt.Fprintln(writer, vals)
 	}
-	_, err = exec.ExecContext(ctx, cache.query, vals...)
+	result, err := exec.ExecContext(ctx, cache.query, vals...)
 
 	if err != nil {
 		return errors.Wrap(err, "models: unable to upsert for transactions")
 	}
 
+	var lastID int64
 	var uniqueMap []uint64
 	var nzUniqueCols []interface{}
 
@@ -867,6 +907,16 @@ func (o *Transaction) Upsert(ctx context.Context, exec boil.ContextExecutor, upd
 		goto CacheNoHooks
 	}
 
+	lastID, err = result.LastInsertId()
+	if err != nil {
+		return ErrSyncFail
+	}
+
+	o.ID = uint(lastID)
+	if lastID != 0 && len(cache.retMapping) == 1 && cache.retMapping[0] == transactionMapping["id"] {
+		goto CacheNoHooks
+	}
+
 	uniqueMap, err = queries.BindMapping(transactionType, transactionMapping, nzUniques)
 	if err != nil {
 		return errors.Wrap(err, "models: unable to retrieve unique values for transactions")
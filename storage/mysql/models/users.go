@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/friendsofgo/errors"
+	"github.com/volatiletech/null/v8"
 	"github.com/volatiletech/sqlboiler/v4/boil"
 	"github.com/volatiletech/sqlboiler/v4/queries"
 	"github.com/volatiletech/sqlboiler/v4/queries/qm"
@@ -23,44 +24,110 @@ import (
 
 // User is an object representing the database table.
 type User struct {
-	ID       uint   `boil:"id" json:"id" toml:"id" yaml:"id"`
-	Email    string `boil:"email" json:"email" toml:"email" yaml:"email"`
-	Password string `boil:"password" json:"password" toml:"password" yaml:"password"`
+	ID              uint        `boil:"id" json:"id" toml:"id" yaml:"id"`
+	Email           string      `boil:"email" json:"email" toml:"email" yaml:"email"`
+	Password        string      `boil:"password" json:"password" toml:"password" yaml:"password"`
+	EmailVerifiedAt null.Time   `boil:"email_verified_at" json:"email_verified_at,omitempty" toml:"email_verified_at" yaml:"email_verified_at,omitempty"`
+	TotpSecret      null.String `boil:"totp_secret" json:"totp_secret,omitempty" toml:"totp_secret" yaml:"totp_secret,omitempty"`
+	TotpEnabledAt   null.Time   `boil:"totp_enabled_at" json:"totp_enabled_at,omitempty" toml:"totp_enabled_at" yaml:"totp_enabled_at,omitempty"`
+	TotpLastStep    null.Uint64 `boil:"totp_last_step" json:"totp_last_step,omitempty" toml:"totp_last_step" yaml:"totp_last_step,omitempty"`
 
 	R *userR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L userL  `boil:"-" json:"-" toml:"-" yaml:"-"`
 }
 
 var UserColumns = struct {
-	ID       string
-	Email    string
-	Password string
+	ID              string
+	Email           string
+	Password        string
+	EmailVerifiedAt string
+	TotpSecret      string
+	TotpEnabledAt   string
+	TotpLastStep    string
 }{
-	ID:       "id",
-	Email:    "email",
-	Password: "password",
+	ID:              "id",
+	Email:           "email",
+	Password:        "password",
+	EmailVerifiedAt: "email_verified_at",
+	TotpSecret:      "totp_secret",
+	TotpEnabledAt:   "totp_enabled_at",
+	TotpLastStep:    "totp_last_step",
 }
 
 var UserTableColumns = struct {
-	ID       string
-	Email    string
-	Password string
+	ID              string
+	Email           string
+	Password        string
+	EmailVerifiedAt string
+	TotpSecret      string
+	TotpEnabledAt   string
+	TotpLastStep    string
 }{
-	ID:       "users.id",
-	Email:    "users.email",
-	Password: "users.password",
+	ID:              "users.id",
+	Email:           "users.email",
+	Password:        "users.password",
+	EmailVerifiedAt: "users.email_verified_at",
+	TotpSecret:      "users.totp_secret",
+	TotpEnabledAt:   "users.totp_enabled_at",
+	TotpLastStep:    "users.totp_last_step",
 }
 
 // Generated where
 
+type whereHelpernull_Uint64 struct{ field string }
+
+func (w whereHelpernull_Uint64) EQ(x null.Uint64) qm.QueryMod {
+	return qmhelper.WhereNullEQ(w.field, false, x)
+}
+func (w whereHelpernull_Uint64) NEQ(x null.Uint64) qm.QueryMod {
+	return qmhelper.WhereNullEQ(w.field, true, x)
+}
+func (w whereHelpernull_Uint64) LT(x null.Uint64) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LT, x)
+}
+func (w whereHelpernull_Uint64) LTE(x null.Uint64) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LTE, x)
+}
+func (w whereHelpernull_Uint64) GT(x null.Uint64) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GT, x)
+}
+func (w whereHelpernull_Uint64) GTE(x null.Uint64) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GTE, x)
+}
+func (w whereHelpernull_Uint64) IN(slice []uint64) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereIn(fmt.Sprintf("%s IN ?", w.field), values...)
+}
+func (w whereHelpernull_Uint64) NIN(slice []uint64) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereNotIn(fmt.Sprintf("%s NOT IN ?", w.field), values...)
+}
+
+func (w whereHelpernull_Uint64) IsNull() qm.QueryMod    { return qmhelper.WhereIsNull(w.field) }
+func (w whereHelpernull_Uint64) IsNotNull() qm.QueryMod { return qmhelper.WhereIsNotNull(w.field) }
+
 var UserWhere = struct {
-	ID       whereHelperuint
-	Email    whereHelperstring
-	Password whereHelperstring
+	ID              whereHelperuint
+	Email           whereHelperstring
+	Password        whereHelperstring
+	EmailVerifiedAt whereHelpernull_Time
+	TotpSecret      whereHelpernull_String
+	TotpEnabledAt   whereHelpernull_Time
+	TotpLastStep    whereHelpernull_Uint64
 }{
-	ID:       whereHelperuint{field: "`users`.`id`"},
-	Email:    whereHelperstring{field: "`users`.`email`"},
-	Password: whereHelperstring{field: "`users`.`password`"},
+	ID:              whereHelperuint{field: "`users`.`id`"},
+	Email:           whereHelperstring{field: "`users`.`email`"},
+	Password:        whereHelperstring{field: "`users`.`password`"},
+	EmailVerifiedAt: whereHelpernull_Time{field: "`users`.`email_verified_at`"},
+	TotpSecret:      whereHelpernull_String{field: "`users`.`totp_secret`"},
+	TotpEnabledAt:   whereHelpernull_Time{field: "`users`.`totp_enabled_at`"},
+	TotpLastStep:    whereHelpernull_Uint64{field: "`users`.`totp_last_step`"},
 }
 
 // UserRels is where relationship names are stored.
@@ -80,9 +147,9 @@ func (*userR) NewStruct() *userR {
 type userL struct{}
 
 var (
-	userAllColumns            = []string{"id", "email", "password"}
-	userColumnsWithoutDefault = []string{"id", "email", "password"}
-	userColumnsWithDefault    = []string{}
+	userAllColumns            = []string{"id", "email", "password", "email_verified_at", "totp_secret", "totp_enabled_at", "totp_last_step"}
+	userColumnsWithoutDefault = []string{"email", "password", "email_verified_at", "totp_secret", "totp_enabled_at", "totp_last_step"}
+	userColumnsWithDefault    = []string{"id"}
 	userPrimaryKeyColumns     = []string{"id"}
 	userGeneratedColumns      = []string{}
 )
@@ -492,18 +559,29 @@ func (o *User) Insert(ctx context.Context, exec boil.ContextExecutor, columns bo
 		fmt.Fprintln(writer, cache.query)
 		fmt.Fprintln(writer, vals)
 	}
-	_, err = exec.ExecContext(ctx, cache.query, vals...)
+	result, err := exec.ExecContext(ctx, cache.query, vals...)
 
 	if err != nil {
 		return errors.Wrap(err, "models: unable to insert into users")
 	}
 
+	var lastID int64
 	var identifierCols []interface{}
 
 	if len(cache.retMapping) == 0 {
 		goto CacheNoHooks
 	}
 
+	lastID, err = result.LastInsertId()
+	if err != nil {
+		return ErrSyncFail
+	}
+
+	o.ID = uint(lastID)
+	if lastID != 0 && len(cache.retMapping) == 1 && cache.retMapping[0] == userMapping["id"] {
+		goto CacheNoHooks
+	}
+
 	identifierCols = []interface{}{
 		o.ID,
 	}
@@ -756,12 +834,13 @@ func (o *User) Upsert(ctx context.Context, exec boil.ContextExecutor, updateColu
 		fmt.Fprintln(writer, cache.query)
 		fmt.Fprintln(writer, vals)
 	}
-	_, err = exec.ExecContext(ctx, cache.query, vals...)
+	result, err := exec.ExecContext(ctx, cache.query, vals...)
 
 	if err != nil {
 		return errors.Wrap(err, "models: unable to upsert for users")
 	}
 
+	var lastID int64
 	var uniqueMap []uint64
 	var nzUniqueCols []interface{}
 
@@ -769,6 +848,16 @@ func (o *User) Upsert(ctx context.Context, exec boil.ContextExecutor, updateColu
 		goto CacheNoHooks
 	}
 
+	lastID, err = result.LastInsertId()
+	if err != nil {
+		return ErrSyncFail
+	}
+
+	o.ID = uint(lastID)
+	if lastID != 0 && len(cache.retMapping) == 1 && cache.retMapping[0] == userMapping["id"] {
+		goto CacheNoHooks
+	}
+
 	uniqueMap, err = queries.BindMapping(userType, userMapping, nzUniques)
 	if err != nil {
 		return errors.Wrap(err, "models: unable to retrieve unique values for users")
@@ -2,20 +2,42 @@ package mysql
 
 import (
 	"database/sql"
+	"time"
 
 	"dddstructure/storage"
+	"dddstructure/storage/idgen"
+	"dddstructure/storage/mysql/health"
 	"dddstructure/storage/mysql/invoice"
+	"dddstructure/storage/mysql/loginattempt"
+	"dddstructure/storage/mysql/organization"
+	"dddstructure/storage/mysql/outbox"
+	"dddstructure/storage/mysql/recoverycode"
+	"dddstructure/storage/mysql/report"
+	"dddstructure/storage/mysql/session"
 	"dddstructure/storage/mysql/transaction"
 	"dddstructure/storage/mysql/user"
+	"dddstructure/storage/mysql/usertoken"
 )
 
 // New returns a new implementation of storage.Storage that uses MySQL as the
-// backend database.
-func New(db *sql.DB) *storage.Storage {
+// backend database. Each query is cancelled after the given timeout, or only
+// once its context is if the timeout is zero.
+//
+// The IDs of new users, organizations, invoices and transactions are taken
+// from the given generator, or assigned by the database if it is nil.
+func New(db *sql.DB, timeout time.Duration, ids idgen.Generator) *storage.Storage {
 	s := &storage.Storage{
-		User:        user.New(db),
-		Invoice:     invoice.New(db),
-		Transaction: transaction.New(db),
+		User:         user.New(db, timeout, ids),
+		UserToken:    usertoken.New(db, timeout),
+		RecoveryCode: recoverycode.New(db, timeout),
+		LoginAttempt: loginattempt.New(db, timeout),
+		Session:      session.New(db, timeout),
+		Organization: organization.New(db, timeout, ids),
+		Invoice:      invoice.New(db, timeout, ids),
+		Transaction:  transaction.New(db, timeout, ids),
+		Report:       report.New(db, timeout),
+		Outbox:       outbox.New(db, timeout),
+		Health:       health.New(db, timeout),
 	}
 
 	return s
@@ -0,0 +1,322 @@
+package organization
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/idgen"
+	"dddstructure/storage/mysql/models"
+	"dddstructure/storage/organization"
+
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+	ids     idgen.Generator
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout. The IDs of new organizations are taken from the given generator,
+// or assigned by the AUTO_INCREMENT column if it is nil.
+func New(db *sql.DB, timeout time.Duration, ids idgen.Generator) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+		ids:     ids,
+	}
+}
+
+// Create creates a new organization, with the given member as its owner.
+func (db *Database) Create(ctx context.Context, o *organization.Organization, owner *organization.Member) (*organization.Organization, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Map to model.
+	model := models.Organization{
+		ID:        o.ID,
+		Name:      o.Name,
+		CreatedAt: o.CreatedAt,
+	}
+
+	// Handle ID.
+	if model.ID == 0 && db.ids != nil {
+		model.ID = db.ids.NewID()
+	}
+
+	// Insert into database, along with the owner.
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := model.Insert(ctx, tx, boil.Infer()); err != nil {
+		return nil, err
+	}
+
+	// The ID is read back from the insert when assigned by the database.
+	memberModel := models.OrganizationMember{
+		OrganizationID: model.ID,
+		UserID:         owner.UserID,
+		Role:           models.OrganizationMembersRole(owner.Role),
+		CreatedAt:      owner.CreatedAt,
+	}
+	if err := memberModel.Insert(ctx, tx, boil.Infer()); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	created := *o
+	created.ID = model.ID
+
+	return &created, nil
+}
+
+// GetByID gets an organization by the given ID.
+func (db *Database) GetByID(ctx context.Context, id uint) (*organization.Organization, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	model, err := models.Organizations(qm.Where("id=?", id)).One(ctx, db.db)
+	if err == sql.ErrNoRows {
+		return nil, organization.ErrOrganizationNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Map to organization type.
+	o := &organization.Organization{
+		ID:        model.ID,
+		Name:      model.Name,
+		CreatedAt: model.CreatedAt,
+	}
+
+	return o, nil
+}
+
+// CreateMember creates a new organization member.
+func (db *Database) CreateMember(ctx context.Context, m *organization.Member) (*organization.Member, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Map to model.
+	model := models.OrganizationMember{
+		OrganizationID: m.OrganizationID,
+		UserID:         m.UserID,
+		Role:           models.OrganizationMembersRole(m.Role),
+		CreatedAt:      m.CreatedAt,
+	}
+
+	// Insert into database.
+	err := model.Insert(ctx, db.db, boil.Infer())
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// GetMember gets the member of an organization by the given user ID.
+func (db *Database) GetMember(ctx context.Context, organizationID, userID uint) (*organization.Member, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	model, err := models.OrganizationMembers(qm.Where("organization_id=? AND user_id=?", organizationID, userID)).One(ctx, db.db)
+	if err == sql.ErrNoRows {
+		return nil, organization.ErrMemberNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return memberModelToStorage(model), nil
+}
+
+// GetMembers gets the members of an organization, ordered by when they
+// joined.
+func (db *Database) GetMembers(ctx context.Context, organizationID uint) ([]*organization.Member, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	modelMembers, err := models.OrganizationMembers(
+		qm.Where("organization_id=?", organizationID),
+		qm.OrderBy("created_at ASC, user_id ASC"),
+	).All(ctx, db.db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build members slice.
+	members := []*organization.Member{}
+	for _, mm := range modelMembers {
+		members = append(members, memberModelToStorage(mm))
+	}
+
+	return members, nil
+}
+
+// membershipRow defines a row of the memberships query.
+type membershipRow struct {
+	ID        uint      `boil:"id"`
+	Name      string    `boil:"name"`
+	CreatedAt time.Time `boil:"created_at"`
+	Role      string    `boil:"role"`
+}
+
+// GetByUserID gets the organizations a user is a member of, along with their
+// role in each, ordered by ID.
+func (db *Database) GetByUserID(ctx context.Context, userID uint) ([]*organization.Membership, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	query := `SELECT o.id, o.name, o.created_at, m.role
+		FROM organization_members m
+		JOIN organizations o ON o.id = m.organization_id
+		WHERE m.user_id = ?
+		ORDER BY o.id ASC`
+
+	// Get from database.
+	var rows []*membershipRow
+	err := queries.Raw(query, userID).Bind(ctx, db.db, &rows)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// Build memberships slice.
+	memberships := []*organization.Membership{}
+	for _, row := range rows {
+		memberships = append(memberships, &organization.Membership{
+			Organization: organization.Organization{
+				ID:        row.ID,
+				Name:      row.Name,
+				CreatedAt: row.CreatedAt,
+			},
+			Role: row.Role,
+		})
+	}
+
+	return memberships, nil
+}
+
+// UpdateMember updates an organization member.
+func (db *Database) UpdateMember(ctx context.Context, m *organization.Member) (*organization.Member, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Map to model.
+	model := models.OrganizationMember{
+		OrganizationID: m.OrganizationID,
+		UserID:         m.UserID,
+		Role:           models.OrganizationMembersRole(m.Role),
+		CreatedAt:      m.CreatedAt,
+	}
+
+	// Update in database.
+	_, err := model.Update(ctx, db.db, boil.Infer())
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// DeleteMember deletes an organization member.
+func (db *Database) DeleteMember(ctx context.Context, organizationID, userID uint) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	_, err := models.OrganizationMembers(qm.Where("organization_id=? AND user_id=?", organizationID, userID)).DeleteAll(ctx, db.db)
+
+	return err
+}
+
+// CreateInvitation creates a new organization invitation.
+func (db *Database) CreateInvitation(ctx context.Context, i *organization.Invitation) (*organization.Invitation, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Map to model.
+	model := models.OrganizationInvitation{
+		Hash:           i.Hash,
+		OrganizationID: i.OrganizationID,
+		Email:          i.Email,
+		Role:           models.OrganizationInvitationsRole(i.Role),
+		InvitedBy:      i.InvitedBy,
+		ExpiresAt:      i.ExpiresAt,
+		CreatedAt:      i.CreatedAt,
+	}
+
+	// Insert into database.
+	err := model.Insert(ctx, db.db, boil.Infer())
+	if err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// GetInvitationByHash gets an organization invitation by the given hash.
+func (db *Database) GetInvitationByHash(ctx context.Context, hash string) (*organization.Invitation, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	model, err := models.OrganizationInvitations(qm.Where("hash=?", hash)).One(ctx, db.db)
+	if err == sql.ErrNoRows {
+		return nil, organization.ErrInvitationNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Map to invitation type.
+	i := &organization.Invitation{
+		Hash:           model.Hash,
+		OrganizationID: model.OrganizationID,
+		Email:          model.Email,
+		Role:           model.Role.String(),
+		InvitedBy:      model.InvitedBy,
+		ExpiresAt:      model.ExpiresAt,
+		CreatedAt:      model.CreatedAt,
+	}
+
+	return i, nil
+}
+
+// DeleteInvitation deletes an organization invitation.
+//
+// If the invitation does not exist, ErrInvitationNotFound is returned. Only
+// one caller can delete an invitation, so this is used to make invitations
+// single-use.
+func (db *Database) DeleteInvitation(ctx context.Context, hash string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	rows, err := models.OrganizationInvitations(qm.Where("hash=?", hash)).DeleteAll(ctx, db.db)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return organization.ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+// memberModelToStorage handles mapping a member model to a storage member.
+func memberModelToStorage(model *models.OrganizationMember) *organization.Member {
+	return &organization.Member{
+		OrganizationID: model.OrganizationID,
+		UserID:         model.UserID,
+		Role:           model.Role.String(),
+		CreatedAt:      model.CreatedAt,
+	}
+}
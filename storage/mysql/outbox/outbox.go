@@ -0,0 +1,155 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/mysql/models"
+	"dddstructure/storage/outbox"
+
+	"github.com/google/uuid"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout.
+func New(db *sql.DB, timeout time.Duration) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+	}
+}
+
+// Claim claims up to the given number of events waiting to be published,
+// oldest first, until the given time. Events claimed by another relay are
+// skipped until their claim ends.
+func (db *Database) Claim(ctx context.Context, limit uint, until time.Time) ([]*outbox.Event, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// The events are claimed in a single statement, which locks the rows it
+	// changes, then read back by the ID of the claim. They are ordered by
+	// the AUTO_INCREMENT sequence rather than the creation time, which only
+	// has second precision.
+	claimID := uuid.New().String()
+	query := "UPDATE outbox_events SET claim_id=?, available_at=? WHERE dead_at IS NULL AND (available_at IS NULL OR available_at<=?) ORDER BY sequence ASC LIMIT ?"
+	if _, err := db.db.ExecContext(ctx, query, claimID, until.UTC(), time.Now().UTC(), limit); err != nil {
+		return nil, err
+	}
+
+	return db.query(ctx, qm.Where("claim_id=?", claimID), qm.OrderBy("sequence ASC"))
+}
+
+// Delete deletes an event once it is published.
+func (db *Database) Delete(ctx context.Context, id string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	count, err := models.OutboxEvents(qm.Where("id=?", id)).DeleteAll(ctx, db.db)
+	if err != nil {
+		return err
+	} else if count == 0 {
+		return outbox.ErrEventNotFound
+	}
+
+	return nil
+}
+
+// Fail records a failed attempt to publish an event, releasing its claim so
+// it is claimed again from the given time.
+func (db *Database) Fail(ctx context.Context, id, reason string, retryAt time.Time) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	query := "UPDATE outbox_events SET claim_id=NULL, available_at=?, attempts=attempts+1, last_error=? WHERE id=?"
+	return db.exec(ctx, query, retryAt.UTC(), reason, id)
+}
+
+// DeadLetter records a failed attempt to publish an event, which is never
+// claimed again.
+func (db *Database) DeadLetter(ctx context.Context, id, reason string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	query := "UPDATE outbox_events SET claim_id=NULL, dead_at=?, attempts=attempts+1, last_error=? WHERE id=?"
+	return db.exec(ctx, query, time.Now().UTC(), reason, id)
+}
+
+// GetDeadLetters gets up to the given number of dead events, oldest first.
+func (db *Database) GetDeadLetters(ctx context.Context, limit uint) ([]*outbox.Event, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	return db.query(ctx, qm.Where("dead_at IS NOT NULL"), qm.OrderBy("sequence ASC"), qm.Limit(int(limit)))
+}
+
+// query reads the events selected by the given query mods.
+func (db *Database) query(ctx context.Context, mods ...qm.QueryMod) ([]*outbox.Event, error) {
+	modelEvents, err := models.OutboxEvents(mods...).All(ctx, db.db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build events slice.
+	events := []*outbox.Event{}
+	for _, me := range modelEvents {
+		events = append(events, &outbox.Event{
+			ID:        me.ID,
+			Type:      me.Type,
+			Version:   me.Version,
+			Payload:   []byte(me.Payload),
+			CreatedAt: me.CreatedAt,
+			Attempts:  me.Attempts,
+			LastError: me.LastError.String,
+		})
+	}
+
+	return events, nil
+}
+
+// exec runs the given statement changing an event, returning
+// ErrEventNotFound if it changed nothing.
+func (db *Database) exec(ctx context.Context, query string, args ...interface{}) error {
+	res, err := db.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if count == 0 {
+		return outbox.ErrEventNotFound
+	}
+
+	return nil
+}
+
+// Insert inserts the given events with the given executor, which is the
+// transaction writing the change they record.
+func Insert(ctx context.Context, exec boil.ContextExecutor, events []*outbox.Event) error {
+	for _, e := range events {
+		model := models.OutboxEvent{
+			ID:        e.ID,
+			Type:      e.Type,
+			Version:   e.Version,
+			Payload:   e.Payload,
+			CreatedAt: e.CreatedAt,
+		}
+
+		if err := model.Insert(ctx, exec, boil.Infer()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package recoverycode
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/mysql/models"
+	"dddstructure/storage/recoverycode"
+
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout.
+func New(db *sql.DB, timeout time.Duration) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+	}
+}
+
+// Create creates a new recovery code.
+func (db *Database) Create(ctx context.Context, c *recoverycode.RecoveryCode) (*recoverycode.RecoveryCode, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Map to model.
+	model := models.RecoveryCode{
+		Hash:      c.Hash,
+		UserID:    c.UserID,
+		CreatedAt: c.CreatedAt,
+	}
+
+	// Insert into database.
+	err := model.Insert(ctx, db.db, boil.Infer())
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// GetByHash gets a recovery code by the given hash.
+func (db *Database) GetByHash(ctx context.Context, hash string) (*recoverycode.RecoveryCode, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	model, err := models.RecoveryCodes(qm.Where("hash=?", hash)).One(ctx, db.db)
+	if err == sql.ErrNoRows {
+		return nil, recoverycode.ErrRecoveryCodeNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Map to recovery code type.
+	c := &recoverycode.RecoveryCode{
+		Hash:      model.Hash,
+		UserID:    model.UserID,
+		CreatedAt: model.CreatedAt,
+	}
+
+	return c, nil
+}
+
+// Delete deletes a recovery code.
+//
+// If the recovery code does not exist, ErrRecoveryCodeNotFound is returned.
+// Only one caller can delete a code, so this is used to make codes
+// single-use.
+func (db *Database) Delete(ctx context.Context, hash string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	rows, err := models.RecoveryCodes(qm.Where("hash=?", hash)).DeleteAll(ctx, db.db)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return recoverycode.ErrRecoveryCodeNotFound
+	}
+
+	return nil
+}
+
+// DeleteByUserID deletes all recovery codes for a user.
+func (db *Database) DeleteByUserID(ctx context.Context, userID uint) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	_, err := models.RecoveryCodes(qm.Where("user_id=?", userID)).DeleteAll(ctx, db.db)
+
+	return err
+}
@@ -0,0 +1,220 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/report"
+
+	"github.com/volatiletech/sqlboiler/v4/queries"
+)
+
+// periodFormats maps each revenue interval to the SQL expression that
+// truncates a transaction created at datetime to the start of its period.
+var periodFormats = map[string]string{
+	report.RevenueIntervalDay:   "DATE_FORMAT(t.created_at, '%Y-%m-%d')",
+	report.RevenueIntervalWeek:  "DATE_FORMAT(DATE_SUB(t.created_at, INTERVAL WEEKDAY(t.created_at) DAY), '%Y-%m-%d')",
+	report.RevenueIntervalMonth: "DATE_FORMAT(t.created_at, '%Y-%m-01')",
+}
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout.
+func New(db *sql.DB, timeout time.Duration) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+	}
+}
+
+// agingRow defines a row of the aging query.
+type agingRow struct {
+	Currency  string `boil:"currency"`
+	Bucket    string `boil:"bucket"`
+	Count     uint   `boil:"count"`
+	AmountDue uint   `boil:"amount_due"`
+}
+
+// GetAging gets the amount due on unpaid invoices per currency and aging
+// bucket.
+func (db *Database) GetAging(ctx context.Context, params *report.AgingParams) ([]*report.AgingBucket, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	asOf := params.AsOf.Format("2006-01-02")
+
+	query := `SELECT currency,
+		CASE
+			WHEN DATEDIFF(?, due_date) <= 0 THEN '` + report.AgingBucketCurrent + `'
+			WHEN DATEDIFF(?, due_date) <= 30 THEN '` + report.AgingBucket1To30 + `'
+			WHEN DATEDIFF(?, due_date) <= 60 THEN '` + report.AgingBucket31To60 + `'
+			WHEN DATEDIFF(?, due_date) <= 90 THEN '` + report.AgingBucket61To90 + `'
+			ELSE '` + report.AgingBucket90Plus + `'
+		END AS bucket,
+		COUNT(*) AS count,
+		SUM(amount_due) AS amount_due
+		FROM invoices
+		WHERE organization_id=? AND status<>'paid' AND amount_due>0
+		GROUP BY currency, bucket
+		ORDER BY currency ASC`
+
+	// Get from database.
+	var rows []*agingRow
+	err := queries.Raw(query, asOf, asOf, asOf, asOf, params.OrganizationID).Bind(ctx, db.db, &rows)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// Build buckets slice.
+	buckets := []*report.AgingBucket{}
+	for _, row := range rows {
+		buckets = append(buckets, &report.AgingBucket{
+			Currency:  row.Currency,
+			Bucket:    row.Bucket,
+			Count:     row.Count,
+			AmountDue: row.AmountDue,
+		})
+	}
+
+	return buckets, nil
+}
+
+// revenueRow defines a row of the revenue query.
+type revenueRow struct {
+	Period    string `boil:"period"`
+	Currency  string `boil:"currency"`
+	Count     uint   `boil:"count"`
+	Collected uint   `boil:"collected"`
+	Refunded  uint   `boil:"refunded"`
+}
+
+// GetRevenue gets the revenue collected and refunded per period and
+// currency.
+//
+// Transactions take the currency of the invoice they belong to, so
+// transactions without an invoice are not counted.
+func (db *Database) GetRevenue(ctx context.Context, params *report.RevenueParams) ([]*report.Revenue, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	period, ok := periodFormats[params.Interval]
+	if !ok {
+		return nil, fmt.Errorf("unknown revenue interval %q", params.Interval)
+	}
+
+	// Handle get params.
+	where := []string{"t.organization_id=?", "t.status='approved'", "t.type IN ('sale', 'capture', 'refund')"}
+	args := []interface{}{params.OrganizationID}
+
+	if params.StartDate != nil {
+		where = append(where, "t.created_at>=?")
+		args = append(args, params.StartDate)
+	}
+	if params.EndDate != nil {
+		where = append(where, "t.created_at<=?")
+		args = append(args, params.EndDate)
+	}
+
+	query := `SELECT ` + period + ` AS period,
+		i.currency AS currency,
+		COUNT(*) AS count,
+		SUM(CASE WHEN t.type IN ('sale', 'capture') THEN t.amount_captured ELSE 0 END) AS collected,
+		SUM(CASE WHEN t.type='refund' THEN t.amount_captured ELSE 0 END) AS refunded
+		FROM transactions t
+		INNER JOIN invoices i ON i.id=t.invoice_id
+		WHERE ` + strings.Join(where, " AND ") + `
+		GROUP BY period, currency
+		ORDER BY period ASC, currency ASC`
+
+	// Get from database.
+	var rows []*revenueRow
+	err := queries.Raw(query, args...).Bind(ctx, db.db, &rows)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// Build revenue slice.
+	revenue := []*report.Revenue{}
+	for _, row := range rows {
+		t, err := time.Parse("2006-01-02", row.Period)
+		if err != nil {
+			return nil, err
+		}
+
+		revenue = append(revenue, &report.Revenue{
+			Period:    t,
+			Currency:  row.Currency,
+			Count:     row.Count,
+			Collected: row.Collected,
+			Refunded:  row.Refunded,
+		})
+	}
+
+	return revenue, nil
+}
+
+// balanceRow defines a row of the balances query.
+type balanceRow struct {
+	Currency    string `boil:"currency"`
+	Count       uint   `boil:"count"`
+	Outstanding uint   `boil:"outstanding"`
+	Collected   uint   `boil:"collected"`
+}
+
+// GetBalances gets the outstanding and collected totals of invoices per
+// currency.
+func (db *Database) GetBalances(ctx context.Context, params *report.BalancesParams) ([]*report.Balance, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Handle get params.
+	where := []string{"organization_id=?"}
+	args := []interface{}{params.OrganizationID}
+
+	if params.StartDate != nil {
+		where = append(where, "created_at>=?")
+		args = append(args, params.StartDate)
+	}
+	if params.EndDate != nil {
+		where = append(where, "created_at<=?")
+		args = append(args, params.EndDate)
+	}
+
+	query := `SELECT currency,
+		COUNT(*) AS count,
+		SUM(CASE WHEN status<>'paid' THEN amount_due ELSE 0 END) AS outstanding,
+		SUM(amount_paid) AS collected
+		FROM invoices
+		WHERE ` + strings.Join(where, " AND ") + `
+		GROUP BY currency
+		ORDER BY currency ASC`
+
+	// Get from database.
+	var rows []*balanceRow
+	err := queries.Raw(query, args...).Bind(ctx, db.db, &rows)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// Build balances slice.
+	balances := []*report.Balance{}
+	for _, row := range rows {
+		balances = append(balances, &report.Balance{
+			Currency:    row.Currency,
+			Count:       row.Count,
+			Outstanding: row.Outstanding,
+			Collected:   row.Collected,
+		})
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,224 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/mysql/models"
+	"dddstructure/storage/session"
+
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout.
+func New(db *sql.DB, timeout time.Duration) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+	}
+}
+
+// Create creates a new session.
+func (db *Database) Create(ctx context.Context, s *session.Session) (*session.Session, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Map to model.
+	model := storageToModel(s)
+
+	// Insert into database.
+	err := model.Insert(ctx, db.db, boil.Infer())
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// GetByID gets a session by the given ID.
+func (db *Database) GetByID(ctx context.Context, id string) (*session.Session, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	model, err := models.Sessions(qm.Where("id=?", id)).One(ctx, db.db)
+	if err == sql.ErrNoRows {
+		return nil, session.ErrSessionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return modelToStorage(model), nil
+}
+
+// GetByUserID gets the sessions of a user, most recently used first.
+func (db *Database) GetByUserID(ctx context.Context, userID uint) ([]*session.Session, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	modelSessions, err := models.Sessions(
+		qm.Where("user_id=?", userID),
+		qm.OrderBy("last_used_at DESC, id ASC"),
+	).All(ctx, db.db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build sessions slice.
+	sessions := []*session.Session{}
+	for _, ms := range modelSessions {
+		sessions = append(sessions, modelToStorage(ms))
+	}
+
+	return sessions, nil
+}
+
+// Update updates a session.
+func (db *Database) Update(ctx context.Context, s *session.Session) (*session.Session, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Map to model.
+	model := storageToModel(s)
+
+	// Update in database.
+	_, err := model.Update(ctx, db.db, boil.Infer())
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Delete deletes a session and its refresh tokens.
+func (db *Database) Delete(ctx context.Context, id string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	if _, err := models.RefreshTokens(qm.Where("session_id=?", id)).DeleteAll(ctx, db.db); err != nil {
+		return err
+	}
+
+	_, err := models.Sessions(qm.Where("id=?", id)).DeleteAll(ctx, db.db)
+
+	return err
+}
+
+// DeleteByUserID deletes all sessions of a user and their refresh tokens.
+func (db *Database) DeleteByUserID(ctx context.Context, userID uint) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	if _, err := models.RefreshTokens(qm.Where("session_id IN (SELECT id FROM sessions WHERE user_id=?)", userID)).DeleteAll(ctx, db.db); err != nil {
+		return err
+	}
+
+	_, err := models.Sessions(qm.Where("user_id=?", userID)).DeleteAll(ctx, db.db)
+
+	return err
+}
+
+// CreateRefreshToken creates a new refresh token.
+func (db *Database) CreateRefreshToken(ctx context.Context, t *session.RefreshToken) (*session.RefreshToken, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Map to model.
+	model := models.RefreshToken{
+		Hash:      t.Hash,
+		SessionID: t.SessionID,
+		UsedAt:    null.TimeFromPtr(t.UsedAt),
+		CreatedAt: t.CreatedAt,
+	}
+
+	// Insert into database.
+	err := model.Insert(ctx, db.db, boil.Infer())
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// GetRefreshTokenByHash gets a refresh token by the given hash.
+func (db *Database) GetRefreshTokenByHash(ctx context.Context, hash string) (*session.RefreshToken, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	model, err := models.RefreshTokens(qm.Where("hash=?", hash)).One(ctx, db.db)
+	if err == sql.ErrNoRows {
+		return nil, session.ErrRefreshTokenNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Map to refresh token type.
+	t := &session.RefreshToken{
+		Hash:      model.Hash,
+		SessionID: model.SessionID,
+		UsedAt:    model.UsedAt.Ptr(),
+		CreatedAt: model.CreatedAt,
+	}
+
+	return t, nil
+}
+
+// UseRefreshToken marks a refresh token as used.
+//
+// The token is only updated if it has not been used yet, otherwise
+// ErrRefreshTokenUsed is returned, so only one request can use a token.
+func (db *Database) UseRefreshToken(ctx context.Context, hash string, usedAt time.Time) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	rows, err := models.RefreshTokens(qm.Where("hash=? AND used_at IS NULL", hash)).UpdateAll(ctx, db.db, models.M{
+		"used_at": usedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return session.ErrRefreshTokenUsed
+	}
+
+	return nil
+}
+
+// storageToModel handles mapping a storage session type to a model session
+// type.
+func storageToModel(s *session.Session) models.Session {
+	return models.Session{
+		ID:         s.ID,
+		UserID:     s.UserID,
+		UserAgent:  s.UserAgent,
+		IPAddress:  s.IPAddress,
+		CreatedAt:  s.CreatedAt,
+		LastUsedAt: s.LastUsedAt,
+		ExpiresAt:  s.ExpiresAt,
+	}
+}
+
+// modelToStorage handles mapping a model session type to a storage session
+// type.
+func modelToStorage(m *models.Session) *session.Session {
+	return &session.Session{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		UserAgent:  m.UserAgent,
+		IPAddress:  m.IPAddress,
+		CreatedAt:  m.CreatedAt,
+		LastUsedAt: m.LastUsedAt,
+		ExpiresAt:  m.ExpiresAt,
+	}
+}
@@ -3,8 +3,13 @@ package transaction
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/idgen"
 	"dddstructure/storage/mysql/models"
+	mysqloutbox "dddstructure/storage/mysql/outbox"
+	"dddstructure/storage/outbox"
 	"dddstructure/storage/transaction"
 
 	"github.com/volatiletech/sqlboiler/v4/boil"
@@ -13,41 +18,82 @@ import (
 
 // Database defines the database.
 type Database struct {
-	db *sql.DB
+	db      *sql.DB
+	timeout time.Duration
+	ids     idgen.Generator
 }
 
-// New creates a new database.
-func New(db *sql.DB) *Database {
+// New creates a new database, where each query is cancelled after the
+// given timeout. The IDs of new transactions are taken from the given generator,
+// or assigned by the AUTO_INCREMENT column if it is nil.
+func New(db *sql.DB, timeout time.Duration, ids idgen.Generator) *Database {
 	return &Database{
-		db: db,
+		db:      db,
+		timeout: timeout,
+		ids:     ids,
 	}
 }
 
 // Create creates a new transaction.
-func (db *Database) Create(t *transaction.Transaction) (*transaction.Transaction, error) {
+func (db *Database) Create(ctx context.Context, t *transaction.Transaction) (*transaction.Transaction, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
 	// Map to model.
 	model := models.Transaction{
 		ID:             t.ID,
+		OrganizationID: t.OrganizationID,
 		UserID:         t.UserID,
 		Type:           models.TransactionsType(t.Type),
 		CardType:       t.CardType,
 		AmountCaptured: t.AmountCaptured,
 		InvoiceID:      t.InvoiceID,
 		Status:         models.TransactionsStatus(t.Status),
+		CreatedAt:      t.CreatedAt,
+	}
+
+	// Handle ID.
+	if model.ID == 0 && db.ids != nil {
+		model.ID = db.ids.NewID()
+	}
+
+	// Insert into database, along with the events of the new transaction.
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	// Insert into database.
-	err := model.Insert(context.Background(), db.db, boil.Infer())
+	err = model.Insert(ctx, tx, boil.Infer())
 	if err != nil {
 		return nil, err
 	}
 
+	// The ID is read back from the insert when assigned by the database.
+	t.ID = model.ID
+
+	events, err := outbox.TransactionEvents(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mysqloutbox.Insert(ctx, tx, events); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	return t, nil
 }
 
 // GetByID gets a transaction by the given ID.
-func (db *Database) GetByID(id uint) (*transaction.Transaction, error) {
-	modelt, err := models.Transactions(qm.Where("id=?", id)).One(context.Background(), db.db)
+func (db *Database) GetByID(ctx context.Context, id uint) (*transaction.Transaction, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	modelt, err := models.Transactions(qm.Where("id=?", id)).One(ctx, db.db)
 	if err == sql.ErrNoRows {
 		return nil, transaction.ErrTransactionNotFound
 	} else if err != nil {
@@ -57,12 +103,14 @@ func (db *Database) GetByID(id uint) (*transaction.Transaction, error) {
 	// Map to transaction type.
 	t := &transaction.Transaction{
 		ID:             modelt.ID,
+		OrganizationID: modelt.OrganizationID,
 		UserID:         modelt.UserID,
 		Type:           modelt.Type.String(),
 		CardType:       modelt.CardType,
 		AmountCaptured: modelt.AmountCaptured,
 		InvoiceID:      modelt.InvoiceID,
 		Status:         modelt.Status.String(),
+		CreatedAt:      modelt.CreatedAt,
 	}
 
 	return t, nil
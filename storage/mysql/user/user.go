@@ -3,47 +3,75 @@ package user
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/idgen"
 	"dddstructure/storage/mysql/models"
 	"dddstructure/storage/user"
 
+	"github.com/volatiletech/null/v8"
 	"github.com/volatiletech/sqlboiler/v4/boil"
 	"github.com/volatiletech/sqlboiler/v4/queries/qm"
 )
 
 // Database defines the database.
 type Database struct {
-	db *sql.DB
+	db      *sql.DB
+	timeout time.Duration
+	ids     idgen.Generator
 }
 
-// New creates a new database.
-func New(db *sql.DB) *Database {
+// New creates a new database, where each query is cancelled after the
+// given timeout. The IDs of new users are taken from the given generator,
+// or assigned by the AUTO_INCREMENT column if it is nil.
+func New(db *sql.DB, timeout time.Duration, ids idgen.Generator) *Database {
 	return &Database{
-		db: db,
+		db:      db,
+		timeout: timeout,
+		ids:     ids,
 	}
 }
 
 // Create creates a new user.
-func (db *Database) Create(u *user.User) (*user.User, error) {
+func (db *Database) Create(ctx context.Context, u *user.User) (*user.User, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
 	// Map to model.
 	model := models.User{
-		ID:       u.ID,
-		Email:    u.Email,
-		Password: u.Password,
+		ID:              u.ID,
+		Email:           u.Email,
+		Password:        u.Password,
+		EmailVerifiedAt: null.TimeFromPtr(u.EmailVerifiedAt),
+		TotpSecret:      null.NewString(u.TOTPSecret, u.TOTPSecret != ""),
+		TotpEnabledAt:   null.TimeFromPtr(u.TOTPEnabledAt),
+		TotpLastStep:    null.NewUint64(u.TOTPLastStep, u.TOTPLastStep != 0),
+	}
+
+	// Handle ID.
+	if model.ID == 0 && db.ids != nil {
+		model.ID = db.ids.NewID()
 	}
 
 	// Insert into database.
-	err := model.Insert(context.Background(), db.db, boil.Infer())
+	err := model.Insert(ctx, db.db, boil.Infer())
 	if err != nil {
 		return nil, err
 	}
 
+	// The ID is read back from the insert when assigned by the database.
+	u.ID = model.ID
+
 	return u, nil
 }
 
 // GetByID gets a user by the given ID.
-func (db *Database) GetByID(id uint) (*user.User, error) {
-	modelu, err := models.Users(qm.Where("id=?", id)).One(context.Background(), db.db)
+func (db *Database) GetByID(ctx context.Context, id uint) (*user.User, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	modelu, err := models.Users(qm.Where("id=?", id)).One(ctx, db.db)
 	if err == sql.ErrNoRows {
 		return nil, user.ErrUserNotFound
 	} else if err != nil {
@@ -52,17 +80,24 @@ func (db *Database) GetByID(id uint) (*user.User, error) {
 
 	// Map to user type.
 	u := &user.User{
-		ID:       modelu.ID,
-		Email:    modelu.Email,
-		Password: modelu.Password,
+		ID:              modelu.ID,
+		Email:           modelu.Email,
+		Password:        modelu.Password,
+		EmailVerifiedAt: modelu.EmailVerifiedAt.Ptr(),
+		TOTPSecret:      modelu.TotpSecret.String,
+		TOTPEnabledAt:   modelu.TotpEnabledAt.Ptr(),
+		TOTPLastStep:    modelu.TotpLastStep.Uint64,
 	}
 
 	return u, nil
 }
 
 // GetByEmail gets a user by the given email.
-func (db *Database) GetByEmail(email string) (*user.User, error) {
-	modelu, err := models.Users(qm.Where("email=?", email)).One(context.Background(), db.db)
+func (db *Database) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	modelu, err := models.Users(qm.Where("email=?", email)).One(ctx, db.db)
 	if err == sql.ErrNoRows {
 		return nil, user.ErrUserNotFound
 	} else if err != nil {
@@ -71,25 +106,36 @@ func (db *Database) GetByEmail(email string) (*user.User, error) {
 
 	// Map to user type.
 	u := &user.User{
-		ID:       modelu.ID,
-		Email:    modelu.Email,
-		Password: modelu.Password,
+		ID:              modelu.ID,
+		Email:           modelu.Email,
+		Password:        modelu.Password,
+		EmailVerifiedAt: modelu.EmailVerifiedAt.Ptr(),
+		TOTPSecret:      modelu.TotpSecret.String,
+		TOTPEnabledAt:   modelu.TotpEnabledAt.Ptr(),
+		TOTPLastStep:    modelu.TotpLastStep.Uint64,
 	}
 
 	return u, nil
 }
 
 // Update updates an invoice.
-func (db *Database) Update(u *user.User) (*user.User, error) {
+func (db *Database) Update(ctx context.Context, u *user.User) (*user.User, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
 	// Map to model.
 	model := models.User{
-		ID:       u.ID,
-		Email:    u.Email,
-		Password: u.Password,
+		ID:              u.ID,
+		Email:           u.Email,
+		Password:        u.Password,
+		EmailVerifiedAt: null.TimeFromPtr(u.EmailVerifiedAt),
+		TotpSecret:      null.NewString(u.TOTPSecret, u.TOTPSecret != ""),
+		TotpEnabledAt:   null.TimeFromPtr(u.TOTPEnabledAt),
+		TotpLastStep:    null.NewUint64(u.TOTPLastStep, u.TOTPLastStep != 0),
 	}
 
 	// Update in database.
-	_, err := model.Update(context.Background(), db.db, boil.Infer())
+	_, err := model.Update(ctx, db.db, boil.Infer())
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,106 @@
+package usertoken
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/mysql/models"
+	"dddstructure/storage/usertoken"
+
+	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout.
+func New(db *sql.DB, timeout time.Duration) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+	}
+}
+
+// Create creates a new user token.
+func (db *Database) Create(ctx context.Context, t *usertoken.UserToken) (*usertoken.UserToken, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Map to model.
+	model := models.UserToken{
+		Hash:      t.Hash,
+		UserID:    t.UserID,
+		Type:      models.UserTokensType(t.Type),
+		ExpiresAt: t.ExpiresAt,
+		CreatedAt: t.CreatedAt,
+	}
+
+	// Insert into database.
+	err := model.Insert(ctx, db.db, boil.Infer())
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// GetByHash gets a user token by the given hash.
+func (db *Database) GetByHash(ctx context.Context, hash string) (*usertoken.UserToken, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	model, err := models.UserTokens(qm.Where("hash=?", hash)).One(ctx, db.db)
+	if err == sql.ErrNoRows {
+		return nil, usertoken.ErrUserTokenNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Map to user token type.
+	t := &usertoken.UserToken{
+		Hash:      model.Hash,
+		UserID:    model.UserID,
+		Type:      model.Type.String(),
+		ExpiresAt: model.ExpiresAt,
+		CreatedAt: model.CreatedAt,
+	}
+
+	return t, nil
+}
+
+// Delete deletes a user token.
+//
+// If the user token does not exist, ErrUserTokenNotFound is returned. Only
+// one caller can delete a token, so this is used to make tokens single-use.
+func (db *Database) Delete(ctx context.Context, hash string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	rows, err := models.UserTokens(qm.Where("hash=?", hash)).DeleteAll(ctx, db.db)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return usertoken.ErrUserTokenNotFound
+	}
+
+	return nil
+}
+
+// DeleteByUserID deletes all user tokens of the given type for a user.
+func (db *Database) DeleteByUserID(ctx context.Context, userID uint, tokenType string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	_, err := models.UserTokens(qm.Where("user_id=? AND type=?", userID, tokenType)).DeleteAll(ctx, db.db)
+
+	return err
+}
@@ -0,0 +1,17 @@
+package organization
+
+import "errors"
+
+var (
+	// ErrOrganizationNotFound is returned when an organization could not be
+	// found.
+	ErrOrganizationNotFound = errors.New("organization not found")
+
+	// ErrMemberNotFound is returned when an organization member could not be
+	// found.
+	ErrMemberNotFound = errors.New("organization member not found")
+
+	// ErrInvitationNotFound is returned when an organization invitation could
+	// not be found.
+	ErrInvitationNotFound = errors.New("organization invitation not found")
+)
@@ -0,0 +1,61 @@
+package organization
+
+import (
+	"context"
+	"time"
+)
+
+// Database defines the organization database interface.
+//
+// Members and invitations belong to a single organization, so they are
+// stored through the same interface. Create stores an organization along
+// with its owner in a single transaction, so there is never an organization
+// without members.
+type Database interface {
+	Create(ctx context.Context, o *Organization, owner *Member) (*Organization, error)
+	GetByID(ctx context.Context, id uint) (*Organization, error)
+	CreateMember(ctx context.Context, m *Member) (*Member, error)
+	GetMember(ctx context.Context, organizationID, userID uint) (*Member, error)
+	GetMembers(ctx context.Context, organizationID uint) ([]*Member, error)
+	GetByUserID(ctx context.Context, userID uint) ([]*Membership, error)
+	UpdateMember(ctx context.Context, m *Member) (*Member, error)
+	DeleteMember(ctx context.Context, organizationID, userID uint) error
+	CreateInvitation(ctx context.Context, i *Invitation) (*Invitation, error)
+	GetInvitationByHash(ctx context.Context, hash string) (*Invitation, error)
+	DeleteInvitation(ctx context.Context, hash string) error
+}
+
+// Organization defines an organization.
+type Organization struct {
+	ID        uint
+	Name      string
+	CreatedAt time.Time
+}
+
+// Member defines the membership of a user in an organization.
+type Member struct {
+	OrganizationID uint
+	UserID         uint
+	Role           string
+	CreatedAt      time.Time
+}
+
+// Membership defines an organization a user is a member of, along with their
+// role in it.
+type Membership struct {
+	Organization
+	Role string
+}
+
+// Invitation defines a single-use invitation to join an organization.
+//
+// Only the SHA-256 hash of the invitation token is stored.
+type Invitation struct {
+	Hash           string
+	OrganizationID uint
+	Email          string
+	Role           string
+	InvitedBy      uint
+	ExpiresAt      time.Time
+	CreatedAt      time.Time
+}
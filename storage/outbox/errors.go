@@ -0,0 +1,8 @@
+package outbox
+
+import "errors"
+
+var (
+	// ErrEventNotFound is returned when an outbox event could not be found.
+	ErrEventNotFound = errors.New("outbox event not found")
+)
@@ -0,0 +1,123 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"dddstructure/storage/invoice"
+	"dddstructure/storage/transaction"
+
+	"github.com/google/uuid"
+)
+
+// Payload versions. A version is only bumped when a field is removed or
+// changes meaning, as consumers ignore fields they do not know.
+const (
+	InvoicePayloadVersion     = 1
+	TransactionPayloadVersion = 1
+)
+
+// InvoicePayload defines the payload of the invoice events.
+type InvoicePayload struct {
+	ID             uint      `json:"id"`
+	OrganizationID uint      `json:"organization_id"`
+	InvoiceNumber  string    `json:"invoice_number"`
+	Currency       string    `json:"currency"`
+	AmountDue      uint      `json:"amount_due"`
+	AmountPaid     uint      `json:"amount_paid"`
+	Status         string    `json:"status"`
+	DueDate        string    `json:"due_date"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TransactionPayload defines the payload of the transaction events.
+type TransactionPayload struct {
+	ID             uint      `json:"id"`
+	OrganizationID uint      `json:"organization_id"`
+	InvoiceID      uint      `json:"invoice_id"`
+	Type           string    `json:"type"`
+	Amount         uint      `json:"amount"`
+	Status         string    `json:"status"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// InvoiceEvents returns the events recording an invoice changing from old to
+// i, where old is nil if the invoice was just created.
+//
+// Every storage backend calls this with the invoice as it is written, inside
+// the same database transaction, so they all record the same events. Times
+// are rounded to the second as the databases store them.
+func InvoiceEvents(old, i *invoice.Invoice) ([]*Event, error) {
+	var types []string
+	if old == nil {
+		types = append(types, TypeInvoiceCreated)
+	}
+	if i.Status == "paid" && (old == nil || old.Status != "paid") {
+		types = append(types, TypeInvoicePaid)
+	}
+
+	payload := &InvoicePayload{
+		ID:             i.ID,
+		OrganizationID: i.OrganizationID,
+		InvoiceNumber:  i.InvoiceNumber,
+		Currency:       i.Currency,
+		AmountDue:      i.AmountDue,
+		AmountPaid:     i.AmountPaid,
+		Status:         i.Status,
+		DueDate:        i.DueDate.UTC().Format("2006-01-02"),
+		CreatedAt:      i.CreatedAt.UTC().Round(time.Second),
+	}
+
+	return newEvents(types, InvoicePayloadVersion, payload)
+}
+
+// TransactionEvents returns the events recording a transaction being
+// created.
+func TransactionEvents(t *transaction.Transaction) ([]*Event, error) {
+	var types []string
+	if t.Type == "refund" && t.Status == "approved" {
+		types = append(types, TypeTransactionRefunded)
+	}
+
+	payload := &TransactionPayload{
+		ID:             t.ID,
+		OrganizationID: t.OrganizationID,
+		InvoiceID:      t.InvoiceID,
+		Type:           t.Type,
+		Amount:         t.AmountCaptured,
+		Status:         t.Status,
+		CreatedAt:      t.CreatedAt.UTC().Round(time.Second),
+	}
+
+	return newEvents(types, TransactionPayloadVersion, payload)
+}
+
+// newEvents returns a new event of each of the given types, sharing the
+// given payload.
+func newEvents(types []string, version uint, payload interface{}) ([]*Event, error) {
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// Events are stored with datetimes rounded to the second, like every
+	// other record.
+	now := time.Now().UTC().Round(time.Second)
+
+	events := make([]*Event, 0, len(types))
+	for _, t := range types {
+		events = append(events, &Event{
+			ID:        uuid.New().String(),
+			Type:      t,
+			Version:   version,
+			Payload:   b,
+			CreatedAt: now,
+		})
+	}
+
+	return events, nil
+}
@@ -0,0 +1,48 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+// Database defines the outbox database interface.
+//
+// Events are not created through this interface. They are written by the
+// invoice and transaction databases in the same database transaction as the
+// change they record, so an event exists if and only if its change does.
+//
+// Relays claim the events they publish until a lease ends, so several relays
+// share the outbox without publishing the same events at once. An event
+// whose relay stops before deleting it is claimed again once its lease ends.
+type Database interface {
+	Claim(ctx context.Context, limit uint, until time.Time) ([]*Event, error)
+	Delete(ctx context.Context, id string) error
+	Fail(ctx context.Context, id, reason string, retryAt time.Time) error
+	DeadLetter(ctx context.Context, id, reason string) error
+	GetDeadLetters(ctx context.Context, limit uint) ([]*Event, error)
+}
+
+// Event types.
+const (
+	TypeInvoiceCreated      = "invoice.created"
+	TypeInvoicePaid         = "invoice.paid"
+	TypeTransactionRefunded = "transaction.refunded"
+)
+
+// Event defines a domain event waiting to be published.
+//
+// The ID is unique to the event and kept when it is published again, so
+// consumers can drop events they have already seen. The payload is JSON,
+// whose layout is given by the type and version.
+//
+// Attempts counts the failed attempts to publish the event, and LastError
+// holds the reason the last one failed.
+type Event struct {
+	ID        string
+	Type      string
+	Version   uint
+	Payload   []byte
+	CreatedAt time.Time
+	Attempts  uint
+	LastError string
+}
@@ -0,0 +1,79 @@
+package loginattempt
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/loginattempt"
+	"dddstructure/storage/postgres/timestamp"
+)
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout.
+func New(db *sql.DB, timeout time.Duration) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+	}
+}
+
+// Get gets the failed login attempts for the given key.
+func (db *Database) Get(ctx context.Context, key string) (*loginattempt.LoginAttempt, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	a := &loginattempt.LoginAttempt{}
+	err := db.db.QueryRowContext(ctx, "SELECT attempt_key, failures, last_failed_at FROM login_attempts WHERE attempt_key=$1", key).
+		Scan(&a.Key, &a.Failures, timestamp.Scan(&a.LastFailedAt))
+	if err == sql.ErrNoRows {
+		return nil, loginattempt.ErrLoginAttemptNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// RecordFailure adds a failed login attempt for the given key, returning the
+// updated attempts.
+//
+// The count starts over if the last failure was before resetBefore. This is
+// done in a single statement, which also returns the updated row, so failures
+// at the same time are all counted.
+func (db *Database) RecordFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (*loginattempt.LoginAttempt, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	query := `INSERT INTO login_attempts (attempt_key, failures, last_failed_at) VALUES ($1, 1, $2)
+		ON CONFLICT (attempt_key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failed_at < $3 THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failed_at = EXCLUDED.last_failed_at
+		RETURNING attempt_key, failures, last_failed_at`
+
+	a := &loginattempt.LoginAttempt{}
+	err := db.db.QueryRowContext(ctx, query, key, timestamp.Value(failedAt), timestamp.Value(resetBefore)).
+		Scan(&a.Key, &a.Failures, timestamp.Scan(&a.LastFailedAt))
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Delete deletes the failed login attempts for the given key.
+func (db *Database) Delete(ctx context.Context, key string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	_, err := db.db.ExecContext(ctx, "DELETE FROM login_attempts WHERE attempt_key=$1", key)
+
+	return err
+}
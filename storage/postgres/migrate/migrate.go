@@ -0,0 +1,84 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"dddstructure/db/migrate"
+)
+
+// lockName defines the name of the Postgres advisory lock held while
+// migrating, so only one deploy migrates the database at a time.
+const lockName = "dddstructure_migrate"
+
+// lockRetry defines how often the lock is tried while another migration
+// holds it.
+const lockRetry = 100 * time.Millisecond
+
+// createTable creates the table recording the applied migrations.
+const createTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version integer NOT NULL PRIMARY KEY,
+	name varchar(255) NOT NULL,
+	applied_at timestamp(0) NOT NULL
+)`
+
+// Driver defines the Postgres driver, which implements the migrate.Driver
+// interface.
+type Driver struct{}
+
+// New creates a new migrator of the given Postgres database, as migrate.New
+// does.
+func New(db *sql.DB, migrations []*migrate.Migration, lockTimeout time.Duration, l *slog.Logger) *migrate.Migrator {
+	return migrate.New(db, Driver{}, migrations, lockTimeout, l)
+}
+
+// Lock takes the Postgres advisory lock, which belongs to the session, so it
+// is also released if the process dies. Postgres can not wait for an
+// advisory lock with a timeout, so it is tried until the timeout passes.
+func (Driver) Lock(ctx context.Context, conn *sql.Conn, timeout time.Duration) error {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	for {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", lockName).Scan(&locked); err != nil {
+			return err
+		}
+		if locked {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			return migrate.ErrLocked
+		case <-time.After(lockRetry):
+		}
+	}
+}
+
+// Unlock releases the Postgres advisory lock.
+func (Driver) Unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", lockName)
+	return err
+}
+
+// CreateTable returns the statement creating the schema_migrations table.
+func (Driver) CreateTable() string {
+	return createTable
+}
+
+// Placeholder returns the numbered Postgres placeholder, such as "$1".
+func (Driver) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// Transactional returns true, as Postgres can roll back schema changes, so
+// a migration that fails part way changes nothing.
+func (Driver) Transactional() bool {
+	return true
+}
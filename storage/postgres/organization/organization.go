@@ -0,0 +1,281 @@
+package organization
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/idgen"
+	"dddstructure/storage/organization"
+	"dddstructure/storage/postgres/timestamp"
+)
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+	ids     idgen.Generator
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout. The IDs of new organizations are taken from the given generator,
+// or assigned by the identity column if it is nil.
+func New(db *sql.DB, timeout time.Duration, ids idgen.Generator) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+		ids:     ids,
+	}
+}
+
+// Create creates a new organization, with the given member as its owner.
+func (db *Database) Create(ctx context.Context, o *organization.Organization, owner *organization.Member) (*organization.Organization, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Handle ID.
+	id := o.ID
+	if id == 0 && db.ids != nil {
+		id = db.ids.NewID()
+	}
+
+	// Insert into database, along with the owner.
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// An ID of NULL is assigned by the identity column. The ID is returned
+	// by the insert in either case.
+	created := *o
+	err = tx.QueryRowContext(ctx, "INSERT INTO organizations (id, name, created_at) VALUES (COALESCE($1, nextval(pg_get_serial_sequence('organizations', 'id'))), $2, $3) RETURNING id",
+		sql.NullInt64{Int64: int64(id), Valid: id != 0},
+		o.Name,
+		timestamp.Value(o.CreatedAt),
+	).Scan(&created.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO organization_members (organization_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)",
+		created.ID,
+		owner.UserID,
+		owner.Role,
+		timestamp.Value(owner.CreatedAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// GetByID gets an organization by the given ID.
+func (db *Database) GetByID(ctx context.Context, id uint) (*organization.Organization, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	o := &organization.Organization{}
+	err := db.db.QueryRowContext(ctx, "SELECT id, name, created_at FROM organizations WHERE id=$1", id).
+		Scan(&o.ID, &o.Name, timestamp.Scan(&o.CreatedAt))
+	if err == sql.ErrNoRows {
+		return nil, organization.ErrOrganizationNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// CreateMember creates a new organization member.
+func (db *Database) CreateMember(ctx context.Context, m *organization.Member) (*organization.Member, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Insert into database.
+	_, err := db.db.ExecContext(ctx, "INSERT INTO organization_members (organization_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)",
+		m.OrganizationID,
+		m.UserID,
+		m.Role,
+		timestamp.Value(m.CreatedAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// GetMember gets the member of an organization by the given user ID.
+func (db *Database) GetMember(ctx context.Context, organizationID, userID uint) (*organization.Member, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	m := &organization.Member{}
+	err := db.db.QueryRowContext(ctx, "SELECT organization_id, user_id, role, created_at FROM organization_members WHERE organization_id=$1 AND user_id=$2", organizationID, userID).
+		Scan(&m.OrganizationID, &m.UserID, &m.Role, timestamp.Scan(&m.CreatedAt))
+	if err == sql.ErrNoRows {
+		return nil, organization.ErrMemberNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// GetMembers gets the members of an organization, ordered by when they
+// joined.
+func (db *Database) GetMembers(ctx context.Context, organizationID uint) ([]*organization.Member, error) {
+	return db.getMembers(ctx, "SELECT organization_id, user_id, role, created_at FROM organization_members WHERE organization_id=$1 ORDER BY created_at ASC, user_id ASC", organizationID)
+}
+
+// GetByUserID gets the organizations a user is a member of, along with their
+// role in each, ordered by ID.
+func (db *Database) GetByUserID(ctx context.Context, userID uint) ([]*organization.Membership, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	rows, err := db.db.QueryContext(ctx, "SELECT o.id, o.name, o.created_at, m.role FROM organization_members m JOIN organizations o ON o.id=m.organization_id WHERE m.user_id=$1 ORDER BY o.id ASC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Build memberships slice.
+	memberships := []*organization.Membership{}
+	for rows.Next() {
+		m := &organization.Membership{}
+		if err := rows.Scan(&m.ID, &m.Name, timestamp.Scan(&m.CreatedAt), &m.Role); err != nil {
+			return nil, err
+		}
+
+		memberships = append(memberships, m)
+	}
+
+	return memberships, rows.Err()
+}
+
+// UpdateMember updates an organization member.
+func (db *Database) UpdateMember(ctx context.Context, m *organization.Member) (*organization.Member, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Update in database.
+	_, err := db.db.ExecContext(ctx, "UPDATE organization_members SET role=$1, created_at=$2 WHERE organization_id=$3 AND user_id=$4",
+		m.Role,
+		timestamp.Value(m.CreatedAt),
+		m.OrganizationID,
+		m.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// DeleteMember deletes an organization member.
+func (db *Database) DeleteMember(ctx context.Context, organizationID, userID uint) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	_, err := db.db.ExecContext(ctx, "DELETE FROM organization_members WHERE organization_id=$1 AND user_id=$2", organizationID, userID)
+
+	return err
+}
+
+// CreateInvitation creates a new organization invitation.
+func (db *Database) CreateInvitation(ctx context.Context, i *organization.Invitation) (*organization.Invitation, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Insert into database.
+	_, err := db.db.ExecContext(ctx, "INSERT INTO organization_invitations (hash, organization_id, email, role, invited_by, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		i.Hash,
+		i.OrganizationID,
+		i.Email,
+		i.Role,
+		i.InvitedBy,
+		timestamp.Value(i.ExpiresAt),
+		timestamp.Value(i.CreatedAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// GetInvitationByHash gets an organization invitation by the given hash.
+func (db *Database) GetInvitationByHash(ctx context.Context, hash string) (*organization.Invitation, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	i := &organization.Invitation{}
+	err := db.db.QueryRowContext(ctx, "SELECT hash, organization_id, email, role, invited_by, expires_at, created_at FROM organization_invitations WHERE hash=$1", hash).
+		Scan(&i.Hash, &i.OrganizationID, &i.Email, &i.Role, &i.InvitedBy, timestamp.Scan(&i.ExpiresAt), timestamp.Scan(&i.CreatedAt))
+	if err == sql.ErrNoRows {
+		return nil, organization.ErrInvitationNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// DeleteInvitation deletes an organization invitation.
+//
+// If the invitation does not exist, ErrInvitationNotFound is returned. Only
+// one caller can delete an invitation, so this is used to make invitations
+// single-use.
+func (db *Database) DeleteInvitation(ctx context.Context, hash string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	res, err := db.db.ExecContext(ctx, "DELETE FROM organization_invitations WHERE hash=$1", hash)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return organization.ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+// getMembers gets the members returned by the given query.
+func (db *Database) getMembers(ctx context.Context, query string, args ...interface{}) ([]*organization.Member, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	rows, err := db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Build members slice.
+	members := []*organization.Member{}
+	for rows.Next() {
+		m := &organization.Member{}
+		if err := rows.Scan(&m.OrganizationID, &m.UserID, &m.Role, timestamp.Scan(&m.CreatedAt)); err != nil {
+			return nil, err
+		}
+
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
@@ -0,0 +1,158 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/outbox"
+	"dddstructure/storage/postgres/timestamp"
+
+	"github.com/google/uuid"
+)
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout.
+func New(db *sql.DB, timeout time.Duration) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+	}
+}
+
+// Claim claims up to the given number of events waiting to be published,
+// oldest first, until the given time. Events claimed by another relay are
+// skipped until their claim ends.
+func (db *Database) Claim(ctx context.Context, limit uint, until time.Time) ([]*outbox.Event, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Rows another relay is claiming at the same time are skipped rather
+	// than waited for. The claimed events are read back by the ID of the
+	// claim, ordered by the identity sequence rather than the creation time,
+	// which only has second precision.
+	claimID := uuid.New().String()
+	_, err := db.db.ExecContext(ctx, "UPDATE outbox_events SET claim_id=$1, available_at=$2 WHERE sequence IN (SELECT sequence FROM outbox_events WHERE dead_at IS NULL AND (available_at IS NULL OR available_at<=$3) ORDER BY sequence ASC LIMIT $4 FOR UPDATE SKIP LOCKED)",
+		claimID,
+		timestamp.Value(until),
+		timestamp.Value(time.Now()),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.query(ctx, "SELECT id, type, version, payload, created_at, attempts, last_error FROM outbox_events WHERE claim_id=$1 ORDER BY sequence ASC", claimID)
+}
+
+// Delete deletes an event once it is published.
+func (db *Database) Delete(ctx context.Context, id string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	return db.exec(ctx, "DELETE FROM outbox_events WHERE id=$1", id)
+}
+
+// Fail records a failed attempt to publish an event, releasing its claim so
+// it is claimed again from the given time.
+func (db *Database) Fail(ctx context.Context, id, reason string, retryAt time.Time) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	return db.exec(ctx, "UPDATE outbox_events SET claim_id=NULL, available_at=$1, attempts=attempts+1, last_error=$2 WHERE id=$3",
+		timestamp.Value(retryAt),
+		reason,
+		id,
+	)
+}
+
+// DeadLetter records a failed attempt to publish an event, which is never
+// claimed again.
+func (db *Database) DeadLetter(ctx context.Context, id, reason string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	return db.exec(ctx, "UPDATE outbox_events SET claim_id=NULL, dead_at=$1, attempts=attempts+1, last_error=$2 WHERE id=$3",
+		timestamp.Value(time.Now()),
+		reason,
+		id,
+	)
+}
+
+// GetDeadLetters gets up to the given number of dead events, oldest first.
+func (db *Database) GetDeadLetters(ctx context.Context, limit uint) ([]*outbox.Event, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	return db.query(ctx, "SELECT id, type, version, payload, created_at, attempts, last_error FROM outbox_events WHERE dead_at IS NOT NULL ORDER BY sequence ASC LIMIT $1", limit)
+}
+
+// query reads the events selected by the given query.
+func (db *Database) query(ctx context.Context, query string, args ...interface{}) ([]*outbox.Event, error) {
+	rows, err := db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*outbox.Event{}
+	for rows.Next() {
+		e := &outbox.Event{}
+		var payload string
+		var lastError sql.NullString
+		if err := rows.Scan(&e.ID, &e.Type, &e.Version, &payload, timestamp.Scan(&e.CreatedAt), &e.Attempts, &lastError); err != nil {
+			return nil, err
+		}
+		e.Payload = []byte(payload)
+		e.LastError = lastError.String
+
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// exec runs the given statement changing an event, returning
+// ErrEventNotFound if it changed nothing.
+func (db *Database) exec(ctx context.Context, query string, args ...interface{}) error {
+	res, err := db.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	} else if count == 0 {
+		return outbox.ErrEventNotFound
+	}
+
+	return nil
+}
+
+// Insert inserts the given events in the given transaction, which is the one
+// writing the change they record.
+func Insert(ctx context.Context, tx *sql.Tx, events []*outbox.Event) error {
+	for _, e := range events {
+		// The payload is passed as text, as the driver sends bytes as bytea.
+		_, err := tx.ExecContext(ctx, "INSERT INTO outbox_events (id, type, version, payload, created_at) VALUES ($1, $2, $3, $4, $5)",
+			e.ID,
+			e.Type,
+			e.Version,
+			string(e.Payload),
+			timestamp.Value(e.CreatedAt),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
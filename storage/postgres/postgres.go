@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"database/sql"
+	"net/url"
+	"time"
+
+	"dddstructure/storage"
+	"dddstructure/storage/idgen"
+	"dddstructure/storage/postgres/health"
+	"dddstructure/storage/postgres/invoice"
+	"dddstructure/storage/postgres/loginattempt"
+	"dddstructure/storage/postgres/organization"
+	"dddstructure/storage/postgres/outbox"
+	"dddstructure/storage/postgres/recoverycode"
+	"dddstructure/storage/postgres/report"
+	"dddstructure/storage/postgres/session"
+	"dddstructure/storage/postgres/transaction"
+	"dddstructure/storage/postgres/user"
+	"dddstructure/storage/postgres/usertoken"
+)
+
+// DSN returns the connection string of a Postgres database for the lib/pq
+// driver, where an empty SSL mode uses the driver default of require.
+func DSN(host, port, name, user, pass, sslMode string) string {
+	u := &url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(user, pass),
+		Host:   host + ":" + port,
+		Path:   "/" + name,
+	}
+
+	if sslMode != "" {
+		u.RawQuery = url.Values{"sslmode": {sslMode}}.Encode()
+	}
+
+	return u.String()
+}
+
+// New returns a new implementation of storage.Storage that uses Postgres as
+// the backend database, with the schema of the db/postgres migrations. Each
+// query is cancelled after the given timeout, or only once its context is if
+// the timeout is zero.
+//
+// The IDs of new users, organizations, invoices and transactions are taken
+// from the given generator, or assigned by the database if it is nil. Unlike
+// MySQL, the identity columns do not skip past IDs taken from a generator,
+// so a database should keep to one or the other.
+func New(db *sql.DB, timeout time.Duration, ids idgen.Generator) *storage.Storage {
+	s := &storage.Storage{
+		User:         user.New(db, timeout, ids),
+		UserToken:    usertoken.New(db, timeout),
+		RecoveryCode: recoverycode.New(db, timeout),
+		LoginAttempt: loginattempt.New(db, timeout),
+		Session:      session.New(db, timeout),
+		Organization: organization.New(db, timeout, ids),
+		Invoice:      invoice.New(db, timeout, ids),
+		Transaction:  transaction.New(db, timeout, ids),
+		Report:       report.New(db, timeout),
+		Outbox:       outbox.New(db, timeout),
+		Health:       health.New(db, timeout),
+	}
+
+	return s
+}
@@ -0,0 +1,219 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/postgres/timestamp"
+	"dddstructure/storage/session"
+)
+
+// columns defines the columns of a session, in the order they are scanned.
+const columns = "id, user_id, user_agent, ip_address, created_at, last_used_at, expires_at"
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout.
+func New(db *sql.DB, timeout time.Duration) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+	}
+}
+
+// Create creates a new session.
+func (db *Database) Create(ctx context.Context, s *session.Session) (*session.Session, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Insert into database.
+	_, err := db.db.ExecContext(ctx, "INSERT INTO sessions ("+columns+") VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		s.ID,
+		s.UserID,
+		s.UserAgent,
+		s.IPAddress,
+		timestamp.Value(s.CreatedAt),
+		timestamp.Value(s.LastUsedAt),
+		timestamp.Value(s.ExpiresAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// GetByID gets a session by the given ID.
+func (db *Database) GetByID(ctx context.Context, id string) (*session.Session, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	s, err := scan(db.db.QueryRowContext(ctx, "SELECT "+columns+" FROM sessions WHERE id=$1", id))
+	if err == sql.ErrNoRows {
+		return nil, session.ErrSessionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// GetByUserID gets the sessions of a user, most recently used first.
+func (db *Database) GetByUserID(ctx context.Context, userID uint) ([]*session.Session, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	rows, err := db.db.QueryContext(ctx, "SELECT "+columns+" FROM sessions WHERE user_id=$1 ORDER BY last_used_at DESC, id ASC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Build sessions slice.
+	sessions := []*session.Session{}
+	for rows.Next() {
+		s, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Update updates a session.
+func (db *Database) Update(ctx context.Context, s *session.Session) (*session.Session, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Update in database.
+	_, err := db.db.ExecContext(ctx, "UPDATE sessions SET user_id=$1, user_agent=$2, ip_address=$3, created_at=$4, last_used_at=$5, expires_at=$6 WHERE id=$7",
+		s.UserID,
+		s.UserAgent,
+		s.IPAddress,
+		timestamp.Value(s.CreatedAt),
+		timestamp.Value(s.LastUsedAt),
+		timestamp.Value(s.ExpiresAt),
+		s.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Delete deletes a session and its refresh tokens.
+func (db *Database) Delete(ctx context.Context, id string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	if _, err := db.db.ExecContext(ctx, "DELETE FROM refresh_tokens WHERE session_id=$1", id); err != nil {
+		return err
+	}
+
+	_, err := db.db.ExecContext(ctx, "DELETE FROM sessions WHERE id=$1", id)
+
+	return err
+}
+
+// DeleteByUserID deletes all sessions of a user and their refresh tokens.
+func (db *Database) DeleteByUserID(ctx context.Context, userID uint) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	if _, err := db.db.ExecContext(ctx, "DELETE FROM refresh_tokens WHERE session_id IN (SELECT id FROM sessions WHERE user_id=$1)", userID); err != nil {
+		return err
+	}
+
+	_, err := db.db.ExecContext(ctx, "DELETE FROM sessions WHERE user_id=$1", userID)
+
+	return err
+}
+
+// CreateRefreshToken creates a new refresh token.
+func (db *Database) CreateRefreshToken(ctx context.Context, t *session.RefreshToken) (*session.RefreshToken, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Insert into database.
+	_, err := db.db.ExecContext(ctx, "INSERT INTO refresh_tokens (hash, session_id, used_at, created_at) VALUES ($1, $2, $3, $4)",
+		t.Hash,
+		t.SessionID,
+		timestamp.Null(t.UsedAt),
+		timestamp.Value(t.CreatedAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// GetRefreshTokenByHash gets a refresh token by the given hash.
+func (db *Database) GetRefreshTokenByHash(ctx context.Context, hash string) (*session.RefreshToken, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	t := &session.RefreshToken{}
+	err := db.db.QueryRowContext(ctx, "SELECT hash, session_id, used_at, created_at FROM refresh_tokens WHERE hash=$1", hash).
+		Scan(&t.Hash, &t.SessionID, timestamp.ScanNull(&t.UsedAt), timestamp.Scan(&t.CreatedAt))
+	if err == sql.ErrNoRows {
+		return nil, session.ErrRefreshTokenNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// UseRefreshToken marks a refresh token as used.
+//
+// The token is only updated if it has not been used yet, otherwise
+// ErrRefreshTokenUsed is returned, so only one request can use a token.
+func (db *Database) UseRefreshToken(ctx context.Context, hash string, usedAt time.Time) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	res, err := db.db.ExecContext(ctx, "UPDATE refresh_tokens SET used_at=$1 WHERE hash=$2 AND used_at IS NULL", timestamp.Value(usedAt), hash)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return session.ErrRefreshTokenUsed
+	}
+
+	return nil
+}
+
+// scanner defines a row that can be scanned, either a single row or one of
+// many rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scan scans a row of the session columns into a session.
+func scan(row scanner) (*session.Session, error) {
+	s := &session.Session{}
+	err := row.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IPAddress, timestamp.Scan(&s.CreatedAt), timestamp.Scan(&s.LastUsedAt), timestamp.Scan(&s.ExpiresAt))
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,81 @@
+package timestamp
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Value returns a time for a timestamp column, in UTC as the column has no
+// time zone.
+func Value(t time.Time) time.Time {
+	return t.UTC()
+}
+
+// Null returns a time for a nullable timestamp column, where a nil time is
+// stored as NULL.
+func Null(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// Date formats a time for a date column, keeping only its UTC date.
+func Date(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// Scan returns a destination scanning a timestamp or date column into t.
+//
+// The driver reads columns without a time zone into a fixed zone with no
+// offset, so the time is moved to UTC to be the same as every other backend.
+func Scan(t *time.Time) sql.Scanner {
+	return scanner{t: t}
+}
+
+// ScanNull returns a destination scanning a nullable timestamp column into t,
+// which is set to nil if it is NULL.
+func ScanNull(t **time.Time) sql.Scanner {
+	return nullScanner{t: t}
+}
+
+// scanner defines a timestamp scan destination.
+type scanner struct {
+	t *time.Time
+}
+
+// Scan implements sql.Scanner.
+func (s scanner) Scan(src interface{}) error {
+	var nt sql.NullTime
+	if err := nt.Scan(src); err != nil {
+		return err
+	}
+
+	*s.t = nt.Time.UTC()
+
+	return nil
+}
+
+// nullScanner defines a nullable timestamp scan destination.
+type nullScanner struct {
+	t **time.Time
+}
+
+// Scan implements sql.Scanner.
+func (s nullScanner) Scan(src interface{}) error {
+	var nt sql.NullTime
+	if err := nt.Scan(src); err != nil {
+		return err
+	}
+
+	if !nt.Valid {
+		*s.t = nil
+		return nil
+	}
+
+	t := nt.Time.UTC()
+	*s.t = &t
+
+	return nil
+}
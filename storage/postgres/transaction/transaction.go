@@ -0,0 +1,100 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/idgen"
+	"dddstructure/storage/outbox"
+	postgresoutbox "dddstructure/storage/postgres/outbox"
+	"dddstructure/storage/postgres/timestamp"
+	"dddstructure/storage/transaction"
+)
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+	ids     idgen.Generator
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout. The IDs of new transactions are taken from the given generator,
+// or assigned by the identity column if it is nil.
+func New(db *sql.DB, timeout time.Duration, ids idgen.Generator) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+		ids:     ids,
+	}
+}
+
+// Create creates a new transaction.
+func (db *Database) Create(ctx context.Context, t *transaction.Transaction) (*transaction.Transaction, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Handle ID.
+	id := t.ID
+	if id == 0 && db.ids != nil {
+		id = db.ids.NewID()
+	}
+
+	// Insert into database, where an ID of NULL is assigned by the identity
+	// column. The ID is returned by the insert in either case, and the events
+	// of the new transaction are inserted in the same database transaction.
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, "INSERT INTO transactions (id, organization_id, user_id, type, card_type, amount_captured, invoice_id, status, created_at) VALUES (COALESCE($1, nextval(pg_get_serial_sequence('transactions', 'id'))), $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id",
+		sql.NullInt64{Int64: int64(id), Valid: id != 0},
+		t.OrganizationID,
+		t.UserID,
+		t.Type,
+		t.CardType,
+		t.AmountCaptured,
+		t.InvoiceID,
+		t.Status,
+		timestamp.Value(t.CreatedAt),
+	).Scan(&t.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := outbox.TransactionEvents(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := postgresoutbox.Insert(ctx, tx, events); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// GetByID gets a transaction by the given ID.
+func (db *Database) GetByID(ctx context.Context, id uint) (*transaction.Transaction, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	t := &transaction.Transaction{}
+	err := db.db.QueryRowContext(ctx, "SELECT id, organization_id, user_id, type, card_type, amount_captured, invoice_id, status, created_at FROM transactions WHERE id=$1", id).
+		Scan(&t.ID, &t.OrganizationID, &t.UserID, &t.Type, &t.CardType, &t.AmountCaptured, &t.InvoiceID, &t.Status, timestamp.Scan(&t.CreatedAt))
+	if err == sql.ErrNoRows {
+		return nil, transaction.ErrTransactionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,98 @@
+package usertoken
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/postgres/timestamp"
+	"dddstructure/storage/usertoken"
+)
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout.
+func New(db *sql.DB, timeout time.Duration) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+	}
+}
+
+// Create creates a new user token.
+func (db *Database) Create(ctx context.Context, t *usertoken.UserToken) (*usertoken.UserToken, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Insert into database.
+	_, err := db.db.ExecContext(ctx, "INSERT INTO user_tokens (hash, user_id, type, expires_at, created_at) VALUES ($1, $2, $3, $4, $5)",
+		t.Hash,
+		t.UserID,
+		t.Type,
+		timestamp.Value(t.ExpiresAt),
+		timestamp.Value(t.CreatedAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// GetByHash gets a user token by the given hash.
+func (db *Database) GetByHash(ctx context.Context, hash string) (*usertoken.UserToken, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	t := &usertoken.UserToken{}
+	err := db.db.QueryRowContext(ctx, "SELECT hash, user_id, type, expires_at, created_at FROM user_tokens WHERE hash=$1", hash).
+		Scan(&t.Hash, &t.UserID, &t.Type, timestamp.Scan(&t.ExpiresAt), timestamp.Scan(&t.CreatedAt))
+	if err == sql.ErrNoRows {
+		return nil, usertoken.ErrUserTokenNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Delete deletes a user token.
+//
+// If the user token does not exist, ErrUserTokenNotFound is returned. Only
+// one caller can delete a token, so this is used to make tokens single-use.
+func (db *Database) Delete(ctx context.Context, hash string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	res, err := db.db.ExecContext(ctx, "DELETE FROM user_tokens WHERE hash=$1", hash)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return usertoken.ErrUserTokenNotFound
+	}
+
+	return nil
+}
+
+// DeleteByUserID deletes all user tokens of the given type for a user.
+func (db *Database) DeleteByUserID(ctx context.Context, userID uint, tokenType string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	_, err := db.db.ExecContext(ctx, "DELETE FROM user_tokens WHERE user_id=$1 AND type=$2", userID, tokenType)
+
+	return err
+}
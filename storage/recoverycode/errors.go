@@ -0,0 +1,9 @@
+package recoverycode
+
+import "errors"
+
+var (
+	// ErrRecoveryCodeNotFound is returned when a recovery code could not be
+	// found.
+	ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+)
@@ -0,0 +1,24 @@
+package recoverycode
+
+import (
+	"context"
+	"time"
+)
+
+// Database defines the recovery code database interface.
+type Database interface {
+	Create(ctx context.Context, c *RecoveryCode) (*RecoveryCode, error)
+	GetByHash(ctx context.Context, hash string) (*RecoveryCode, error)
+	Delete(ctx context.Context, hash string) error
+	DeleteByUserID(ctx context.Context, userID uint) error
+}
+
+// RecoveryCode defines a single-use two-factor authentication recovery code.
+//
+// Only the SHA-256 hash of a code is stored, so the codes themselves can't be
+// read back from the database.
+type RecoveryCode struct {
+	Hash      string
+	UserID    uint
+	CreatedAt time.Time
+}
@@ -0,0 +1,93 @@
+package report
+
+import (
+	"context"
+	"time"
+)
+
+// Database defines the report database interface.
+type Database interface {
+	GetAging(ctx context.Context, params *AgingParams) ([]*AgingBucket, error)
+	GetRevenue(ctx context.Context, params *RevenueParams) ([]*Revenue, error)
+	GetBalances(ctx context.Context, params *BalancesParams) ([]*Balance, error)
+}
+
+// Aging bucket names, by the number of days an invoice is past its due date.
+const (
+	AgingBucketCurrent = "current"
+	AgingBucket1To30   = "1_30"
+	AgingBucket31To60  = "31_60"
+	AgingBucket61To90  = "61_90"
+	AgingBucket90Plus  = "90_plus"
+)
+
+// Revenue intervals.
+const (
+	RevenueIntervalDay   = "day"
+	RevenueIntervalWeek  = "week"
+	RevenueIntervalMonth = "month"
+)
+
+// AgingParams defines the aging report parameters.
+//
+// Only unpaid invoices with an amount due are aged. AsOf is the date the
+// days past due are counted to.
+type AgingParams struct {
+	OrganizationID uint
+	AsOf           time.Time
+}
+
+// AgingBucket defines the unpaid invoices of a single currency that fall in a
+// single aging bucket.
+type AgingBucket struct {
+	Currency  string
+	Bucket    string
+	Count     uint
+	AmountDue uint
+}
+
+// RevenueParams defines the revenue report parameters.
+//
+// The start and end dates are inclusive, and filter transactions by their
+// created at datetime.
+type RevenueParams struct {
+	OrganizationID uint
+	Interval       string
+	StartDate      *time.Time
+	EndDate        *time.Time
+}
+
+// Revenue defines the revenue of a single currency within a single period.
+//
+// Collected is the total of approved sale and capture transactions, and
+// Refunded is the total of approved refund transactions. Period is the UTC
+// start of the day, the Monday of the week, or the first of the month.
+type Revenue struct {
+	Period    time.Time
+	Currency  string
+	Count     uint
+	Collected uint
+	Refunded  uint
+}
+
+// BalancesParams defines the balances report parameters.
+//
+// The start and end dates are inclusive, and filter invoices by their created
+// at datetime.
+type BalancesParams struct {
+	OrganizationID uint
+	StartDate      *time.Time
+	EndDate        *time.Time
+}
+
+// Balance defines the outstanding and collected totals of the invoices in a
+// single currency.
+//
+// Outstanding is the amount due on unpaid invoices, and Collected is the
+// amount paid on all invoices.
+type Balance struct {
+	Currency    string
+	Count       uint
+	Outstanding uint
+	Collected   uint
+}
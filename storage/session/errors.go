@@ -0,0 +1,16 @@
+package session
+
+import "errors"
+
+var (
+	// ErrSessionNotFound is returned when a session could not be found.
+	ErrSessionNotFound = errors.New("session not found")
+
+	// ErrRefreshTokenNotFound is returned when a refresh token could not be
+	// found.
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
+	// ErrRefreshTokenUsed is returned when a refresh token has already been
+	// used.
+	ErrRefreshTokenUsed = errors.New("refresh token already used")
+)
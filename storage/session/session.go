@@ -0,0 +1,45 @@
+package session
+
+import (
+	"context"
+	"time"
+)
+
+// Database defines the session database interface.
+//
+// Refresh tokens belong to a single session and are only ever used through
+// it, so they are stored through the same interface.
+type Database interface {
+	Create(ctx context.Context, s *Session) (*Session, error)
+	GetByID(ctx context.Context, id string) (*Session, error)
+	GetByUserID(ctx context.Context, userID uint) ([]*Session, error)
+	Update(ctx context.Context, s *Session) (*Session, error)
+	Delete(ctx context.Context, id string) error
+	DeleteByUserID(ctx context.Context, userID uint) error
+	CreateRefreshToken(ctx context.Context, t *RefreshToken) (*RefreshToken, error)
+	GetRefreshTokenByHash(ctx context.Context, hash string) (*RefreshToken, error)
+	UseRefreshToken(ctx context.Context, hash string, usedAt time.Time) error
+}
+
+// Session defines a login session.
+type Session struct {
+	ID         string
+	UserID     uint
+	UserAgent  string
+	IPAddress  string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+}
+
+// RefreshToken defines a session refresh token.
+//
+// Only the SHA-256 hash of a token is stored. A token is marked as used once
+// it has been exchanged for a new one, instead of being deleted, so any later
+// attempt to use it again can be detected.
+type RefreshToken struct {
+	Hash      string
+	SessionID string
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,46 @@
+package datetime
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Layouts of the datetime and date columns.
+const (
+	layoutDatetime = "2006-01-02 15:04:05"
+	layoutDate     = "2006-01-02"
+)
+
+// Format formats a time for a datetime column.
+//
+// Like MySQL datetimes, the time is stored in UTC and rounded to the second,
+// so every datetime has the same layout and they compare as text in the same
+// order as they do as times.
+func Format(t time.Time) string {
+	return t.UTC().Round(time.Second).Format(layoutDatetime)
+}
+
+// FormatDate formats a time for a date column, keeping only its UTC date.
+func FormatDate(t time.Time) string {
+	return t.UTC().Format(layoutDate)
+}
+
+// Null formats a time for a nullable datetime column, where a nil time is
+// stored as NULL.
+func Null(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: Format(*t), Valid: true}
+}
+
+// Ptr returns a pointer to the time read from a nullable datetime column, or
+// nil if it is NULL.
+func Ptr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+
+	return &t.Time
+}
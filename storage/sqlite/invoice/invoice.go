@@ -0,0 +1,518 @@
+package invoice
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/idgen"
+	"dddstructure/storage/invoice"
+	"dddstructure/storage/outbox"
+	"dddstructure/storage/sqlite/datetime"
+	sqliteoutbox "dddstructure/storage/sqlite/outbox"
+)
+
+// columns defines the columns of an invoice, in the order they are inserted
+// and scanned.
+var columns = []string{
+	"id", "organization_id", "user_id", "public_hash", "invoice_number",
+	"po_number", "currency", "due_date", "message",
+	"bill_to_first_name", "bill_to_last_name", "bill_to_company",
+	"bill_to_address_line_1", "bill_to_address_line_2", "bill_to_city",
+	"bill_to_state", "bill_to_postal_code", "bill_to_country",
+	"bill_to_email", "bill_to_phone",
+	"pay_to_first_name", "pay_to_last_name", "pay_to_company",
+	"pay_to_address_line_1", "pay_to_address_line_2", "pay_to_city",
+	"pay_to_state", "pay_to_postal_code", "pay_to_country",
+	"pay_to_email", "pay_to_phone",
+	"line_items", "payment_methods", "tax_rate", "amount_due",
+	"amount_paid", "status", "created_at",
+}
+
+// selectColumns selects every invoice column.
+var selectColumns = "SELECT `" + strings.Join(columns, "`, `") + "` FROM `invoices`"
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+	ids     idgen.Generator
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout. The IDs of new invoices are taken from the given generator,
+// or assigned by the AUTOINCREMENT column if it is nil.
+func New(db *sql.DB, timeout time.Duration, ids idgen.Generator) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+		ids:     ids,
+	}
+}
+
+// Create creates a new invoice.
+func (db *Database) Create(ctx context.Context, i *invoice.Invoice) (*invoice.Invoice, error) {
+	invoices, err := db.CreateBatch(ctx, []*invoice.Invoice{i})
+	if err != nil {
+		return nil, err
+	}
+
+	return invoices[0], nil
+}
+
+// CreateBatch creates new invoices in a single transaction, so either all of
+// them are created or none are.
+func (db *Database) CreateBatch(ctx context.Context, invoices []*invoice.Invoice) ([]*invoice.Invoice, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// The events of the new invoices are inserted in the same transaction.
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The invoices are copied, so the IDs of a batch that is rolled back
+	// are not left on them.
+	created := []*invoice.Invoice{}
+	for _, i := range invoices {
+		c := *i
+		if err := db.insert(ctx, tx, &c); err != nil {
+			return nil, err
+		}
+
+		created = append(created, &c)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// insert inserts an invoice and its events in the given transaction.
+func (db *Database) insert(ctx context.Context, tx *sql.Tx, i *invoice.Invoice) error {
+	// Map to row values.
+	values, err := storageToValues(i)
+	if err != nil {
+		return err
+	}
+
+	// Handle ID.
+	id := i.ID
+	if id == 0 && db.ids != nil {
+		id = db.ids.NewID()
+	}
+
+	// Insert into database, where an ID of NULL is assigned by the database.
+	values[0] = sql.NullInt64{Int64: int64(id), Valid: id != 0}
+
+	query := "INSERT INTO `invoices` (`" + strings.Join(columns, "`, `") + "`) VALUES (?" + strings.Repeat(", ?", len(columns)-1) + ")"
+	res, err := tx.ExecContext(ctx, query, values...)
+	if err != nil {
+		return err
+	}
+
+	// The ID is read back from the insert when assigned by the database.
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	i.ID = uint(lastID)
+
+	events, err := outbox.InvoiceEvents(nil, i)
+	if err != nil {
+		return err
+	}
+
+	return sqliteoutbox.Insert(ctx, tx, events)
+}
+
+// Get gets a set of invoices.
+func (db *Database) Get(ctx context.Context, params *invoice.GetParams) ([]*invoice.Invoice, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Handle get params.
+	where, args := getFilter(params)
+
+	// Handle cursor and ordering.
+	//
+	// Invoices are returned newest first. When paging before the cursor,
+	// the order is flipped so the limit applies to the invoices closest to
+	// the cursor, and the results are reversed back afterwards.
+	if params.Cursor != nil {
+		createdAt := datetime.Format(params.Cursor.CreatedAt)
+		if params.Cursor.Before {
+			where = append(where, "(`created_at`>? OR (`created_at`=? AND `id`>?))")
+		} else {
+			where = append(where, "(`created_at`<? OR (`created_at`=? AND `id`<?))")
+		}
+		args = append(args, createdAt, createdAt, params.Cursor.ID)
+	}
+
+	query := selectColumns + whereClause(where)
+
+	if params.Cursor != nil && params.Cursor.Before {
+		query += " ORDER BY `created_at` ASC, `id` ASC"
+	} else {
+		query += " ORDER BY `created_at` DESC, `id` DESC"
+	}
+
+	query += " LIMIT ? OFFSET ?"
+	args = append(args, params.Limit, params.Offset)
+
+	// Get from database.
+	rows, err := db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Build invoices slice.
+	invoices := []*invoice.Invoice{}
+	for rows.Next() {
+		i, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		invoices = append(invoices, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Restore newest first ordering.
+	if params.Cursor != nil && params.Cursor.Before {
+		for l, r := 0, len(invoices)-1; l < r; l, r = l+1, r-1 {
+			invoices[l], invoices[r] = invoices[r], invoices[l]
+		}
+	}
+
+	return invoices, nil
+}
+
+// GetCount gets the count of a set of invoices.
+func (db *Database) GetCount(ctx context.Context, params *invoice.GetParams) (uint, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Handle get params.
+	where, args := getFilter(params)
+
+	// Get from database.
+	var count uint
+	err := db.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM `invoices`"+whereClause(where), args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetByID gets an invoice by the given ID.
+func (db *Database) GetByID(ctx context.Context, id uint) (*invoice.Invoice, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	i, err := scan(db.db.QueryRowContext(ctx, selectColumns+" WHERE `id`=?", id))
+	if err == sql.ErrNoRows {
+		return nil, invoice.ErrInvoiceNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// GetByPublicHash gets an invoice by the given public hash.
+func (db *Database) GetByPublicHash(ctx context.Context, hash string) (*invoice.Invoice, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	i, err := scan(db.db.QueryRowContext(ctx, selectColumns+" WHERE `public_hash`=? LIMIT 1", hash))
+	if err == sql.ErrNoRows {
+		return nil, invoice.ErrInvoiceNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// Update updates an invoice.
+func (db *Database) Update(ctx context.Context, i *invoice.Invoice) (*invoice.Invoice, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Map to row values.
+	values, err := storageToValues(i)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every column but the ID is set, and the ID is moved to the end for
+	// the where clause.
+	set := make([]string, 0, len(columns)-1)
+	for _, c := range columns[1:] {
+		set = append(set, "`"+c+"`=?")
+	}
+	args := append(values[1:], i.ID)
+
+	// Update in database, along with the events of the change. SQLite
+	// allows one write at a time, so the status read is current until the
+	// commit.
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, "SELECT `status` FROM `invoices` WHERE `id`=?", i.ID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return i, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE `invoices` SET "+strings.Join(set, ", ")+" WHERE `id`=?", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := outbox.InvoiceEvents(&invoice.Invoice{Status: status}, i)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sqliteoutbox.Insert(ctx, tx, events); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// Delete deletes an invoice.
+func (db *Database) Delete(ctx context.Context, id uint) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	res, err := db.db.ExecContext(ctx, "DELETE FROM `invoices` WHERE `id`=?", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return invoice.ErrInvoiceNotFound
+	}
+
+	return nil
+}
+
+// getFilter returns the where conditions and their arguments filtering
+// invoices by the given get params, shared by Get and GetCount so they always
+// count the same invoices.
+func getFilter(params *invoice.GetParams) ([]string, []interface{}) {
+	var where []string
+	var args []interface{}
+
+	if params.ID != nil {
+		where = append(where, "`id`=?")
+		args = append(args, *params.ID)
+	}
+
+	if params.OrganizationID != nil {
+		where = append(where, "`organization_id`=?")
+		args = append(args, *params.OrganizationID)
+	}
+
+	if params.Status != nil {
+		where = append(where, "`status`=?")
+		args = append(args, *params.Status)
+	}
+
+	if params.CreatedAt != nil {
+		if params.CreatedAt.StartDate != nil {
+			where = append(where, "`created_at`>=?")
+			args = append(args, datetime.Format(*params.CreatedAt.StartDate))
+		}
+		if params.CreatedAt.EndDate != nil {
+			where = append(where, "`created_at`<=?")
+			args = append(args, datetime.Format(*params.CreatedAt.EndDate))
+		}
+	}
+
+	return where, args
+}
+
+// whereClause joins the given where conditions into a where clause, or
+// returns an empty string if there are none.
+func whereClause(where []string) string {
+	if len(where) == 0 {
+		return ""
+	}
+
+	return " WHERE " + strings.Join(where, " AND ")
+}
+
+// storageToValues handles mapping a storage invoice type to the values of
+// its columns.
+func storageToValues(i *invoice.Invoice) ([]interface{}, error) {
+	// Handle line items.
+	lineItems, err := jsonValue(i.LineItems)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle payment methods.
+	paymentMethods, err := jsonValue(i.PaymentMethods)
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{
+		i.ID,
+		i.OrganizationID,
+		i.UserID,
+		i.PublicHash,
+		i.InvoiceNumber,
+		i.PONumber,
+		i.Currency,
+		datetime.FormatDate(i.DueDate),
+		i.Message,
+		i.BillTo.FirstName,
+		i.BillTo.LastName,
+		i.BillTo.Company,
+		i.BillTo.AddressLine1,
+		i.BillTo.AddressLine2,
+		i.BillTo.City,
+		i.BillTo.State,
+		i.BillTo.PostalCode,
+		i.BillTo.Country,
+		i.BillTo.Email,
+		i.BillTo.Phone,
+		i.PayTo.FirstName,
+		i.PayTo.LastName,
+		i.PayTo.Company,
+		i.PayTo.AddressLine1,
+		i.PayTo.AddressLine2,
+		i.PayTo.City,
+		i.PayTo.State,
+		i.PayTo.PostalCode,
+		i.PayTo.Country,
+		i.PayTo.Email,
+		i.PayTo.Phone,
+		lineItems,
+		paymentMethods,
+		i.TaxRate,
+		i.AmountDue,
+		i.AmountPaid,
+		i.Status,
+		datetime.Format(i.CreatedAt),
+	}, nil
+}
+
+// jsonValue marshals a value for a json column. Like MySQL, a nil slice is
+// stored as NULL and read back as nil.
+func jsonValue(v interface{}) (sql.NullString, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	if string(b) == "null" {
+		return sql.NullString{}, nil
+	}
+
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+// scanner defines a row that can be scanned, either a single row or one of
+// many rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scan scans a row of the invoice columns into an invoice.
+func scan(row scanner) (*invoice.Invoice, error) {
+	var i invoice.Invoice
+	var lineItems, paymentMethods sql.NullString
+
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.UserID,
+		&i.PublicHash,
+		&i.InvoiceNumber,
+		&i.PONumber,
+		&i.Currency,
+		&i.DueDate,
+		&i.Message,
+		&i.BillTo.FirstName,
+		&i.BillTo.LastName,
+		&i.BillTo.Company,
+		&i.BillTo.AddressLine1,
+		&i.BillTo.AddressLine2,
+		&i.BillTo.City,
+		&i.BillTo.State,
+		&i.BillTo.PostalCode,
+		&i.BillTo.Country,
+		&i.BillTo.Email,
+		&i.BillTo.Phone,
+		&i.PayTo.FirstName,
+		&i.PayTo.LastName,
+		&i.PayTo.Company,
+		&i.PayTo.AddressLine1,
+		&i.PayTo.AddressLine2,
+		&i.PayTo.City,
+		&i.PayTo.State,
+		&i.PayTo.PostalCode,
+		&i.PayTo.Country,
+		&i.PayTo.Email,
+		&i.PayTo.Phone,
+		&lineItems,
+		&paymentMethods,
+		&i.TaxRate,
+		&i.AmountDue,
+		&i.AmountPaid,
+		&i.Status,
+		&i.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle line items.
+	if lineItems.Valid {
+		i.LineItems = []invoice.LineItem{}
+		if err := json.Unmarshal([]byte(lineItems.String), &i.LineItems); err != nil {
+			return nil, err
+		}
+	}
+
+	// Handle payment methods.
+	if paymentMethods.Valid {
+		i.PaymentMethods = []string{}
+		if err := json.Unmarshal([]byte(paymentMethods.String), &i.PaymentMethods); err != nil {
+			return nil, err
+		}
+	}
+
+	return &i, nil
+}
@@ -0,0 +1,97 @@
+package recoverycode
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/recoverycode"
+	"dddstructure/storage/sqlite/datetime"
+)
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout.
+func New(db *sql.DB, timeout time.Duration) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+	}
+}
+
+// Create creates a new recovery code.
+func (db *Database) Create(ctx context.Context, c *recoverycode.RecoveryCode) (*recoverycode.RecoveryCode, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Insert into database.
+	_, err := db.db.ExecContext(ctx, "INSERT INTO `recovery_codes` (`hash`, `user_id`, `created_at`) VALUES (?, ?, ?)",
+		c.Hash,
+		c.UserID,
+		datetime.Format(c.CreatedAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// GetByHash gets a recovery code by the given hash.
+func (db *Database) GetByHash(ctx context.Context, hash string) (*recoverycode.RecoveryCode, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	c := &recoverycode.RecoveryCode{}
+	err := db.db.QueryRowContext(ctx, "SELECT `hash`, `user_id`, `created_at` FROM `recovery_codes` WHERE `hash`=?", hash).
+		Scan(&c.Hash, &c.UserID, &c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, recoverycode.ErrRecoveryCodeNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Delete deletes a recovery code.
+//
+// If the recovery code does not exist, ErrRecoveryCodeNotFound is returned.
+// Only one caller can delete a code, so this is used to make codes
+// single-use.
+func (db *Database) Delete(ctx context.Context, hash string) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	res, err := db.db.ExecContext(ctx, "DELETE FROM `recovery_codes` WHERE `hash`=?", hash)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return recoverycode.ErrRecoveryCodeNotFound
+	}
+
+	return nil
+}
+
+// DeleteByUserID deletes all recovery codes for a user.
+func (db *Database) DeleteByUserID(ctx context.Context, userID uint) error {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	_, err := db.db.ExecContext(ctx, "DELETE FROM `recovery_codes` WHERE `user_id`=?", userID)
+
+	return err
+}
@@ -0,0 +1,197 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/report"
+	"dddstructure/storage/sqlite/datetime"
+)
+
+// periodFormats maps each revenue interval to the SQL expression that
+// truncates a transaction created at datetime to the start of its period.
+var periodFormats = map[string]string{
+	report.RevenueIntervalDay:   "date(t.created_at)",
+	report.RevenueIntervalWeek:  "date(t.created_at, '-6 days', 'weekday 1')",
+	report.RevenueIntervalMonth: "date(t.created_at, 'start of month')",
+}
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout.
+func New(db *sql.DB, timeout time.Duration) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+	}
+}
+
+// GetAging gets the amount due on unpaid invoices per currency and aging
+// bucket.
+func (db *Database) GetAging(ctx context.Context, params *report.AgingParams) ([]*report.AgingBucket, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// The days past due, like DATEDIFF in MySQL.
+	days := "CAST(julianday(?) - julianday(due_date) AS INTEGER)"
+
+	query := `SELECT currency,
+		CASE
+			WHEN ` + days + ` <= 0 THEN '` + report.AgingBucketCurrent + `'
+			WHEN ` + days + ` <= 30 THEN '` + report.AgingBucket1To30 + `'
+			WHEN ` + days + ` <= 60 THEN '` + report.AgingBucket31To60 + `'
+			WHEN ` + days + ` <= 90 THEN '` + report.AgingBucket61To90 + `'
+			ELSE '` + report.AgingBucket90Plus + `'
+		END AS bucket,
+		COUNT(*) AS count,
+		SUM(amount_due) AS amount_due
+		FROM invoices
+		WHERE organization_id=? AND status<>'paid' AND amount_due>0
+		GROUP BY currency, bucket
+		ORDER BY currency ASC`
+
+	asOf := datetime.FormatDate(params.AsOf)
+
+	// Get from database.
+	rows, err := db.db.QueryContext(ctx, query, asOf, asOf, asOf, asOf, params.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Build buckets slice.
+	buckets := []*report.AgingBucket{}
+	for rows.Next() {
+		b := &report.AgingBucket{}
+		if err := rows.Scan(&b.Currency, &b.Bucket, &b.Count, &b.AmountDue); err != nil {
+			return nil, err
+		}
+
+		buckets = append(buckets, b)
+	}
+
+	return buckets, rows.Err()
+}
+
+// GetRevenue gets the revenue collected and refunded per period and
+// currency.
+//
+// Transactions take the currency of the invoice they belong to, so
+// transactions without an invoice are not counted.
+func (db *Database) GetRevenue(ctx context.Context, params *report.RevenueParams) ([]*report.Revenue, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	period, ok := periodFormats[params.Interval]
+	if !ok {
+		return nil, fmt.Errorf("unknown revenue interval %q", params.Interval)
+	}
+
+	// Handle get params.
+	where := []string{"t.organization_id=?", "t.status='approved'", "t.type IN ('sale', 'capture', 'refund')"}
+	args := []interface{}{params.OrganizationID}
+
+	if params.StartDate != nil {
+		where = append(where, "t.created_at>=?")
+		args = append(args, datetime.Format(*params.StartDate))
+	}
+	if params.EndDate != nil {
+		where = append(where, "t.created_at<=?")
+		args = append(args, datetime.Format(*params.EndDate))
+	}
+
+	query := `SELECT ` + period + ` AS period,
+		i.currency AS currency,
+		COUNT(*) AS count,
+		SUM(CASE WHEN t.type IN ('sale', 'capture') THEN t.amount_captured ELSE 0 END) AS collected,
+		SUM(CASE WHEN t.type='refund' THEN t.amount_captured ELSE 0 END) AS refunded
+		FROM transactions t
+		INNER JOIN invoices i ON i.id=t.invoice_id
+		WHERE ` + strings.Join(where, " AND ") + `
+		GROUP BY period, currency
+		ORDER BY period ASC, currency ASC`
+
+	// Get from database.
+	rows, err := db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Build revenue slice.
+	revenue := []*report.Revenue{}
+	for rows.Next() {
+		var p string
+		r := &report.Revenue{}
+		if err := rows.Scan(&p, &r.Currency, &r.Count, &r.Collected, &r.Refunded); err != nil {
+			return nil, err
+		}
+
+		r.Period, err = time.Parse("2006-01-02", p)
+		if err != nil {
+			return nil, err
+		}
+
+		revenue = append(revenue, r)
+	}
+
+	return revenue, rows.Err()
+}
+
+// GetBalances gets the outstanding and collected totals of invoices per
+// currency.
+func (db *Database) GetBalances(ctx context.Context, params *report.BalancesParams) ([]*report.Balance, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Handle get params.
+	where := []string{"organization_id=?"}
+	args := []interface{}{params.OrganizationID}
+
+	if params.StartDate != nil {
+		where = append(where, "created_at>=?")
+		args = append(args, datetime.Format(*params.StartDate))
+	}
+	if params.EndDate != nil {
+		where = append(where, "created_at<=?")
+		args = append(args, datetime.Format(*params.EndDate))
+	}
+
+	query := `SELECT currency,
+		COUNT(*) AS count,
+		SUM(CASE WHEN status<>'paid' THEN amount_due ELSE 0 END) AS outstanding,
+		SUM(amount_paid) AS collected
+		FROM invoices
+		WHERE ` + strings.Join(where, " AND ") + `
+		GROUP BY currency
+		ORDER BY currency ASC`
+
+	// Get from database.
+	rows, err := db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Build balances slice.
+	balances := []*report.Balance{}
+	for rows.Next() {
+		b := &report.Balance{}
+		if err := rows.Scan(&b.Currency, &b.Count, &b.Outstanding, &b.Collected); err != nil {
+			return nil, err
+		}
+
+		balances = append(balances, b)
+	}
+
+	return balances, rows.Err()
+}
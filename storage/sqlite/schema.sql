@@ -0,0 +1,163 @@
+-- The SQLite schema, matching the MySQL schema after every migration in
+-- db/migrations. It is created when the database is opened, so a change to
+-- the MySQL schema must be made here too.
+--
+-- SQLite has no enum or json types, so the enum columns check their values
+-- and the json columns check they are valid JSON. Datetimes are stored in UTC
+-- as text, so they sort and compare the same as MySQL datetimes.
+
+CREATE TABLE IF NOT EXISTS `users` (
+    `id` integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    `email` varchar(255) NOT NULL COLLATE NOCASE,
+    `password` char(60) NOT NULL,
+    `email_verified_at` datetime DEFAULT NULL,
+    `totp_secret` varchar(64) DEFAULT NULL,
+    `totp_enabled_at` datetime DEFAULT NULL,
+    `totp_last_step` bigint DEFAULT NULL
+);
+
+CREATE TABLE IF NOT EXISTS `user_tokens` (
+    `hash` char(64) NOT NULL PRIMARY KEY,
+    `user_id` bigint NOT NULL,
+    `type` varchar(32) NOT NULL CHECK (`type` IN ('password_reset', 'email_verification', 'two_factor_challenge')),
+    `expires_at` datetime NOT NULL,
+    `created_at` datetime NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS `user_tokens_user_id_type` ON `user_tokens` (`user_id`, `type`);
+
+CREATE TABLE IF NOT EXISTS `recovery_codes` (
+    `hash` char(64) NOT NULL PRIMARY KEY,
+    `user_id` bigint NOT NULL,
+    `created_at` datetime NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS `recovery_codes_user_id` ON `recovery_codes` (`user_id`);
+
+CREATE TABLE IF NOT EXISTS `login_attempts` (
+    `attempt_key` varchar(255) NOT NULL PRIMARY KEY COLLATE NOCASE,
+    `failures` integer NOT NULL,
+    `last_failed_at` datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS `sessions` (
+    `id` char(36) NOT NULL PRIMARY KEY,
+    `user_id` bigint NOT NULL,
+    `user_agent` varchar(255) NOT NULL,
+    `ip_address` varchar(45) NOT NULL,
+    `created_at` datetime NOT NULL,
+    `last_used_at` datetime NOT NULL,
+    `expires_at` datetime NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS `sessions_user_id` ON `sessions` (`user_id`);
+
+CREATE TABLE IF NOT EXISTS `refresh_tokens` (
+    `hash` char(64) NOT NULL PRIMARY KEY,
+    `session_id` char(36) NOT NULL,
+    `used_at` datetime DEFAULT NULL,
+    `created_at` datetime NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS `refresh_tokens_session_id` ON `refresh_tokens` (`session_id`);
+
+CREATE TABLE IF NOT EXISTS `organizations` (
+    `id` integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    `name` varchar(255) NOT NULL,
+    `created_at` datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS `organization_members` (
+    `organization_id` bigint NOT NULL,
+    `user_id` bigint NOT NULL,
+    `role` varchar(16) NOT NULL CHECK (`role` IN ('owner', 'admin', 'accountant', 'read_only')),
+    `created_at` datetime NOT NULL,
+    PRIMARY KEY (`organization_id`, `user_id`)
+);
+
+CREATE INDEX IF NOT EXISTS `organization_members_user_id` ON `organization_members` (`user_id`);
+
+CREATE TABLE IF NOT EXISTS `organization_invitations` (
+    `hash` char(64) NOT NULL PRIMARY KEY,
+    `organization_id` bigint NOT NULL,
+    `email` varchar(255) NOT NULL COLLATE NOCASE,
+    `role` varchar(16) NOT NULL CHECK (`role` IN ('owner', 'admin', 'accountant', 'read_only')),
+    `invited_by` bigint NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `created_at` datetime NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS `organization_invitations_organization_id` ON `organization_invitations` (`organization_id`);
+
+CREATE TABLE IF NOT EXISTS `invoices` (
+    `id` integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    `organization_id` bigint NOT NULL,
+    `user_id` bigint NOT NULL,
+    `public_hash` char(36) NOT NULL,
+    `invoice_number` varchar(50) NOT NULL,
+    `po_number` varchar(50) NOT NULL,
+    `currency` char(3) NOT NULL,
+    `due_date` date NOT NULL,
+    `message` varchar(255) NOT NULL,
+    `bill_to_first_name` varchar(255) NOT NULL,
+    `bill_to_last_name` varchar(255) NOT NULL,
+    `bill_to_company` varchar(255) NOT NULL,
+    `bill_to_address_line_1` varchar(255) NOT NULL,
+    `bill_to_address_line_2` varchar(255) NOT NULL,
+    `bill_to_city` varchar(255) NOT NULL,
+    `bill_to_state` varchar(3) NOT NULL,
+    `bill_to_postal_code` varchar(12) NOT NULL,
+    `bill_to_country` char(2) NOT NULL,
+    `bill_to_email` varchar(255) NOT NULL,
+    `bill_to_phone` varchar(15) NOT NULL,
+    `pay_to_first_name` varchar(255) NOT NULL,
+    `pay_to_last_name` varchar(255) NOT NULL,
+    `pay_to_company` varchar(255) NOT NULL,
+    `pay_to_address_line_1` varchar(255) NOT NULL,
+    `pay_to_address_line_2` varchar(255) NOT NULL,
+    `pay_to_city` varchar(255) NOT NULL,
+    `pay_to_state` varchar(3) NOT NULL,
+    `pay_to_postal_code` varchar(12) NOT NULL,
+    `pay_to_country` char(2) NOT NULL,
+    `pay_to_email` varchar(255) NOT NULL,
+    `pay_to_phone` varchar(15) NOT NULL,
+    `line_items` json DEFAULT NULL CHECK (`line_items` IS NULL OR json_valid(`line_items`)),
+    `payment_methods` json DEFAULT NULL CHECK (`payment_methods` IS NULL OR json_valid(`payment_methods`)),
+    `tax_rate` varchar(10) NOT NULL,
+    `amount_due` integer NOT NULL,
+    `amount_paid` integer NOT NULL,
+    `status` varchar(16) NOT NULL CHECK (`status` IN ('pending', 'paid', 'past_due')),
+    `created_at` datetime NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS `invoices_organization_id_created_at_id` ON `invoices` (`organization_id`, `created_at`, `id`);
+
+CREATE TABLE IF NOT EXISTS `transactions` (
+    `id` integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    `organization_id` bigint NOT NULL,
+    `user_id` bigint NOT NULL,
+    `type` varchar(16) NOT NULL CHECK (`type` IN ('authorize', 'capture', 'sale', 'void', 'refund')),
+    `card_type` varchar(255) NOT NULL,
+    `amount_captured` integer NOT NULL,
+    `invoice_id` bigint NOT NULL,
+    `status` varchar(16) NOT NULL CHECK (`status` IN ('approved', 'declined')),
+    `created_at` datetime NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS `transactions_organization_id_created_at` ON `transactions` (`organization_id`, `created_at`);
+
+CREATE TABLE IF NOT EXISTS `outbox_events` (
+    `sequence` integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    `id` varchar(36) NOT NULL UNIQUE,
+    `type` varchar(64) NOT NULL,
+    `version` integer NOT NULL,
+    `payload` json NOT NULL CHECK (json_valid(`payload`)),
+    `created_at` datetime NOT NULL,
+    `claim_id` varchar(36) DEFAULT NULL,
+    `available_at` datetime DEFAULT NULL,
+    `attempts` integer NOT NULL DEFAULT 0,
+    `last_error` text DEFAULT NULL,
+    `dead_at` datetime DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS `outbox_events_claim_id` ON `outbox_events` (`claim_id`);
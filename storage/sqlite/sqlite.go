@@ -0,0 +1,120 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"time"
+
+	"dddstructure/storage"
+	"dddstructure/storage/idgen"
+	"dddstructure/storage/sqlite/health"
+	"dddstructure/storage/sqlite/invoice"
+	"dddstructure/storage/sqlite/loginattempt"
+	"dddstructure/storage/sqlite/organization"
+	"dddstructure/storage/sqlite/outbox"
+	"dddstructure/storage/sqlite/recoverycode"
+	"dddstructure/storage/sqlite/report"
+	"dddstructure/storage/sqlite/session"
+	"dddstructure/storage/sqlite/transaction"
+	"dddstructure/storage/sqlite/user"
+	"dddstructure/storage/sqlite/usertoken"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// schema holds the statements creating every table, if they do not exist.
+//
+//go:embed schema.sql
+var schema string
+
+// columns holds the columns added to tables after they were first created,
+// which are added to the tables of existing files when they are opened.
+var columns = []struct {
+	table, column, definition string
+}{
+	{"outbox_events", "claim_id", "varchar(36) DEFAULT NULL"},
+	{"outbox_events", "available_at", "datetime DEFAULT NULL"},
+	{"outbox_events", "attempts", "integer NOT NULL DEFAULT 0"},
+	{"outbox_events", "last_error", "text DEFAULT NULL"},
+	{"outbox_events", "dead_at", "datetime DEFAULT NULL"},
+}
+
+// Open opens the SQLite database in the file at the given path, creating the
+// file and its tables if they do not exist. The path ":memory:" opens a
+// database that is only kept in memory.
+//
+// SQLite only allows one write at a time, so the database is limited to a
+// single connection. Queries wait for each other instead of failing as busy,
+// and an in-memory database is the same for every query.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+
+	if err := addColumns(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// addColumns adds the columns missing from the tables of an existing file.
+// It runs before the schema, whose indexes may use the columns, and skips
+// tables that do not exist yet, as the schema creates them whole.
+func addColumns(ctx context.Context, db *sql.DB) error {
+	for _, c := range columns {
+		var count int
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pragma_table_info(?)", c.table).Scan(&count); err != nil {
+			return err
+		} else if count == 0 {
+			continue
+		}
+
+		var exists bool
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE `name`=?", c.table, c.column).Scan(&exists); err != nil {
+			return err
+		} else if exists {
+			continue
+		}
+
+		if _, err := db.ExecContext(ctx, "ALTER TABLE `"+c.table+"` ADD COLUMN `"+c.column+"` "+c.definition); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// New returns a new implementation of storage.Storage that uses SQLite as
+// the backend database, opened with Open. Each query is cancelled after the
+// given timeout, or only once its context is if the timeout is zero.
+//
+// The IDs of new users, organizations, invoices and transactions are taken
+// from the given generator, or assigned by the database if it is nil.
+func New(db *sql.DB, timeout time.Duration, ids idgen.Generator) *storage.Storage {
+	s := &storage.Storage{
+		User:         user.New(db, timeout, ids),
+		UserToken:    usertoken.New(db, timeout),
+		RecoveryCode: recoverycode.New(db, timeout),
+		LoginAttempt: loginattempt.New(db, timeout),
+		Session:      session.New(db, timeout),
+		Organization: organization.New(db, timeout, ids),
+		Invoice:      invoice.New(db, timeout, ids),
+		Transaction:  transaction.New(db, timeout, ids),
+		Report:       report.New(db, timeout),
+		Outbox:       outbox.New(db, timeout),
+		Health:       health.New(db, timeout),
+	}
+
+	return s
+}
@@ -0,0 +1,106 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/idgen"
+	"dddstructure/storage/outbox"
+	"dddstructure/storage/sqlite/datetime"
+	sqliteoutbox "dddstructure/storage/sqlite/outbox"
+	"dddstructure/storage/transaction"
+)
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+	ids     idgen.Generator
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout. The IDs of new transactions are taken from the given generator,
+// or assigned by the AUTOINCREMENT column if it is nil.
+func New(db *sql.DB, timeout time.Duration, ids idgen.Generator) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+		ids:     ids,
+	}
+}
+
+// Create creates a new transaction.
+func (db *Database) Create(ctx context.Context, t *transaction.Transaction) (*transaction.Transaction, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Handle ID.
+	id := t.ID
+	if id == 0 && db.ids != nil {
+		id = db.ids.NewID()
+	}
+
+	// Insert into database, where an ID of NULL is assigned by the database,
+	// along with the events of the new transaction.
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "INSERT INTO `transactions` (`id`, `organization_id`, `user_id`, `type`, `card_type`, `amount_captured`, `invoice_id`, `status`, `created_at`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		sql.NullInt64{Int64: int64(id), Valid: id != 0},
+		t.OrganizationID,
+		t.UserID,
+		t.Type,
+		t.CardType,
+		t.AmountCaptured,
+		t.InvoiceID,
+		t.Status,
+		datetime.Format(t.CreatedAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The ID is read back from the insert when assigned by the database.
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	t.ID = uint(lastID)
+
+	events, err := outbox.TransactionEvents(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sqliteoutbox.Insert(ctx, tx, events); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// GetByID gets a transaction by the given ID.
+func (db *Database) GetByID(ctx context.Context, id uint) (*transaction.Transaction, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	t := &transaction.Transaction{}
+	err := db.db.QueryRowContext(ctx, "SELECT `id`, `organization_id`, `user_id`, `type`, `card_type`, `amount_captured`, `invoice_id`, `status`, `created_at` FROM `transactions` WHERE `id`=?", id).
+		Scan(&t.ID, &t.OrganizationID, &t.UserID, &t.Type, &t.CardType, &t.AmountCaptured, &t.InvoiceID, &t.Status, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, transaction.ErrTransactionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,133 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dddstructure/storage/deadline"
+	"dddstructure/storage/idgen"
+	"dddstructure/storage/sqlite/datetime"
+	"dddstructure/storage/user"
+)
+
+// columns defines the columns of a user, in the order they are scanned.
+const columns = "`id`, `email`, `password`, `email_verified_at`, `totp_secret`, `totp_enabled_at`, `totp_last_step`"
+
+// Database defines the database.
+type Database struct {
+	db      *sql.DB
+	timeout time.Duration
+	ids     idgen.Generator
+}
+
+// New creates a new database, where each query is cancelled after the
+// given timeout. The IDs of new users are taken from the given generator,
+// or assigned by the AUTOINCREMENT column if it is nil.
+func New(db *sql.DB, timeout time.Duration, ids idgen.Generator) *Database {
+	return &Database{
+		db:      db,
+		timeout: timeout,
+		ids:     ids,
+	}
+}
+
+// Create creates a new user.
+func (db *Database) Create(ctx context.Context, u *user.User) (*user.User, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Handle ID.
+	id := u.ID
+	if id == 0 && db.ids != nil {
+		id = db.ids.NewID()
+	}
+
+	// Insert into database, where an ID of NULL is assigned by the database.
+	res, err := db.db.ExecContext(ctx, "INSERT INTO `users` ("+columns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+		sql.NullInt64{Int64: int64(id), Valid: id != 0},
+		u.Email,
+		u.Password,
+		datetime.Null(u.EmailVerifiedAt),
+		sql.NullString{String: u.TOTPSecret, Valid: u.TOTPSecret != ""},
+		datetime.Null(u.TOTPEnabledAt),
+		sql.NullInt64{Int64: int64(u.TOTPLastStep), Valid: u.TOTPLastStep != 0},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The ID is read back from the insert when assigned by the database.
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	u.ID = uint(lastID)
+
+	return u, nil
+}
+
+// GetByID gets a user by the given ID.
+func (db *Database) GetByID(ctx context.Context, id uint) (*user.User, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	row := db.db.QueryRowContext(ctx, "SELECT "+columns+" FROM `users` WHERE `id`=?", id)
+
+	return scan(row)
+}
+
+// GetByEmail gets a user by the given email.
+func (db *Database) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	row := db.db.QueryRowContext(ctx, "SELECT "+columns+" FROM `users` WHERE `email`=? LIMIT 1", email)
+
+	return scan(row)
+}
+
+// Update updates a user.
+func (db *Database) Update(ctx context.Context, u *user.User) (*user.User, error) {
+	ctx, cancel := deadline.Context(ctx, db.timeout)
+	defer cancel()
+
+	// Update in database.
+	_, err := db.db.ExecContext(ctx, "UPDATE `users` SET `email`=?, `password`=?, `email_verified_at`=?, `totp_secret`=?, `totp_enabled_at`=?, `totp_last_step`=? WHERE `id`=?",
+		u.Email,
+		u.Password,
+		datetime.Null(u.EmailVerifiedAt),
+		sql.NullString{String: u.TOTPSecret, Valid: u.TOTPSecret != ""},
+		datetime.Null(u.TOTPEnabledAt),
+		sql.NullInt64{Int64: int64(u.TOTPLastStep), Valid: u.TOTPLastStep != 0},
+		u.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// scan scans a row of the user columns into a user.
+func scan(row *sql.Row) (*user.User, error) {
+	var u user.User
+	var emailVerifiedAt, totpEnabledAt sql.NullTime
+	var totpSecret sql.NullString
+	var totpLastStep sql.NullInt64
+
+	err := row.Scan(&u.ID, &u.Email, &u.Password, &emailVerifiedAt, &totpSecret, &totpEnabledAt, &totpLastStep)
+	if err == sql.ErrNoRows {
+		return nil, user.ErrUserNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Map to user type.
+	u.EmailVerifiedAt = datetime.Ptr(emailVerifiedAt)
+	u.TOTPSecret = totpSecret.String
+	u.TOTPEnabledAt = datetime.Ptr(totpEnabledAt)
+	u.TOTPLastStep = uint64(totpLastStep.Int64)
+
+	return &u, nil
+}
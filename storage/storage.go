@@ -1,19 +1,44 @@
 package storage
 
 import (
+	"dddstructure/storage/health"
 	"dddstructure/storage/invoice"
+	"dddstructure/storage/loginattempt"
+	"dddstructure/storage/organization"
+	"dddstructure/storage/outbox"
+	"dddstructure/storage/recoverycode"
+	"dddstructure/storage/report"
+	"dddstructure/storage/session"
 	"dddstructure/storage/transaction"
 	"dddstructure/storage/user"
+	"dddstructure/storage/usertoken"
 )
 
 // Storage defines the storage system.
 type Storage struct {
-	User        user.Database
-	Invoice     invoice.Database
-	Transaction transaction.Database
+	User         user.Database
+	UserToken    usertoken.Database
+	RecoveryCode recoverycode.Database
+	LoginAttempt loginattempt.Database
+	Session      session.Database
+	Organization organization.Database
+	Invoice      invoice.Database
+	Transaction  transaction.Database
+	Report       report.Database
+	Outbox       outbox.Database
+	Health       health.Database
 }
 
-// New returns a new storage.
-func New(storage *Storage) *Storage {
+// Decorator defines a function wrapping the databases of a storage, such as
+// to cache lookups, returning the wrapped storage.
+type Decorator func(s *Storage) *Storage
+
+// New returns a new storage, wrapping the given storage with each of the
+// given decorators in order, so calls go through the last decorator first.
+func New(storage *Storage, decorators ...Decorator) *Storage {
+	for _, d := range decorators {
+		storage = d(storage)
+	}
+
 	return storage
 }
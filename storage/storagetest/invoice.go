@@ -0,0 +1,556 @@
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"dddstructure/storage"
+	"dddstructure/storage/invoice"
+
+	"github.com/google/uuid"
+)
+
+// testInvoice tests the invoice database.
+func testInvoice(t *testing.T, newStorage Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).Invoice
+
+		// Create an invoice with every field set.
+		want := newInvoice(1, now(), "pending")
+		created, err := db.Create(ctx, want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.ID == 0 {
+			t.Fatal("Expected an ID to be assigned")
+		}
+		want.ID = created.ID
+
+		// Get the invoice by ID and public hash.
+		got, err := db.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkInvoice(t, want, got)
+
+		got, err = db.GetByPublicHash(ctx, want.PublicHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkInvoice(t, want, got)
+
+		// Create a second invoice, which gets a new ID.
+		created, err = db.Create(ctx, newInvoice(1, now(), "pending"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.ID == 0 || created.ID == want.ID {
+			t.Errorf("Expected a new ID to be assigned, got '%d'", created.ID)
+		}
+	})
+
+	t.Run("CreateWithID", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).Invoice
+
+		// Create an invoice with its ID set, which is kept.
+		i := newInvoice(1, now(), "pending")
+		i.ID = 1000
+		created, err := db.Create(ctx, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.ID != 1000 {
+			t.Errorf("Expected ID to be '%d', got '%d'", 1000, created.ID)
+		}
+
+		if _, err := db.GetByID(ctx, 1000); err != nil {
+			t.Errorf("Expected error to be '%v', got '%v'", nil, err)
+		}
+	})
+
+	t.Run("CreateBatch", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStorage(t)
+
+		// Create a batch whose second invoice has the ID of the first,
+		// which creates neither invoice nor their events.
+		first := newInvoice(1, now(), "pending")
+		first.ID = 1000
+		second := newInvoice(1, now(), "pending")
+		second.ID = 1000
+		if _, err := s.Invoice.CreateBatch(ctx, []*invoice.Invoice{first, second}); err == nil {
+			t.Fatal("Expected error for duplicate ID")
+		}
+
+		if _, err := s.Invoice.GetByID(ctx, 1000); err != invoice.ErrInvoiceNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", invoice.ErrInvoiceNotFound, err)
+		}
+		if events := getPending(t, s.Outbox, 10); len(events) != 0 {
+			t.Errorf("Expected '%d' events, got '%d'", 0, len(events))
+		}
+
+		// Create a batch of valid invoices, which are returned in order
+		// with their events.
+		second.ID = 0
+		created, err := s.Invoice.CreateBatch(ctx, []*invoice.Invoice{first, second})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(created) != 2 {
+			t.Fatalf("Expected '%d' invoices, got '%d'", 2, len(created))
+		}
+		if created[0].ID != 1000 || created[1].ID == 0 || created[1].ID == 1000 {
+			t.Errorf("Expected IDs '%d' and a new one, got '%d' and '%d'", 1000, created[0].ID, created[1].ID)
+		}
+
+		for _, c := range created {
+			got, err := s.Invoice.GetByID(ctx, c.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkInvoice(t, c, got)
+		}
+		if events := getPending(t, s.Outbox, 10); len(events) != 2 {
+			t.Errorf("Expected '%d' events, got '%d'", 2, len(events))
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).Invoice
+
+		if _, err := db.GetByID(ctx, 1); err != invoice.ErrInvoiceNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", invoice.ErrInvoiceNotFound, err)
+		}
+		if _, err := db.GetByPublicHash(ctx, "00000000-0000-0000-0000-000000000000"); err != invoice.ErrInvoiceNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", invoice.ErrInvoiceNotFound, err)
+		}
+		if err := db.Delete(ctx, 1); err != invoice.ErrInvoiceNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", invoice.ErrInvoiceNotFound, err)
+		}
+
+		invoices, err := db.Get(ctx, &invoice.GetParams{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(invoices) != 0 {
+			t.Errorf("Expected invoices to be '%d', got '%d'", 0, len(invoices))
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).Invoice
+
+		created, err := db.Create(ctx, newInvoice(1, now(), "pending"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Update the invoice.
+		want := newInvoice(1, created.CreatedAt, "paid")
+		want.ID = created.ID
+		want.PublicHash = created.PublicHash
+		want.Message = "Paid in full"
+		want.BillTo.Company = "Acme"
+		want.LineItems = want.LineItems[:1]
+		want.PaymentMethods = []string{"ach"}
+		want.AmountPaid = want.AmountDue
+		if _, err := db.Update(ctx, want); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := db.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkInvoice(t, want, got)
+
+		// Updating an invoice that does not exist does not create it.
+		missing := newInvoice(1, now(), "pending")
+		missing.ID = created.ID + 100
+		db.Update(ctx, missing)
+		if _, err := db.GetByID(ctx, missing.ID); err != invoice.ErrInvoiceNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", invoice.ErrInvoiceNotFound, err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).Invoice
+
+		created, err := db.Create(ctx, newInvoice(1, now(), "pending"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := db.Delete(ctx, created.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.GetByID(ctx, created.ID); err != invoice.ErrInvoiceNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", invoice.ErrInvoiceNotFound, err)
+		}
+
+		// Deleting it again returns not found.
+		if err := db.Delete(ctx, created.ID); err != invoice.ErrInvoiceNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", invoice.ErrInvoiceNotFound, err)
+		}
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStorage(t)
+
+		// Create invoices for two organizations, a day apart, alternating
+		// between pending and paid.
+		start := date(2024, time.March, 1)
+		ids := createInvoices(t, s, 1, start, 6)
+		createInvoices(t, s, 2, start, 2)
+
+		orgID := uint(1)
+		paid := "paid"
+		from := start.AddDate(0, 0, 1)
+		to := start.AddDate(0, 0, 3)
+
+		tests := []struct {
+			name   string
+			params invoice.GetParams
+			want   []uint
+		}{
+			{
+				name:   "organization",
+				params: invoice.GetParams{OrganizationID: &orgID},
+				want:   []uint{ids[5], ids[4], ids[3], ids[2], ids[1], ids[0]},
+			},
+			{
+				name:   "ID",
+				params: invoice.GetParams{ID: &ids[2]},
+				want:   []uint{ids[2]},
+			},
+			{
+				name:   "status",
+				params: invoice.GetParams{OrganizationID: &orgID, Status: &paid},
+				want:   []uint{ids[5], ids[3], ids[1]},
+			},
+			{
+				name: "created at",
+				params: invoice.GetParams{
+					OrganizationID: &orgID,
+					CreatedAt:      &invoice.GetParamsCreatedAt{StartDate: &from, EndDate: &to},
+				},
+				want: []uint{ids[3], ids[2], ids[1]},
+			},
+			{
+				name: "created at start",
+				params: invoice.GetParams{
+					OrganizationID: &orgID,
+					CreatedAt:      &invoice.GetParamsCreatedAt{StartDate: &to},
+				},
+				want: []uint{ids[5], ids[4], ids[3]},
+			},
+			{
+				name: "created at end",
+				params: invoice.GetParams{
+					OrganizationID: &orgID,
+					CreatedAt:      &invoice.GetParamsCreatedAt{EndDate: &from},
+				},
+				want: []uint{ids[1], ids[0]},
+			},
+			{
+				name: "every filter",
+				params: invoice.GetParams{
+					OrganizationID: &orgID,
+					Status:         &paid,
+					CreatedAt:      &invoice.GetParamsCreatedAt{StartDate: &from, EndDate: &to},
+				},
+				want: []uint{ids[3], ids[1]},
+			},
+		}
+
+		for _, tt := range tests {
+			tt.params.Limit = 100
+
+			invoices, err := s.Invoice.Get(ctx, &tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkIDs(t, tt.name, tt.want, invoices)
+
+			count, err := s.Invoice.GetCount(ctx, &tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != uint(len(tt.want)) {
+				t.Errorf("%s: Expected count to be '%d', got '%d'", tt.name, len(tt.want), count)
+			}
+		}
+	})
+
+	t.Run("Ordering", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStorage(t)
+
+		// Invoices created at the same time are ordered by ID, newest first.
+		createdAt := date(2024, time.March, 1)
+		var ids []uint
+		for n := 0; n < 3; n++ {
+			i, err := s.Invoice.Create(ctx, newInvoice(1, createdAt, "pending"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, i.ID)
+		}
+
+		invoices, err := s.Invoice.Get(ctx, &invoice.GetParams{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := append([]uint(nil), ids...)
+		sort.Slice(want, func(a, b int) bool {
+			return want[a] > want[b]
+		})
+		checkIDs(t, "same created at", want, invoices)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStorage(t)
+
+		// Create five invoices, a day apart.
+		start := date(2024, time.March, 1)
+		ids := createInvoices(t, s, 1, start, 5)
+		newest := invoiceAt(ids, start, 4)
+		oldest := invoiceAt(ids, start, 0)
+		middle := invoiceAt(ids, start, 2)
+
+		tests := []struct {
+			name   string
+			params invoice.GetParams
+			want   []uint
+		}{
+			{
+				name:   "limit",
+				params: invoice.GetParams{Limit: 2},
+				want:   []uint{ids[4], ids[3]},
+			},
+			{
+				name:   "limit past the end",
+				params: invoice.GetParams{Limit: 10},
+				want:   []uint{ids[4], ids[3], ids[2], ids[1], ids[0]},
+			},
+			{
+				name:   "limit zero",
+				params: invoice.GetParams{Limit: 0},
+				want:   []uint{},
+			},
+			{
+				name:   "offset",
+				params: invoice.GetParams{Offset: 3, Limit: 10},
+				want:   []uint{ids[1], ids[0]},
+			},
+			{
+				name:   "offset of the last invoice",
+				params: invoice.GetParams{Offset: 4, Limit: 10},
+				want:   []uint{ids[0]},
+			},
+			{
+				name:   "offset past the end",
+				params: invoice.GetParams{Offset: 5, Limit: 10},
+				want:   []uint{},
+			},
+			{
+				name:   "after cursor",
+				params: invoice.GetParams{Cursor: &middle, Limit: 10},
+				want:   []uint{ids[1], ids[0]},
+			},
+			{
+				name:   "after cursor with limit",
+				params: invoice.GetParams{Cursor: &middle, Limit: 1},
+				want:   []uint{ids[1]},
+			},
+			{
+				name:   "before cursor",
+				params: invoice.GetParams{Cursor: before(middle), Limit: 10},
+				want:   []uint{ids[4], ids[3]},
+			},
+			{
+				name:   "before cursor with limit",
+				params: invoice.GetParams{Cursor: before(middle), Limit: 1},
+				want:   []uint{ids[3]},
+			},
+			{
+				name:   "before cursor with offset",
+				params: invoice.GetParams{Cursor: before(middle), Offset: 1, Limit: 10},
+				want:   []uint{ids[4]},
+			},
+			{
+				name:   "after oldest",
+				params: invoice.GetParams{Cursor: &oldest, Limit: 10},
+				want:   []uint{},
+			},
+			{
+				name:   "before newest",
+				params: invoice.GetParams{Cursor: before(newest), Limit: 10},
+				want:   []uint{},
+			},
+		}
+
+		for _, tt := range tests {
+			invoices, err := s.Invoice.Get(ctx, &tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkIDs(t, tt.name, tt.want, invoices)
+		}
+	})
+}
+
+// newInvoice returns a new invoice with every field set, valid for every
+// backend.
+func newInvoice(organizationID uint, createdAt time.Time, status string) *invoice.Invoice {
+	return &invoice.Invoice{
+		OrganizationID: organizationID,
+		UserID:         1,
+		PublicHash:     uuid.New().String(),
+		InvoiceNumber:  "INV-1",
+		PONumber:       "PO-1",
+		Currency:       "USD",
+		DueDate:        date(2024, time.April, 1),
+		Message:        "Thank you",
+		BillTo: invoice.BillTo{
+			FirstName:    "Bill",
+			LastName:     "Smith",
+			AddressLine1: "1 Main St",
+			City:         "Springfield",
+			State:        "IL",
+			PostalCode:   "62701",
+			Country:      "US",
+			Email:        "bill@test.com",
+			Phone:        "5555555555",
+		},
+		PayTo: invoice.PayTo{
+			FirstName: "John",
+			LastName:  "Doe",
+			Company:   "Doe Co",
+			Country:   "US",
+			Email:     "john@test.com",
+		},
+		LineItems: []invoice.LineItem{
+			{Name: "Widget", Description: "A widget", Quantity: 2, Price: 500, Subtotal: 1000},
+			{Name: "Gadget", Quantity: 1, Price: 250, Subtotal: 250},
+		},
+		PaymentMethods: []string{"card", "ach"},
+		TaxRate:        "0.05",
+		AmountDue:      1313,
+		Status:         status,
+		CreatedAt:      createdAt,
+	}
+}
+
+// createInvoices creates the given number of invoices for an organization,
+// a day apart from the given start, alternating between pending and paid. The
+// IDs are returned oldest first.
+func createInvoices(t *testing.T, s *storage.Storage, organizationID uint, start time.Time, n int) []uint {
+	t.Helper()
+
+	var ids []uint
+	for d := 0; d < n; d++ {
+		status := "pending"
+		if d%2 == 1 {
+			status = "paid"
+		}
+
+		i, err := s.Invoice.Create(context.Background(), newInvoice(organizationID, start.AddDate(0, 0, d), status))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, i.ID)
+	}
+
+	return ids
+}
+
+// invoiceAt returns a cursor pointing at the invoice created the given
+// number of days after the start.
+func invoiceAt(ids []uint, start time.Time, day int) invoice.GetParamsCursor {
+	return invoice.GetParamsCursor{
+		CreatedAt: start.AddDate(0, 0, day),
+		ID:        ids[day],
+	}
+}
+
+// before returns a copy of the given cursor that pages before it.
+func before(c invoice.GetParamsCursor) *invoice.GetParamsCursor {
+	c.Before = true
+	return &c
+}
+
+// checkIDs checks the got invoices have the wanted IDs, in order.
+func checkIDs(t *testing.T, name string, want []uint, got []*invoice.Invoice) {
+	t.Helper()
+
+	gotIDs := make([]uint, 0, len(got))
+	for _, i := range got {
+		gotIDs = append(gotIDs, i.ID)
+	}
+
+	if fmt.Sprint(gotIDs) != fmt.Sprint(want) {
+		t.Errorf("%s: Expected invoice IDs to be '%v', got '%v'", name, want, gotIDs)
+	}
+}
+
+// checkInvoice checks the got invoice matches the wanted invoice.
+func checkInvoice(t *testing.T, want, got *invoice.Invoice) {
+	t.Helper()
+
+	if got.ID != want.ID {
+		t.Errorf("Expected ID to be '%d', got '%d'", want.ID, got.ID)
+	}
+	if got.OrganizationID != want.OrganizationID || got.UserID != want.UserID {
+		t.Errorf("Expected organization and user ID to be '%d' and '%d', got '%d' and '%d'", want.OrganizationID, want.UserID, got.OrganizationID, got.UserID)
+	}
+	if got.PublicHash != want.PublicHash {
+		t.Errorf("Expected public hash to be '%s', got '%s'", want.PublicHash, got.PublicHash)
+	}
+	if got.InvoiceNumber != want.InvoiceNumber || got.PONumber != want.PONumber {
+		t.Errorf("Expected invoice and PO number to be '%s' and '%s', got '%s' and '%s'", want.InvoiceNumber, want.PONumber, got.InvoiceNumber, got.PONumber)
+	}
+	if got.Currency != want.Currency {
+		t.Errorf("Expected currency to be '%s', got '%s'", want.Currency, got.Currency)
+	}
+	if !got.DueDate.Equal(want.DueDate) {
+		t.Errorf("Expected due date to be '%v', got '%v'", want.DueDate, got.DueDate)
+	}
+	if got.Message != want.Message {
+		t.Errorf("Expected message to be '%s', got '%s'", want.Message, got.Message)
+	}
+	if got.BillTo != want.BillTo {
+		t.Errorf("Expected bill to to be '%+v', got '%+v'", want.BillTo, got.BillTo)
+	}
+	if got.PayTo != want.PayTo {
+		t.Errorf("Expected pay to to be '%+v', got '%+v'", want.PayTo, got.PayTo)
+	}
+	if fmt.Sprint(got.LineItems) != fmt.Sprint(want.LineItems) {
+		t.Errorf("Expected line items to be '%+v', got '%+v'", want.LineItems, got.LineItems)
+	}
+	if fmt.Sprint(got.PaymentMethods) != fmt.Sprint(want.PaymentMethods) {
+		t.Errorf("Expected payment methods to be '%v', got '%v'", want.PaymentMethods, got.PaymentMethods)
+	}
+	if got.TaxRate != want.TaxRate {
+		t.Errorf("Expected tax rate to be '%s', got '%s'", want.TaxRate, got.TaxRate)
+	}
+	if got.AmountDue != want.AmountDue || got.AmountPaid != want.AmountPaid {
+		t.Errorf("Expected amount due and paid to be '%d' and '%d', got '%d' and '%d'", want.AmountDue, want.AmountPaid, got.AmountDue, got.AmountPaid)
+	}
+	if got.Status != want.Status {
+		t.Errorf("Expected status to be '%s', got '%s'", want.Status, got.Status)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("Expected created at to be '%v', got '%v'", want.CreatedAt, got.CreatedAt)
+	}
+}
@@ -0,0 +1,177 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"dddstructure/storage/organization"
+)
+
+// testOrganization tests the organization database.
+func testOrganization(t *testing.T, newStorage Factory) {
+	t.Run("CreateWithOwner", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).Organization
+
+		// Create an organization, with its owner.
+		want := &organization.Organization{
+			Name:      "Acme",
+			CreatedAt: now(),
+		}
+		created, err := db.Create(ctx, want, &organization.Member{
+			UserID:    1,
+			Role:      "owner",
+			CreatedAt: want.CreatedAt,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.ID == 0 {
+			t.Fatal("Expected an ID to be assigned")
+		}
+		want.ID = created.ID
+
+		got, err := db.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkOrganization(t, want, got)
+
+		// The owner is a member of the new organization.
+		m, err := db.GetMember(ctx, created.ID, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Role != "owner" {
+			t.Errorf("Expected role to be '%s', got '%s'", "owner", m.Role)
+		}
+	})
+
+	t.Run("CreateIsAtomic", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).Organization
+
+		// Creating an organization with an owner that cannot be stored
+		// creates neither.
+		if _, err := db.Create(ctx, &organization.Organization{
+			ID:        900,
+			Name:      "Acme",
+			CreatedAt: now(),
+		}, &organization.Member{
+			UserID:    1,
+			Role:      "superuser",
+			CreatedAt: now(),
+		}); err == nil {
+			t.Fatal("Expected an error for an invalid role")
+		}
+
+		if _, err := db.GetByID(ctx, 900); err != organization.ErrOrganizationNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", organization.ErrOrganizationNotFound, err)
+		}
+
+		memberships, err := db.GetByUserID(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(memberships) != 0 {
+			t.Errorf("Expected '%d' memberships, got '%d'", 0, len(memberships))
+		}
+	})
+
+	t.Run("GetByUserID", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).Organization
+
+		// Create organizations out of ID order, the user owning one and
+		// being added to another.
+		second, err := db.Create(ctx, &organization.Organization{
+			ID:        20,
+			Name:      "Second",
+			CreatedAt: now(),
+		}, &organization.Member{
+			UserID:    1,
+			Role:      "owner",
+			CreatedAt: now(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		first, err := db.Create(ctx, &organization.Organization{
+			ID:        10,
+			Name:      "First",
+			CreatedAt: now(),
+		}, &organization.Member{
+			UserID:    2,
+			Role:      "owner",
+			CreatedAt: now(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := db.CreateMember(ctx, &organization.Member{
+			OrganizationID: first.ID,
+			UserID:         1,
+			Role:           "accountant",
+			CreatedAt:      now(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		// Another user's organization is left out.
+		if _, err := db.Create(ctx, &organization.Organization{
+			ID:        30,
+			Name:      "Other",
+			CreatedAt: now(),
+		}, &organization.Member{
+			UserID:    2,
+			Role:      "owner",
+			CreatedAt: now(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		memberships, err := db.GetByUserID(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(memberships) != 2 {
+			t.Fatalf("Expected '%d' memberships, got '%d'", 2, len(memberships))
+		}
+
+		checkOrganization(t, first, &memberships[0].Organization)
+		if memberships[0].Role != "accountant" {
+			t.Errorf("Expected role to be '%s', got '%s'", "accountant", memberships[0].Role)
+		}
+		checkOrganization(t, second, &memberships[1].Organization)
+		if memberships[1].Role != "owner" {
+			t.Errorf("Expected role to be '%s', got '%s'", "owner", memberships[1].Role)
+		}
+
+		// A user with no organizations gets an empty slice.
+		memberships, err = db.GetByUserID(ctx, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if memberships == nil || len(memberships) != 0 {
+			t.Errorf("Expected an empty slice, got '%v'", memberships)
+		}
+	})
+}
+
+// checkOrganization checks the got organization matches the wanted
+// organization.
+func checkOrganization(t *testing.T, want, got *organization.Organization) {
+	t.Helper()
+
+	if got.ID != want.ID {
+		t.Errorf("Expected ID to be '%d', got '%d'", want.ID, got.ID)
+	}
+	if got.Name != want.Name {
+		t.Errorf("Expected name to be '%s', got '%s'", want.Name, got.Name)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("Expected created at to be '%v', got '%v'", want.CreatedAt, got.CreatedAt)
+	}
+}
@@ -0,0 +1,323 @@
+package storagetest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"dddstructure/storage/outbox"
+)
+
+// testOutbox tests the events written to the outbox by the invoice and
+// transaction databases, and the outbox database itself.
+func testOutbox(t *testing.T, newStorage Factory) {
+	t.Run("InvoiceEvents", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStorage(t)
+
+		// Create an invoice, which records a created event.
+		inv, err := s.Invoice.Create(ctx, newInvoice(1, now(), "pending"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		events := getPending(t, s.Outbox, 10)
+		if len(events) != 1 {
+			t.Fatalf("Expected '%d' events, got '%d'", 1, len(events))
+		}
+		if events[0].Type != outbox.TypeInvoiceCreated {
+			t.Errorf("Expected type to be '%s', got '%s'", outbox.TypeInvoiceCreated, events[0].Type)
+		}
+		if events[0].Version != outbox.InvoicePayloadVersion {
+			t.Errorf("Expected version to be '%d', got '%d'", outbox.InvoicePayloadVersion, events[0].Version)
+		}
+		if events[0].ID == "" || events[0].CreatedAt.IsZero() {
+			t.Errorf("Expected ID and created at to be set, got '%+v'", events[0])
+		}
+
+		var payload outbox.InvoicePayload
+		if err := json.Unmarshal(events[0].Payload, &payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload.ID != inv.ID || payload.AmountDue != inv.AmountDue || payload.Status != "pending" || payload.DueDate != "2024-04-01" {
+			t.Errorf("Expected payload of invoice '%d', got '%+v'", inv.ID, payload)
+		}
+
+		// Pay the invoice, which records a paid event after the created
+		// event.
+		inv.Status = "paid"
+		inv.AmountPaid = inv.AmountDue
+		if _, err := s.Invoice.Update(ctx, inv); err != nil {
+			t.Fatal(err)
+		}
+
+		events = getPending(t, s.Outbox, 10)
+		if len(events) != 2 {
+			t.Fatalf("Expected '%d' events, got '%d'", 2, len(events))
+		}
+		if events[0].Type != outbox.TypeInvoiceCreated || events[1].Type != outbox.TypeInvoicePaid {
+			t.Errorf("Expected types to be '%s' and '%s', got '%s' and '%s'", outbox.TypeInvoiceCreated, outbox.TypeInvoicePaid, events[0].Type, events[1].Type)
+		}
+
+		payload = outbox.InvoicePayload{}
+		if err := json.Unmarshal(events[1].Payload, &payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload.Status != "paid" || payload.AmountPaid != inv.AmountDue {
+			t.Errorf("Expected payload of paid invoice, got '%+v'", payload)
+		}
+
+		// Update the paid invoice, which records nothing.
+		inv.Message = "Paid, thank you"
+		if _, err := s.Invoice.Update(ctx, inv); err != nil {
+			t.Fatal(err)
+		}
+
+		if events := getPending(t, s.Outbox, 10); len(events) != 2 {
+			t.Errorf("Expected '%d' events, got '%d'", 2, len(events))
+		}
+
+		// Update an invoice that does not exist, which records nothing.
+		missing := newInvoice(1, now(), "paid")
+		missing.ID = inv.ID + 1000
+		if _, err := s.Invoice.Update(ctx, missing); err != nil {
+			t.Fatal(err)
+		}
+
+		if events := getPending(t, s.Outbox, 10); len(events) != 2 {
+			t.Errorf("Expected '%d' events, got '%d'", 2, len(events))
+		}
+	})
+
+	t.Run("TransactionEvents", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStorage(t)
+
+		// Create a sale and a declined refund, which record nothing.
+		sale := newTransaction()
+		sale.Type = "sale"
+		if _, err := s.Transaction.Create(ctx, sale); err != nil {
+			t.Fatal(err)
+		}
+
+		declined := newTransaction()
+		declined.Status = "declined"
+		if _, err := s.Transaction.Create(ctx, declined); err != nil {
+			t.Fatal(err)
+		}
+
+		if events := getPending(t, s.Outbox, 10); len(events) != 0 {
+			t.Fatalf("Expected '%d' events, got '%d'", 0, len(events))
+		}
+
+		// Create an approved refund, which records a refunded event.
+		refund, err := s.Transaction.Create(ctx, newTransaction())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		events := getPending(t, s.Outbox, 10)
+		if len(events) != 1 {
+			t.Fatalf("Expected '%d' events, got '%d'", 1, len(events))
+		}
+		if events[0].Type != outbox.TypeTransactionRefunded {
+			t.Errorf("Expected type to be '%s', got '%s'", outbox.TypeTransactionRefunded, events[0].Type)
+		}
+
+		var payload outbox.TransactionPayload
+		if err := json.Unmarshal(events[0].Payload, &payload); err != nil {
+			t.Fatal(err)
+		}
+		want := outbox.TransactionPayload{
+			ID:             refund.ID,
+			OrganizationID: refund.OrganizationID,
+			InvoiceID:      refund.InvoiceID,
+			Type:           "refund",
+			Amount:         refund.AmountCaptured,
+			Status:         "approved",
+			CreatedAt:      refund.CreatedAt,
+		}
+		if !payload.CreatedAt.Equal(want.CreatedAt) {
+			t.Errorf("Expected created at to be '%v', got '%v'", want.CreatedAt, payload.CreatedAt)
+		}
+		payload.CreatedAt = want.CreatedAt
+		if payload != want {
+			t.Errorf("Expected payload to be '%+v', got '%+v'", want, payload)
+		}
+	})
+
+	t.Run("ClaimAndDelete", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStorage(t)
+
+		// Create refunds, whose events are claimed in order.
+		var ids []uint
+		for i := 0; i < 3; i++ {
+			trans, err := s.Transaction.Create(ctx, newTransaction())
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, trans.ID)
+		}
+
+		events := getPending(t, s.Outbox, 2)
+		if len(events) != 2 {
+			t.Fatalf("Expected '%d' events, got '%d'", 2, len(events))
+		}
+		for n, e := range events {
+			var payload outbox.TransactionPayload
+			if err := json.Unmarshal(e.Payload, &payload); err != nil {
+				t.Fatal(err)
+			}
+			if payload.ID != ids[n] {
+				t.Errorf("Expected event '%d' to be of transaction '%d', got '%d'", n, ids[n], payload.ID)
+			}
+		}
+
+		// Delete the first event, which is no longer pending.
+		if err := s.Outbox.Delete(ctx, events[0].ID); err != nil {
+			t.Fatal(err)
+		}
+
+		remaining := getPending(t, s.Outbox, 10)
+		if len(remaining) != 2 {
+			t.Fatalf("Expected '%d' events, got '%d'", 2, len(remaining))
+		}
+		if remaining[0].ID != events[1].ID {
+			t.Errorf("Expected first event to be '%s', got '%s'", events[1].ID, remaining[0].ID)
+		}
+
+		// Delete it again, which is not found.
+		if err := s.Outbox.Delete(ctx, events[0].ID); err != outbox.ErrEventNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", outbox.ErrEventNotFound, err)
+		}
+	})
+
+	t.Run("ClaimLease", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStorage(t)
+
+		for i := 0; i < 3; i++ {
+			if _, err := s.Transaction.Create(ctx, newTransaction()); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Claim two events, which another relay does not claim until the
+		// lease ends.
+		claimed, err := s.Outbox.Claim(ctx, 2, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(claimed) != 2 {
+			t.Fatalf("Expected '%d' events, got '%d'", 2, len(claimed))
+		}
+
+		other, err := s.Outbox.Claim(ctx, 10, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(other) != 1 {
+			t.Fatalf("Expected '%d' events, got '%d'", 1, len(other))
+		}
+		if other[0].ID == claimed[0].ID || other[0].ID == claimed[1].ID {
+			t.Errorf("Expected event '%s' not to be claimed twice", other[0].ID)
+		}
+
+		if events, err := s.Outbox.Claim(ctx, 10, time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		} else if len(events) != 0 {
+			t.Errorf("Expected '%d' events, got '%d'", 0, len(events))
+		}
+	})
+
+	t.Run("FailAndDeadLetter", func(t *testing.T) {
+		ctx := context.Background()
+		s := newStorage(t)
+
+		for i := 0; i < 2; i++ {
+			if _, err := s.Transaction.Create(ctx, newTransaction()); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		events := getPending(t, s.Outbox, 10)
+		if len(events) != 2 {
+			t.Fatalf("Expected '%d' events, got '%d'", 2, len(events))
+		}
+		if events[0].Attempts != 0 || events[0].LastError != "" {
+			t.Errorf("Expected no attempts, got '%d' and '%s'", events[0].Attempts, events[0].LastError)
+		}
+
+		// Fail the first event until later, which is not claimed until
+		// then.
+		if err := s.Outbox.Fail(ctx, events[0].ID, "sink unavailable", time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+
+		pending := getPending(t, s.Outbox, 10)
+		if len(pending) != 1 || pending[0].ID != events[1].ID {
+			t.Fatalf("Expected only event '%s' to be pending, got '%d' events", events[1].ID, len(pending))
+		}
+
+		// Fail it again to be retried now, which records both attempts.
+		if err := s.Outbox.Fail(ctx, events[0].ID, "sink timed out", time.Now().Add(-time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+
+		pending = getPending(t, s.Outbox, 10)
+		if len(pending) != 2 || pending[0].ID != events[0].ID {
+			t.Fatalf("Expected event '%s' to be pending first, got '%d' events", events[0].ID, len(pending))
+		}
+		if pending[0].Attempts != 2 {
+			t.Errorf("Expected attempts to be '%d', got '%d'", 2, pending[0].Attempts)
+		}
+		if pending[0].LastError != "sink timed out" {
+			t.Errorf("Expected last error to be '%s', got '%s'", "sink timed out", pending[0].LastError)
+		}
+
+		// Dead-letter it, which is never claimed again.
+		if err := s.Outbox.DeadLetter(ctx, events[0].ID, "sink rejected event"); err != nil {
+			t.Fatal(err)
+		}
+
+		pending = getPending(t, s.Outbox, 10)
+		if len(pending) != 1 || pending[0].ID != events[1].ID {
+			t.Fatalf("Expected only event '%s' to be pending, got '%d' events", events[1].ID, len(pending))
+		}
+
+		dead, err := s.Outbox.GetDeadLetters(ctx, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dead) != 1 || dead[0].ID != events[0].ID {
+			t.Fatalf("Expected only event '%s' to be dead, got '%d' events", events[0].ID, len(dead))
+		}
+		if dead[0].Attempts != 3 || dead[0].LastError != "sink rejected event" {
+			t.Errorf("Expected '%d' attempts and last error '%s', got '%d' and '%s'", 3, "sink rejected event", dead[0].Attempts, dead[0].LastError)
+		}
+
+		// Fail an event that does not exist, which is not found.
+		if err := s.Outbox.Fail(ctx, "00000000-0000-0000-0000-000000000000", "sink unavailable", time.Now()); err != outbox.ErrEventNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", outbox.ErrEventNotFound, err)
+		}
+		if err := s.Outbox.DeadLetter(ctx, "00000000-0000-0000-0000-000000000000", "sink unavailable"); err != outbox.ErrEventNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", outbox.ErrEventNotFound, err)
+		}
+	})
+}
+
+// getPending gets the pending events of an outbox, failing the test on
+// error. The events are claimed with a lease that has already ended, so they
+// stay pending.
+func getPending(t *testing.T, db outbox.Database, limit uint) []*outbox.Event {
+	t.Helper()
+
+	events, err := db.Claim(context.Background(), limit, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return events
+}
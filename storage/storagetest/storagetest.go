@@ -0,0 +1,57 @@
+package storagetest
+
+import (
+	"testing"
+	"time"
+
+	"dddstructure/storage"
+)
+
+// Factory returns new storage for a single test. The users, organizations,
+// invoices, transactions and outbox events of the storage must be empty.
+type Factory func(t *testing.T) *storage.Storage
+
+// Run runs the conformance tests against the storage returned by the given
+// factory, checking every backend behaves the way the services expect.
+//
+// The factory is called once per test, and the tests run one at a time, so
+// a backend sharing a single database can empty it on each call.
+func Run(t *testing.T, newStorage Factory) {
+	t.Run("User", func(t *testing.T) {
+		testUser(t, newStorage)
+	})
+	t.Run("Organization", func(t *testing.T) {
+		testOrganization(t, newStorage)
+	})
+	t.Run("Invoice", func(t *testing.T) {
+		testInvoice(t, newStorage)
+	})
+	t.Run("Transaction", func(t *testing.T) {
+		testTransaction(t, newStorage)
+	})
+	t.Run("Outbox", func(t *testing.T) {
+		testOutbox(t, newStorage)
+	})
+}
+
+// now returns the current time, truncated to what every backend can store.
+// MySQL datetime columns only keep whole seconds.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// date returns midnight UTC of the given date, as stored by MySQL date
+// columns.
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// equalTime checks if two optional times are both unset, or set to the same
+// time.
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
@@ -0,0 +1,87 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"dddstructure/storage/transaction"
+)
+
+// testTransaction tests the transaction database.
+func testTransaction(t *testing.T, newStorage Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).Transaction
+
+		// Create a transaction with every field set.
+		want := newTransaction()
+		created, err := db.Create(ctx, want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.ID == 0 {
+			t.Fatal("Expected an ID to be assigned")
+		}
+		want.ID = created.ID
+
+		got, err := db.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *got != *want {
+			t.Errorf("Expected transaction to be '%+v', got '%+v'", want, got)
+		}
+
+		// Create a second transaction, which gets a new ID.
+		created, err = db.Create(ctx, newTransaction())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.ID == 0 || created.ID == want.ID {
+			t.Errorf("Expected a new ID to be assigned, got '%d'", created.ID)
+		}
+	})
+
+	t.Run("CreateWithID", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).Transaction
+
+		// Create a transaction with its ID set, which is kept.
+		trans := newTransaction()
+		trans.ID = 1000
+		created, err := db.Create(ctx, trans)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.ID != 1000 {
+			t.Errorf("Expected ID to be '%d', got '%d'", 1000, created.ID)
+		}
+
+		if _, err := db.GetByID(ctx, 1000); err != nil {
+			t.Errorf("Expected error to be '%v', got '%v'", nil, err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).Transaction
+
+		if _, err := db.GetByID(ctx, 1); err != transaction.ErrTransactionNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", transaction.ErrTransactionNotFound, err)
+		}
+	})
+}
+
+// newTransaction returns a new transaction with every field set.
+func newTransaction() *transaction.Transaction {
+	return &transaction.Transaction{
+		OrganizationID: 1,
+		UserID:         1,
+		Type:           "refund",
+		CardType:       "visa",
+		AmountCaptured: 1000,
+		InvoiceID:      1,
+		Status:         "approved",
+		CreatedAt:      now(),
+	}
+}
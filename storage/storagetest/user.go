@@ -0,0 +1,199 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dddstructure/storage/user"
+)
+
+// testUser tests the user database.
+func testUser(t *testing.T, newStorage Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).User
+
+		// Create a user with every optional field set.
+		verifiedAt := now()
+		enabledAt := verifiedAt.Add(-time.Hour)
+		want := &user.User{
+			Email:           "johndoe@test.com",
+			Password:        "password",
+			EmailVerifiedAt: &verifiedAt,
+			TOTPSecret:      "JBSWY3DPEHPK3PXP",
+			TOTPEnabledAt:   &enabledAt,
+			TOTPLastStep:    56000000,
+		}
+		created, err := db.Create(ctx, want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.ID == 0 {
+			t.Fatal("Expected an ID to be assigned")
+		}
+		want.ID = created.ID
+
+		// Get the user by ID and email.
+		got, err := db.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkUser(t, want, got)
+
+		got, err = db.GetByEmail(ctx, want.Email)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkUser(t, want, got)
+
+		// Emails are matched ignoring case.
+		got, err = db.GetByEmail(ctx, "JohnDoe@Test.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkUser(t, want, got)
+
+		// Create a user with no optional fields set, which stay unset.
+		bare := &user.User{
+			Email:    "janedoe@test.com",
+			Password: want.Password,
+		}
+		created, err = db.Create(ctx, bare)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.ID == 0 || created.ID == want.ID {
+			t.Errorf("Expected a new ID to be assigned, got '%d'", created.ID)
+		}
+		bare.ID = created.ID
+
+		got, err = db.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkUser(t, bare, got)
+	})
+
+	t.Run("CreateWithID", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).User
+
+		// Create a user with its ID set, which is kept.
+		created, err := db.Create(ctx, &user.User{
+			ID:       1000,
+			Email:    "johndoe@test.com",
+			Password: "password",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.ID != 1000 {
+			t.Errorf("Expected ID to be '%d', got '%d'", 1000, created.ID)
+		}
+
+		if _, err := db.GetByID(ctx, 1000); err != nil {
+			t.Errorf("Expected error to be '%v', got '%v'", nil, err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).User
+
+		if _, err := db.GetByID(ctx, 1); err != user.ErrUserNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", user.ErrUserNotFound, err)
+		}
+		if _, err := db.GetByEmail(ctx, "johndoe@test.com"); err != user.ErrUserNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", user.ErrUserNotFound, err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		ctx := context.Background()
+		db := newStorage(t).User
+
+		created, err := db.Create(ctx, &user.User{
+			Email:    "johndoe@test.com",
+			Password: "password",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Update every field.
+		verifiedAt := now()
+		want := &user.User{
+			ID:              created.ID,
+			Email:           "janedoe@test.com",
+			Password:        "new password",
+			EmailVerifiedAt: &verifiedAt,
+			TOTPSecret:      "JBSWY3DPEHPK3PXP",
+			TOTPEnabledAt:   &verifiedAt,
+			TOTPLastStep:    1,
+		}
+		if _, err := db.Update(ctx, want); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := db.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkUser(t, want, got)
+
+		// The old email no longer matches.
+		if _, err := db.GetByEmail(ctx, "johndoe@test.com"); err != user.ErrUserNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", user.ErrUserNotFound, err)
+		}
+
+		// Clear the optional fields.
+		want = &user.User{
+			ID:       created.ID,
+			Email:    want.Email,
+			Password: want.Password,
+		}
+		if _, err := db.Update(ctx, want); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err = db.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkUser(t, want, got)
+
+		// Updating a user that does not exist does not create it.
+		db.Update(ctx, &user.User{ID: created.ID + 100, Email: "missing@test.com"})
+		if _, err := db.GetByID(ctx, created.ID+100); err != user.ErrUserNotFound {
+			t.Errorf("Expected error to be '%v', got '%v'", user.ErrUserNotFound, err)
+		}
+	})
+}
+
+// checkUser checks the got user matches the wanted user.
+func checkUser(t *testing.T, want, got *user.User) {
+	t.Helper()
+
+	if got.ID != want.ID {
+		t.Errorf("Expected ID to be '%d', got '%d'", want.ID, got.ID)
+	}
+	if got.Email != want.Email {
+		t.Errorf("Expected email to be '%s', got '%s'", want.Email, got.Email)
+	}
+	if got.Password != want.Password {
+		t.Errorf("Expected password to be '%s', got '%s'", want.Password, got.Password)
+	}
+	if !equalTime(got.EmailVerifiedAt, want.EmailVerifiedAt) {
+		t.Errorf("Expected email verified at to be '%v', got '%v'", want.EmailVerifiedAt, got.EmailVerifiedAt)
+	}
+	if got.TOTPSecret != want.TOTPSecret {
+		t.Errorf("Expected TOTP secret to be '%s', got '%s'", want.TOTPSecret, got.TOTPSecret)
+	}
+	if !equalTime(got.TOTPEnabledAt, want.TOTPEnabledAt) {
+		t.Errorf("Expected TOTP enabled at to be '%v', got '%v'", want.TOTPEnabledAt, got.TOTPEnabledAt)
+	}
+	if got.TOTPLastStep != want.TOTPLastStep {
+		t.Errorf("Expected TOTP last step to be '%d', got '%d'", want.TOTPLastStep, got.TOTPLastStep)
+	}
+}
@@ -0,0 +1,23 @@
+package traced
+
+import (
+	"context"
+
+	"dddstructure/storage/health"
+	"dddstructure/trace"
+)
+
+// healthDatabase traces each health database call.
+type healthDatabase struct {
+	next health.Database
+}
+
+// Ping traces Ping.
+func (db *healthDatabase) Ping(ctx context.Context) error {
+	ctx, span := trace.StartKind(ctx, "storage.health.Ping", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.Ping(ctx)
+	span.SetError(err)
+	return err
+}
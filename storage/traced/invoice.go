@@ -0,0 +1,93 @@
+package traced
+
+import (
+	"context"
+
+	"dddstructure/storage/invoice"
+	"dddstructure/trace"
+)
+
+// invoiceDatabase traces each invoice database call.
+type invoiceDatabase struct {
+	next invoice.Database
+}
+
+// Create traces Create.
+func (db *invoiceDatabase) Create(ctx context.Context, i *invoice.Invoice) (*invoice.Invoice, error) {
+	ctx, span := trace.StartKind(ctx, "storage.invoice.Create", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.Create(ctx, i)
+	span.SetError(err)
+	return ret, err
+}
+
+// CreateBatch traces CreateBatch.
+func (db *invoiceDatabase) CreateBatch(ctx context.Context, invoices []*invoice.Invoice) ([]*invoice.Invoice, error) {
+	ctx, span := trace.StartKind(ctx, "storage.invoice.CreateBatch", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.CreateBatch(ctx, invoices)
+	span.SetError(err)
+	return ret, err
+}
+
+// Get traces Get.
+func (db *invoiceDatabase) Get(ctx context.Context, params *invoice.GetParams) ([]*invoice.Invoice, error) {
+	ctx, span := trace.StartKind(ctx, "storage.invoice.Get", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.Get(ctx, params)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetCount traces GetCount.
+func (db *invoiceDatabase) GetCount(ctx context.Context, params *invoice.GetParams) (uint, error) {
+	ctx, span := trace.StartKind(ctx, "storage.invoice.GetCount", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetCount(ctx, params)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetByID traces GetByID.
+func (db *invoiceDatabase) GetByID(ctx context.Context, id uint) (*invoice.Invoice, error) {
+	ctx, span := trace.StartKind(ctx, "storage.invoice.GetByID", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetByID(ctx, id)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetByPublicHash traces GetByPublicHash.
+func (db *invoiceDatabase) GetByPublicHash(ctx context.Context, hash string) (*invoice.Invoice, error) {
+	ctx, span := trace.StartKind(ctx, "storage.invoice.GetByPublicHash", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetByPublicHash(ctx, hash)
+	span.SetError(err)
+	return ret, err
+}
+
+// Update traces Update.
+func (db *invoiceDatabase) Update(ctx context.Context, i *invoice.Invoice) (*invoice.Invoice, error) {
+	ctx, span := trace.StartKind(ctx, "storage.invoice.Update", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.Update(ctx, i)
+	span.SetError(err)
+	return ret, err
+}
+
+// Delete traces Delete.
+func (db *invoiceDatabase) Delete(ctx context.Context, id uint) error {
+	ctx, span := trace.StartKind(ctx, "storage.invoice.Delete", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.Delete(ctx, id)
+	span.SetError(err)
+	return err
+}
@@ -0,0 +1,44 @@
+package traced
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/storage/loginattempt"
+	"dddstructure/trace"
+)
+
+// loginattemptDatabase traces each login attempt database call.
+type loginattemptDatabase struct {
+	next loginattempt.Database
+}
+
+// Get traces Get.
+func (db *loginattemptDatabase) Get(ctx context.Context, key string) (*loginattempt.LoginAttempt, error) {
+	ctx, span := trace.StartKind(ctx, "storage.loginattempt.Get", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.Get(ctx, key)
+	span.SetError(err)
+	return ret, err
+}
+
+// RecordFailure traces RecordFailure.
+func (db *loginattemptDatabase) RecordFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (*loginattempt.LoginAttempt, error) {
+	ctx, span := trace.StartKind(ctx, "storage.loginattempt.RecordFailure", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.RecordFailure(ctx, key, failedAt, resetBefore)
+	span.SetError(err)
+	return ret, err
+}
+
+// Delete traces Delete.
+func (db *loginattemptDatabase) Delete(ctx context.Context, key string) error {
+	ctx, span := trace.StartKind(ctx, "storage.loginattempt.Delete", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.Delete(ctx, key)
+	span.SetError(err)
+	return err
+}
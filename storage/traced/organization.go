@@ -0,0 +1,123 @@
+package traced
+
+import (
+	"context"
+
+	"dddstructure/storage/organization"
+	"dddstructure/trace"
+)
+
+// organizationDatabase traces each organization database call.
+type organizationDatabase struct {
+	next organization.Database
+}
+
+// Create traces Create.
+func (db *organizationDatabase) Create(ctx context.Context, o *organization.Organization, owner *organization.Member) (*organization.Organization, error) {
+	ctx, span := trace.StartKind(ctx, "storage.organization.Create", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.Create(ctx, o, owner)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetByID traces GetByID.
+func (db *organizationDatabase) GetByID(ctx context.Context, id uint) (*organization.Organization, error) {
+	ctx, span := trace.StartKind(ctx, "storage.organization.GetByID", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetByID(ctx, id)
+	span.SetError(err)
+	return ret, err
+}
+
+// CreateMember traces CreateMember.
+func (db *organizationDatabase) CreateMember(ctx context.Context, m *organization.Member) (*organization.Member, error) {
+	ctx, span := trace.StartKind(ctx, "storage.organization.CreateMember", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.CreateMember(ctx, m)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetMember traces GetMember.
+func (db *organizationDatabase) GetMember(ctx context.Context, organizationID, userID uint) (*organization.Member, error) {
+	ctx, span := trace.StartKind(ctx, "storage.organization.GetMember", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetMember(ctx, organizationID, userID)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetMembers traces GetMembers.
+func (db *organizationDatabase) GetMembers(ctx context.Context, organizationID uint) ([]*organization.Member, error) {
+	ctx, span := trace.StartKind(ctx, "storage.organization.GetMembers", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetMembers(ctx, organizationID)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetByUserID traces GetByUserID.
+func (db *organizationDatabase) GetByUserID(ctx context.Context, userID uint) ([]*organization.Membership, error) {
+	ctx, span := trace.StartKind(ctx, "storage.organization.GetByUserID", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetByUserID(ctx, userID)
+	span.SetError(err)
+	return ret, err
+}
+
+// UpdateMember traces UpdateMember.
+func (db *organizationDatabase) UpdateMember(ctx context.Context, m *organization.Member) (*organization.Member, error) {
+	ctx, span := trace.StartKind(ctx, "storage.organization.UpdateMember", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.UpdateMember(ctx, m)
+	span.SetError(err)
+	return ret, err
+}
+
+// DeleteMember traces DeleteMember.
+func (db *organizationDatabase) DeleteMember(ctx context.Context, organizationID, userID uint) error {
+	ctx, span := trace.StartKind(ctx, "storage.organization.DeleteMember", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.DeleteMember(ctx, organizationID, userID)
+	span.SetError(err)
+	return err
+}
+
+// CreateInvitation traces CreateInvitation.
+func (db *organizationDatabase) CreateInvitation(ctx context.Context, i *organization.Invitation) (*organization.Invitation, error) {
+	ctx, span := trace.StartKind(ctx, "storage.organization.CreateInvitation", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.CreateInvitation(ctx, i)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetInvitationByHash traces GetInvitationByHash.
+func (db *organizationDatabase) GetInvitationByHash(ctx context.Context, hash string) (*organization.Invitation, error) {
+	ctx, span := trace.StartKind(ctx, "storage.organization.GetInvitationByHash", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetInvitationByHash(ctx, hash)
+	span.SetError(err)
+	return ret, err
+}
+
+// DeleteInvitation traces DeleteInvitation.
+func (db *organizationDatabase) DeleteInvitation(ctx context.Context, hash string) error {
+	ctx, span := trace.StartKind(ctx, "storage.organization.DeleteInvitation", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.DeleteInvitation(ctx, hash)
+	span.SetError(err)
+	return err
+}
@@ -0,0 +1,64 @@
+package traced
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/storage/outbox"
+	"dddstructure/trace"
+)
+
+// outboxDatabase traces each outbox database call.
+type outboxDatabase struct {
+	next outbox.Database
+}
+
+// Claim traces Claim.
+func (db *outboxDatabase) Claim(ctx context.Context, limit uint, until time.Time) ([]*outbox.Event, error) {
+	ctx, span := trace.StartKind(ctx, "storage.outbox.Claim", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.Claim(ctx, limit, until)
+	span.SetError(err)
+	return ret, err
+}
+
+// Delete traces Delete.
+func (db *outboxDatabase) Delete(ctx context.Context, id string) error {
+	ctx, span := trace.StartKind(ctx, "storage.outbox.Delete", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.Delete(ctx, id)
+	span.SetError(err)
+	return err
+}
+
+// Fail traces Fail.
+func (db *outboxDatabase) Fail(ctx context.Context, id, reason string, retryAt time.Time) error {
+	ctx, span := trace.StartKind(ctx, "storage.outbox.Fail", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.Fail(ctx, id, reason, retryAt)
+	span.SetError(err)
+	return err
+}
+
+// DeadLetter traces DeadLetter.
+func (db *outboxDatabase) DeadLetter(ctx context.Context, id, reason string) error {
+	ctx, span := trace.StartKind(ctx, "storage.outbox.DeadLetter", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.DeadLetter(ctx, id, reason)
+	span.SetError(err)
+	return err
+}
+
+// GetDeadLetters traces GetDeadLetters.
+func (db *outboxDatabase) GetDeadLetters(ctx context.Context, limit uint) ([]*outbox.Event, error) {
+	ctx, span := trace.StartKind(ctx, "storage.outbox.GetDeadLetters", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetDeadLetters(ctx, limit)
+	span.SetError(err)
+	return ret, err
+}
@@ -0,0 +1,53 @@
+package traced
+
+import (
+	"context"
+
+	"dddstructure/storage/recoverycode"
+	"dddstructure/trace"
+)
+
+// recoverycodeDatabase traces each recovery code database call.
+type recoverycodeDatabase struct {
+	next recoverycode.Database
+}
+
+// Create traces Create.
+func (db *recoverycodeDatabase) Create(ctx context.Context, c *recoverycode.RecoveryCode) (*recoverycode.RecoveryCode, error) {
+	ctx, span := trace.StartKind(ctx, "storage.recoverycode.Create", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.Create(ctx, c)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetByHash traces GetByHash.
+func (db *recoverycodeDatabase) GetByHash(ctx context.Context, hash string) (*recoverycode.RecoveryCode, error) {
+	ctx, span := trace.StartKind(ctx, "storage.recoverycode.GetByHash", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetByHash(ctx, hash)
+	span.SetError(err)
+	return ret, err
+}
+
+// Delete traces Delete.
+func (db *recoverycodeDatabase) Delete(ctx context.Context, hash string) error {
+	ctx, span := trace.StartKind(ctx, "storage.recoverycode.Delete", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.Delete(ctx, hash)
+	span.SetError(err)
+	return err
+}
+
+// DeleteByUserID traces DeleteByUserID.
+func (db *recoverycodeDatabase) DeleteByUserID(ctx context.Context, userID uint) error {
+	ctx, span := trace.StartKind(ctx, "storage.recoverycode.DeleteByUserID", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.DeleteByUserID(ctx, userID)
+	span.SetError(err)
+	return err
+}
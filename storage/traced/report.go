@@ -0,0 +1,43 @@
+package traced
+
+import (
+	"context"
+
+	"dddstructure/storage/report"
+	"dddstructure/trace"
+)
+
+// reportDatabase traces each report database call.
+type reportDatabase struct {
+	next report.Database
+}
+
+// GetAging traces GetAging.
+func (db *reportDatabase) GetAging(ctx context.Context, params *report.AgingParams) ([]*report.AgingBucket, error) {
+	ctx, span := trace.StartKind(ctx, "storage.report.GetAging", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetAging(ctx, params)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetRevenue traces GetRevenue.
+func (db *reportDatabase) GetRevenue(ctx context.Context, params *report.RevenueParams) ([]*report.Revenue, error) {
+	ctx, span := trace.StartKind(ctx, "storage.report.GetRevenue", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetRevenue(ctx, params)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetBalances traces GetBalances.
+func (db *reportDatabase) GetBalances(ctx context.Context, params *report.BalancesParams) ([]*report.Balance, error) {
+	ctx, span := trace.StartKind(ctx, "storage.report.GetBalances", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetBalances(ctx, params)
+	span.SetError(err)
+	return ret, err
+}
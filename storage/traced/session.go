@@ -0,0 +1,104 @@
+package traced
+
+import (
+	"context"
+	"time"
+
+	"dddstructure/storage/session"
+	"dddstructure/trace"
+)
+
+// sessionDatabase traces each session database call.
+type sessionDatabase struct {
+	next session.Database
+}
+
+// Create traces Create.
+func (db *sessionDatabase) Create(ctx context.Context, s *session.Session) (*session.Session, error) {
+	ctx, span := trace.StartKind(ctx, "storage.session.Create", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.Create(ctx, s)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetByID traces GetByID.
+func (db *sessionDatabase) GetByID(ctx context.Context, id string) (*session.Session, error) {
+	ctx, span := trace.StartKind(ctx, "storage.session.GetByID", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetByID(ctx, id)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetByUserID traces GetByUserID.
+func (db *sessionDatabase) GetByUserID(ctx context.Context, userID uint) ([]*session.Session, error) {
+	ctx, span := trace.StartKind(ctx, "storage.session.GetByUserID", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetByUserID(ctx, userID)
+	span.SetError(err)
+	return ret, err
+}
+
+// Update traces Update.
+func (db *sessionDatabase) Update(ctx context.Context, s *session.Session) (*session.Session, error) {
+	ctx, span := trace.StartKind(ctx, "storage.session.Update", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.Update(ctx, s)
+	span.SetError(err)
+	return ret, err
+}
+
+// Delete traces Delete.
+func (db *sessionDatabase) Delete(ctx context.Context, id string) error {
+	ctx, span := trace.StartKind(ctx, "storage.session.Delete", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.Delete(ctx, id)
+	span.SetError(err)
+	return err
+}
+
+// DeleteByUserID traces DeleteByUserID.
+func (db *sessionDatabase) DeleteByUserID(ctx context.Context, userID uint) error {
+	ctx, span := trace.StartKind(ctx, "storage.session.DeleteByUserID", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.DeleteByUserID(ctx, userID)
+	span.SetError(err)
+	return err
+}
+
+// CreateRefreshToken traces CreateRefreshToken.
+func (db *sessionDatabase) CreateRefreshToken(ctx context.Context, t *session.RefreshToken) (*session.RefreshToken, error) {
+	ctx, span := trace.StartKind(ctx, "storage.session.CreateRefreshToken", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.CreateRefreshToken(ctx, t)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetRefreshTokenByHash traces GetRefreshTokenByHash.
+func (db *sessionDatabase) GetRefreshTokenByHash(ctx context.Context, hash string) (*session.RefreshToken, error) {
+	ctx, span := trace.StartKind(ctx, "storage.session.GetRefreshTokenByHash", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetRefreshTokenByHash(ctx, hash)
+	span.SetError(err)
+	return ret, err
+}
+
+// UseRefreshToken traces UseRefreshToken.
+func (db *sessionDatabase) UseRefreshToken(ctx context.Context, hash string, usedAt time.Time) error {
+	ctx, span := trace.StartKind(ctx, "storage.session.UseRefreshToken", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.UseRefreshToken(ctx, hash, usedAt)
+	span.SetError(err)
+	return err
+}
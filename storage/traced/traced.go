@@ -0,0 +1,24 @@
+package traced
+
+import "dddstructure/storage"
+
+// New returns a new implementation of storage.Storage that wraps each
+// database of the given storage, starting a span for every call.
+//
+// Spans are only started when the context of the call has a span, such as
+// one started for an API request, so calls outside of a trace cost nothing.
+func New(s *storage.Storage) *storage.Storage {
+	return &storage.Storage{
+		User:         &userDatabase{next: s.User},
+		UserToken:    &usertokenDatabase{next: s.UserToken},
+		RecoveryCode: &recoverycodeDatabase{next: s.RecoveryCode},
+		LoginAttempt: &loginattemptDatabase{next: s.LoginAttempt},
+		Session:      &sessionDatabase{next: s.Session},
+		Organization: &organizationDatabase{next: s.Organization},
+		Invoice:      &invoiceDatabase{next: s.Invoice},
+		Transaction:  &transactionDatabase{next: s.Transaction},
+		Report:       &reportDatabase{next: s.Report},
+		Outbox:       &outboxDatabase{next: s.Outbox},
+		Health:       &healthDatabase{next: s.Health},
+	}
+}
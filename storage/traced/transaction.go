@@ -0,0 +1,33 @@
+package traced
+
+import (
+	"context"
+
+	"dddstructure/storage/transaction"
+	"dddstructure/trace"
+)
+
+// transactionDatabase traces each transaction database call.
+type transactionDatabase struct {
+	next transaction.Database
+}
+
+// Create traces Create.
+func (db *transactionDatabase) Create(ctx context.Context, i *transaction.Transaction) (*transaction.Transaction, error) {
+	ctx, span := trace.StartKind(ctx, "storage.transaction.Create", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.Create(ctx, i)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetByID traces GetByID.
+func (db *transactionDatabase) GetByID(ctx context.Context, id uint) (*transaction.Transaction, error) {
+	ctx, span := trace.StartKind(ctx, "storage.transaction.GetByID", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetByID(ctx, id)
+	span.SetError(err)
+	return ret, err
+}
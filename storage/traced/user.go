@@ -0,0 +1,53 @@
+package traced
+
+import (
+	"context"
+
+	"dddstructure/storage/user"
+	"dddstructure/trace"
+)
+
+// userDatabase traces each user database call.
+type userDatabase struct {
+	next user.Database
+}
+
+// Create traces Create.
+func (db *userDatabase) Create(ctx context.Context, u *user.User) (*user.User, error) {
+	ctx, span := trace.StartKind(ctx, "storage.user.Create", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.Create(ctx, u)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetByID traces GetByID.
+func (db *userDatabase) GetByID(ctx context.Context, id uint) (*user.User, error) {
+	ctx, span := trace.StartKind(ctx, "storage.user.GetByID", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetByID(ctx, id)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetByEmail traces GetByEmail.
+func (db *userDatabase) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	ctx, span := trace.StartKind(ctx, "storage.user.GetByEmail", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetByEmail(ctx, email)
+	span.SetError(err)
+	return ret, err
+}
+
+// Update traces Update.
+func (db *userDatabase) Update(ctx context.Context, u *user.User) (*user.User, error) {
+	ctx, span := trace.StartKind(ctx, "storage.user.Update", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.Update(ctx, u)
+	span.SetError(err)
+	return ret, err
+}
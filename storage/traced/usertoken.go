@@ -0,0 +1,53 @@
+package traced
+
+import (
+	"context"
+
+	"dddstructure/storage/usertoken"
+	"dddstructure/trace"
+)
+
+// usertokenDatabase traces each user token database call.
+type usertokenDatabase struct {
+	next usertoken.Database
+}
+
+// Create traces Create.
+func (db *usertokenDatabase) Create(ctx context.Context, t *usertoken.UserToken) (*usertoken.UserToken, error) {
+	ctx, span := trace.StartKind(ctx, "storage.usertoken.Create", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.Create(ctx, t)
+	span.SetError(err)
+	return ret, err
+}
+
+// GetByHash traces GetByHash.
+func (db *usertokenDatabase) GetByHash(ctx context.Context, hash string) (*usertoken.UserToken, error) {
+	ctx, span := trace.StartKind(ctx, "storage.usertoken.GetByHash", trace.SpanKindClient)
+	defer span.End()
+
+	ret, err := db.next.GetByHash(ctx, hash)
+	span.SetError(err)
+	return ret, err
+}
+
+// Delete traces Delete.
+func (db *usertokenDatabase) Delete(ctx context.Context, hash string) error {
+	ctx, span := trace.StartKind(ctx, "storage.usertoken.Delete", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.Delete(ctx, hash)
+	span.SetError(err)
+	return err
+}
+
+// DeleteByUserID traces DeleteByUserID.
+func (db *usertokenDatabase) DeleteByUserID(ctx context.Context, userID uint, tokenType string) error {
+	ctx, span := trace.StartKind(ctx, "storage.usertoken.DeleteByUserID", trace.SpanKindClient)
+	defer span.End()
+
+	err := db.next.DeleteByUserID(ctx, userID, tokenType)
+	span.SetError(err)
+	return err
+}
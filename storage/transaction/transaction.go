@@ -1,18 +1,25 @@
 package transaction
 
+import (
+	"context"
+	"time"
+)
+
 // Database defines the transaction database interface.
 type Database interface {
-	Create(i *Transaction) (*Transaction, error)
-	GetByID(id uint) (*Transaction, error)
+	Create(ctx context.Context, i *Transaction) (*Transaction, error)
+	GetByID(ctx context.Context, id uint) (*Transaction, error)
 }
 
 // Transaction defines the transaction.
 type Transaction struct {
 	ID             uint
+	OrganizationID uint
 	UserID         uint
 	Type           string
 	CardType       string
 	AmountCaptured uint
 	InvoiceID      uint
 	Status         string
+	CreatedAt      time.Time
 }
@@ -1,16 +1,25 @@
 package user
 
+import (
+	"context"
+	"time"
+)
+
 // Database defines the user database interface.
 type Database interface {
-	Create(u *User) (*User, error)
-	GetByID(id uint) (*User, error)
-	GetByEmail(email string) (*User, error)
-	Update(u *User) (*User, error)
+	Create(ctx context.Context, u *User) (*User, error)
+	GetByID(ctx context.Context, id uint) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	Update(ctx context.Context, u *User) (*User, error)
 }
 
 // User defines a user.
 type User struct {
-	ID       uint
-	Email    string
-	Password string
+	ID              uint
+	Email           string
+	Password        string
+	EmailVerifiedAt *time.Time
+	TOTPSecret      string
+	TOTPEnabledAt   *time.Time
+	TOTPLastStep    uint64
 }
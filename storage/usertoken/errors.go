@@ -0,0 +1,8 @@
+package usertoken
+
+import "errors"
+
+var (
+	// ErrUserTokenNotFound is returned when a user token could not be found.
+	ErrUserTokenNotFound = errors.New("user token not found")
+)
@@ -0,0 +1,33 @@
+package usertoken
+
+import (
+	"context"
+	"time"
+)
+
+// Database defines the user token database interface.
+type Database interface {
+	Create(ctx context.Context, t *UserToken) (*UserToken, error)
+	GetByHash(ctx context.Context, hash string) (*UserToken, error)
+	Delete(ctx context.Context, hash string) error
+	DeleteByUserID(ctx context.Context, userID uint, tokenType string) error
+}
+
+// User token types.
+const (
+	TypePasswordReset      = "password_reset"
+	TypeEmailVerification  = "email_verification"
+	TypeTwoFactorChallenge = "two_factor_challenge"
+)
+
+// UserToken defines a single-use user token.
+//
+// Only the SHA-256 hash of a token is stored, so the tokens themselves can't
+// be read back from the database.
+type UserToken struct {
+	Hash      string
+	UserID    uint
+	Type      string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
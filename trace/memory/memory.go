@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"dddstructure/trace"
+)
+
+// Exporter defines an exporter that keeps finished spans in memory, for
+// tests.
+type Exporter struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+// New creates a new in-memory exporter.
+func New() *Exporter {
+	return &Exporter{}
+}
+
+// ExportSpans keeps the given spans.
+func (e *Exporter) ExportSpans(ctx context.Context, spans []*trace.SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+// Shutdown does nothing, as the spans are kept until Reset is called.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Spans returns the spans exported so far, in the order they ended.
+func (e *Exporter) Spans() []*trace.SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]*trace.SpanData{}, e.spans...)
+}
+
+// Reset removes the spans exported so far.
+func (e *Exporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = nil
+}
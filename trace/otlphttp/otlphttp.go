@@ -0,0 +1,312 @@
+package otlphttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"dddstructure/trace"
+)
+
+const (
+	// maxQueueSize defines the number of spans kept waiting to be sent,
+	// after which spans are dropped rather than blocking requests.
+	maxQueueSize = 2048
+
+	// maxBatchSize defines the number of spans sent in a single request.
+	maxBatchSize = 512
+
+	// flushInterval defines how often waiting spans are sent.
+	flushInterval = 5 * time.Second
+
+	// requestTimeout defines the timeout of each request to the collector.
+	requestTimeout = 10 * time.Second
+)
+
+// ErrShutdown is returned when spans are exported after the exporter is shut
+// down.
+var ErrShutdown = errors.New("exporter is shut down")
+
+// Exporter defines an exporter that sends spans to an OpenTelemetry
+// collector using OTLP over HTTP, with the JSON encoding.
+//
+// Spans are queued and sent in batches from a separate goroutine, so
+// exporting never waits on the collector.
+type Exporter struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+	logger      *slog.Logger
+
+	queue    chan *trace.SpanData
+	quit     chan struct{}
+	done     chan struct{}
+	quitOnce sync.Once
+}
+
+// New creates a new OTLP over HTTP exporter sending spans to the given
+// endpoint, such as http://localhost:4318/v1/traces, under the given service
+// name. Failures to send spans are logged to the given logger.
+func New(endpoint, serviceName string, l *slog.Logger) *Exporter {
+	e := &Exporter{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: requestTimeout},
+		logger:      l,
+		queue:       make(chan *trace.SpanData, maxQueueSize),
+		quit:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+
+	go e.run()
+
+	return e
+}
+
+// ExportSpans queues the given spans to be sent. If the queue is full the
+// spans are dropped.
+func (e *Exporter) ExportSpans(ctx context.Context, spans []*trace.SpanData) error {
+	for _, s := range spans {
+		select {
+		case <-e.quit:
+			return ErrShutdown
+		default:
+		}
+
+		select {
+		case e.queue <- s:
+		default:
+			e.logger.Warn("trace queue full, dropping span",
+				slog.String("span", s.Name))
+		}
+	}
+
+	return nil
+}
+
+// Shutdown sends the queued spans and stops the exporter, waiting until the
+// given context is done at most.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	e.quitOnce.Do(func() {
+		close(e.quit)
+	})
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run sends the queued spans in batches, once a batch is full or on every
+// flush interval, until the exporter is shut down.
+func (e *Exporter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*trace.SpanData, 0, maxBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := e.send(batch); err != nil {
+			e.logger.Error("e.send() error",
+				slog.Any("error", err),
+				slog.Int("spans", len(batch)))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case s := <-e.queue:
+			batch = append(batch, s)
+			if len(batch) == maxBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.quit:
+			for {
+				select {
+				case s := <-e.queue:
+					batch = append(batch, s)
+					if len(batch) == maxBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// send sends a batch of spans to the collector.
+func (e *Exporter) send(spans []*trace.SpanData) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("collector responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// exportRequest defines the OTLP export request, as encoded in JSON.
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+// resourceSpans defines the spans of a single resource.
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+// resource defines the process the spans come from.
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+// scopeSpans defines the spans of a single instrumentation scope.
+type scopeSpans struct {
+	Scope scope  `json:"scope"`
+	Spans []span `json:"spans"`
+}
+
+// scope defines the instrumentation scope.
+type scope struct {
+	Name string `json:"name"`
+}
+
+// span defines a single span.
+type span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            *status    `json:"status,omitempty"`
+}
+
+// status defines the status of a span.
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// statusCodeError defines the status code of a failed span.
+const statusCodeError = 2
+
+// keyValue defines an attribute.
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+// anyValue defines the value of an attribute. Integers are encoded as
+// strings, as they are 64 bit.
+type anyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+
+// request creates the export request for a batch of spans.
+func (e *Exporter) request(spans []*trace.SpanData) *exportRequest {
+	ss := scopeSpans{
+		Scope: scope{Name: "dddstructure"},
+		Spans: make([]span, 0, len(spans)),
+	}
+
+	for _, s := range spans {
+		out := span{
+			TraceID:           s.TraceID.String(),
+			SpanID:            s.SpanID.String(),
+			Name:              s.Name,
+			Kind:              int(s.Kind),
+			StartTimeUnixNano: strconv.FormatInt(s.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+			Attributes:        attributes(s.Attributes),
+		}
+		if s.ParentSpanID.IsValid() {
+			out.ParentSpanID = s.ParentSpanID.String()
+		}
+		if s.Error != "" {
+			out.Status = &status{Code: statusCodeError, Message: s.Error}
+		}
+
+		ss.Spans = append(ss.Spans, out)
+	}
+
+	return &exportRequest{
+		ResourceSpans: []resourceSpans{
+			{
+				Resource: resource{
+					Attributes: attributes([]trace.Attribute{
+						trace.String("service.name", e.serviceName),
+					}),
+				},
+				ScopeSpans: []scopeSpans{ss},
+			},
+		},
+	}
+}
+
+// attributes converts span attributes to OTLP attributes.
+func attributes(attrs []trace.Attribute) []keyValue {
+	kvs := make([]keyValue, 0, len(attrs))
+	for _, a := range attrs {
+		kv := keyValue{Key: a.Key}
+
+		switch v := a.Value.(type) {
+		case string:
+			kv.Value.StringValue = &v
+		case int64:
+			s := strconv.FormatInt(v, 10)
+			kv.Value.IntValue = &s
+		case bool:
+			kv.Value.BoolValue = &v
+		default:
+			s := fmt.Sprint(v)
+			kv.Value.StringValue = &s
+		}
+
+		kvs = append(kvs, kv)
+	}
+
+	return kvs
+}
@@ -0,0 +1,335 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// TraceparentHeader defines the header used to pass the trace context, as
+// set out in the W3C Trace Context recommendation.
+const TraceparentHeader = "traceparent"
+
+// ErrInvalidTraceparent is returned when a traceparent header can not be
+// parsed.
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// TraceID defines the ID of a trace.
+type TraceID [16]byte
+
+// IsValid checks if the trace ID is set.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// String returns the trace ID in hex.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID defines the ID of a span.
+type SpanID [8]byte
+
+// IsValid checks if the span ID is set.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// String returns the span ID in hex.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanContext defines the part of a span that is passed to other spans and
+// across processes.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid checks if both the trace ID and span ID are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent returns the span context as a traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent parses a traceparent header value.
+func ParseTraceparent(v string) (SpanContext, error) {
+	var sc SpanContext
+
+	// Only version 00 is known, but later versions must start with the same
+	// fields.
+	if len(v) < 55 || v[2] != '-' || v[35] != '-' || v[52] != '-' {
+		return sc, ErrInvalidTraceparent
+	}
+	if len(v) > 55 && (v[:2] == "00" || v[55] != '-') {
+		return sc, ErrInvalidTraceparent
+	}
+	if v[:2] == "ff" {
+		return sc, ErrInvalidTraceparent
+	}
+
+	var version, flags [1]byte
+	if _, err := hex.Decode(version[:], []byte(v[:2])); err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(v[3:35])); err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(v[36:52])); err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	if _, err := hex.Decode(flags[:], []byte(v[53:55])); err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	sc.Sampled = flags[0]&1 == 1
+	return sc, nil
+}
+
+// SpanKind defines the role of a span in a trace.
+type SpanKind int
+
+// Defines the span kinds, matching the OTLP values.
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// Attribute defines a key and value set on a span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// SpanData defines a finished span, as passed to an exporter.
+type SpanData struct {
+	Name         string
+	Kind         SpanKind
+	TraceID      TraceID
+	SpanID       SpanID
+	ParentSpanID SpanID
+	StartTime    time.Time
+	EndTime      time.Time
+	Attributes   []Attribute
+	Error        string
+}
+
+// Exporter defines an exporter that finished spans are sent to.
+type Exporter interface {
+	// ExportSpans exports the given spans. It is called as each sampled
+	// span ends, so it must not block on the network.
+	ExportSpans(ctx context.Context, spans []*SpanData) error
+
+	// Shutdown exports any spans not yet sent and stops the exporter.
+	Shutdown(ctx context.Context) error
+}
+
+// Tracer defines a tracer, which starts the root span of each trace handled
+// by this process.
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer creates a new tracer exporting spans to the given exporter.
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{
+		exporter: exporter,
+	}
+}
+
+// Start starts a new span with the given name and kind.
+//
+// The span is a child of the span in the given context, or of the given
+// remote span context if there is none and it is valid, such as one taken
+// from a traceparent header. Otherwise a new trace is started.
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind, remote SpanContext) (context.Context, *Span) {
+	if parent := SpanFromContext(ctx); parent != nil {
+		return start(ctx, parent.tracer, parent.sc, name, kind)
+	}
+
+	if !remote.IsValid() {
+		remote = SpanContext{TraceID: newTraceID(), Sampled: true}
+	}
+
+	return start(ctx, t, remote, name, kind)
+}
+
+// Shutdown exports any spans not yet sent and stops the exporter.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	return t.exporter.Shutdown(ctx)
+}
+
+// Start starts a new span with the given name as a child of the span in the
+// given context.
+//
+// If there is no span in the context, nothing is traced and the returned span
+// is nil. All methods of a nil span do nothing, so callers do not need to
+// check.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return StartKind(ctx, name, SpanKindInternal)
+}
+
+// StartKind starts a new span like Start, with the given kind.
+func StartKind(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+
+	return start(ctx, parent.tracer, parent.sc, name, kind)
+}
+
+// start starts a new span as a child of the given span context.
+func start(ctx context.Context, t *Tracer, parent SpanContext, name string, kind SpanKind) (context.Context, *Span) {
+	s := &Span{
+		tracer: t,
+		sc: SpanContext{
+			TraceID: parent.TraceID,
+			SpanID:  newSpanID(),
+			Sampled: parent.Sampled,
+		},
+		data: SpanData{
+			Name:         name,
+			Kind:         kind,
+			TraceID:      parent.TraceID,
+			ParentSpanID: parent.SpanID,
+			StartTime:    time.Now(),
+		},
+	}
+	s.data.SpanID = s.sc.SpanID
+
+	return context.WithValue(ctx, spanKey, s), s
+}
+
+// key is the key type used by this package for the context.
+type key int
+
+// spanKey is the key used for storing and retrieving the current span from
+// the context.
+var spanKey key = 1
+
+// SpanFromContext returns the current span of the given context, or nil if
+// there is none.
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey).(*Span)
+	return s
+}
+
+// Span defines a span, timing a single operation of a trace.
+type Span struct {
+	tracer *Tracer
+	sc     SpanContext
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext returns the span context of the span.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+
+	return s.sc
+}
+
+// SetAttributes sets the given attributes on the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+// SetError marks the span as failed with the given error, if it is not nil.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Error = err.Error()
+}
+
+// End ends the span and sends it to the exporter if it is sampled. Only the
+// first call has any effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if s.sc.Sampled {
+		s.tracer.exporter.ExportSpans(context.Background(), []*SpanData{&data})
+	}
+}
+
+// newTraceID generates a new random trace ID.
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+
+	return id
+}
+
+// newSpanID generates a new random span ID.
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+
+	return id
+}
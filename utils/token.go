@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+)
+
+// tokenLength defines the number of random bytes in a token.
+const tokenLength = 32
+
+// NewToken generates a new random, URL safe token, returning both the token
+// to hand out and its hash to store.
+func NewToken() (string, string, error) {
+	b := make([]byte, tokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hex encoded SHA-256 hash of a token.
+//
+// Tokens are random and long enough that a fast hash is safe here, unlike
+// with passwords.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenLink returns the given link with the token added as the token query
+// parameter, or just the token if there is no link.
+func TokenLink(link, token string) string {
+	if link == "" {
+		return token
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return token
+	}
+
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
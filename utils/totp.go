@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// totpSecretLength defines the number of random bytes in a TOTP secret,
+	// which is the length of a SHA-1 HMAC key recommended by RFC 4226.
+	totpSecretLength = 20
+
+	// totpDigits defines the number of digits in a TOTP code.
+	totpDigits = 6
+
+	// totpPeriod defines how long each TOTP code is valid for.
+	totpPeriod = 30 * time.Second
+)
+
+// totpEncoding is the base32 encoding used for TOTP secrets by authenticator
+// apps.
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewTOTPSecret generates a new random, base32 encoded TOTP secret.
+func NewTOTPSecret() (string, error) {
+	b := make([]byte, totpSecretLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPStep returns the RFC 6238 time step for the given time.
+func TOTPStep(t time.Time) uint64 {
+	return uint64(t.Unix()) / uint64(totpPeriod/time.Second)
+}
+
+// TOTPCode returns the RFC 6238 code of a base32 encoded secret for the given
+// time step.
+func TOTPCode(secret string, step uint64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	// HMAC the big endian step, as in RFC 4226.
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, step)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// Dynamically truncate the HMAC to a 31 bit number.
+	offset := sum[len(sum)-1] & 0x0f
+	number := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for n := 0; n < totpDigits; n++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", totpDigits, number%mod), nil
+}
+
+// TOTPURI returns the otpauth:// provisioning URI for a TOTP secret, which
+// authenticator apps read from a QR code.
+func TOTPURI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(int(totpPeriod/time.Second)))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}
+
+	return u.String()
+}